@@ -45,14 +45,19 @@ import (
 
 var DefAbiMgr = NewAbiMgr()
 
+//wasm contract abi files are in the wasm sub directory of Path
+const WASM_ABI_DIR = "wasm"
+
 type AbiMgr struct {
 	Path       string
 	nativeAbis map[string]*NativeContractAbi
+	wasmAbis   map[string]*WasmContractAbi
 }
 
 func NewAbiMgr() *AbiMgr {
 	return &AbiMgr{
 		nativeAbis: make(map[string]*NativeContractAbi),
+		wasmAbis:   make(map[string]*WasmContractAbi),
 	}
 }
 
@@ -64,9 +69,18 @@ func (this *AbiMgr) GetNativeAbi(address string) *NativeContractAbi {
 	return nil
 }
 
+func (this *AbiMgr) GetWasmAbi(address string) *WasmContractAbi {
+	abi, ok := this.wasmAbis[address]
+	if ok {
+		return abi
+	}
+	return nil
+}
+
 func (this *AbiMgr) Init(path string) {
 	this.Path = path
 	this.loadNativeAbi()
+	this.loadWasmAbi()
 }
 
 func (this *AbiMgr) loadNativeAbi() {
@@ -98,3 +112,34 @@ func (this *AbiMgr) loadNativeAbi() {
 		log.Infof("Native contract name:%s address:%s abi load success", fileName, nativeAbi.Address)
 	}
 }
+
+func (this *AbiMgr) loadWasmAbi() {
+	wasmAbiPath := fmt.Sprintf("%s/%s", this.Path, WASM_ABI_DIR)
+	wasmAbiFiles, err := ioutil.ReadDir(wasmAbiPath)
+	if err != nil {
+		log.Infof("AbiMgr loadWasmAbi read dir:%s error:%s", wasmAbiPath, err)
+		return
+	}
+	for _, wasmAbiFile := range wasmAbiFiles {
+		fileName := wasmAbiFile.Name()
+		if wasmAbiFile.IsDir() {
+			continue
+		}
+		if !strings.HasSuffix(fileName, ".json") {
+			continue
+		}
+		data, err := ioutil.ReadFile(fmt.Sprintf("%s/%s", wasmAbiPath, fileName))
+		if err != nil {
+			log.Errorf("AbiMgr loadWasmAbi name:%s error:%s", fileName, err)
+			continue
+		}
+		wasmAbi := &WasmContractAbi{}
+		err = json.Unmarshal(data, wasmAbi)
+		if err != nil {
+			log.Errorf("AbiMgr loadWasmAbi name:%s error:%s", fileName, err)
+			continue
+		}
+		this.wasmAbis[wasmAbi.Address] = wasmAbi
+		log.Infof("Wasm contract name:%s address:%s abi load success", fileName, wasmAbi.Address)
+	}
+}
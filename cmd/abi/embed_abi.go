@@ -41,6 +41,8 @@ const (
 	EMBED_PARAM_TYPE_INTEGER    = "integer"
 	EMBED_PARAM_TYPE_ARRAY      = "array"
 	EMBED_PARAM_TYPE_BYTE_ARRAY = "bytearray"
+	EMBED_PARAM_TYPE_STRUCT     = "struct"
+	EMBED_PARAM_TYPE_MAP        = "map"
 	EMBED_PARAM_TYPE_VOID       = "void"
 	EMBED_PARAM_TYPE_ANY        = "any"
 )
@@ -78,9 +80,14 @@ type EmbededContractFunctionAbi struct {
 	ReturnType string                      `json:"returntype"`
 }
 
+//EmbededContractParamsAbi describe a contract param.
+//SubType is the element type of array or the value type of map, any type if nil. Map keys are string.
+//Fields are the field types of struct in order
 type EmbededContractParamsAbi struct {
-	Name string `json:"name"`
-	Type string `json:"type"`
+	Name    string                      `json:"name"`
+	Type    string                      `json:"type"`
+	SubType *EmbededContractParamsAbi   `json:"subtype,omitempty"`
+	Fields  []*EmbededContractParamsAbi `json:"fields,omitempty"`
 }
 
 type EmbededContractEventAbi struct {
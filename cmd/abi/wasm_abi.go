@@ -0,0 +1,75 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package abi
+
+import "strings"
+
+const (
+	WASM_PARAM_TYPE_BYTES   = "bytes"
+	WASM_PARAM_TYPE_STRING  = "string"
+	WASM_PARAM_TYPE_BOOL    = "bool"
+	WASM_PARAM_TYPE_ADDRESS = "address"
+	WASM_PARAM_TYPE_INT128  = "int128"
+	WASM_PARAM_TYPE_UINT128 = "uint128"
+	WASM_PARAM_TYPE_LIST    = "list"
+	WASM_PARAM_TYPE_STRUCT  = "struct"
+)
+
+type WasmContractAbi struct {
+	Address   string                     `json:"hash"`
+	Functions []*WasmContractFunctionAbi `json:"functions"`
+	Events    []*WasmContractEventAbi    `json:"events"`
+}
+
+type WasmContractFunctionAbi struct {
+	Name       string                  `json:"name"`
+	Parameters []*WasmContractParamAbi `json:"parameters"`
+	ReturnType string                  `json:"returnType"`
+}
+
+type WasmContractParamAbi struct {
+	Name    string                  `json:"name"`
+	Type    string                  `json:"type"`
+	SubType []*WasmContractParamAbi `json:"subType"`
+}
+
+type WasmContractEventAbi struct {
+	Name       string                  `json:"name"`
+	Parameters []*WasmContractParamAbi `json:"parameters"`
+}
+
+func (this *WasmContractAbi) GetFunc(name string) *WasmContractFunctionAbi {
+	name = strings.ToLower(name)
+	for _, funcAbi := range this.Functions {
+		if strings.ToLower(funcAbi.Name) == name {
+			return funcAbi
+		}
+	}
+	return nil
+}
+
+func (this *WasmContractAbi) GetEvent(name string) *WasmContractEventAbi {
+	name = strings.ToLower(name)
+	for _, evtAbi := range this.Events {
+		if strings.ToLower(evtAbi.Name) == name {
+			return evtAbi
+		}
+	}
+	return nil
+}
@@ -42,7 +42,9 @@ import (
 	"github.com/imZhuFei/zeepin/account"
 	cmdcom "github.com/imZhuFei/zeepin/cmd/common"
 	"github.com/imZhuFei/zeepin/cmd/utils"
+	"github.com/imZhuFei/zeepin/common"
 	"github.com/imZhuFei/zeepin/common/config"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/token"
 	nutils "github.com/imZhuFei/zeepin/smartcontract/service/native/utils"
 	"github.com/urfave/cli"
 )
@@ -50,7 +52,7 @@ import (
 var AssetCommand = cli.Command{
 	Name:        "asset",
 	Usage:       "Handle assets",
-	Description: "Asset management commands can check account balance, ZPT/GALA transfers, extract GALAs, and view unbound GALAs, and so on.",
+	Description: "Asset management commands can check account balance, ZPT/GALA transfers, extract GALAs, view unbound GALAs, issue tokens, and so on.",
 	Subcommands: []cli.Command{
 		{
 			Action:      transfer,
@@ -109,6 +111,7 @@ var AssetCommand = cli.Command{
 			ArgsUsage: "<address|label|index>",
 			Flags: []cli.Flag{
 				utils.RPCPortFlag,
+				utils.TokenSymbolFlag,
 				utils.WalletFileFlag,
 			},
 		},
@@ -146,6 +149,66 @@ var AssetCommand = cli.Command{
 				utils.WalletFileFlag,
 			},
 		},
+		{
+			Action:      issueToken,
+			Name:        "issue",
+			Usage:       "Issue a new token by GID",
+			ArgsUsage:   " ",
+			Description: "Issue a new token in the native token factory. The signer account should own the public key of issuer GID. The token can be transferred with '--asset=<symbol>'",
+			Flags: []cli.Flag{
+				utils.RPCPortFlag,
+				utils.TransactionGasPriceFlag,
+				utils.TransactionGasLimitFlag,
+				utils.TokenIssuerFlag,
+				utils.TokenKeyNoFlag,
+				utils.TokenNameFlag,
+				utils.TokenSymbolFlag,
+				utils.TokenDecimalsFlag,
+				utils.TokenSupplyFlag,
+				utils.TokenOwnerFlag,
+				utils.TokenAuthorityFlag,
+				utils.AccountAddressFlag,
+				utils.WalletFileFlag,
+			},
+		},
+		{
+			Action:    mintToken,
+			Name:      "mint",
+			Usage:     "Mint token by the token authority",
+			ArgsUsage: " ",
+			Flags: []cli.Flag{
+				utils.RPCPortFlag,
+				utils.TransactionGasPriceFlag,
+				utils.TransactionGasLimitFlag,
+				utils.TokenSymbolFlag,
+				utils.TokenToFlag,
+				utils.TokenAmountFlag,
+				utils.WalletFileFlag,
+			},
+		},
+		{
+			Action:    burnToken,
+			Name:      "burn",
+			Usage:     "Burn token from the balance of the token authority",
+			ArgsUsage: " ",
+			Flags: []cli.Flag{
+				utils.RPCPortFlag,
+				utils.TransactionGasPriceFlag,
+				utils.TransactionGasLimitFlag,
+				utils.TokenSymbolFlag,
+				utils.TokenAmountFlag,
+				utils.WalletFileFlag,
+			},
+		},
+		{
+			Action:    tokenInfo,
+			Name:      "tokeninfo",
+			Usage:     "Show info of token",
+			ArgsUsage: "<symbol>",
+			Flags: []cli.Flag{
+				utils.RPCPortFlag,
+			},
+		},
 	},
 }
 
@@ -174,20 +237,7 @@ func transfer(ctx *cli.Context) error {
 		return fmt.Errorf("Parse to address:%s error:%s", to, err)
 	}
 
-	var amount uint64
-	amountStr := ctx.String(utils.TransactionAmountFlag.Name)
-	switch strings.ToLower(asset) {
-	case "zpt":
-		amount = utils.ParseZpt(amountStr)
-		amountStr = utils.FormatZpt(amount)
-	case "gala":
-		amount = utils.ParseGala(amountStr)
-		amountStr = utils.FormatGala(amount)
-	default:
-		return fmt.Errorf("unsupport asset:%s", asset)
-	}
-
-	err = utils.CheckAssetAmount(asset, amount)
+	amount, amountStr, err := parseAssetAmount(asset, ctx.String(utils.TransactionAmountFlag.Name))
 	if err != nil {
 		return err
 	}
@@ -251,6 +301,18 @@ func getBalance(ctx *cli.Context) error {
 	fmt.Printf("BalanceOf:%s\n", accAddr)
 	fmt.Printf("  ZPT:%s\n", utils.FormatZpt(zpt))
 	fmt.Printf("  GALA:%s\n", utils.FormatGala(gala))
+	symbol := ctx.String(utils.GetFlagName(utils.TokenSymbolFlag))
+	if symbol != "" {
+		tokenBalance, err := utils.GetTokenBalance(symbol, accAddr)
+		if err != nil {
+			return err
+		}
+		tokenBalance, err = formatAssetAmount(symbol, tokenBalance)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("  %s:%s\n", strings.ToUpper(symbol), tokenBalance)
+	}
 	return nil
 }
 
@@ -275,25 +337,18 @@ func getAllowance(ctx *cli.Context) error {
 	if err != nil {
 		return err
 	}
-	balanceStr, err := utils.GetAllowance(asset, fromAddr, toAddr)
+	var balanceStr string
+	if utils.IsTokenAsset(asset) {
+		balanceStr, err = utils.GetTokenAllowance(asset, fromAddr, toAddr)
+	} else {
+		balanceStr, err = utils.GetAllowance(asset, fromAddr, toAddr)
+	}
 	if err != nil {
 		return err
 	}
-	switch strings.ToLower(asset) {
-	case "zpt":
-		balance, err := strconv.ParseUint(balanceStr, 10, 64)
-		if err != nil {
-			return err
-		}
-		balanceStr = utils.FormatZpt(balance)
-	case "gala":
-		balance, err := strconv.ParseUint(balanceStr, 10, 64)
-		if err != nil {
-			return err
-		}
-		balanceStr = utils.FormatGala(balance)
-	default:
-		return fmt.Errorf("unsupport asset:%s", asset)
+	balanceStr, err = formatAssetAmount(asset, balanceStr)
+	if err != nil {
+		return err
 	}
 	fmt.Printf("Allowance:%s\n", asset)
 	fmt.Printf("  From:%s\n", fromAddr)
@@ -324,19 +379,7 @@ func approve(ctx *cli.Context) error {
 	if err != nil {
 		return err
 	}
-	var amount uint64
-	switch strings.ToLower(asset) {
-	case "zpt":
-		amount = utils.ParseZpt(amountStr)
-		amountStr = utils.FormatZpt(amount)
-	case "gala":
-		amount = utils.ParseGala(amountStr)
-		amountStr = utils.FormatGala(amount)
-	default:
-		return fmt.Errorf("unsupport asset:%s", asset)
-	}
-
-	err = utils.CheckAssetAmount(asset, amount)
+	amount, amountStr, err := parseAssetAmount(asset, amountStr)
 	if err != nil {
 		return err
 	}
@@ -413,19 +456,7 @@ func transferFrom(ctx *cli.Context) error {
 		return fmt.Errorf("GetAccount error:%s", err)
 	}
 
-	var amount uint64
-	switch strings.ToLower(asset) {
-	case "zpt":
-		amount = utils.ParseZpt(amountStr)
-		amountStr = utils.FormatZpt(amount)
-	case "gala":
-		amount = utils.ParseGala(amountStr)
-		amountStr = utils.FormatGala(amount)
-	default:
-		return fmt.Errorf("unsupport asset:%s", asset)
-	}
-
-	err = utils.CheckAssetAmount(asset, amount)
+	amount, amountStr, err := parseAssetAmount(asset, amountStr)
 	if err != nil {
 		return err
 	}
@@ -488,7 +519,7 @@ func unboundGala(ctx *cli.Context) error {
 func withdrawGala(ctx *cli.Context) error {
 	SetRpcPort(ctx)
 	if ctx.NArg() < 1 {
-		fmt.Println("Missing argument. Account address, label or index expected.\n")
+		fmt.Println("Missing argument. Account address, label or index expected.")
 		cli.ShowSubcommandHelp(ctx)
 		return nil
 	}
@@ -540,3 +571,237 @@ func withdrawGala(ctx *cli.Context) error {
 	fmt.Printf("  Using './zeepin info status %s' to query transaction status\n", txHash)
 	return nil
 }
+
+//parseAssetAmount parse raw amount in the precision of asset, return amount and the formatted amount string
+func parseAssetAmount(asset, amountStr string) (uint64, string, error) {
+	var amount uint64
+	switch strings.ToLower(asset) {
+	case "zpt":
+		amount = utils.ParseZpt(amountStr)
+		amountStr = utils.FormatZpt(amount)
+	case "gala":
+		amount = utils.ParseGala(amountStr)
+		amountStr = utils.FormatGala(amount)
+	default:
+		info, err := utils.GetTokenInfo(asset)
+		if err != nil {
+			return 0, "", fmt.Errorf("unsupport asset:%s, get token info error:%s", asset, err)
+		}
+		amount = utils.ParseAssetAmount(amountStr, byte(info.Decimals))
+		return amount, utils.FormatAssetAmount(amount, int(info.Decimals)), nil
+	}
+
+	err := utils.CheckAssetAmount(asset, amount)
+	if err != nil {
+		return 0, "", err
+	}
+	return amount, amountStr, nil
+}
+
+//formatAssetAmount format raw amount string in the precision of asset
+func formatAssetAmount(asset, amountStr string) (string, error) {
+	amount, err := strconv.ParseUint(amountStr, 10, 64)
+	if err != nil {
+		return "", err
+	}
+	switch strings.ToLower(asset) {
+	case "zpt":
+		return utils.FormatZpt(amount), nil
+	case "gala":
+		return utils.FormatGala(amount), nil
+	default:
+		info, err := utils.GetTokenInfo(asset)
+		if err != nil {
+			return "", fmt.Errorf("unsupport asset:%s, get token info error:%s", asset, err)
+		}
+		return utils.FormatAssetAmount(amount, int(info.Decimals)), nil
+	}
+}
+
+func getGasPrice(ctx *cli.Context) (uint64, uint64, error) {
+	gasPrice := ctx.Uint64(utils.TransactionGasPriceFlag.Name)
+	gasLimit := ctx.Uint64(utils.TransactionGasLimitFlag.Name)
+	networkId, err := utils.GetNetworkId()
+	if err != nil {
+		return 0, 0, err
+	}
+	if networkId == config.NETWORK_ID_SOLO_NET {
+		gasPrice = 0
+	}
+	return gasPrice, gasLimit, nil
+}
+
+func issueToken(ctx *cli.Context) error {
+	SetRpcPort(ctx)
+	issuer := ctx.String(utils.GetFlagName(utils.TokenIssuerFlag))
+	name := ctx.String(utils.GetFlagName(utils.TokenNameFlag))
+	symbol := ctx.String(utils.GetFlagName(utils.TokenSymbolFlag))
+	if issuer == "" || name == "" || symbol == "" {
+		fmt.Printf("Missing issuer, name or symbol argument\n")
+		cli.ShowSubcommandHelp(ctx)
+		return nil
+	}
+	decimals := ctx.Uint(utils.GetFlagName(utils.TokenDecimalsFlag))
+	if decimals > token.MAX_DECIMALS {
+		return fmt.Errorf("decimals:%d over max:%d", decimals, token.MAX_DECIMALS)
+	}
+	supply := utils.ParseAssetAmount(ctx.String(utils.GetFlagName(utils.TokenSupplyFlag)), byte(decimals))
+
+	signer, err := cmdcom.GetAccount(ctx)
+	if err != nil {
+		return fmt.Errorf("GetAccount error:%s", err)
+	}
+	param := &token.RegisterParam{
+		Issuer:      []byte(issuer),
+		KeyNo:       ctx.Uint64(utils.GetFlagName(utils.TokenKeyNoFlag)),
+		Name:        name,
+		Symbol:      strings.ToUpper(symbol),
+		Decimals:    uint64(decimals),
+		TotalSupply: supply,
+		Owner:       signer.Address,
+	}
+	if owner := ctx.String(utils.GetFlagName(utils.TokenOwnerFlag)); owner != "" {
+		ownerAddr, err := cmdcom.ParseAddress(owner, ctx)
+		if err != nil {
+			return fmt.Errorf("Parse owner address:%s error:%s", owner, err)
+		}
+		param.Owner, _ = common.AddressFromBase58(ownerAddr)
+	}
+	if authority := ctx.String(utils.GetFlagName(utils.TokenAuthorityFlag)); authority != "" {
+		authorityAddr, err := cmdcom.ParseAddress(authority, ctx)
+		if err != nil {
+			return fmt.Errorf("Parse authority address:%s error:%s", authority, err)
+		}
+		param.Authority, _ = common.AddressFromBase58(authorityAddr)
+	}
+
+	gasPrice, gasLimit, err := getGasPrice(ctx)
+	if err != nil {
+		return err
+	}
+	txHash, err := utils.IssueToken(gasPrice, gasLimit, signer, param)
+	if err != nil {
+		return fmt.Errorf("issue token error:%s", err)
+	}
+	fmt.Printf("Issue token:\n")
+	fmt.Printf("  Symbol:%s\n", param.Symbol)
+	tokenId := utils.GetTokenID(param.Symbol)
+	fmt.Printf("  TokenId:%s\n", tokenId.ToBase58())
+	fmt.Printf("  Supply:%s\n", utils.FormatAssetAmount(supply, int(decimals)))
+	fmt.Printf("  TxHash:%s\n", txHash)
+	fmt.Printf("\nTip:\n")
+	fmt.Printf("  Using './zeepin info status %s' to query transaction status\n", txHash)
+	return nil
+}
+
+func mintToken(ctx *cli.Context) error {
+	SetRpcPort(ctx)
+	symbol := ctx.String(utils.GetFlagName(utils.TokenSymbolFlag))
+	to := ctx.String(utils.GetFlagName(utils.TokenToFlag))
+	amountStr := ctx.String(utils.GetFlagName(utils.TokenAmountFlag))
+	if symbol == "" || to == "" || amountStr == "" {
+		fmt.Printf("Missing symbol, to or amount argument\n")
+		cli.ShowSubcommandHelp(ctx)
+		return nil
+	}
+	toAddr, err := cmdcom.ParseAddress(to, ctx)
+	if err != nil {
+		return err
+	}
+	info, err := utils.GetTokenInfo(symbol)
+	if err != nil {
+		return fmt.Errorf("get token info error:%s", err)
+	}
+	if !info.Mintable() {
+		return fmt.Errorf("token:%s is not mintable", info.Symbol)
+	}
+	amount := utils.ParseAssetAmount(amountStr, byte(info.Decimals))
+
+	signer, err := cmdcom.GetAccount(ctx, info.Authority.ToBase58())
+	if err != nil {
+		return fmt.Errorf("GetAccount error:%s", err)
+	}
+	gasPrice, gasLimit, err := getGasPrice(ctx)
+	if err != nil {
+		return err
+	}
+	txHash, err := utils.MintToken(gasPrice, gasLimit, signer, symbol, toAddr, amount)
+	if err != nil {
+		return fmt.Errorf("mint token error:%s", err)
+	}
+	fmt.Printf("Mint %s:\n", info.Symbol)
+	fmt.Printf("  To:%s\n", toAddr)
+	fmt.Printf("  Amount:%s\n", utils.FormatAssetAmount(amount, int(info.Decimals)))
+	fmt.Printf("  TxHash:%s\n", txHash)
+	fmt.Printf("\nTip:\n")
+	fmt.Printf("  Using './zeepin info status %s' to query transaction status\n", txHash)
+	return nil
+}
+
+func burnToken(ctx *cli.Context) error {
+	SetRpcPort(ctx)
+	symbol := ctx.String(utils.GetFlagName(utils.TokenSymbolFlag))
+	amountStr := ctx.String(utils.GetFlagName(utils.TokenAmountFlag))
+	if symbol == "" || amountStr == "" {
+		fmt.Printf("Missing symbol or amount argument\n")
+		cli.ShowSubcommandHelp(ctx)
+		return nil
+	}
+	info, err := utils.GetTokenInfo(symbol)
+	if err != nil {
+		return fmt.Errorf("get token info error:%s", err)
+	}
+	if !info.Mintable() {
+		return fmt.Errorf("token:%s is not burnable", info.Symbol)
+	}
+	amount := utils.ParseAssetAmount(amountStr, byte(info.Decimals))
+
+	signer, err := cmdcom.GetAccount(ctx, info.Authority.ToBase58())
+	if err != nil {
+		return fmt.Errorf("GetAccount error:%s", err)
+	}
+	gasPrice, gasLimit, err := getGasPrice(ctx)
+	if err != nil {
+		return err
+	}
+	txHash, err := utils.BurnToken(gasPrice, gasLimit, signer, symbol, amount)
+	if err != nil {
+		return fmt.Errorf("burn token error:%s", err)
+	}
+	fmt.Printf("Burn %s:\n", info.Symbol)
+	fmt.Printf("  From:%s\n", info.Authority.ToBase58())
+	fmt.Printf("  Amount:%s\n", utils.FormatAssetAmount(amount, int(info.Decimals)))
+	fmt.Printf("  TxHash:%s\n", txHash)
+	fmt.Printf("\nTip:\n")
+	fmt.Printf("  Using './zeepin info status %s' to query transaction status\n", txHash)
+	return nil
+}
+
+func tokenInfo(ctx *cli.Context) error {
+	SetRpcPort(ctx)
+	if ctx.NArg() < 1 {
+		fmt.Println("Missing argument. Token symbol expected.")
+		cli.ShowSubcommandHelp(ctx)
+		return nil
+	}
+	symbol := ctx.Args().First()
+	info, err := utils.GetTokenInfo(symbol)
+	if err != nil {
+		return fmt.Errorf("get token info error:%s", err)
+	}
+	supply, err := utils.GetTokenTotalSupply(symbol)
+	if err != nil {
+		return fmt.Errorf("get token total supply error:%s", err)
+	}
+	fmt.Printf("Token:%s\n", info.Symbol)
+	tokenId := utils.GetTokenID(symbol)
+	fmt.Printf("  TokenId:%s\n", tokenId.ToBase58())
+	fmt.Printf("  Name:%s\n", info.Name)
+	fmt.Printf("  Decimals:%d\n", info.Decimals)
+	fmt.Printf("  TotalSupply:%s\n", utils.FormatAssetAmount(supply, int(info.Decimals)))
+	fmt.Printf("  Issuer:%s\n", info.Issuer)
+	if info.Mintable() {
+		fmt.Printf("  Authority:%s\n", info.Authority.ToBase58())
+	}
+	return nil
+}
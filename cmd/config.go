@@ -142,6 +142,9 @@ func setGenesis(ctx *cli.Context, cfg *config.ZeepinChainConfig) error {
 func setCommonConfig(ctx *cli.Context, cfg *config.CommonConfig) {
 	cfg.LogLevel = ctx.GlobalUint(utils.GetFlagName(utils.LogLevelFlag))
 	cfg.EnableEventLog = !ctx.GlobalBool(utils.GetFlagName(utils.DisableEventLogFlag))
+	cfg.EnableAddressIndex = ctx.GlobalBool(utils.GetFlagName(utils.EnableAddressIndexFlag))
+	cfg.EnableArchive = ctx.GlobalBool(utils.GetFlagName(utils.EnableArchiveFlag))
+	cfg.PruneBlocks = uint32(ctx.GlobalUint(utils.GetFlagName(utils.PruneBlocksFlag)))
 	cfg.GasLimit = ctx.GlobalUint64(utils.GetFlagName(utils.GasLimitFlag))
 	cfg.GasPrice = ctx.GlobalUint64(utils.GetFlagName(utils.GasPriceFlag))
 	cfg.DataDir = ctx.GlobalString(utils.GetFlagName(utils.DataDirFlag))
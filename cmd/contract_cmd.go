@@ -251,9 +251,9 @@ func invokeCodeContract(ctx *cli.Context) error {
 		case 0:
 			fmt.Printf("Return: nil\n")
 		case 1:
-			fmt.Printf("Return:%+v\n", values[0])
+			fmt.Printf("Return:%s\n", returnValueString(values[0]))
 		default:
-			fmt.Printf("Return:%+v\n", values)
+			fmt.Printf("Return:%s\n", returnValueString(values))
 		}
 		return nil
 	}
@@ -356,9 +356,9 @@ func invokeContract(ctx *cli.Context) error {
 		case 0:
 			fmt.Printf("  Return: nil\n")
 		case 1:
-			fmt.Printf("  Return:%+v\n", values[0])
+			fmt.Printf("  Return:%s\n", returnValueString(values[0]))
 		default:
-			fmt.Printf("  Return:%+v\n", values)
+			fmt.Printf("  Return:%s\n", returnValueString(values))
 		}
 		return nil
 	}
@@ -392,3 +392,12 @@ func invokeContract(ctx *cli.Context) error {
 	fmt.Printf("  Using './zeepin info status %s' to query transaction status\n", txHash)
 	return nil
 }
+
+//returnValueString return the json string of parsed return value, such as struct or map
+func returnValueString(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%+v", value)
+	}
+	return string(data)
+}
@@ -86,6 +86,17 @@ var InfoCommand = cli.Command{
 				utils.RPCPortFlag,
 			},
 		},
+		{
+			Action:    authInfo,
+			Name:      "auth",
+			Usage:     "Display the permissions of contract",
+			ArgsUsage: "<contract address> [function name]",
+			Description: `Display the admin, roles, functions of role, GIDs holding role and active delegations of contract
+managed by the auth contract. Only the roles which can call the function are displayed if function name is given.`,
+			Flags: []cli.Flag{
+				utils.RPCPortFlag,
+			},
+		},
 	},
 	Description: `Query information command can query information such as blocks, transactions, and transaction executions. 
 You can use the ./zeepin info block --help command to view help information.`,
@@ -179,3 +190,23 @@ func curBlockHeight(ctx *cli.Context) error {
 	fmt.Printf("CurrentBlockHeight:%d\n", count-1)
 	return nil
 }
+
+func authInfo(ctx *cli.Context) error {
+	SetRpcPort(ctx)
+	if ctx.NArg() < 1 {
+		fmt.Println("Missing argument. Contract address expected.")
+		cli.ShowSubcommandHelp(ctx)
+		return nil
+	}
+	data, err := utils.GetContractAuth(ctx.Args().First(), ctx.Args().Get(1))
+	if err != nil {
+		return err
+	}
+	var out bytes.Buffer
+	err = json.Indent(&out, data, "", "   ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(out.String())
+	return nil
+}
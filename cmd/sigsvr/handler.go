@@ -44,4 +44,5 @@ func init() {
 	DefCliRpcSvr.RegHandler("sigembededinvoketx", handlers.SigEmbededInvokeTx)
 	DefCliRpcSvr.RegHandler("sigembededinvokeabitx", handlers.SigEmbededInvokeAbiTx)
 	DefCliRpcSvr.RegHandler("signativeinvoketx", handlers.SigNativeInvokeTx)
+	DefCliRpcSvr.RegHandler("sigwasminvoketx", handlers.SigWasmInvokeTx)
 }
@@ -0,0 +1,115 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package handlers
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/imZhuFei/zeepin/cmd/abi"
+	clisvrcom "github.com/imZhuFei/zeepin/cmd/sigsvr/common"
+	cliutil "github.com/imZhuFei/zeepin/cmd/utils"
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/common/log"
+)
+
+type SigWasmInvokeTxReq struct {
+	GasPrice uint64        `json:"gas_price"`
+	GasLimit uint64        `json:"gas_limit"`
+	Address  string        `json:"address"`
+	Method   string        `json:"method"`
+	Params   []interface{} `json:"params"`
+	Version  byte          `json:"version"`
+}
+
+type SigWasmInvokeTxRsp struct {
+	SignedTx string `json:"signed_tx"`
+}
+
+func SigWasmInvokeTx(req *clisvrcom.CliRpcRequest, resp *clisvrcom.CliRpcResponse) {
+	rawReq := &SigWasmInvokeTxReq{}
+	err := json.Unmarshal(req.Params, rawReq)
+	if err != nil {
+		log.Infof("Cli Qid:%s SigWasmInvokeTx json.Unmarshal SigWasmInvokeTxReq:%s error:%s", req.Qid, req.Params, err)
+		resp.ErrorCode = clisvrcom.CLIERR_INVALID_PARAMS
+		return
+	}
+	contractAddr, err := common.AddressFromHexString(rawReq.Address)
+	if err != nil {
+		log.Infof("Cli Qid:%s SigWasmInvokeTx AddressParseFromBytes:%s error:%s", req.Qid, rawReq.Address, err)
+		resp.ErrorCode = clisvrcom.CLIERR_INVALID_PARAMS
+		return
+	}
+	wasmAbi := abi.DefAbiMgr.GetWasmAbi(rawReq.Address)
+	if wasmAbi == nil {
+		resp.ErrorCode = clisvrcom.CLIERR_ABI_NOT_FOUND
+		return
+	}
+	funcAbi := wasmAbi.GetFunc(rawReq.Method)
+	if funcAbi == nil {
+		resp.ErrorCode = clisvrcom.CLIERR_ABI_NOT_FOUND
+		return
+	}
+	tx, err := cliutil.NewWasmInvokeTransaction(rawReq.GasPrice, rawReq.GasLimit, contractAddr, rawReq.Version, rawReq.Params, funcAbi)
+	if err != nil {
+		resp.ErrorCode = clisvrcom.CLIERR_INTERNAL_ERR
+		resp.ErrorInfo = err.Error()
+		return
+	}
+	signer := clisvrcom.DefAccount
+	err = cliutil.SignTransaction(signer, tx)
+	if err != nil {
+		log.Infof("Cli Qid:%s SigWasmInvokeTx SignTransaction error:%s", req.Qid, err)
+		resp.ErrorCode = clisvrcom.CLIERR_INTERNAL_ERR
+		return
+	}
+	immutable, err := tx.IntoImmutable()
+	if err != nil {
+		log.Infof("Cli Qid:%s SigWasmInvokeTx IntoImmutable error:%s", req.Qid, err)
+		resp.ErrorCode = clisvrcom.CLIERR_INTERNAL_ERR
+		return
+	}
+	buf := bytes.NewBuffer(nil)
+	err = immutable.Serialize(buf)
+	if err != nil {
+		log.Infof("Cli Qid:%s SigWasmInvokeTx tx Serialize error:%s", req.Qid, err)
+		resp.ErrorCode = clisvrcom.CLIERR_INTERNAL_ERR
+		return
+	}
+	resp.Result = &SigWasmInvokeTxRsp{
+		SignedTx: hex.EncodeToString(buf.Bytes()),
+	}
+}
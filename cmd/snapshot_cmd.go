@@ -0,0 +1,82 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+
+	"github.com/imZhuFei/zeepin/cmd/utils"
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/core/store/ledgerstore"
+	"github.com/urfave/cli"
+)
+
+var SnapshotCommand = cli.Command{
+	Name:      "snapshot",
+	Usage:     "Write a state snapshot of the ledger in DB to a file",
+	ArgsUsage: "",
+	Action:    writeSnapshot,
+	Flags: []cli.Flag{
+		utils.SnapshotFileFlag,
+	},
+	Description: "Snapshot is taken at the current block of the ledger in DB, so the node should be stopped first. A node with empty DB starts from the snapshot with --snapshot flag, and syncs blocks from the height of snapshot",
+}
+
+func writeSnapshot(ctx *cli.Context) error {
+	cfg, err := SetZeepinChainConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("SetZeepinChainConfig error:%s", err)
+	}
+	snapshotFile := ctx.String(utils.GetFlagName(utils.SnapshotFileFlag))
+	if snapshotFile == "" {
+		fmt.Printf("Missing file argument\n")
+		cli.ShowSubcommandHelp(ctx)
+		return nil
+	}
+	if common.FileExisted(snapshotFile) {
+		return fmt.Errorf("File:%s has already exist", snapshotFile)
+	}
+	dbDir := cfg.Common.DataDir + string(os.PathSeparator) + cfg.P2PNode.NetworkName
+
+	sf, err := os.OpenFile(snapshotFile, os.O_RDWR|os.O_CREATE, 0664)
+	if err != nil {
+		return fmt.Errorf("Open file:%s error:%s", snapshotFile, err)
+	}
+	defer sf.Close()
+	fWriter := bufio.NewWriter(sf)
+
+	fmt.Printf("Start snapshot.\n")
+	info, err := ledgerstore.WriteSnapshot(dbDir, fWriter)
+	if err == nil {
+		err = fWriter.Flush()
+	}
+	if err != nil {
+		sf.Close()
+		os.Remove(snapshotFile)
+		return fmt.Errorf("Write snapshot error:%s", err)
+	}
+	fmt.Printf("Snapshot successfully.\n")
+	fmt.Printf("Block height:%d\n", info.Height)
+	fmt.Printf("Block hash:%s\n", info.BlockHash.ToHexString())
+	fmt.Printf("State root:%s\n", info.StateRoot.ToHexString())
+	fmt.Printf("Snapshot file:%s\n", snapshotFile)
+	return nil
+}
@@ -98,10 +98,15 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.ConfigFlag,
 			utils.LogLevelFlag,
 			utils.DisableEventLogFlag,
+			utils.EnableAddressIndexFlag,
+			utils.EnableArchiveFlag,
+			utils.PruneBlocksFlag,
 			utils.DataDirFlag,
 			utils.ImportEnableFlag,
 			utils.ImportHeightFlag,
 			utils.ImportFileFlag,
+			utils.SnapshotFlag,
+			utils.SnapshotHashFlag,
 		},
 	},
 	{
@@ -241,6 +246,12 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.ExportHeightFlag,
 		},
 	},
+	{
+		Name: "SNAPSHOT",
+		Flags: []cli.Flag{
+			utils.SnapshotFileFlag,
+		},
+	},
 	{
 		Name: "MISC",
 	},
@@ -41,6 +41,7 @@ import (
 	"strings"
 
 	"github.com/imZhuFei/zeepin/cmd/abi"
+	httpcom "github.com/imZhuFei/zeepin/http/base/common"
 )
 
 func NewEmbedContractAbi(abiData []byte) (*abi.EmbedContractAbi, error) {
@@ -93,6 +94,8 @@ func ParseEmbededParam(params []string, paramsAbi []*abi.EmbededContractParamsAb
 			res, err = ParseEmbededParamString(rawParam)
 		case abi.EMBED_PARAM_TYPE_BYTE_ARRAY:
 			res, err = ParseEmbededParamByteArray(rawParam)
+		case abi.EMBED_PARAM_TYPE_ARRAY, abi.EMBED_PARAM_TYPE_STRUCT, abi.EMBED_PARAM_TYPE_MAP, abi.EMBED_PARAM_TYPE_ANY:
+			res, err = ParseEmbededParamJson(rawParam, paramAbi)
 		default:
 			return nil, fmt.Errorf("unknown param type:%s", paramAbi.Type)
 		}
@@ -139,3 +142,128 @@ func ParseEmbededParamByteArray(param string) (interface{}, error) {
 	}
 	return res, nil
 }
+
+//ParseEmbededParamJson parse compound param in json, such as [1,"foo"] or {"foo":true}
+//Struct can be express by json array in field order, or json object with field name
+func ParseEmbededParamJson(param string, paramAbi *abi.EmbededContractParamsAbi) (interface{}, error) {
+	decoder := json.NewDecoder(strings.NewReader(param))
+	decoder.UseNumber()
+	var value interface{}
+	err := decoder.Decode(&value)
+	if err != nil {
+		return nil, fmt.Errorf("json decode param:%s error:%s", param, err)
+	}
+	return parseEmbededJsonValue(value, paramAbi)
+}
+
+func parseEmbededJsonValue(value interface{}, paramAbi *abi.EmbededContractParamsAbi) (interface{}, error) {
+	pType := abi.EMBED_PARAM_TYPE_ANY
+	if paramAbi != nil {
+		pType = strings.ToLower(paramAbi.Type)
+	}
+	switch pType {
+	case abi.EMBED_PARAM_TYPE_INTEGER:
+		switch v := value.(type) {
+		case json.Number:
+			return ParseEmbededParamInteger(v.String())
+		case string:
+			return ParseEmbededParamInteger(v)
+		}
+	case abi.EMBED_PARAM_TYPE_BOOL:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			return ParseEmbededParamBoolean(v)
+		}
+	case abi.EMBED_PARAM_TYPE_STRING:
+		if v, ok := value.(string); ok {
+			return ParseEmbededParamString(v)
+		}
+	case abi.EMBED_PARAM_TYPE_BYTE_ARRAY:
+		if v, ok := value.(string); ok {
+			return ParseEmbededParamByteArray(v)
+		}
+	case abi.EMBED_PARAM_TYPE_ARRAY:
+		if v, ok := value.([]interface{}); ok {
+			return parseEmbededJsonArray(v, paramAbi.SubType)
+		}
+	case abi.EMBED_PARAM_TYPE_STRUCT:
+		return parseEmbededJsonStruct(value, paramAbi.Fields)
+	case abi.EMBED_PARAM_TYPE_MAP:
+		if v, ok := value.(map[string]interface{}); ok {
+			return parseEmbededJsonMap(v, paramAbi.SubType)
+		}
+	case abi.EMBED_PARAM_TYPE_ANY:
+		switch v := value.(type) {
+		case json.Number:
+			return ParseEmbededParamInteger(v.String())
+		case string, bool:
+			return v, nil
+		case []interface{}:
+			return parseEmbededJsonArray(v, nil)
+		case map[string]interface{}:
+			return parseEmbededJsonMap(v, nil)
+		}
+	default:
+		return nil, fmt.Errorf("unknown param type:%s", pType)
+	}
+	return nil, fmt.Errorf("param:%v does not match type:%s", value, pType)
+}
+
+func parseEmbededJsonArray(values []interface{}, elemAbi *abi.EmbededContractParamsAbi) ([]interface{}, error) {
+	res := make([]interface{}, 0, len(values))
+	for i, value := range values {
+		item, err := parseEmbededJsonValue(value, elemAbi)
+		if err != nil {
+			return nil, fmt.Errorf("parse array item:%d error:%s", i, err)
+		}
+		res = append(res, item)
+	}
+	return res, nil
+}
+
+func parseEmbededJsonStruct(value interface{}, fieldsAbi []*abi.EmbededContractParamsAbi) (httpcom.EmbeddedStruct, error) {
+	values := make([]interface{}, 0, len(fieldsAbi))
+	switch v := value.(type) {
+	case []interface{}:
+		if len(v) != len(fieldsAbi) {
+			return nil, fmt.Errorf("struct field count:%d not match abi:%d", len(v), len(fieldsAbi))
+		}
+		values = v
+	case map[string]interface{}:
+		if len(v) != len(fieldsAbi) {
+			return nil, fmt.Errorf("struct field count:%d not match abi:%d", len(v), len(fieldsAbi))
+		}
+		for _, fieldAbi := range fieldsAbi {
+			field, ok := v[fieldAbi.Name]
+			if !ok {
+				return nil, fmt.Errorf("struct missing field:%s", fieldAbi.Name)
+			}
+			values = append(values, field)
+		}
+	default:
+		return nil, fmt.Errorf("param:%v does not match type:%s", value, abi.EMBED_PARAM_TYPE_STRUCT)
+	}
+	res := make(httpcom.EmbeddedStruct, 0, len(values))
+	for i, fieldAbi := range fieldsAbi {
+		field, err := parseEmbededJsonValue(values[i], fieldAbi)
+		if err != nil {
+			return nil, fmt.Errorf("parse struct field:%s error:%s", fieldAbi.Name, err)
+		}
+		res = append(res, field)
+	}
+	return res, nil
+}
+
+func parseEmbededJsonMap(values map[string]interface{}, valueAbi *abi.EmbededContractParamsAbi) (map[string]interface{}, error) {
+	res := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		item, err := parseEmbededJsonValue(value, valueAbi)
+		if err != nil {
+			return nil, fmt.Errorf("parse map key:%s error:%s", key, err)
+		}
+		res[key] = item
+	}
+	return res, nil
+}
@@ -36,6 +36,9 @@ package utils
 import (
 	"fmt"
 	"testing"
+
+	httpcom "github.com/imZhuFei/zeepin/http/base/common"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestParseEmbededFunc(t *testing.T) {
@@ -92,3 +95,66 @@ func TestParseEmbededFunc(t *testing.T) {
 	}
 	fmt.Printf("TestParseEmbededFunc %v\n", params)
 }
+
+func TestParseEmbededCompoundParam(t *testing.T) {
+	var testEmbededAbi = `{
+  "hash": "0xe827bf96529b5780ad0702757b8bad315e2bb8ce",
+  "entrypoint": "Main",
+  "functions": [
+    {
+      "name": "Register",
+      "parameters": [
+        {
+          "name": "owner",
+          "type": "Struct",
+          "fields": [
+            {"name": "name", "type": "String"},
+            {"name": "id", "type": "ByteArray"},
+            {"name": "tags", "type": "Array", "subtype": {"type": "String"}}
+          ]
+        },
+        {
+          "name": "balances",
+          "type": "Map",
+          "subtype": {"type": "Integer"}
+        },
+        {
+          "name": "args",
+          "type": "Array"
+        }
+      ],
+      "returntype": "Boolean"
+    }
+  ],
+  "events": []
+}`
+	contractAbi, err := NewEmbedContractAbi([]byte(testEmbededAbi))
+	assert.Nil(t, err)
+	funcAbi := contractAbi.GetFunc("Register")
+	assert.NotNil(t, funcAbi)
+
+	params, err := ParseEmbededFunc([]string{
+		`{"id":"0102","name":"foo","tags":["a","b"]}`,
+		`{"zpt":10,"gala":"20"}`,
+		`[1,"foo",true,[2]]`,
+	}, funcAbi)
+	assert.Nil(t, err)
+	expect := []interface{}{
+		"register",
+		[]interface{}{
+			httpcom.EmbeddedStruct{"foo", []byte{1, 2}, []interface{}{"a", "b"}},
+			map[string]interface{}{"zpt": int64(10), "gala": int64(20)},
+			[]interface{}{int64(1), "foo", true, []interface{}{int64(2)}},
+		},
+	}
+	assert.Equal(t, expect, params)
+
+	params, err = ParseEmbededFunc([]string{`["foo","0102",[]]`, `{}`, `[]`}, funcAbi)
+	assert.Nil(t, err)
+	assert.Equal(t, httpcom.EmbeddedStruct{"foo", []byte{1, 2}, []interface{}{}}, params[1].([]interface{})[0])
+
+	_, err = ParseEmbededFunc([]string{`["foo","0102"]`, `{}`, `[]`}, funcAbi)
+	assert.NotNil(t, err)
+	_, err = ParseEmbededFunc([]string{`["foo","0102",[]]`, `{"zpt":true}`, `[]`}, funcAbi)
+	assert.NotNil(t, err)
+}
@@ -45,8 +45,9 @@ import (
 )
 
 const (
-	DEFAULT_EXPORT_FILE = "./blocks.dat"
-	DEFAULT_ABI_PATH    = "./abi"
+	DEFAULT_EXPORT_FILE   = "./blocks.dat"
+	DEFAULT_SNAPSHOT_FILE = "./snapshot.dat"
+	DEFAULT_ABI_PATH      = "./abi"
 )
 
 var (
@@ -64,6 +65,26 @@ var (
 		Name:  "disableeventlog",
 		Usage: "If set disableeventlog flag, zeepin will not record event log output by smart contract",
 	}
+	EnableAddressIndexFlag = cli.BoolFlag{
+		Name:  "enableaddressindex",
+		Usage: "If set enableaddressindex flag, zeepin will index transactions and ZPT/GALA transfers by address. It needs the event log. Blocks saved before the flag is set are not indexed, the index starts from the next block",
+	}
+	EnableArchiveFlag = cli.BoolFlag{
+		Name:  "enablearchive",
+		Usage: "If set enablearchive flag, zeepin will keep the history of states, so that storage, balance and pre-execution can be queried at a past height, and storage proofs are kept for all blocks",
+	}
+	PruneBlocksFlag = cli.UintFlag{
+		Name:  "pruneblocks",
+		Usage: "Prune the transactions and events of blocks older than the recent `<number>` blocks, block headers are kept. At least 128 recent blocks are kept. The default value is 0, which means no pruning",
+	}
+	SnapshotFlag = cli.StringFlag{
+		Name:  "snapshot",
+		Usage: "Start the node with empty ledger from the snapshot `<filename>` written by snapshot command, and sync blocks from the height of snapshot",
+	}
+	SnapshotHashFlag = cli.StringFlag{
+		Name:  "snapshothash",
+		Usage: "Hash of the block at the height of snapshot, got from a trusted source such as your own synced node. Required with --snapshot",
+	}
 	WalletFileFlag = cli.StringFlag{
 		Name:  "wallet,w",
 		Value: config.DEFAULT_WALLET_FILE_NAME,
@@ -324,7 +345,7 @@ var (
 	}
 	ContractParamsFlag = cli.StringFlag{
 		Name:  "params",
-		Usage: "Invoke contract parameters list. use comma ',' to split params, and must add type prefix to params. Param type support bytearray(hexstring), string, integer, boolean,For example: string:foo,int:0,bool:true; If parameter is an object array, enclose array with '[]'. For example:  string:foo,[int:0,bool:true]",
+		Usage: "Invoke contract parameters list. use comma ',' to split params, and must add type prefix to params. Param type support bytearray(hexstring), string, integer, boolean,For example: string:foo,int:0,bool:true; If parameter is an object array, enclose array with '[]'. For example:  string:foo,[int:0,bool:true]; Struct and map parameter use 'struct:[]' and 'map:[]', map key split with '='. For example: struct:[int:0,string:foo],map:[foo=int:0,bar=bool:true]",
 	}
 	ContractAttrFlag = cli.Int64Flag{
 		Name:  "attr,t",
@@ -337,7 +358,7 @@ var (
 	}
 	ContractParamTypeFlag = cli.Int64Flag{
 		Name:  "paramtype",
-		Usage: "method param type: 0: json, 1: raw, default: 0",
+		Usage: "method param type: 0: json, 1: raw, 2: binary, default: 0",
 		Value: 0,
 	}
 	ContractPrepareDeployFlag = cli.BoolFlag{
@@ -350,7 +371,7 @@ var (
 	}
 	ContractReturnTypeFlag = cli.StringFlag{
 		Name:  "return",
-		Usage: "Return type of contract.Return type support bytearray(hexstring), string, integer, boolean. If return type is object array, enclose array with '[]'. For example [string,int,bool,string]. Only prepare invoke need this flag.",
+		Usage: "Return type of contract.Return type support bytearray(hexstring), string, integer, boolean. If return type is object array, enclose array with '[]'. For example [string,int,bool,string]. Struct return type use 'struct:[]', map return type enclose the value type with 'map:[]'. For example struct:[int,string],map:[int]. Only prepare invoke need this flag.",
 	}
 
 	//information cmd settings
@@ -366,7 +387,7 @@ var (
 	//Transfer setting
 	TransactionAssetFlag = cli.StringFlag{
 		Name:  "asset",
-		Usage: "Using to specifies the transfer asset `<zpt|gala>`",
+		Usage: "Using to specifies the transfer asset `<zpt|gala|token symbol>`",
 		Value: ASSET_ZPT,
 	}
 	TransactionFromFlag = cli.StringFlag{
@@ -407,7 +428,7 @@ var (
 	}
 	ApproveAssetFlag = cli.StringFlag{
 		Name:  "asset",
-		Usage: "Using to specifies the transfer asset <zpt|gala> for approve",
+		Usage: "Using to specifies the transfer asset <zpt|gala|token symbol> for approve",
 		Value: "zpt",
 	}
 	ApproveAmountFlag = cli.StringFlag{
@@ -423,6 +444,50 @@ var (
 		Usage: "Using to specifies the sender account `<address|label|index>` of transfer from transaction, if empty sender is to account",
 	}
 
+	//Token setting
+	TokenSymbolFlag = cli.StringFlag{
+		Name:  "symbol",
+		Usage: "Using to specifies the token `<symbol>`, only upper case letters and digits",
+	}
+	TokenNameFlag = cli.StringFlag{
+		Name:  "name",
+		Usage: "Using to specifies the token `<name>`",
+	}
+	TokenDecimalsFlag = cli.UintFlag{
+		Name:  "decimals",
+		Usage: "Using to specifies the decimals of token amount",
+	}
+	TokenSupplyFlag = cli.StringFlag{
+		Name:  "supply",
+		Usage: "Using to specifies the initial supply of token, in the precision of token decimals",
+		Value: "0",
+	}
+	TokenIssuerFlag = cli.StringFlag{
+		Name:  "issuer",
+		Usage: "Using to specifies the GID `<gid>` which issues the token",
+	}
+	TokenKeyNoFlag = cli.Uint64Flag{
+		Name:  "keyno",
+		Usage: "Using to specifies the index of issuer GID public key, which is owned by the signer account",
+		Value: 1,
+	}
+	TokenOwnerFlag = cli.StringFlag{
+		Name:  "owner",
+		Usage: "Using to specifies the account `<address|label|index>` which receives the initial supply, if empty using the signer account",
+	}
+	TokenAuthorityFlag = cli.StringFlag{
+		Name:  "authority",
+		Usage: "Using to specifies the mint and burn authority account `<address|label|index>`, if empty the token supply is fixed",
+	}
+	TokenToFlag = cli.StringFlag{
+		Name:  "to",
+		Usage: "Using to specifies the account `<address|label|index>` which receives the minted token",
+	}
+	TokenAmountFlag = cli.StringFlag{
+		Name:  "amount",
+		Usage: "Using to specifies the token amount to mint or burn",
+	}
+
 	//Cli setting
 	CliRpcPortFlag = cli.UintFlag{
 		Name:  "cliport",
@@ -452,6 +517,13 @@ var (
 		Value: "m",
 	}
 
+	//Snapshot setting
+	SnapshotFileFlag = cli.StringFlag{
+		Name:  "file",
+		Usage: "Path of snapshot file",
+		Value: DEFAULT_SNAPSHOT_FILE,
+	}
+
 	//PreExecute switcher
 	TxpoolPreExecDisableFlag = cli.BoolFlag{
 		Name:  "disabletxpoolpreexec",
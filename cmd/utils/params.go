@@ -40,6 +40,8 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+
+	httpcom "github.com/imZhuFei/zeepin/http/base/common"
 )
 
 const (
@@ -51,14 +53,30 @@ const (
 	PARAM_TYPE_INTEGER    = "int"
 	PARAM_TYPE_INTEGER64  = "int64"
 	PARAM_TYPE_BOOLEAN    = "bool"
+	PARAM_TYPE_STRUCT     = "struct"
+	PARAM_TYPE_MAP        = "map"
 	PARAM_LEFT_BRACKET    = "["
 	PARAM_RIGHT_BRACKET   = "]"
+	PARAM_MAP_KEY_SPLIT   = "="
 )
 
+//rawParamGroup is a bracketed param list with a type prefix, such as struct:[int:0,string:foo]
+type rawParamGroup struct {
+	Type  string
+	Items []interface{}
+}
+
+//TypeName return the group type without type split
+func (this *rawParamGroup) TypeName() string {
+	return strings.ToLower(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(this.Type), PARAM_TYPE_SPLIT)))
+}
+
 //ParseParams return interface{} array of encode params item.
 //A param item compose of type and value, type can be: bytearray, string, int, bool
 //Param type and param value split with ":", such as int:10
 //Param array can be express with "[]", such [int:10,string:foo], param array can be nested, such as [int:10,[int:12,bool:true]]
+//Param struct can be express with "struct:[]", such as struct:[int:10,string:foo]
+//Param map can be express with "map:[]" and key split with "=", such as map:[foo=int:10,bar=[bool:true]]
 //A raw params example: string:foo,[int:0,[bool:true,string:bar],bool:false]
 func ParseParams(rawParamStr string) ([]interface{}, error) {
 	rawParams, _, err := parseRawParamsString(rawParamStr)
@@ -89,13 +107,16 @@ func parseRawParamsString(rawParamStr string) ([]interface{}, int, error) {
 			if index == totalSize-1 {
 				return rawParamItems, 0, nil
 			}
-			//clear current param as invalid input
+			//current param is the type prefix of bracketed params
+			prefix := strings.TrimSpace(curRawParam)
 			curRawParam = ""
 			items, size, err := parseRawParamsString(string(rawParamStr[i+1:]))
 			if err != nil {
 				return nil, 0, fmt.Errorf("parse params error:%s", err)
 			}
-			if len(items) > 0 {
+			if prefix != "" {
+				rawParamItems = append(rawParamItems, &rawParamGroup{Type: prefix, Items: items})
+			} else if len(items) > 0 {
 				rawParamItems = append(rawParamItems, items)
 			}
 			i += size
@@ -135,6 +156,12 @@ func parseRawParams(rawParams []interface{}) ([]interface{}, error) {
 				return nil, err
 			}
 			params = append(params, res)
+		case *rawParamGroup:
+			res, err := parseRawParamGroup(v)
+			if err != nil {
+				return nil, err
+			}
+			params = append(params, res)
 		default:
 			return nil, fmt.Errorf("unknown param type:%s", reflect.TypeOf(rawParam))
 		}
@@ -142,6 +169,70 @@ func parseRawParams(rawParams []interface{}) ([]interface{}, error) {
 	return params, nil
 }
 
+func parseRawParamGroup(group *rawParamGroup) (interface{}, error) {
+	pType := group.TypeName()
+	switch pType {
+	case PARAM_TYPE_ARRAY:
+		res, err := parseRawParams(group.Items)
+		if err != nil {
+			return nil, err
+		}
+		if res == nil {
+			res = make([]interface{}, 0)
+		}
+		return res, nil
+	case PARAM_TYPE_STRUCT:
+		res, err := parseRawParams(group.Items)
+		if err != nil {
+			return nil, err
+		}
+		return httpcom.EmbeddedStruct(res), nil
+	case PARAM_TYPE_MAP:
+		return parseRawParamMap(group.Items)
+	default:
+		return nil, fmt.Errorf("unspport param type:%s", pType)
+	}
+}
+
+func parseRawParamMap(rawParams []interface{}) (map[string]interface{}, error) {
+	res := make(map[string]interface{}, len(rawParams))
+	for _, rawParam := range rawParams {
+		var key string
+		var value interface{}
+		var err error
+		switch v := rawParam.(type) {
+		case string:
+			kv := strings.SplitN(v, PARAM_MAP_KEY_SPLIT, 2)
+			if len(kv) != 2 {
+				return nil, fmt.Errorf("invalid map item:%s", v)
+			}
+			key = strings.TrimSpace(kv[0])
+			value, err = parseRawParam(kv[1])
+		case *rawParamGroup:
+			kv := strings.SplitN(v.Type, PARAM_MAP_KEY_SPLIT, 2)
+			if len(kv) != 2 {
+				return nil, fmt.Errorf("invalid map item:%s", v.Type)
+			}
+			key = strings.TrimSpace(kv[0])
+			if strings.TrimSpace(kv[1]) == "" {
+				value, err = parseRawParamGroup(&rawParamGroup{Type: PARAM_TYPE_ARRAY, Items: v.Items})
+			} else {
+				value, err = parseRawParamGroup(&rawParamGroup{Type: kv[1], Items: v.Items})
+			}
+		default:
+			return nil, fmt.Errorf("map item missing key:%v", rawParam)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := res[key]; ok {
+			return nil, fmt.Errorf("duplicate map key:%s", key)
+		}
+		res[key] = value
+	}
+	return res, nil
+}
+
 func parseRawParam(rawParam string) (interface{}, error) {
 	rawParam = strings.TrimSpace(rawParam)
 	rawParam = strings.Trim(rawParam, PARAMS_SPLIT)
@@ -203,6 +294,8 @@ func parseRawParamValue(pType string, pValue string) (interface{}, error) {
 //Return type can be: bytearray, string, int, bool.
 //Types can be split with "," each other, such as int,string,bool
 //Type array can be express with "[]", such [int,string], param array can be nested, such as [int,[int,bool]]
+//Type struct can be express with "struct:[]", such as struct:[int,string]
+//Type map can be express with "map:[]" enclosing the type of map values, such as map:[int]. Map keys are decoded as string
 func ParseReturnValue(rawValue interface{}, rawReturnTypeStr string) ([]interface{}, error) {
 	returnTypes, _, err := parseRawParamsString(rawReturnTypeStr)
 	if err != nil {
@@ -219,47 +312,88 @@ func ParseReturnValue(rawValue interface{}, rawReturnTypeStr string) ([]interfac
 func parseReturnValueArray(rawValues []interface{}, returnTypes []interface{}) ([]interface{}, error) {
 	values := make([]interface{}, 0)
 	for i := 0; i < len(rawValues); i++ {
-		rawValue := rawValues[i]
 		if i == len(returnTypes) {
 			return values, nil
 		}
-		valueType := returnTypes[i]
+		value, err := parseReturnValue(rawValues[i], returnTypes[i])
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
 
+func parseReturnValue(rawValue interface{}, valueType interface{}) (interface{}, error) {
+	switch v := rawValue.(type) {
+	case string:
+		vType, ok := valueType.(string)
+		if !ok {
+			return nil, fmt.Errorf("Parse return value:%s types:%v failed, types doesnot match", v, valueType)
+		}
+		var value interface{}
 		var err error
-		switch v := rawValue.(type) {
-		case string:
-			var value interface{}
-			vType := valueType.(string)
-			switch strings.ToLower(vType) {
-			case PARAM_TYPE_BYTE_ARRAY:
-				value, err = ParseEmbeddedContractReturnTypeByteArray(v)
-			case PARAM_TYPE_STRING:
-				value, err = ParseEmbeddedContractReturnTypeString(v)
-			case PARAM_TYPE_INTEGER:
-				value, err = ParseEmbededContractReturnTypeInteger(v)
-			case PARAM_TYPE_BOOLEAN:
-				value, err = ParseEmbeddedContractReturnTypeBool(v)
+		switch strings.ToLower(vType) {
+		case PARAM_TYPE_BYTE_ARRAY:
+			value, err = ParseEmbeddedContractReturnTypeByteArray(v)
+		case PARAM_TYPE_STRING:
+			value, err = ParseEmbeddedContractReturnTypeString(v)
+		case PARAM_TYPE_INTEGER, PARAM_TYPE_INTEGER64:
+			value, err = ParseEmbededContractReturnTypeInteger(v)
+		case PARAM_TYPE_BOOLEAN:
+			value, err = ParseEmbeddedContractReturnTypeBool(v)
+		default:
+			return nil, fmt.Errorf("unknown return type:%s", vType)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Parse return value:%s type:%s error:%s", v, vType, err)
+		}
+		return value, nil
+	case []interface{}:
+		var valueTypes []interface{}
+		switch vt := valueType.(type) {
+		case []interface{}:
+			valueTypes = vt
+		case *rawParamGroup:
+			pType := vt.TypeName()
+			switch pType {
+			case PARAM_TYPE_ARRAY, PARAM_TYPE_STRUCT:
+				valueTypes = vt.Items
 			default:
-				return nil, fmt.Errorf("unknown return type:%s", v)
+				return nil, fmt.Errorf("Parse return value:%+v types:%s failed, types doesnot match", v, pType)
 			}
-			values = append(values, value)
+		default:
+			return nil, fmt.Errorf("Parse return value:%+v types:%v failed, types doesnot match", v, valueType)
+		}
+		values, err := parseReturnValueArray(v, valueTypes)
+		if err != nil {
+			return nil, fmt.Errorf("Parse return values:%+v types:%v error:%s", v, valueTypes, err)
+		}
+		return values, nil
+	case map[string]interface{}:
+		group, ok := valueType.(*rawParamGroup)
+		if !ok || group.TypeName() != PARAM_TYPE_MAP {
+			return nil, fmt.Errorf("Parse return value:%+v types:%v failed, types doesnot match", v, valueType)
+		}
+		if len(group.Items) != 1 {
+			return nil, fmt.Errorf("map return type should have one value type")
+		}
+		values := make(map[string]interface{}, len(v))
+		for rawKey, rawItem := range v {
+			key, err := ParseEmbeddedContractReturnTypeString(rawKey)
 			if err != nil {
-				return nil, fmt.Errorf("Parse return value:%s type:byte array error:%s", v, err)
-			}
-		case []interface{}:
-			valueTypes, ok := valueType.([]interface{})
-			if !ok {
-				return nil, fmt.Errorf("Parse return value:%+v types:%s failed, types doesnot match", v, valueType)
+				return nil, fmt.Errorf("Parse return map key:%s error:%s", rawKey, err)
 			}
-			values, err := parseReturnValueArray(v, valueTypes)
+			value, err := parseReturnValue(rawItem, group.Items[0])
 			if err != nil {
-				return nil, fmt.Errorf("Parese return values:%+v types:%s error:%s", values, valueType, err)
+				return nil, fmt.Errorf("Parse return map value of key:%s error:%s", key, err)
 			}
-		default:
-			return nil, fmt.Errorf("unknown return type:%s", reflect.TypeOf(rawValue))
+			values[key] = value
 		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("unknown return type:%s", reflect.TypeOf(rawValue))
 	}
-	return values, nil
 }
 
 //EmbeddedInvokeParam use to express the param to invoke embedded contract.
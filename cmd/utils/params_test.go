@@ -39,6 +39,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"testing"
+
+	httpcom "github.com/imZhuFei/zeepin/http/base/common"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestParseRawParamsArray(t *testing.T) {
@@ -92,6 +95,49 @@ func TestParseParams(t *testing.T) {
 	}
 }
 
+func TestParseStructMapParams(t *testing.T) {
+	rawParamStr := "struct:[int:1,bytearray:0102,[string:foo]],map:[foo=int:2,bar=struct:[bool:true],baz=[int:3]],map:[]"
+	params, err := ParseParams(rawParamStr)
+	assert.Nil(t, err)
+	expect := []interface{}{
+		httpcom.EmbeddedStruct{1, []byte{1, 2}, []interface{}{"foo"}},
+		map[string]interface{}{
+			"foo": 2,
+			"bar": httpcom.EmbeddedStruct{true},
+			"baz": []interface{}{3},
+		},
+		map[string]interface{}{},
+	}
+	assert.Equal(t, expect, params)
+
+	_, err = ParseParams("map:[int:1]")
+	assert.NotNil(t, err)
+	_, err = ParseParams("map:[foo=int:1,foo=int:2]")
+	assert.NotNil(t, err)
+}
+
+func TestParseReturnValue(t *testing.T) {
+	hexStr := func(s string) string { return hex.EncodeToString([]byte(s)) }
+	rawValue := []interface{}{
+		"0a",
+		[]interface{}{"01", []interface{}{hexStr("foo")}},
+		[]interface{}{hexStr("bar"), "00"},
+		map[string]interface{}{hexStr("a"): "02", hexStr("b"): "03"},
+	}
+	values, err := ParseReturnValue(rawValue, "int,[bool,[string]],struct:[string,bool],map:[int]")
+	assert.Nil(t, err)
+	expect := []interface{}{
+		int64(10),
+		[]interface{}{true, []interface{}{"foo"}},
+		[]interface{}{"bar", false},
+		map[string]interface{}{"a": int64(2), "b": int64(3)},
+	}
+	assert.Equal(t, expect, values)
+
+	_, err = ParseReturnValue(rawValue, "int,[bool,[string]],struct:[string,bool],[int]")
+	assert.NotNil(t, err)
+}
+
 func arrayEqual(a1, a2 []interface{}) (bool, error) {
 	data1, err := json.Marshal(a1)
 	if err != nil {
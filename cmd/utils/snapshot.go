@@ -0,0 +1,59 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/common/log"
+	"github.com/imZhuFei/zeepin/core/store/ledgerstore"
+)
+
+//ImportSnapshot import the snapshot file taken at the trusted block of blockHash into the ledger in dataDir before
+//the ledger is opened. Snapshot is skipped if the ledger already exists, so the node can be restarted with the same flags
+func ImportSnapshot(dataDir, snapshotFile, blockHash string) error {
+	if blockHash == "" {
+		return fmt.Errorf("missing trusted block hash of snapshot, set it by --%s", GetFlagName(SnapshotHashFlag))
+	}
+	trustedHash, err := common.Uint256FromHexString(blockHash)
+	if err != nil {
+		return fmt.Errorf("invalid block hash:%s error:%s", blockHash, err)
+	}
+	sfile, err := os.OpenFile(snapshotFile, os.O_RDONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer sfile.Close()
+
+	log.Infof("Start import snapshot:%s", snapshotFile)
+	info, err := ledgerstore.ImportSnapshot(dataDir, bufio.NewReader(sfile), trustedHash)
+	if err == ledgerstore.ErrLedgerExisted {
+		log.Infof("Ledger already exists, skip import snapshot")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	log.Infof("Import snapshot complete, block height:%d block hash:%s state root:%s",
+		info.Height, info.BlockHash.ToHexString(), info.StateRoot.ToHexString())
+	return nil
+}
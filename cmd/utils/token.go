@@ -0,0 +1,153 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/imZhuFei/zeepin/account"
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/embed/simulator/types"
+	httpcom "github.com/imZhuFei/zeepin/http/base/common"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/token"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/utils"
+)
+
+const (
+	VERSION_CONTRACT_TOKEN = byte(0)
+)
+
+//IsTokenAsset return whether asset is a token issued by the token factory instead of zpt or gala
+func IsTokenAsset(asset string) bool {
+	switch strings.ToLower(asset) {
+	case ASSET_ZPT, ASSET_GALA:
+		return false
+	}
+	return true
+}
+
+//GetTokenID return token id of token symbol, symbol is case insensitive in cli
+func GetTokenID(symbol string) common.Address {
+	return token.GenTokenID(strings.ToUpper(symbol))
+}
+
+func prepareInvokeToken(method string, params []interface{}) ([]byte, error) {
+	preResult, err := PrepareInvokeNativeContract(utils.TokenContractAddress, VERSION_CONTRACT_TOKEN, method, params)
+	if err != nil {
+		return nil, err
+	}
+	result, ok := preResult.Result.(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid %s result:%v", method, preResult.Result)
+	}
+	return common.HexToBytes(result)
+}
+
+//GetTokenInfo return token info of token symbol
+func GetTokenInfo(symbol string) (*token.TokenInfo, error) {
+	data, err := prepareInvokeToken(token.TOKENINFO_NAME, []interface{}{GetTokenID(symbol)})
+	if err != nil {
+		return nil, err
+	}
+	info := &token.TokenInfo{}
+	err = info.Deserialize(bytes.NewBuffer(data))
+	if err != nil {
+		return nil, fmt.Errorf("deserialize token info error:%s", err)
+	}
+	return info, nil
+}
+
+//GetTokenTotalSupply return total supply of token symbol
+func GetTokenTotalSupply(symbol string) (uint64, error) {
+	data, err := prepareInvokeToken(token.TOTALSUPPLY_NAME, []interface{}{GetTokenID(symbol)})
+	if err != nil {
+		return 0, err
+	}
+	return types.BigIntFromBytes(data).Uint64(), nil
+}
+
+//GetTokenBalance return token balance of address in base58 code
+func GetTokenBalance(symbol, address string) (string, error) {
+	addr, err := common.AddressFromBase58(address)
+	if err != nil {
+		return "", fmt.Errorf("address:%s invalid:%s", address, err)
+	}
+	data, err := prepareInvokeToken(token.BALANCEOF_NAME, []interface{}{httpcom.EmbeddedStruct{GetTokenID(symbol), addr}})
+	if err != nil {
+		return "", err
+	}
+	return types.BigIntFromBytes(data).String(), nil
+}
+
+//GetTokenAllowance return token approve balance from account to another account
+func GetTokenAllowance(symbol, from, to string) (string, error) {
+	fromAddr, err := common.AddressFromBase58(from)
+	if err != nil {
+		return "", fmt.Errorf("from address:%s invalid:%s", from, err)
+	}
+	toAddr, err := common.AddressFromBase58(to)
+	if err != nil {
+		return "", fmt.Errorf("to address:%s invalid:%s", to, err)
+	}
+	data, err := prepareInvokeToken(token.ALLOWANCE_NAME, []interface{}{httpcom.EmbeddedStruct{GetTokenID(symbol), fromAddr, toAddr}})
+	if err != nil {
+		return "", err
+	}
+	return types.BigIntFromBytes(data).String(), nil
+}
+
+//FormatToken return token amount in the precision of token decimals
+func FormatToken(amount string, decimals uint64) (string, error) {
+	value, err := strconv.ParseUint(amount, 10, 64)
+	if err != nil {
+		return "", err
+	}
+	return FormatAssetAmount(value, int(decimals)), nil
+}
+
+//IssueToken register a new token in token factory, the signer should own the key of issuer GID
+func IssueToken(gasPrice, gasLimit uint64, signer *account.Account, param *token.RegisterParam) (string, error) {
+	return InvokeNativeContract(gasPrice, gasLimit, signer, utils.TokenContractAddress, VERSION_CONTRACT_TOKEN, token.REGISTER_NAME, []interface{}{param})
+}
+
+//MintToken mint token to account, the signer should be the token authority
+func MintToken(gasPrice, gasLimit uint64, signer *account.Account, symbol, to string, amount uint64) (string, error) {
+	toAddr, err := common.AddressFromBase58(to)
+	if err != nil {
+		return "", fmt.Errorf("to address:%s invalid:%s", to, err)
+	}
+	param := &token.MintParam{
+		Token: GetTokenID(symbol),
+		To:    toAddr,
+		Value: amount,
+	}
+	return InvokeNativeContract(gasPrice, gasLimit, signer, utils.TokenContractAddress, VERSION_CONTRACT_TOKEN, token.MINT_NAME, []interface{}{param})
+}
+
+//BurnToken burn token from the balance of the token authority
+func BurnToken(gasPrice, gasLimit uint64, signer *account.Account, symbol string, amount uint64) (string, error) {
+	param := &token.BurnParam{
+		Token: GetTokenID(symbol),
+		Value: amount,
+	}
+	return InvokeNativeContract(gasPrice, gasLimit, signer, utils.TokenContractAddress, VERSION_CONTRACT_TOKEN, token.BURN_NAME, []interface{}{param})
+}
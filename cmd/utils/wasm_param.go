@@ -0,0 +1,172 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package utils
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/imZhuFei/zeepin/cmd/abi"
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/core/types"
+	httpcom "github.com/imZhuFei/zeepin/http/base/common"
+	"github.com/imZhuFei/zeepin/smartcontract/service/wasmvm"
+	wasmabi "github.com/imZhuFei/zeepin/vm/wasmvm/abi"
+)
+
+func NewWasmInvokeTransaction(gasPrice, gasLimit uint64, contractAddr common.Address, version byte, params []interface{}, funcAbi *abi.WasmContractFunctionAbi) (*types.MutableTransaction, error) {
+	values, err := ParseWasmParams(params, funcAbi.Parameters)
+	if err != nil {
+		return nil, err
+	}
+	return httpcom.NewWASMVMInvokeTransaction(gasPrice, gasLimit, contractAddr, funcAbi.Name, wasmvm.Binary, version, values)
+}
+
+//ParseWasmParams convert the string params to the values of wasm binary abi
+func ParseWasmParams(params []interface{}, paramsAbi []*abi.WasmContractParamAbi) ([]interface{}, error) {
+	if len(params) != len(paramsAbi) {
+		return nil, fmt.Errorf("abi unmatch")
+	}
+	values := make([]interface{}, 0, len(params))
+	for i, param := range params {
+		paramAbi := paramsAbi[i]
+		value, err := ParseWasmParam(param, paramAbi)
+		if err != nil {
+			return nil, fmt.Errorf("param:%s parse:%v error:%s", paramAbi.Name, param, err)
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+func ParseWasmParam(param interface{}, paramAbi *abi.WasmContractParamAbi) (interface{}, error) {
+	switch strings.ToLower(paramAbi.Type) {
+	case abi.WASM_PARAM_TYPE_STRUCT:
+		return ParseWasmParamStruct(param, paramAbi)
+	case abi.WASM_PARAM_TYPE_LIST:
+		return ParseWasmParamList(param, paramAbi)
+	}
+	rawParam, ok := param.(string)
+	if !ok {
+		return nil, fmt.Errorf("param:%v assert to string failed", param)
+	}
+	rawParam = strings.TrimSpace(rawParam)
+	switch strings.ToLower(paramAbi.Type) {
+	case abi.WASM_PARAM_TYPE_BYTES:
+		data, err := hex.DecodeString(rawParam)
+		if err != nil {
+			return nil, fmt.Errorf("hex decode string error:%s", err)
+		}
+		return data, nil
+	case abi.WASM_PARAM_TYPE_STRING:
+		return rawParam, nil
+	case abi.WASM_PARAM_TYPE_BOOL:
+		switch strings.ToLower(rawParam) {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		default:
+			return nil, fmt.Errorf("invalid bool value")
+		}
+	case abi.WASM_PARAM_TYPE_ADDRESS:
+		return ParseWasmParamAddress(rawParam)
+	case abi.WASM_PARAM_TYPE_INT128:
+		return ParseWasmParamInteger(rawParam)
+	case abi.WASM_PARAM_TYPE_UINT128:
+		value, err := ParseWasmParamInteger(rawParam)
+		if err != nil {
+			return nil, err
+		}
+		if value.Sign() < 0 {
+			return nil, fmt.Errorf("invalid uint128 value")
+		}
+		return wasmabi.Uint128{Value: value}, nil
+	default:
+		return nil, fmt.Errorf("unknown param type:%s", paramAbi.Type)
+	}
+}
+
+func ParseWasmParamStruct(param interface{}, structAbi *abi.WasmContractParamAbi) (interface{}, error) {
+	params, ok := param.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("assert to []interface{} failed")
+	}
+	if len(params) != len(structAbi.SubType) {
+		return nil, fmt.Errorf("struct abi not match")
+	}
+	fields, err := ParseWasmParams(params, structAbi.SubType)
+	if err != nil {
+		return nil, fmt.Errorf("params struct:%s error:%s", structAbi.Name, err)
+	}
+	return wasmabi.Struct(fields), nil
+}
+
+func ParseWasmParamList(param interface{}, listAbi *abi.WasmContractParamAbi) (interface{}, error) {
+	params, ok := param.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("assert to []interface{} failed")
+	}
+	if len(listAbi.SubType) != 1 {
+		return nil, fmt.Errorf("list abi should have one sub type")
+	}
+	items := make([]interface{}, 0, len(params))
+	for i, item := range params {
+		itemAbi := &abi.WasmContractParamAbi{
+			Name:    fmt.Sprintf("%s_%d", listAbi.Name, i),
+			Type:    listAbi.SubType[0].Type,
+			SubType: listAbi.SubType[0].SubType,
+		}
+		value, err := ParseWasmParam(item, itemAbi)
+		if err != nil {
+			return nil, fmt.Errorf("parse list error:%s", err)
+		}
+		items = append(items, value)
+	}
+	return items, nil
+}
+
+func ParseWasmParamInteger(param string) (*big.Int, error) {
+	if param == "" {
+		return nil, fmt.Errorf("invalid integer")
+	}
+	value, ok := new(big.Int).SetString(param, 10)
+	if !ok {
+		return nil, fmt.Errorf("parse integer:%s failed", param)
+	}
+	return value, nil
+}
+
+func ParseWasmParamAddress(param string) (common.Address, error) {
+	if param == "" {
+		return common.Address{}, fmt.Errorf("invalid address")
+	}
+	//Maybe param is a contract address
+	addr, err := common.AddressFromHexString(param)
+	if err != nil {
+		//Maybe param is a account address
+		addr, err = common.AddressFromBase58(param)
+		if err != nil {
+			return common.Address{}, fmt.Errorf("invalid address")
+		}
+	}
+	return addr, nil
+}
@@ -0,0 +1,98 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package utils
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/imZhuFei/zeepin/cmd/abi"
+	"github.com/imZhuFei/zeepin/common"
+	wasmabi "github.com/imZhuFei/zeepin/vm/wasmvm/abi"
+)
+
+func TestParseWasmParams(t *testing.T) {
+	paramsAbi := []*abi.WasmContractParamAbi{
+		{Name: "data", Type: "Bytes"},
+		{Name: "memo", Type: "String"},
+		{Name: "flag", Type: "Bool"},
+		{Name: "to", Type: "Address"},
+		{Name: "amount", Type: "Int128"},
+		{Name: "supply", Type: "Uint128"},
+		{
+			Name:    "owners",
+			Type:    "List",
+			SubType: []*abi.WasmContractParamAbi{{Type: "Address"}},
+		},
+		{
+			Name: "state",
+			Type: "Struct",
+			SubType: []*abi.WasmContractParamAbi{
+				{Name: "name", Type: "String"},
+				{Name: "value", Type: "Int128"},
+			},
+		},
+	}
+	addr := common.Address{1, 2, 3}
+	params := []interface{}{
+		"0102",
+		"hello",
+		"true",
+		addr.ToBase58(),
+		"-100",
+		"340282366920938463463374607431768211455",
+		[]interface{}{addr.ToHexString(), addr.ToBase58()},
+		[]interface{}{"key", "7"},
+	}
+	values, err := ParseWasmParams(params, paramsAbi)
+	if err != nil {
+		t.Fatalf("ParseWasmParams error:%s", err)
+	}
+	supply, _ := new(big.Int).SetString("340282366920938463463374607431768211455", 10)
+	expect := []interface{}{
+		[]byte{1, 2},
+		"hello",
+		true,
+		addr,
+		big.NewInt(-100),
+		wasmabi.Uint128{Value: supply},
+		[]interface{}{addr, addr},
+		wasmabi.Struct{"key", big.NewInt(7)},
+	}
+	if !reflect.DeepEqual(values, expect) {
+		t.Fatalf("ParseWasmParams %v != %v", values, expect)
+	}
+	data, err := wasmabi.Encode(values)
+	if err != nil {
+		t.Fatalf("Encode error:%s", err)
+	}
+	decoded, err := wasmabi.Decode(data)
+	if err != nil {
+		t.Fatalf("Decode error:%s", err)
+	}
+	if !reflect.DeepEqual(decoded, expect) {
+		t.Fatalf("Decode %v != %v", decoded, expect)
+	}
+
+	_, err = ParseWasmParams([]interface{}{"-1"}, []*abi.WasmContractParamAbi{{Name: "supply", Type: "Uint128"}})
+	if err == nil {
+		t.Fatalf("negative uint128 should failed")
+	}
+}
@@ -48,6 +48,7 @@ import (
 	"github.com/imZhuFei/zeepin/core/types"
 	httpcom "github.com/imZhuFei/zeepin/http/base/common"
 	rpccommon "github.com/imZhuFei/zeepin/http/base/common"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/token"
 	"github.com/imZhuFei/zeepin/smartcontract/service/native/utils"
 	"github.com/imZhuFei/zeepin/smartcontract/service/native/zpt"
 	"github.com/imZhuFei/zeepin/smartcontract/service/wasmvm"
@@ -170,19 +171,24 @@ func ApproveTx(gasPrice, gasLimit uint64, asset string, from, to string, amount
 		To:    toAddr,
 		Value: amount,
 	}
+	var param interface{}
 	var version byte
 	var contractAddr common.Address
 	switch strings.ToLower(asset) {
 	case ASSET_ZPT:
 		version = VERSION_CONTRACT_ZPT
 		contractAddr = utils.ZptContractAddress
+		param = state
 	case ASSET_GALA:
 		version = VERSION_CONTRACT_GALA
 		contractAddr = utils.GalaContractAddress
+		param = state
 	default:
-		return nil, fmt.Errorf("Unsupport asset:%s", asset)
+		version = VERSION_CONTRACT_TOKEN
+		contractAddr = utils.TokenContractAddress
+		param = &token.TokenState{Token: GetTokenID(asset), From: fromAddr, To: toAddr, Value: amount}
 	}
-	invokeCode, err := httpcom.BuildNativeInvokeCode(contractAddr, version, CONTRACT_APPROVE, []interface{}{state})
+	invokeCode, err := httpcom.BuildNativeInvokeCode(contractAddr, version, CONTRACT_APPROVE, []interface{}{param})
 	if err != nil {
 		return nil, fmt.Errorf("build invoke code error:%s", err)
 	}
@@ -215,19 +221,24 @@ func TransferTx(gasPrice, gasLimit uint64, asset, from, to string, amount uint64
 		To:    toAddr,
 		Value: amount,
 	})
+	var param interface{}
 	var version byte
 	var contractAddr common.Address
 	switch strings.ToLower(asset) {
 	case ASSET_ZPT:
 		version = VERSION_CONTRACT_ZPT
 		contractAddr = utils.ZptContractAddress
+		param = sts
 	case ASSET_GALA:
 		version = VERSION_CONTRACT_GALA
 		contractAddr = utils.GalaContractAddress
+		param = sts
 	default:
-		return nil, fmt.Errorf("Unsupport asset:%s", asset)
+		version = VERSION_CONTRACT_TOKEN
+		contractAddr = utils.TokenContractAddress
+		param = &token.TokenTransfers{Token: GetTokenID(asset), States: []zpt.State{*sts[0]}}
 	}
-	invokeCode, err := httpcom.BuildNativeInvokeCode(contractAddr, version, CONTRACT_TRANSFER, []interface{}{sts})
+	invokeCode, err := httpcom.BuildNativeInvokeCode(contractAddr, version, CONTRACT_TRANSFER, []interface{}{param})
 	if err != nil {
 		return nil, fmt.Errorf("build invoke code error:%s", err)
 	}
@@ -264,19 +275,30 @@ func TransferFromTx(gasPrice, gasLimit uint64, asset, sender, from, to string, a
 		To:     toAddr,
 		Value:  amount,
 	}
+	var param interface{}
 	var version byte
 	var contractAddr common.Address
 	switch strings.ToLower(asset) {
 	case ASSET_ZPT:
 		version = VERSION_CONTRACT_ZPT
 		contractAddr = utils.ZptContractAddress
+		param = transferFrom
 	case ASSET_GALA:
 		version = VERSION_CONTRACT_GALA
 		contractAddr = utils.GalaContractAddress
+		param = transferFrom
 	default:
-		return nil, fmt.Errorf("Unsupport asset:%s", asset)
-	}
-	invokeCode, err := httpcom.BuildNativeInvokeCode(contractAddr, version, CONTRACT_TRANSFER_FROM, []interface{}{transferFrom})
+		version = VERSION_CONTRACT_TOKEN
+		contractAddr = utils.TokenContractAddress
+		param = &token.TokenTransferFromState{
+			Token:  GetTokenID(asset),
+			Sender: senderAddr,
+			From:   fromAddr,
+			To:     toAddr,
+			Value:  amount,
+		}
+	}
+	invokeCode, err := httpcom.BuildNativeInvokeCode(contractAddr, version, CONTRACT_TRANSFER_FROM, []interface{}{param})
 	if err != nil {
 		return nil, fmt.Errorf("build invoke code error:%s", err)
 	}
@@ -361,6 +383,15 @@ func GetSmartContractEventInfo(txHash string) ([]byte, error) {
 	return sendRpcRequest("getsmartcodeevent", []interface{}{txHash})
 }
 
+//GetContractAuth returns the permissions of contract in the auth contract, fn filters the roles by function name if not empty
+func GetContractAuth(contract, fn string) ([]byte, error) {
+	params := []interface{}{contract}
+	if fn != "" {
+		params = append(params, fn)
+	}
+	return sendRpcRequest("getcontractauth", params)
+}
+
 func GetRawTransaction(txHash string) ([]byte, error) {
 	return sendRpcRequest("getrawtransaction", []interface{}{txHash, 1})
 }
@@ -109,6 +109,42 @@ var NETWORK_NAME = map[uint32]string{
 	NETWORK_ID_SOLO_NET:    NETWORK_NAME_SOLO_NET,
 }
 
+var WASM_VERIFY_HEIGHT = map[uint32]uint32{
+	NETWORK_ID_MAIN_NET:    constants.WASM_VERIFY_HEIGHT_MAINNET, //Network main
+	NETWORK_ID_POLARIS_NET: constants.WASM_VERIFY_HEIGHT_POLARIS, //Network polaris
+	NETWORK_ID_SOLO_NET:    0,                                    //Network solo
+}
+
+var WASM_GAS_HEIGHT = map[uint32]uint32{
+	NETWORK_ID_MAIN_NET:    constants.WASM_GAS_HEIGHT_MAINNET, //Network main
+	NETWORK_ID_POLARIS_NET: constants.WASM_GAS_HEIGHT_POLARIS, //Network polaris
+	NETWORK_ID_SOLO_NET:    0,                                 //Network solo
+}
+
+var CONTRACT_API_HEIGHT = map[uint32]uint32{
+	NETWORK_ID_MAIN_NET:    constants.CONTRACT_API_HEIGHT_MAINNET, //Network main
+	NETWORK_ID_POLARIS_NET: constants.CONTRACT_API_HEIGHT_POLARIS, //Network polaris
+	NETWORK_ID_SOLO_NET:    0,                                     //Network solo
+}
+
+var NATIVE_CONTRACT_HEIGHT = map[uint32]uint32{
+	NETWORK_ID_MAIN_NET:    constants.NATIVE_CONTRACT_HEIGHT_MAINNET, //Network main
+	NETWORK_ID_POLARIS_NET: constants.NATIVE_CONTRACT_HEIGHT_POLARIS, //Network polaris
+	NETWORK_ID_SOLO_NET:    0,                                        //Network solo
+}
+
+var GOVERNANCE_HEIGHT = map[uint32]uint32{
+	NETWORK_ID_MAIN_NET:    constants.GOVERNANCE_HEIGHT_MAINNET, //Network main
+	NETWORK_ID_POLARIS_NET: constants.GOVERNANCE_HEIGHT_POLARIS, //Network polaris
+	NETWORK_ID_SOLO_NET:    0,                                   //Network solo
+}
+
+var STATE_ROOT_HEIGHT = map[uint32]uint32{
+	NETWORK_ID_MAIN_NET:    constants.STATE_ROOT_HEIGHT_MAINNET, //Network main
+	NETWORK_ID_POLARIS_NET: constants.STATE_ROOT_HEIGHT_POLARIS, //Network polaris
+	NETWORK_ID_SOLO_NET:    0,                                   //Network solo
+}
+
 func GetNetworkMagic(id uint32) uint32 {
 	nid, ok := NETWORK_MAGIC[id]
 	if ok {
@@ -117,6 +153,60 @@ func GetNetworkMagic(id uint32) uint32 {
 	return id
 }
 
+//GetWasmVerifyHeight return the height from which wasm code is validated on network id, other networks validate it since genesis
+func GetWasmVerifyHeight(id uint32) uint32 {
+	height, ok := WASM_VERIFY_HEIGHT[id]
+	if ok {
+		return height
+	}
+	return 0
+}
+
+//GetWasmGasHeight return the height from which wasm execution is metered on network id, other networks meter it since genesis
+func GetWasmGasHeight(id uint32) uint32 {
+	height, ok := WASM_GAS_HEIGHT[id]
+	if ok {
+		return height
+	}
+	return 0
+}
+
+//GetContractApiHeight return the height from which new host functions are provided to contracts on network id, other networks provide them since genesis
+func GetContractApiHeight(id uint32) uint32 {
+	height, ok := CONTRACT_API_HEIGHT[id]
+	if ok {
+		return height
+	}
+	return 0
+}
+
+//GetNativeContractHeight return the height from which new native contracts are registered on network id, other networks register them since genesis
+func GetNativeContractHeight(id uint32) uint32 {
+	height, ok := NATIVE_CONTRACT_HEIGHT[id]
+	if ok {
+		return height
+	}
+	return 0
+}
+
+//GetGovernanceHeight return the height from which new governance methods are registered on network id, other networks register them since genesis
+func GetGovernanceHeight(id uint32) uint32 {
+	height, ok := GOVERNANCE_HEIGHT[id]
+	if ok {
+		return height
+	}
+	return 0
+}
+
+//GetStateRootHeight return the height from which blocks should commit state root on network id, other networks commit it since genesis
+func GetStateRootHeight(id uint32) uint32 {
+	height, ok := STATE_ROOT_HEIGHT[id]
+	if ok {
+		return height
+	}
+	return 0
+}
+
 func GetNetworkName(id uint32) string {
 	name, ok := NETWORK_NAME[id]
 	if ok {
@@ -507,13 +597,16 @@ type SOLOConfig struct {
 }
 
 type CommonConfig struct {
-	LogLevel       uint
-	NodeType       string
-	EnableEventLog bool
-	SystemFee      map[string]int64
-	GasLimit       uint64
-	GasPrice       uint64
-	DataDir        string
+	LogLevel           uint
+	NodeType           string
+	EnableEventLog     bool
+	EnableAddressIndex bool
+	EnableArchive      bool
+	PruneBlocks        uint32
+	SystemFee          map[string]int64
+	GasLimit           uint64
+	GasPrice           uint64
+	DataDir            string
 }
 
 type ConsensusConfig struct {
@@ -35,6 +35,7 @@
 package constants
 
 import (
+	"math"
 	"time"
 )
 
@@ -95,3 +96,39 @@ const (
 	NETWORK_MAGIC_MAINNET = 0x8c77ab60
 	NETWORK_MAGIC_POLARIS = 0x2d8829df
 )
+
+// height from which the wasm code deployed or migrated is validated, and the linear memory is limited
+const (
+	WASM_VERIFY_HEIGHT_MAINNET = math.MaxUint32 //not activated yet, set when the upgrade is scheduled
+	WASM_VERIFY_HEIGHT_POLARIS = math.MaxUint32 //not activated yet, set when the upgrade is scheduled
+)
+
+// height from which every vbft block should commit the state root of previous block
+const (
+	STATE_ROOT_HEIGHT_MAINNET = math.MaxUint32 //not activated yet, set when the upgrade is scheduled
+	STATE_ROOT_HEIGHT_POLARIS = math.MaxUint32 //not activated yet, set when the upgrade is scheduled
+)
+
+// height from which the instructions, host calls and grown memory of wasm contracts are charged gas
+const (
+	WASM_GAS_HEIGHT_MAINNET = math.MaxUint32 //not activated yet, set when the upgrade is scheduled
+	WASM_GAS_HEIGHT_POLARIS = math.MaxUint32 //not activated yet, set when the upgrade is scheduled
+)
+
+// height from which the host functions added to wasm and embedded contracts after genesis are provided
+const (
+	CONTRACT_API_HEIGHT_MAINNET = math.MaxUint32 //not activated yet, set when the upgrade is scheduled
+	CONTRACT_API_HEIGHT_POLARIS = math.MaxUint32 //not activated yet, set when the upgrade is scheduled
+)
+
+// height from which the native contracts added after genesis are registered
+const (
+	NATIVE_CONTRACT_HEIGHT_MAINNET = math.MaxUint32 //not activated yet, set when the upgrade is scheduled
+	NATIVE_CONTRACT_HEIGHT_POLARIS = math.MaxUint32 //not activated yet, set when the upgrade is scheduled
+)
+
+// height from which the governance methods added after genesis are registered
+const (
+	GOVERNANCE_HEIGHT_MAINNET = math.MaxUint32 //not activated yet, set when the upgrade is scheduled
+	GOVERNANCE_HEIGHT_POLARIS = math.MaxUint32 //not activated yet, set when the upgrade is scheduled
+)
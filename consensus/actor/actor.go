@@ -80,6 +80,11 @@ func (self *TxPoolActor) VerifyBlock(txs []*types.Transaction, height uint32) er
 	return nil
 }
 
+// AppendTx submits a transaction made by the consensus node, it is broadcasted after verified like http ones
+func (self *TxPoolActor) AppendTx(tx *types.Transaction) {
+	self.Pool.Tell(&txpool.TxReq{Tx: tx, Sender: txpool.HttpSender})
+}
+
 type P2PActor struct {
 	P2P *actor.PID
 }
@@ -77,6 +77,7 @@ type VbftBlockInfo struct {
 	VrfProof           []byte       `json:"vrf_proof"`
 	LastConfigBlockNum uint32       `json:"last_config_block_num"`
 	NewChainConfig     *ChainConfig `json:"new_chain_config"`
+	StateRoot          []byte       `json:"state_root,omitempty"` //state root after the previous block is executed
 }
 
 const (
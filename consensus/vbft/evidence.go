@@ -0,0 +1,210 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package vbft
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/common/config"
+	"github.com/imZhuFei/zeepin/common/log"
+	vconfig "github.com/imZhuFei/zeepin/consensus/vbft/config"
+	"github.com/imZhuFei/zeepin/core/signature"
+	"github.com/imZhuFei/zeepin/core/types"
+	"github.com/imZhuFei/zeepin/core/utils"
+	gover "github.com/imZhuFei/zeepin/smartcontract/service/native/governance"
+	nutils "github.com/imZhuFei/zeepin/smartcontract/service/native/utils"
+	"github.com/ontio/ontology-crypto/keypair"
+)
+
+// max number of evidences remembered for dup checking
+const MAX_EVIDENCE_CACHE = 1024
+
+func serializeUnsignedHeader(header *types.Header) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := header.SerializeUnsigned(buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func newEvidenceMsg(peerIdx uint32, header1 *types.Header, sig1 []byte, header2 *types.Header, sig2 []byte) (*evidenceMsg, error) {
+	h1, err := serializeUnsignedHeader(header1)
+	if err != nil {
+		return nil, fmt.Errorf("serialize header1: %s", err)
+	}
+	h2, err := serializeUnsignedHeader(header2)
+	if err != nil {
+		return nil, fmt.Errorf("serialize header2: %s", err)
+	}
+	return &evidenceMsg{
+		PeerIndex: peerIdx,
+		BlockNum:  header1.Height,
+		Header1:   h1,
+		Sig1:      sig1,
+		Header2:   h2,
+		Sig2:      sig2,
+	}, nil
+}
+
+// check if the proposer has proposed a conflicting block at the same height
+func (self *Server) checkProposalEquivocation(pMsg *blockProposalMsg) {
+	proposer := pMsg.Block.getProposer()
+	header := pMsg.Block.Block.Header
+	hash := pMsg.Block.Block.Hash()
+	for _, msg := range self.msgPool.GetProposalMsgs(pMsg.GetBlockNum()) {
+		p, ok := msg.(*blockProposalMsg)
+		if !ok || p.Block.getProposer() != proposer {
+			continue
+		}
+		other := p.Block.Block.Header
+		if other.Hash() == hash {
+			continue
+		}
+		if other.PrevBlockHash == header.PrevBlockHash && bytes.Equal(other.ConsensusPayload, header.ConsensusPayload) {
+			continue
+		}
+		if len(other.SigData) == 0 || len(header.SigData) == 0 {
+			continue
+		}
+		evidence, err := newEvidenceMsg(proposer, other, other.SigData[0], header, header.SigData[0])
+		if err != nil {
+			log.Errorf("server %d failed to build proposal evidence of %d: %s", self.Index, proposer, err)
+			return
+		}
+		log.Warnf("server %d detected conflicting proposals of %d at blk %d", self.Index, proposer, pMsg.GetBlockNum())
+		self.processEvidenceMsg(evidence, true)
+		return
+	}
+}
+
+// find the header of block with the hash in proposals of the height
+func (self *Server) findProposalHeader(blkNum uint32, hash common.Uint256) *types.Header {
+	for _, msg := range self.msgPool.GetProposalMsgs(blkNum) {
+		p, ok := msg.(*blockProposalMsg)
+		if !ok {
+			continue
+		}
+		if p.Block.Block.Hash() == hash {
+			return p.Block.Block.Header
+		}
+		if p.Block.EmptyBlock != nil && p.Block.EmptyBlock.Hash() == hash {
+			return p.Block.EmptyBlock.Header
+		}
+	}
+	return nil
+}
+
+// check if the endorser has endorsed blocks of two forks at the same height
+func (self *Server) checkEndorseEquivocation(eMsg *blockEndorseMsg) {
+	for _, msg := range self.msgPool.GetEndorsementsMsgs(eMsg.GetBlockNum()) {
+		e, ok := msg.(*blockEndorseMsg)
+		if !ok || e.Endorser != eMsg.Endorser || e.EndorsedBlockHash == eMsg.EndorsedBlockHash {
+			continue
+		}
+		header1 := self.findProposalHeader(eMsg.GetBlockNum(), e.EndorsedBlockHash)
+		header2 := self.findProposalHeader(eMsg.GetBlockNum(), eMsg.EndorsedBlockHash)
+		if header1 == nil || header2 == nil || header1.PrevBlockHash == header2.PrevBlockHash {
+			continue
+		}
+		evidence, err := newEvidenceMsg(eMsg.Endorser, header1, e.EndorserSig, header2, eMsg.EndorserSig)
+		if err != nil {
+			log.Errorf("server %d failed to build endorse evidence of %d: %s", self.Index, eMsg.Endorser, err)
+			return
+		}
+		log.Warnf("server %d detected conflicting endorsements of %d at blk %d", self.Index, eMsg.Endorser, eMsg.GetBlockNum())
+		self.processEvidenceMsg(evidence, true)
+		return
+	}
+}
+
+// verify the evidence, gossip it if detected locally, and submit it to governance contract
+func (self *Server) processEvidenceMsg(msg *evidenceMsg, gossip bool) {
+	pk := self.peerPool.GetPeerPubKey(msg.PeerIndex)
+	if pk == nil {
+		log.Errorf("server %d failed to get peer %d pubkey for evidence", self.Index, msg.PeerIndex)
+		return
+	}
+	evidence := &gover.EvidenceParam{
+		PeerPubkey: vconfig.PubkeyID(pk),
+		Header1:    msg.Header1,
+		Sig1:       msg.Sig1,
+		Header2:    msg.Header2,
+		Sig2:       msg.Sig2,
+	}
+	height, err := gover.VerifyEvidence(evidence, msg.PeerIndex)
+	if err != nil {
+		log.Errorf("server %d received invalid evidence of %d: %s", self.Index, msg.PeerIndex, err)
+		return
+	}
+
+	key := fmt.Sprintf("%s:%d", evidence.PeerPubkey, height)
+	self.evidenceLock.Lock()
+	if _, present := self.evidences[key]; present {
+		self.evidenceLock.Unlock()
+		return
+	}
+	if len(self.evidences) >= MAX_EVIDENCE_CACHE {
+		self.evidences = make(map[string]struct{})
+	}
+	self.evidences[key] = struct{}{}
+	self.evidenceLock.Unlock()
+
+	if gossip {
+		self.broadcast(msg)
+	}
+	if self.GetCurrentBlockNo() < config.GetGovernanceHeight(config.DefConfig.P2PNode.NetworkId) {
+		return
+	}
+	if err := self.submitEvidence(evidence); err != nil {
+		log.Errorf("server %d failed to submit evidence of %d: %s", self.Index, msg.PeerIndex, err)
+	}
+}
+
+func (self *Server) submitEvidence(evidence *gover.EvidenceParam) error {
+	mutable := utils.BuildNativeStructTransaction(nutils.GovernanceContractAddress, gover.SUBMIT_EVIDENCE,
+		[]byte(evidence.PeerPubkey), evidence.Header1, evidence.Sig1, evidence.Header2, evidence.Sig2)
+	mutable.GasPrice = config.DefConfig.Common.GasPrice
+	mutable.GasLimit = config.DefConfig.Common.GasLimit
+	if mutable.GasLimit < config.DEFAULT_GAS_LIMIT {
+		mutable.GasLimit = config.DEFAULT_GAS_LIMIT
+	}
+	mutable.Nonce = uint32(time.Now().UnixNano() % math.MaxUint32)
+	mutable.Payer = self.account.Address
+
+	txHash := mutable.Hash()
+	sig, err := signature.Sign(self.account, txHash[:])
+	if err != nil {
+		return fmt.Errorf("sign evidence tx: %s", err)
+	}
+	mutable.Sigs = []types.Sig{{
+		PubKeys: []keypair.PublicKey{self.account.PublicKey},
+		M:       1,
+		SigData: [][]byte{sig},
+	}}
+	tx, err := mutable.IntoImmutable()
+	if err != nil {
+		return fmt.Errorf("evidence tx into immutable: %s", err)
+	}
+	self.poolActor.AppendTx(tx)
+	log.Infof("server %d submitted evidence tx %s", self.Index, txHash.ToHexString())
+	return nil
+}
@@ -40,6 +40,7 @@ import (
 	"time"
 
 	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/common/config"
 	"github.com/imZhuFei/zeepin/common/log"
 	"github.com/imZhuFei/zeepin/consensus/vbft/config"
 	"github.com/imZhuFei/zeepin/core/ledger"
@@ -125,6 +126,12 @@ func DeserializeVbftMsg(msgPayload []byte) (ConsensusMsg, error) {
 			return nil, fmt.Errorf("failed to unmarshal msg (type: %d): %s", m.Type, err)
 		}
 		return t, nil
+	case EvidenceMessage:
+		t := &evidenceMsg{}
+		if err := json.Unmarshal(m.Payload, t); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal msg (type: %d): %s", m.Type, err)
+		}
+		return t, nil
 	}
 
 	return nil, fmt.Errorf("unknown msg type: %d", m.Type)
@@ -255,6 +262,14 @@ func (self *Server) constructProposalMsg(blkNum uint32, sysTxs, userTxs []*types
 		LastConfigBlockNum: lastConfigBlkNum,
 		NewChainConfig:     chainconfig,
 	}
+	// state root of previous block is committed from the state root height
+	if blkNum >= config.GetStateRootHeight(config.DefConfig.P2PNode.NetworkId) {
+		stateRoot, err := ledger.DefLedger.GetStateRoot(blkNum - 1)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get state root of block (%d): %s", blkNum-1, err)
+		}
+		vbftBlkInfo.StateRoot = stateRoot[:]
+	}
 	consensusPayload, err := json.Marshal(vbftBlkInfo)
 	if err != nil {
 		return nil, err
@@ -62,6 +62,7 @@ const (
 	ProposalFetchMessage
 	BlockFetchMessage
 	BlockFetchRespMessage
+	EvidenceMessage
 )
 
 type ConsensusMsg interface {
@@ -400,3 +401,31 @@ func (msg *proposalFetchMsg) GetBlockNum() uint32 {
 func (msg *proposalFetchMsg) Serialize() ([]byte, error) {
 	return json.Marshal(msg)
 }
+
+// evidence msg carries two conflicting headers signed by the peer, it is gossiped to all peers
+// and submitted to governance contract to slash the peer
+type evidenceMsg struct {
+	PeerIndex uint32 `json:"peer_index"`
+	BlockNum  uint32 `json:"block_num"`
+	Header1   []byte `json:"header1"`
+	Sig1      []byte `json:"sig1"`
+	Header2   []byte `json:"header2"`
+	Sig2      []byte `json:"sig2"`
+}
+
+func (msg *evidenceMsg) Type() MsgType {
+	return EvidenceMessage
+}
+
+func (msg *evidenceMsg) Verify(pub keypair.PublicKey) error {
+	// signatures are verified with the pubkey of PeerIndex when processing
+	return nil
+}
+
+func (msg *evidenceMsg) GetBlockNum() uint32 {
+	return msg.BlockNum
+}
+
+func (msg *evidenceMsg) Serialize() ([]byte, error) {
+	return json.Marshal(msg)
+}
@@ -44,6 +44,7 @@ import (
 
 	"github.com/imZhuFei/zeepin/account"
 	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/common/config"
 	"github.com/imZhuFei/zeepin/common/log"
 	actorTypes "github.com/imZhuFei/zeepin/consensus/actor"
 	"github.com/imZhuFei/zeepin/consensus/vbft/config"
@@ -143,6 +144,9 @@ type Server struct {
 	quitC      chan struct{}
 	quit       bool
 	quitWg     sync.WaitGroup
+
+	evidenceLock sync.Mutex
+	evidences    map[string]struct{} // submitted evidences, for dup checking
 }
 
 func NewVbftServer(account *account.Account, txpool, p2p *actor.PID) (*Server, error) {
@@ -153,6 +157,7 @@ func NewVbftServer(account *account.Account, txpool, p2p *actor.PID) (*Server, e
 		p2p:                &actorTypes.P2PActor{P2P: p2p},
 		ledger:             ledger.DefLedger,
 		incrValidator:      increment.NewIncrementValidator(10),
+		evidences:          make(map[string]struct{}),
 	}
 	server.stateMgr = newStateMgr(server)
 
@@ -773,6 +778,7 @@ func (self *Server) onConsensusMsg(peerIdx uint32, msg ConsensusMsg, msgHash com
 			log.Error("invalid msg with proposal msg type")
 			return
 		}
+		self.checkProposalEquivocation(pMsg)
 
 		msgBlkNum := pMsg.GetBlockNum()
 		if msgBlkNum > self.GetCurrentBlockNo() {
@@ -828,6 +834,7 @@ func (self *Server) onConsensusMsg(peerIdx uint32, msg ConsensusMsg, msgHash com
 			log.Error("invalid msg with endorse msg type")
 			return
 		}
+		self.checkEndorseEquivocation(pMsg)
 
 		// TODO: verify msg
 
@@ -951,6 +958,14 @@ func (self *Server) onConsensusMsg(peerIdx uint32, msg ConsensusMsg, msgHash com
 			}
 		}
 
+	case EvidenceMessage:
+		pMsg, ok := msg.(*evidenceMsg)
+		if !ok {
+			log.Errorf("invalid msg with evidence msg type")
+			return
+		}
+		self.processEvidenceMsg(pMsg, false)
+
 	case ProposalFetchMessage:
 		pMsg, ok := msg.(*proposalFetchMsg)
 		if !ok {
@@ -1077,6 +1092,21 @@ func (self *Server) processProposalMsg(msg *blockProposalMsg) {
 		log.Errorf("BlockPrposalMessage  check LastConfigBlockNum blocknum:%d,prvLastConfigBlockNum:%d,self LastConfigBlockNum:%d", msg.GetBlockNum(), blk.Info.LastConfigBlockNum, self.LastConfigBlockNum)
 		return
 	}
+	// state root of previous block is required from the state root height, a root not checked is never endorsed
+	if msgStateRoot := msg.Block.Info.StateRoot; len(msgStateRoot) > 0 {
+		stateRoot, err := ledger.DefLedger.GetStateRoot(msgBlkNum - 1)
+		if err != nil {
+			log.Errorf("BlockPrposalMessage failed to get stateRoot of blocknum:%d: %s", msgBlkNum-1, err)
+			return
+		}
+		if !bytes.Equal(stateRoot[:], msgStateRoot) {
+			log.Errorf("BlockPrposalMessage check blocknum:%d,stateRoot:%s,msg stateRoot:%x", msgBlkNum, stateRoot.ToHexString(), msgStateRoot)
+			return
+		}
+	} else if msgBlkNum >= config.GetStateRootHeight(config.DefConfig.P2PNode.NetworkId) {
+		log.Errorf("BlockPrposalMessage check blocknum:%d, stateRoot is missing", msgBlkNum)
+		return
+	}
 
 	cfg := vconfig.ChainConfig{}
 	if blk.getNewChainConfig() != nil {
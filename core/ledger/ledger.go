@@ -42,10 +42,13 @@ import (
 	"github.com/imZhuFei/zeepin/core/payload"
 	"github.com/imZhuFei/zeepin/core/states"
 	"github.com/imZhuFei/zeepin/core/store"
+	scom "github.com/imZhuFei/zeepin/core/store/common"
 	"github.com/imZhuFei/zeepin/core/store/ledgerstore"
 	"github.com/imZhuFei/zeepin/core/types"
+	"github.com/imZhuFei/zeepin/merkle"
 	"github.com/imZhuFei/zeepin/smartcontract/event"
 	cstate "github.com/imZhuFei/zeepin/smartcontract/states"
+	"github.com/imZhuFei/zeepin/smartcontract/trace"
 	"github.com/ontio/ontology-crypto/keypair"
 )
 
@@ -169,18 +172,57 @@ func (self *Ledger) GetStorageItem(codeHash common.Address, key []byte) ([]byte,
 	return storageItem.Value, nil
 }
 
+func (self *Ledger) GetStorageItemAt(codeHash common.Address, key []byte, height uint32) ([]byte, error) {
+	storageKey := &states.StorageKey{
+		ContractAddress: codeHash,
+		Key:             key,
+	}
+	storageItem, err := self.ldgStore.GetStorageItemAt(storageKey, height)
+	if err != nil {
+		return nil, err
+	}
+	if storageItem == nil {
+		return nil, nil
+	}
+	return storageItem.Value, nil
+}
+
 func (self *Ledger) GetContractState(contractHash common.Address) (*payload.DeployCode, error) {
 	return self.ldgStore.GetContractState(contractHash)
 }
 
+func (self *Ledger) GetEventSchemas(contractHash common.Address) (*states.EventSchemaState, error) {
+	return self.ldgStore.GetEventSchemas(contractHash)
+}
+
 func (self *Ledger) GetMerkleProof(proofHeight, rootHeight uint32) ([]common.Uint256, error) {
 	return self.ldgStore.GetMerkleProof(proofHeight, rootHeight)
 }
 
+func (self *Ledger) GetStateRoot(height uint32) (common.Uint256, error) {
+	return self.ldgStore.GetStateRoot(height)
+}
+
+func (self *Ledger) GetStorageProof(codeHash common.Address, key []byte, height uint32) ([]byte, *merkle.SparseMerkleProof, error) {
+	storageKey := &states.StorageKey{
+		ContractAddress: codeHash,
+		Key:             key,
+	}
+	return self.ldgStore.GetStorageProof(storageKey, height)
+}
+
 func (self *Ledger) PreExecuteContract(tx *types.Transaction) (*cstate.PreExecResult, error) {
 	return self.ldgStore.PreExecuteContract(tx)
 }
 
+func (self *Ledger) PreExecuteContractAt(tx *types.Transaction, height uint32) (*cstate.PreExecResult, error) {
+	return self.ldgStore.PreExecuteContractAt(tx, height)
+}
+
+func (self *Ledger) TraceContract(tx *types.Transaction, maxSteps int) (*trace.Trace, error) {
+	return self.ldgStore.TraceContract(tx, maxSteps)
+}
+
 func (self *Ledger) GetEventNotifyByTx(tx common.Uint256) (*event.ExecuteNotify, error) {
 	return self.ldgStore.GetEventNotifyByTx(tx)
 }
@@ -189,6 +231,14 @@ func (self *Ledger) GetEventNotifyByBlock(height uint32) ([]*event.ExecuteNotify
 	return self.ldgStore.GetEventNotifyByBlock(height)
 }
 
+func (self *Ledger) GetTransactionsByAddress(addr common.Address, start []byte, limit uint32) ([]*scom.AddressTx, []byte, error) {
+	return self.ldgStore.GetTransactionsByAddress(addr, start, limit)
+}
+
+func (self *Ledger) GetTransfersByAddress(addr common.Address, start []byte, limit uint32) ([]*scom.AddressTransfer, []byte, error) {
+	return self.ldgStore.GetTransfersByAddress(addr, start, limit)
+}
+
 func (self *Ledger) Close() error {
 	return self.ldgStore.Close()
 }
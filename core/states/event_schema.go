@@ -0,0 +1,205 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package states
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/imZhuFei/zeepin/common/serialization"
+	"github.com/imZhuFei/zeepin/errors"
+)
+
+const (
+	EVENT_FIELD_TYPE_BYTE_ARRAY = "bytearray"
+	EVENT_FIELD_TYPE_STRING     = "string"
+	EVENT_FIELD_TYPE_INTEGER    = "integer"
+	EVENT_FIELD_TYPE_BOOLEAN    = "boolean"
+	EVENT_FIELD_TYPE_ADDRESS    = "address"
+
+	MAX_EVENT_SCHEMA_COUNT = 64 //max event schemas of a contract
+	MAX_EVENT_FIELD_COUNT  = 32 //max fields of a event schema
+	MAX_EVENT_NAME_LEN     = 64 //max length of event name and field name
+)
+
+// EventField describe a named and typed field of contract event
+type EventField struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// EventSchema describe the fields of contract event in notify order
+type EventSchema struct {
+	Name   string        `json:"name"`
+	Fields []*EventField `json:"fields"`
+}
+
+// ParseEventSchema parse and check event schema in json, such as
+// {"name":"transfer","fields":[{"name":"from","type":"address"},{"name":"amount","type":"integer"}]}
+func ParseEventSchema(data []byte) (*EventSchema, error) {
+	schema := new(EventSchema)
+	if err := json.Unmarshal(data, schema); err != nil {
+		return nil, fmt.Errorf("[ParseEventSchema] json unmarshal error:%s", err)
+	}
+	if err := schema.Validate(); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// Validate check event name, field names and field types
+func (this *EventSchema) Validate() error {
+	if len(this.Name) == 0 || len(this.Name) > MAX_EVENT_NAME_LEN {
+		return fmt.Errorf("[EventSchema] invalid event name length:%d", len(this.Name))
+	}
+	if len(this.Fields) > MAX_EVENT_FIELD_COUNT {
+		return fmt.Errorf("[EventSchema] too many fields:%d", len(this.Fields))
+	}
+	names := make(map[string]bool, len(this.Fields))
+	for _, field := range this.Fields {
+		if field == nil {
+			return fmt.Errorf("[EventSchema] nil field")
+		}
+		if len(field.Name) == 0 || len(field.Name) > MAX_EVENT_NAME_LEN {
+			return fmt.Errorf("[EventSchema] invalid field name length:%d", len(field.Name))
+		}
+		if names[field.Name] {
+			return fmt.Errorf("[EventSchema] duplicate field:%s", field.Name)
+		}
+		names[field.Name] = true
+		switch field.Type {
+		case EVENT_FIELD_TYPE_BYTE_ARRAY, EVENT_FIELD_TYPE_STRING, EVENT_FIELD_TYPE_INTEGER,
+			EVENT_FIELD_TYPE_BOOLEAN, EVENT_FIELD_TYPE_ADDRESS:
+		default:
+			return fmt.Errorf("[EventSchema] field:%s unknown type:%s", field.Name, field.Type)
+		}
+	}
+	return nil
+}
+
+func (this *EventSchema) Serialize(w io.Writer) error {
+	if err := serialization.WriteString(w, this.Name); err != nil {
+		return err
+	}
+	if err := serialization.WriteVarUint(w, uint64(len(this.Fields))); err != nil {
+		return err
+	}
+	for _, field := range this.Fields {
+		if err := serialization.WriteString(w, field.Name); err != nil {
+			return err
+		}
+		if err := serialization.WriteString(w, field.Type); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (this *EventSchema) Deserialize(r io.Reader) error {
+	name, err := serialization.ReadString(r)
+	if err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "[EventSchema], Name Deserialize failed.")
+	}
+	n, err := serialization.ReadVarUint(r, MAX_EVENT_FIELD_COUNT)
+	if err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "[EventSchema], Fields count Deserialize failed.")
+	}
+	fields := make([]*EventField, 0, n)
+	for i := uint64(0); i < n; i++ {
+		field := new(EventField)
+		if field.Name, err = serialization.ReadString(r); err != nil {
+			return errors.NewDetailErr(err, errors.ErrNoCode, "[EventSchema], Field name Deserialize failed.")
+		}
+		if field.Type, err = serialization.ReadString(r); err != nil {
+			return errors.NewDetailErr(err, errors.ErrNoCode, "[EventSchema], Field type Deserialize failed.")
+		}
+		fields = append(fields, field)
+	}
+	this.Name = name
+	this.Fields = fields
+	return nil
+}
+
+// EventSchemaState store all event schemas registered by a contract
+type EventSchemaState struct {
+	StateBase
+	Schemas []*EventSchema
+}
+
+// GetSchema return the schema of event name, nil if not registered
+func (this *EventSchemaState) GetSchema(name string) *EventSchema {
+	for _, schema := range this.Schemas {
+		if schema.Name == name {
+			return schema
+		}
+	}
+	return nil
+}
+
+// SetSchema add schema or replace the registered schema with the same event name
+func (this *EventSchemaState) SetSchema(schema *EventSchema) error {
+	for i, s := range this.Schemas {
+		if s.Name == schema.Name {
+			this.Schemas[i] = schema
+			return nil
+		}
+	}
+	if len(this.Schemas) >= MAX_EVENT_SCHEMA_COUNT {
+		return fmt.Errorf("[EventSchemaState] too many event schemas")
+	}
+	this.Schemas = append(this.Schemas, schema)
+	return nil
+}
+
+func (this *EventSchemaState) Serialize(w io.Writer) error {
+	if err := this.StateBase.Serialize(w); err != nil {
+		return err
+	}
+	if err := serialization.WriteVarUint(w, uint64(len(this.Schemas))); err != nil {
+		return err
+	}
+	for _, schema := range this.Schemas {
+		if err := schema.Serialize(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (this *EventSchemaState) Deserialize(r io.Reader) error {
+	err := this.StateBase.Deserialize(r)
+	if err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "[EventSchemaState], StateBase Deserialize failed.")
+	}
+	n, err := serialization.ReadVarUint(r, MAX_EVENT_SCHEMA_COUNT)
+	if err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "[EventSchemaState], Schemas count Deserialize failed.")
+	}
+	schemas := make([]*EventSchema, 0, n)
+	for i := uint64(0); i < n; i++ {
+		schema := new(EventSchema)
+		if err := schema.Deserialize(r); err != nil {
+			return err
+		}
+		schemas = append(schemas, schema)
+	}
+	this.Schemas = schemas
+	return nil
+}
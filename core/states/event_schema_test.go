@@ -0,0 +1,64 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package states
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventSchemaState(t *testing.T) {
+	schema, err := ParseEventSchema([]byte(`{"name":"transfer","fields":[{"name":"from","type":"address"},{"name":"amount","type":"integer"}]}`))
+	assert.Nil(t, err)
+	assert.Equal(t, "transfer", schema.Name)
+	assert.Equal(t, &EventField{Name: "amount", Type: EVENT_FIELD_TYPE_INTEGER}, schema.Fields[1])
+
+	state := new(EventSchemaState)
+	assert.Nil(t, state.SetSchema(schema))
+	assert.Nil(t, state.SetSchema(&EventSchema{Name: "approve"}))
+	assert.Nil(t, state.SetSchema(&EventSchema{Name: "transfer", Fields: schema.Fields[:1]}))
+	assert.Equal(t, 2, len(state.Schemas))
+	assert.Equal(t, 1, len(state.GetSchema("transfer").Fields))
+	assert.Nil(t, state.GetSchema("unknown"))
+
+	bf := new(bytes.Buffer)
+	assert.Nil(t, state.Serialize(bf))
+	result := new(EventSchemaState)
+	assert.Nil(t, result.Deserialize(bf))
+	assert.Equal(t, state.Schemas[0], result.Schemas[0])
+	assert.Equal(t, state.Schemas[1].Name, result.Schemas[1].Name)
+	assert.Equal(t, 0, len(result.Schemas[1].Fields))
+}
+
+func TestParseEventSchemaInvalid(t *testing.T) {
+	invalid := []string{
+		`{"name":"","fields":[]}`,
+		`{"name":"transfer","fields":[{"name":"a","type":"float"}]}`,
+		`{"name":"transfer","fields":[{"name":"a","type":"string"},{"name":"a","type":"integer"}]}`,
+		`{"name":"transfer","fields":[{"name":"","type":"string"}]}`,
+		`{"name":"transfer","fields":[null]}`,
+		`["transfer"]`,
+	}
+	for _, data := range invalid {
+		_, err := ParseEventSchema([]byte(data))
+		assert.NotNil(t, err, data)
+	}
+}
@@ -0,0 +1,109 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package common
+
+import (
+	"io"
+
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/common/serialization"
+)
+
+//Max records returned by one address index query
+const MAX_ADDRESS_INDEX_LIMIT = 100
+
+//AddressTx is a transaction touching an address, recorded by the address index
+type AddressTx struct {
+	TxHash common.Uint256
+	Height uint32
+}
+
+func (this *AddressTx) Serialize(w io.Writer) error {
+	if err := this.TxHash.Serialize(w); err != nil {
+		return err
+	}
+	return serialization.WriteUint32(w, this.Height)
+}
+
+func (this *AddressTx) Deserialize(r io.Reader) error {
+	if err := this.TxHash.Deserialize(r); err != nil {
+		return err
+	}
+	height, err := serialization.ReadUint32(r)
+	if err != nil {
+		return err
+	}
+	this.Height = height
+	return nil
+}
+
+//AddressTransfer is a ZPT or GALA transfer notify touching an address, recorded by the address index
+type AddressTransfer struct {
+	TxHash   common.Uint256
+	Height   uint32
+	Contract common.Address
+	From     common.Address
+	To       common.Address
+	Amount   uint64
+}
+
+func (this *AddressTransfer) Serialize(w io.Writer) error {
+	if err := this.TxHash.Serialize(w); err != nil {
+		return err
+	}
+	if err := serialization.WriteUint32(w, this.Height); err != nil {
+		return err
+	}
+	if err := this.Contract.Serialize(w); err != nil {
+		return err
+	}
+	if err := this.From.Serialize(w); err != nil {
+		return err
+	}
+	if err := this.To.Serialize(w); err != nil {
+		return err
+	}
+	return serialization.WriteUint64(w, this.Amount)
+}
+
+func (this *AddressTransfer) Deserialize(r io.Reader) error {
+	if err := this.TxHash.Deserialize(r); err != nil {
+		return err
+	}
+	height, err := serialization.ReadUint32(r)
+	if err != nil {
+		return err
+	}
+	if err := this.Contract.Deserialize(r); err != nil {
+		return err
+	}
+	if err := this.From.Deserialize(r); err != nil {
+		return err
+	}
+	if err := this.To.Deserialize(r); err != nil {
+		return err
+	}
+	amount, err := serialization.ReadUint64(r)
+	if err != nil {
+		return err
+	}
+	this.Height = height
+	this.Amount = amount
+	return nil
+}
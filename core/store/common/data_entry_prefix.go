@@ -44,19 +44,31 @@ const (
 	DATA_TRANSACTION                 = 0x02 //Transction hash = > transaction key prefix
 
 	// Transaction
-	ST_BOOKKEEPER DataEntryPrefix = 0x03 //BookKeeper state key prefix
-	ST_CONTRACT   DataEntryPrefix = 0x04 //Smart contract state key prefix
-	ST_STORAGE    DataEntryPrefix = 0x05 //Smart contract storage key prefix
-	ST_VALIDATOR  DataEntryPrefix = 0x07 //no use
-	ST_VOTE       DataEntryPrefix = 0x08 //Vote state key prefix
+	ST_BOOKKEEPER   DataEntryPrefix = 0x03 //BookKeeper state key prefix
+	ST_CONTRACT     DataEntryPrefix = 0x04 //Smart contract state key prefix
+	ST_STORAGE      DataEntryPrefix = 0x05 //Smart contract storage key prefix
+	ST_VALIDATOR    DataEntryPrefix = 0x07 //no use
+	ST_VOTE         DataEntryPrefix = 0x08 //Vote state key prefix
+	ST_EVENT_SCHEMA DataEntryPrefix = 0x0a //Smart contract event schema key prefix
+	ST_HISTORY      DataEntryPrefix = 0x19 //State key + block height => state value key prefix, only in archive mode
 
 	IX_HEADER_HASH_LIST DataEntryPrefix = 0x09 //Block height => block hash key prefix
+	IX_ADDRESS_TX       DataEntryPrefix = 0x17 //Address + block height + tx hash => empty key prefix
+	IX_ADDRESS_TRANSFER DataEntryPrefix = 0x18 //Address + block height + tx hash + notify index => transfer key prefix
 
 	//SYSTEM
 	SYS_CURRENT_BLOCK      DataEntryPrefix = 0x10 //Current block key prefix
 	SYS_VERSION            DataEntryPrefix = 0x11 //Store version key prefix
-	SYS_CURRENT_STATE_ROOT DataEntryPrefix = 0x12 //no use
+	SYS_CURRENT_STATE_ROOT DataEntryPrefix = 0x12 //Current state root key prefix
 	SYS_BLOCK_MERKLE_TREE  DataEntryPrefix = 0x13 // Block merkle tree root key prefix
+	SYS_STATE_ROOT         DataEntryPrefix = 0x15 //Block height => state root key prefix
+	SYS_STATE_MERKLE_TREE  DataEntryPrefix = 0x16 //State merkle tree node hash => node key prefix
+	SYS_ARCHIVE_START      DataEntryPrefix = 0x1a //Block height from which state history is kept key prefix
+	SYS_PRUNED_HEIGHT      DataEntryPrefix = 0x1b //Block height below which block bodies are pruned key prefix
+	SYS_ADDRESS_INDEX      DataEntryPrefix = 0x1c //Block height from which address index is kept key prefix
+	SYS_SNAPSHOT_HEIGHT    DataEntryPrefix = 0x1d //Block height of imported snapshot key prefix
+	SYS_STATE_MERKLE_REF   DataEntryPrefix = 0x1e //State merkle tree node hash => reference count key prefix
+	SYS_STATE_ROOT_PRUNED  DataEntryPrefix = 0x1f //Block height below which state roots are released key prefix
 
 	EVENT_NOTIFY DataEntryPrefix = 0x14 //Event notify key prefix
 )
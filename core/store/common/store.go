@@ -66,6 +66,8 @@ type PersistStore interface {
 	BatchPut(key []byte, value []byte)       //Put a key-value pair to batch
 	BatchDelete(key []byte)                  //Delete the key in batch
 	BatchCommit() error                      //Commit batch to store
+	BatchData() []byte                       //Return the encoded operations in batch
+	CommitBatchData(data []byte) error       //Commit the operations encoded by BatchData to store and sync to disk
 	Close() error                            //Close store
 	NewIterator(prefix []byte) StoreIterator //Return the iterator of store
 }
@@ -82,6 +84,8 @@ type StateStore interface {
 	TryDelete(prefix DataEntryPrefix, key []byte)
 	//iterator key in store
 	Find(prefix DataEntryPrefix, key []byte) ([]*StateItem, error)
+	//Iterate the items whose key start with key in key order, until fn return error
+	Iterate(prefix DataEntryPrefix, key []byte, fn func(item *StateItem) error) error
 }
 
 //MemoryCacheStore
@@ -132,3 +136,38 @@ func (e *StateItem) Copy() *StateItem {
 	c := *e
 	return &c
 }
+
+//OverlayIterate call fn in key order with the items of iterate, where the item of overlay with the same key
+//takes the place of it, and the deleted items of overlay are skipped. The overlay should be sorted by key
+func OverlayIterate(overlay []*StateItem, iterate func(fn func(item *StateItem) error) error,
+	fn func(item *StateItem) error) error {
+	i := 0
+	next := func() error {
+		item := overlay[i]
+		i++
+		if item.State == Deleted {
+			return nil
+		}
+		return fn(item)
+	}
+	err := iterate(func(item *StateItem) error {
+		for i < len(overlay) && overlay[i].Key < item.Key {
+			if err := next(); err != nil {
+				return err
+			}
+		}
+		if i < len(overlay) && overlay[i].Key == item.Key {
+			return next()
+		}
+		return fn(item)
+	})
+	if err != nil {
+		return err
+	}
+	for i < len(overlay) {
+		if err := next(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,238 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/common/log"
+	"github.com/imZhuFei/zeepin/common/serialization"
+	scom "github.com/imZhuFei/zeepin/core/store/common"
+	"github.com/imZhuFei/zeepin/core/types"
+	"github.com/imZhuFei/zeepin/smartcontract/event"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/utils"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/zpt"
+)
+
+//initAddressIndex save the height from which the address index of event store is kept. The index is built from the
+//transfer notifications, which are only saved with event log. The blocks saved before the index is enabled are not
+//indexed, so the index enabled on a ledger with blocks, or enabled again after disabled, starts from the next block.
+func (this *EventStore) initAddressIndex(enable bool, enableEventLog bool) error {
+	_, err := this.store.Get(this.getAddressIndexKey())
+	if err != nil && err != scom.ErrNotFound {
+		return err
+	}
+	indexed := err == nil
+	if !enable {
+		if indexed {
+			log.Warnf("address index is off, blocks saved before it is enabled again are not indexed")
+			return this.store.Delete(this.getAddressIndexKey())
+		}
+		return nil
+	}
+	if !enableEventLog {
+		return fmt.Errorf("address index needs event log")
+	}
+	if indexed {
+		return nil
+	}
+	_, height, err := this.GetCurrentBlock()
+	if err != nil && err != scom.ErrNotFound {
+		return err
+	}
+	start := uint32(0)
+	if err == nil {
+		start = height + 1
+		log.Warnf("address index starts from height %d, blocks up to height %d are not indexed", start, height)
+	}
+	value := bytes.NewBuffer(nil)
+	serialization.WriteUint32(value, start)
+	return this.store.Put(this.getAddressIndexKey(), value.Bytes())
+}
+
+//GetAddressIndexStartHeight return the height from which transactions are indexed by address
+func (this *EventStore) GetAddressIndexStartHeight() (uint32, error) {
+	value, err := this.store.Get(this.getAddressIndexKey())
+	if err != nil {
+		return 0, err
+	}
+	return serialization.ReadUint32(bytes.NewReader(value))
+}
+
+func (this *EventStore) getAddressIndexKey() []byte {
+	return []byte{byte(scom.SYS_ADDRESS_INDEX)}
+}
+
+//SaveAddressIndex index the transaction by the payer, the signers and the parties of its zpt and gala transfers.
+//Index keys put big endian height after the address, so the records of an address are ordered by block height
+func (this *EventStore) SaveAddressIndex(height uint32, tx *types.Transaction, notify *event.ExecuteNotify) error {
+	txHash := tx.Hash()
+	addrs := make(map[common.Address]bool)
+	addrs[tx.Payer] = true
+	for _, addr := range tx.GetSignatureAddresses() {
+		addrs[addr] = true
+	}
+	for i, n := range notify.Notify {
+		transfer, ok := parseTransferNotify(n)
+		if !ok {
+			continue
+		}
+		transfer.TxHash = txHash
+		transfer.Height = height
+		value := bytes.NewBuffer(nil)
+		if err := transfer.Serialize(value); err != nil {
+			return fmt.Errorf("serialize transfer error %s", err)
+		}
+		for _, addr := range []common.Address{transfer.From, transfer.To} {
+			key := getAddressIndexKey(scom.IX_ADDRESS_TRANSFER, addr, height, txHash)
+			key = append(key, make([]byte, 4)...)
+			binary.BigEndian.PutUint32(key[len(key)-4:], uint32(i))
+			this.store.BatchPut(key, value.Bytes())
+			addrs[addr] = true
+		}
+	}
+	value := bytes.NewBuffer(nil)
+	record := &scom.AddressTx{TxHash: txHash, Height: height}
+	if err := record.Serialize(value); err != nil {
+		return fmt.Errorf("serialize address tx error %s", err)
+	}
+	for addr := range addrs {
+		this.store.BatchPut(getAddressIndexKey(scom.IX_ADDRESS_TX, addr, height, txHash), value.Bytes())
+	}
+	return nil
+}
+
+//GetTransactionsByAddress return the transactions of address, newest first.
+//start is the cursor returned by the previous page, empty for the first page
+func (this *EventStore) GetTransactionsByAddress(addr common.Address, start []byte, limit uint32) ([]*scom.AddressTx, []byte, error) {
+	txs := make([]*scom.AddressTx, 0)
+	next, err := this.iterateAddressIndex(scom.IX_ADDRESS_TX, addr, start, limit, func(value []byte) error {
+		record := new(scom.AddressTx)
+		if err := record.Deserialize(bytes.NewReader(value)); err != nil {
+			return err
+		}
+		txs = append(txs, record)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return txs, next, nil
+}
+
+//GetTransfersByAddress return the zpt and gala transfers of address, newest first.
+//start is the cursor returned by the previous page, empty for the first page
+func (this *EventStore) GetTransfersByAddress(addr common.Address, start []byte, limit uint32) ([]*scom.AddressTransfer, []byte, error) {
+	transfers := make([]*scom.AddressTransfer, 0)
+	next, err := this.iterateAddressIndex(scom.IX_ADDRESS_TRANSFER, addr, start, limit, func(value []byte) error {
+		record := new(scom.AddressTransfer)
+		if err := record.Deserialize(bytes.NewReader(value)); err != nil {
+			return err
+		}
+		transfers = append(transfers, record)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return transfers, next, nil
+}
+
+//iterateAddressIndex walk the index of address backward from start, and return the cursor of next page
+func (this *EventStore) iterateAddressIndex(prefix scom.DataEntryPrefix, addr common.Address, start []byte, limit uint32,
+	handler func(value []byte) error) ([]byte, error) {
+	if limit == 0 || limit > scom.MAX_ADDRESS_INDEX_LIMIT {
+		limit = scom.MAX_ADDRESS_INDEX_LIMIT
+	}
+	indexPrefix := append([]byte{byte(prefix)}, addr[:]...)
+	iter := this.store.NewIterator(indexPrefix)
+	defer iter.Release()
+
+	var ok bool
+	if len(start) == 0 {
+		ok = iter.Last()
+	} else {
+		seekKey := append(indexPrefix, start...)
+		if !iter.Seek(seekKey) {
+			ok = iter.Last()
+		} else if bytes.Compare(iter.Key(), seekKey) > 0 {
+			ok = iter.Prev()
+		} else {
+			ok = true
+		}
+	}
+	count := uint32(0)
+	for ; ok; ok = iter.Prev() {
+		if count == limit {
+			return append([]byte{}, iter.Key()[len(indexPrefix):]...), nil
+		}
+		if err := handler(iter.Value()); err != nil {
+			return nil, fmt.Errorf("deserialize address index error %s", err)
+		}
+		count++
+	}
+	return nil, nil
+}
+
+func getAddressIndexKey(prefix scom.DataEntryPrefix, addr common.Address, height uint32, txHash common.Uint256) []byte {
+	key := make([]byte, 0, 1+common.ADDR_LEN+4+common.UINT256_SIZE)
+	key = append(key, byte(prefix))
+	key = append(key, addr[:]...)
+	key = append(key, make([]byte, 4)...)
+	binary.BigEndian.PutUint32(key[len(key)-4:], height)
+	return append(key, txHash[:]...)
+}
+
+//parseTransferNotify parse the notify emitted by zpt.AddNotifications
+func parseTransferNotify(n *event.NotifyEventInfo) (*scom.AddressTransfer, bool) {
+	if n.ContractAddress != utils.ZptContractAddress && n.ContractAddress != utils.GalaContractAddress {
+		return nil, false
+	}
+	states, ok := n.States.([]interface{})
+	if !ok || len(states) != 4 {
+		return nil, false
+	}
+	if name, ok := states[0].(string); !ok || name != zpt.TRANSFER_NAME {
+		return nil, false
+	}
+	fromStr, ok := states[1].(string)
+	if !ok {
+		return nil, false
+	}
+	toStr, ok := states[2].(string)
+	if !ok {
+		return nil, false
+	}
+	amount, ok := states[3].(uint64)
+	if !ok {
+		return nil, false
+	}
+	from, err := common.AddressFromBase58(fromStr)
+	if err != nil {
+		return nil, false
+	}
+	to, err := common.AddressFromBase58(toStr)
+	if err != nil {
+		return nil, false
+	}
+	return &scom.AddressTransfer{Contract: n.ContractAddress, From: from, To: to, Amount: amount}, true
+}
@@ -0,0 +1,165 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"testing"
+
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/core/payload"
+	"github.com/imZhuFei/zeepin/core/types"
+	"github.com/imZhuFei/zeepin/smartcontract/event"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/utils"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/zpt"
+)
+
+func TestAddressIndex(t *testing.T) {
+	eventStore, err := NewEventStore("test/addressindex")
+	if err != nil {
+		t.Errorf("NewEventStore error %s", err)
+		return
+	}
+	defer eventStore.Close()
+
+	payer := common.Address{1}
+	receiver := common.Address{2}
+	txs := make([]*types.Transaction, 0)
+	eventStore.NewBatch()
+	for i := uint32(0); i < 3; i++ {
+		tx := &types.Transaction{
+			TxType:  types.Invoke,
+			Nonce:   i,
+			Payer:   payer,
+			Payload: &payload.InvokeCode{},
+		}
+		notify := &event.ExecuteNotify{TxHash: tx.Hash(), State: event.CONTRACT_STATE_SUCCESS}
+		if i == 1 {
+			notify.Notify = []*event.NotifyEventInfo{
+				{
+					ContractAddress: utils.ZptContractAddress,
+					States:          []interface{}{zpt.TRANSFER_NAME, payer.ToBase58(), receiver.ToBase58(), uint64(100)},
+				},
+				{
+					ContractAddress: common.Address{3},
+					States:          []interface{}{zpt.TRANSFER_NAME, payer.ToBase58(), receiver.ToBase58(), uint64(200)},
+				},
+			}
+		}
+		if err := eventStore.SaveAddressIndex(10+i, tx, notify); err != nil {
+			t.Errorf("SaveAddressIndex error %s", err)
+			return
+		}
+		txs = append(txs, tx)
+	}
+	if err := eventStore.CommitTo(); err != nil {
+		t.Errorf("CommitTo error %s", err)
+		return
+	}
+
+	records, next, err := eventStore.GetTransactionsByAddress(payer, nil, 2)
+	if err != nil {
+		t.Errorf("GetTransactionsByAddress error %s", err)
+		return
+	}
+	if len(records) != 2 || records[0].TxHash != txs[2].Hash() || records[1].TxHash != txs[1].Hash() || records[1].Height != 11 {
+		t.Errorf("GetTransactionsByAddress first page unexpected %v", records)
+		return
+	}
+	records, next, err = eventStore.GetTransactionsByAddress(payer, next, 2)
+	if err != nil {
+		t.Errorf("GetTransactionsByAddress error %s", err)
+		return
+	}
+	if len(records) != 1 || records[0].TxHash != txs[0].Hash() || next != nil {
+		t.Errorf("GetTransactionsByAddress second page unexpected %v next %x", records, next)
+		return
+	}
+
+	records, _, err = eventStore.GetTransactionsByAddress(receiver, nil, 0)
+	if err != nil {
+		t.Errorf("GetTransactionsByAddress error %s", err)
+		return
+	}
+	if len(records) != 1 || records[0].TxHash != txs[1].Hash() {
+		t.Errorf("GetTransactionsByAddress receiver unexpected %v", records)
+		return
+	}
+
+	transfers, next, err := eventStore.GetTransfersByAddress(receiver, nil, 0)
+	if err != nil {
+		t.Errorf("GetTransfersByAddress error %s", err)
+		return
+	}
+	if len(transfers) != 1 || next != nil {
+		t.Errorf("GetTransfersByAddress count %d != 1", len(transfers))
+		return
+	}
+	transfer := transfers[0]
+	if transfer.TxHash != txs[1].Hash() || transfer.Height != 11 || transfer.Contract != utils.ZptContractAddress ||
+		transfer.From != payer || transfer.To != receiver || transfer.Amount != 100 {
+		t.Errorf("GetTransfersByAddress unexpected transfer %+v", transfer)
+		return
+	}
+}
+
+func TestInitAddressIndex(t *testing.T) {
+	eventStore, err := NewEventStore("test/addressindexinit")
+	if err != nil {
+		t.Errorf("NewEventStore error %s", err)
+		return
+	}
+	defer eventStore.Close()
+
+	if err = eventStore.initAddressIndex(true, false); err == nil {
+		t.Errorf("TestInitAddressIndex address index should need event log")
+		return
+	}
+	if err = eventStore.initAddressIndex(true, true); err != nil {
+		t.Errorf("TestInitAddressIndex init on empty ledger error %s", err)
+		return
+	}
+	eventStore.NewBatch()
+	eventStore.SaveCurrentBlock(0, common.Uint256{1})
+	if err = eventStore.CommitTo(); err != nil {
+		t.Errorf("CommitTo error %s", err)
+		return
+	}
+	if err = eventStore.initAddressIndex(true, true); err != nil {
+		t.Errorf("TestInitAddressIndex init on indexed ledger error %s", err)
+		return
+	}
+	if err = eventStore.initAddressIndex(false, true); err != nil {
+		t.Errorf("TestInitAddressIndex disable error %s", err)
+		return
+	}
+	//enabled again on a ledger with blocks, the index starts from the next block
+	if err = eventStore.initAddressIndex(true, true); err != nil {
+		t.Errorf("TestInitAddressIndex init on ledger with blocks error %s", err)
+		return
+	}
+	start, err := eventStore.GetAddressIndexStartHeight()
+	if err != nil {
+		t.Errorf("GetAddressIndexStartHeight error %s", err)
+		return
+	}
+	if start != 1 {
+		t.Errorf("TestInitAddressIndex start height %d != 1", start)
+		return
+	}
+}
@@ -0,0 +1,88 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"fmt"
+
+	vconfig "github.com/imZhuFei/zeepin/consensus/vbft/config"
+	scom "github.com/imZhuFei/zeepin/core/store/common"
+	"github.com/imZhuFei/zeepin/core/types"
+)
+
+const (
+	MIN_KEEP_BLOCKS  = uint32(128)  //Min count of recent blocks whose body is kept by pruning, and saved in snapshot
+	PRUNE_BATCH_SIZE = uint32(1000) //Max count of blocks pruned each time a block is saved
+)
+
+//pruneBlocks delete the transactions and event notifies of the blocks older than keep count of recent blocks
+//in current batch of block store and event store. Headers are kept, so are the bodies of genesis block and
+//config blocks which consensus loads on startup. At most PRUNE_BATCH_SIZE blocks are pruned each time,
+//so a node which turns pruning on catches up gradually.
+func (this *LedgerStoreImp) pruneBlocks(blockHeight, keep uint32) error {
+	if keep < MIN_KEEP_BLOCKS {
+		keep = MIN_KEEP_BLOCKS
+	}
+	if blockHeight < keep {
+		return nil
+	}
+	endHeight := blockHeight - keep + 1
+	startHeight, err := this.blockStore.GetPrunedHeight()
+	if err != nil && err != scom.ErrNotFound {
+		return fmt.Errorf("GetPrunedHeight error %s", err)
+	}
+	if startHeight >= endHeight {
+		return nil
+	}
+	if endHeight-startHeight > PRUNE_BATCH_SIZE {
+		endHeight = startHeight + PRUNE_BATCH_SIZE
+	}
+	for height := startHeight; height < endHeight; height++ {
+		blockHash, err := this.blockStore.GetBlockHash(height)
+		if err != nil {
+			return fmt.Errorf("GetBlockHash height %d error %s", height, err)
+		}
+		header, err := this.blockStore.GetHeader(blockHash)
+		if err != nil {
+			return fmt.Errorf("GetHeader height %d error %s", height, err)
+		}
+		if isKeptBlock(header) {
+			continue
+		}
+		txHashes, err := this.blockStore.PruneBlock(blockHash)
+		if err != nil {
+			return fmt.Errorf("PruneBlock height %d error %s", height, err)
+		}
+		err = this.eventStore.DeleteEventNotify(height, txHashes)
+		if err != nil {
+			return fmt.Errorf("DeleteEventNotify height %d error %s", height, err)
+		}
+	}
+	this.blockStore.SavePrunedHeight(endHeight)
+	return nil
+}
+
+//isKeptBlock return whether the body of block is never pruned, that is genesis block or block with new chain config
+func isKeptBlock(header *types.Header) bool {
+	if header.Height == 0 {
+		return true
+	}
+	blkInfo, err := vconfig.VbftBlock(header)
+	return err == nil && blkInfo.NewChainConfig != nil
+}
@@ -0,0 +1,116 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	scom "github.com/imZhuFei/zeepin/core/store/common"
+)
+
+func TestPruneBlocks(t *testing.T) {
+	dataDir := "test/prune"
+	count := MIN_KEEP_BLOCKS + 50
+	blocks, err := saveTestChain(dataDir, count)
+	if err != nil {
+		t.Errorf("saveTestChain error %s", err)
+		return
+	}
+	blockStore, err := NewBlockStore(fmt.Sprintf("%s%s%s", dataDir, string(os.PathSeparator), DBDirBlock), false)
+	if err != nil {
+		t.Errorf("NewBlockStore error %s", err)
+		return
+	}
+	defer blockStore.Close()
+	eventStore, err := NewEventStore(fmt.Sprintf("%s%s%s", dataDir, string(os.PathSeparator), DBDirEvent))
+	if err != nil {
+		t.Errorf("NewEventStore error %s", err)
+		return
+	}
+	defer eventStore.Close()
+	ledgerStore := &LedgerStoreImp{
+		blockStore: blockStore,
+		eventStore: eventStore,
+	}
+
+	prune := func(height, keep uint32) error {
+		blockStore.NewBatch()
+		eventStore.NewBatch()
+		err := ledgerStore.pruneBlocks(height, keep)
+		if err != nil {
+			return err
+		}
+		err = blockStore.CommitTo()
+		if err != nil {
+			return err
+		}
+		return eventStore.CommitTo()
+	}
+	//keep is raised to MIN_KEEP_BLOCKS
+	err = prune(count-2, 10)
+	if err != nil {
+		t.Errorf("pruneBlocks error %s", err)
+		return
+	}
+	err = prune(count-1, 10)
+	if err != nil {
+		t.Errorf("pruneBlocks error %s", err)
+		return
+	}
+	prunedHeight, err := blockStore.GetPrunedHeight()
+	if err != nil || prunedHeight != count-MIN_KEEP_BLOCKS {
+		t.Errorf("TestPruneBlocks pruned height %d error %v", prunedHeight, err)
+		return
+	}
+
+	for _, block := range blocks {
+		height := block.Header.Height
+		blockHash := block.Hash()
+		txHash := block.Transactions[0].Hash()
+		_, err = blockStore.GetHeader(blockHash)
+		if err != nil {
+			t.Errorf("TestPruneBlocks GetHeader height %d error %s", height, err)
+			return
+		}
+		pruned := height > 0 && height < prunedHeight
+		_, err = blockStore.GetBlock(blockHash)
+		if pruned != (err != nil) {
+			t.Errorf("TestPruneBlocks GetBlock height %d pruned %v error %v", height, pruned, err)
+			return
+		}
+		//a pruned transaction is still contained, so it can't be replayed
+		exist, err := blockStore.ContainTransaction(txHash)
+		if err != nil || !exist {
+			t.Errorf("TestPruneBlocks ContainTransaction height %d pruned %v error %v", height, pruned, err)
+			return
+		}
+		_, txHeight, err := blockStore.GetTransaction(txHash)
+		if pruned != (err == scom.ErrNotFound) || txHeight != height {
+			t.Errorf("TestPruneBlocks GetTransaction height %d pruned %v height %d error %v", height, pruned, txHeight, err)
+			return
+		}
+		_, err = eventStore.GetEventNotifyByTx(txHash)
+		if pruned != (err != nil) {
+			t.Errorf("TestPruneBlocks GetEventNotifyByTx height %d pruned %v error %v", height, pruned, err)
+			return
+		}
+	}
+}
@@ -138,6 +138,9 @@ func (this *BlockStore) GetBlock(blockHash common.Uint256) (*types.Block, error)
 	txList := make([]*types.Transaction, 0, len(txHashes))
 	for _, txHash := range txHashes {
 		tx, _, err := this.GetTransaction(txHash)
+		if err == scom.ErrNotFound {
+			return nil, fmt.Errorf("transactions of block %s are pruned", blockHash.ToHexString())
+		}
 		if err != nil {
 			return nil, fmt.Errorf("GetTransaction %s error %s", txHash.ToHexString(), err)
 		}
@@ -400,6 +403,10 @@ func (this *BlockStore) loadTransaction(txHash common.Uint256) (*types.Transacti
 	if err != nil {
 		return nil, 0, fmt.Errorf("ReadUint32 error %s", err)
 	}
+	if reader.Len() == 0 {
+		//transaction of pruned block, only the height is kept
+		return nil, height, scom.ErrNotFound
+	}
 	tx = new(types.Transaction)
 	err = tx.Deserialize(reader)
 	if err != nil {
@@ -427,6 +434,41 @@ func (this *BlockStore) ContainTransaction(txHash common.Uint256) (bool, error)
 	return true, nil
 }
 
+//PruneBlock delete the transactions of block specified by block hash, and return the transaction hashes of it.
+//The header of block is kept, and each transaction is replaced by its block height, so ContainTransaction
+//still finds it and a pruned transaction can't be replayed
+func (this *BlockStore) PruneBlock(blockHash common.Uint256) ([]common.Uint256, error) {
+	header, txHashes, err := this.loadHeaderWithTx(blockHash)
+	if err != nil {
+		return nil, err
+	}
+	value := bytes.NewBuffer(nil)
+	serialization.WriteUint32(value, header.Height)
+	for _, txHash := range txHashes {
+		if this.enableCache {
+			this.cache.DeleteTransaction(txHash)
+		}
+		this.store.BatchPut(this.getTransactionKey(txHash), value.Bytes())
+	}
+	return txHashes, nil
+}
+
+//GetPrunedHeight return the height below which block bodies have been pruned
+func (this *BlockStore) GetPrunedHeight() (uint32, error) {
+	value, err := this.store.Get(this.getPrunedHeightKey())
+	if err != nil {
+		return 0, err
+	}
+	return serialization.ReadUint32(bytes.NewReader(value))
+}
+
+//SavePrunedHeight persist the height below which block bodies have been pruned
+func (this *BlockStore) SavePrunedHeight(height uint32) {
+	value := bytes.NewBuffer(nil)
+	serialization.WriteUint32(value, height)
+	this.store.BatchPut(this.getPrunedHeightKey(), value.Bytes())
+}
+
 //GetVersion return the version of store
 func (this *BlockStore) GetVersion() (byte, error) {
 	key := this.getVersionKey()
@@ -499,6 +541,10 @@ func (this *BlockStore) getVersionKey() []byte {
 	return []byte{byte(scom.SYS_VERSION)}
 }
 
+func (this *BlockStore) getPrunedHeightKey() []byte {
+	return []byte{byte(scom.SYS_PRUNED_HEIGHT)}
+}
+
 func (this *BlockStore) getHeaderIndexListKey(startHeight uint32) []byte {
 	key := bytes.NewBuffer(nil)
 	key.WriteByte(byte(scom.IX_HEADER_HASH_LIST))
@@ -151,6 +151,19 @@ func (this *EventStore) GetEventNotifyByBlock(height uint32) ([]*event.ExecuteNo
 	return evtNotifies, nil
 }
 
+//DeleteEventNotify delete the event notify of block and of the transactions in it
+func (this *EventStore) DeleteEventNotify(height uint32, txHashs []common.Uint256) error {
+	key, err := this.getEventNotifyByBlockKey(height)
+	if err != nil {
+		return err
+	}
+	this.store.BatchDelete(key)
+	for _, txHash := range txHashs {
+		this.store.BatchDelete(this.getEventNotifyByTxKey(txHash))
+	}
+	return nil
+}
+
 //CommitTo event store batch to store
 func (this *EventStore) CommitTo() error {
 	return this.store.BatchCommit()
@@ -0,0 +1,121 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/core/payload"
+	"github.com/imZhuFei/zeepin/core/states"
+	scom "github.com/imZhuFei/zeepin/core/store/common"
+	"github.com/imZhuFei/zeepin/core/types"
+)
+
+//historyLedgerStore is the view of ledger after the block of height is executed, which is used to pre-execute
+//transactions against history states. Blocks above height are not visible, and contracts are read from state history
+type historyLedgerStore struct {
+	*LedgerStoreImp
+	height    uint32
+	blockHash common.Uint256
+}
+
+func newHistoryLedgerStore(ledger *LedgerStoreImp, height uint32) *historyLedgerStore {
+	return &historyLedgerStore{
+		LedgerStoreImp: ledger,
+		height:         height,
+		blockHash:      ledger.GetBlockHash(height),
+	}
+}
+
+func (this *historyLedgerStore) GetCurrentBlockHash() common.Uint256 {
+	return this.blockHash
+}
+
+func (this *historyLedgerStore) GetCurrentBlockHeight() uint32 {
+	return this.height
+}
+
+func (this *historyLedgerStore) GetCurrentHeaderHash() common.Uint256 {
+	return this.blockHash
+}
+
+func (this *historyLedgerStore) GetCurrentHeaderHeight() uint32 {
+	return this.height
+}
+
+func (this *historyLedgerStore) GetBlockHash(height uint32) common.Uint256 {
+	if height > this.height {
+		return common.Uint256{}
+	}
+	return this.LedgerStoreImp.GetBlockHash(height)
+}
+
+func (this *historyLedgerStore) GetHeaderByHash(blockHash common.Uint256) (*types.Header, error) {
+	header, err := this.LedgerStoreImp.GetHeaderByHash(blockHash)
+	if err != nil {
+		return nil, err
+	}
+	if header.Height > this.height {
+		return nil, scom.ErrNotFound
+	}
+	return header, nil
+}
+
+func (this *historyLedgerStore) GetHeaderByHeight(height uint32) (*types.Header, error) {
+	if height > this.height {
+		return nil, nil
+	}
+	return this.LedgerStoreImp.GetHeaderByHeight(height)
+}
+
+func (this *historyLedgerStore) GetBlockByHash(blockHash common.Uint256) (*types.Block, error) {
+	block, err := this.LedgerStoreImp.GetBlockByHash(blockHash)
+	if err != nil {
+		return nil, err
+	}
+	if block.Header.Height > this.height {
+		return nil, scom.ErrNotFound
+	}
+	return block, nil
+}
+
+func (this *historyLedgerStore) GetBlockByHeight(height uint32) (*types.Block, error) {
+	if height > this.height {
+		return nil, nil
+	}
+	return this.LedgerStoreImp.GetBlockByHeight(height)
+}
+
+func (this *historyLedgerStore) GetTransaction(txHash common.Uint256) (*types.Transaction, uint32, error) {
+	tx, height, err := this.LedgerStoreImp.GetTransaction(txHash)
+	if err != nil {
+		return nil, 0, err
+	}
+	if height > this.height {
+		return nil, 0, scom.ErrNotFound
+	}
+	return tx, height, nil
+}
+
+func (this *historyLedgerStore) GetContractState(contractHash common.Address) (*payload.DeployCode, error) {
+	return this.stateStore.GetContractStateAt(contractHash, this.height)
+}
+
+func (this *historyLedgerStore) GetStorageItem(key *states.StorageKey) (*states.StorageItem, error) {
+	return this.stateStore.GetStorageStateAt(key, this.height)
+}
@@ -0,0 +1,328 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"runtime"
+
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/common/log"
+	"github.com/imZhuFei/zeepin/common/serialization"
+	scom "github.com/imZhuFei/zeepin/core/store/common"
+	"github.com/imZhuFei/zeepin/core/store/leveldbstore"
+)
+
+//A block is committed to block store, state store and event store, which are independent leveldb instances,
+//through a write-ahead journal:
+//  1. the hash of block is appended to merkle tree file and flushed while saving block to state store
+//  2. the batches of all stores are written to journal file, the file and its directory are synced
+//  3. the batches are committed to block store, state store and event store in turn, each synced to disk
+//  4. the journal file is removed and its directory is synced
+//So the journal is only gone after all stores are on disk, even if the machine loses power.
+//A journal left by a crash is replayed when ledger store is opened, before any store is loaded. Replaying a batch
+//which was already committed is harmless, since a batch only contains puts and deletes. A journal which is torn
+//is discarded, then none of the stores has been touched. Extra hashes in merkle tree file are overwritten, since
+//merkle tree file is reopened at the tree size saved in state store.
+
+var (
+	//errJournalCorrupted is returned when the journal file is incomplete or its checksum mismatch
+	errJournalCorrupted = errors.New("journal corrupted")
+
+	//commitFaultHook is called at every stage of committing a block, returning error aborts the commit.
+	//It's only set by test to simulate a crash at the stage.
+	commitFaultHook func(stage int) error
+)
+
+//Stages of committing a block
+const (
+	commitStageBegin   = iota //Block saved to batches, journal not written
+	commitStageJournal        //Journal written
+	commitStageBlock          //Block store committed
+	commitStageState          //State store committed
+	commitStageEvent          //Event store committed, journal not removed
+)
+
+//journalRecord is the batches of stores of a block
+type journalRecord struct {
+	Height     uint32
+	BlockHash  common.Uint256
+	BlockBatch []byte
+	StateBatch []byte
+	EventBatch []byte
+}
+
+func (this *journalRecord) Serialize() ([]byte, error) {
+	w := bytes.NewBuffer(nil)
+	err := serialization.WriteUint32(w, this.Height)
+	if err != nil {
+		return nil, err
+	}
+	err = this.BlockHash.Serialize(w)
+	if err != nil {
+		return nil, err
+	}
+	for _, batch := range [][]byte{this.BlockBatch, this.StateBatch, this.EventBatch} {
+		err = serialization.WriteVarBytes(w, batch)
+		if err != nil {
+			return nil, err
+		}
+	}
+	checksum := sha256.Sum256(w.Bytes())
+	w.Write(checksum[:])
+	return w.Bytes(), nil
+}
+
+func (this *journalRecord) Deserialize(data []byte) error {
+	if len(data) < sha256.Size {
+		return errJournalCorrupted
+	}
+	body := data[:len(data)-sha256.Size]
+	checksum := sha256.Sum256(body)
+	if !bytes.Equal(checksum[:], data[len(body):]) {
+		return errJournalCorrupted
+	}
+	r := bytes.NewReader(body)
+	var err error
+	this.Height, err = serialization.ReadUint32(r)
+	if err != nil {
+		return err
+	}
+	err = this.BlockHash.Deserialize(r)
+	if err != nil {
+		return err
+	}
+	this.BlockBatch, err = serialization.ReadVarBytes(r)
+	if err != nil {
+		return err
+	}
+	this.StateBatch, err = serialization.ReadVarBytes(r)
+	if err != nil {
+		return err
+	}
+	this.EventBatch, err = serialization.ReadVarBytes(r)
+	return err
+}
+
+//journal is the write-ahead journal file of ledger store
+type journal struct {
+	dir  string
+	path string
+}
+
+func newJournal(dataDir string) *journal {
+	return &journal{
+		dir:  dataDir,
+		path: fmt.Sprintf("%s%s%s", dataDir, string(os.PathSeparator), JournalFile),
+	}
+}
+
+//write save record to journal file and sync it to disk
+func (this *journal) write(record *journalRecord) error {
+	data, err := record.Serialize()
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(this.path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(data)
+	if err != nil {
+		file.Close()
+		return err
+	}
+	err = file.Sync()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	err = file.Close()
+	if err != nil {
+		return err
+	}
+	return syncDir(this.dir)
+}
+
+//read return the record in journal file, or nil if there is no journal
+func (this *journal) read() (*journalRecord, error) {
+	data, err := ioutil.ReadFile(this.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	record := &journalRecord{}
+	err = record.Deserialize(data)
+	if err != nil {
+		return nil, errJournalCorrupted
+	}
+	return record, nil
+}
+
+//remove delete the journal file
+func (this *journal) remove() error {
+	err := os.Remove(this.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return syncDir(this.dir)
+}
+
+//syncDir sync the directory entries of dir to disk, so that a created or removed file survives power loss
+func syncDir(dir string) error {
+	if runtime.GOOS == "windows" {
+		//directory can't be synced on windows
+		return nil
+	}
+	file, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	err = file.Sync()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func onCommitStage(stage int) error {
+	if commitFaultHook == nil {
+		return nil
+	}
+	return commitFaultHook(stage)
+}
+
+//commitStores commit the batches of block store, state store and event store through journal.
+//The batches are committed as written in journal, with sync.
+func (this *LedgerStoreImp) commitStores(blockHeight uint32, blockHash common.Uint256) error {
+	err := onCommitStage(commitStageBegin)
+	if err != nil {
+		return err
+	}
+	record := &journalRecord{
+		Height:     blockHeight,
+		BlockHash:  blockHash,
+		BlockBatch: this.blockStore.store.BatchData(),
+		StateBatch: this.stateStore.store.BatchData(),
+		EventBatch: this.eventStore.store.BatchData(),
+	}
+	err = this.journal.write(record)
+	if err != nil {
+		return fmt.Errorf("write journal error %s", err)
+	}
+	err = onCommitStage(commitStageJournal)
+	if err != nil {
+		return err
+	}
+	err = this.blockStore.store.CommitBatchData(record.BlockBatch)
+	if err != nil {
+		return fmt.Errorf("commit block store error %s", err)
+	}
+	err = onCommitStage(commitStageBlock)
+	if err != nil {
+		return err
+	}
+	err = this.stateStore.store.CommitBatchData(record.StateBatch)
+	if err != nil {
+		return fmt.Errorf("commit state store error %s", err)
+	}
+	err = onCommitStage(commitStageState)
+	if err != nil {
+		return err
+	}
+	err = this.eventStore.store.CommitBatchData(record.EventBatch)
+	if err != nil {
+		return fmt.Errorf("commit event store error %s", err)
+	}
+	err = onCommitStage(commitStageEvent)
+	if err != nil {
+		return err
+	}
+	err = this.journal.remove()
+	if err != nil {
+		return fmt.Errorf("remove journal error %s", err)
+	}
+	return nil
+}
+
+//recoverJournal replay the journal left by an interrupted commit to the stores in dataDir
+func recoverJournal(dataDir string, journal *journal) error {
+	record, err := journal.read()
+	if err == errJournalCorrupted {
+		log.Warnf("discard corrupted journal %s", journal.path)
+		return journal.remove()
+	}
+	if err != nil {
+		return fmt.Errorf("read journal error %s", err)
+	}
+	if record == nil {
+		return nil
+	}
+	blockStore, err := NewBlockStore(fmt.Sprintf("%s%s%s", dataDir, string(os.PathSeparator), DBDirBlock), false)
+	if err != nil {
+		return fmt.Errorf("NewBlockStore error %s", err)
+	}
+	_, currentHeight, err := blockStore.GetCurrentBlock()
+	blockStore.Close()
+	if err != nil && err != scom.ErrNotFound {
+		return fmt.Errorf("blockStore.GetCurrentBlock error %s", err)
+	}
+	if err == nil && currentHeight > record.Height {
+		log.Warnf("discard stale journal of block height:%d current height:%d", record.Height, currentHeight)
+		return journal.remove()
+	}
+	log.Infof("replay journal of block height:%d hash:%s", record.Height, record.BlockHash.ToHexString())
+	for _, item := range []struct {
+		dbDir string
+		batch []byte
+	}{
+		{DBDirBlock, record.BlockBatch},
+		{DBDirState, record.StateBatch},
+		{DBDirEvent, record.EventBatch},
+	} {
+		err = replayBatch(fmt.Sprintf("%s%s%s", dataDir, string(os.PathSeparator), item.dbDir), item.batch)
+		if err != nil {
+			return fmt.Errorf("replay journal to %s error %s", item.dbDir, err)
+		}
+	}
+	return journal.remove()
+}
+
+func replayBatch(dbDir string, batch []byte) error {
+	if len(batch) == 0 {
+		return nil
+	}
+	store, err := leveldbstore.NewLevelDBStore(dbDir)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	return store.CommitBatchData(batch)
+}
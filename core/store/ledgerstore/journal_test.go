@@ -0,0 +1,172 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/imZhuFei/zeepin/common"
+)
+
+//commitTestBlock commit the markers of block at height to all stores of ledger store
+func commitTestBlock(ledgerStore *LedgerStoreImp, height uint32) error {
+	blockHash := common.Uint256{byte(height + 1)}
+	ledgerStore.blockStore.NewBatch()
+	ledgerStore.stateStore.NewBatch()
+	ledgerStore.eventStore.NewBatch()
+	ledgerStore.blockStore.SaveBlockHash(height, blockHash)
+	err := ledgerStore.blockStore.SaveCurrentBlock(height, blockHash)
+	if err != nil {
+		return err
+	}
+	err = ledgerStore.stateStore.AddMerkleTreeRoot(blockHash)
+	if err != nil {
+		return err
+	}
+	err = ledgerStore.stateStore.SaveCurrentBlock(height, blockHash)
+	if err != nil {
+		return err
+	}
+	err = ledgerStore.eventStore.SaveCurrentBlock(height, blockHash)
+	if err != nil {
+		return err
+	}
+	return ledgerStore.commitStores(height, blockHash)
+}
+
+//checkLedgerHeight check all stores of ledger store are at height
+func checkLedgerHeight(ledgerStore *LedgerStoreImp, height uint32) error {
+	_, blockHeight, err := ledgerStore.blockStore.GetCurrentBlock()
+	if err != nil || blockHeight != height {
+		return fmt.Errorf("block store height %d error %v", blockHeight, err)
+	}
+	blockHash, err := ledgerStore.blockStore.GetBlockHash(height)
+	if err != nil || blockHash != (common.Uint256{byte(height + 1)}) {
+		return fmt.Errorf("block hash of height %d error %v", height, err)
+	}
+	_, stateHeight, err := ledgerStore.stateStore.GetCurrentBlock()
+	if err != nil || stateHeight != height {
+		return fmt.Errorf("state store height %d error %v", stateHeight, err)
+	}
+	treeSize, _, err := ledgerStore.stateStore.GetMerkleTree()
+	if err != nil || treeSize != height+1 {
+		return fmt.Errorf("merkle tree size %d error %v", treeSize, err)
+	}
+	_, eventHeight, err := ledgerStore.eventStore.GetCurrentBlock()
+	if err != nil || eventHeight != height {
+		return fmt.Errorf("event store height %d error %v", eventHeight, err)
+	}
+	return nil
+}
+
+func TestCommitJournal(t *testing.T) {
+	defer func() { commitFaultHook = nil }()
+	faultErr := fmt.Errorf("fault injected")
+	testCases := []struct {
+		name   string
+		stage  int
+		height uint32
+		torn   bool
+	}{
+		{"begin", commitStageBegin, 0, false},
+		{"journal", commitStageJournal, 1, false},
+		{"block", commitStageBlock, 1, false},
+		{"state", commitStageState, 1, false},
+		{"event", commitStageEvent, 1, false},
+		{"torn", commitStageJournal, 0, true},
+	}
+	for _, testCase := range testCases {
+		dataDir := fmt.Sprintf("test/journal/%s", testCase.name)
+		ledgerStore, err := NewLedgerStore(dataDir)
+		if err != nil {
+			t.Errorf("%s NewLedgerStore error %s", testCase.name, err)
+			return
+		}
+		commitFaultHook = nil
+		err = commitTestBlock(ledgerStore, 0)
+		if err != nil {
+			t.Errorf("%s commit block 0 error %s", testCase.name, err)
+			return
+		}
+		stage := testCase.stage
+		commitFaultHook = func(s int) error {
+			if s == stage {
+				return faultErr
+			}
+			return nil
+		}
+		err = commitTestBlock(ledgerStore, 1)
+		commitFaultHook = nil
+		if err != faultErr {
+			t.Errorf("%s commit block 1 error %v, expect fault", testCase.name, err)
+			return
+		}
+		ledgerStore.Close()
+		if testCase.torn {
+			info, err := os.Stat(ledgerStore.journal.path)
+			if err != nil {
+				t.Errorf("%s stat journal error %s", testCase.name, err)
+				return
+			}
+			err = os.Truncate(ledgerStore.journal.path, info.Size()/2)
+			if err != nil {
+				t.Errorf("%s truncate journal error %s", testCase.name, err)
+				return
+			}
+		}
+
+		ledgerStore, err = NewLedgerStore(dataDir)
+		if err != nil {
+			t.Errorf("%s reopen NewLedgerStore error %s", testCase.name, err)
+			return
+		}
+		err = checkLedgerHeight(ledgerStore, testCase.height)
+		if err != nil {
+			t.Errorf("%s after fault %s", testCase.name, err)
+			return
+		}
+		_, err = os.Stat(ledgerStore.journal.path)
+		if !os.IsNotExist(err) {
+			t.Errorf("%s journal is not removed after recovery", testCase.name)
+			return
+		}
+		if testCase.height == 0 {
+			err = commitTestBlock(ledgerStore, 1)
+			if err != nil {
+				t.Errorf("%s recommit block 1 error %s", testCase.name, err)
+				return
+			}
+		}
+		ledgerStore.Close()
+
+		ledgerStore, err = NewLedgerStore(dataDir)
+		if err != nil {
+			t.Errorf("%s reopen NewLedgerStore error %s", testCase.name, err)
+			return
+		}
+		err = checkLedgerHeight(ledgerStore, 1)
+		ledgerStore.Close()
+		if err != nil {
+			t.Errorf("%s after recovery %s", testCase.name, err)
+			return
+		}
+	}
+}
@@ -53,12 +53,14 @@ import (
 	"github.com/imZhuFei/zeepin/core/payload"
 	"github.com/imZhuFei/zeepin/core/signature"
 	"github.com/imZhuFei/zeepin/core/states"
+	"github.com/imZhuFei/zeepin/core/store"
 	scom "github.com/imZhuFei/zeepin/core/store/common"
 	"github.com/imZhuFei/zeepin/core/store/statestore"
 	"github.com/imZhuFei/zeepin/core/types"
 	"github.com/imZhuFei/zeepin/errors"
 	"github.com/imZhuFei/zeepin/events"
 	"github.com/imZhuFei/zeepin/events/message"
+	"github.com/imZhuFei/zeepin/merkle"
 	"github.com/imZhuFei/zeepin/smartcontract"
 	scommon "github.com/imZhuFei/zeepin/smartcontract/common"
 	"github.com/imZhuFei/zeepin/smartcontract/context"
@@ -66,8 +68,10 @@ import (
 	"github.com/imZhuFei/zeepin/smartcontract/service/native/embed"
 	"github.com/imZhuFei/zeepin/smartcontract/service/native/global_params"
 	"github.com/imZhuFei/zeepin/smartcontract/service/native/utils"
+	"github.com/imZhuFei/zeepin/smartcontract/service/wasmvm"
 	sstate "github.com/imZhuFei/zeepin/smartcontract/states"
 	"github.com/imZhuFei/zeepin/smartcontract/storage"
+	"github.com/imZhuFei/zeepin/smartcontract/trace"
 	"github.com/ontio/ontology-crypto/keypair"
 )
 
@@ -82,6 +86,7 @@ var (
 	DBDirBlock          = "block"
 	DBDirState          = "states"
 	MerkleTreeStorePath = "merkle_tree.db"
+	JournalFile         = "commit.journal"
 )
 
 //LedgerStoreImp is main store struct fo ledger
@@ -89,6 +94,7 @@ type LedgerStoreImp struct {
 	blockStore         *BlockStore                      //BlockStore for saving block & transaction data
 	stateStore         *StateStore                      //StateStore for saving state data, like balance, smart contract execution result, and so on.
 	eventStore         *EventStore                      //EventStore for saving log those gen after smart contract executed.
+	journal            *journal                         //Write-ahead journal for committing block to all stores
 	storedIndexCount   uint32                           //record the count of have saved block index
 	currBlockHeight    uint32                           //Current block height
 	currBlockHash      common.Uint256                   //Current block hash
@@ -107,6 +113,12 @@ func NewLedgerStore(dataDir string) (*LedgerStoreImp, error) {
 		headerCache:        make(map[common.Uint256]*types.Header, 0),
 		vbftPeerInfoheader: make(map[string]uint32),
 		vbftPeerInfoblock:  make(map[string]uint32),
+		journal:            newJournal(dataDir),
+	}
+
+	err := recoverJournal(dataDir, ledgerStore.journal)
+	if err != nil {
+		return nil, fmt.Errorf("recoverJournal error %s", err)
 	}
 
 	blockStore, err := NewBlockStore(fmt.Sprintf("%s%s%s", dataDir, string(os.PathSeparator), DBDirBlock), true)
@@ -128,6 +140,11 @@ func NewLedgerStore(dataDir string) (*LedgerStoreImp, error) {
 	}
 	ledgerStore.eventStore = eventState
 
+	err = eventState.initAddressIndex(config.DefConfig.Common.EnableAddressIndex, config.DefConfig.Common.EnableEventLog)
+	if err != nil {
+		return nil, fmt.Errorf("initAddressIndex error %s", err)
+	}
+
 	return ledgerStore, nil
 }
 
@@ -503,6 +520,40 @@ func (this *LedgerStoreImp) verifyHeader(header *types.Header, vbftPeerInfo map[
 	return vbftPeerInfo, nil
 }
 
+//verifyStateRoot check the state root of previous block committed in the consensus payload of header
+func (this *LedgerStoreImp) verifyStateRoot(header *types.Header) error {
+	if header.Height == 0 || strings.ToLower(config.DefConfig.Genesis.ConsensusType) != "gbft" {
+		return nil
+	}
+	blkInfo, err := vconfig.VbftBlock(header)
+	if err != nil {
+		return err
+	}
+	//blocks proposed before state root was introduced
+	if len(blkInfo.StateRoot) == 0 {
+		if header.Height >= config.GetStateRootHeight(config.DefConfig.P2PNode.NetworkId) {
+			return fmt.Errorf("state root of height %d is not committed in block", header.Height-1)
+		}
+		//states imported from snapshot are only trusted after the next block commits their root
+		snapshotHeight, err := this.stateStore.GetSnapshotHeight()
+		if err != nil && err != scom.ErrNotFound {
+			return fmt.Errorf("GetSnapshotHeight error %s", err)
+		}
+		if err == nil && snapshotHeight == header.Height-1 {
+			return fmt.Errorf("state root of snapshot height %d is not committed in block", snapshotHeight)
+		}
+		return nil
+	}
+	stateRoot, err := this.stateStore.GetStateRoot(header.Height - 1)
+	if err != nil {
+		return fmt.Errorf("get state root of height %d error %s", header.Height-1, err)
+	}
+	if !bytes.Equal(stateRoot[:], blkInfo.StateRoot) {
+		return fmt.Errorf("state root of height %d is %s, not %x", header.Height-1, stateRoot.ToHexString(), blkInfo.StateRoot)
+	}
+	return nil
+}
+
 //AddHeader add header to cache, and add the mapping of block height to block hash. Using in block sync
 func (this *LedgerStoreImp) AddHeader(header *types.Header) error {
 	nextHeaderHeight := this.GetCurrentHeaderHeight() + 1
@@ -551,6 +602,10 @@ func (this *LedgerStoreImp) AddBlock(block *types.Block) error {
 	if err != nil {
 		return fmt.Errorf("verifyHeader error %s", err)
 	}
+	err = this.verifyStateRoot(block.Header)
+	if err != nil {
+		return fmt.Errorf("verifyStateRoot error %s", err)
+	}
 
 	err = this.saveBlock(block)
 	if err != nil {
@@ -611,6 +666,18 @@ func (this *LedgerStoreImp) saveBlockToStateStore(block *types.Block) error {
 		return fmt.Errorf("AddMerkleTreeRoot error %s", err)
 	}
 
+	err = this.stateStore.AddStateRoot(blockHeight, stateBatch)
+	if err != nil {
+		return fmt.Errorf("AddStateRoot error %s", err)
+	}
+
+	if config.DefConfig.Common.EnableArchive {
+		err = this.stateStore.AddStateHistory(blockHeight, stateBatch)
+		if err != nil {
+			return fmt.Errorf("AddStateHistory error %s", err)
+		}
+	}
+
 	err = this.stateStore.SaveCurrentBlock(blockHeight, blockHash)
 	if err != nil {
 		return fmt.Errorf("SaveCurrentBlock error %s", err)
@@ -688,17 +755,15 @@ func (this *LedgerStoreImp) saveBlock(block *types.Block) error {
 	if err != nil {
 		return fmt.Errorf("save to event store height:%d error:%s", blockHeight, err)
 	}
-	err = this.blockStore.CommitTo()
-	if err != nil {
-		return fmt.Errorf("blockStore.CommitTo height:%d error %s", blockHeight, err)
-	}
-	err = this.stateStore.CommitTo()
-	if err != nil {
-		return fmt.Errorf("stateStore.CommitTo height:%d error %s", blockHeight, err)
+	if pruneBlocks := config.DefConfig.Common.PruneBlocks; pruneBlocks > 0 {
+		err = this.pruneBlocks(blockHeight, pruneBlocks)
+		if err != nil {
+			return fmt.Errorf("prune blocks height:%d error:%s", blockHeight, err)
+		}
 	}
-	err = this.eventStore.CommitTo()
+	err = this.commitStores(blockHeight, blockHash)
 	if err != nil {
-		return fmt.Errorf("eventStore.CommitTo height:%d error %s", blockHeight, err)
+		return fmt.Errorf("commit stores height:%d error %s", blockHeight, err)
 	}
 	this.setCurrentBlock(blockHeight, blockHash)
 
@@ -735,6 +800,11 @@ func (this *LedgerStoreImp) handleTransaction(stateBatch *statestore.StateBatch,
 		}
 		SaveNotify(this.eventStore, txHash, notify)
 	}
+	if config.DefConfig.Common.EnableAddressIndex {
+		if err := this.eventStore.SaveAddressIndex(block.Header.Height, tx, notify); err != nil {
+			return fmt.Errorf("SaveAddressIndex tx %s error %s", txHash.ToHexString(), err)
+		}
+	}
 	return nil
 }
 
@@ -839,6 +909,16 @@ func (this *LedgerStoreImp) GetMerkleProof(proofHeight, rootHeight uint32) ([]co
 	return this.stateStore.GetMerkleProof(proofHeight, rootHeight)
 }
 
+//GetStateRoot return the state root after the block of height is executed
+func (this *LedgerStoreImp) GetStateRoot(height uint32) (common.Uint256, error) {
+	return this.stateStore.GetStateRoot(height)
+}
+
+//GetStorageProof return the storage value after the block of height is executed, with the proof against the state root of height
+func (this *LedgerStoreImp) GetStorageProof(key *states.StorageKey, height uint32) ([]byte, *merkle.SparseMerkleProof, error) {
+	return this.stateStore.GetStorageProof(key, height)
+}
+
 //GetContractState return contract by contract address. Wrap function of StateStore.GetContractState
 func (this *LedgerStoreImp) GetContractState(contractHash common.Address) (*payload.DeployCode, error) {
 	return this.stateStore.GetContractState(contractHash)
@@ -849,6 +929,36 @@ func (this *LedgerStoreImp) GetStorageItem(key *states.StorageKey) (*states.Stor
 	return this.stateStore.GetStorageState(key)
 }
 
+//GetStorageItemAt return the storage value of the key after the block of height is executed.
+//Heights below current block height need archive mode
+func (this *LedgerStoreImp) GetStorageItemAt(key *states.StorageKey, height uint32) (*states.StorageItem, error) {
+	currentHeight := this.GetCurrentBlockHeight()
+	if height > currentHeight {
+		return nil, fmt.Errorf("height %d is above current block height %d", height, currentHeight)
+	}
+	if height == currentHeight {
+		return this.stateStore.GetStorageState(key)
+	}
+	return this.stateStore.GetStorageStateAt(key, height)
+}
+
+//getStateBatchAt return the state batch of current states, or the read only state batch of history states
+func (this *LedgerStoreImp) getStateBatchAt(height uint32) (*statestore.StateBatch, error) {
+	currentHeight := this.GetCurrentBlockHeight()
+	if height > currentHeight {
+		return nil, fmt.Errorf("height %d is above current block height %d", height, currentHeight)
+	}
+	if height == currentHeight {
+		return this.stateStore.NewStateBatch(), nil
+	}
+	return this.stateStore.NewHistoryStateBatch(height)
+}
+
+//GetEventSchemas return the event schemas registered by contract. Wrap function of StateStore.GetEventSchemas
+func (this *LedgerStoreImp) GetEventSchemas(contractHash common.Address) (*states.EventSchemaState, error) {
+	return this.stateStore.GetEventSchemas(contractHash)
+}
+
 //GetEventNotifyByTx return the events notify gen by executing of smart contract.  Wrap function of EventStore.GetEventNotifyByTx
 func (this *LedgerStoreImp) GetEventNotifyByTx(tx common.Uint256) (*event.ExecuteNotify, error) {
 	return this.eventStore.GetEventNotifyByTx(tx)
@@ -859,9 +969,58 @@ func (this *LedgerStoreImp) GetEventNotifyByBlock(height uint32) ([]*event.Execu
 	return this.eventStore.GetEventNotifyByBlock(height)
 }
 
+//GetTransactionsByAddress return the transactions of address, newest first. Wrap function of EventStore.GetTransactionsByAddress
+func (this *LedgerStoreImp) GetTransactionsByAddress(addr common.Address, start []byte, limit uint32) ([]*scom.AddressTx, []byte, error) {
+	if !config.DefConfig.Common.EnableAddressIndex {
+		return nil, nil, fmt.Errorf("address index is disabled")
+	}
+	return this.eventStore.GetTransactionsByAddress(addr, start, limit)
+}
+
+//GetTransfersByAddress return the zpt and gala transfers of address, newest first. Wrap function of EventStore.GetTransfersByAddress
+func (this *LedgerStoreImp) GetTransfersByAddress(addr common.Address, start []byte, limit uint32) ([]*scom.AddressTransfer, []byte, error) {
+	if !config.DefConfig.Common.EnableAddressIndex {
+		return nil, nil, fmt.Errorf("address index is disabled")
+	}
+	return this.eventStore.GetTransfersByAddress(addr, start, limit)
+}
+
 //PreExecuteContract return the result of smart contract execution without commit to store
 func (this *LedgerStoreImp) PreExecuteContract(tx *types.Transaction) (*sstate.PreExecResult, error) {
-	header, err := this.GetHeaderByHeight(this.GetCurrentBlockHeight())
+	return this.preExecuteContract(tx, this.GetCurrentBlockHeight(), nil)
+}
+
+//PreExecuteContractAt return the result of smart contract execution against the states after the block of height is executed.
+//Heights below current block height need archive mode
+func (this *LedgerStoreImp) PreExecuteContractAt(tx *types.Transaction, height uint32) (*sstate.PreExecResult, error) {
+	return this.preExecuteContract(tx, height, nil)
+}
+
+//TraceContract pre-execute the invoke transaction and return its execution steps, at most maxSteps steps are recorded
+func (this *LedgerStoreImp) TraceContract(tx *types.Transaction, maxSteps int) (*trace.Trace, error) {
+	if tx.TxType != types.Invoke {
+		return nil, errors.NewErr("only invoke transaction can be traced")
+	}
+	tracer := trace.NewTracer(maxSteps)
+	result, err := this.preExecuteContract(tx, this.GetCurrentBlockHeight(), tracer)
+	t := tracer.Trace()
+	if result != nil {
+		t.State = result.State
+		t.Gas = result.Gas
+		t.Result = result.Result
+	}
+	if err != nil {
+		t.Error = err.Error()
+	}
+	return t, nil
+}
+
+func (this *LedgerStoreImp) preExecuteContract(tx *types.Transaction, height uint32, tracer *trace.Tracer) (*sstate.PreExecResult, error) {
+	stateBatch, err := this.getStateBatchAt(height)
+	if err != nil {
+		return &sstate.PreExecResult{State: event.CONTRACT_STATE_FAIL, Gas: embed.MIN_TRANSACTION_GAS, Result: nil}, err
+	}
+	header, err := this.GetHeaderByHeight(height)
 	if err != nil {
 		return &sstate.PreExecResult{State: event.CONTRACT_STATE_FAIL, Gas: embed.MIN_TRANSACTION_GAS, Result: nil}, err
 	}
@@ -872,8 +1031,14 @@ func (this *LedgerStoreImp) PreExecuteContract(tx *types.Transaction) (*sstate.P
 		Tx:     tx,
 	}
 
-	cache := storage.NewCloneCache(this.stateStore.NewStateBatch())
-	preGas, err := this.getPreGas(config, cache)
+	//history states are pre-executed against the view of ledger at height
+	var ledger store.LedgerStore = this
+	if height < this.GetCurrentBlockHeight() {
+		ledger = newHistoryLedgerStore(this, height)
+	}
+
+	cache := storage.NewCloneCache(stateBatch)
+	preGas, err := this.getPreGas(config, cache, ledger)
 	if err != nil {
 		return &sstate.PreExecResult{State: event.CONTRACT_STATE_FAIL, Gas: embed.MIN_TRANSACTION_GAS, Result: nil}, err
 	}
@@ -883,9 +1048,10 @@ func (this *LedgerStoreImp) PreExecuteContract(tx *types.Transaction) (*sstate.P
 
 		sc := smartcontract.SmartContract{
 			Config:     config,
-			Store:      this,
+			Store:      ledger,
 			CloneCache: cache,
 			Gas:        math.MaxUint64 - calcGasByCodeLen(len(invoke.Code), preGas[embed.UINT_INVOKE_CODE_LEN_NAME]),
+			Tracer:     tracer,
 		}
 
 		//start the smart contract executive function
@@ -914,13 +1080,16 @@ func (this *LedgerStoreImp) PreExecuteContract(tx *types.Transaction) (*sstate.P
 		return &sstate.PreExecResult{State: event.CONTRACT_STATE_SUCCESS, Gas: gasCost, Result: result}, nil
 	} else if tx.TxType == types.Deploy {
 		deploy := tx.Payload.(*payload.DeployCode)
+		if err := wasmvm.VerifyCode(deploy.Code, config.Height); err != nil {
+			return &sstate.PreExecResult{State: event.CONTRACT_STATE_FAIL, Gas: embed.MIN_TRANSACTION_GAS, Result: nil}, err
+		}
 		return &sstate.PreExecResult{State: event.CONTRACT_STATE_SUCCESS, Gas: preGas[embed.CONTRACT_CREATE_NAME] + calcGasByCodeLen(len(deploy.Code), preGas[embed.UINT_DEPLOY_CODE_LEN_NAME]), Result: nil}, nil
 	} else {
 		return &sstate.PreExecResult{State: event.CONTRACT_STATE_FAIL, Gas: embed.MIN_TRANSACTION_GAS, Result: nil}, errors.NewErr("transaction type error")
 	}
 }
 
-func (this *LedgerStoreImp) getPreGas(config *smartcontract.Config, cache *storage.CloneCache) (map[string]uint64, error) {
+func (this *LedgerStoreImp) getPreGas(config *smartcontract.Config, cache *storage.CloneCache, ledger store.LedgerStore) (map[string]uint64, error) {
 	bf := new(bytes.Buffer)
 	names := []string{embed.CONTRACT_CREATE_NAME, embed.UINT_INVOKE_CODE_LEN_NAME, embed.UINT_DEPLOY_CODE_LEN_NAME}
 	if err := utils.WriteVarUint(bf, uint64(len(names))); err != nil {
@@ -936,7 +1105,7 @@ func (this *LedgerStoreImp) getPreGas(config *smartcontract.Config, cache *stora
 	sc := smartcontract.SmartContract{
 		Config:     config,
 		CloneCache: cache,
-		Store:      this,
+		Store:      ledger,
 		Gas:        math.MaxUint64,
 	}
 
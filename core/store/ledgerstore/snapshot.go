@@ -0,0 +1,628 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/common/config"
+	"github.com/imZhuFei/zeepin/common/log"
+	"github.com/imZhuFei/zeepin/common/serialization"
+	scom "github.com/imZhuFei/zeepin/core/store/common"
+	"github.com/imZhuFei/zeepin/core/store/leveldbstore"
+	"github.com/imZhuFei/zeepin/core/types"
+	"github.com/imZhuFei/zeepin/merkle"
+)
+
+//A snapshot file is laid out as
+//  magic, version, height, block hash and state root of the block snapshot taken at
+//  size and hashes of block merkle tree, count of hashes in merkle tree store and the hashes
+//  header records of blocks from genesis to height
+//  count of blocks with body and the blocks, which are genesis block, config blocks and the last MIN_KEEP_BLOCKS blocks
+//  state entries as key and value, ended by an empty key
+//  sha256 checksum of all above
+//State history, state merkle tree and state roots are not saved. The state merkle tree is rebuilt from storage
+//when the snapshot is imported, and checked against the state root of snapshot.
+//Nothing in the file is trusted by itself. The block hash of snapshot must match a block hash got from a trusted
+//source, which authenticates the header chain. The state root is committed by the signed header of next block,
+//so the first block synced after import must carry the state root, see LedgerStoreImp.verifyStateRoot.
+
+const (
+	SNAPSHOT_VERSION    = byte(1) //Version of snapshot file
+	SNAPSHOT_BATCH_SIZE = 10000   //Count of records committed in one batch when importing snapshot
+)
+
+var (
+	SNAPSHOT_MAGIC = []byte("ZPTSNAP") //Magic of snapshot file
+
+	//ErrLedgerExisted is returned when importing snapshot into a data dir which already has ledger
+	ErrLedgerExisted = errors.New("ledger already exists")
+
+	//State entries saved in snapshot and kept in state history
+	statePrefixes = []scom.DataEntryPrefix{
+		scom.ST_BOOKKEEPER,
+		scom.ST_CONTRACT,
+		scom.ST_STORAGE,
+		scom.ST_VOTE,
+		scom.ST_EVENT_SCHEMA,
+	}
+)
+
+//SnapshotInfo is the block which snapshot is taken at
+type SnapshotInfo struct {
+	Height    uint32
+	BlockHash common.Uint256
+	StateRoot common.Uint256
+}
+
+func (this *SnapshotInfo) Serialize(w io.Writer) error {
+	_, err := w.Write(SNAPSHOT_MAGIC)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write([]byte{SNAPSHOT_VERSION})
+	if err != nil {
+		return err
+	}
+	err = serialization.WriteUint32(w, this.Height)
+	if err != nil {
+		return err
+	}
+	err = this.BlockHash.Serialize(w)
+	if err != nil {
+		return err
+	}
+	return this.StateRoot.Serialize(w)
+}
+
+func (this *SnapshotInfo) Deserialize(r io.Reader) error {
+	magic := make([]byte, len(SNAPSHOT_MAGIC)+1)
+	_, err := io.ReadFull(r, magic)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(magic[:len(SNAPSHOT_MAGIC)], SNAPSHOT_MAGIC) {
+		return fmt.Errorf("not a snapshot file")
+	}
+	if magic[len(SNAPSHOT_MAGIC)] != SNAPSHOT_VERSION {
+		return fmt.Errorf("unsupported snapshot version %d", magic[len(SNAPSHOT_MAGIC)])
+	}
+	this.Height, err = serialization.ReadUint32(r)
+	if err != nil {
+		return err
+	}
+	err = this.BlockHash.Deserialize(r)
+	if err != nil {
+		return err
+	}
+	return this.StateRoot.Deserialize(r)
+}
+
+//WriteSnapshot write the snapshot of ledger in dataDir at current block to w. The node of dataDir should be stopped
+func WriteSnapshot(dataDir string, w io.Writer) (*SnapshotInfo, error) {
+	blockStore, err := NewBlockStore(fmt.Sprintf("%s%s%s", dataDir, string(os.PathSeparator), DBDirBlock), false)
+	if err != nil {
+		return nil, fmt.Errorf("NewBlockStore error %s", err)
+	}
+	defer blockStore.Close()
+	store, err := leveldbstore.NewLevelDBStore(fmt.Sprintf("%s%s%s", dataDir, string(os.PathSeparator), DBDirState))
+	if err != nil {
+		return nil, fmt.Errorf("NewLevelDBStore error %s", err)
+	}
+	stateStore := &StateStore{store: store}
+	defer stateStore.Close()
+
+	blockHash, height, err := blockStore.GetCurrentBlock()
+	if err != nil {
+		return nil, fmt.Errorf("blockStore.GetCurrentBlock error %s", err)
+	}
+	stateHash, stateHeight, err := stateStore.GetCurrentBlock()
+	if err != nil {
+		return nil, fmt.Errorf("stateStore.GetCurrentBlock error %s", err)
+	}
+	if stateHeight != height || stateHash != blockHash {
+		return nil, fmt.Errorf("state store at height %d is behind block store at height %d, start the node to catch up first", stateHeight, height)
+	}
+	stateRoot, err := stateStore.GetStateRoot(height)
+	if err != nil {
+		return nil, fmt.Errorf("GetStateRoot height %d error %s", height, err)
+	}
+	treeSize, treeHashes, err := stateStore.GetMerkleTree()
+	if err != nil {
+		return nil, fmt.Errorf("GetMerkleTree error %s", err)
+	}
+	if treeSize != height+1 {
+		return nil, fmt.Errorf("merkle tree size %d is inconsistent with block height %d", treeSize, height)
+	}
+	hashStore, err := merkle.NewFileHashStore(fmt.Sprintf("%s%s%s", dataDir, string(os.PathSeparator), MerkleTreeStorePath), treeSize)
+	if err != nil {
+		return nil, fmt.Errorf("NewFileHashStore error %s", err)
+	}
+	defer hashStore.Close()
+
+	info := &SnapshotInfo{
+		Height:    height,
+		BlockHash: blockHash,
+		StateRoot: stateRoot,
+	}
+	hasher := sha256.New()
+	writer := bufio.NewWriter(io.MultiWriter(w, hasher))
+	err = info.Serialize(writer)
+	if err != nil {
+		return nil, err
+	}
+
+	serialization.WriteUint32(writer, treeSize)
+	serialization.WriteUint32(writer, uint32(len(treeHashes)))
+	for _, hash := range treeHashes {
+		hash.Serialize(writer)
+	}
+	hashNum := merkle.GetStoredHashNum(treeSize)
+	serialization.WriteUint64(writer, uint64(hashNum))
+	for pos := int64(0); pos < hashNum; pos++ {
+		hash, err := hashStore.GetHash(uint32(pos))
+		if err != nil {
+			return nil, fmt.Errorf("merkle tree store GetHash %d error %s", pos, err)
+		}
+		err = hash.Serialize(writer)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	bodyHeights := make([]uint32, 0)
+	for i := uint32(0); i <= height; i++ {
+		hash, err := blockStore.GetBlockHash(i)
+		if err != nil {
+			return nil, fmt.Errorf("GetBlockHash height %d error %s", i, err)
+		}
+		record, err := blockStore.store.Get(blockStore.getHeaderKey(hash))
+		if err != nil {
+			return nil, fmt.Errorf("get header height %d error %s", i, err)
+		}
+		header, err := getHeaderOfRecord(record)
+		if err != nil {
+			return nil, fmt.Errorf("header height %d deserialize error %s", i, err)
+		}
+		if isKeptBlock(header) || height-i < MIN_KEEP_BLOCKS {
+			bodyHeights = append(bodyHeights, i)
+		}
+		err = serialization.WriteVarBytes(writer, record)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = serialization.WriteUint32(writer, uint32(len(bodyHeights)))
+	if err != nil {
+		return nil, err
+	}
+	for _, i := range bodyHeights {
+		hash, err := blockStore.GetBlockHash(i)
+		if err != nil {
+			return nil, fmt.Errorf("GetBlockHash height %d error %s", i, err)
+		}
+		block, err := blockStore.GetBlock(hash)
+		if err != nil {
+			return nil, fmt.Errorf("GetBlock height %d error %s", i, err)
+		}
+		err = block.Serialize(writer)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, prefix := range statePrefixes {
+		iter := store.NewIterator([]byte{byte(prefix)})
+		for iter.Next() {
+			serialization.WriteVarBytes(writer, iter.Key())
+			err = serialization.WriteVarBytes(writer, iter.Value())
+			if err != nil {
+				iter.Release()
+				return nil, err
+			}
+		}
+		iter.Release()
+	}
+	err = serialization.WriteVarBytes(writer, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	err = writer.Flush()
+	if err != nil {
+		return nil, err
+	}
+	_, err = w.Write(hasher.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+//ImportSnapshot import the snapshot read from r into the empty ledger in dataDir. The snapshot must be taken at the
+//trusted block of blockHash. Headers of all blocks are imported, while bodies are only imported for the blocks saved
+//in snapshot, so the ledger looks like a pruned one. Then the ledger is opened by NewLedgerStore and syncs blocks
+//from the height of snapshot, where the signed state root of next block verifies the imported states.
+func ImportSnapshot(dataDir string, r io.Reader, blockHash common.Uint256) (*SnapshotInfo, error) {
+	if strings.ToLower(config.DefConfig.Genesis.ConsensusType) != config.CONSENSUS_TYPE_VBFT {
+		return nil, fmt.Errorf("state root of snapshot cannot be verified with %s consensus", config.DefConfig.Genesis.ConsensusType)
+	}
+	blockStore, err := NewBlockStore(fmt.Sprintf("%s%s%s", dataDir, string(os.PathSeparator), DBDirBlock), false)
+	if err != nil {
+		return nil, fmt.Errorf("NewBlockStore error %s", err)
+	}
+	defer blockStore.Close()
+	_, err = blockStore.GetVersion()
+	if err == nil {
+		return nil, ErrLedgerExisted
+	}
+	if err != scom.ErrNotFound {
+		return nil, fmt.Errorf("GetVersion error %s", err)
+	}
+
+	info, err := importSnapshot(dataDir, blockStore, r, blockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	stateDir := fmt.Sprintf("%s%s%s", dataDir, string(os.PathSeparator), DBDirState)
+	stateStore, err := NewStateStore(stateDir, fmt.Sprintf("%s%s%s", dataDir, string(os.PathSeparator), MerkleTreeStorePath))
+	if err != nil {
+		return nil, fmt.Errorf("NewStateStore error %s", err)
+	}
+	stateRoot, err := stateStore.GetStateRoot(info.Height)
+	stateStore.Close()
+	if err != nil {
+		return nil, fmt.Errorf("GetStateRoot error %s", err)
+	}
+	if stateRoot != info.StateRoot {
+		return nil, fmt.Errorf("state root %s is not the one %s of snapshot", stateRoot.ToHexString(), info.StateRoot.ToHexString())
+	}
+
+	eventStore, err := NewEventStore(fmt.Sprintf("%s%s%s", dataDir, string(os.PathSeparator), DBDirEvent))
+	if err != nil {
+		return nil, fmt.Errorf("NewEventStore error %s", err)
+	}
+	defer eventStore.Close()
+	err = eventStore.ClearAll()
+	if err != nil {
+		return nil, fmt.Errorf("eventStore.ClearAll error %s", err)
+	}
+	eventStore.NewBatch()
+	eventStore.SaveCurrentBlock(info.Height, info.BlockHash)
+	err = eventStore.CommitTo()
+	if err != nil {
+		return nil, fmt.Errorf("eventStore.CommitTo error %s", err)
+	}
+
+	//version is saved at last, so an interrupted import is cleared by next import or genesis block init
+	err = blockStore.SaveVersion(SYSTEM_VERSION)
+	if err != nil {
+		return nil, fmt.Errorf("SaveVersion error %s", err)
+	}
+	return info, nil
+}
+
+//importSnapshot write the blocks, block merkle tree and states of snapshot to stores and check them,
+//the state merkle tree is left to be built by NewStateStore
+func importSnapshot(dataDir string, blockStore *BlockStore, r io.Reader, trustedHash common.Uint256) (*SnapshotInfo, error) {
+	err := blockStore.ClearAll()
+	if err != nil {
+		return nil, fmt.Errorf("blockStore.ClearAll error %s", err)
+	}
+	store, err := leveldbstore.NewLevelDBStore(fmt.Sprintf("%s%s%s", dataDir, string(os.PathSeparator), DBDirState))
+	if err != nil {
+		return nil, fmt.Errorf("NewLevelDBStore error %s", err)
+	}
+	stateStore := &StateStore{store: store}
+	defer stateStore.Close()
+	err = stateStore.ClearAll()
+	if err != nil {
+		return nil, fmt.Errorf("stateStore.ClearAll error %s", err)
+	}
+	merklePath := fmt.Sprintf("%s%s%s", dataDir, string(os.PathSeparator), MerkleTreeStorePath)
+	err = os.Remove(merklePath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("remove merkle tree store error %s", err)
+	}
+	hashStore, err := merkle.NewFileHashStore(merklePath, 0)
+	if err != nil {
+		return nil, fmt.Errorf("NewFileHashStore error %s", err)
+	}
+	defer hashStore.Close()
+
+	hasher := sha256.New()
+	fileReader := bufio.NewReader(r)
+	reader := io.TeeReader(fileReader, hasher)
+	info := &SnapshotInfo{}
+	err = info.Deserialize(reader)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot info deserialize error %s", err)
+	}
+	if info.BlockHash != trustedHash {
+		return nil, fmt.Errorf("block hash %s of snapshot is not the trusted one %s", info.BlockHash.ToHexString(), trustedHash.ToHexString())
+	}
+	log.Infof("import snapshot at height %d block hash %s", info.Height, info.BlockHash.ToHexString())
+
+	treeSize, err := serialization.ReadUint32(reader)
+	if err != nil {
+		return nil, fmt.Errorf("read block merkle tree error %s", err)
+	}
+	treeHashNum, err := serialization.ReadUint32(reader)
+	if err != nil {
+		return nil, fmt.Errorf("read block merkle tree error %s", err)
+	}
+	if treeHashNum > 32 {
+		return nil, fmt.Errorf("count %d of block merkle tree hashes is invalid", treeHashNum)
+	}
+	treeHashes, err := readHashes(reader, uint64(treeHashNum))
+	if err != nil {
+		return nil, fmt.Errorf("read block merkle tree error %s", err)
+	}
+	if treeSize != info.Height+1 {
+		return nil, fmt.Errorf("merkle tree size %d is inconsistent with block height %d", treeSize, info.Height)
+	}
+	hashNum, err := serialization.ReadUint64(reader)
+	if err != nil {
+		return nil, fmt.Errorf("read merkle tree store error %s", err)
+	}
+	if int64(hashNum) != merkle.GetStoredHashNum(treeSize) {
+		return nil, fmt.Errorf("count %d of merkle tree store is inconsistent with merkle tree size %d", hashNum, treeSize)
+	}
+	for hashNum > 0 {
+		count := hashNum
+		if count > SNAPSHOT_BATCH_SIZE {
+			count = SNAPSHOT_BATCH_SIZE
+		}
+		hashes, err := readHashes(reader, count)
+		if err != nil {
+			return nil, fmt.Errorf("read merkle tree store error %s", err)
+		}
+		err = hashStore.Append(hashes)
+		if err != nil {
+			return nil, fmt.Errorf("merkle tree store Append error %s", err)
+		}
+		hashNum -= count
+	}
+	err = hashStore.Flush()
+	if err != nil {
+		return nil, fmt.Errorf("merkle tree store Flush error %s", err)
+	}
+
+	//block merkle tree is rebuilt from transaction roots of headers, checking the block roots of headers and merkle tree store
+	checkedStore := &checkedHashStore{HashStore: hashStore}
+	merkleTree := merkle.NewTree(0, nil, checkedStore)
+	blockHashes := make([]common.Uint256, 0, info.Height+1)
+	blockStore.NewBatch()
+	for height := uint32(0); height <= info.Height; height++ {
+		record, err := serialization.ReadVarBytes(reader)
+		if err != nil {
+			return nil, fmt.Errorf("read header height %d error %s", height, err)
+		}
+		header, err := getHeaderOfRecord(record)
+		if err != nil {
+			return nil, fmt.Errorf("header height %d deserialize error %s", height, err)
+		}
+		if header.Height != height {
+			return nil, fmt.Errorf("header height %d is not in order", header.Height)
+		}
+		if height > 0 && header.PrevBlockHash != blockHashes[height-1] {
+			return nil, fmt.Errorf("header height %d prev block hash mismatch", height)
+		}
+		merkleTree.AppendHash(header.TransactionsRoot)
+		if checkedStore.err != nil {
+			return nil, checkedStore.err
+		}
+		if height > 0 && header.BlockRoot != merkleTree.Root() {
+			return nil, fmt.Errorf("header height %d block root mismatch", height)
+		}
+		blockHash := header.Hash()
+		blockStore.store.BatchPut(blockStore.getHeaderKey(blockHash), record)
+		blockStore.SaveBlockHash(height, blockHash)
+		blockHashes = append(blockHashes, blockHash)
+		if (height+1)%SNAPSHOT_BATCH_SIZE == 0 {
+			err = blockStore.CommitTo()
+			if err != nil {
+				return nil, fmt.Errorf("blockStore.CommitTo error %s", err)
+			}
+			blockStore.NewBatch()
+		}
+	}
+	if blockHashes[info.Height] != info.BlockHash {
+		return nil, fmt.Errorf("header height %d is not the block of snapshot", info.Height)
+	}
+	if !isSameHashes(merkleTree.Hashes(), treeHashes) {
+		return nil, fmt.Errorf("block merkle tree mismatch")
+	}
+
+	blockCount, err := serialization.ReadUint32(reader)
+	if err != nil {
+		return nil, fmt.Errorf("read block count error %s", err)
+	}
+	for i := uint32(0); i < blockCount; i++ {
+		block := &types.Block{}
+		err = block.Deserialize(reader)
+		if err != nil {
+			return nil, fmt.Errorf("block deserialize error %s", err)
+		}
+		height := block.Header.Height
+		if height > info.Height || block.Hash() != blockHashes[height] {
+			return nil, fmt.Errorf("block height %d is not in chain", height)
+		}
+		txHashes := make([]common.Uint256, 0, len(block.Transactions))
+		for _, tx := range block.Transactions {
+			txHashes = append(txHashes, tx.Hash())
+		}
+		if common.ComputeMerkleRoot(txHashes) != block.Header.TransactionsRoot {
+			return nil, fmt.Errorf("block height %d transactions root mismatch", height)
+		}
+		for _, tx := range block.Transactions {
+			err = blockStore.SaveTransaction(tx, height)
+			if err != nil {
+				return nil, fmt.Errorf("SaveTransaction height %d error %s", height, err)
+			}
+		}
+	}
+
+	store.NewBatch()
+	entryCount := 0
+	for {
+		key, err := serialization.ReadVarBytes(reader)
+		if err != nil {
+			return nil, fmt.Errorf("read state key error %s", err)
+		}
+		if len(key) == 0 {
+			break
+		}
+		if !isSnapshotStateKey(key) {
+			return nil, fmt.Errorf("state key %x is not allowed in snapshot", key)
+		}
+		value, err := serialization.ReadVarBytes(reader)
+		if err != nil {
+			return nil, fmt.Errorf("read state value error %s", err)
+		}
+		store.BatchPut(key, value)
+		entryCount++
+		if entryCount%SNAPSHOT_BATCH_SIZE == 0 {
+			err = store.BatchCommit()
+			if err != nil {
+				return nil, fmt.Errorf("stateStore.CommitTo error %s", err)
+			}
+			store.NewBatch()
+		}
+	}
+
+	checksum := make([]byte, sha256.Size)
+	_, err = io.ReadFull(fileReader, checksum)
+	if err != nil {
+		return nil, fmt.Errorf("read checksum error %s", err)
+	}
+	if !bytes.Equal(checksum, hasher.Sum(nil)) {
+		return nil, fmt.Errorf("checksum of snapshot mismatch")
+	}
+
+	err = stateStore.saveMerkleTree(merkleTree)
+	if err != nil {
+		return nil, fmt.Errorf("saveMerkleTree error %s", err)
+	}
+	stateStore.SaveCurrentBlock(info.Height, info.BlockHash)
+	stateStore.SaveSnapshotHeight(info.Height)
+	err = store.BatchCommit()
+	if err != nil {
+		return nil, fmt.Errorf("stateStore.CommitTo error %s", err)
+	}
+
+	for start := uint32(0); info.Height-start >= HEADER_INDEX_BATCH_SIZE; start += HEADER_INDEX_BATCH_SIZE {
+		err = blockStore.SaveHeaderIndexList(start, blockHashes[start:start+HEADER_INDEX_BATCH_SIZE])
+		if err != nil {
+			return nil, fmt.Errorf("SaveHeaderIndexList start %d error %s", start, err)
+		}
+	}
+	blockStore.SaveCurrentBlock(info.Height, info.BlockHash)
+	if info.Height >= MIN_KEEP_BLOCKS {
+		blockStore.SavePrunedHeight(info.Height - MIN_KEEP_BLOCKS + 1)
+	}
+	err = blockStore.CommitTo()
+	if err != nil {
+		return nil, fmt.Errorf("blockStore.CommitTo error %s", err)
+	}
+	return info, nil
+}
+
+//checkedHashStore check the hashes appended by block merkle tree against the hashes already in merkle tree store
+type checkedHashStore struct {
+	merkle.HashStore
+	pos uint32
+	err error
+}
+
+func (self *checkedHashStore) Append(hashes []common.Uint256) error {
+	for _, hash := range hashes {
+		stored, err := self.HashStore.GetHash(self.pos)
+		if err == nil && stored != hash {
+			err = fmt.Errorf("hash %d of merkle tree store mismatch", self.pos)
+		}
+		if err != nil && self.err == nil {
+			self.err = err
+		}
+		self.pos++
+	}
+	return self.err
+}
+
+func (self *checkedHashStore) Flush() error {
+	return nil
+}
+
+//getHeaderOfRecord return the header in the header record of block store
+func getHeaderOfRecord(record []byte) (*types.Header, error) {
+	reader := bytes.NewReader(record)
+	sysFee := new(common.Fixed64)
+	err := sysFee.Deserialize(reader)
+	if err != nil {
+		return nil, err
+	}
+	header := new(types.Header)
+	err = header.Deserialize(reader)
+	if err != nil {
+		return nil, err
+	}
+	return header, nil
+}
+
+func isSnapshotStateKey(key []byte) bool {
+	for _, prefix := range statePrefixes {
+		if key[0] == byte(prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func readHashes(r io.Reader, count uint64) ([]common.Uint256, error) {
+	hashes := make([]common.Uint256, count)
+	for i := range hashes {
+		err := hashes[i].Deserialize(r)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return hashes, nil
+}
+
+func isSameHashes(a, b []common.Uint256) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,258 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/imZhuFei/zeepin/common"
+	vconfig "github.com/imZhuFei/zeepin/consensus/vbft/config"
+	"github.com/imZhuFei/zeepin/core/payload"
+	"github.com/imZhuFei/zeepin/core/states"
+	scom "github.com/imZhuFei/zeepin/core/store/common"
+	"github.com/imZhuFei/zeepin/core/types"
+	"github.com/imZhuFei/zeepin/smartcontract/event"
+)
+
+//saveTestChain save blocks of height 0 to count-1 to stores in dataDir, each block with one transaction
+//which writes a storage and has an event notify
+func saveTestChain(dataDir string, count uint32) ([]*types.Block, error) {
+	blockStore, err := NewBlockStore(fmt.Sprintf("%s%s%s", dataDir, string(os.PathSeparator), DBDirBlock), false)
+	if err != nil {
+		return nil, err
+	}
+	defer blockStore.Close()
+	stateStore, err := NewStateStore(fmt.Sprintf("%s%s%s", dataDir, string(os.PathSeparator), DBDirState),
+		fmt.Sprintf("%s%s%s", dataDir, string(os.PathSeparator), MerkleTreeStorePath))
+	if err != nil {
+		return nil, err
+	}
+	defer stateStore.Close()
+	eventStore, err := NewEventStore(fmt.Sprintf("%s%s%s", dataDir, string(os.PathSeparator), DBDirEvent))
+	if err != nil {
+		return nil, err
+	}
+	defer eventStore.Close()
+
+	blocks := make([]*types.Block, 0, count)
+	prevHash := common.Uint256{}
+	for height := uint32(0); height < count; height++ {
+		tx := &types.Transaction{
+			TxType:  types.Invoke,
+			Nonce:   height,
+			Payload: &payload.InvokeCode{},
+		}
+		txHash := tx.Hash()
+		txRoot := common.ComputeMerkleRoot([]common.Uint256{txHash})
+		header := &types.Header{
+			PrevBlockHash:    prevHash,
+			TransactionsRoot: txRoot,
+			Height:           height,
+		}
+		if height > 0 {
+			header.BlockRoot = stateStore.GetBlockRootWithNewTxRoot(txRoot)
+		}
+		block := &types.Block{
+			Header:       header,
+			Transactions: []*types.Transaction{tx},
+		}
+		blockHash := block.Hash()
+
+		blockStore.NewBatch()
+		err = blockStore.SaveBlock(block)
+		if err != nil {
+			return nil, err
+		}
+		blockStore.SaveBlockHash(height, blockHash)
+		blockStore.SaveCurrentBlock(height, blockHash)
+		err = blockStore.CommitTo()
+		if err != nil {
+			return nil, err
+		}
+
+		stateStore.NewBatch()
+		stateBatch := stateStore.NewStateBatch()
+		address := common.Address{byte(height % 7)}
+		stateBatch.TryAdd(scom.ST_STORAGE, append(address[:], []byte("key")...), &states.StorageItem{Value: []byte(fmt.Sprintf("value%d", height))})
+		err = stateStore.AddMerkleTreeRoot(txRoot)
+		if err != nil {
+			return nil, err
+		}
+		err = stateStore.AddStateRoot(height, stateBatch)
+		if err != nil {
+			return nil, err
+		}
+		stateStore.SaveCurrentBlock(height, blockHash)
+		err = stateBatch.CommitTo()
+		if err != nil {
+			return nil, err
+		}
+		err = stateStore.CommitTo()
+		if err != nil {
+			return nil, err
+		}
+
+		eventStore.NewBatch()
+		eventStore.SaveEventNotifyByTx(txHash, &event.ExecuteNotify{TxHash: txHash, State: event.CONTRACT_STATE_SUCCESS})
+		eventStore.SaveEventNotifyByBlock(height, []common.Uint256{txHash})
+		eventStore.SaveCurrentBlock(height, blockHash)
+		err = eventStore.CommitTo()
+		if err != nil {
+			return nil, err
+		}
+
+		blocks = append(blocks, block)
+		prevHash = blockHash
+	}
+	return blocks, nil
+}
+
+func TestSnapshot(t *testing.T) {
+	srcDir := "test/snapshot/source"
+	blocks, err := saveTestChain(srcDir, MIN_KEEP_BLOCKS+10)
+	if err != nil {
+		t.Errorf("saveTestChain error %s", err)
+		return
+	}
+	height := uint32(len(blocks) - 1)
+	buf := bytes.NewBuffer(nil)
+	info, err := WriteSnapshot(srcDir, buf)
+	if err != nil {
+		t.Errorf("WriteSnapshot error %s", err)
+		return
+	}
+	if info.Height != height || info.BlockHash != blocks[height].Hash() {
+		t.Errorf("TestSnapshot snapshot at height %d, expect %d", info.Height, height)
+		return
+	}
+	data := buf.Bytes()
+
+	corrupted := make([]byte, len(data))
+	copy(corrupted, data)
+	corrupted[len(corrupted)-sha256.Size-2] ^= 0xff
+	_, err = ImportSnapshot("test/snapshot/corrupted", bytes.NewReader(corrupted), info.BlockHash)
+	if err == nil {
+		t.Errorf("TestSnapshot corrupted snapshot should not be imported")
+		return
+	}
+	_, err = ImportSnapshot("test/snapshot/untrusted", bytes.NewReader(data), blocks[height-1].Hash())
+	if err == nil {
+		t.Errorf("TestSnapshot snapshot not at the trusted block should not be imported")
+		return
+	}
+
+	dstDir := "test/snapshot/target"
+	imported, err := ImportSnapshot(dstDir, bytes.NewReader(data), info.BlockHash)
+	if err != nil {
+		t.Errorf("ImportSnapshot error %s", err)
+		return
+	}
+	if *imported != *info {
+		t.Errorf("TestSnapshot imported snapshot %v != %v", imported, info)
+		return
+	}
+	_, err = ImportSnapshot(dstDir, bytes.NewReader(data), info.BlockHash)
+	if err != ErrLedgerExisted {
+		t.Errorf("TestSnapshot import into existed ledger error %v", err)
+		return
+	}
+
+	blockStore, err := NewBlockStore(fmt.Sprintf("%s%s%s", dstDir, string(os.PathSeparator), DBDirBlock), false)
+	if err != nil {
+		t.Errorf("NewBlockStore error %s", err)
+		return
+	}
+	defer blockStore.Close()
+	currHash, currHeight, err := blockStore.GetCurrentBlock()
+	if err != nil || currHeight != height || currHash != info.BlockHash {
+		t.Errorf("TestSnapshot current block height %d error %v", currHeight, err)
+		return
+	}
+	for _, block := range blocks {
+		blockHash, err := blockStore.GetBlockHash(block.Header.Height)
+		if err != nil || blockHash != block.Hash() {
+			t.Errorf("TestSnapshot block hash height %d error %v", block.Header.Height, err)
+			return
+		}
+		_, err = blockStore.GetHeader(blockHash)
+		if err != nil {
+			t.Errorf("TestSnapshot GetHeader height %d error %s", block.Header.Height, err)
+			return
+		}
+		_, err = blockStore.GetBlock(blockHash)
+		withBody := block.Header.Height == 0 || height-block.Header.Height < MIN_KEEP_BLOCKS
+		if withBody && err != nil {
+			t.Errorf("TestSnapshot GetBlock height %d error %s", block.Header.Height, err)
+			return
+		}
+		if !withBody && err == nil {
+			t.Errorf("TestSnapshot block height %d should be pruned", block.Header.Height)
+			return
+		}
+	}
+	prunedHeight, err := blockStore.GetPrunedHeight()
+	if err != nil || prunedHeight != height-MIN_KEEP_BLOCKS+1 {
+		t.Errorf("TestSnapshot pruned height %d error %v", prunedHeight, err)
+		return
+	}
+
+	stateStore, err := NewStateStore(fmt.Sprintf("%s%s%s", dstDir, string(os.PathSeparator), DBDirState),
+		fmt.Sprintf("%s%s%s", dstDir, string(os.PathSeparator), MerkleTreeStorePath))
+	if err != nil {
+		t.Errorf("NewStateStore error %s", err)
+		return
+	}
+	defer stateStore.Close()
+	stateRoot, err := stateStore.GetStateRoot(height)
+	if err != nil || stateRoot != info.StateRoot {
+		t.Errorf("TestSnapshot state root %s error %v", stateRoot.ToHexString(), err)
+		return
+	}
+	address := common.Address{byte(height % 7)}
+	item, err := stateStore.GetStorageState(&states.StorageKey{ContractAddress: address, Key: []byte("key")})
+	if err != nil || string(item.Value) != fmt.Sprintf("value%d", height) {
+		t.Errorf("TestSnapshot GetStorageState error %v", err)
+		return
+	}
+	proof, err := stateStore.GetMerkleProof(1, height)
+	if err != nil || len(proof) == 0 {
+		t.Errorf("TestSnapshot GetMerkleProof error %v", err)
+		return
+	}
+
+	//the next block must commit the state root of snapshot
+	ledgerStore := &LedgerStoreImp{stateStore: stateStore}
+	for _, stateRoot := range []common.Uint256{{}, {1}, info.StateRoot} {
+		blkInfo := &vconfig.VbftBlockInfo{}
+		if stateRoot != (common.Uint256{}) {
+			blkInfo.StateRoot = stateRoot[:]
+		}
+		consensusPayload, _ := json.Marshal(blkInfo)
+		err = ledgerStore.verifyStateRoot(&types.Header{Height: height + 1, ConsensusPayload: consensusPayload})
+		if (err == nil) != (stateRoot == info.StateRoot) {
+			t.Errorf("TestSnapshot verifyStateRoot %s error %v", stateRoot.ToHexString(), err)
+			return
+		}
+	}
+}
@@ -0,0 +1,418 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/common/log"
+	"github.com/imZhuFei/zeepin/common/serialization"
+	"github.com/imZhuFei/zeepin/core/payload"
+	"github.com/imZhuFei/zeepin/core/states"
+	scom "github.com/imZhuFei/zeepin/core/store/common"
+	"github.com/imZhuFei/zeepin/core/store/statestore"
+	"github.com/imZhuFei/zeepin/errors"
+)
+
+//State history is kept in archive mode. Every state value written by a block is saved again under the key
+//ST_HISTORY + escaped state key + 0x0000 + big endian block height, where 0x00 in state key is escaped to 0x00ff.
+//So the versions of a state key are adjacent and ordered by height, and the escaped keys keep the prefix order
+//of state keys. An empty value means the state was deleted at that height.
+
+//initStateHistory snapshot all states at current height when archive mode is turned on,
+//and drop the archive start when it is turned off, since the history will have a gap
+func (self *StateStore) initStateHistory(currBlockHeight uint32, hasBlock bool, archive bool) error {
+	_, err := self.GetArchiveStartHeight()
+	if err != nil && err != scom.ErrNotFound {
+		return err
+	}
+	archived := err == nil
+	if !archive {
+		if archived {
+			log.Warnf("archive mode is off, state history will not be queried any more")
+			return self.store.Delete(self.getArchiveStartKey())
+		}
+		return nil
+	}
+	if archived {
+		return nil
+	}
+	self.store.NewBatch()
+	if hasBlock {
+		log.Infof("saving state history snapshot at height %d", currBlockHeight)
+		count := 0
+		for _, prefix := range statePrefixes {
+			iter := self.store.NewIterator([]byte{byte(prefix)})
+			for iter.Next() {
+				self.store.BatchPut(getStateHistoryKey(iter.Key(), currBlockHeight), iter.Value())
+				count++
+				if count%SNAPSHOT_BATCH_SIZE != 0 {
+					continue
+				}
+				err = self.store.BatchCommit()
+				if err != nil {
+					iter.Release()
+					return err
+				}
+				self.store.NewBatch()
+			}
+			iter.Release()
+		}
+	}
+	//archive start is saved with the last batch, so an interrupted snapshot is saved again on restart
+	self.saveArchiveStartHeight(currBlockHeight)
+	return self.store.BatchCommit()
+}
+
+//AddStateHistory save the states changed in state batch as the version of block height
+func (self *StateStore) AddStateHistory(height uint32, stateBatch *statestore.StateBatch) error {
+	for k, v := range stateBatch.GetChangeSet() {
+		if !isStateKey([]byte(k)) {
+			continue
+		}
+		key := getStateHistoryKey([]byte(k), height)
+		if v.State == scom.Deleted {
+			self.store.BatchPut(key, []byte{})
+			continue
+		}
+		value := bytes.NewBuffer(nil)
+		if err := v.Value.Serialize(value); err != nil {
+			return fmt.Errorf("serialize state of key %x error %s", k, err)
+		}
+		self.store.BatchPut(key, value.Bytes())
+	}
+	return nil
+}
+
+//GetArchiveStartHeight return the height from which state history is kept
+func (self *StateStore) GetArchiveStartHeight() (uint32, error) {
+	data, err := self.store.Get(self.getArchiveStartKey())
+	if err != nil {
+		return 0, err
+	}
+	return serialization.ReadUint32(bytes.NewReader(data))
+}
+
+//NewHistoryStateBatch return a read only state batch of the states after the block of height is executed
+func (self *StateStore) NewHistoryStateBatch(height uint32) (*statestore.StateBatch, error) {
+	if err := self.checkArchived(height); err != nil {
+		return nil, err
+	}
+	return statestore.NewStateStoreBatch(statestore.NewMemDatabase(), &historyStore{store: self.store, height: height}), nil
+}
+
+//GetStorageStateAt return the storage value after the block of height is executed
+func (self *StateStore) GetStorageStateAt(key *states.StorageKey, height uint32) (*states.StorageItem, error) {
+	if err := self.checkArchived(height); err != nil {
+		return nil, err
+	}
+	storeKey, err := self.getStorageKey(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := getStateAt(self.store, storeKey, height)
+	if err != nil {
+		return nil, err
+	}
+	storageState := new(states.StorageItem)
+	err = storageState.Deserialize(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return storageState, nil
+}
+
+//GetContractStateAt return the contract after the block of height is executed
+func (self *StateStore) GetContractStateAt(contractHash common.Address, height uint32) (*payload.DeployCode, error) {
+	if err := self.checkArchived(height); err != nil {
+		return nil, err
+	}
+	key, err := self.getContractStateKey(contractHash)
+	if err != nil {
+		return nil, err
+	}
+	data, err := getStateAt(self.store, key, height)
+	if err != nil {
+		return nil, err
+	}
+	contractState := new(payload.DeployCode)
+	err = contractState.Deserialize(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return contractState, nil
+}
+
+func (self *StateStore) checkArchived(height uint32) error {
+	start, err := self.GetArchiveStartHeight()
+	if err == scom.ErrNotFound {
+		return fmt.Errorf("archive mode is disabled")
+	}
+	if err != nil {
+		return err
+	}
+	if height < start {
+		return fmt.Errorf("state at height %d is not archived, archive starts at height %d", height, start)
+	}
+	return nil
+}
+
+func (self *StateStore) saveArchiveStartHeight(height uint32) {
+	value := bytes.NewBuffer(nil)
+	serialization.WriteUint32(value, height)
+	self.store.BatchPut(self.getArchiveStartKey(), value.Bytes())
+}
+
+func (self *StateStore) getArchiveStartKey() []byte {
+	return []byte{byte(scom.SYS_ARCHIVE_START)}
+}
+
+//getStateAt return the latest version of state key not above height
+func getStateAt(store scom.PersistStore, stateKey []byte, height uint32) ([]byte, error) {
+	iter := store.NewIterator(getStateHistoryPrefix(stateKey, true))
+	defer iter.Release()
+	var ok bool
+	if iter.Seek(getStateHistoryKey(stateKey, height+1)) {
+		ok = iter.Prev()
+	} else {
+		ok = iter.Last()
+	}
+	if !ok || len(iter.Value()) == 0 {
+		return nil, scom.ErrNotFound
+	}
+	return append([]byte{}, iter.Value()...), nil
+}
+
+//isStateKey return whether the key is one of the state entries kept in state history
+func isStateKey(key []byte) bool {
+	if len(key) == 0 {
+		return false
+	}
+	for _, prefix := range statePrefixes {
+		if key[0] == byte(prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func getStateHistoryPrefix(stateKey []byte, terminate bool) []byte {
+	key := make([]byte, 0, 1+len(stateKey)+2)
+	key = append(key, byte(scom.ST_HISTORY))
+	for _, b := range stateKey {
+		if b == 0 {
+			key = append(key, 0, 0xff)
+		} else {
+			key = append(key, b)
+		}
+	}
+	if terminate {
+		key = append(key, 0, 0)
+	}
+	return key
+}
+
+func getStateHistoryKey(stateKey []byte, height uint32) []byte {
+	key := getStateHistoryPrefix(stateKey, true)
+	key = append(key, make([]byte, 4)...)
+	binary.BigEndian.PutUint32(key[len(key)-4:], height)
+	return key
+}
+
+func parseStateHistoryKey(key []byte) ([]byte, uint32, error) {
+	stateKey := make([]byte, 0, len(key))
+	for i := 1; i < len(key); i++ {
+		if key[i] != 0 {
+			stateKey = append(stateKey, key[i])
+			continue
+		}
+		if i+1 < len(key) && key[i+1] == 0xff {
+			stateKey = append(stateKey, 0)
+			i++
+			continue
+		}
+		if i+6 == len(key) && key[i+1] == 0 {
+			return stateKey, binary.BigEndian.Uint32(key[i+2:]), nil
+		}
+		break
+	}
+	return nil, 0, fmt.Errorf("invalid state history key %x", key)
+}
+
+//historyStore is a read only view of the states after the block of height is executed
+type historyStore struct {
+	store  scom.PersistStore
+	height uint32
+}
+
+func (self *historyStore) Put(key []byte, value []byte) error {
+	return errors.NewErr("history store is read only")
+}
+
+func (self *historyStore) Get(key []byte) ([]byte, error) {
+	return getStateAt(self.store, key, self.height)
+}
+
+func (self *historyStore) Has(key []byte) (bool, error) {
+	_, err := self.Get(key)
+	if err == scom.ErrNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (self *historyStore) Delete(key []byte) error {
+	return errors.NewErr("history store is read only")
+}
+
+func (self *historyStore) NewBatch() {}
+
+func (self *historyStore) BatchPut(key []byte, value []byte) {}
+
+func (self *historyStore) BatchDelete(key []byte) {}
+
+func (self *historyStore) BatchCommit() error {
+	return errors.NewErr("history store is read only")
+}
+
+func (self *historyStore) BatchData() []byte {
+	return nil
+}
+
+func (self *historyStore) CommitBatchData(data []byte) error {
+	return errors.NewErr("history store is read only")
+}
+
+func (self *historyStore) Close() error {
+	return nil
+}
+
+//NewIterator return the states with prefix at height. The version of each state key is looked up when the
+//iterator moves to it, by seeking to the version of height+1 and stepping back
+func (self *historyStore) NewIterator(prefix []byte) scom.StoreIterator {
+	return &historyIterator{
+		iter:   self.store.NewIterator(getStateHistoryPrefix(prefix, false)),
+		height: self.height,
+	}
+}
+
+//historyIterator iterate the state keys of history, skipping the keys without version or deleted at height
+type historyIterator struct {
+	iter   scom.StoreIterator
+	height uint32
+	key    []byte
+	value  []byte
+}
+
+func (it *historyIterator) Next() bool {
+	if it.key == nil {
+		return it.forward(it.iter.Next())
+	}
+	//the versions of a longer state key with the same prefix are escaped to 0x00ff or above
+	return it.forward(it.iter.Seek(append(getStateHistoryPrefix(it.key, false), 0, 1)))
+}
+
+func (it *historyIterator) Prev() bool {
+	if it.key == nil {
+		return it.backward(it.iter.Prev())
+	}
+	return it.backward(it.iter.Seek(getStateHistoryKey(it.key, 0)) && it.iter.Prev())
+}
+
+func (it *historyIterator) First() bool {
+	return it.forward(it.iter.First())
+}
+
+func (it *historyIterator) Last() bool {
+	return it.backward(it.iter.Last())
+}
+
+func (it *historyIterator) Seek(key []byte) bool {
+	return it.forward(it.iter.Seek(getStateHistoryPrefix(key, false)))
+}
+
+func (it *historyIterator) Key() []byte {
+	return it.key
+}
+
+func (it *historyIterator) Value() []byte {
+	return it.value
+}
+
+func (it *historyIterator) Release() {
+	it.iter.Release()
+}
+
+//forward moves to the first state key with value at height, from the version where the iterator is
+func (it *historyIterator) forward(ok bool) bool {
+	for ok {
+		stateKey, _, err := parseStateHistoryKey(it.iter.Key())
+		if err != nil {
+			log.Errorf("historyIterator.Next error %s", err)
+			ok = it.iter.Next()
+			continue
+		}
+		if it.load(stateKey) {
+			return true
+		}
+		ok = it.iter.Seek(append(getStateHistoryPrefix(stateKey, false), 0, 1))
+	}
+	it.key, it.value = nil, nil
+	return false
+}
+
+//backward moves to the last state key with value at height, from the version where the iterator is
+func (it *historyIterator) backward(ok bool) bool {
+	for ok {
+		stateKey, _, err := parseStateHistoryKey(it.iter.Key())
+		if err != nil {
+			log.Errorf("historyIterator.Prev error %s", err)
+			ok = it.iter.Prev()
+			continue
+		}
+		if it.load(stateKey) {
+			return true
+		}
+		ok = it.iter.Seek(getStateHistoryKey(stateKey, 0)) && it.iter.Prev()
+	}
+	it.key, it.value = nil, nil
+	return false
+}
+
+//load find the latest version of state key not above height, and return whether the state exists at height
+func (it *historyIterator) load(stateKey []byte) bool {
+	var ok bool
+	if it.iter.Seek(getStateHistoryKey(stateKey, it.height+1)) {
+		ok = it.iter.Prev()
+	} else {
+		ok = it.iter.Last()
+	}
+	if !ok || len(it.iter.Value()) == 0 {
+		return false
+	}
+	key, _, err := parseStateHistoryKey(it.iter.Key())
+	if err != nil || !bytes.Equal(key, stateKey) {
+		return false
+	}
+	it.key, it.value = stateKey, append([]byte{}, it.iter.Value()...)
+	return true
+}
@@ -0,0 +1,204 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/common/config"
+	"github.com/imZhuFei/zeepin/core/payload"
+	"github.com/imZhuFei/zeepin/core/states"
+	scommon "github.com/imZhuFei/zeepin/core/store/common"
+	"github.com/imZhuFei/zeepin/core/store/statestore"
+)
+
+func TestStateHistoryKey(t *testing.T) {
+	for _, stateKey := range [][]byte{{}, {0}, {0, 0}, {1, 0, 0xff, 0}, []byte("key")} {
+		key, height, err := parseStateHistoryKey(getStateHistoryKey(stateKey, 0x01000200))
+		if err != nil {
+			t.Errorf("parseStateHistoryKey error %s", err)
+			return
+		}
+		if !bytes.Equal(key, stateKey) || height != 0x01000200 {
+			t.Errorf("parseStateHistoryKey %x %d != %x", key, height, stateKey)
+			return
+		}
+	}
+	if bytes.HasPrefix(getStateHistoryKey([]byte{1, 0}, 0), getStateHistoryPrefix([]byte{1}, true)) {
+		t.Errorf("versions of different keys are not separated")
+		return
+	}
+}
+
+func TestStateHistory(t *testing.T) {
+	config.DefConfig.Common.EnableArchive = true
+	defer func() { config.DefConfig.Common.EnableArchive = false }()
+
+	dir := "test/archive"
+	store, err := NewStateStore(dir, dir+"/"+MerkleTreeStorePath)
+	if err != nil {
+		t.Errorf("NewStateStore error %s", err)
+		return
+	}
+	defer store.Close()
+
+	address := common.Address{0x03}
+	key1 := append(address[:], []byte("key")...)
+	key2 := append(append([]byte{}, key1...), 0)
+	key3 := append(append([]byte{}, key1...), []byte("a")...)
+	blocks := []func(batch *statestore.StateBatch){
+		func(batch *statestore.StateBatch) {
+			batch.TryAdd(scommon.ST_STORAGE, key1, &states.StorageItem{Value: []byte("value1")})
+			batch.TryAdd(scommon.ST_STORAGE, key2, &states.StorageItem{Value: []byte("value2")})
+			batch.TryAdd(scommon.ST_CONTRACT, address[:], &payload.DeployCode{Code: []byte("code1")})
+		},
+		func(batch *statestore.StateBatch) {
+			batch.TryAdd(scommon.ST_STORAGE, key1, &states.StorageItem{Value: []byte("value1'")})
+			batch.TryDelete(scommon.ST_STORAGE, key2)
+		},
+		func(batch *statestore.StateBatch) {
+			batch.TryAdd(scommon.ST_STORAGE, key3, &states.StorageItem{Value: []byte("value3")})
+			batch.TryAdd(scommon.ST_CONTRACT, address[:], &payload.DeployCode{Code: []byte("code2")})
+		},
+	}
+	for height, block := range blocks {
+		store.NewBatch()
+		batch := store.NewStateBatch()
+		block(batch)
+		if err := store.AddStateHistory(uint32(height), batch); err != nil {
+			t.Errorf("AddStateHistory error %s", err)
+			return
+		}
+		if err := batch.CommitTo(); err != nil {
+			t.Errorf("batch.CommitTo error %s", err)
+			return
+		}
+		if err := store.CommitTo(); err != nil {
+			t.Errorf("store.CommitTo error %s", err)
+			return
+		}
+	}
+
+	expects := []struct {
+		key    []byte
+		height uint32
+		value  string
+	}{
+		{key1, 0, "value1"},
+		{key1, 1, "value1'"},
+		{key1, 2, "value1'"},
+		{key2, 0, "value2"},
+		{key2, 1, ""},
+		{key3, 1, ""},
+		{key3, 2, "value3"},
+	}
+	for _, expect := range expects {
+		storageKey := &states.StorageKey{ContractAddress: address, Key: expect.key[common.ADDR_LEN:]}
+		item, err := store.GetStorageStateAt(storageKey, expect.height)
+		if expect.value == "" {
+			if err != scommon.ErrNotFound {
+				t.Errorf("GetStorageStateAt %x at %d should not be found, err %v", expect.key, expect.height, err)
+				return
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("GetStorageStateAt error %s", err)
+			return
+		}
+		if string(item.Value) != expect.value {
+			t.Errorf("GetStorageStateAt %x at %d %s != %s", expect.key, expect.height, item.Value, expect.value)
+			return
+		}
+	}
+
+	for height, code := range []string{"code1", "code1", "code2"} {
+		contract, err := store.GetContractStateAt(address, uint32(height))
+		if err != nil {
+			t.Errorf("GetContractStateAt error %s", err)
+			return
+		}
+		if string(contract.Code) != code {
+			t.Errorf("GetContractStateAt at %d %s != %s", height, contract.Code, code)
+			return
+		}
+	}
+
+	for height, count := range []int{2, 1, 2} {
+		batch, err := store.NewHistoryStateBatch(uint32(height))
+		if err != nil {
+			t.Errorf("NewHistoryStateBatch error %s", err)
+			return
+		}
+		items, err := batch.Find(scommon.ST_STORAGE, address[:])
+		if err != nil {
+			t.Errorf("Find error %s", err)
+			return
+		}
+		if len(items) != count {
+			t.Errorf("Find at %d count %d != %d", height, len(items), count)
+			return
+		}
+	}
+
+	//key2 deleted at height 1 is skipped in both directions
+	storeKey := func(key []byte) []byte {
+		return append([]byte{byte(scommon.ST_STORAGE)}, key...)
+	}
+	iter := (&historyStore{store: store.store, height: 2}).NewIterator(storeKey(address[:]))
+	defer iter.Release()
+	if !iter.Last() || !bytes.Equal(iter.Key(), storeKey(key3)) {
+		t.Errorf("history iterator Last %x != %x", iter.Key(), key3)
+		return
+	}
+	if !iter.Prev() || !bytes.Equal(iter.Key(), storeKey(key1)) {
+		t.Errorf("history iterator Prev %x != %x", iter.Key(), key1)
+		return
+	}
+	if !iter.Seek(storeKey(key2)) || !bytes.Equal(iter.Key(), storeKey(key3)) {
+		t.Errorf("history iterator Seek %x != %x", iter.Key(), key3)
+		return
+	}
+	if iter.Next() {
+		t.Errorf("history iterator Next after last %x", iter.Key())
+		return
+	}
+
+	if _, err := testStateStore.NewHistoryStateBatch(0); err == nil {
+		t.Errorf("NewHistoryStateBatch should fail without archive mode")
+		return
+	}
+}
+
+func TestStateHistoryPrefixes(t *testing.T) {
+	for _, prefix := range statePrefixes {
+		if !isStateKey([]byte{byte(prefix), 1}) {
+			t.Errorf("state prefix %x is not kept in state history", prefix)
+			return
+		}
+	}
+	for _, key := range [][]byte{{}, {byte(scommon.ST_HISTORY), 1}, {byte(scommon.SYS_CURRENT_BLOCK)}} {
+		if isStateKey(key) {
+			t.Errorf("key %x should not be kept in state history", key)
+			return
+		}
+	}
+}
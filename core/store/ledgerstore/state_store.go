@@ -39,6 +39,8 @@ import (
 	"fmt"
 
 	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/common/config"
+	"github.com/imZhuFei/zeepin/common/log"
 	"github.com/imZhuFei/zeepin/common/serialization"
 	"github.com/imZhuFei/zeepin/core/payload"
 	"github.com/imZhuFei/zeepin/core/states"
@@ -52,6 +54,11 @@ var (
 	BOOKKEEPER = []byte("Bookkeeper") //Bookkeeper store key
 )
 
+const (
+	STATE_ROOT_BATCH_SIZE  = 10000       //Count of storage items applied to state merkle tree in one batch when building it
+	STATE_ROOT_KEEP_BLOCKS = uint32(128) //Count of recent state roots whose tree nodes are kept for storage proof, unless in archive mode
+)
+
 //StateStore saving the data of ledger states. Like balance of account, and the execution result of smart contract
 type StateStore struct {
 	dbDir           string                    //Store file path
@@ -77,10 +84,21 @@ func NewStateStore(dbDir, merklePath string) (*StateStore, error) {
 	if err != nil && err != scom.ErrNotFound {
 		return nil, fmt.Errorf("GetCurrentBlock error %s", err)
 	}
+	hasBlock := err == nil
 	err = stateStore.init(height)
 	if err != nil {
 		return nil, fmt.Errorf("init error %s", err)
 	}
+	if hasBlock {
+		err = stateStore.initStateRoot(height)
+		if err != nil {
+			return nil, fmt.Errorf("initStateRoot error %s", err)
+		}
+	}
+	err = stateStore.initStateHistory(height, hasBlock, config.DefConfig.Common.EnableArchive)
+	if err != nil {
+		return nil, fmt.Errorf("initStateHistory error %s", err)
+	}
 	return stateStore, nil
 }
 
@@ -132,17 +150,20 @@ func (self *StateStore) GetMerkleTree() (uint32, []common.Uint256, error) {
 
 //AddMerkleTreeRoot add a new tree root
 func (self *StateStore) AddMerkleTreeRoot(txRoot common.Uint256) error {
-	key := self.getMerkleTreeKey()
-
 	self.merkleTree.AppendHash(txRoot)
 	err := self.merkleHashStore.Flush()
 	if err != nil {
 		return err
 	}
-	treeSize := self.merkleTree.TreeSize()
-	hashes := self.merkleTree.Hashes()
+	return self.saveMerkleTree(self.merkleTree)
+}
+
+func (self *StateStore) saveMerkleTree(merkleTree *merkle.CompactMerkleTree) error {
+	key := self.getMerkleTreeKey()
+	treeSize := merkleTree.TreeSize()
+	hashes := merkleTree.Hashes()
 	value := bytes.NewBuffer(make([]byte, 0, 4+len(hashes)*common.UINT256_SIZE))
-	err = serialization.WriteUint32(value, treeSize)
+	err := serialization.WriteUint32(value, treeSize)
 	if err != nil {
 		return err
 	}
@@ -161,6 +182,216 @@ func (self *StateStore) GetMerkleProof(proofHeight, rootHeight uint32) ([]common
 	return self.merkleTree.InclusionProof(proofHeight, rootHeight+1)
 }
 
+//initStateRoot build the state merkle tree from all storage, if the store was created without state root
+func (self *StateStore) initStateRoot(currBlockHeight uint32) error {
+	_, err := self.GetCurrentStateRoot()
+	if err != scom.ErrNotFound {
+		return err
+	}
+	log.Infof("building state merkle tree at height %d", currBlockHeight)
+	root := common.UINT256_EMPTY
+	kvs := make(map[string][]byte)
+	iter := self.store.NewIterator([]byte{byte(scom.ST_STORAGE)})
+	defer iter.Release()
+	for iter.Next() {
+		item := new(states.StorageItem)
+		err = item.Deserialize(bytes.NewReader(iter.Value()))
+		if err != nil {
+			return err
+		}
+		kvs[string(iter.Key()[1:])] = getStorageValue(item)
+		if len(kvs) < STATE_ROOT_BATCH_SIZE {
+			continue
+		}
+		self.store.NewBatch()
+		root, err = self.rebuildStateRoot(root, kvs)
+		if err != nil {
+			return err
+		}
+		err = self.store.BatchCommit()
+		if err != nil {
+			return err
+		}
+		kvs = make(map[string][]byte)
+	}
+
+	self.store.NewBatch()
+	root, err = self.rebuildStateRoot(root, kvs)
+	if err != nil {
+		return err
+	}
+	self.saveStateRoot(currBlockHeight, root)
+	//roots below the height never exist, so they need not be released
+	self.saveStateRootPrunedHeight(currBlockHeight)
+	return self.store.BatchCommit()
+}
+
+//rebuildStateRoot apply a batch of storage to the state merkle tree being built, and release the root of previous batch
+func (self *StateStore) rebuildStateRoot(root common.Uint256, kvs map[string][]byte) (common.Uint256, error) {
+	nodeStore := newStateNodeStore(self.store)
+	tree := merkle.NewSparseMerkleTree(root, nodeStore)
+	err := tree.Update(kvs)
+	if err != nil {
+		return common.UINT256_EMPTY, err
+	}
+	err = tree.Retain()
+	if err != nil {
+		return common.UINT256_EMPTY, err
+	}
+	err = tree.Release(root)
+	if err != nil {
+		return common.UINT256_EMPTY, err
+	}
+	nodeStore.flush()
+	return tree.Root(), nil
+}
+
+//AddStateRoot apply the storage changed in state batch to state merkle tree, and save the new root of block height
+func (self *StateStore) AddStateRoot(height uint32, stateBatch *statestore.StateBatch) error {
+	kvs := make(map[string][]byte)
+	for k, v := range stateBatch.GetChangeSet() {
+		if k[0] != byte(scom.ST_STORAGE) {
+			continue
+		}
+		if v.State == scom.Deleted {
+			kvs[k[1:]] = nil
+			continue
+		}
+		item, ok := v.Value.(*states.StorageItem)
+		if !ok {
+			return fmt.Errorf("storage item of key %x is invalid", k[1:])
+		}
+		kvs[k[1:]] = getStorageValue(item)
+	}
+	root, err := self.GetCurrentStateRoot()
+	if err != nil && err != scom.ErrNotFound {
+		return err
+	}
+	nodeStore := newStateNodeStore(self.store)
+	tree := merkle.NewSparseMerkleTree(root, nodeStore)
+	err = tree.Update(kvs)
+	if err != nil {
+		return err
+	}
+	err = tree.Retain()
+	if err != nil {
+		return err
+	}
+	if !config.DefConfig.Common.EnableArchive {
+		err = self.releaseStateRoots(tree, height)
+		if err != nil {
+			return err
+		}
+	}
+	nodeStore.flush()
+	self.saveStateRoot(height, tree.Root())
+	return nil
+}
+
+//releaseStateRoots release the state roots older than STATE_ROOT_KEEP_BLOCKS blocks, so the tree nodes no longer
+//referenced by recent roots are deleted. At most PRUNE_BATCH_SIZE roots are released each time
+func (self *StateStore) releaseStateRoots(tree *merkle.SparseMerkleTree, height uint32) error {
+	if height < STATE_ROOT_KEEP_BLOCKS {
+		return nil
+	}
+	endHeight := height - STATE_ROOT_KEEP_BLOCKS + 1
+	startHeight, err := self.GetStateRootPrunedHeight()
+	if err != nil && err != scom.ErrNotFound {
+		return err
+	}
+	if startHeight >= endHeight {
+		return nil
+	}
+	if endHeight-startHeight > PRUNE_BATCH_SIZE {
+		endHeight = startHeight + PRUNE_BATCH_SIZE
+	}
+	for h := startHeight; h < endHeight; h++ {
+		root, err := self.GetStateRoot(h)
+		if err == scom.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		err = tree.Release(root)
+		if err != nil {
+			return fmt.Errorf("release state root of height %d error %s", h, err)
+		}
+	}
+	self.saveStateRootPrunedHeight(endHeight)
+	return nil
+}
+
+//GetStateRootPrunedHeight return the height below which the tree nodes of state roots are released
+func (self *StateStore) GetStateRootPrunedHeight() (uint32, error) {
+	value, err := self.store.Get([]byte{byte(scom.SYS_STATE_ROOT_PRUNED)})
+	if err != nil {
+		return 0, err
+	}
+	return serialization.ReadUint32(bytes.NewReader(value))
+}
+
+func (self *StateStore) saveStateRootPrunedHeight(height uint32) {
+	value := bytes.NewBuffer(nil)
+	serialization.WriteUint32(value, height)
+	self.store.BatchPut([]byte{byte(scom.SYS_STATE_ROOT_PRUNED)}, value.Bytes())
+}
+
+//GetCurrentStateRoot return the state root of current block
+func (self *StateStore) GetCurrentStateRoot() (common.Uint256, error) {
+	data, err := self.store.Get([]byte{byte(scom.SYS_CURRENT_STATE_ROOT)})
+	if err != nil {
+		return common.UINT256_EMPTY, err
+	}
+	return common.Uint256ParseFromBytes(data)
+}
+
+//GetStateRoot return the state root after the block of height is executed
+func (self *StateStore) GetStateRoot(height uint32) (common.Uint256, error) {
+	data, err := self.store.Get(self.getStateRootKey(height))
+	if err != nil {
+		return common.UINT256_EMPTY, err
+	}
+	return common.Uint256ParseFromBytes(data)
+}
+
+//GetStorageProof return the storage value of the key after the block of height is executed,
+//and the proof of it against the state root of height. Nil value means the key not exist.
+//The tree nodes of a root released by pruning are deleted, so its proof returns error
+func (self *StateStore) GetStorageProof(key *states.StorageKey, height uint32) ([]byte, *merkle.SparseMerkleProof, error) {
+	root, err := self.GetStateRoot(height)
+	if err != nil {
+		return nil, nil, err
+	}
+	storeKey, err := self.getStorageKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	tree := merkle.NewSparseMerkleTree(root, newStateNodeStore(self.store))
+	return tree.Prove(storeKey[1:])
+}
+
+func (self *StateStore) saveStateRoot(height uint32, root common.Uint256) {
+	self.store.BatchPut(self.getStateRootKey(height), root.ToArray())
+	self.store.BatchPut([]byte{byte(scom.SYS_CURRENT_STATE_ROOT)}, root.ToArray())
+}
+
+//GetSnapshotHeight return the height of the snapshot which the states are imported from
+func (self *StateStore) GetSnapshotHeight() (uint32, error) {
+	value, err := self.store.Get([]byte{byte(scom.SYS_SNAPSHOT_HEIGHT)})
+	if err != nil {
+		return 0, err
+	}
+	return serialization.ReadUint32(bytes.NewReader(value))
+}
+
+//SaveSnapshotHeight persist the height of the snapshot which the states are imported from
+func (self *StateStore) SaveSnapshotHeight(height uint32) {
+	value := bytes.NewBuffer(nil)
+	serialization.WriteUint32(value, height)
+	self.store.BatchPut([]byte{byte(scom.SYS_SNAPSHOT_HEIGHT)}, value.Bytes())
+}
+
 //NewStateBatch return state commit bathe. Usually using in smart contract execution
 func (self *StateStore) NewStateBatch() *statestore.StateBatch {
 	return statestore.NewStateStoreBatch(statestore.NewMemDatabase(), self.store)
@@ -246,6 +477,24 @@ func (self *StateStore) GetStorageState(key *states.StorageKey) (*states.Storage
 	return storageState, nil
 }
 
+//GetEventSchemas return the event schemas registered by contract
+func (self *StateStore) GetEventSchemas(contractHash common.Address) (*states.EventSchemaState, error) {
+	key := make([]byte, 1+common.ADDR_LEN)
+	key[0] = byte(scom.ST_EVENT_SCHEMA)
+	copy(key[1:], contractHash[:])
+
+	data, err := self.store.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	schemas := new(states.EventSchemaState)
+	err = schemas.Deserialize(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return schemas, nil
+}
+
 //GetCurrentBlock return current block height and current hash in state store
 func (self *StateStore) GetCurrentBlock() (common.Uint256, uint32, error) {
 	key := self.getCurrentBlockKey()
@@ -307,6 +556,13 @@ func (self *StateStore) GetBlockRootWithNewTxRoot(txRoot common.Uint256) common.
 	return self.merkleTree.GetRootWithNewLeaf(txRoot)
 }
 
+func (self *StateStore) getStateRootKey(height uint32) []byte {
+	key := bytes.NewBuffer(nil)
+	key.WriteByte(byte(scom.SYS_STATE_ROOT))
+	serialization.WriteUint32(key, height)
+	return key.Bytes()
+}
+
 func (self *StateStore) getMerkleTreeKey() []byte {
 	return []byte{byte(scom.SYS_BLOCK_MERKLE_TREE)}
 }
@@ -326,3 +582,94 @@ func (self *StateStore) ClearAll() error {
 func (self *StateStore) Close() error {
 	return self.store.Close()
 }
+
+//stateNodeStore persist the nodes of state merkle tree and their reference counts in the batch of state store.
+//The changes are cached until flush, since the batch cannot be read before commit
+type stateNodeStore struct {
+	store scom.PersistStore
+	nodes map[common.Uint256][]byte //Changed nodes, nil for deleted
+	refs  map[common.Uint256]uint32 //Changed reference counts, 0 for deleted
+}
+
+func newStateNodeStore(store scom.PersistStore) *stateNodeStore {
+	return &stateNodeStore{
+		store: store,
+		nodes: make(map[common.Uint256][]byte),
+		refs:  make(map[common.Uint256]uint32),
+	}
+}
+
+func (self *stateNodeStore) GetNode(hash common.Uint256) ([]byte, error) {
+	if node, ok := self.nodes[hash]; ok {
+		if node == nil {
+			return nil, scom.ErrNotFound
+		}
+		return node, nil
+	}
+	return self.store.Get(getStateMerkleTreeKey(hash))
+}
+
+func (self *stateNodeStore) PutNode(hash common.Uint256, node []byte) {
+	self.nodes[hash] = node
+}
+
+func (self *stateNodeStore) DeleteNode(hash common.Uint256) {
+	self.nodes[hash] = nil
+	self.refs[hash] = 0
+}
+
+func (self *stateNodeStore) GetNodeRef(hash common.Uint256) (uint32, error) {
+	if ref, ok := self.refs[hash]; ok {
+		return ref, nil
+	}
+	value, err := self.store.Get(getStateMerkleRefKey(hash))
+	if err == scom.ErrNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return serialization.ReadUint32(bytes.NewReader(value))
+}
+
+func (self *stateNodeStore) PutNodeRef(hash common.Uint256, ref uint32) {
+	self.refs[hash] = ref
+}
+
+//flush put the changed nodes and reference counts to the batch of state store
+func (self *stateNodeStore) flush() {
+	for hash, node := range self.nodes {
+		if node == nil {
+			self.store.BatchDelete(getStateMerkleTreeKey(hash))
+			continue
+		}
+		self.store.BatchPut(getStateMerkleTreeKey(hash), node)
+	}
+	for hash, ref := range self.refs {
+		if ref == 0 {
+			self.store.BatchDelete(getStateMerkleRefKey(hash))
+			continue
+		}
+		value := bytes.NewBuffer(nil)
+		serialization.WriteUint32(value, ref)
+		self.store.BatchPut(getStateMerkleRefKey(hash), value.Bytes())
+	}
+	self.nodes = make(map[common.Uint256][]byte)
+	self.refs = make(map[common.Uint256]uint32)
+}
+
+func getStateMerkleTreeKey(hash common.Uint256) []byte {
+	return append([]byte{byte(scom.SYS_STATE_MERKLE_TREE)}, hash[:]...)
+}
+
+func getStateMerkleRefKey(hash common.Uint256) []byte {
+	return append([]byte{byte(scom.SYS_STATE_MERKLE_REF)}, hash[:]...)
+}
+
+//getStorageValue return the value committed by state merkle tree, a changed item always has a leaf
+func getStorageValue(item *states.StorageItem) []byte {
+	if item.Value == nil {
+		return []byte{}
+	}
+	return item.Value
+}
@@ -35,14 +35,17 @@
 package ledgerstore
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/imZhuFei/zeepin/account"
+	"github.com/imZhuFei/zeepin/common"
 	"github.com/imZhuFei/zeepin/core/payload"
 	"github.com/imZhuFei/zeepin/core/states"
 	scommon "github.com/imZhuFei/zeepin/core/store/common"
 	"github.com/imZhuFei/zeepin/core/store/statestore"
 	"github.com/imZhuFei/zeepin/core/types"
+	"github.com/imZhuFei/zeepin/merkle"
 	"github.com/ontio/ontology-crypto/keypair"
 )
 
@@ -154,6 +157,224 @@ func TestBookkeeperState(t *testing.T) {
 	}
 }
 
+func TestStateRoot(t *testing.T) {
+	batch, err := getStateBatch()
+	if err != nil {
+		t.Errorf("NewStateBatch error %s", err)
+		return
+	}
+	address := common.Address{0x01}
+	key1 := &states.StorageKey{ContractAddress: address, Key: []byte("key1")}
+	key2 := &states.StorageKey{ContractAddress: address, Key: []byte("key2")}
+	batch.TryAdd(scommon.ST_STORAGE, append(address[:], key1.Key...), &states.StorageItem{Value: []byte("value1")})
+	batch.TryAdd(scommon.ST_STORAGE, append(address[:], key2.Key...), &states.StorageItem{Value: []byte("value2")})
+	err = testStateStore.AddStateRoot(1, batch)
+	if err != nil {
+		t.Errorf("AddStateRoot error %s", err)
+		return
+	}
+	err = batch.CommitTo()
+	if err != nil {
+		t.Errorf("batch.CommitTo error %s", err)
+		return
+	}
+	err = testStateStore.CommitTo()
+	if err != nil {
+		t.Errorf("testStateStore.CommitTo error %s", err)
+		return
+	}
+	root1, err := testStateStore.GetStateRoot(1)
+	if err != nil {
+		t.Errorf("GetStateRoot error %s", err)
+		return
+	}
+
+	batch, err = getStateBatch()
+	if err != nil {
+		t.Errorf("NewStateBatch error %s", err)
+		return
+	}
+	batch.TryDelete(scommon.ST_STORAGE, append(address[:], key2.Key...))
+	err = testStateStore.AddStateRoot(2, batch)
+	if err != nil {
+		t.Errorf("AddStateRoot error %s", err)
+		return
+	}
+	err = batch.CommitTo()
+	if err != nil {
+		t.Errorf("batch.CommitTo error %s", err)
+		return
+	}
+	err = testStateStore.CommitTo()
+	if err != nil {
+		t.Errorf("testStateStore.CommitTo error %s", err)
+		return
+	}
+	root2, err := testStateStore.GetStateRoot(2)
+	if err != nil {
+		t.Errorf("GetStateRoot error %s", err)
+		return
+	}
+
+	value, proof, err := testStateStore.GetStorageProof(key2, 1)
+	if err != nil {
+		t.Errorf("GetStorageProof error %s", err)
+		return
+	}
+	if string(value) != "value2" {
+		t.Errorf("TestStateRoot value of height 1 %s != value2", value)
+		return
+	}
+	err = merkle.VerifySparseMerkleProof(root1, append(address[:], key2.Key...), value, proof)
+	if err != nil {
+		t.Errorf("VerifySparseMerkleProof error %s", err)
+		return
+	}
+	value, proof, err = testStateStore.GetStorageProof(key2, 2)
+	if err != nil {
+		t.Errorf("GetStorageProof error %s", err)
+		return
+	}
+	if value != nil {
+		t.Errorf("TestStateRoot value of height 2 %s != nil", value)
+		return
+	}
+	err = merkle.VerifySparseMerkleProof(root2, append(address[:], key2.Key...), nil, proof)
+	if err != nil {
+		t.Errorf("VerifySparseMerkleProof error %s", err)
+		return
+	}
+}
+
+func TestInitStateRoot(t *testing.T) {
+	dir := "test/stateroot"
+	store, err := NewStateStore(dir, dir+"/"+MerkleTreeStorePath)
+	if err != nil {
+		t.Errorf("NewStateStore error %s", err)
+		return
+	}
+	//store created before state root was introduced, with more storage than one build batch
+	store.NewBatch()
+	batch := store.NewStateBatch()
+	address := common.Address{0x02}
+	kvs := make(map[string][]byte)
+	for i := 0; i <= STATE_ROOT_BATCH_SIZE; i++ {
+		key := append(address[:], []byte(fmt.Sprintf("key%d", i))...)
+		value := []byte(fmt.Sprintf("value%d", i))
+		batch.TryAdd(scommon.ST_STORAGE, key, &states.StorageItem{Value: value})
+		kvs[string(key)] = value
+	}
+	err = batch.CommitTo()
+	if err != nil {
+		t.Errorf("batch.CommitTo error %s", err)
+		return
+	}
+	store.SaveCurrentBlock(10, common.Uint256{})
+	err = store.CommitTo()
+	if err != nil {
+		t.Errorf("store.CommitTo error %s", err)
+		return
+	}
+	store.Close()
+
+	store, err = NewStateStore(dir, dir+"/"+MerkleTreeStorePath)
+	if err != nil {
+		t.Errorf("NewStateStore error %s", err)
+		return
+	}
+	defer store.Close()
+	root, err := store.GetStateRoot(10)
+	if err != nil {
+		t.Errorf("GetStateRoot error %s", err)
+		return
+	}
+	tree := merkle.NewSparseMerkleTree(common.UINT256_EMPTY, merkle.NewMemNodeStore())
+	tree.Update(kvs)
+	if expect := tree.Root(); root != expect {
+		t.Errorf("TestInitStateRoot root %s != %s", root.ToHexString(), expect.ToHexString())
+		return
+	}
+	//nodes of the roots of previous build batches are deleted, so no node is left after the root is released
+	store.NewBatch()
+	nodeStore := newStateNodeStore(store.store)
+	err = merkle.NewSparseMerkleTree(root, nodeStore).Release(root)
+	if err != nil {
+		t.Errorf("Release error %s", err)
+		return
+	}
+	nodeStore.flush()
+	err = store.CommitTo()
+	if err != nil {
+		t.Errorf("store.CommitTo error %s", err)
+		return
+	}
+	for _, prefix := range []scommon.DataEntryPrefix{scommon.SYS_STATE_MERKLE_TREE, scommon.SYS_STATE_MERKLE_REF} {
+		iter := store.store.NewIterator([]byte{byte(prefix)})
+		if iter.Next() {
+			t.Errorf("TestInitStateRoot key %x left after root released", iter.Key())
+		}
+		iter.Release()
+	}
+}
+
+func TestReleaseStateRoots(t *testing.T) {
+	dir := "test/staterelease"
+	store, err := NewStateStore(dir, dir+"/"+MerkleTreeStorePath)
+	if err != nil {
+		t.Errorf("NewStateStore error %s", err)
+		return
+	}
+	defer store.Close()
+	address := common.Address{0x03}
+	key := &states.StorageKey{ContractAddress: address, Key: []byte("key")}
+	height := STATE_ROOT_KEEP_BLOCKS + 10
+	for h := uint32(0); h <= height; h++ {
+		store.NewBatch()
+		batch := store.NewStateBatch()
+		batch.TryAdd(scommon.ST_STORAGE, append(address[:], key.Key...), &states.StorageItem{Value: []byte(fmt.Sprintf("value%d", h))})
+		batch.TryAdd(scommon.ST_STORAGE, append(address[:], []byte(fmt.Sprintf("key%d", h%3))...), &states.StorageItem{Value: []byte("value")})
+		err = store.AddStateRoot(h, batch)
+		if err != nil {
+			t.Errorf("AddStateRoot error %s", err)
+			return
+		}
+		err = batch.CommitTo()
+		if err != nil {
+			t.Errorf("batch.CommitTo error %s", err)
+			return
+		}
+		err = store.CommitTo()
+		if err != nil {
+			t.Errorf("store.CommitTo error %s", err)
+			return
+		}
+	}
+	prunedHeight, err := store.GetStateRootPrunedHeight()
+	if err != nil {
+		t.Errorf("GetStateRootPrunedHeight error %s", err)
+		return
+	}
+	if expect := height - STATE_ROOT_KEEP_BLOCKS + 1; prunedHeight != expect {
+		t.Errorf("TestReleaseStateRoots pruned height %d != %d", prunedHeight, expect)
+		return
+	}
+	if _, _, err = store.GetStorageProof(key, prunedHeight-1); err == nil {
+		t.Errorf("GetStorageProof of released root should fail")
+		return
+	}
+	for h := prunedHeight; h <= height; h++ {
+		value, _, err := store.GetStorageProof(key, h)
+		if err != nil {
+			t.Errorf("GetStorageProof of height %d error %s", h, err)
+			return
+		}
+		if expect := fmt.Sprintf("value%d", h); string(value) != expect {
+			t.Errorf("TestReleaseStateRoots value of height %d %s != %s", h, value, expect)
+			return
+		}
+	}
+}
+
 func getStateBatch() (*statestore.StateBatch, error) {
 	testStateStore.NewBatch()
 	batch := testStateStore.NewStateBatch()
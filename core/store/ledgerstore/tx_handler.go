@@ -39,6 +39,7 @@ import (
 	"fmt"
 	"math"
 	"strconv"
+	"sync"
 
 	"github.com/imZhuFei/zeepin/common"
 	"github.com/imZhuFei/zeepin/common/config"
@@ -59,6 +60,7 @@ import (
 	ninit "github.com/imZhuFei/zeepin/smartcontract/service/native/init"
 	"github.com/imZhuFei/zeepin/smartcontract/service/native/utils"
 	"github.com/imZhuFei/zeepin/smartcontract/service/native/zpt"
+	"github.com/imZhuFei/zeepin/smartcontract/service/wasmvm"
 	"github.com/imZhuFei/zeepin/smartcontract/storage"
 )
 
@@ -116,6 +118,12 @@ func (self *StateStore) HandleDeployTransaction(store store.LedgerStore, stateBa
 		cache.Commit()
 	}
 
+	if err := wasmvm.VerifyCode(deploy.Code, block.Header.Height); err != nil {
+		notify.Notify = append(notify.Notify, notifies...)
+		notify.GasConsumed = gasConsumed
+		return err
+	}
+
 	log.Infof("deploy contract address:%s", address.ToHexString())
 	// store contract message
 	err = stateBatch.TryGetOrAdd(scommon.ST_CONTRACT, address[:], deploy)
@@ -311,10 +319,11 @@ func chargeCostGas(payer common.Address, gas uint64, config *smartcontract.Confi
 
 func refreshGlobalParam(config *smartcontract.Config, cache *storage.CloneCache, store store.LedgerStore) error {
 	bf := new(bytes.Buffer)
-	if err := utils.WriteVarUint(bf, uint64(len(embed.GAS_TABLE_KEYS))); err != nil {
+	keys := append(append([]string{}, embed.GAS_TABLE_KEYS...), wasmvm.WASM_GAS_TABLE_KEYS...)
+	if err := utils.WriteVarUint(bf, uint64(len(keys))); err != nil {
 		return fmt.Errorf("write gas_table_keys length error:%s", err)
 	}
-	for _, value := range embed.GAS_TABLE_KEYS {
+	for _, value := range keys {
 		if err := serialization.WriteString(bf, value); err != nil {
 			return fmt.Errorf("serialize param name error:%s", value)
 		}
@@ -336,20 +345,25 @@ func refreshGlobalParam(config *smartcontract.Config, cache *storage.CloneCache,
 	if err := params.Deserialize(bytes.NewBuffer(result.([]byte))); err != nil {
 		return fmt.Errorf("deserialize global params error:%s", err)
 	}
-	embed.GAS_TABLE.Range(func(key, value interface{}) bool {
+	refreshGasTable(embed.GAS_TABLE, params)
+	refreshGasTable(wasmvm.WASM_GAS_TABLE, params)
+	return nil
+}
+
+func refreshGasTable(table *sync.Map, params *global_params.Params) {
+	table.Range(func(key, value interface{}) bool {
 		n, ps := params.GetParam(key.(string))
 		if n != -1 && ps.Value != "" {
 			pu, err := strconv.ParseUint(ps.Value, 10, 64)
 			if err != nil {
 				log.Errorf("[refreshGlobalParam] failed to parse uint %v\n", ps.Value)
 			} else {
-				embed.GAS_TABLE.Store(key, pu)
+				table.Store(key, pu)
 
 			}
 		}
 		return true
 	})
-	return nil
 }
 
 func getBalanceFromNative(config *smartcontract.Config, cache *storage.CloneCache, store store.LedgerStore, address common.Address) (uint64, error) {
@@ -130,6 +130,24 @@ func (self *LevelDBStore) BatchCommit() error {
 	return nil
 }
 
+//BatchData return the encoded operations in batch
+func (self *LevelDBStore) BatchData() []byte {
+	if self.batch == nil {
+		return nil
+	}
+	return self.batch.Dump()
+}
+
+//CommitBatchData commit the operations encoded by BatchData to leveldb, and sync them to disk
+func (self *LevelDBStore) CommitBatchData(data []byte) error {
+	batch := new(leveldb.Batch)
+	err := batch.Load(data)
+	if err != nil {
+		return err
+	}
+	return self.db.Write(batch, &opt.WriteOptions{Sync: true})
+}
+
 //Close leveldb
 func (self *LevelDBStore) Close() error {
 	err := self.db.Close()
@@ -37,7 +37,7 @@ package statestore
 import (
 	"bytes"
 	"fmt"
-
+	"sort"
 	"strings"
 
 	"github.com/imZhuFei/zeepin/core/payload"
@@ -85,6 +85,34 @@ func (self *StateBatch) Find(prefix common.DataEntryPrefix, key []byte) ([]*comm
 	return sts, nil
 }
 
+//Iterate call fn with the items whose key start with key in key order, until fn return error.
+//The items in store are read lazily, and the items changed in batch take their place
+func (self *StateBatch) Iterate(prefix common.DataEntryPrefix, key []byte, fn func(item *common.StateItem) error) error {
+	bp := []byte{byte(prefix)}
+	keyP := string(append(bp, key...))
+	var overlay []*common.StateItem
+	for k, v := range self.memoryStore.GetChangeSet() {
+		if strings.HasPrefix(k, keyP) {
+			overlay = append(overlay, v.Copy())
+		}
+	}
+	sort.Slice(overlay, func(i, j int) bool { return overlay[i].Key < overlay[j].Key })
+	return common.OverlayIterate(overlay, func(fn func(item *common.StateItem) error) error {
+		iter := self.store.NewIterator(append(bp, key...))
+		defer iter.Release()
+		for iter.Next() {
+			state, err := getStateObject(prefix, iter.Value())
+			if err != nil {
+				return err
+			}
+			if err := fn(&common.StateItem{Key: string(iter.Key()[1:]), Value: state}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, fn)
+}
+
 func (self *StateBatch) TryAdd(prefix common.DataEntryPrefix, key []byte, value states.StateValue) {
 	self.setStateObject(byte(prefix), key, value, common.Changed)
 }
@@ -164,6 +192,11 @@ func (self *StateBatch) CommitTo() error {
 	return nil
 }
 
+//GetChangeSet return the items changed in batch, which are keyed by prefix and key
+func (self *StateBatch) GetChangeSet() map[string]*common.StateItem {
+	return self.memoryStore.GetChangeSet()
+}
+
 func (self *StateBatch) setStateObject(prefix byte, key []byte, value states.StateValue, state common.ItemState) {
 	self.memoryStore.Put(prefix, key, value, state)
 }
@@ -199,6 +232,12 @@ func getStateObject(prefix common.DataEntryPrefix, enc []byte) (states.StateValu
 			return nil, err
 		}
 		return storage, nil
+	case common.ST_EVENT_SCHEMA:
+		schemas := new(states.EventSchemaState)
+		if err := schemas.Deserialize(reader); err != nil {
+			return nil, err
+		}
+		return schemas, nil
 	default:
 		panic("[getStateObject] invalid state type!")
 	}
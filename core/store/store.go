@@ -38,9 +38,12 @@ import (
 	"github.com/imZhuFei/zeepin/common"
 	"github.com/imZhuFei/zeepin/core/payload"
 	"github.com/imZhuFei/zeepin/core/states"
+	scom "github.com/imZhuFei/zeepin/core/store/common"
 	"github.com/imZhuFei/zeepin/core/types"
+	"github.com/imZhuFei/zeepin/merkle"
 	"github.com/imZhuFei/zeepin/smartcontract/event"
 	cstates "github.com/imZhuFei/zeepin/smartcontract/states"
+	"github.com/imZhuFei/zeepin/smartcontract/trace"
 	"github.com/ontio/ontology-crypto/keypair"
 )
 
@@ -64,10 +67,18 @@ type LedgerStore interface {
 	IsContainTransaction(txHash common.Uint256) (bool, error)
 	GetBlockRootWithNewTxRoot(txRoot common.Uint256) common.Uint256
 	GetMerkleProof(m, n uint32) ([]common.Uint256, error)
+	GetStateRoot(height uint32) (common.Uint256, error)
+	GetStorageProof(key *states.StorageKey, height uint32) ([]byte, *merkle.SparseMerkleProof, error)
 	GetContractState(contractHash common.Address) (*payload.DeployCode, error)
 	GetBookkeeperState() (*states.BookkeeperState, error)
 	GetStorageItem(key *states.StorageKey) (*states.StorageItem, error)
+	GetStorageItemAt(key *states.StorageKey, height uint32) (*states.StorageItem, error)
+	GetEventSchemas(contractHash common.Address) (*states.EventSchemaState, error)
 	PreExecuteContract(tx *types.Transaction) (*cstates.PreExecResult, error)
+	PreExecuteContractAt(tx *types.Transaction, height uint32) (*cstates.PreExecResult, error)
+	TraceContract(tx *types.Transaction, maxSteps int) (*trace.Trace, error)
 	GetEventNotifyByTx(tx common.Uint256) (*event.ExecuteNotify, error)
 	GetEventNotifyByBlock(height uint32) ([]*event.ExecuteNotify, error)
+	GetTransactionsByAddress(addr common.Address, start []byte, limit uint32) ([]*scom.AddressTx, []byte, error)
+	GetTransfersByAddress(addr common.Address, start []byte, limit uint32) ([]*scom.AddressTransfer, []byte, error)
 }
@@ -93,3 +93,29 @@ func BuildNativeTransaction(addr common.Address, initMethod string, args []byte)
 	tx.GasLimit = math.MaxUint64
 	return tx
 }
+
+// BuildNativeStructTransaction returns a native invoke Transaction whose args is a struct of fields,
+// each field is passed to the native contract as var bytes
+func BuildNativeStructTransaction(addr common.Address, initMethod string, fields ...[]byte) *types.MutableTransaction {
+	bf := new(bytes.Buffer)
+	builder := vm.NewParamsBuilder(bf)
+	builder.EmitPushInteger(big.NewInt(0))
+	builder.Emit(vm.NEWSTRUCT)
+	builder.Emit(vm.TOALTSTACK)
+	for _, field := range fields {
+		builder.EmitPushByteArray(field)
+		builder.Emit(vm.DUPFROMALTSTACK)
+		builder.Emit(vm.SWAP)
+		builder.Emit(vm.APPEND)
+	}
+	builder.Emit(vm.FROMALTSTACK)
+	builder.EmitPushByteArray([]byte(initMethod))
+	builder.EmitPushByteArray(addr[:])
+	builder.EmitPushInteger(big.NewInt(0))
+	builder.Emit(vm.SYSCALL)
+	builder.EmitPushByteArray([]byte(embed.NATIVE_INVOKE_NAME))
+
+	tx := NewInvokeTransaction(builder.ToArray())
+	tx.GasLimit = math.MaxUint64
+	return tx
+}
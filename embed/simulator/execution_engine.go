@@ -55,6 +55,7 @@ type ExecutionEngine struct {
 	Context         *ExecutionContext
 	OpCode          OpCode
 	OpExec          OpExec
+	Tracer          Tracer
 }
 
 func (this *ExecutionEngine) CurrentContext() *ExecutionContext {
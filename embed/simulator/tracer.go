@@ -0,0 +1,53 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package simulator
+
+import "fmt"
+
+// Tracer receives the opcodes and system calls executed by the engine,
+// it is set only when debugging contracts
+type Tracer interface {
+	CaptureStep(engine *ExecutionEngine, gas uint64)
+	CaptureSyscall(engine *ExecutionEngine, name string, gas uint64)
+}
+
+// TraceStep notify the tracer before current opcode is executed
+func (this *ExecutionEngine) TraceStep(gas uint64) {
+	if this.Tracer != nil {
+		this.Tracer.CaptureStep(this, gas)
+	}
+}
+
+// TraceSyscall notify the tracer before the system call is executed
+func (this *ExecutionEngine) TraceSyscall(name string, gas uint64) {
+	if this.Tracer != nil {
+		this.Tracer.CaptureSyscall(this, name, gas)
+	}
+}
+
+// OpName return the name of opcode
+func OpName(op OpCode) string {
+	if op >= PUSHBYTES1 && op <= PUSHBYTES75 {
+		return fmt.Sprintf("PUSHBYTES%d", op)
+	}
+	if name := OpExecList[op].Name; name != "" {
+		return name
+	}
+	return fmt.Sprintf("0x%02x", byte(op))
+}
@@ -38,9 +38,13 @@ import (
 	"github.com/imZhuFei/zeepin/common"
 	"github.com/imZhuFei/zeepin/core/ledger"
 	"github.com/imZhuFei/zeepin/core/payload"
+	"github.com/imZhuFei/zeepin/core/states"
+	scom "github.com/imZhuFei/zeepin/core/store/common"
 	"github.com/imZhuFei/zeepin/core/types"
+	"github.com/imZhuFei/zeepin/merkle"
 	"github.com/imZhuFei/zeepin/smartcontract/event"
 	cstate "github.com/imZhuFei/zeepin/smartcontract/states"
+	"github.com/imZhuFei/zeepin/smartcontract/trace"
 )
 
 const (
@@ -88,11 +92,21 @@ func GetStorageItem(address common.Address, key []byte) ([]byte, error) {
 	return ledger.DefLedger.GetStorageItem(address, key)
 }
 
+//GetStorageItemAt from ledger
+func GetStorageItemAt(address common.Address, key []byte, height uint32) ([]byte, error) {
+	return ledger.DefLedger.GetStorageItemAt(address, key, height)
+}
+
 //GetContractStateFromStore from ledger
 func GetContractStateFromStore(hash common.Address) (*payload.DeployCode, error) {
 	return ledger.DefLedger.GetContractState(hash)
 }
 
+//GetEventSchemas from ledger
+func GetEventSchemas(hash common.Address) (*states.EventSchemaState, error) {
+	return ledger.DefLedger.GetEventSchemas(hash)
+}
+
 //GetTxnWithHeightByTxHash from ledger
 func GetTxnWithHeightByTxHash(hash common.Uint256) (uint32, *types.Transaction, error) {
 	tx, height, err := ledger.DefLedger.GetTransactionWithHeight(hash)
@@ -104,6 +118,16 @@ func PreExecuteContract(tx *types.Transaction) (*cstate.PreExecResult, error) {
 	return ledger.DefLedger.PreExecuteContract(tx)
 }
 
+//PreExecuteContractAt from ledger
+func PreExecuteContractAt(tx *types.Transaction, height uint32) (*cstate.PreExecResult, error) {
+	return ledger.DefLedger.PreExecuteContractAt(tx, height)
+}
+
+//TraceContract from ledger
+func TraceContract(tx *types.Transaction, maxSteps int) (*trace.Trace, error) {
+	return ledger.DefLedger.TraceContract(tx, maxSteps)
+}
+
 //GetEventNotifyByTxHash from ledger
 func GetEventNotifyByTxHash(txHash common.Uint256) (*event.ExecuteNotify, error) {
 	return ledger.DefLedger.GetEventNotifyByTx(txHash)
@@ -114,7 +138,27 @@ func GetEventNotifyByHeight(height uint32) ([]*event.ExecuteNotify, error) {
 	return ledger.DefLedger.GetEventNotifyByBlock(height)
 }
 
+//GetTransactionsByAddress from ledger
+func GetTransactionsByAddress(addr common.Address, start []byte, limit uint32) ([]*scom.AddressTx, []byte, error) {
+	return ledger.DefLedger.GetTransactionsByAddress(addr, start, limit)
+}
+
+//GetTransfersByAddress from ledger
+func GetTransfersByAddress(addr common.Address, start []byte, limit uint32) ([]*scom.AddressTransfer, []byte, error) {
+	return ledger.DefLedger.GetTransfersByAddress(addr, start, limit)
+}
+
 //GetMerkleProof from ledger
 func GetMerkleProof(proofHeight uint32, rootHeight uint32) ([]common.Uint256, error) {
 	return ledger.DefLedger.GetMerkleProof(proofHeight, rootHeight)
 }
+
+//GetStateRoot from ledger
+func GetStateRoot(height uint32) (common.Uint256, error) {
+	return ledger.DefLedger.GetStateRoot(height)
+}
+
+//GetStorageProof from ledger
+func GetStorageProof(address common.Address, key []byte, height uint32) ([]byte, *merkle.SparseMerkleProof, error) {
+	return ledger.DefLedger.GetStorageProof(address, key, height)
+}
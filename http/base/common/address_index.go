@@ -0,0 +1,100 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package common
+
+import (
+	"encoding/hex"
+
+	"github.com/imZhuFei/zeepin/common"
+	bactor "github.com/imZhuFei/zeepin/http/base/actor"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/utils"
+)
+
+type AddressTxInfo struct {
+	TxHash string
+	Height uint32
+}
+
+type AddressTxsInfo struct {
+	Address      string
+	Transactions []*AddressTxInfo
+	Next         string
+}
+
+type TransferInfo struct {
+	TxHash   string
+	Height   uint32
+	Asset    string
+	Contract string
+	From     string
+	To       string
+	Amount   uint64
+}
+
+type TransfersInfo struct {
+	Address   string
+	Transfers []*TransferInfo
+	Next      string
+}
+
+// GetTransactionsByAddress returns a page of the transactions touching an address, newest first
+func GetTransactionsByAddress(addr common.Address, start []byte, limit uint32) (*AddressTxsInfo, error) {
+	txs, next, err := bactor.GetTransactionsByAddress(addr, start, limit)
+	if err != nil {
+		return nil, err
+	}
+	info := &AddressTxsInfo{
+		Address:      addr.ToBase58(),
+		Transactions: make([]*AddressTxInfo, 0, len(txs)),
+		Next:         hex.EncodeToString(next),
+	}
+	for _, tx := range txs {
+		info.Transactions = append(info.Transactions, &AddressTxInfo{TxHash: tx.TxHash.ToHexString(), Height: tx.Height})
+	}
+	return info, nil
+}
+
+// GetTransfersByAddress returns a page of the zpt and gala transfers touching an address, newest first
+func GetTransfersByAddress(addr common.Address, start []byte, limit uint32) (*TransfersInfo, error) {
+	transfers, next, err := bactor.GetTransfersByAddress(addr, start, limit)
+	if err != nil {
+		return nil, err
+	}
+	info := &TransfersInfo{
+		Address:   addr.ToBase58(),
+		Transfers: make([]*TransferInfo, 0, len(transfers)),
+		Next:      hex.EncodeToString(next),
+	}
+	for _, transfer := range transfers {
+		asset := "zpt"
+		if transfer.Contract == utils.GalaContractAddress {
+			asset = "gala"
+		}
+		info.Transfers = append(info.Transfers, &TransferInfo{
+			TxHash:   transfer.TxHash.ToHexString(),
+			Height:   transfer.Height,
+			Asset:    asset,
+			Contract: transfer.Contract.ToHexString(),
+			From:     transfer.From.ToBase58(),
+			To:       transfer.To.ToBase58(),
+			Amount:   transfer.Amount,
+		})
+	}
+	return info, nil
+}
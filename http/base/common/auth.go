@@ -0,0 +1,141 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package common
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/auth"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/utils"
+)
+
+type RoleHolderInfo struct {
+	GID        string
+	Level      uint64
+	ExpireTime uint64
+	Delegator  string `json:",omitempty"`
+}
+
+type RoleAuthInfo struct {
+	Role      string
+	FuncNames []string
+	Holders   []*RoleHolderInfo
+}
+
+type DelegationInfo struct {
+	Role string
+	RoleHolderInfo
+}
+
+type ContractAuthInfo struct {
+	ContractAddress string
+	Admin           string
+	Roles           []*RoleAuthInfo
+	Delegations     []*DelegationInfo
+}
+
+func getRoleHolders(method string, params []interface{}) ([]*auth.RoleHolder, error) {
+	data, err := preExecNativeContract(utils.AuthContractAddress, method, params)
+	if err != nil {
+		return nil, err
+	}
+	list := new(auth.RoleHolderList)
+	if err := list.Deserialize(bytes.NewBuffer(data)); err != nil {
+		return nil, fmt.Errorf("deserialize role holders error:%s", err)
+	}
+	return list.Holders, nil
+}
+
+func newRoleHolderInfo(holder *auth.RoleHolder) RoleHolderInfo {
+	return RoleHolderInfo{
+		GID:        string(holder.GID),
+		Level:      holder.Level,
+		ExpireTime: holder.ExpireTime,
+		Delegator:  string(holder.Delegator),
+	}
+}
+
+// GetContractAuth returns the admin, roles, role holders and active delegations of a contract,
+// only the roles allowed to call fn and their delegations are returned if fn is not empty
+func GetContractAuth(contractAddr common.Address, fn string) (*ContractAuthInfo, error) {
+	admin, err := preExecNativeContract(utils.AuthContractAddress, "getContractAdmin", []interface{}{contractAddr[:]})
+	if err != nil {
+		return nil, err
+	}
+	data, err := preExecNativeContract(utils.AuthContractAddress, "getRoles", []interface{}{contractAddr[:]})
+	if err != nil {
+		return nil, err
+	}
+	roles := new(auth.RoleList)
+	if err := roles.Deserialize(bytes.NewBuffer(data)); err != nil {
+		return nil, fmt.Errorf("deserialize roles error:%s", err)
+	}
+	info := &ContractAuthInfo{
+		ContractAddress: contractAddr.ToHexString(),
+		Admin:           string(admin),
+		Roles:           make([]*RoleAuthInfo, 0, len(roles.Roles)),
+		Delegations:     make([]*DelegationInfo, 0),
+	}
+	selected := make([]string, 0, len(roles.Roles))
+	for _, role := range roles.Roles {
+		if fn != "" && !containsString(role.FuncNames, fn) {
+			continue
+		}
+		selected = append(selected, string(role.Role))
+		holders, err := getRoleHolders("getRoleGIDs", []interface{}{EmbeddedStruct{contractAddr[:], role.Role}})
+		if err != nil {
+			return nil, err
+		}
+		roleInfo := &RoleAuthInfo{
+			Role:      string(role.Role),
+			FuncNames: role.FuncNames,
+			Holders:   make([]*RoleHolderInfo, 0, len(holders)),
+		}
+		for _, holder := range holders {
+			holderInfo := newRoleHolderInfo(holder)
+			roleInfo.Holders = append(roleInfo.Holders, &holderInfo)
+		}
+		info.Roles = append(info.Roles, roleInfo)
+	}
+	delegations, err := getRoleHolders("getDelegations", []interface{}{contractAddr[:]})
+	if err != nil {
+		return nil, err
+	}
+	for _, delegation := range delegations {
+		if !containsString(selected, string(delegation.Role)) {
+			continue
+		}
+		info.Delegations = append(info.Delegations, &DelegationInfo{
+			Role:           string(delegation.Role),
+			RoleHolderInfo: newRoleHolderInfo(delegation),
+		})
+	}
+	return info, nil
+}
+
+func containsString(s []string, str string) bool {
+	for _, v := range s {
+		if v == str {
+			return true
+		}
+	}
+	return false
+}
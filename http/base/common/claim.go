@@ -0,0 +1,109 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package common
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/imZhuFei/zeepin/common"
+	bactor "github.com/imZhuFei/zeepin/http/base/actor"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/claim"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/utils"
+)
+
+type ClaimInfo struct {
+	ClaimHash string
+	Issuer    string
+	Subject   string
+	Status    string
+	IssuedAt  uint64
+	Expiry    uint64
+	RevokedAt uint64
+}
+
+type ClaimListInfo struct {
+	ClaimHashes []string
+	Next        string
+}
+
+// preExecNativeContract returns the result of a native contract method pre-executed against current state
+func preExecNativeContract(contractAddr common.Address, method string, params []interface{}) ([]byte, error) {
+	mutable, err := NewNativeInvokeTransaction(0, 0, contractAddr, 0, method, params)
+	if err != nil {
+		return nil, fmt.Errorf("NewNativeInvokeTransaction error:%s", err)
+	}
+	tx, err := mutable.IntoImmutable()
+	if err != nil {
+		return nil, err
+	}
+	result, err := bactor.PreExecuteContract(tx)
+	if err != nil {
+		return nil, fmt.Errorf("PrepareInvokeContract error:%s", err)
+	}
+	if result.State == 0 {
+		return nil, fmt.Errorf("prepare invoke failed")
+	}
+	data, err := hex.DecodeString(result.Result.(string))
+	if err != nil {
+		return nil, fmt.Errorf("hex.DecodeString error:%s", err)
+	}
+	return data, nil
+}
+
+func GetClaim(claimHash []byte) (*ClaimInfo, error) {
+	data, err := preExecNativeContract(utils.ClaimContractAddress, claim.GETCLAIM_NAME, []interface{}{claimHash})
+	if err != nil {
+		return nil, err
+	}
+	c := new(claim.Claim)
+	if err := c.Deserialize(bytes.NewBuffer(data)); err != nil {
+		return nil, fmt.Errorf("deserialize claim error:%s", err)
+	}
+	return &ClaimInfo{
+		ClaimHash: hex.EncodeToString(c.ClaimHash),
+		Issuer:    string(c.Issuer),
+		Subject:   string(c.Subject),
+		Status:    claim.StatusString(c.Status),
+		IssuedAt:  c.IssuedAt,
+		Expiry:    c.Expiry,
+		RevokedAt: c.RevokedAt,
+	}, nil
+}
+
+func GetClaimsOf(subject, start []byte, limit uint64) (*ClaimListInfo, error) {
+	data, err := preExecNativeContract(utils.ClaimContractAddress, claim.CLAIMSOF_NAME,
+		[]interface{}{EmbeddedStruct{subject, start, limit}})
+	if err != nil {
+		return nil, err
+	}
+	list := new(claim.ClaimList)
+	if err := list.Deserialize(bytes.NewBuffer(data)); err != nil {
+		return nil, fmt.Errorf("deserialize claim list error:%s", err)
+	}
+	info := &ClaimListInfo{
+		ClaimHashes: make([]string, 0, len(list.ClaimHashes)),
+		Next:        hex.EncodeToString(list.Next),
+	}
+	for _, hash := range list.ClaimHashes {
+		info.ClaimHashes = append(info.ClaimHashes, hex.EncodeToString(hash))
+	}
+	return info, nil
+}
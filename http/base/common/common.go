@@ -43,6 +43,7 @@ import (
 	"fmt"
 	"math/big"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -51,6 +52,7 @@ import (
 	"github.com/imZhuFei/zeepin/common/log"
 	"github.com/imZhuFei/zeepin/common/serialization"
 	"github.com/imZhuFei/zeepin/core/payload"
+	"github.com/imZhuFei/zeepin/core/states"
 	"github.com/imZhuFei/zeepin/core/types"
 	"github.com/imZhuFei/zeepin/embed/simulator"
 	ontErrors "github.com/imZhuFei/zeepin/errors"
@@ -60,6 +62,7 @@ import (
 	"github.com/imZhuFei/zeepin/smartcontract/service/native/utils"
 	"github.com/imZhuFei/zeepin/smartcontract/service/wasmvm"
 	cstates "github.com/imZhuFei/zeepin/smartcontract/states"
+	wasmabi "github.com/imZhuFei/zeepin/vm/wasmvm/abi"
 	"github.com/imZhuFei/zeepin/vm/wasmvm/exec"
 	"github.com/ontio/ontology-crypto/keypair"
 )
@@ -80,6 +83,17 @@ type MerkleProof struct {
 	TargetHashes     []string
 }
 
+type StorageProof struct {
+	ContractAddress string
+	Key             string
+	Value           string
+	BlockHeight     uint32
+	StateRoot       string
+	Siblings        []string
+	LeafKeyHash     string
+	LeafValueHash   string
+}
+
 type LogEventArgs struct {
 	TxHash          string
 	ContractAddress string
@@ -96,6 +110,8 @@ type ExecuteNotify struct {
 type NotifyEventInfo struct {
 	ContractAddress string
 	States          interface{}
+	EventName       string                 `json:",omitempty"` //decoded by the event schema registered by contract
+	Fields          map[string]interface{} `json:",omitempty"`
 }
 
 type TxAttributeInfo struct {
@@ -193,8 +209,21 @@ func GetLogEvent(obj *event.LogEventArgs) (map[string]bool, LogEventArgs) {
 func GetExecuteNotify(obj *event.ExecuteNotify) (map[string]bool, ExecuteNotify) {
 	evts := []NotifyEventInfo{}
 	var contractAddrs = make(map[string]bool)
+	schemas := make(map[common.Address]*states.EventSchemaState)
 	for _, v := range obj.Notify {
-		evts = append(evts, NotifyEventInfo{v.ContractAddress.ToHexString(), v.States})
+		evt := NotifyEventInfo{ContractAddress: v.ContractAddress.ToHexString(), States: v.States}
+		schema, ok := schemas[v.ContractAddress]
+		if !ok {
+			schema, _ = bactor.GetEventSchemas(v.ContractAddress)
+			schemas[v.ContractAddress] = schema
+		}
+		if schema != nil {
+			if name, fields, err := event.DecodeNotify(schema, v.States); err == nil {
+				evt.EventName = name
+				evt.Fields = fields
+			}
+		}
+		evts = append(evts, evt)
 		contractAddrs[v.ContractAddress.ToHexString()] = true
 	}
 	txhash := obj.TxHash.ToHexString()
@@ -280,12 +309,12 @@ func GetBlockInfo(block *types.Block) BlockInfo {
 	return b
 }
 
-func GetBalance(address common.Address) (*BalanceOfRsp, error) {
-	zpt, err := GetContractBalance(0, utils.ZptContractAddress, address)
+func GetBalance(address common.Address, height uint32) (*BalanceOfRsp, error) {
+	zpt, err := GetContractBalance(0, utils.ZptContractAddress, address, height)
 	if err != nil {
 		return nil, fmt.Errorf("get zpt balance error:%s", err)
 	}
-	gala, err := GetContractBalance(0, utils.GalaContractAddress, address)
+	gala, err := GetContractBalance(0, utils.GalaContractAddress, address, height)
 	if err != nil {
 		return nil, fmt.Errorf("get gala balance error:%s", err)
 	}
@@ -295,7 +324,7 @@ func GetBalance(address common.Address) (*BalanceOfRsp, error) {
 	}, nil
 }
 
-func GetAllowance(asset string, from, to common.Address) (string, error) {
+func GetAllowance(asset string, from, to common.Address, height uint32) (string, error) {
 	var contractAddr common.Address
 	switch strings.ToLower(asset) {
 	case "zpt":
@@ -305,14 +334,14 @@ func GetAllowance(asset string, from, to common.Address) (string, error) {
 	default:
 		return "", fmt.Errorf("unsupport asset")
 	}
-	allowance, err := GetContractAllowance(0, contractAddr, from, to)
+	allowance, err := GetContractAllowance(0, contractAddr, from, to, height)
 	if err != nil {
 		return "", fmt.Errorf("get allowance error:%s", err)
 	}
 	return fmt.Sprintf("%v", allowance), nil
 }
 
-func GetContractBalance(cVersion byte, contractAddr, accAddr common.Address) (uint64, error) {
+func GetContractBalance(cVersion byte, contractAddr, accAddr common.Address, height uint32) (uint64, error) {
 	mutable, err := NewNativeInvokeTransaction(0, 0, contractAddr, cVersion, "balanceOf", []interface{}{accAddr[:]})
 	if err != nil {
 		return 0, fmt.Errorf("NewNativeInvokeTransaction error:%s", err)
@@ -321,7 +350,7 @@ func GetContractBalance(cVersion byte, contractAddr, accAddr common.Address) (ui
 	if err != nil {
 		return 0, err
 	}
-	result, err := bactor.PreExecuteContract(tx)
+	result, err := bactor.PreExecuteContractAt(tx, height)
 	if err != nil {
 		return 0, fmt.Errorf("PrepareInvokeContract error:%s", err)
 	}
@@ -337,7 +366,7 @@ func GetContractBalance(cVersion byte, contractAddr, accAddr common.Address) (ui
 	return balance.Uint64(), nil
 }
 
-func GetContractAllowance(cVersion byte, contractAddr, fromAddr, toAddr common.Address) (uint64, error) {
+func GetContractAllowance(cVersion byte, contractAddr, fromAddr, toAddr common.Address, height uint32) (uint64, error) {
 	type allowanceStruct struct {
 		From common.Address
 		To   common.Address
@@ -354,7 +383,7 @@ func GetContractAllowance(cVersion byte, contractAddr, fromAddr, toAddr common.A
 	if err != nil {
 		return 0, err
 	}
-	result, err := bactor.PreExecuteContract(tx)
+	result, err := bactor.PreExecuteContractAt(tx, height)
 	if err != nil {
 		return 0, fmt.Errorf("PrepareInvokeContract error:%s", err)
 	}
@@ -369,6 +398,33 @@ func GetContractAllowance(cVersion byte, contractAddr, fromAddr, toAddr common.A
 	return allowance.Uint64(), nil
 }
 
+//GetStorageProof return the storage value after the block of height is executed and its proof against the state root,
+//which is committed in the consensus payload of the next block
+func GetStorageProof(address common.Address, key []byte, height uint32) (*StorageProof, error) {
+	stateRoot, err := bactor.GetStateRoot(height)
+	if err != nil {
+		return nil, fmt.Errorf("get state root of height %d error:%s", height, err)
+	}
+	value, proof, err := bactor.GetStorageProof(address, key, height)
+	if err != nil {
+		return nil, err
+	}
+	rsp := &StorageProof{
+		ContractAddress: address.ToHexString(),
+		Key:             common.ToHexString(key),
+		Value:           common.ToHexString(value),
+		BlockHeight:     height,
+		StateRoot:       stateRoot.ToHexString(),
+		Siblings:        make([]string, 0, len(proof.Siblings)),
+		LeafKeyHash:     proof.LeafKeyHash.ToHexString(),
+		LeafValueHash:   proof.LeafValueHash.ToHexString(),
+	}
+	for _, sibling := range proof.Siblings {
+		rsp.Siblings = append(rsp.Siblings, sibling.ToHexString())
+	}
+	return rsp, nil
+}
+
 func GetGasPrice() (map[string]interface{}, error) {
 	start := bactor.GetCurrentBlockHeight()
 	var gasPrice uint64 = 0
@@ -484,6 +540,9 @@ func BuildEmbeddedInvokeCode(smartContractAddress common.Address, params []inter
 	return args, nil
 }
 
+//EmbeddedStruct is an invoke param which is built as embedded vm struct instead of array
+type EmbeddedStruct []interface{}
+
 //buildEmbeddedParamInter build embed invoke param code
 func BuildEmbeddedParam(builder *simulator.ParamsBuilder, smartContractParams []interface{}) error {
 	//VM load params in reverse order
@@ -525,6 +584,39 @@ func BuildEmbeddedParam(builder *simulator.ParamsBuilder, smartContractParams []
 			}
 			builder.EmitPushInteger(big.NewInt(int64(len(v))))
 			builder.Emit(simulator.PACK)
+		case EmbeddedStruct:
+			builder.EmitPushInteger(big.NewInt(0))
+			builder.Emit(simulator.NEWSTRUCT)
+			builder.Emit(simulator.TOALTSTACK)
+			for _, field := range v {
+				err := BuildEmbeddedParam(builder, []interface{}{field})
+				if err != nil {
+					return err
+				}
+				builder.Emit(simulator.DUPFROMALTSTACK)
+				builder.Emit(simulator.SWAP)
+				builder.Emit(simulator.APPEND)
+			}
+			builder.Emit(simulator.FROMALTSTACK)
+		case map[string]interface{}:
+			//keys are sorted to keep invoke code deterministic
+			keys := make([]string, 0, len(v))
+			for key := range v {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			builder.Emit(simulator.NEWMAP)
+			builder.Emit(simulator.TOALTSTACK)
+			for _, key := range keys {
+				builder.Emit(simulator.DUPFROMALTSTACK)
+				builder.EmitPushByteArray([]byte(key))
+				err := BuildEmbeddedParam(builder, []interface{}{v[key]})
+				if err != nil {
+					return err
+				}
+				builder.Emit(simulator.SETITEM)
+			}
+			builder.Emit(simulator.FROMALTSTACK)
 		default:
 			object := reflect.ValueOf(v)
 			kind := object.Kind().String()
@@ -639,6 +731,8 @@ func buildWasmContractParam(params []interface{}, paramType wasmvm.ParamType) ([
 			}
 		}
 		return bf.Bytes(), nil
+	case wasmvm.Binary:
+		return wasmabi.Encode(params)
 	default:
 		return nil, fmt.Errorf("unsupported type")
 	}
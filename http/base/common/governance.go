@@ -0,0 +1,117 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package common
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/governance"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/utils"
+)
+
+type PeerStakeInfo struct {
+	PeerPubkey          string
+	InitPos             uint64
+	ConsensusPos        uint64
+	FreezePos           uint64
+	NewPos              uint64
+	WithdrawPos         uint64
+	WithdrawFreezePos   uint64
+	WithdrawUnfreezePos uint64
+	UnlockHeight        uint32
+}
+
+type StakeInfo struct {
+	Address        string
+	View           uint32
+	NextViewHeight uint32
+	TotalStake     uint64
+	UnboundGala    uint64
+	Pending        uint64
+	Withdrawable   uint64
+	Peers          []*PeerStakeInfo
+}
+
+type SplitPayoutInfo struct {
+	View   uint32
+	Amount uint64
+}
+
+type SplitPayoutsInfo struct {
+	Address string
+	Payouts []*SplitPayoutInfo
+}
+
+// GetStakeInfo returns the stakes per peer, the pending and withdrawable zpt and the unbound gala of an address
+func GetStakeInfo(addr common.Address) (*StakeInfo, error) {
+	data, err := preExecNativeContract(utils.GovernanceContractAddress, governance.GET_STAKE_INFO, []interface{}{addr[:]})
+	if err != nil {
+		return nil, err
+	}
+	stake := new(governance.StakeInfo)
+	if err := stake.Deserialize(bytes.NewBuffer(data)); err != nil {
+		return nil, fmt.Errorf("deserialize stake info error:%s", err)
+	}
+	info := &StakeInfo{
+		Address:        stake.Address.ToBase58(),
+		View:           stake.View,
+		NextViewHeight: stake.NextViewHeight,
+		TotalStake:     stake.TotalStake,
+		UnboundGala:    stake.UnboundGala,
+		Pending:        stake.Pending,
+		Withdrawable:   stake.Withdrawable,
+		Peers:          make([]*PeerStakeInfo, 0, len(stake.Peers)),
+	}
+	for _, peer := range stake.Peers {
+		info.Peers = append(info.Peers, &PeerStakeInfo{
+			PeerPubkey:          peer.PeerPubkey,
+			InitPos:             peer.InitPos,
+			ConsensusPos:        peer.ConsensusPos,
+			FreezePos:           peer.FreezePos,
+			NewPos:              peer.NewPos,
+			WithdrawPos:         peer.WithdrawPos,
+			WithdrawFreezePos:   peer.WithdrawFreezePos,
+			WithdrawUnfreezePos: peer.WithdrawUnfreezePos,
+			UnlockHeight:        peer.UnlockHeight,
+		})
+	}
+	return info, nil
+}
+
+// GetSplitPayouts returns the gala paid to an address by the fee split of each view in [startView, endView]
+func GetSplitPayouts(addr common.Address, startView, endView uint32) (*SplitPayoutsInfo, error) {
+	data, err := preExecNativeContract(utils.GovernanceContractAddress, governance.GET_SPLIT_PAYOUTS,
+		[]interface{}{EmbeddedStruct{addr[:], uint64(startView), uint64(endView)}})
+	if err != nil {
+		return nil, err
+	}
+	list := new(governance.SplitPayoutList)
+	if err := list.Deserialize(bytes.NewBuffer(data)); err != nil {
+		return nil, fmt.Errorf("deserialize split payouts error:%s", err)
+	}
+	info := &SplitPayoutsInfo{
+		Address: addr.ToBase58(),
+		Payouts: make([]*SplitPayoutInfo, 0, len(list.Payouts)),
+	}
+	for _, payout := range list.Payouts {
+		info.Payouts = append(info.Payouts, &SplitPayoutInfo{View: payout.View, Amount: payout.Amount})
+	}
+	return info, nil
+}
@@ -297,7 +297,15 @@ func SendRawTransaction(cmd map[string]interface{}) map[string]interface{} {
 	log.Debugf("SendRawTransaction recv %s", hash.ToHexString())
 	if txn.TxType == types.Invoke || txn.TxType == types.Deploy {
 		if preExec, ok := cmd["PreExec"].(string); ok && preExec == "1" {
-			resp["Result"], err = bactor.PreExecuteContract(&txn)
+			height := bactor.GetCurrentBlockHeight()
+			if str, ok := cmd["Height"].(string); ok && str != "" {
+				h, err := strconv.ParseUint(str, 10, 32)
+				if err != nil || uint32(h) > height {
+					return ResponsePack(berr.INVALID_PARAMS)
+				}
+				height = uint32(h)
+			}
+			resp["Result"], err = bactor.PreExecuteContractAt(&txn, height)
 			if err != nil {
 				log.Infof("PreExec: ", err)
 				resp["Result"] = err.Error()
@@ -318,6 +326,39 @@ func SendRawTransaction(cmd map[string]interface{}) map[string]interface{} {
 	return resp
 }
 
+//trace the execution of raw transaction without commit to ledger
+func TraceTransaction(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(berr.SUCCESS)
+
+	str, ok := cmd["Data"].(string)
+	if !ok {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	bys, err := common.HexToBytes(str)
+	if err != nil {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	maxSteps := 0
+	if steps, ok := cmd["MaxSteps"].(string); ok && steps != "" {
+		maxSteps, err = strconv.Atoi(steps)
+		if err != nil || maxSteps < 0 {
+			return ResponsePack(berr.INVALID_PARAMS)
+		}
+	}
+	var txn types.Transaction
+	if err := txn.Deserialize(bytes.NewReader(bys)); err != nil {
+		return ResponsePack(berr.INVALID_TRANSACTION)
+	}
+	result, err := bactor.TraceContract(&txn, maxSteps)
+	if err != nil {
+		resp = ResponsePack(berr.INVALID_TRANSACTION)
+		resp["Result"] = err.Error()
+		return resp
+	}
+	resp["Result"] = result
+	return resp
+}
+
 //get smartcontract event by height
 func GetSmartCodeEventTxsByHeight(cmd map[string]interface{}) map[string]interface{} {
 	resp := ResponsePack(berr.SUCCESS)
@@ -437,17 +478,64 @@ func GetStorage(cmd map[string]interface{}) map[string]interface{} {
 	if err != nil {
 		return ResponsePack(berr.INVALID_PARAMS)
 	}
-	value, err := bactor.GetStorageItem(address, item)
+	height, ok := getHeightParam(cmd)
+	if !ok {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	value, err := bactor.GetStorageItemAt(address, item, height)
 	if err != nil {
 		if err == scom.ErrNotFound {
 			return ResponsePack(berr.SUCCESS)
 		}
-		return ResponsePack(berr.INTERNAL_ERROR)
+		resp = ResponsePack(berr.INTERNAL_ERROR)
+		resp["Result"] = err.Error()
+		return resp
 	}
 	resp["Result"] = common.ToHexString(value)
 	return resp
 }
 
+//get the storage value with its proof against the state root of the block height
+func GetStorageProof(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(berr.SUCCESS)
+	str, ok := cmd["Hash"].(string)
+	if !ok {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	var address common.Address
+	var err error
+	if len(str) == common.ADDR_LEN*2 {
+		address, err = common.AddressFromHexString(str)
+	} else {
+		address, err = common.AddressFromBase58(str)
+	}
+	if err != nil {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	str, ok = cmd["Key"].(string)
+	if !ok {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	key, err := common.HexToBytes(str)
+	if err != nil {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	height := bactor.GetCurrentBlockHeight()
+	if str, ok := cmd["Height"].(string); ok && str != "" {
+		h, err := strconv.ParseUint(str, 10, 32)
+		if err != nil || uint32(h) > height {
+			return ResponsePack(berr.INVALID_PARAMS)
+		}
+		height = uint32(h)
+	}
+	rsp, err := bcomn.GetStorageProof(address, key, height)
+	if err != nil {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	resp["Result"] = rsp
+	return resp
+}
+
 //get balance of address
 func GetBalance(cmd map[string]interface{}) map[string]interface{} {
 	resp := ResponsePack(berr.SUCCESS)
@@ -459,10 +547,16 @@ func GetBalance(cmd map[string]interface{}) map[string]interface{} {
 	if err != nil {
 		return ResponsePack(berr.INVALID_PARAMS)
 	}
-	balance, err := bcomn.GetBalance(address)
-	if err != nil {
+	height, ok := getHeightParam(cmd)
+	if !ok {
 		return ResponsePack(berr.INVALID_PARAMS)
 	}
+	balance, err := bcomn.GetBalance(address, height)
+	if err != nil {
+		resp = ResponsePack(berr.INVALID_PARAMS)
+		resp["Result"] = err.Error()
+		return resp
+	}
 	resp["Result"] = balance
 	return resp
 }
@@ -542,10 +636,16 @@ func GetAllowance(cmd map[string]interface{}) map[string]interface{} {
 	if err != nil {
 		return ResponsePack(berr.INVALID_PARAMS)
 	}
-	rsp, err := bcomn.GetAllowance(asset, fromAddr, toAddr)
-	if err != nil {
+	height, ok := getHeightParam(cmd)
+	if !ok {
 		return ResponsePack(berr.INVALID_PARAMS)
 	}
+	rsp, err := bcomn.GetAllowance(asset, fromAddr, toAddr, height)
+	if err != nil {
+		resp = ResponsePack(berr.INVALID_PARAMS)
+		resp["Result"] = err.Error()
+		return resp
+	}
 	resp["Result"] = rsp
 	return resp
 }
@@ -562,7 +662,7 @@ func GetUnboundGala(cmd map[string]interface{}) map[string]interface{} {
 		return ResponsePack(berr.INVALID_PARAMS)
 	}
 	fromAddr := utils.ZptContractAddress
-	rsp, err := bcomn.GetAllowance("gala", fromAddr, toAddr)
+	rsp, err := bcomn.GetAllowance("gala", fromAddr, toAddr, bactor.GetCurrentBlockHeight())
 	if err != nil {
 		return ResponsePack(berr.INVALID_PARAMS)
 	}
@@ -603,3 +703,123 @@ func GetMemPoolTxState(cmd map[string]interface{}) map[string]interface{} {
 	resp["Result"] = bcomn.TXNEntryInfo{attrs}
 	return resp
 }
+
+//get the stakes per peer, pending and withdrawable zpt and unbound gala of an address in governance
+func GetStakeInfo(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(berr.SUCCESS)
+	str, ok := cmd["Addr"].(string)
+	if !ok {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	address, err := common.AddressFromBase58(str)
+	if err != nil {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	rsp, err := bcomn.GetStakeInfo(address)
+	if err != nil {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	resp["Result"] = rsp
+	return resp
+}
+
+//get the gala paid to an address by the governance fee split per view
+func GetSplitPayouts(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(berr.SUCCESS)
+	str, ok := cmd["Addr"].(string)
+	if !ok {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	address, err := common.AddressFromBase58(str)
+	if err != nil {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	var startView, endView uint64
+	if str, ok := cmd["StartView"].(string); ok && str != "" {
+		if startView, err = strconv.ParseUint(str, 10, 32); err != nil {
+			return ResponsePack(berr.INVALID_PARAMS)
+		}
+	}
+	if str, ok := cmd["EndView"].(string); ok && str != "" {
+		if endView, err = strconv.ParseUint(str, 10, 32); err != nil {
+			return ResponsePack(berr.INVALID_PARAMS)
+		}
+	}
+	rsp, err := bcomn.GetSplitPayouts(address, uint32(startView), uint32(endView))
+	if err != nil {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	resp["Result"] = rsp
+	return resp
+}
+
+//get the transactions touching an address from the address index, newest first
+func GetTransactionsByAddress(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(berr.SUCCESS)
+	address, start, limit, ok := getAddressIndexParams(cmd)
+	if !ok {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	rsp, err := bcomn.GetTransactionsByAddress(address, start, limit)
+	if err != nil {
+		resp = ResponsePack(berr.INTERNAL_ERROR)
+		resp["Result"] = err.Error()
+		return resp
+	}
+	resp["Result"] = rsp
+	return resp
+}
+
+//get the zpt and gala transfers touching an address from the address index, newest first
+func GetTransfersByAddress(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(berr.SUCCESS)
+	address, start, limit, ok := getAddressIndexParams(cmd)
+	if !ok {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	rsp, err := bcomn.GetTransfersByAddress(address, start, limit)
+	if err != nil {
+		resp = ResponsePack(berr.INTERNAL_ERROR)
+		resp["Result"] = err.Error()
+		return resp
+	}
+	resp["Result"] = rsp
+	return resp
+}
+
+func getAddressIndexParams(cmd map[string]interface{}) (common.Address, []byte, uint32, bool) {
+	str, ok := cmd["Addr"].(string)
+	if !ok {
+		return common.Address{}, nil, 0, false
+	}
+	address, err := common.AddressFromBase58(str)
+	if err != nil {
+		return common.Address{}, nil, 0, false
+	}
+	var start []byte
+	if str, ok := cmd["Start"].(string); ok && str != "" {
+		if start, err = common.HexToBytes(str); err != nil {
+			return common.Address{}, nil, 0, false
+		}
+	}
+	var limit uint64
+	if str, ok := cmd["Limit"].(string); ok && str != "" {
+		if limit, err = strconv.ParseUint(str, 10, 32); err != nil {
+			return common.Address{}, nil, 0, false
+		}
+	}
+	return address, start, uint32(limit), true
+}
+
+//getHeightParam return the optional height param, default is the current block height
+func getHeightParam(cmd map[string]interface{}) (uint32, bool) {
+	height := bactor.GetCurrentBlockHeight()
+	if str, ok := cmd["Height"].(string); ok && str != "" {
+		h, err := strconv.ParseUint(str, 10, 32)
+		if err != nil || uint32(h) > height {
+			return 0, false
+		}
+		height = uint32(h)
+	}
+	return height, true
+}
@@ -37,6 +37,7 @@ package rpc
 import (
 	"bytes"
 	"encoding/hex"
+	"math"
 
 	"github.com/imZhuFei/zeepin/common"
 	"github.com/imZhuFei/zeepin/common/config"
@@ -246,8 +247,8 @@ func GetRawTransaction(params []interface{}) map[string]interface{} {
 	return responseSuccess(common.ToHexString(w.Bytes()))
 }
 
-//get storage from contract
-//   {"jsonrpc": "2.0", "method": "getstorage", "params": ["code hash", "key"], "id": 0}
+//get storage from contract, at the block height if given
+//   {"jsonrpc": "2.0", "method": "getstorage", "params": ["code hash", "key", height], "id": 0}
 func GetStorage(params []interface{}) map[string]interface{} {
 	if len(params) < 2 {
 		return responsePack(berr.INVALID_PARAMS, nil)
@@ -282,16 +283,66 @@ func GetStorage(params []interface{}) map[string]interface{} {
 	default:
 		return responsePack(berr.INVALID_PARAMS, "")
 	}
-	value, err := bactor.GetStorageItem(address, key)
+	height := bactor.GetCurrentBlockHeight()
+	if len(params) > 2 {
+		h, ok := params[2].(float64)
+		if !ok || h < 0 || h > float64(height) {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+		height = uint32(h)
+	}
+	value, err := bactor.GetStorageItemAt(address, key, height)
 	if err != nil {
 		if err == scom.ErrNotFound {
 			return responseSuccess(nil)
 		}
-		return responsePack(berr.INVALID_PARAMS, "")
+		return responsePack(berr.INVALID_PARAMS, err.Error())
 	}
 	return responseSuccess(common.ToHexString(value))
 }
 
+//get the storage value with its proof against the state root of the block height
+func GetStorageProof(params []interface{}) map[string]interface{} {
+	if len(params) < 2 {
+		return responsePack(berr.INVALID_PARAMS, nil)
+	}
+	str, ok := params[0].(string)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	var address common.Address
+	var err error
+	if len(str) == common.ADDR_LEN*2 {
+		address, err = common.AddressFromHexString(str)
+	} else {
+		address, err = common.AddressFromBase58(str)
+	}
+	if err != nil {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	str, ok = params[1].(string)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	key, err := hex.DecodeString(str)
+	if err != nil {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	height := bactor.GetCurrentBlockHeight()
+	if len(params) > 2 {
+		h, ok := params[2].(float64)
+		if !ok || h < 0 || h > float64(height) {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+		height = uint32(h)
+	}
+	rsp, err := bcomn.GetStorageProof(address, key, height)
+	if err != nil {
+		return responsePack(berr.INVALID_PARAMS, err.Error())
+	}
+	return responseSuccess(rsp)
+}
+
 //send raw transaction
 // A JSON example for sendrawtransaction method as following:
 //   {"jsonrpc": "2.0", "method": "sendrawtransaction", "params": ["raw transactioin in hex"], "id": 0}
@@ -317,7 +368,15 @@ func SendRawTransaction(params []interface{}) map[string]interface{} {
 			if len(params) > 1 {
 				preExec, ok := params[1].(float64)
 				if ok && preExec == 1 {
-					result, err := bactor.PreExecuteContract(&txn)
+					height := bactor.GetCurrentBlockHeight()
+					if len(params) > 2 {
+						h, ok := params[2].(float64)
+						if !ok || h < 0 || h > float64(height) {
+							return responsePack(berr.INVALID_PARAMS, "")
+						}
+						height = uint32(h)
+					}
+					result, err := bactor.PreExecuteContractAt(&txn, height)
 					if err != nil {
 						log.Infof("PreExec: ", err)
 						return responsePack(berr.SMARTCODE_ERROR, err.Error())
@@ -339,6 +398,38 @@ func SendRawTransaction(params []interface{}) map[string]interface{} {
 	return responseSuccess(hash.ToHexString())
 }
 
+//trace the execution of raw transaction without commit to ledger
+func TraceTransaction(params []interface{}) map[string]interface{} {
+	if len(params) < 1 {
+		return responsePack(berr.INVALID_PARAMS, nil)
+	}
+	str, ok := params[0].(string)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	maxSteps := 0
+	if len(params) > 1 {
+		steps, ok := params[1].(float64)
+		if !ok || steps < 0 {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+		maxSteps = int(steps)
+	}
+	hex, err := common.HexToBytes(str)
+	if err != nil {
+		return responsePack(berr.INVALID_PARAMS, err.Error())
+	}
+	var txn types.Transaction
+	if err := txn.Deserialize(bytes.NewReader(hex)); err != nil {
+		return responsePack(berr.INVALID_TRANSACTION, err.Error())
+	}
+	result, err := bactor.TraceContract(&txn, maxSteps)
+	if err != nil {
+		return responsePack(berr.INVALID_TRANSACTION, err.Error())
+	}
+	return responseSuccess(result)
+}
+
 //get node version
 func GetNodeVersion(params []interface{}) map[string]interface{} {
 	return responseSuccess(config.Version)
@@ -478,9 +569,17 @@ func GetBalance(params []interface{}) map[string]interface{} {
 	if err != nil {
 		return responsePack(berr.INVALID_PARAMS, "")
 	}
-	rsp, err := bcomn.GetBalance(address)
+	height := bactor.GetCurrentBlockHeight()
+	if len(params) > 1 {
+		h, ok := params[1].(float64)
+		if !ok || h < 0 || h > float64(height) {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+		height = uint32(h)
+	}
+	rsp, err := bcomn.GetBalance(address, height)
 	if err != nil {
-		return responsePack(berr.INVALID_PARAMS, "")
+		return responsePack(berr.INVALID_PARAMS, err.Error())
 	}
 	return responseSuccess(rsp)
 }
@@ -510,9 +609,17 @@ func GetAllowance(params []interface{}) map[string]interface{} {
 	if err != nil {
 		return responsePack(berr.INVALID_PARAMS, "")
 	}
-	rsp, err := bcomn.GetAllowance(asset, fromAddr, toAddr)
+	height := bactor.GetCurrentBlockHeight()
+	if len(params) > 3 {
+		h, ok := params[3].(float64)
+		if !ok || h < 0 || h > float64(height) {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+		height = uint32(h)
+	}
+	rsp, err := bcomn.GetAllowance(asset, fromAddr, toAddr, height)
 	if err != nil {
-		return responsePack(berr.INVALID_PARAMS, "")
+		return responsePack(berr.INVALID_PARAMS, err.Error())
 	}
 	return responseSuccess(rsp)
 }
@@ -601,9 +708,227 @@ func GetUnboundGala(params []interface{}) map[string]interface{} {
 		return responsePack(berr.INVALID_PARAMS, "")
 	}
 	fromAddr := utils.ZptContractAddress
-	rsp, err := bcomn.GetAllowance("gala", fromAddr, toAddr)
+	rsp, err := bcomn.GetAllowance("gala", fromAddr, toAddr, bactor.GetCurrentBlockHeight())
+	if err != nil {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	return responseSuccess(rsp)
+}
+
+//get the claim committed to the claim registry by claim hash
+func GetClaim(params []interface{}) map[string]interface{} {
+	if len(params) < 1 {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	str, ok := params[0].(string)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	claimHash, err := common.HexToBytes(str)
 	if err != nil {
 		return responsePack(berr.INVALID_PARAMS, "")
 	}
+	rsp, err := bcomn.GetClaim(claimHash)
+	if err != nil {
+		return responsePack(berr.INVALID_PARAMS, err.Error())
+	}
+	return responseSuccess(rsp)
+}
+
+//get the hashes of claims about a GID
+func GetClaimsOf(params []interface{}) map[string]interface{} {
+	if len(params) < 1 {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	subject, ok := params[0].(string)
+	if !ok || subject == "" {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	var start []byte
+	if len(params) > 1 {
+		str, ok := params[1].(string)
+		if !ok {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+		var err error
+		if start, err = common.HexToBytes(str); err != nil {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+	}
+	var limit uint64
+	if len(params) > 2 {
+		l, ok := params[2].(float64)
+		if !ok || l < 0 {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+		limit = uint64(l)
+	}
+	rsp, err := bcomn.GetClaimsOf([]byte(subject), start, limit)
+	if err != nil {
+		return responsePack(berr.INVALID_PARAMS, err.Error())
+	}
+	return responseSuccess(rsp)
+}
+
+//get the admin, roles, role holders and delegations of a contract managed by the auth contract
+func GetContractAuth(params []interface{}) map[string]interface{} {
+	if len(params) < 1 {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	str, ok := params[0].(string)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	var address common.Address
+	var err error
+	if len(str) == common.ADDR_LEN*2 {
+		address, err = common.AddressFromHexString(str)
+	} else {
+		address, err = common.AddressFromBase58(str)
+	}
+	if err != nil {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	var fn string
+	if len(params) > 1 {
+		if fn, ok = params[1].(string); !ok {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+	}
+	rsp, err := bcomn.GetContractAuth(address, fn)
+	if err != nil {
+		return responsePack(berr.INVALID_PARAMS, err.Error())
+	}
+	return responseSuccess(rsp)
+}
+
+//get the stakes per peer, pending and withdrawable zpt and unbound gala of an address in governance
+func GetStakeInfo(params []interface{}) map[string]interface{} {
+	if len(params) < 1 {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	str, ok := params[0].(string)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	address, err := common.AddressFromBase58(str)
+	if err != nil {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	rsp, err := bcomn.GetStakeInfo(address)
+	if err != nil {
+		return responsePack(berr.INVALID_PARAMS, err.Error())
+	}
+	return responseSuccess(rsp)
+}
+
+//get the gala paid to an address by the governance fee split per view
+func GetSplitPayouts(params []interface{}) map[string]interface{} {
+	if len(params) < 1 {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	str, ok := params[0].(string)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	address, err := common.AddressFromBase58(str)
+	if err != nil {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	var startView, endView uint32
+	if len(params) > 1 {
+		v, ok := params[1].(float64)
+		if !ok || v < 0 || v > math.MaxUint32 {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+		startView = uint32(v)
+	}
+	if len(params) > 2 {
+		v, ok := params[2].(float64)
+		if !ok || v < 0 || v > math.MaxUint32 {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+		endView = uint32(v)
+	}
+	rsp, err := bcomn.GetSplitPayouts(address, startView, endView)
+	if err != nil {
+		return responsePack(berr.INVALID_PARAMS, err.Error())
+	}
+	return responseSuccess(rsp)
+}
+
+//get the transactions touching an address from the address index, newest first
+func GetTransactionsByAddress(params []interface{}) map[string]interface{} {
+	if len(params) < 1 {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	str, ok := params[0].(string)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	address, err := common.AddressFromBase58(str)
+	if err != nil {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	var start []byte
+	if len(params) > 1 {
+		str, ok := params[1].(string)
+		if !ok {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+		if start, err = common.HexToBytes(str); err != nil {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+	}
+	var limit uint32
+	if len(params) > 2 {
+		l, ok := params[2].(float64)
+		if !ok || l < 0 || l > math.MaxUint32 {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+		limit = uint32(l)
+	}
+	rsp, err := bcomn.GetTransactionsByAddress(address, start, limit)
+	if err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	return responseSuccess(rsp)
+}
+
+//get the zpt and gala transfers touching an address from the address index, newest first
+func GetTransfersByAddress(params []interface{}) map[string]interface{} {
+	if len(params) < 1 {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	str, ok := params[0].(string)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	address, err := common.AddressFromBase58(str)
+	if err != nil {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	var start []byte
+	if len(params) > 1 {
+		str, ok := params[1].(string)
+		if !ok {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+		if start, err = common.HexToBytes(str); err != nil {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+	}
+	var limit uint32
+	if len(params) > 2 {
+		l, ok := params[2].(float64)
+		if !ok || l < 0 || l > math.MaxUint32 {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+		limit = uint32(l)
+	}
+	rsp, err := bcomn.GetTransfersByAddress(address, start, limit)
+	if err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
 	return responseSuccess(rsp)
 }
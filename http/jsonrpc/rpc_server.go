@@ -60,6 +60,7 @@ func StartRPCServer() error {
 
 	rpc.HandleFunc("getrawtransaction", rpc.GetRawTransaction)
 	rpc.HandleFunc("sendrawtransaction", rpc.SendRawTransaction)
+	rpc.HandleFunc("tracetransaction", rpc.TraceTransaction)
 	rpc.HandleFunc("getstorage", rpc.GetStorage)
 	rpc.HandleFunc("getversion", rpc.GetNodeVersion)
 	rpc.HandleFunc("getnetworkid", rpc.GetNetworkId)
@@ -73,9 +74,17 @@ func StartRPCServer() error {
 	rpc.HandleFunc("getbalance", rpc.GetBalance)
 	rpc.HandleFunc("getallowance", rpc.GetAllowance)
 	rpc.HandleFunc("getmerkleproof", rpc.GetMerkleProof)
+	rpc.HandleFunc("getstorageproof", rpc.GetStorageProof)
 	rpc.HandleFunc("getblocktxsbyheight", rpc.GetBlockTxsByHeight)
 	rpc.HandleFunc("getgasprice", rpc.GetGasPrice)
 	rpc.HandleFunc("getunboundgala", rpc.GetUnboundGala)
+	rpc.HandleFunc("getclaim", rpc.GetClaim)
+	rpc.HandleFunc("getclaimsof", rpc.GetClaimsOf)
+	rpc.HandleFunc("getcontractauth", rpc.GetContractAuth)
+	rpc.HandleFunc("getstakeinfo", rpc.GetStakeInfo)
+	rpc.HandleFunc("getsplitpayouts", rpc.GetSplitPayouts)
+	rpc.HandleFunc("gettransactionsbyaddress", rpc.GetTransactionsByAddress)
+	rpc.HandleFunc("gettransfersbyaddress", rpc.GetTransfersByAddress)
 
 	err := http.ListenAndServe(":"+strconv.Itoa(int(cfg.DefConfig.Rpc.HttpJsonPort)), nil)
 	if err != nil {
@@ -90,8 +90,14 @@ const (
 	GET_MEMPOOL_TXSTATE   = "/api/v1/mempool/txstate/:hash"
 	GET_VERSION           = "/api/v1/version"
 	GET_NETWORKID         = "/api/v1/networkid"
-
-	POST_RAW_TX = "/api/v1/transaction"
+	GET_STAKE_INFO        = "/api/v1/stakeinfo/:addr"
+	GET_STORAGE_PROOF     = "/api/v1/storageproof/:hash/:key"
+	GET_SPLIT_PAYOUTS     = "/api/v1/splitpayouts/:addr"
+	GET_TXS_BY_ADDRESS    = "/api/v1/address/transactions/:addr"
+	GET_TRANSFERS_BY_ADDR = "/api/v1/address/transfers/:addr"
+
+	POST_RAW_TX   = "/api/v1/transaction"
+	POST_TRACE_TX = "/api/v1/trace/transaction"
 )
 
 //init restful server
@@ -166,10 +172,16 @@ func (this *restServer) registryMethod() {
 		GET_MEMPOOL_TXSTATE:   {name: "getmempooltxstate", handler: rest.GetMemPoolTxState},
 		GET_VERSION:           {name: "getversion", handler: rest.GetNodeVersion},
 		GET_NETWORKID:         {name: "getnetworkid", handler: rest.GetNetworkId},
+		GET_STAKE_INFO:        {name: "getstakeinfo", handler: rest.GetStakeInfo},
+		GET_STORAGE_PROOF:     {name: "getstorageproof", handler: rest.GetStorageProof},
+		GET_SPLIT_PAYOUTS:     {name: "getsplitpayouts", handler: rest.GetSplitPayouts},
+		GET_TXS_BY_ADDRESS:    {name: "gettransactionsbyaddress", handler: rest.GetTransactionsByAddress},
+		GET_TRANSFERS_BY_ADDR: {name: "gettransfersbyaddress", handler: rest.GetTransfersByAddress},
 	}
 
 	postMethodMap := map[string]Action{
-		POST_RAW_TX: {name: "sendrawtransaction", handler: rest.SendRawTransaction},
+		POST_RAW_TX:   {name: "sendrawtransaction", handler: rest.SendRawTransaction},
+		POST_TRACE_TX: {name: "tracetransaction", handler: rest.TraceTransaction},
 	}
 	this.postMap = postMethodMap
 	this.getMap = getMethodMap
@@ -194,6 +206,8 @@ func (this *restServer) getPath(url string) string {
 		return GET_SMTCOCE_EVTS
 	} else if strings.Contains(url, strings.TrimRight(GET_BLK_HGT_BY_TXHASH, ":hash")) {
 		return GET_BLK_HGT_BY_TXHASH
+	} else if strings.Contains(url, strings.TrimRight(GET_STORAGE_PROOF, ":hash/:key")) {
+		return GET_STORAGE_PROOF
 	} else if strings.Contains(url, strings.TrimRight(GET_STORAGE, ":hash/:key")) {
 		return GET_STORAGE
 	} else if strings.Contains(url, strings.TrimRight(GET_BALANCE, ":addr")) {
@@ -206,6 +220,14 @@ func (this *restServer) getPath(url string) string {
 		return GET_UNBOUNDGALA
 	} else if strings.Contains(url, strings.TrimRight(GET_MEMPOOL_TXSTATE, ":hash")) {
 		return GET_MEMPOOL_TXSTATE
+	} else if strings.Contains(url, strings.TrimRight(GET_STAKE_INFO, ":addr")) {
+		return GET_STAKE_INFO
+	} else if strings.Contains(url, strings.TrimRight(GET_SPLIT_PAYOUTS, ":addr")) {
+		return GET_SPLIT_PAYOUTS
+	} else if strings.Contains(url, strings.TrimRight(GET_TXS_BY_ADDRESS, ":addr")) {
+		return GET_TXS_BY_ADDRESS
+	} else if strings.Contains(url, strings.TrimRight(GET_TRANSFERS_BY_ADDR, ":addr")) {
+		return GET_TRANSFERS_BY_ADDR
 	}
 	return url
 }
@@ -229,9 +251,12 @@ func (this *restServer) getParams(r *http.Request, url string, req map[string]in
 	case GET_CONTRACT_STATE:
 		req["Hash"], req["Raw"] = getParam(r, "hash"), r.FormValue("raw")
 	case POST_RAW_TX:
-		req["PreExec"] = r.FormValue("preExec")
+		req["PreExec"], req["Height"] = r.FormValue("preExec"), r.FormValue("height")
+	case POST_TRACE_TX:
+		req["MaxSteps"] = r.FormValue("maxSteps")
 	case GET_STORAGE:
 		req["Hash"], req["Key"] = getParam(r, "hash"), getParam(r, "key")
+		req["Height"] = r.FormValue("height")
 	case GET_SMTCOCE_EVT_TXS:
 		req["Height"] = getParam(r, "height")
 	case GET_SMTCOCE_EVTS:
@@ -239,16 +264,28 @@ func (this *restServer) getParams(r *http.Request, url string, req map[string]in
 	case GET_BLK_HGT_BY_TXHASH:
 		req["Hash"] = getParam(r, "hash")
 	case GET_BALANCE:
-		req["Addr"] = getParam(r, "addr")
+		req["Addr"], req["Height"] = getParam(r, "addr"), r.FormValue("height")
 	case GET_MERKLE_PROOF:
 		req["Hash"] = getParam(r, "hash")
 	case GET_ALLOWANCE:
 		req["Asset"] = getParam(r, "asset")
 		req["From"], req["To"] = getParam(r, "from"), getParam(r, "to")
+		req["Height"] = r.FormValue("height")
 	case GET_UNBOUNDGALA:
 		req["Addr"] = getParam(r, "addr")
 	case GET_MEMPOOL_TXSTATE:
 		req["Hash"] = getParam(r, "hash")
+	case GET_STAKE_INFO:
+		req["Addr"] = getParam(r, "addr")
+	case GET_STORAGE_PROOF:
+		req["Hash"], req["Key"] = getParam(r, "hash"), getParam(r, "key")
+		req["Height"] = r.FormValue("height")
+	case GET_SPLIT_PAYOUTS:
+		req["Addr"] = getParam(r, "addr")
+		req["StartView"], req["EndView"] = r.FormValue("start"), r.FormValue("end")
+	case GET_TXS_BY_ADDRESS, GET_TRANSFERS_BY_ADDR:
+		req["Addr"] = getParam(r, "addr")
+		req["Start"], req["Limit"] = r.FormValue("start"), r.FormValue("limit")
 	default:
 	}
 	return req
@@ -210,6 +210,7 @@ func (self *WsServer) registryMethod() {
 		"heartbeat":                 {handler: heartbeat},
 		"subscribe":                 {handler: subscribe},
 		"getstorage":                {handler: rest.GetStorage},
+		"getstorageproof":           {handler: rest.GetStorageProof},
 		"getallowance":              {handler: rest.GetAllowance},
 		"getmerkleproof":            {handler: rest.GetMerkleProof},
 		"getblocktxsbyheight":       {handler: rest.GetBlockTxsByHeight},
@@ -219,6 +220,10 @@ func (self *WsServer) registryMethod() {
 		"getmempooltxstate":         {handler: rest.GetMemPoolTxState},
 		"getversion":                {handler: rest.GetNodeVersion},
 		"getnetworkid":              {handler: rest.GetNetworkId},
+		"getstakeinfo":              {handler: rest.GetStakeInfo},
+		"getsplitpayouts":           {handler: rest.GetSplitPayouts},
+		"gettransactionsbyaddress":  {handler: rest.GetTransactionsByAddress},
+		"gettransfersbyaddress":     {handler: rest.GetTransfersByAddress},
 
 		"getsessioncount": {handler: getsessioncount},
 	}
@@ -88,16 +88,22 @@ func setupAPP() *cli.App {
 		cmd.AssetCommand,
 		cmd.ContractCommand,
 		cmd.ExportCommand,
+		cmd.SnapshotCommand,
 	}
 	app.Flags = []cli.Flag{
 		//common setting
 		utils.ConfigFlag,
 		utils.LogLevelFlag,
 		utils.DisableEventLogFlag,
+		utils.EnableAddressIndexFlag,
+		utils.EnableArchiveFlag,
+		utils.PruneBlocksFlag,
 		utils.DataDirFlag,
 		utils.ImportEnableFlag,
 		utils.ImportHeightFlag,
 		utils.ImportFileFlag,
+		utils.SnapshotFlag,
+		utils.SnapshotHashFlag,
 		//account setting
 		utils.WalletFileFlag,
 		utils.AccountAddressFlag,
@@ -264,6 +270,16 @@ func initLedger(ctx *cli.Context) (*ledger.Ledger, error) {
 			log.Warnf("InitLedger remove:%s error:%s", dbDir, err)
 		}
 	}
+	snapshotFile := ctx.GlobalString(utils.GetFlagName(utils.SnapshotFlag))
+	if (snapshotFile != "" || config.DefConfig.Common.PruneBlocks > 0) && config.DefConfig.Consensus.EnableConsensus {
+		return nil, fmt.Errorf("consensus node cannot start from snapshot or prune blocks")
+	}
+	if snapshotFile != "" {
+		err = utils.ImportSnapshot(dbDir, snapshotFile, ctx.GlobalString(utils.GetFlagName(utils.SnapshotHashFlag)))
+		if err != nil {
+			return nil, fmt.Errorf("ImportSnapshot error:%s", err)
+		}
+	}
 	ledger.DefLedger, err = ledger.NewLedger(dbDir)
 	if err != nil {
 		return nil, fmt.Errorf("NewLedger error:%s", err)
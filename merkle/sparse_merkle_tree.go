@@ -0,0 +1,490 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package merkle
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/common/serialization"
+)
+
+const (
+	SMT_MAX_DEPTH = common.UINT256_SIZE * 8
+
+	smtLeafNode     byte = 0
+	smtInternalNode byte = 1
+)
+
+// NodeStore is an interface for persist the nodes of sparse merkle tree and their reference counts by node hash
+type NodeStore interface {
+	GetNode(hash common.Uint256) ([]byte, error)
+	PutNode(hash common.Uint256, node []byte)
+	DeleteNode(hash common.Uint256)
+	GetNodeRef(hash common.Uint256) (uint32, error) // 0 if the node is not stored
+	PutNodeRef(hash common.Uint256, ref uint32)
+}
+
+type memNodeStore struct {
+	nodes map[common.Uint256][]byte
+	refs  map[common.Uint256]uint32
+}
+
+// NewMemNodeStore returns a NodeStore implement in memory
+func NewMemNodeStore() NodeStore {
+	return &memNodeStore{
+		nodes: make(map[common.Uint256][]byte),
+		refs:  make(map[common.Uint256]uint32),
+	}
+}
+
+func (self *memNodeStore) GetNode(hash common.Uint256) ([]byte, error) {
+	node, ok := self.nodes[hash]
+	if !ok {
+		return nil, fmt.Errorf("node %s not found", hash.ToHexString())
+	}
+	return node, nil
+}
+
+func (self *memNodeStore) PutNode(hash common.Uint256, node []byte) {
+	self.nodes[hash] = node
+}
+
+func (self *memNodeStore) DeleteNode(hash common.Uint256) {
+	delete(self.nodes, hash)
+	delete(self.refs, hash)
+}
+
+func (self *memNodeStore) GetNodeRef(hash common.Uint256) (uint32, error) {
+	return self.refs[hash], nil
+}
+
+func (self *memNodeStore) PutNodeRef(hash common.Uint256, ref uint32) {
+	self.refs[hash] = ref
+}
+
+// SparseMerkleTree is a merkle tree of key-value pairs indexed by the sha256 hash of key,
+// the bits of key hash from the highest give the path from root to the leaf.
+//
+// A subtree without leaf is empty and its hash is UINT256_EMPTY, a subtree with only one leaf
+// is the leaf itself, so the depth of tree is about log2 of the number of keys.
+//
+// leaf hash:     sha256(0x00 || sha256(key) || sha256(value))
+// internal hash: sha256(0x01 || left || right)
+//
+// Nodes are stored by hash and never overwritten. A node is referenced by its stored parents and
+// by the roots retained with Retain, and it is deleted once Release drops its last reference, so
+// the tree of a root can be read until the root is released.
+type SparseMerkleTree struct {
+	root   common.Uint256
+	store  NodeStore
+	hasher TreeHasher
+}
+
+type smtNode struct {
+	hash     common.Uint256
+	internal bool
+	// leaf node
+	keyHash   common.Uint256
+	valueHash common.Uint256
+	value     []byte
+	// internal node
+	left  common.Uint256
+	right common.Uint256
+}
+
+type smtLeaf struct {
+	keyHash common.Uint256
+	value   []byte
+}
+
+// SparseMerkleProof proves the value of a key, or that the key does not exist
+type SparseMerkleProof struct {
+	Siblings      []common.Uint256 // sibling hashes from the root down to the leaf
+	LeafKeyHash   common.Uint256   // key hash of the leaf at the end of path, UINT256_EMPTY if the path ends at an empty subtree
+	LeafValueHash common.Uint256   // value hash of the leaf at the end of path
+}
+
+// NewSparseMerkleTree returns the tree of root, UINT256_EMPTY for an empty tree
+func NewSparseMerkleTree(root common.Uint256, store NodeStore) *SparseMerkleTree {
+	return &SparseMerkleTree{
+		root:   root,
+		store:  store,
+		hasher: TreeHasher{},
+	}
+}
+
+func (self *SparseMerkleTree) Root() common.Uint256 {
+	return self.root
+}
+
+// Retain adds a reference to the current root, so its nodes are kept until the root is released.
+// The root of Update must be retained before the next Update
+func (self *SparseMerkleTree) Retain() error {
+	return self.incRef(self.root)
+}
+
+// Release drops a reference to a retained root, and deletes the nodes no longer referenced
+func (self *SparseMerkleTree) Release(root common.Uint256) error {
+	return self.decRef(root)
+}
+
+// Update sets the values of keys in one batch, nil value deletes the key
+func (self *SparseMerkleTree) Update(kvs map[string][]byte) error {
+	if len(kvs) == 0 {
+		return nil
+	}
+	leaves := make([]*smtLeaf, 0, len(kvs))
+	for k, v := range kvs {
+		leaves = append(leaves, &smtLeaf{keyHash: sha256.Sum256([]byte(k)), value: v})
+	}
+	sort.Slice(leaves, func(i, j int) bool {
+		return bytes.Compare(leaves[i].keyHash[:], leaves[j].keyHash[:]) < 0
+	})
+	root, err := self.getNode(self.root)
+	if err != nil {
+		return err
+	}
+	root, err = self.update(root, 0, leaves)
+	if err != nil {
+		return err
+	}
+	self.root = hashOfNode(root)
+	return nil
+}
+
+// Get returns the value of key, nil if the key does not exist
+func (self *SparseMerkleTree) Get(key []byte) ([]byte, error) {
+	value, _, err := self.Prove(key)
+	return value, err
+}
+
+// Prove returns the value of key and the proof of it, nil value if the key does not exist
+func (self *SparseMerkleTree) Prove(key []byte) ([]byte, *SparseMerkleProof, error) {
+	keyHash := sha256.Sum256(key)
+	proof := &SparseMerkleProof{}
+	node, err := self.getNode(self.root)
+	if err != nil {
+		return nil, nil, err
+	}
+	for depth := uint(0); node != nil && node.internal; depth++ {
+		child, sibling := node.left, node.right
+		if getBit(keyHash, depth) == 1 {
+			child, sibling = node.right, node.left
+		}
+		proof.Siblings = append(proof.Siblings, sibling)
+		if node, err = self.getNode(child); err != nil {
+			return nil, nil, err
+		}
+	}
+	if node == nil {
+		return nil, proof, nil
+	}
+	proof.LeafKeyHash, proof.LeafValueHash = node.keyHash, node.valueHash
+	if node.keyHash != keyHash {
+		return nil, proof, nil
+	}
+	return node.value, proof, nil
+}
+
+// update applies the sorted leaves to the subtree of node at depth, and returns the new subtree
+func (self *SparseMerkleTree) update(node *smtNode, depth uint, leaves []*smtLeaf) (*smtNode, error) {
+	if node == nil || !node.internal {
+		// rebuild the subtree with the leaf of node and the leaves
+		if node != nil {
+			i := sort.Search(len(leaves), func(i int) bool {
+				return bytes.Compare(leaves[i].keyHash[:], node.keyHash[:]) >= 0
+			})
+			if i == len(leaves) || leaves[i].keyHash != node.keyHash {
+				leaves = append(leaves[:i:i], append([]*smtLeaf{{keyHash: node.keyHash, value: node.value}}, leaves[i:]...)...)
+			}
+		}
+		values := make([]*smtLeaf, 0, len(leaves))
+		for _, leaf := range leaves {
+			if leaf.value != nil {
+				values = append(values, leaf)
+			}
+		}
+		return self.build(depth, values)
+	}
+	if depth >= SMT_MAX_DEPTH {
+		return nil, errors.New("sparse merkle tree is deeper than max depth")
+	}
+	i := splitLeaves(leaves, depth)
+	left, err := self.getNode(node.left)
+	if err != nil {
+		return nil, err
+	}
+	if i > 0 {
+		if left, err = self.update(left, depth+1, leaves[:i]); err != nil {
+			return nil, err
+		}
+	}
+	right, err := self.getNode(node.right)
+	if err != nil {
+		return nil, err
+	}
+	if i < len(leaves) {
+		if right, err = self.update(right, depth+1, leaves[i:]); err != nil {
+			return nil, err
+		}
+	}
+	return self.newInternalNode(left, right)
+}
+
+// build returns a new subtree at depth of the sorted leaves
+func (self *SparseMerkleTree) build(depth uint, leaves []*smtLeaf) (*smtNode, error) {
+	switch len(leaves) {
+	case 0:
+		return nil, nil
+	case 1:
+		return self.newLeafNode(leaves[0].keyHash, leaves[0].value)
+	}
+	if depth >= SMT_MAX_DEPTH {
+		return nil, errors.New("sparse merkle tree is deeper than max depth")
+	}
+	i := splitLeaves(leaves, depth)
+	left, err := self.build(depth+1, leaves[:i])
+	if err != nil {
+		return nil, err
+	}
+	right, err := self.build(depth+1, leaves[i:])
+	if err != nil {
+		return nil, err
+	}
+	return self.newInternalNode(left, right)
+}
+
+func (self *SparseMerkleTree) newLeafNode(keyHash common.Uint256, value []byte) (*smtNode, error) {
+	node := &smtNode{
+		keyHash:   keyHash,
+		valueHash: sha256.Sum256(value),
+		value:     value,
+	}
+	node.hash = hashLeaf(self.hasher, node.keyHash, node.valueHash)
+	if err := self.putNode(node); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// newInternalNode returns the parent of left and right, an empty subtree or a single leaf is not wrapped
+func (self *SparseMerkleTree) newInternalNode(left, right *smtNode) (*smtNode, error) {
+	if left == nil && (right == nil || !right.internal) {
+		return right, nil
+	}
+	if right == nil && !left.internal {
+		return left, nil
+	}
+	node := &smtNode{
+		internal: true,
+		left:     hashOfNode(left),
+		right:    hashOfNode(right),
+	}
+	node.hash = self.hasher.hash_children(node.left, node.right)
+	if err := self.putNode(node); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+func (self *SparseMerkleTree) getNode(hash common.Uint256) (*smtNode, error) {
+	if hash == common.UINT256_EMPTY {
+		return nil, nil
+	}
+	data, err := self.store.GetNode(hash)
+	if err != nil {
+		return nil, err
+	}
+	node := &smtNode{hash: hash}
+	if err := node.Deserialize(bytes.NewReader(data)); err != nil {
+		return nil, fmt.Errorf("deserialize node %s error: %s", hash.ToHexString(), err)
+	}
+	return node, nil
+}
+
+// putNode stores a new node and references its children, a referenced node is already stored
+func (self *SparseMerkleTree) putNode(node *smtNode) error {
+	ref, err := self.store.GetNodeRef(node.hash)
+	if err != nil {
+		return err
+	}
+	if ref > 0 {
+		return nil
+	}
+	buf := new(bytes.Buffer)
+	node.Serialize(buf)
+	self.store.PutNode(node.hash, buf.Bytes())
+	if !node.internal {
+		return nil
+	}
+	if err := self.incRef(node.left); err != nil {
+		return err
+	}
+	return self.incRef(node.right)
+}
+
+func (self *SparseMerkleTree) incRef(hash common.Uint256) error {
+	if hash == common.UINT256_EMPTY {
+		return nil
+	}
+	ref, err := self.store.GetNodeRef(hash)
+	if err != nil {
+		return err
+	}
+	self.store.PutNodeRef(hash, ref+1)
+	return nil
+}
+
+// decRef drops a reference to node, and deletes the node with its references to children when it is the last one
+func (self *SparseMerkleTree) decRef(hash common.Uint256) error {
+	if hash == common.UINT256_EMPTY {
+		return nil
+	}
+	ref, err := self.store.GetNodeRef(hash)
+	if err != nil {
+		return err
+	}
+	if ref > 1 {
+		self.store.PutNodeRef(hash, ref-1)
+		return nil
+	}
+	node, err := self.getNode(hash)
+	if err != nil {
+		return err
+	}
+	self.store.DeleteNode(hash)
+	if !node.internal {
+		return nil
+	}
+	if err := self.decRef(node.left); err != nil {
+		return err
+	}
+	return self.decRef(node.right)
+}
+
+func (self *smtNode) Serialize(w io.Writer) error {
+	if self.internal {
+		if err := serialization.WriteByte(w, smtInternalNode); err != nil {
+			return err
+		}
+		if err := self.left.Serialize(w); err != nil {
+			return err
+		}
+		return self.right.Serialize(w)
+	}
+	if err := serialization.WriteByte(w, smtLeafNode); err != nil {
+		return err
+	}
+	if err := self.keyHash.Serialize(w); err != nil {
+		return err
+	}
+	return serialization.WriteVarBytes(w, self.value)
+}
+
+func (self *smtNode) Deserialize(r io.Reader) error {
+	typ, err := serialization.ReadByte(r)
+	if err != nil {
+		return err
+	}
+	switch typ {
+	case smtInternalNode:
+		self.internal = true
+		if err := self.left.Deserialize(r); err != nil {
+			return err
+		}
+		return self.right.Deserialize(r)
+	case smtLeafNode:
+		if err := self.keyHash.Deserialize(r); err != nil {
+			return err
+		}
+		if self.value, err = serialization.ReadVarBytes(r); err != nil {
+			return err
+		}
+		self.valueHash = sha256.Sum256(self.value)
+		return nil
+	}
+	return fmt.Errorf("unknown node type %d", typ)
+}
+
+// VerifySparseMerkleProof verifies the value of key against the root hash of tree with the proof
+// returned by Prove, nil value verifies that the key does not exist. It returns nil when the proof is valid
+func VerifySparseMerkleProof(root common.Uint256, key, value []byte, proof *SparseMerkleProof) error {
+	if len(proof.Siblings) > SMT_MAX_DEPTH {
+		return errors.New("proof is deeper than max depth")
+	}
+	hasher := TreeHasher{}
+	keyHash := sha256.Sum256(key)
+	hash := common.UINT256_EMPTY
+	if value != nil {
+		if proof.LeafKeyHash != keyHash || proof.LeafValueHash != sha256.Sum256(value) {
+			return errors.New("leaf of proof differs from the key and value")
+		}
+		hash = hashLeaf(hasher, proof.LeafKeyHash, proof.LeafValueHash)
+	} else if proof.LeafKeyHash != common.UINT256_EMPTY {
+		if proof.LeafKeyHash == keyHash {
+			return errors.New("leaf of proof is the key")
+		}
+		for depth := uint(0); depth < uint(len(proof.Siblings)); depth++ {
+			if getBit(proof.LeafKeyHash, depth) != getBit(keyHash, depth) {
+				return errors.New("leaf of proof is not on the path of key")
+			}
+		}
+		hash = hashLeaf(hasher, proof.LeafKeyHash, proof.LeafValueHash)
+	}
+	for i := len(proof.Siblings) - 1; i >= 0; i-- {
+		if getBit(keyHash, uint(i)) == 1 {
+			hash = hasher.hash_children(proof.Siblings[i], hash)
+		} else {
+			hash = hasher.hash_children(hash, proof.Siblings[i])
+		}
+	}
+	if hash != root {
+		return fmt.Errorf("Constructed root hash differs from provided root hash. Constructed: %x, Expected: %x", hash, root)
+	}
+	return nil
+}
+
+func hashLeaf(hasher TreeHasher, keyHash, valueHash common.Uint256) common.Uint256 {
+	return hasher.hash_leaf(append(keyHash[:], valueHash[:]...))
+}
+
+func hashOfNode(node *smtNode) common.Uint256 {
+	if node == nil {
+		return common.UINT256_EMPTY
+	}
+	return node.hash
+}
+
+// getBit returns the bit of hash at depth, from the highest bit of the first byte
+func getBit(hash common.Uint256, depth uint) byte {
+	return (hash[depth/8] >> (7 - depth%8)) & 1
+}
+
+// splitLeaves returns the index of the first leaf whose bit at depth is 1
+func splitLeaves(leaves []*smtLeaf, depth uint) int {
+	return sort.Search(len(leaves), func(i int) bool {
+		return getBit(leaves[i].keyHash, depth) == 1
+	})
+}
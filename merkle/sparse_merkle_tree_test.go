@@ -0,0 +1,151 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package merkle
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSparseMerkleTreeUpdate(t *testing.T) {
+	store := NewMemNodeStore()
+	tree := NewSparseMerkleTree(common.UINT256_EMPTY, store)
+	kvs := make(map[string][]byte)
+	for i := 0; i < 100; i++ {
+		kvs[fmt.Sprintf("key%d", i)] = []byte(fmt.Sprintf("value%d", i))
+	}
+	assert.Nil(t, tree.Update(kvs))
+	root := tree.Root()
+	assert.NotEqual(t, common.UINT256_EMPTY, root)
+
+	// the root only depends on the key-value pairs, not on the order of updates
+	incremental := NewSparseMerkleTree(common.UINT256_EMPTY, NewMemNodeStore())
+	for i := 99; i >= 0; i-- {
+		key := fmt.Sprintf("key%d", i)
+		assert.Nil(t, incremental.Update(map[string][]byte{key: kvs[key], "tmp": []byte(key)}))
+	}
+	assert.Nil(t, incremental.Update(map[string][]byte{"tmp": nil}))
+	assert.Equal(t, root, incremental.Root())
+
+	assert.Nil(t, tree.Update(map[string][]byte{"key1": []byte("new"), "key2": nil}))
+	value, err := tree.Get([]byte("key1"))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("new"), value)
+	value, err = tree.Get([]byte("key2"))
+	assert.Nil(t, err)
+	assert.Nil(t, value)
+
+	// the tree of an old root can still be read
+	old := NewSparseMerkleTree(root, store)
+	value, err = old.Get([]byte("key2"))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("value2"), value)
+
+	deletes := make(map[string][]byte)
+	for k := range kvs {
+		deletes[k] = nil
+	}
+	assert.Nil(t, tree.Update(deletes))
+	assert.Equal(t, common.UINT256_EMPTY, tree.Root())
+}
+
+func TestSparseMerkleProof(t *testing.T) {
+	tree := NewSparseMerkleTree(common.UINT256_EMPTY, NewMemNodeStore())
+	value, proof, err := tree.Prove([]byte("key"))
+	assert.Nil(t, err)
+	assert.Nil(t, value)
+	assert.Nil(t, VerifySparseMerkleProof(tree.Root(), []byte("key"), nil, proof))
+
+	kvs := make(map[string][]byte)
+	for i := 0; i < 50; i++ {
+		kvs[fmt.Sprintf("key%d", i)] = []byte(fmt.Sprintf("value%d", i))
+	}
+	assert.Nil(t, tree.Update(kvs))
+	root := tree.Root()
+	for k, v := range kvs {
+		value, proof, err := tree.Prove([]byte(k))
+		assert.Nil(t, err)
+		assert.Equal(t, v, value)
+		assert.Nil(t, VerifySparseMerkleProof(root, []byte(k), v, proof))
+		assert.NotNil(t, VerifySparseMerkleProof(root, []byte(k), []byte("other"), proof))
+		assert.NotNil(t, VerifySparseMerkleProof(root, []byte(k), nil, proof))
+	}
+	for i := 50; i < 100; i++ {
+		key := []byte(fmt.Sprintf("key%d", i))
+		value, proof, err := tree.Prove(key)
+		assert.Nil(t, err)
+		assert.Nil(t, value)
+		assert.Nil(t, VerifySparseMerkleProof(root, key, nil, proof))
+		assert.NotNil(t, VerifySparseMerkleProof(root, key, []byte("value"), proof))
+	}
+
+	_, proof, err = tree.Prove([]byte("key1"))
+	assert.Nil(t, err)
+	proof.Siblings[0][0] ^= 1
+	assert.NotNil(t, VerifySparseMerkleProof(root, []byte("key1"), []byte("value1"), proof))
+}
+
+func TestSparseMerkleTreeRelease(t *testing.T) {
+	store := NewMemNodeStore().(*memNodeStore)
+	tree := NewSparseMerkleTree(common.UINT256_EMPTY, store)
+	kvs := make(map[string][]byte)
+	for i := 0; i < 100; i++ {
+		kvs[fmt.Sprintf("key%d", i)] = []byte(fmt.Sprintf("value%d", i))
+	}
+	assert.Nil(t, tree.Update(kvs))
+	assert.Nil(t, tree.Retain())
+	root1 := tree.Root()
+	nodes1 := len(store.nodes)
+
+	assert.Nil(t, tree.Update(map[string][]byte{"key1": []byte("new"), "key2": nil}))
+	assert.Nil(t, tree.Retain())
+	root2 := tree.Root()
+	assert.True(t, len(store.nodes) > nodes1)
+
+	// the nodes only referenced by the released root are deleted
+	assert.Nil(t, tree.Release(root1))
+	assert.True(t, len(store.nodes) < nodes1)
+	for k, v := range kvs {
+		value, err := tree.Get([]byte(k))
+		assert.Nil(t, err)
+		switch k {
+		case "key1":
+			assert.Equal(t, []byte("new"), value)
+		case "key2":
+			assert.Nil(t, value)
+		default:
+			assert.Equal(t, v, value)
+		}
+	}
+	_, err := NewSparseMerkleTree(root1, store).Get([]byte("key2"))
+	assert.NotNil(t, err)
+
+	// a root retained twice is kept until both are released
+	assert.Nil(t, tree.Update(map[string][]byte{}))
+	assert.Nil(t, tree.Retain())
+	assert.Nil(t, tree.Release(root2))
+	value, err := tree.Get([]byte("key1"))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("new"), value)
+	assert.Nil(t, tree.Release(root2))
+	assert.Equal(t, 0, len(store.nodes))
+	assert.Equal(t, 0, len(store.refs))
+}
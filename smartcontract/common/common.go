@@ -42,7 +42,7 @@ import (
 
 // ConvertReturnTypes return embeded stack element value
 // According item types convert to hex string value
-// Now embeded support type contain: ByteArray/Integer/Boolean/Array/Struct/Interop/StackItems
+// Now embeded support type contain: ByteArray/Integer/Boolean/Array/Struct/Map/Interop/StackItems
 func ConvertEmbededTypeHexString(item interface{}) interface{} {
 	if item == nil {
 		return nil
@@ -79,6 +79,19 @@ func ConvertEmbededTypeHexString(item interface{}) interface{} {
 			arr = append(arr, ConvertEmbededTypeHexString(val))
 		}
 		return arr
+	case *types.Map:
+		//map keys are converted to hex string as json object keys
+		res := make(map[string]interface{})
+		mp, _ := v.GetMap()
+		for key, val := range mp {
+			k, ok := ConvertEmbededTypeHexString(key).(string)
+			if !ok {
+				log.Error("[ConvertTypes] Invalid Map Key Types!")
+				return nil
+			}
+			res[k] = ConvertEmbededTypeHexString(val)
+		}
+		return res
 	case *types.Interop:
 		it, _ := v.GetInterface()
 		return common.ToHexString(it.ToArray())
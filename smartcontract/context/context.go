@@ -45,6 +45,9 @@ import (
 // when need to check authorization, use CheckWitness
 // when smart contract execute trigger event, use PushNotifications push it to smart contract notifications
 // when need to invoke a smart contract, use AppCall to invoke it
+// when a contract call another contract, create the engine of callee by NewExecuteEngine, NewWasmExecuteEngine
+// or NewNativeExecuteEngine, which fail when the call depth is over limit
+// when a contract create or migrate to new code, use VerifyCode to validate it
 type ContextRef interface {
 	PushContext(context *Context)
 	CurrentContext() *Context
@@ -54,6 +57,9 @@ type ContextRef interface {
 	CheckWitness(address common.Address) bool
 	PushNotifications(notifications []*event.NotifyEventInfo)
 	NewExecuteEngine(code []byte) (Engine, error)
+	NewWasmExecuteEngine(code []byte) (Engine, error)
+	NewNativeExecuteEngine(code []byte) (Engine, error)
+	VerifyCode(code []byte) error
 	CheckUseGas(gas uint64) bool
 	CheckExecStep() bool
 }
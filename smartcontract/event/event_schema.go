@@ -0,0 +1,171 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package event
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/core/states"
+)
+
+// DecodeNotify decode notify states to named fields by the event schemas registered by contract.
+// Notify states of embedded contract is an array whose first item is the hex encoded event name,
+// and notify message of wasm contract is a json array whose first item is the event name.
+func DecodeNotify(schemas *states.EventSchemaState, notify interface{}) (string, map[string]interface{}, error) {
+	var items []interface{}
+	switch v := notify.(type) {
+	case []interface{}:
+		items = v
+	case []string:
+		for _, s := range v {
+			items = append(items, s)
+		}
+	default:
+		return "", nil, fmt.Errorf("notify states is not array")
+	}
+	if len(items) == 0 {
+		return "", nil, fmt.Errorf("notify states is empty")
+	}
+
+	decodeField := decodeEmbeddedField
+	if msg, ok := items[0].(string); ok && len(items) == 1 && strings.HasPrefix(strings.TrimSpace(msg), "[") {
+		decoder := json.NewDecoder(strings.NewReader(msg))
+		decoder.UseNumber()
+		items = nil
+		if err := decoder.Decode(&items); err != nil {
+			return "", nil, fmt.Errorf("decode wasm notify error:%s", err)
+		}
+		if len(items) == 0 {
+			return "", nil, fmt.Errorf("notify states is empty")
+		}
+		decodeField = decodeWasmField
+	} else {
+		name, err := decodeEmbeddedField(states.EVENT_FIELD_TYPE_STRING, items[0])
+		if err != nil {
+			return "", nil, fmt.Errorf("decode event name error:%s", err)
+		}
+		items[0] = name
+	}
+
+	name, ok := items[0].(string)
+	if !ok {
+		return "", nil, fmt.Errorf("event name is not string")
+	}
+	schema := schemas.GetSchema(name)
+	if schema == nil {
+		return "", nil, fmt.Errorf("event:%s schema not registered", name)
+	}
+	values := items[1:]
+	if len(values) != len(schema.Fields) {
+		return "", nil, fmt.Errorf("event:%s fields count:%d not match schema:%d", name, len(values), len(schema.Fields))
+	}
+	fields := make(map[string]interface{}, len(values))
+	for i, field := range schema.Fields {
+		value, err := decodeField(field.Type, values[i])
+		if err != nil {
+			return "", nil, fmt.Errorf("event:%s decode field:%s error:%s", name, field.Name, err)
+		}
+		fields[field.Name] = value
+	}
+	return name, fields, nil
+}
+
+// decodeEmbeddedField decode hex string state of embedded contract
+func decodeEmbeddedField(fieldType string, value interface{}) (interface{}, error) {
+	hexStr, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("value is not hex string")
+	}
+	data, err := hex.DecodeString(hexStr)
+	if err != nil {
+		return nil, err
+	}
+	switch fieldType {
+	case states.EVENT_FIELD_TYPE_BYTE_ARRAY:
+		return hexStr, nil
+	case states.EVENT_FIELD_TYPE_STRING:
+		return string(data), nil
+	case states.EVENT_FIELD_TYPE_INTEGER:
+		return common.BigIntFromEmbeddedBytes(data), nil
+	case states.EVENT_FIELD_TYPE_BOOLEAN:
+		for _, b := range data {
+			if b != 0 {
+				return true, nil
+			}
+		}
+		return false, nil
+	case states.EVENT_FIELD_TYPE_ADDRESS:
+		address, err := common.AddressParseFromBytes(data)
+		if err != nil {
+			return nil, err
+		}
+		return address.ToBase58(), nil
+	default:
+		return nil, fmt.Errorf("unknown type:%s", fieldType)
+	}
+}
+
+// decodeWasmField decode json value of wasm contract
+func decodeWasmField(fieldType string, value interface{}) (interface{}, error) {
+	switch fieldType {
+	case states.EVENT_FIELD_TYPE_BYTE_ARRAY:
+		if v, ok := value.(string); ok {
+			if _, err := hex.DecodeString(v); err != nil {
+				return nil, err
+			}
+			return v, nil
+		}
+	case states.EVENT_FIELD_TYPE_STRING:
+		if v, ok := value.(string); ok {
+			return v, nil
+		}
+	case states.EVENT_FIELD_TYPE_INTEGER:
+		var str string
+		switch v := value.(type) {
+		case json.Number:
+			str = v.String()
+		case string:
+			str = v
+		}
+		if i, ok := new(big.Int).SetString(str, 10); ok {
+			return i, nil
+		}
+	case states.EVENT_FIELD_TYPE_BOOLEAN:
+		if v, ok := value.(bool); ok {
+			return v, nil
+		}
+	case states.EVENT_FIELD_TYPE_ADDRESS:
+		if v, ok := value.(string); ok {
+			if address, err := common.AddressFromBase58(v); err == nil {
+				return address.ToBase58(), nil
+			}
+			if address, err := common.AddressFromHexString(v); err == nil {
+				return address.ToBase58(), nil
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unknown type:%s", fieldType)
+	}
+	return nil, fmt.Errorf("value:%v does not match type:%s", value, fieldType)
+}
@@ -0,0 +1,78 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package event
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/core/states"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeNotify(t *testing.T) {
+	schemas := &states.EventSchemaState{Schemas: []*states.EventSchema{
+		{
+			Name: "transfer",
+			Fields: []*states.EventField{
+				{Name: "from", Type: states.EVENT_FIELD_TYPE_ADDRESS},
+				{Name: "amount", Type: states.EVENT_FIELD_TYPE_INTEGER},
+				{Name: "memo", Type: states.EVENT_FIELD_TYPE_STRING},
+				{Name: "data", Type: states.EVENT_FIELD_TYPE_BYTE_ARRAY},
+				{Name: "ok", Type: states.EVENT_FIELD_TYPE_BOOLEAN},
+			},
+		},
+	}}
+	from := common.Address{1, 2, 3}
+	hexStr := func(s string) string { return hex.EncodeToString([]byte(s)) }
+
+	// embedded contract notify hex states
+	name, fields, err := DecodeNotify(schemas, []interface{}{hexStr("transfer"), hex.EncodeToString(from[:]), "e803", hexStr("foo"), "0102", "01"})
+	assert.Nil(t, err)
+	assert.Equal(t, "transfer", name)
+	assert.Equal(t, map[string]interface{}{
+		"from":   from.ToBase58(),
+		"amount": big.NewInt(1000),
+		"memo":   "foo",
+		"data":   "0102",
+		"ok":     true,
+	}, fields)
+
+	// wasm contract notify json message
+	_, fields, err = DecodeNotify(schemas, []string{`["transfer","` + from.ToBase58() + `",1000,"foo","0102",true]`})
+	assert.Nil(t, err)
+	assert.Equal(t, big.NewInt(1000), fields["amount"])
+	assert.Equal(t, from.ToBase58(), fields["from"])
+	_, fields, err = DecodeNotify(schemas, []interface{}{`["transfer","` + from.ToHexString() + `","1000","foo","0102",false]`})
+	assert.Nil(t, err)
+	assert.Equal(t, from.ToBase58(), fields["from"])
+	assert.Equal(t, false, fields["ok"])
+
+	// not match schema
+	_, _, err = DecodeNotify(schemas, []interface{}{hexStr("approve"), "01"})
+	assert.NotNil(t, err)
+	_, _, err = DecodeNotify(schemas, []interface{}{hexStr("transfer"), "01"})
+	assert.NotNil(t, err)
+	_, _, err = DecodeNotify(schemas, []string{"transfer from foo"})
+	assert.NotNil(t, err)
+	_, _, err = DecodeNotify(schemas, hexStr("transfer"))
+	assert.NotNil(t, err)
+}
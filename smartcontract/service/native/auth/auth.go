@@ -693,4 +693,8 @@ func RegisterAuthContract(native *native.NativeService) {
 	native.Register("assignGIDsToRole", AssignGIDsToRole)
 	native.Register("verifyToken", VerifyToken)
 	native.Register("transfer", Transfer)
+	native.Register("getContractAdmin", GetContractAdmin)
+	native.Register("getRoles", GetRoles)
+	native.Register("getRoleGIDs", GetRoleGIDs)
+	native.Register("getDelegations", GetDelegations)
 }
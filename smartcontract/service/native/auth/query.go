@@ -0,0 +1,200 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package auth
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/imZhuFei/zeepin/common"
+	cstates "github.com/imZhuFei/zeepin/core/states"
+	scommon "github.com/imZhuFei/zeepin/core/store/common"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native"
+)
+
+/*
+ * read-only methods to audit the permissions of a contract, the items are
+ * enumerated by the key prefixes in utils.go
+ */
+
+type authItem struct {
+	suffix []byte
+	value  []byte
+}
+
+// find the items of contractAddr whose key starts with pre, sorted by key
+func findAuthItems(native *native.NativeService, contractAddr common.Address, pre []byte) ([]*authItem, error) {
+	this := native.ContextRef.CurrentContext().ContractAddress
+	prefix := append(this[:], contractAddr[:]...)
+	prefix = append(prefix, pre...)
+	stateValues, err := native.CloneCache.Find(scommon.ST_STORAGE, prefix)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]*authItem, 0, len(stateValues))
+	for _, v := range stateValues {
+		item, ok := v.Value.(*cstates.StorageItem)
+		if !ok {
+			return nil, fmt.Errorf("invalid storage item of key %x", v.Key)
+		}
+		items = append(items, &authItem{suffix: []byte(v.Key[len(prefix):]), value: item.Value})
+	}
+	return items, nil
+}
+
+func getRoles(native *native.NativeService, contractAddr common.Address) (*RoleList, error) {
+	items, err := findAuthItems(native, contractAddr, PreRoleFunc)
+	if err != nil {
+		return nil, err
+	}
+	list := &RoleList{Roles: make([]*RoleInfo, 0, len(items))}
+	for _, item := range items {
+		funcs := new(roleFuncs)
+		if err := funcs.Deserialize(bytes.NewReader(item.value)); err != nil {
+			return nil, fmt.Errorf("deserialize roleFuncs object failed. data: %x", item.value)
+		}
+		sort.Strings(funcs.funcNames)
+		list.Roles = append(list.Roles, &RoleInfo{Role: item.suffix, FuncNames: funcs.funcNames})
+	}
+	return list, nil
+}
+
+// get the active delegations of role, or of all roles if role is nil
+func getDelegations(native *native.NativeService, contractAddr common.Address, role []byte) ([]*RoleHolder, error) {
+	items, err := findAuthItems(native, contractAddr, PreDelegateStatus)
+	if err != nil {
+		return nil, err
+	}
+	holders := make([]*RoleHolder, 0)
+	for _, item := range items {
+		status := new(Status)
+		if err := status.Deserialize(bytes.NewReader(item.value)); err != nil {
+			return nil, fmt.Errorf("deserialize Status object failed. data: %x", item.value)
+		}
+		for _, s := range status.status {
+			if role != nil && !bytes.Equal(s.role, role) || native.Time >= s.expireTime {
+				continue
+			}
+			holders = append(holders, &RoleHolder{
+				GID:        item.suffix,
+				Role:       s.role,
+				Level:      uint64(s.level),
+				ExpireTime: uint64(s.expireTime),
+				Delegator:  s.root,
+			})
+		}
+	}
+	return holders, nil
+}
+
+func getRoleHolders(native *native.NativeService, contractAddr common.Address, role []byte) ([]*RoleHolder, error) {
+	items, err := findAuthItems(native, contractAddr, PreRoleToken)
+	if err != nil {
+		return nil, err
+	}
+	holders := make([]*RoleHolder, 0)
+	for _, item := range items {
+		tokens := new(roleTokens)
+		if err := tokens.Deserialize(bytes.NewReader(item.value)); err != nil {
+			return nil, fmt.Errorf("deserialize roleTokens object failed. data: %x", item.value)
+		}
+		for _, token := range tokens.tokens {
+			if !bytes.Equal(token.role, role) || token.expireTime < native.Time {
+				continue
+			}
+			holders = append(holders, &RoleHolder{
+				GID:        item.suffix,
+				Role:       token.role,
+				Level:      uint64(token.level),
+				ExpireTime: uint64(token.expireTime),
+			})
+		}
+	}
+	delegations, err := getDelegations(native, contractAddr, role)
+	if err != nil {
+		return nil, err
+	}
+	return append(holders, delegations...), nil
+}
+
+func GetContractAdmin(native *native.NativeService) ([]byte, error) {
+	param := new(ContractParam)
+	if err := param.Deserialize(bytes.NewReader(native.Input)); err != nil {
+		return nil, fmt.Errorf("[getContractAdmin] deserialize param failed: %v", err)
+	}
+	admin, err := getContractAdmin(native, param.ContractAddr)
+	if err != nil {
+		return nil, fmt.Errorf("[getContractAdmin] getContractAdmin failed: %v", err)
+	}
+	return admin, nil
+}
+
+func GetRoles(native *native.NativeService) ([]byte, error) {
+	param := new(ContractParam)
+	if err := param.Deserialize(bytes.NewReader(native.Input)); err != nil {
+		return nil, fmt.Errorf("[getRoles] deserialize param failed: %v", err)
+	}
+	list, err := getRoles(native, param.ContractAddr)
+	if err != nil {
+		return nil, fmt.Errorf("[getRoles] getRoles failed: %v", err)
+	}
+	bf := new(bytes.Buffer)
+	if err := list.Serialize(bf); err != nil {
+		return nil, fmt.Errorf("[getRoles] serialize roles failed: %v", err)
+	}
+	return bf.Bytes(), nil
+}
+
+// get the GIDs holding the role, assigned by the admin or delegated and not expired
+func GetRoleGIDs(native *native.NativeService) ([]byte, error) {
+	param := new(RoleParam)
+	if err := param.Deserialize(bytes.NewReader(native.Input)); err != nil {
+		return nil, fmt.Errorf("[getRoleGIDs] deserialize param failed: %v", err)
+	}
+	if len(param.Role) == 0 {
+		return nil, fmt.Errorf("[getRoleGIDs] invalid param: role is nil")
+	}
+	holders, err := getRoleHolders(native, param.ContractAddr, param.Role)
+	if err != nil {
+		return nil, fmt.Errorf("[getRoleGIDs] getRoleHolders failed: %v", err)
+	}
+	bf := new(bytes.Buffer)
+	if err := (&RoleHolderList{Holders: holders}).Serialize(bf); err != nil {
+		return nil, fmt.Errorf("[getRoleGIDs] serialize holders failed: %v", err)
+	}
+	return bf.Bytes(), nil
+}
+
+// get the delegations of all roles which are not expired
+func GetDelegations(native *native.NativeService) ([]byte, error) {
+	param := new(ContractParam)
+	if err := param.Deserialize(bytes.NewReader(native.Input)); err != nil {
+		return nil, fmt.Errorf("[getDelegations] deserialize param failed: %v", err)
+	}
+	holders, err := getDelegations(native, param.ContractAddr, nil)
+	if err != nil {
+		return nil, fmt.Errorf("[getDelegations] getDelegations failed: %v", err)
+	}
+	bf := new(bytes.Buffer)
+	if err := (&RoleHolderList{Holders: holders}).Serialize(bf); err != nil {
+		return nil, fmt.Errorf("[getDelegations] serialize delegations failed: %v", err)
+	}
+	return bf.Bytes(), nil
+}
@@ -0,0 +1,105 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package auth
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/smartcontract/context"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/testutil"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func query(t *testing.T, srvc *native.NativeService, handler native.Handler, param interface {
+	Serialize(w io.Writer) error
+}) []byte {
+	bf := new(bytes.Buffer)
+	assert.Nil(t, param.Serialize(bf))
+	srvc.Input = bf.Bytes()
+	ret, err := handler(srvc)
+	assert.Nil(t, err)
+	return ret
+}
+
+func TestQuery(t *testing.T) {
+	ctx := testutil.NewContext()
+	ctx.PushContext(&context.Context{ContractAddress: utils.AuthContractAddress})
+	srvc, closeStore := testutil.NewNativeService(t, ctx)
+	defer closeStore()
+	srvc.Time = 1000
+	contract := common.Address{0x01}
+	other := common.Address{0x02}
+	admin, alice, bob := []byte("did:zpt:admin"), []byte("did:zpt:alice"), []byte("did:zpt:bob")
+	assert.Nil(t, putContractAdmin(srvc, contract, admin))
+	assert.Nil(t, putRoleFunc(srvc, contract, []byte("writer"), &roleFuncs{[]string{"put", "del"}}))
+	assert.Nil(t, putRoleFunc(srvc, contract, []byte("reader"), &roleFuncs{[]string{"get"}}))
+	assert.Nil(t, putRoleFunc(srvc, other, []byte("owner"), &roleFuncs{[]string{"all"}}))
+	assert.Nil(t, putGIDToken(srvc, contract, alice, &roleTokens{[]*AuthToken{
+		{role: []byte("writer"), expireTime: 2000, level: 2},
+		{role: []byte("reader"), expireTime: 500, level: 1},
+	}}))
+	assert.Nil(t, putDelegateStatus(srvc, contract, bob, &Status{[]*DelegateStatus{
+		{root: alice, AuthToken: AuthToken{role: []byte("writer"), expireTime: 1500, level: 1}},
+		{root: alice, AuthToken: AuthToken{role: []byte("reader"), expireTime: 1000, level: 1}},
+	}}))
+
+	ret := query(t, srvc, GetContractAdmin, &ContractParam{ContractAddr: contract})
+	assert.Equal(t, admin, ret)
+
+	ret = query(t, srvc, GetRoles, &ContractParam{ContractAddr: contract})
+	roles := new(RoleList)
+	assert.Nil(t, roles.Deserialize(bytes.NewReader(ret)))
+	assert.Equal(t, 2, len(roles.Roles))
+	assert.Equal(t, []byte("reader"), roles.Roles[0].Role)
+	assert.Equal(t, []string{"get"}, roles.Roles[0].FuncNames)
+	assert.Equal(t, []byte("writer"), roles.Roles[1].Role)
+	assert.Equal(t, []string{"del", "put"}, roles.Roles[1].FuncNames)
+
+	// the token of alice for reader and the delegation to bob are expired
+	ret = query(t, srvc, GetRoleGIDs, &RoleParam{ContractAddr: contract, Role: []byte("reader")})
+	holders := new(RoleHolderList)
+	assert.Nil(t, holders.Deserialize(bytes.NewReader(ret)))
+	assert.Equal(t, 0, len(holders.Holders))
+
+	ret = query(t, srvc, GetRoleGIDs, &RoleParam{ContractAddr: contract, Role: []byte("writer")})
+	holders = new(RoleHolderList)
+	assert.Nil(t, holders.Deserialize(bytes.NewReader(ret)))
+	assert.Equal(t, 2, len(holders.Holders))
+	assert.Equal(t, alice, holders.Holders[0].GID)
+	assert.Equal(t, uint64(2), holders.Holders[0].Level)
+	assert.Equal(t, uint64(2000), holders.Holders[0].ExpireTime)
+	assert.Equal(t, 0, len(holders.Holders[0].Delegator))
+	assert.Equal(t, bob, holders.Holders[1].GID)
+	assert.Equal(t, alice, holders.Holders[1].Delegator)
+
+	ret = query(t, srvc, GetDelegations, &ContractParam{ContractAddr: contract})
+	holders = new(RoleHolderList)
+	assert.Nil(t, holders.Deserialize(bytes.NewReader(ret)))
+	assert.Equal(t, 1, len(holders.Holders))
+	assert.Equal(t, uint64(1500), holders.Holders[0].ExpireTime)
+
+	srvc.Input = []byte{0x01}
+	_, err := GetRoles(srvc)
+	assert.NotNil(t, err)
+}
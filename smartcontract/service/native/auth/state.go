@@ -38,6 +38,7 @@ import (
 	"io"
 
 	"github.com/imZhuFei/zeepin/common/serialization"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/utils"
 )
 
 /*
@@ -203,3 +204,161 @@ func (this *roleTokens) Deserialize(rd io.Reader) error {
 	}
 	return nil
 }
+
+/*
+ * results of the query methods
+ */
+type RoleInfo struct {
+	Role      []byte
+	FuncNames []string
+}
+
+func (this *RoleInfo) Serialize(w io.Writer) error {
+	if err := serialization.WriteVarBytes(w, this.Role); err != nil {
+		return err
+	}
+	if err := utils.WriteVarUint(w, uint64(len(this.FuncNames))); err != nil {
+		return err
+	}
+	for _, fn := range this.FuncNames {
+		if err := serialization.WriteString(w, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (this *RoleInfo) Deserialize(rd io.Reader) error {
+	var err error
+	if this.Role, err = serialization.ReadVarBytes(rd); err != nil {
+		return err
+	}
+	fnLen, err := utils.ReadVarUint(rd)
+	if err != nil {
+		return err
+	}
+	this.FuncNames = make([]string, 0)
+	for i := uint64(0); i < fnLen; i++ {
+		fn, err := serialization.ReadString(rd)
+		if err != nil {
+			return err
+		}
+		this.FuncNames = append(this.FuncNames, fn)
+	}
+	return nil
+}
+
+type RoleList struct {
+	Roles []*RoleInfo
+}
+
+func (this *RoleList) Serialize(w io.Writer) error {
+	if err := utils.WriteVarUint(w, uint64(len(this.Roles))); err != nil {
+		return err
+	}
+	for _, role := range this.Roles {
+		if err := role.Serialize(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (this *RoleList) Deserialize(rd io.Reader) error {
+	rLen, err := utils.ReadVarUint(rd)
+	if err != nil {
+		return err
+	}
+	this.Roles = make([]*RoleInfo, 0)
+	for i := uint64(0); i < rLen; i++ {
+		role := new(RoleInfo)
+		if err := role.Deserialize(rd); err != nil {
+			return err
+		}
+		this.Roles = append(this.Roles, role)
+	}
+	return nil
+}
+
+/*
+ * a GID holding a role, Delegator is empty if the role is assigned by the
+ * admin and is the GID which delegates the role otherwise
+ */
+type RoleHolder struct {
+	GID        []byte
+	Role       []byte
+	Level      uint64
+	ExpireTime uint64
+	Delegator  []byte
+}
+
+func (this *RoleHolder) Serialize(w io.Writer) error {
+	if err := serialization.WriteVarBytes(w, this.GID); err != nil {
+		return err
+	}
+	if err := serialization.WriteVarBytes(w, this.Role); err != nil {
+		return err
+	}
+	if err := utils.WriteVarUint(w, this.Level); err != nil {
+		return err
+	}
+	if err := utils.WriteVarUint(w, this.ExpireTime); err != nil {
+		return err
+	}
+	if err := serialization.WriteVarBytes(w, this.Delegator); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (this *RoleHolder) Deserialize(rd io.Reader) error {
+	var err error
+	if this.GID, err = serialization.ReadVarBytes(rd); err != nil {
+		return err
+	}
+	if this.Role, err = serialization.ReadVarBytes(rd); err != nil {
+		return err
+	}
+	if this.Level, err = utils.ReadVarUint(rd); err != nil {
+		return err
+	}
+	if this.ExpireTime, err = utils.ReadVarUint(rd); err != nil {
+		return err
+	}
+	if this.Delegator, err = serialization.ReadVarBytes(rd); err != nil {
+		return err
+	}
+	return nil
+}
+
+type RoleHolderList struct {
+	Holders []*RoleHolder
+}
+
+func (this *RoleHolderList) Serialize(w io.Writer) error {
+	if err := utils.WriteVarUint(w, uint64(len(this.Holders))); err != nil {
+		return err
+	}
+	for _, holder := range this.Holders {
+		if err := holder.Serialize(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (this *RoleHolderList) Deserialize(rd io.Reader) error {
+	hLen, err := utils.ReadVarUint(rd)
+	if err != nil {
+		return err
+	}
+	this.Holders = make([]*RoleHolder, 0)
+	for i := uint64(0); i < hLen; i++ {
+		holder := new(RoleHolder)
+		if err := holder.Deserialize(rd); err != nil {
+			return err
+		}
+		this.Holders = append(this.Holders, holder)
+	}
+	return nil
+}
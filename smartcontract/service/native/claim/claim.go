@@ -0,0 +1,173 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package claim implements the verifiable claim registry native contract.
+// An issuer GID commits the hash of a claim about a subject GID, signed by
+// one of its keys, and may later revoke it. Third parties look the claim up
+// by its hash to check who issued it and whether it is still valid.
+package claim
+
+import (
+	"bytes"
+
+	"github.com/imZhuFei/zeepin/common/serialization"
+	"github.com/imZhuFei/zeepin/errors"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/utils"
+)
+
+func InitClaim() {
+	native.Contracts[utils.ClaimContractAddress] = RegisterClaimContract
+}
+
+func RegisterClaimContract(native *native.NativeService) {
+	if !utils.IsNativeContractHeight(native.Height) {
+		return
+	}
+	native.Register(COMMIT_NAME, Commit)
+	native.Register(REVOKE_NAME, Revoke)
+	native.Register(GETCLAIM_NAME, GetClaim)
+	native.Register(CLAIMSOF_NAME, ClaimsOf)
+}
+
+func Commit(native *native.NativeService) ([]byte, error) {
+	param := new(CommitParam)
+	if err := param.Deserialize(bytes.NewBuffer(native.Input)); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[Commit] param deserialize error!")
+	}
+	if err := checkClaimHash(param.ClaimHash); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[Commit] invalid claim hash!")
+	}
+	if err := checkGID(param.Issuer); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[Commit] invalid issuer!")
+	}
+	if err := checkGID(param.Subject); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[Commit] invalid subject!")
+	}
+	if param.Expiry != 0 && param.Expiry <= uint64(native.Time) {
+		return utils.BYTE_FALSE, errors.NewErr("[Commit] expiry is not in the future!")
+	}
+	contract := native.ContextRef.CurrentContext().ContractAddress
+	claim, err := getClaim(native, contract, param.ClaimHash)
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	if claim != nil {
+		return utils.BYTE_FALSE, errors.NewErr("[Commit] claim already committed!")
+	}
+	if err := verifyIssuer(native, param.Issuer, param.KeyNo); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[Commit] verify issuer error!")
+	}
+	if err := checkRegistered(native, param.Subject); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[Commit] check subject error!")
+	}
+
+	claim = &Claim{
+		ClaimHash: param.ClaimHash,
+		Issuer:    param.Issuer,
+		Subject:   param.Subject,
+		Status:    STATUS_VALID,
+		IssuedAt:  uint64(native.Time),
+		Expiry:    param.Expiry,
+	}
+	if err := putClaim(native, contract, claim); err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	err = utils.LinkedlistInsert(native, genSubjectClaimsKey(contract, param.Subject), param.ClaimHash, []byte{})
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[Commit] insert subject claim error!")
+	}
+	addCommitNotification(native, contract, claim)
+	return utils.BYTE_TRUE, nil
+}
+
+// Revoke marks the claim as revoked, the record is kept so that holders of the
+// claim can still learn that it was revoked and when
+func Revoke(native *native.NativeService) ([]byte, error) {
+	param := new(RevokeParam)
+	if err := param.Deserialize(bytes.NewBuffer(native.Input)); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[Revoke] param deserialize error!")
+	}
+	contract := native.ContextRef.CurrentContext().ContractAddress
+	claim, err := getClaim(native, contract, param.ClaimHash)
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	if claim == nil {
+		return utils.BYTE_FALSE, errors.NewErr("[Revoke] claim not exist!")
+	}
+	if !bytes.Equal(claim.Issuer, param.Issuer) {
+		return utils.BYTE_FALSE, errors.NewErr("[Revoke] revoker is not the issuer of claim!")
+	}
+	if claim.Status == STATUS_REVOKED {
+		return utils.BYTE_FALSE, errors.NewErr("[Revoke] claim already revoked!")
+	}
+	if err := verifyIssuer(native, param.Issuer, param.KeyNo); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[Revoke] verify issuer error!")
+	}
+
+	claim.Status = STATUS_REVOKED
+	claim.RevokedAt = uint64(native.Time)
+	if err := putClaim(native, contract, claim); err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	addRevokeNotification(native, contract, claim)
+	return utils.BYTE_TRUE, nil
+}
+
+// GetClaim returns the claim of the hash, with its status at the current block
+// time
+func GetClaim(native *native.NativeService) ([]byte, error) {
+	claimHash, err := serialization.ReadVarBytes(bytes.NewBuffer(native.Input))
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[GetClaim] get claim hash error!")
+	}
+	claim, err := getClaim(native, native.ContextRef.CurrentContext().ContractAddress, claimHash)
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	if claim == nil {
+		return utils.BYTE_FALSE, errors.NewErr("[GetClaim] claim not exist!")
+	}
+	claim.Status = queryStatus(native, claim)
+	bf := new(bytes.Buffer)
+	if err := claim.Serialize(bf); err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	return bf.Bytes(), nil
+}
+
+// ClaimsOf returns the hashes of claims about the subject, most recent first
+func ClaimsOf(native *native.NativeService) ([]byte, error) {
+	param := new(ClaimsOfParam)
+	if err := param.Deserialize(bytes.NewBuffer(native.Input)); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[ClaimsOf] param deserialize error!")
+	}
+	if err := checkGID(param.Subject); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[ClaimsOf] invalid subject!")
+	}
+	list, err := getSubjectClaims(native, native.ContextRef.CurrentContext().ContractAddress, param)
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	bf := new(bytes.Buffer)
+	if err := list.Serialize(bf); err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	return bf.Bytes(), nil
+}
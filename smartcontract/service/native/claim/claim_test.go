@@ -0,0 +1,192 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package claim
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/imZhuFei/zeepin/account"
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/common/config"
+	"github.com/imZhuFei/zeepin/common/log"
+	"github.com/imZhuFei/zeepin/common/serialization"
+	"github.com/imZhuFei/zeepin/core/types"
+	"github.com/imZhuFei/zeepin/smartcontract/context"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/gid"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/testutil"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/utils"
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/stretchr/testify/assert"
+)
+
+type identity struct {
+	id   []byte
+	keys [][]byte
+	addr []common.Address
+}
+
+func newIdentity(t *testing.T) *identity {
+	id, err := account.GenerateID()
+	assert.Nil(t, err)
+	ident := &identity{id: []byte(id)}
+	ident.newKey(t)
+	return ident
+}
+
+func (this *identity) newKey(t *testing.T) {
+	_, pub, err := keypair.GenerateKeyPair(keypair.PK_ECDSA, keypair.P256)
+	assert.Nil(t, err)
+	this.keys = append(this.keys, keypair.SerializePublicKey(pub))
+	this.addr = append(this.addr, types.AddressFromPubKey(pub))
+}
+
+func call(srvc *native.NativeService, contract common.Address, method string, param interface {
+	Serialize(w io.Writer) error
+}) ([]byte, error) {
+	bf := new(bytes.Buffer)
+	if err := param.Serialize(bf); err != nil {
+		return nil, err
+	}
+	ret, err := srvc.NativeCall(contract, method, bf.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return ret.([]byte), nil
+}
+
+type gidArgs [][]byte
+
+func (this gidArgs) Serialize(w io.Writer) error {
+	for _, arg := range this {
+		if err := serialization.WriteVarBytes(w, arg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type hashArg []byte
+
+func (this hashArg) Serialize(w io.Writer) error {
+	return serialization.WriteVarBytes(w, this)
+}
+
+func getClaimOf(t *testing.T, srvc *native.NativeService, claimHash []byte) *Claim {
+	ret, err := call(srvc, utils.ClaimContractAddress, GETCLAIM_NAME, hashArg(claimHash))
+	assert.Nil(t, err)
+	claim := new(Claim)
+	assert.Nil(t, claim.Deserialize(bytes.NewBuffer(ret)))
+	return claim
+}
+
+func TestClaim(t *testing.T) {
+	log.InitLog(log.InfoLog, log.Stdout)
+	gid.Init()
+	InitClaim()
+	networkId := config.DefConfig.P2PNode.NetworkId
+	defer func() { config.DefConfig.P2PNode.NetworkId = networkId }()
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_SOLO_NET
+	issuer, subject, other := newIdentity(t), newIdentity(t), newIdentity(t)
+	ctx := testutil.NewContext(issuer.addr[0], subject.addr[0])
+	ctx.PushContext(&context.Context{})
+	srvc, closeStore := testutil.NewNativeService(t, ctx)
+	defer closeStore()
+	srvc.Time = 1000
+	config.DefConfig.Common.EnableEventLog = true
+	for _, ident := range []*identity{issuer, subject} {
+		_, err := call(srvc, utils.GIDContractAddress, "regIDWithPublicKey", gidArgs{ident.id, ident.keys[0]})
+		assert.Nil(t, err)
+	}
+
+	badge := bytes.Repeat([]byte{1}, CLAIM_HASH_LEN)
+	commit := &CommitParam{ClaimHash: badge, Issuer: issuer.id, KeyNo: 1, Subject: subject.id, Expiry: 2000}
+	_, err := call(srvc, utils.ClaimContractAddress, COMMIT_NAME, &CommitParam{ClaimHash: badge[1:],
+		Issuer: issuer.id, KeyNo: 1, Subject: subject.id})
+	assert.NotNil(t, err)
+	_, err = call(srvc, utils.ClaimContractAddress, COMMIT_NAME, &CommitParam{ClaimHash: badge,
+		Issuer: issuer.id, KeyNo: 1, Subject: other.id})
+	assert.NotNil(t, err)
+	_, err = call(srvc, utils.ClaimContractAddress, COMMIT_NAME, &CommitParam{ClaimHash: badge,
+		Issuer: other.id, KeyNo: 1, Subject: subject.id})
+	assert.NotNil(t, err)
+	_, err = call(srvc, utils.ClaimContractAddress, COMMIT_NAME, commit)
+	assert.Nil(t, err)
+	_, err = call(srvc, utils.ClaimContractAddress, COMMIT_NAME, commit)
+	assert.NotNil(t, err)
+
+	claim := getClaimOf(t, srvc, badge)
+	assert.Equal(t, &Claim{ClaimHash: badge, Issuer: issuer.id, Subject: subject.id, Status: STATUS_VALID,
+		IssuedAt: 1000, Expiry: 2000}, claim)
+	srvc.Time = 2000
+	assert.Equal(t, uint64(STATUS_EXPIRED), getClaimOf(t, srvc, badge).Status)
+
+	// a key removed from the issuer can not sign claims any more
+	issuer.newKey(t)
+	_, err = call(srvc, utils.GIDContractAddress, "addKey", gidArgs{issuer.id, issuer.keys[1], issuer.keys[0]})
+	assert.Nil(t, err)
+	_, err = call(srvc, utils.GIDContractAddress, "removeKey", gidArgs{issuer.id, issuer.keys[0], issuer.keys[0]})
+	assert.Nil(t, err)
+	second := bytes.Repeat([]byte{2}, CLAIM_HASH_LEN)
+	_, err = call(srvc, utils.ClaimContractAddress, COMMIT_NAME, &CommitParam{ClaimHash: second,
+		Issuer: issuer.id, KeyNo: 1, Subject: subject.id})
+	assert.NotNil(t, err)
+	ctx.Witnesses[issuer.addr[1]] = true
+	_, err = call(srvc, utils.ClaimContractAddress, COMMIT_NAME, &CommitParam{ClaimHash: second,
+		Issuer: issuer.id, KeyNo: 2, Subject: subject.id})
+	assert.Nil(t, err)
+
+	_, err = call(srvc, utils.ClaimContractAddress, REVOKE_NAME, &RevokeParam{ClaimHash: second, Issuer: subject.id, KeyNo: 1})
+	assert.NotNil(t, err)
+	_, err = call(srvc, utils.ClaimContractAddress, REVOKE_NAME, &RevokeParam{ClaimHash: second, Issuer: issuer.id, KeyNo: 2})
+	assert.Nil(t, err)
+	_, err = call(srvc, utils.ClaimContractAddress, REVOKE_NAME, &RevokeParam{ClaimHash: second, Issuer: issuer.id, KeyNo: 2})
+	assert.NotNil(t, err)
+	claim = getClaimOf(t, srvc, second)
+	assert.Equal(t, uint64(STATUS_REVOKED), claim.Status)
+	assert.Equal(t, uint64(2000), claim.RevokedAt)
+
+	ret, err := call(srvc, utils.ClaimContractAddress, CLAIMSOF_NAME, &ClaimsOfParam{Subject: subject.id, Limit: 1})
+	assert.Nil(t, err)
+	list := new(ClaimList)
+	assert.Nil(t, list.Deserialize(bytes.NewBuffer(ret)))
+	assert.Equal(t, [][]byte{second}, list.ClaimHashes)
+	assert.Equal(t, badge, list.Next)
+	ret, err = call(srvc, utils.ClaimContractAddress, CLAIMSOF_NAME, &ClaimsOfParam{Subject: subject.id, Start: list.Next})
+	assert.Nil(t, err)
+	assert.Nil(t, list.Deserialize(bytes.NewBuffer(ret)))
+	assert.Equal(t, [][]byte{badge}, list.ClaimHashes)
+	assert.Equal(t, 0, len(list.Next))
+}
+
+func TestRegisterClaimContract(t *testing.T) {
+	networkId := config.DefConfig.P2PNode.NetworkId
+	defer func() { config.DefConfig.P2PNode.NetworkId = networkId }()
+
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_MAIN_NET
+	srvc := &native.NativeService{ServiceMap: make(map[string]native.Handler), Height: 10}
+	RegisterClaimContract(srvc)
+	assert.Empty(t, srvc.ServiceMap)
+
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_SOLO_NET
+	RegisterClaimContract(srvc)
+	assert.Contains(t, srvc.ServiceMap, COMMIT_NAME)
+}
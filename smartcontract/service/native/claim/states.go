@@ -0,0 +1,249 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package claim
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/imZhuFei/zeepin/common/serialization"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/utils"
+)
+
+// Claim is the record committed by an issuer GID about a subject GID, the
+// claim content itself is kept off chain and only its hash is recorded
+type Claim struct {
+	ClaimHash []byte
+	Issuer    []byte
+	Subject   []byte
+	Status    uint64
+	IssuedAt  uint64
+	Expiry    uint64
+	RevokedAt uint64
+}
+
+func (this *Claim) Serialize(w io.Writer) error {
+	if err := serialization.WriteVarBytes(w, this.ClaimHash); err != nil {
+		return fmt.Errorf("[Claim] serialize claim hash error:%v", err)
+	}
+	if err := serialization.WriteVarBytes(w, this.Issuer); err != nil {
+		return fmt.Errorf("[Claim] serialize issuer error:%v", err)
+	}
+	if err := serialization.WriteVarBytes(w, this.Subject); err != nil {
+		return fmt.Errorf("[Claim] serialize subject error:%v", err)
+	}
+	if err := utils.WriteVarUint(w, this.Status); err != nil {
+		return fmt.Errorf("[Claim] serialize status error:%v", err)
+	}
+	if err := utils.WriteVarUint(w, this.IssuedAt); err != nil {
+		return fmt.Errorf("[Claim] serialize issued at error:%v", err)
+	}
+	if err := utils.WriteVarUint(w, this.Expiry); err != nil {
+		return fmt.Errorf("[Claim] serialize expiry error:%v", err)
+	}
+	if err := utils.WriteVarUint(w, this.RevokedAt); err != nil {
+		return fmt.Errorf("[Claim] serialize revoked at error:%v", err)
+	}
+	return nil
+}
+
+func (this *Claim) Deserialize(r io.Reader) error {
+	var err error
+	if this.ClaimHash, err = serialization.ReadVarBytes(r); err != nil {
+		return fmt.Errorf("[Claim] deserialize claim hash error:%v", err)
+	}
+	if this.Issuer, err = serialization.ReadVarBytes(r); err != nil {
+		return fmt.Errorf("[Claim] deserialize issuer error:%v", err)
+	}
+	if this.Subject, err = serialization.ReadVarBytes(r); err != nil {
+		return fmt.Errorf("[Claim] deserialize subject error:%v", err)
+	}
+	if this.Status, err = utils.ReadVarUint(r); err != nil {
+		return fmt.Errorf("[Claim] deserialize status error:%v", err)
+	}
+	if this.IssuedAt, err = utils.ReadVarUint(r); err != nil {
+		return fmt.Errorf("[Claim] deserialize issued at error:%v", err)
+	}
+	if this.Expiry, err = utils.ReadVarUint(r); err != nil {
+		return fmt.Errorf("[Claim] deserialize expiry error:%v", err)
+	}
+	if this.RevokedAt, err = utils.ReadVarUint(r); err != nil {
+		return fmt.Errorf("[Claim] deserialize revoked at error:%v", err)
+	}
+	return nil
+}
+
+// CommitParam commits a claim signed by the KeyNo-th key of the issuer GID,
+// an Expiry of 0 means the claim never expires
+type CommitParam struct {
+	ClaimHash []byte
+	Issuer    []byte
+	KeyNo     uint64
+	Subject   []byte
+	Expiry    uint64
+}
+
+func (this *CommitParam) Serialize(w io.Writer) error {
+	if err := serialization.WriteVarBytes(w, this.ClaimHash); err != nil {
+		return fmt.Errorf("[CommitParam] serialize claim hash error:%v", err)
+	}
+	if err := serialization.WriteVarBytes(w, this.Issuer); err != nil {
+		return fmt.Errorf("[CommitParam] serialize issuer error:%v", err)
+	}
+	if err := utils.WriteVarUint(w, this.KeyNo); err != nil {
+		return fmt.Errorf("[CommitParam] serialize key no error:%v", err)
+	}
+	if err := serialization.WriteVarBytes(w, this.Subject); err != nil {
+		return fmt.Errorf("[CommitParam] serialize subject error:%v", err)
+	}
+	if err := utils.WriteVarUint(w, this.Expiry); err != nil {
+		return fmt.Errorf("[CommitParam] serialize expiry error:%v", err)
+	}
+	return nil
+}
+
+func (this *CommitParam) Deserialize(r io.Reader) error {
+	var err error
+	if this.ClaimHash, err = serialization.ReadVarBytes(r); err != nil {
+		return fmt.Errorf("[CommitParam] deserialize claim hash error:%v", err)
+	}
+	if this.Issuer, err = serialization.ReadVarBytes(r); err != nil {
+		return fmt.Errorf("[CommitParam] deserialize issuer error:%v", err)
+	}
+	if this.KeyNo, err = utils.ReadVarUint(r); err != nil {
+		return fmt.Errorf("[CommitParam] deserialize key no error:%v", err)
+	}
+	if this.Subject, err = serialization.ReadVarBytes(r); err != nil {
+		return fmt.Errorf("[CommitParam] deserialize subject error:%v", err)
+	}
+	if this.Expiry, err = utils.ReadVarUint(r); err != nil {
+		return fmt.Errorf("[CommitParam] deserialize expiry error:%v", err)
+	}
+	return nil
+}
+
+// RevokeParam revokes a claim, signed by the KeyNo-th key of its issuer GID
+type RevokeParam struct {
+	ClaimHash []byte
+	Issuer    []byte
+	KeyNo     uint64
+}
+
+func (this *RevokeParam) Serialize(w io.Writer) error {
+	if err := serialization.WriteVarBytes(w, this.ClaimHash); err != nil {
+		return fmt.Errorf("[RevokeParam] serialize claim hash error:%v", err)
+	}
+	if err := serialization.WriteVarBytes(w, this.Issuer); err != nil {
+		return fmt.Errorf("[RevokeParam] serialize issuer error:%v", err)
+	}
+	if err := utils.WriteVarUint(w, this.KeyNo); err != nil {
+		return fmt.Errorf("[RevokeParam] serialize key no error:%v", err)
+	}
+	return nil
+}
+
+func (this *RevokeParam) Deserialize(r io.Reader) error {
+	var err error
+	if this.ClaimHash, err = serialization.ReadVarBytes(r); err != nil {
+		return fmt.Errorf("[RevokeParam] deserialize claim hash error:%v", err)
+	}
+	if this.Issuer, err = serialization.ReadVarBytes(r); err != nil {
+		return fmt.Errorf("[RevokeParam] deserialize issuer error:%v", err)
+	}
+	if this.KeyNo, err = utils.ReadVarUint(r); err != nil {
+		return fmt.Errorf("[RevokeParam] deserialize key no error:%v", err)
+	}
+	return nil
+}
+
+// ClaimsOfParam pages through the claims about Subject, an empty Start means
+// the most recent claim and a Limit of 0 means MAX_CLAIMSOF_LIMIT
+type ClaimsOfParam struct {
+	Subject []byte
+	Start   []byte
+	Limit   uint64
+}
+
+func (this *ClaimsOfParam) Serialize(w io.Writer) error {
+	if err := serialization.WriteVarBytes(w, this.Subject); err != nil {
+		return fmt.Errorf("[ClaimsOfParam] serialize subject error:%v", err)
+	}
+	if err := serialization.WriteVarBytes(w, this.Start); err != nil {
+		return fmt.Errorf("[ClaimsOfParam] serialize start error:%v", err)
+	}
+	if err := utils.WriteVarUint(w, this.Limit); err != nil {
+		return fmt.Errorf("[ClaimsOfParam] serialize limit error:%v", err)
+	}
+	return nil
+}
+
+func (this *ClaimsOfParam) Deserialize(r io.Reader) error {
+	var err error
+	if this.Subject, err = serialization.ReadVarBytes(r); err != nil {
+		return fmt.Errorf("[ClaimsOfParam] deserialize subject error:%v", err)
+	}
+	if this.Start, err = serialization.ReadVarBytes(r); err != nil {
+		return fmt.Errorf("[ClaimsOfParam] deserialize start error:%v", err)
+	}
+	if this.Limit, err = utils.ReadVarUint(r); err != nil {
+		return fmt.Errorf("[ClaimsOfParam] deserialize limit error:%v", err)
+	}
+	return nil
+}
+
+// ClaimList is a page of claim hashes, Next is the Start of the next page and
+// is empty on the last page
+type ClaimList struct {
+	ClaimHashes [][]byte
+	Next        []byte
+}
+
+func (this *ClaimList) Serialize(w io.Writer) error {
+	if err := utils.WriteVarUint(w, uint64(len(this.ClaimHashes))); err != nil {
+		return fmt.Errorf("[ClaimList] serialize claim hashes length error:%v", err)
+	}
+	for _, hash := range this.ClaimHashes {
+		if err := serialization.WriteVarBytes(w, hash); err != nil {
+			return fmt.Errorf("[ClaimList] serialize claim hash error:%v", err)
+		}
+	}
+	if err := serialization.WriteVarBytes(w, this.Next); err != nil {
+		return fmt.Errorf("[ClaimList] serialize next error:%v", err)
+	}
+	return nil
+}
+
+func (this *ClaimList) Deserialize(r io.Reader) error {
+	n, err := utils.ReadVarUint(r)
+	if err != nil {
+		return fmt.Errorf("[ClaimList] deserialize claim hashes length error:%v", err)
+	}
+	this.ClaimHashes = make([][]byte, 0)
+	for i := uint64(0); i < n; i++ {
+		hash, err := serialization.ReadVarBytes(r)
+		if err != nil {
+			return fmt.Errorf("[ClaimList] deserialize claim hash error:%v", err)
+		}
+		this.ClaimHashes = append(this.ClaimHashes, hash)
+	}
+	if this.Next, err = serialization.ReadVarBytes(r); err != nil {
+		return fmt.Errorf("[ClaimList] deserialize next error:%v", err)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package claim
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClaim_Serialize(t *testing.T) {
+	claim := &Claim{
+		ClaimHash: bytes.Repeat([]byte{1}, CLAIM_HASH_LEN),
+		Issuer:    []byte("did:zpt:issuer"),
+		Subject:   []byte("did:zpt:subject"),
+		Status:    STATUS_REVOKED,
+		IssuedAt:  1000,
+		Expiry:    2000,
+		RevokedAt: 1500,
+	}
+	bf := new(bytes.Buffer)
+	assert.Nil(t, claim.Serialize(bf))
+	claim2 := new(Claim)
+	assert.Nil(t, claim2.Deserialize(bf))
+	assert.Equal(t, claim, claim2)
+}
+
+func TestCommitParam_Serialize(t *testing.T) {
+	param := &CommitParam{
+		ClaimHash: bytes.Repeat([]byte{1}, CLAIM_HASH_LEN),
+		Issuer:    []byte("did:zpt:issuer"),
+		KeyNo:     1,
+		Subject:   []byte("did:zpt:subject"),
+	}
+	bf := new(bytes.Buffer)
+	assert.Nil(t, param.Serialize(bf))
+	param2 := new(CommitParam)
+	assert.Nil(t, param2.Deserialize(bf))
+	assert.Equal(t, param, param2)
+}
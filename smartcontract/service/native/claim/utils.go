@@ -0,0 +1,223 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package claim
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/common/config"
+	"github.com/imZhuFei/zeepin/common/serialization"
+	cstates "github.com/imZhuFei/zeepin/core/states"
+	scommon "github.com/imZhuFei/zeepin/core/store/common"
+	"github.com/imZhuFei/zeepin/errors"
+	"github.com/imZhuFei/zeepin/smartcontract/event"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/utils"
+)
+
+const (
+	COMMIT_NAME   = "commit"
+	REVOKE_NAME   = "revoke"
+	GETCLAIM_NAME = "getClaim"
+	CLAIMSOF_NAME = "claimsOf"
+
+	//storage key prefix
+	CLAIM          = "claim"
+	SUBJECT_CLAIMS = "subjectClaims"
+
+	//claim status, STATUS_EXPIRED is only reported by queries
+	STATUS_VALID   = 1
+	STATUS_REVOKED = 2
+	STATUS_EXPIRED = 3
+
+	CLAIM_HASH_LEN     = 32
+	MAX_GID_LEN        = 255
+	MAX_CLAIMSOF_LIMIT = 100
+)
+
+// StatusString returns the readable name of a claim status
+func StatusString(status uint64) string {
+	switch status {
+	case STATUS_VALID:
+		return "valid"
+	case STATUS_REVOKED:
+		return "revoked"
+	case STATUS_EXPIRED:
+		return "expired"
+	default:
+		return "unknown"
+	}
+}
+
+func genClaimKey(contract common.Address, claimHash []byte) []byte {
+	return utils.ConcatKey(contract, []byte(CLAIM), claimHash)
+}
+
+// the subject is prefixed by its length, so that the list of one subject can
+// not overlap the list of another
+func genSubjectClaimsKey(contract common.Address, subject []byte) []byte {
+	return utils.ConcatKey(contract, []byte(SUBJECT_CLAIMS), []byte{byte(len(subject))}, subject)
+}
+
+func checkGID(id []byte) error {
+	if len(id) == 0 || len(id) > MAX_GID_LEN {
+		return fmt.Errorf("invalid GID length %d", len(id))
+	}
+	return nil
+}
+
+func checkClaimHash(claimHash []byte) error {
+	if len(claimHash) != CLAIM_HASH_LEN {
+		return fmt.Errorf("claim hash length should be %d", CLAIM_HASH_LEN)
+	}
+	return nil
+}
+
+func getClaim(native *native.NativeService, contract common.Address, claimHash []byte) (*Claim, error) {
+	item, err := utils.GetStorageItem(native, genClaimKey(contract, claimHash))
+	if err != nil {
+		return nil, errors.NewDetailErr(err, errors.ErrNoCode, "[getClaim] get claim error!")
+	}
+	if item == nil {
+		return nil, nil
+	}
+	claim := new(Claim)
+	if err := claim.Deserialize(bytes.NewBuffer(item.Value)); err != nil {
+		return nil, errors.NewDetailErr(err, errors.ErrNoCode, "[getClaim] deserialize claim error!")
+	}
+	return claim, nil
+}
+
+func putClaim(native *native.NativeService, contract common.Address, claim *Claim) error {
+	bf := new(bytes.Buffer)
+	if err := claim.Serialize(bf); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "[putClaim] serialize claim error!")
+	}
+	native.CloneCache.Add(scommon.ST_STORAGE, genClaimKey(contract, claim.ClaimHash), &cstates.StorageItem{Value: bf.Bytes()})
+	return nil
+}
+
+// queryStatus returns the status of claim at the current block time
+func queryStatus(native *native.NativeService, claim *Claim) uint64 {
+	if claim.Status == STATUS_VALID && claim.Expiry != 0 && uint64(native.Time) >= claim.Expiry {
+		return STATUS_EXPIRED
+	}
+	return claim.Status
+}
+
+func getSubjectClaims(native *native.NativeService, contract common.Address, param *ClaimsOfParam) (*ClaimList, error) {
+	index := genSubjectClaimsKey(contract, param.Subject)
+	item := param.Start
+	if len(item) == 0 {
+		head, err := utils.LinkedlistGetHead(native, index)
+		if err != nil {
+			return nil, errors.NewDetailErr(err, errors.ErrNoCode, "[getSubjectClaims] get list head error!")
+		}
+		item = head
+	}
+	limit := param.Limit
+	if limit == 0 || limit > MAX_CLAIMSOF_LIMIT {
+		limit = MAX_CLAIMSOF_LIMIT
+	}
+	list := &ClaimList{ClaimHashes: make([][]byte, 0)}
+	for len(item) > 0 {
+		if uint64(len(list.ClaimHashes)) == limit {
+			list.Next = item
+			break
+		}
+		node, err := utils.LinkedlistGetItem(native, index, item)
+		if err != nil {
+			return nil, errors.NewDetailErr(err, errors.ErrNoCode, "[getSubjectClaims] get list item error!")
+		}
+		if node == nil {
+			return nil, fmt.Errorf("[getSubjectClaims] claim %x not about subject", item)
+		}
+		list.ClaimHashes = append(list.ClaimHashes, item)
+		item = node.GetNext()
+	}
+	return list, nil
+}
+
+// verifyIssuer checks that the transaction is signed by the keyNo-th key of
+// the issuer GID and that the key is not removed
+func verifyIssuer(native *native.NativeService, issuer []byte, keyNo uint64) error {
+	bf := new(bytes.Buffer)
+	if err := serialization.WriteVarBytes(bf, issuer); err != nil {
+		return err
+	}
+	if err := utils.WriteVarUint(bf, keyNo); err != nil {
+		return err
+	}
+	args := bf.Bytes()
+	ret, err := native.NativeCall(utils.GIDContractAddress, "getKeyState", args)
+	if err != nil {
+		return err
+	}
+	if state, ok := ret.([]byte); !ok || string(state) != "in use" {
+		return fmt.Errorf("key %d of issuer is not in use", keyNo)
+	}
+	ret, err = native.NativeCall(utils.GIDContractAddress, "verifySignature", args)
+	if err != nil {
+		return err
+	}
+	if valid, ok := ret.([]byte); !ok || !bytes.Equal(valid, utils.BYTE_TRUE) {
+		return errors.NewErr("verify signature of issuer failed")
+	}
+	return nil
+}
+
+// checkRegistered checks that the GID is registered and has a key in use
+func checkRegistered(native *native.NativeService, id []byte) error {
+	bf := new(bytes.Buffer)
+	if err := serialization.WriteVarBytes(bf, id); err != nil {
+		return err
+	}
+	ret, err := native.NativeCall(utils.GIDContractAddress, "getPublicKeys", bf.Bytes())
+	if err != nil {
+		return err
+	}
+	if keys, ok := ret.([]byte); !ok || len(keys) == 0 {
+		return fmt.Errorf("GID %s is not registered", id)
+	}
+	return nil
+}
+
+func addNotification(native *native.NativeService, contract common.Address, states []interface{}) {
+	if !config.DefConfig.Common.EnableEventLog {
+		return
+	}
+	native.Notifications = append(native.Notifications,
+		&event.NotifyEventInfo{
+			ContractAddress: contract,
+			States:          states,
+		})
+}
+
+func addCommitNotification(native *native.NativeService, contract common.Address, claim *Claim) {
+	addNotification(native, contract, []interface{}{COMMIT_NAME, hex.EncodeToString(claim.ClaimHash),
+		string(claim.Issuer), string(claim.Subject), claim.Expiry})
+}
+
+func addRevokeNotification(native *native.NativeService, contract common.Address, claim *Claim) {
+	addNotification(native, contract, []interface{}{REVOKE_NAME, hex.EncodeToString(claim.ClaimHash),
+		string(claim.Issuer), string(claim.Subject)})
+}
@@ -103,6 +103,11 @@ var (
 	STORAGE_DELETE_NAME             = "System.Storage.Delete"
 	STORAGE_GETCONTEXT_NAME         = "System.Storage.GetContext"
 	STORAGE_GETREADONLYCONTEXT_NAME = "System.Storage.GetReadOnlyContext"
+	STORAGE_FIND_NAME               = "System.Storage.Find"
+
+	ITERATOR_NEXT_NAME  = "System.Iterator.Next"
+	ITERATOR_KEY_NAME   = "System.Iterator.Key"
+	ITERATOR_VALUE_NAME = "System.Iterator.Value"
 
 	STORAGECONTEXT_ASREADONLY_NAME = "System.StorageContext.AsReadOnly"
 
@@ -114,6 +119,8 @@ var (
 	RUNTIME_SERIALIZE_NAME    = "System.Runtime.Serialize"
 	RUNTIME_DESERIALIZE_NAME  = "System.Runtime.Deserialize"
 
+	RUNTIME_REGISTEREVENTSCHEMA_NAME = "ZeepinChain.Runtime.RegisterEventSchema"
+
 	NATIVE_INVOKE_NAME = "ZeepinChain.Native.Invoke"
 
 	GETSCRIPTCONTAINER_NAME     = "System.ExecutionEngine.GetScriptContainer"
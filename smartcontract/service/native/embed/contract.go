@@ -39,10 +39,12 @@ import (
 
 	"github.com/imZhuFei/zeepin/common"
 	"github.com/imZhuFei/zeepin/core/payload"
+	"github.com/imZhuFei/zeepin/core/states"
 	scommon "github.com/imZhuFei/zeepin/core/store/common"
 	"github.com/imZhuFei/zeepin/core/types"
 	vm "github.com/imZhuFei/zeepin/embed/simulator"
 	"github.com/imZhuFei/zeepin/errors"
+	"github.com/imZhuFei/zeepin/smartcontract/storage"
 )
 
 // ContractCreate create a new smart contract on blockchain, and put it to vm stack
@@ -51,6 +53,9 @@ func ContractCreate(service *EmbeddedService, engine *vm.ExecutionEngine) error
 	if err != nil {
 		return errors.NewDetailErr(err, errors.ErrNoCode, "[ContractCreate] contract parameters invalid!")
 	}
+	if err := service.ContextRef.VerifyCode(contract.Code); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "[ContractCreate] contract code invalid!")
+	}
 	contractAddress := types.AddressFromVmCode(contract.Code)
 	state, err := service.CloneCache.GetOrAdd(scommon.ST_CONTRACT, contractAddress[:], contract)
 	if err != nil {
@@ -66,6 +71,9 @@ func ContractMigrate(service *EmbeddedService, engine *vm.ExecutionEngine) error
 	if err != nil {
 		return errors.NewDetailErr(err, errors.ErrNoCode, "[ContractMigrate] contract parameters invalid!")
 	}
+	if err := service.ContextRef.VerifyCode(contract.Code); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "[ContractMigrate] contract code invalid!")
+	}
 	contractAddress := types.AddressFromVmCode(contract.Code)
 
 	if err := isContractExist(service, contractAddress); err != nil {
@@ -78,6 +86,11 @@ func ContractMigrate(service *EmbeddedService, engine *vm.ExecutionEngine) error
 	if err != nil {
 		return errors.NewDetailErr(err, errors.ErrNoCode, "[ContractMigrate] contract store migration error!")
 	}
+	if isApiHeight(service.Height) {
+		if err := migrateEventSchemas(service.CloneCache, context.ContractAddress, contractAddress); err != nil {
+			return errors.NewDetailErr(err, errors.ErrNoCode, "[ContractMigrate] event schema migration error!")
+		}
+	}
 	service.CloneCache.Delete(scommon.ST_CONTRACT, context.ContractAddress[:])
 	for _, v := range items {
 		service.CloneCache.Delete(scommon.ST_STORAGE, []byte(v.Key))
@@ -106,6 +119,9 @@ func ContractDestory(service *EmbeddedService, engine *vm.ExecutionEngine) error
 	for _, v := range stateValues {
 		service.CloneCache.Delete(scommon.ST_STORAGE, []byte(v.Key))
 	}
+	if isApiHeight(service.Height) {
+		service.CloneCache.Delete(scommon.ST_EVENT_SCHEMA, context.ContractAddress[:])
+	}
 	return nil
 }
 
@@ -228,3 +244,66 @@ func storeMigration(service *EmbeddedService, oldAddr common.Address, newAddr co
 	}
 	return stateValues, nil
 }
+
+// MigrateContractStorage move all storage items and event schemas of old wasm contract to new contract,
+// including the items written in the current transaction.
+// Embedded contracts keep migrating the committed items by storeMigration
+func MigrateContractStorage(cache *storage.CloneCache, oldAddr common.Address, newAddr common.Address) error {
+	stateValues, err := cache.Find(scommon.ST_STORAGE, oldAddr[:])
+	if err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "[Contract] Find error!")
+	}
+	for _, v := range stateValues {
+		cache.Add(scommon.ST_STORAGE, getStorageKey(newAddr, []byte(v.Key)[common.ADDR_LEN:]), v.Value)
+		cache.Delete(scommon.ST_STORAGE, []byte(v.Key))
+	}
+	return migrateEventSchemas(cache, oldAddr, newAddr)
+}
+
+// migrateEventSchemas move the event schemas of old contract to new contract
+func migrateEventSchemas(cache *storage.CloneCache, oldAddr common.Address, newAddr common.Address) error {
+	schemas, err := cache.Get(scommon.ST_EVENT_SCHEMA, oldAddr[:])
+	if err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "[Contract] Get event schemas error!")
+	}
+	if schemas != nil {
+		cache.Add(scommon.ST_EVENT_SCHEMA, newAddr[:], schemas)
+		cache.Delete(scommon.ST_EVENT_SCHEMA, oldAddr[:])
+	}
+	return nil
+}
+
+// DestroyContractStorage delete all storage items and event schemas of wasm contract
+func DestroyContractStorage(cache *storage.CloneCache, address common.Address) error {
+	stateValues, err := cache.Find(scommon.ST_STORAGE, address[:])
+	if err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "[Contract] Find error!")
+	}
+	for _, v := range stateValues {
+		cache.Delete(scommon.ST_STORAGE, []byte(v.Key))
+	}
+	cache.Delete(scommon.ST_EVENT_SCHEMA, address[:])
+	return nil
+}
+
+// RegisterEventSchema add the event schema in json to contract, or replace the schema with the same event name
+func RegisterEventSchema(cache *storage.CloneCache, address common.Address, data []byte) error {
+	schema, err := states.ParseEventSchema(data)
+	if err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "[Contract] Parse event schema error!")
+	}
+	item, err := cache.Get(scommon.ST_EVENT_SCHEMA, address[:])
+	if err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "[Contract] Get event schemas error!")
+	}
+	// copy schemas to keep the cached state unchanged
+	schemas := new(states.EventSchemaState)
+	if item != nil {
+		schemas.Schemas = append(schemas.Schemas, item.(*states.EventSchemaState).Schemas...)
+	}
+	if err := schemas.SetSchema(schema); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "[Contract] Set event schema error!")
+	}
+	cache.Add(scommon.ST_EVENT_SCHEMA, address[:], schemas)
+	return nil
+}
@@ -0,0 +1,101 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package embed
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/core/states"
+	scommon "github.com/imZhuFei/zeepin/core/store/common"
+	"github.com/imZhuFei/zeepin/core/store/leveldbstore"
+	"github.com/imZhuFei/zeepin/core/store/statestore"
+	"github.com/imZhuFei/zeepin/smartcontract/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrateContractStorage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "embed")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	db, err := leveldbstore.NewLevelDBStore(dir)
+	assert.Nil(t, err)
+	defer db.Close()
+
+	cache := storage.NewCloneCache(statestore.NewStateStoreBatch(statestore.NewMemDatabase(), db))
+	oldAddr := common.Address{1}
+	newAddr := common.Address{2}
+	cache.Add(scommon.ST_STORAGE, getStorageKey(oldAddr, []byte("k1")), &states.StorageItem{Value: []byte("v1")})
+	cache.Add(scommon.ST_STORAGE, getStorageKey(oldAddr, []byte("k2")), &states.StorageItem{Value: []byte("v2")})
+
+	assert.Nil(t, MigrateContractStorage(cache, oldAddr, newAddr))
+	items, err := cache.Find(scommon.ST_STORAGE, oldAddr[:])
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(items))
+	item, err := cache.Get(scommon.ST_STORAGE, getStorageKey(newAddr, []byte("k2")))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("v2"), item.(*states.StorageItem).Value)
+
+	assert.Nil(t, DestroyContractStorage(cache, newAddr))
+	items, err = cache.Find(scommon.ST_STORAGE, newAddr[:])
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(items))
+}
+
+func TestRegisterEventSchema(t *testing.T) {
+	dir, err := ioutil.TempDir("", "embed")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	db, err := leveldbstore.NewLevelDBStore(dir)
+	assert.Nil(t, err)
+	defer db.Close()
+
+	batch := statestore.NewStateStoreBatch(statestore.NewMemDatabase(), db)
+	cache := storage.NewCloneCache(batch)
+	oldAddr := common.Address{1}
+	newAddr := common.Address{2}
+	transfer := `{"name":"transfer","fields":[{"name":"from","type":"address"},{"name":"amount","type":"integer"}]}`
+	assert.Nil(t, RegisterEventSchema(cache, oldAddr, []byte(transfer)))
+	assert.Nil(t, RegisterEventSchema(cache, oldAddr, []byte(`{"name":"approve","fields":[]}`)))
+	assert.NotNil(t, RegisterEventSchema(cache, oldAddr, []byte(`{"name":"bad","fields":[{"name":"a","type":"float"}]}`)))
+	cache.Commit()
+
+	// replace the registered schema in a new cache, the committed state is not changed
+	cache = storage.NewCloneCache(batch)
+	assert.Nil(t, RegisterEventSchema(cache, oldAddr, []byte(`{"name":"transfer","fields":[]}`)))
+	item, err := batch.TryGet(scommon.ST_EVENT_SCHEMA, oldAddr[:])
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(item.Value.(*states.EventSchemaState).Schemas))
+	assert.Equal(t, 2, len(item.Value.(*states.EventSchemaState).GetSchema("transfer").Fields))
+
+	assert.Nil(t, MigrateContractStorage(cache, oldAddr, newAddr))
+	value, err := cache.Get(scommon.ST_EVENT_SCHEMA, oldAddr[:])
+	assert.Nil(t, err)
+	assert.Nil(t, value)
+	value, err = cache.Get(scommon.ST_EVENT_SCHEMA, newAddr[:])
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(value.(*states.EventSchemaState).GetSchema("transfer").Fields))
+
+	assert.Nil(t, DestroyContractStorage(cache, newAddr))
+	value, err = cache.Get(scommon.ST_EVENT_SCHEMA, newAddr[:])
+	assert.Nil(t, err)
+	assert.Nil(t, value)
+}
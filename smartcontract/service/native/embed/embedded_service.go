@@ -38,6 +38,7 @@ import (
 	"fmt"
 
 	scommon "github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/common/config"
 	"github.com/imZhuFei/zeepin/common/log"
 	"github.com/imZhuFei/zeepin/core/payload"
 	"github.com/imZhuFei/zeepin/core/signature"
@@ -102,6 +103,15 @@ var (
 		GETCALLINGSCRIPTHASH_NAME:            {Execute: GetCallingAddress},
 		GETENTRYSCRIPTHASH_NAME:              {Execute: GetEntryAddress},
 	}
+
+	// Register the service provided from the contract api height
+	ApiServiceMap = map[string]Service{
+		RUNTIME_REGISTEREVENTSCHEMA_NAME: {Execute: RuntimeRegisterEventSchema},
+		STORAGE_FIND_NAME:                {Execute: StorageFind},
+		ITERATOR_NEXT_NAME:               {Execute: IteratorNext},
+		ITERATOR_KEY_NAME:                {Execute: IteratorKey},
+		ITERATOR_VALUE_NAME:              {Execute: IteratorValue},
+	}
 )
 
 var (
@@ -164,6 +174,7 @@ func (this *EmbeddedService) Invoke() (interface{}, error) {
 			}
 		}
 		if this.Engine.OpCode >= vm.PUSHBYTES1 && this.Engine.OpCode <= vm.PUSHBYTES75 {
+			this.Engine.TraceStep(OPCODE_GAS)
 			if !this.ContextRef.CheckUseGas(OPCODE_GAS) {
 				return nil, ERR_GAS_INSUFFICIENT
 			}
@@ -175,6 +186,7 @@ func (this *EmbeddedService) Invoke() (interface{}, error) {
 			if err != nil {
 				return nil, err
 			}
+			this.Engine.TraceStep(price)
 			if !this.ContextRef.CheckUseGas(price) {
 				return nil, ERR_GAS_INSUFFICIENT
 			}
@@ -247,7 +259,7 @@ func (this *EmbeddedService) Invoke() (interface{}, error) {
 // SystemCall provide register service for smart contract to interaction with blockchain
 func (this *EmbeddedService) SystemCall(engine *vm.ExecutionEngine) error {
 	serviceName := engine.Context.OpReader.ReadVarString(vm.MAX_BYTEARRAY_SIZE)
-	service, ok := ServiceMap[serviceName]
+	service, ok := this.getService(serviceName)
 	if !ok {
 		return errors.NewErr(fmt.Sprintf("[SystemCall] service not support: %s", serviceName))
 	}
@@ -255,6 +267,7 @@ func (this *EmbeddedService) SystemCall(engine *vm.ExecutionEngine) error {
 	if err != nil {
 		return err
 	}
+	engine.TraceSyscall(serviceName, price)
 	if !this.ContextRef.CheckUseGas(price) {
 		return ERR_GAS_INSUFFICIENT
 	}
@@ -270,6 +283,23 @@ func (this *EmbeddedService) SystemCall(engine *vm.ExecutionEngine) error {
 	return nil
 }
 
+// getService return the service of name provided at the height of the invocation
+func (this *EmbeddedService) getService(name string) (Service, bool) {
+	if service, ok := ServiceMap[name]; ok {
+		return service, true
+	}
+	if !isApiHeight(this.Height) {
+		return Service{}, false
+	}
+	service, ok := ApiServiceMap[name]
+	return service, ok
+}
+
+// isApiHeight return whether the services added after genesis are provided at height
+func isApiHeight(height uint32) bool {
+	return height >= config.GetContractApiHeight(config.DefConfig.P2PNode.NetworkId)
+}
+
 func (this *EmbeddedService) getContract(address []byte) ([]byte, error) {
 	item, err := this.CloneCache.Store.TryGet(common.ST_CONTRACT, address)
 	if err != nil {
@@ -39,6 +39,14 @@ import (
 	"github.com/imZhuFei/zeepin/errors"
 )
 
+// FindGasCost return the gas of the items returned by storage find, every item costs as a storage get
+func FindGasCost(count int) uint64 {
+	if getCost, ok := GAS_TABLE.Load(STORAGE_GET_NAME); ok {
+		return uint64(count) * getCost.(uint64)
+	}
+	return uint64(count) * OPCODE_GAS
+}
+
 func StoreGasCost(engine *vm.ExecutionEngine) (uint64, error) {
 	key, err := vm.PeekNByteArray(1, engine)
 	if err != nil {
@@ -59,6 +67,24 @@ func GasPrice(engine *vm.ExecutionEngine, name string) (uint64, error) {
 	switch name {
 	case STORAGE_PUT_NAME:
 		return StoreGasCost(engine)
+	case RUNTIME_REGISTEREVENTSCHEMA_NAME:
+		if vm.EvaluationStackCount(engine) < 1 {
+			return 0, errors.NewErr("[GasPrice] Too few input parameters ")
+		}
+		data, err := vm.PeekNByteArray(0, engine)
+		if err != nil {
+			return 0, err
+		}
+		if putCost, ok := GAS_TABLE.Load(STORAGE_PUT_NAME); ok {
+			return uint64((len(data)-1)/1024+1) * putCost.(uint64), nil
+		}
+		return 0, errors.NewErr("[GasPrice] get STORAGE_PUT_NAME gas failed")
+	case STORAGE_FIND_NAME:
+		//the items found are charged by FindGasCost when they are returned
+		if value, ok := GAS_TABLE.Load(STORAGE_GET_NAME); ok {
+			return value.(uint64), nil
+		}
+		return OPCODE_GAS, nil
 	default:
 		if value, ok := GAS_TABLE.Load(name); ok {
 			return value.(uint64), nil
@@ -0,0 +1,103 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package embed
+
+import (
+	"github.com/imZhuFei/zeepin/common"
+	vm "github.com/imZhuFei/zeepin/embed/simulator"
+	"github.com/imZhuFei/zeepin/errors"
+	"github.com/imZhuFei/zeepin/smartcontract/storage"
+)
+
+// StorageIterator iterate the storage items of a smart contract,
+// the contract address is stripped from the keys it return
+type StorageIterator struct {
+	*storage.Iterator
+}
+
+// NewStorageIterator return a new smart contract storage iterator
+func NewStorageIterator(iterator *storage.Iterator) *StorageIterator {
+	return &StorageIterator{Iterator: iterator}
+}
+
+// Key return the current item key without contract address
+func (this *StorageIterator) Key() []byte {
+	key := this.Iterator.Key()
+	if len(key) < common.ADDR_LEN {
+		return []byte{}
+	}
+	return key[common.ADDR_LEN:]
+}
+
+// ToArray return the current item key
+func (this *StorageIterator) ToArray() []byte {
+	return this.Key()
+}
+
+// IteratorNext move iterator to next item, push whether the item exist to vm stack
+func IteratorNext(service *EmbeddedService, engine *vm.ExecutionEngine) error {
+	iterator, err := getIterator(engine)
+	if err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "[IteratorNext] get pop iterator error!")
+	}
+	vm.PushData(engine, iterator.Next())
+	return nil
+}
+
+// IteratorKey push current item key of iterator to vm stack
+func IteratorKey(service *EmbeddedService, engine *vm.ExecutionEngine) error {
+	iterator, err := getIterator(engine)
+	if err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "[IteratorKey] get pop iterator error!")
+	}
+	vm.PushData(engine, iterator.Key())
+	return nil
+}
+
+// IteratorValue push current item value of iterator to vm stack
+func IteratorValue(service *EmbeddedService, engine *vm.ExecutionEngine) error {
+	iterator, err := getIterator(engine)
+	if err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "[IteratorValue] get pop iterator error!")
+	}
+	value := iterator.Value()
+	if value == nil {
+		value = []byte{}
+	}
+	vm.PushData(engine, value)
+	return nil
+}
+
+func getIterator(engine *vm.ExecutionEngine) (*StorageIterator, error) {
+	if vm.EvaluationStackCount(engine) < 1 {
+		return nil, errors.NewErr("[Iterator] Too few input parameters ")
+	}
+	opInterface, err := vm.PopInteropInterface(engine)
+	if err != nil {
+		return nil, err
+	}
+	if opInterface == nil {
+		return nil, errors.NewErr("[Iterator] Get iterator nil")
+	}
+	iterator, ok := opInterface.(*StorageIterator)
+	if !ok {
+		return nil, errors.NewErr("[Iterator] Get iterator invalid")
+	}
+	return iterator, nil
+}
@@ -121,6 +121,22 @@ func RuntimeNotify(service *EmbeddedService, engine *vm.ExecutionEngine) error {
 	return nil
 }
 
+// RuntimeRegisterEventSchema register the event schema in json of current contract
+func RuntimeRegisterEventSchema(service *EmbeddedService, engine *vm.ExecutionEngine) error {
+	if vm.EvaluationStackCount(engine) < 1 {
+		return errors.NewErr("[RuntimeRegisterEventSchema] Too few input parameters ")
+	}
+	data, err := vm.PopByteArray(engine)
+	if err != nil {
+		return err
+	}
+	context := service.ContextRef.CurrentContext()
+	if err := RegisterEventSchema(service.CloneCache, context.ContractAddress, data); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "[RuntimeRegisterEventSchema] register event schema error!")
+	}
+	return nil
+}
+
 // RuntimeLog push smart contract execute event log to client
 func RuntimeLog(service *EmbeddedService, engine *vm.ExecutionEngine) error {
 	item, err := vm.PopByteArray(engine)
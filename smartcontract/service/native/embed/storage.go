@@ -44,6 +44,7 @@ import (
 	scommon "github.com/imZhuFei/zeepin/core/store/common"
 	vm "github.com/imZhuFei/zeepin/embed/simulator"
 	"github.com/imZhuFei/zeepin/errors"
+	"github.com/imZhuFei/zeepin/smartcontract/storage"
 )
 
 // StoragePut put smart contract storage item to cache
@@ -128,6 +129,39 @@ func StorageGet(service *EmbeddedService, engine *vm.ExecutionEngine) error {
 	return nil
 }
 
+// StorageFind push a iterator of the smart contract storage items with the given key prefix to vm stack
+func StorageFind(service *EmbeddedService, engine *vm.ExecutionEngine) error {
+	if vm.EvaluationStackCount(engine) < 2 {
+		return errors.NewErr("[Context] Too few input parameters ")
+	}
+	context, err := getContext(engine)
+	if err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "[StorageFind] get pop context error!")
+	}
+	prefix, err := vm.PopByteArray(engine)
+	if err != nil {
+		return err
+	}
+	//every item is charged when it's read, the scan stops when gas runs out
+	var items []*scommon.StateItem
+	key := getStorageKey(context.Address, prefix)
+	err = service.CloneCache.Iterate(scommon.ST_STORAGE, key, func(item *scommon.StateItem) error {
+		if !service.ContextRef.CheckUseGas(FindGasCost(1)) {
+			return ERR_GAS_INSUFFICIENT
+		}
+		items = append(items, item)
+		return nil
+	})
+	if err == ERR_GAS_INSUFFICIENT {
+		return err
+	}
+	if err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "[StorageFind] find storage error!")
+	}
+	vm.PushData(engine, NewStorageIterator(storage.NewIterator(items)))
+	return nil
+}
+
 // StorageGetContext push smart contract storage context to vm stack
 func StorageGetContext(service *EmbeddedService, engine *vm.ExecutionEngine) error {
 	vm.PushData(engine, NewStorageContext(service.ContextRef.CurrentContext().ContractAddress))
@@ -75,3 +75,23 @@ func triggerRecoveryEvent(srvc *native.NativeService, op string, id []byte, addr
 	st := []string{"Recovery", op, string(id), addr.ToHexString()}
 	newEvent(srvc, st)
 }
+
+func triggerRecoverySetEvent(srvc *native.NativeService, op string, id []byte, set *recoverySet) {
+	members := make([]string, len(set.members))
+	for i, m := range set.members {
+		if len(m) == common.ADDR_LEN {
+			addr, _ := common.AddressParseFromBytes(m)
+			members[i] = addr.ToHexString()
+		} else {
+			members[i] = string(m)
+		}
+	}
+	st := []interface{}{"RecoverySet", op, string(id), set.threshold, set.delay, members}
+	newEvent(srvc, st)
+}
+
+func triggerRecoveryRequestEvent(srvc *native.NativeService, op string, id []byte, req *recoveryRequest) {
+	st := []interface{}{"RecoveryRequest", op, string(id), recoveryOperationName(req.operation),
+		hex.EncodeToString(req.data), len(req.approvals), req.approvedAt}
+	newEvent(srvc, st)
+}
@@ -48,6 +48,11 @@ func RegisterIDContract(srvc *native.NativeService) {
 	srvc.Register("removeKey", removeKey)
 	srvc.Register("addRecovery", addRecovery)
 	srvc.Register("changeRecovery", changeRecovery)
+	srvc.Register("setRecoverySet", setRecoverySet)
+	srvc.Register("requestRecovery", requestRecovery)
+	srvc.Register("approveRecovery", approveRecovery)
+	srvc.Register("executeRecovery", executeRecovery)
+	srvc.Register("vetoRecovery", vetoRecovery)
 	srvc.Register("regIDWithAttributes", regIdWithAttributes)
 	srvc.Register("addAttributes", addAttributes)
 	srvc.Register("removeAttribute", removeAttribute)
@@ -56,5 +61,7 @@ func RegisterIDContract(srvc *native.NativeService) {
 	srvc.Register("getKeyState", GetKeyState)
 	srvc.Register("getAttributes", GetAttributes)
 	srvc.Register("getDDO", GetDDO)
+	srvc.Register("getRecoverySet", GetRecoverySet)
+	srvc.Register("getRecoveryRequest", GetRecoveryRequest)
 	return
 }
@@ -0,0 +1,691 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package gid
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/imZhuFei/zeepin/account"
+	com "github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/common/serialization"
+	"github.com/imZhuFei/zeepin/core/states"
+	"github.com/imZhuFei/zeepin/core/store/common"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/utils"
+	"github.com/ontio/ontology-crypto/keypair"
+)
+
+// A recovery set lets threshold of its members act on behalf of an ID whose
+// owner lost the keys. Members are addresses or other GIDs. An operation
+// requested by the members is kept pending until threshold members approve
+// it, then for delay seconds, during which any owner key can veto it.
+
+const (
+	MAX_RECOVERY_MEMBERS = 16
+	MAX_RECOVERY_DELAY   = 365 * 24 * 60 * 60
+)
+
+// operations of a recovery request
+const (
+	RECOVERY_ADD_KEY uint64 = 1 + iota
+	RECOVERY_REMOVE_KEY
+	RECOVERY_CHANGE
+)
+
+type recoverySet struct {
+	threshold uint64
+	delay     uint64
+	members   [][]byte
+}
+
+func (this *recoverySet) Serialize(w io.Writer) error {
+	if err := utils.WriteVarUint(w, this.threshold); err != nil {
+		return err
+	}
+	if err := utils.WriteVarUint(w, this.delay); err != nil {
+		return err
+	}
+	if err := utils.WriteVarUint(w, uint64(len(this.members))); err != nil {
+		return err
+	}
+	for _, m := range this.members {
+		if err := serialization.WriteVarBytes(w, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (this *recoverySet) Deserialize(r io.Reader) error {
+	var err error
+	if this.threshold, err = utils.ReadVarUint(r); err != nil {
+		return fmt.Errorf("threshold error, %s", err)
+	}
+	if this.delay, err = utils.ReadVarUint(r); err != nil {
+		return fmt.Errorf("delay error, %s", err)
+	}
+	num, err := utils.ReadVarUint(r)
+	if err != nil {
+		return fmt.Errorf("member number error, %s", err)
+	}
+	if num > MAX_RECOVERY_MEMBERS {
+		return fmt.Errorf("too many members")
+	}
+	this.members = make([][]byte, 0, num)
+	for i := uint64(0); i < num; i++ {
+		m, err := serialization.ReadVarBytes(r)
+		if err != nil {
+			return fmt.Errorf("member error, %s", err)
+		}
+		this.members = append(this.members, m)
+	}
+	return nil
+}
+
+func (this *recoverySet) isMember(member []byte) bool {
+	for _, m := range this.members {
+		if bytes.Equal(m, member) {
+			return true
+		}
+	}
+	return false
+}
+
+// check validates the recovery set of id, each member is either an address or
+// a GID other than id itself
+func (this *recoverySet) check(id []byte) error {
+	if len(this.members) == 0 || len(this.members) > MAX_RECOVERY_MEMBERS {
+		return fmt.Errorf("member number should be between 1 and %d", MAX_RECOVERY_MEMBERS)
+	}
+	if this.threshold == 0 || this.threshold > uint64(len(this.members)) {
+		return errors.New("invalid threshold")
+	}
+	if this.delay > MAX_RECOVERY_DELAY {
+		return fmt.Errorf("delay should not be greater than %d", MAX_RECOVERY_DELAY)
+	}
+	for i, m := range this.members {
+		if len(m) != com.ADDR_LEN {
+			if !account.VerifyID(string(m)) {
+				return fmt.Errorf("invalid member %x", m)
+			}
+			if bytes.Equal(m, id) {
+				return errors.New("ID can not be its own recovery member")
+			}
+		}
+		for _, n := range this.members[:i] {
+			if bytes.Equal(m, n) {
+				return fmt.Errorf("duplicated member %x", m)
+			}
+		}
+	}
+	return nil
+}
+
+type recoveryRequest struct {
+	operation  uint64
+	data       []byte
+	approvals  [][]byte
+	createdAt  uint64
+	approvedAt uint64
+}
+
+func (this *recoveryRequest) Serialize(w io.Writer) error {
+	if err := utils.WriteVarUint(w, this.operation); err != nil {
+		return err
+	}
+	if err := serialization.WriteVarBytes(w, this.data); err != nil {
+		return err
+	}
+	if err := utils.WriteVarUint(w, uint64(len(this.approvals))); err != nil {
+		return err
+	}
+	for _, m := range this.approvals {
+		if err := serialization.WriteVarBytes(w, m); err != nil {
+			return err
+		}
+	}
+	if err := utils.WriteVarUint(w, this.createdAt); err != nil {
+		return err
+	}
+	return utils.WriteVarUint(w, this.approvedAt)
+}
+
+func (this *recoveryRequest) Deserialize(r io.Reader) error {
+	var err error
+	if this.operation, err = utils.ReadVarUint(r); err != nil {
+		return err
+	}
+	if this.data, err = serialization.ReadVarBytes(r); err != nil {
+		return err
+	}
+	num, err := utils.ReadVarUint(r)
+	if err != nil {
+		return err
+	}
+	if num > MAX_RECOVERY_MEMBERS {
+		return fmt.Errorf("too many approvals")
+	}
+	this.approvals = make([][]byte, 0, num)
+	for i := uint64(0); i < num; i++ {
+		m, err := serialization.ReadVarBytes(r)
+		if err != nil {
+			return err
+		}
+		this.approvals = append(this.approvals, m)
+	}
+	if this.createdAt, err = utils.ReadVarUint(r); err != nil {
+		return err
+	}
+	this.approvedAt, err = utils.ReadVarUint(r)
+	return err
+}
+
+func (this *recoveryRequest) approved(member []byte) bool {
+	for _, m := range this.approvals {
+		if bytes.Equal(m, member) {
+			return true
+		}
+	}
+	return false
+}
+
+func recoveryOperationName(op uint64) string {
+	switch op {
+	case RECOVERY_ADD_KEY:
+		return "addKey"
+	case RECOVERY_REMOVE_KEY:
+		return "removeKey"
+	case RECOVERY_CHANGE:
+		return "changeRecovery"
+	default:
+		return "unknown"
+	}
+}
+
+func fieldKey(encID []byte, field byte) []byte {
+	key := make([]byte, 0, len(encID)+1)
+	return append(append(key, encID...), field)
+}
+
+func getRecoverySet(srvc *native.NativeService, encID []byte) (*recoverySet, error) {
+	item, err := utils.GetStorageItem(srvc, fieldKey(encID, FIELD_RECOVERY_SET))
+	if err != nil {
+		return nil, fmt.Errorf("get recovery set error, %s", err)
+	} else if item == nil {
+		return nil, nil
+	}
+	set := new(recoverySet)
+	if err := set.Deserialize(bytes.NewBuffer(item.Value)); err != nil {
+		return nil, fmt.Errorf("deserialize recovery set error, %s", err)
+	}
+	return set, nil
+}
+
+func putRecoverySet(srvc *native.NativeService, encID []byte, set *recoverySet) error {
+	var buf bytes.Buffer
+	if err := set.Serialize(&buf); err != nil {
+		return err
+	}
+	srvc.CloneCache.Add(common.ST_STORAGE, fieldKey(encID, FIELD_RECOVERY_SET), &states.StorageItem{Value: buf.Bytes()})
+	return nil
+}
+
+func getRecoveryRequest(srvc *native.NativeService, encID []byte) (*recoveryRequest, error) {
+	item, err := utils.GetStorageItem(srvc, fieldKey(encID, FIELD_RECOVERY_REQUEST))
+	if err != nil {
+		return nil, fmt.Errorf("get recovery request error, %s", err)
+	} else if item == nil {
+		return nil, nil
+	}
+	req := new(recoveryRequest)
+	if err := req.Deserialize(bytes.NewBuffer(item.Value)); err != nil {
+		return nil, fmt.Errorf("deserialize recovery request error, %s", err)
+	}
+	return req, nil
+}
+
+func putRecoveryRequest(srvc *native.NativeService, encID []byte, req *recoveryRequest) error {
+	var buf bytes.Buffer
+	if err := req.Serialize(&buf); err != nil {
+		return err
+	}
+	srvc.CloneCache.Add(common.ST_STORAGE, fieldKey(encID, FIELD_RECOVERY_REQUEST), &states.StorageItem{Value: buf.Bytes()})
+	return nil
+}
+
+func deleteRecoveryRequest(srvc *native.NativeService, encID []byte) {
+	srvc.CloneCache.Delete(common.ST_STORAGE, fieldKey(encID, FIELD_RECOVERY_REQUEST))
+}
+
+// checkMemberWitness checks the signature of a recovery member, an address
+// member signs with the address and a GID member with its keyNo-th key
+func checkMemberWitness(srvc *native.NativeService, member []byte, keyNo uint64) error {
+	if len(member) == com.ADDR_LEN {
+		return checkWitness(srvc, member)
+	}
+	encID, err := encodeID(member)
+	if err != nil {
+		return err
+	}
+	if !checkIDExistence(srvc, encID) {
+		return errors.New("member ID not registered")
+	}
+	pk, err := getPk(srvc, encID, uint32(keyNo))
+	if err != nil {
+		return err
+	} else if pk.revoked {
+		return errors.New("member key revoked")
+	}
+	return checkWitness(srvc, pk.key)
+}
+
+// checkOwnerWitness checks the signature of an unrevoked key of the ID
+func checkOwnerWitness(srvc *native.NativeService, encID, pub []byte) error {
+	index, err := findPk(srvc, encID, pub)
+	if err != nil {
+		return err
+	} else if index == 0 {
+		return errors.New("operator is not the owner")
+	}
+	pk, err := getPk(srvc, encID, index)
+	if err != nil {
+		return err
+	} else if pk.revoked {
+		return errors.New("operator's key revoked")
+	}
+	return checkWitness(srvc, pub)
+}
+
+func checkRecoveryData(id []byte, op uint64, data []byte) error {
+	switch op {
+	case RECOVERY_ADD_KEY, RECOVERY_REMOVE_KEY:
+		if _, err := keypair.DeserializePublicKey(data); err != nil {
+			return fmt.Errorf("invalid public key, %s", err)
+		}
+	case RECOVERY_CHANGE:
+		set := new(recoverySet)
+		if err := set.Deserialize(bytes.NewBuffer(data)); err != nil {
+			return fmt.Errorf("invalid recovery set, %s", err)
+		}
+		if err := set.check(id); err != nil {
+			return fmt.Errorf("invalid recovery set, %s", err)
+		}
+	default:
+		return fmt.Errorf("unknown operation %d", op)
+	}
+	return nil
+}
+
+func executeRecoveryRequest(srvc *native.NativeService, id, encID []byte, req *recoveryRequest) error {
+	switch req.operation {
+	case RECOVERY_ADD_KEY:
+		if index, _ := findPk(srvc, encID, req.data); index != 0 {
+			return errors.New("key already exists")
+		}
+		keyID, err := insertPk(srvc, encID, req.data)
+		if err != nil {
+			return err
+		}
+		triggerPublicEvent(srvc, "add", id, req.data, keyID)
+	case RECOVERY_REMOVE_KEY:
+		keyID, err := revokePk(srvc, encID, req.data)
+		if err != nil {
+			return err
+		}
+		triggerPublicEvent(srvc, "remove", id, req.data, keyID)
+	case RECOVERY_CHANGE:
+		set := new(recoverySet)
+		if err := set.Deserialize(bytes.NewBuffer(req.data)); err != nil {
+			return err
+		}
+		if err := putRecoverySet(srvc, encID, set); err != nil {
+			return err
+		}
+		triggerRecoverySetEvent(srvc, "change", id, set)
+	default:
+		return fmt.Errorf("unknown operation %d", req.operation)
+	}
+	deleteRecoveryRequest(srvc, encID)
+	triggerRecoveryRequestEvent(srvc, "execute", id, req)
+	return nil
+}
+
+// approveRecoveryRequest records the approval of member and executes the
+// request at once if it is approved and no delay is required
+func approveRecoveryRequest(srvc *native.NativeService, op string, id, encID []byte, set *recoverySet,
+	req *recoveryRequest, member []byte) error {
+	req.approvals = append(req.approvals, member)
+	triggerRecoveryRequestEvent(srvc, op, id, req)
+	if req.approvedAt == 0 && uint64(len(req.approvals)) >= set.threshold {
+		req.approvedAt = uint64(srvc.Time)
+		if set.delay == 0 {
+			return executeRecoveryRequest(srvc, id, encID, req)
+		}
+	}
+	return putRecoveryRequest(srvc, encID, req)
+}
+
+func setRecoverySet(srvc *native.NativeService) ([]byte, error) {
+	args := bytes.NewBuffer(srvc.Input)
+	// arg0: ID
+	arg0, err := serialization.ReadVarBytes(args)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("set recovery failed: argument 0 error, %s", err)
+	}
+	// arg1: recovery set
+	arg1 := new(recoverySet)
+	if err := arg1.Deserialize(args); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("set recovery failed: argument 1 error, %s", err)
+	}
+	// arg2: operator's public key, or the address of the single recovery
+	arg2, err := serialization.ReadVarBytes(args)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("set recovery failed: argument 2 error, %s", err)
+	}
+
+	key, err := encodeID(arg0)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("set recovery failed: %s", err)
+	}
+	if !checkIDExistence(srvc, key) {
+		return utils.BYTE_FALSE, errors.New("set recovery failed: ID not registered")
+	}
+	if err := arg1.check(arg0); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("set recovery failed: %s", err)
+	}
+	set, err := getRecoverySet(srvc, key)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("set recovery failed: %s", err)
+	} else if set != nil {
+		return utils.BYTE_FALSE, errors.New("set recovery failed: already set, request changeRecovery instead")
+	}
+	// the single recovery set by addRecovery is replaced by the set, and only
+	// that recovery can do it
+	rec, err := getRecovery(srvc, key)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("set recovery failed: %s", err)
+	}
+	if len(rec) > 0 {
+		if !bytes.Equal(rec, arg2) {
+			return utils.BYTE_FALSE, errors.New("set recovery failed: operator is not the recovery")
+		}
+		err = checkWitness(srvc, arg2)
+	} else {
+		err = checkOwnerWitness(srvc, key, arg2)
+	}
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("set recovery failed: %s", err)
+	}
+
+	if err := putRecoverySet(srvc, key, arg1); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("set recovery failed: %s", err)
+	}
+	srvc.CloneCache.Delete(common.ST_STORAGE, fieldKey(key, FIELD_RECOVERY))
+	triggerRecoverySetEvent(srvc, "set", arg0, arg1)
+	return utils.BYTE_TRUE, nil
+}
+
+func requestRecovery(srvc *native.NativeService) ([]byte, error) {
+	args := bytes.NewBuffer(srvc.Input)
+	// arg0: ID
+	arg0, err := serialization.ReadVarBytes(args)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("request recovery failed: argument 0 error, %s", err)
+	}
+	// arg1: operation
+	arg1, err := utils.ReadVarUint(args)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("request recovery failed: argument 1 error, %s", err)
+	}
+	// arg2: operation data, the public key to add or remove, or the new recovery set
+	arg2, err := serialization.ReadVarBytes(args)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("request recovery failed: argument 2 error, %s", err)
+	}
+	// arg3: member
+	arg3, err := serialization.ReadVarBytes(args)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("request recovery failed: argument 3 error, %s", err)
+	}
+	// arg4: key index of the member if it is a GID
+	arg4, err := utils.ReadVarUint(args)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("request recovery failed: argument 4 error, %s", err)
+	}
+
+	key, err := encodeID(arg0)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("request recovery failed: %s", err)
+	}
+	if !checkIDExistence(srvc, key) {
+		return utils.BYTE_FALSE, errors.New("request recovery failed: ID not registered")
+	}
+	set, err := getRecoverySet(srvc, key)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("request recovery failed: %s", err)
+	} else if set == nil {
+		return utils.BYTE_FALSE, errors.New("request recovery failed: recovery set not set")
+	}
+	if !set.isMember(arg3) {
+		return utils.BYTE_FALSE, errors.New("request recovery failed: operator is not a recovery member")
+	}
+	if err := checkMemberWitness(srvc, arg3, arg4); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("request recovery failed: %s", err)
+	}
+	req, err := getRecoveryRequest(srvc, key)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("request recovery failed: %s", err)
+	} else if req != nil {
+		return utils.BYTE_FALSE, errors.New("request recovery failed: another request is pending")
+	}
+	if err := checkRecoveryData(arg0, arg1, arg2); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("request recovery failed: %s", err)
+	}
+
+	req = &recoveryRequest{
+		operation: arg1,
+		data:      arg2,
+		createdAt: uint64(srvc.Time),
+	}
+	if err := approveRecoveryRequest(srvc, "request", arg0, key, set, req, arg3); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("request recovery failed: %s", err)
+	}
+	return utils.BYTE_TRUE, nil
+}
+
+func approveRecovery(srvc *native.NativeService) ([]byte, error) {
+	args := bytes.NewBuffer(srvc.Input)
+	// arg0: ID
+	arg0, err := serialization.ReadVarBytes(args)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("approve recovery failed: argument 0 error, %s", err)
+	}
+	// arg1: member
+	arg1, err := serialization.ReadVarBytes(args)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("approve recovery failed: argument 1 error, %s", err)
+	}
+	// arg2: key index of the member if it is a GID
+	arg2, err := utils.ReadVarUint(args)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("approve recovery failed: argument 2 error, %s", err)
+	}
+
+	key, err := encodeID(arg0)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("approve recovery failed: %s", err)
+	}
+	set, err := getRecoverySet(srvc, key)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("approve recovery failed: %s", err)
+	} else if set == nil {
+		return utils.BYTE_FALSE, errors.New("approve recovery failed: recovery set not set")
+	}
+	if !set.isMember(arg1) {
+		return utils.BYTE_FALSE, errors.New("approve recovery failed: operator is not a recovery member")
+	}
+	if err := checkMemberWitness(srvc, arg1, arg2); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("approve recovery failed: %s", err)
+	}
+	req, err := getRecoveryRequest(srvc, key)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("approve recovery failed: %s", err)
+	} else if req == nil {
+		return utils.BYTE_FALSE, errors.New("approve recovery failed: no pending request")
+	}
+	if req.approved(arg1) {
+		return utils.BYTE_FALSE, errors.New("approve recovery failed: already approved")
+	}
+
+	if err := approveRecoveryRequest(srvc, "approve", arg0, key, set, req, arg1); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("approve recovery failed: %s", err)
+	}
+	return utils.BYTE_TRUE, nil
+}
+
+func executeRecovery(srvc *native.NativeService) ([]byte, error) {
+	args := bytes.NewBuffer(srvc.Input)
+	// arg0: ID
+	arg0, err := serialization.ReadVarBytes(args)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("execute recovery failed: argument 0 error, %s", err)
+	}
+	// arg1: member
+	arg1, err := serialization.ReadVarBytes(args)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("execute recovery failed: argument 1 error, %s", err)
+	}
+	// arg2: key index of the member if it is a GID
+	arg2, err := utils.ReadVarUint(args)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("execute recovery failed: argument 2 error, %s", err)
+	}
+
+	key, err := encodeID(arg0)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("execute recovery failed: %s", err)
+	}
+	set, err := getRecoverySet(srvc, key)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("execute recovery failed: %s", err)
+	} else if set == nil {
+		return utils.BYTE_FALSE, errors.New("execute recovery failed: recovery set not set")
+	}
+	if !set.isMember(arg1) {
+		return utils.BYTE_FALSE, errors.New("execute recovery failed: operator is not a recovery member")
+	}
+	if err := checkMemberWitness(srvc, arg1, arg2); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("execute recovery failed: %s", err)
+	}
+	req, err := getRecoveryRequest(srvc, key)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("execute recovery failed: %s", err)
+	} else if req == nil {
+		return utils.BYTE_FALSE, errors.New("execute recovery failed: no pending request")
+	}
+	if req.approvedAt == 0 {
+		return utils.BYTE_FALSE, errors.New("execute recovery failed: not enough approvals")
+	}
+	if uint64(srvc.Time) < req.approvedAt+set.delay {
+		return utils.BYTE_FALSE, fmt.Errorf("execute recovery failed: delayed until %d", req.approvedAt+set.delay)
+	}
+
+	if err := executeRecoveryRequest(srvc, arg0, key, req); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("execute recovery failed: %s", err)
+	}
+	return utils.BYTE_TRUE, nil
+}
+
+// vetoRecovery cancels the pending request, it can be called by any owner key
+// before the request is executed
+func vetoRecovery(srvc *native.NativeService) ([]byte, error) {
+	args := bytes.NewBuffer(srvc.Input)
+	// arg0: ID
+	arg0, err := serialization.ReadVarBytes(args)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("veto recovery failed: argument 0 error, %s", err)
+	}
+	// arg1: operator's public key
+	arg1, err := serialization.ReadVarBytes(args)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("veto recovery failed: argument 1 error, %s", err)
+	}
+
+	key, err := encodeID(arg0)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("veto recovery failed: %s", err)
+	}
+	if err := checkOwnerWitness(srvc, key, arg1); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("veto recovery failed: %s", err)
+	}
+	req, err := getRecoveryRequest(srvc, key)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("veto recovery failed: %s", err)
+	} else if req == nil {
+		return utils.BYTE_FALSE, errors.New("veto recovery failed: no pending request")
+	}
+
+	deleteRecoveryRequest(srvc, key)
+	triggerRecoveryRequestEvent(srvc, "veto", arg0, req)
+	return utils.BYTE_TRUE, nil
+}
+
+// GetRecoverySet returns the recovery set of the ID, or nil if it is not set
+func GetRecoverySet(srvc *native.NativeService) ([]byte, error) {
+	args := bytes.NewBuffer(srvc.Input)
+	did, err := serialization.ReadVarBytes(args)
+	if err != nil {
+		return nil, fmt.Errorf("get recovery set error: invalid argument, %s", err)
+	}
+	key, err := encodeID(did)
+	if err != nil {
+		return nil, fmt.Errorf("get recovery set error: %s", err)
+	}
+	item, err := utils.GetStorageItem(srvc, fieldKey(key, FIELD_RECOVERY_SET))
+	if err != nil {
+		return nil, fmt.Errorf("get recovery set error: %s", err)
+	} else if item == nil {
+		return nil, nil
+	}
+	return item.Value, nil
+}
+
+// GetRecoveryRequest returns the pending recovery request of the ID, or nil
+// if there is none
+func GetRecoveryRequest(srvc *native.NativeService) ([]byte, error) {
+	args := bytes.NewBuffer(srvc.Input)
+	did, err := serialization.ReadVarBytes(args)
+	if err != nil {
+		return nil, fmt.Errorf("get recovery request error: invalid argument, %s", err)
+	}
+	key, err := encodeID(did)
+	if err != nil {
+		return nil, fmt.Errorf("get recovery request error: %s", err)
+	}
+	item, err := utils.GetStorageItem(srvc, fieldKey(key, FIELD_RECOVERY_REQUEST))
+	if err != nil {
+		return nil, fmt.Errorf("get recovery request error: %s", err)
+	} else if item == nil {
+		return nil, nil
+	}
+	return item.Value, nil
+}
@@ -0,0 +1,218 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package gid
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/imZhuFei/zeepin/account"
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/common/log"
+	"github.com/imZhuFei/zeepin/common/serialization"
+	"github.com/imZhuFei/zeepin/core/types"
+	"github.com/imZhuFei/zeepin/smartcontract/context"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/testutil"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/utils"
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/stretchr/testify/assert"
+)
+
+func newKey(t *testing.T) ([]byte, common.Address) {
+	_, pub, err := keypair.GenerateKeyPair(keypair.PK_ECDSA, keypair.P256)
+	assert.Nil(t, err)
+	return keypair.SerializePublicKey(pub), types.AddressFromPubKey(pub)
+}
+
+func newID(t *testing.T) []byte {
+	id, err := account.GenerateID()
+	assert.Nil(t, err)
+	return []byte(id)
+}
+
+// call invokes the GID contract, args are []byte written as var bytes, uint64
+// written as var uint or a *recoverySet
+func call(srvc *native.NativeService, method string, args ...interface{}) ([]byte, error) {
+	bf := new(bytes.Buffer)
+	for _, arg := range args {
+		switch v := arg.(type) {
+		case []byte:
+			serialization.WriteVarBytes(bf, v)
+		case uint64:
+			utils.WriteVarUint(bf, v)
+		case *recoverySet:
+			v.Serialize(bf)
+		}
+	}
+	ret, err := srvc.NativeCall(utils.GIDContractAddress, method, bf.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return ret.([]byte), nil
+}
+
+func getRequest(t *testing.T, srvc *native.NativeService, id []byte) *recoveryRequest {
+	ret, err := call(srvc, "getRecoveryRequest", id)
+	assert.Nil(t, err)
+	if ret == nil {
+		return nil
+	}
+	req := new(recoveryRequest)
+	assert.Nil(t, req.Deserialize(bytes.NewBuffer(ret)))
+	return req
+}
+
+func TestRecovery(t *testing.T) {
+	log.InitLog(log.InfoLog, log.Stdout)
+	Init()
+	ctx := testutil.NewContext()
+	ctx.PushContext(&context.Context{})
+	srvc, closeStore := testutil.NewNativeService(t, ctx)
+	defer closeStore()
+	srvc.Time = 1000
+	id, friendID := newID(t), newID(t)
+	ownerKey, ownerAddr := newKey(t)
+	friendKey, friendAddr := newKey(t)
+	_, memberA := newKey(t)
+	_, memberC := newKey(t)
+	ctx.Sign(ownerAddr, friendAddr)
+	_, err := call(srvc, "regIDWithPublicKey", id, ownerKey)
+	assert.Nil(t, err)
+	_, err = call(srvc, "regIDWithPublicKey", friendID, friendKey)
+	assert.Nil(t, err)
+
+	set := &recoverySet{threshold: 2, delay: 100, members: [][]byte{memberA[:], friendID, memberC[:]}}
+	_, err = call(srvc, "setRecoverySet", id, &recoverySet{threshold: 4, members: set.members}, ownerKey)
+	assert.NotNil(t, err)
+	_, err = call(srvc, "setRecoverySet", id, &recoverySet{threshold: 1, members: [][]byte{id}}, ownerKey)
+	assert.NotNil(t, err)
+	_, err = call(srvc, "setRecoverySet", id, set, ownerKey)
+	assert.Nil(t, err)
+	_, err = call(srvc, "setRecoverySet", id, set, ownerKey)
+	assert.NotNil(t, err)
+	_, err = call(srvc, "addRecovery", id, memberA[:], ownerKey)
+	assert.NotNil(t, err)
+
+	// the owner lost the key, two members add a new key after the delay
+	newOwnerKey, newOwnerAddr := newKey(t)
+	ctx.Sign(memberA)
+	_, err = call(srvc, "requestRecovery", id, RECOVERY_ADD_KEY, newOwnerKey, memberC[:], uint64(0))
+	assert.NotNil(t, err)
+	_, err = call(srvc, "requestRecovery", id, RECOVERY_ADD_KEY, newOwnerKey, memberA[:], uint64(0))
+	assert.Nil(t, err)
+	_, err = call(srvc, "requestRecovery", id, RECOVERY_ADD_KEY, newOwnerKey, memberA[:], uint64(0))
+	assert.NotNil(t, err)
+	_, err = call(srvc, "approveRecovery", id, memberA[:], uint64(0))
+	assert.NotNil(t, err)
+	_, err = call(srvc, "executeRecovery", id, memberA[:], uint64(0))
+	assert.NotNil(t, err)
+	ctx.Sign(friendAddr)
+	_, err = call(srvc, "approveRecovery", id, friendID, uint64(1))
+	assert.Nil(t, err)
+	req := getRequest(t, srvc, id)
+	assert.Equal(t, 2, len(req.approvals))
+	assert.Equal(t, uint64(1000), req.approvedAt)
+
+	srvc.Time = 1050
+	_, err = call(srvc, "executeRecovery", id, friendID, uint64(1))
+	assert.NotNil(t, err)
+	// the owner still holds the key and vetoes the request
+	ctx.Sign(ownerAddr)
+	_, err = call(srvc, "vetoRecovery", id, ownerKey)
+	assert.Nil(t, err)
+	assert.Nil(t, getRequest(t, srvc, id))
+
+	ctx.Sign(memberA, memberC)
+	_, err = call(srvc, "requestRecovery", id, RECOVERY_ADD_KEY, newOwnerKey, memberA[:], uint64(0))
+	assert.Nil(t, err)
+	_, err = call(srvc, "approveRecovery", id, memberC[:], uint64(0))
+	assert.Nil(t, err)
+	srvc.Time = 1150
+	_, err = call(srvc, "executeRecovery", id, memberC[:], uint64(0))
+	assert.Nil(t, err)
+	assert.Nil(t, getRequest(t, srvc, id))
+	ret, err := call(srvc, "getKeyState", id, uint64(2))
+	assert.Nil(t, err)
+	assert.Equal(t, "in use", string(ret))
+
+	// the new key removes the old one and the members change the set
+	ctx.Sign(newOwnerAddr)
+	_, err = call(srvc, "removeKey", id, ownerKey, newOwnerKey)
+	assert.Nil(t, err)
+	ctx.Sign(ownerAddr)
+	_, err = call(srvc, "vetoRecovery", id, ownerKey)
+	assert.NotNil(t, err)
+	newSet := &recoverySet{threshold: 1, members: [][]byte{memberC[:]}}
+	bf := new(bytes.Buffer)
+	assert.Nil(t, newSet.Serialize(bf))
+	ctx.Sign(memberA, memberC)
+	_, err = call(srvc, "requestRecovery", id, RECOVERY_CHANGE, bf.Bytes(), memberA[:], uint64(0))
+	assert.Nil(t, err)
+	_, err = call(srvc, "approveRecovery", id, memberC[:], uint64(0))
+	assert.Nil(t, err)
+	srvc.Time = 1250
+	_, err = call(srvc, "executeRecovery", id, memberA[:], uint64(0))
+	assert.Nil(t, err)
+	ret, err = call(srvc, "getRecoverySet", id)
+	assert.Nil(t, err)
+	assert.Equal(t, bf.Bytes(), ret)
+}
+
+func TestRecoveryUpgrade(t *testing.T) {
+	log.InitLog(log.InfoLog, log.Stdout)
+	Init()
+	ctx := testutil.NewContext()
+	ctx.PushContext(&context.Context{})
+	srvc, closeStore := testutil.NewNativeService(t, ctx)
+	defer closeStore()
+
+	id := newID(t)
+	ownerKey, ownerAddr := newKey(t)
+	_, recovery := newKey(t)
+	ctx.Witnesses[ownerAddr] = true
+	_, err := call(srvc, "regIDWithPublicKey", id, ownerKey)
+	assert.Nil(t, err)
+	_, err = call(srvc, "addRecovery", id, recovery[:], ownerKey)
+	assert.Nil(t, err)
+
+	// only the single recovery can replace itself by a recovery set
+	set := &recoverySet{threshold: 1, members: [][]byte{recovery[:]}}
+	_, err = call(srvc, "setRecoverySet", id, set, ownerKey)
+	assert.NotNil(t, err)
+	ctx.Witnesses[recovery] = true
+	_, err = call(srvc, "setRecoverySet", id, set, recovery[:])
+	assert.Nil(t, err)
+
+	key, _ := encodeID(id)
+	rec, err := getRecovery(srvc, key)
+	assert.Nil(t, err)
+	assert.Nil(t, rec)
+	_, err = call(srvc, "addKey", id, ownerKey, recovery[:])
+	assert.NotNil(t, err)
+
+	// without delay the request is executed once approved
+	newOwnerKey, _ := newKey(t)
+	_, err = call(srvc, "requestRecovery", id, RECOVERY_ADD_KEY, newOwnerKey, recovery[:], uint64(0))
+	assert.Nil(t, err)
+	assert.Nil(t, getRequest(t, srvc, id))
+	ret, err := call(srvc, "getKeyState", id, uint64(2))
+	assert.Nil(t, err)
+	assert.Equal(t, "in use", string(ret))
+}
@@ -63,6 +63,8 @@ const (
 	FIELD_PK byte = 1 + iota
 	FIELD_ATTR
 	FIELD_RECOVERY
+	FIELD_RECOVERY_SET
+	FIELD_RECOVERY_REQUEST
 )
 
 func encodeID(id []byte) ([]byte, error) {
@@ -39,6 +39,7 @@ import (
 	"fmt"
 
 	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/common/config"
 	"github.com/imZhuFei/zeepin/common/serialization"
 	scommon "github.com/imZhuFei/zeepin/core/store/common"
 	"github.com/imZhuFei/zeepin/errors"
@@ -56,6 +57,7 @@ const (
 	ACCEPT_ADMIN_NAME                        = "acceptAdmin"
 	TRANSFER_ADMIN_NAME                      = "transferAdmin"
 	SET_OPERATOR                             = "setOperator"
+	SET_GOVERNOR                             = "setGovernor"
 	SET_GLOBAL_PARAM_NAME                    = "setGlobalParam"
 	GET_GLOBAL_PARAM_NAME                    = "getGlobalParam"
 	CREATE_SNAPSHOT_NAME                     = "createSnapshot"
@@ -70,6 +72,9 @@ func RegisterParamContract(native *native.NativeService) {
 	native.Register(ACCEPT_ADMIN_NAME, AcceptAdmin)
 	native.Register(TRANSFER_ADMIN_NAME, TransferAdmin)
 	native.Register(SET_OPERATOR, SetOperator)
+	if native.Height >= config.GetGovernanceHeight(config.DefConfig.P2PNode.NetworkId) {
+		native.Register(SET_GOVERNOR, SetGovernor)
+	}
 	native.Register(SET_GLOBAL_PARAM_NAME, SetGlobalParam)
 	native.Register(GET_GLOBAL_PARAM_NAME, GetGlobalParam)
 	native.Register(CREATE_SNAPSHOT_NAME, CreateSnapshot)
@@ -168,14 +173,46 @@ func SetOperator(native *native.NativeService) ([]byte, error) {
 	return utils.BYTE_TRUE, nil
 }
 
-func SetGlobalParam(native *native.NativeService) ([]byte, error) {
+// SetGovernor set the governor of params, once set, params can only be changed by the governor
+// instead of the operator. Set empty address to give params back to the operator.
+// The governor is set by the admin, after that it can only be changed or cleared by the governor itself.
+func SetGovernor(native *native.NativeService) ([]byte, error) {
 	contract := native.ContextRef.CurrentContext().ContractAddress
-	operator, err := GetStorageRole(native, GenerateOperatorKey(contract))
-	if err != nil || operator == common.ADDRESS_EMPTY {
-		return utils.BYTE_FALSE, fmt.Errorf("set param, operator doesn't exist, caused by %v", err)
+	governor, err := GetStorageRole(native, GenerateGovernorKey(contract))
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("set governor, get governor error: %v", err)
+	}
+	if governor != common.ADDRESS_EMPTY {
+		if !native.ContextRef.CheckWitness(governor) {
+			return utils.BYTE_FALSE, errors.NewErr("set governor, params are governed, check governor witness failed!")
+		}
+	} else {
+		admin, err := GetStorageRole(native, generateAdminKey(contract, false))
+		if err != nil || admin == common.ADDRESS_EMPTY {
+			return utils.BYTE_FALSE, fmt.Errorf("set governor, admin doesn't exist, caused by %v", err)
+		}
+		if !native.ContextRef.CheckWitness(admin) {
+			return utils.BYTE_FALSE, errors.NewErr("set governor, authentication failed!")
+		}
+	}
+	destinationGovernor, err := utils.ReadAddress(bytes.NewBuffer(native.Input))
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewErr("set governor, deserialize governor failed!")
 	}
-	if !native.ContextRef.CheckWitness(operator) {
-		return utils.BYTE_FALSE, errors.NewErr("set param, authentication failed!")
+	if destinationGovernor == common.ADDRESS_EMPTY {
+		native.CloneCache.Delete(scommon.ST_STORAGE, GenerateGovernorKey(contract))
+	} else {
+		native.CloneCache.Add(scommon.ST_STORAGE, GenerateGovernorKey(contract), getRoleStorageItem(destinationGovernor))
+	}
+
+	NotifyRoleChange(native, contract, SET_GOVERNOR, destinationGovernor)
+	return utils.BYTE_TRUE, nil
+}
+
+func SetGlobalParam(native *native.NativeService) ([]byte, error) {
+	contract := native.ContextRef.CurrentContext().ContractAddress
+	if err := CheckParamWitness(native); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "set param, authentication failed!")
 	}
 	params := Params{}
 	if err := params.Deserialize(bytes.NewBuffer(native.Input)); err != nil {
@@ -238,12 +275,8 @@ func GetGlobalParam(native *native.NativeService) ([]byte, error) {
 
 func CreateSnapshot(native *native.NativeService) ([]byte, error) {
 	contract := native.ContextRef.CurrentContext().ContractAddress
-	operator, err := GetStorageRole(native, GenerateOperatorKey(contract))
-	if err != nil || operator == common.ADDRESS_EMPTY {
-		return utils.BYTE_FALSE, fmt.Errorf("create snapshot, operator doesn't exist, caused by %v", err)
-	}
-	if !native.ContextRef.CheckWitness(operator) {
-		return utils.BYTE_FALSE, errors.NewErr("create snapshot, authentication failed!")
+	if err := CheckParamWitness(native); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "create snapshot, authentication failed!")
 	}
 	// read prepare param
 	prepareParam, err := getStorageParam(native, generateParamKey(contract, PREPARE_VALUE))
@@ -36,10 +36,12 @@ package global_params
 
 import (
 	"bytes"
+	"fmt"
 
 	"github.com/imZhuFei/zeepin/common"
 	"github.com/imZhuFei/zeepin/common/config"
 	cstates "github.com/imZhuFei/zeepin/core/states"
+	"github.com/imZhuFei/zeepin/errors"
 	"github.com/imZhuFei/zeepin/smartcontract/event"
 	"github.com/imZhuFei/zeepin/smartcontract/service/native"
 	"github.com/imZhuFei/zeepin/smartcontract/service/native/utils"
@@ -50,6 +52,7 @@ const (
 	TRANSFER = "transfer"
 	ADMIN    = "admin"
 	OPERATOR = "operator"
+	GOVERNOR = "governor"
 )
 
 func getRoleStorageItem(role common.Address) *cstates.StorageItem {
@@ -82,6 +85,10 @@ func GenerateOperatorKey(contract common.Address) []byte {
 	return append(contract[:], OPERATOR...)
 }
 
+func GenerateGovernorKey(contract common.Address) []byte {
+	return append(contract[:], GOVERNOR...)
+}
+
 func getStorageParam(native *native.NativeService, key []byte) (Params, error) {
 	item, err := utils.GetStorageItem(native, key)
 	params := Params{}
@@ -104,6 +111,30 @@ func GetStorageRole(native *native.NativeService, key []byte) (common.Address, e
 	return role, err
 }
 
+// CheckParamWitness check the witness of who can change params, it is the governor if set, otherwise the operator.
+// Once params are governed by proposals, the governor is the governance contract, so params can only be changed
+// by executed proposals.
+func CheckParamWitness(native *native.NativeService) error {
+	governor, err := GetStorageRole(native, GenerateGovernorKey(utils.ParamContractAddress))
+	if err != nil {
+		return fmt.Errorf("get governor error: %v", err)
+	}
+	if governor != common.ADDRESS_EMPTY {
+		if !native.ContextRef.CheckWitness(governor) {
+			return errors.NewErr("params are governed, check governor witness failed!")
+		}
+		return nil
+	}
+	operator, err := GetStorageRole(native, GenerateOperatorKey(utils.ParamContractAddress))
+	if err != nil || operator == common.ADDRESS_EMPTY {
+		return fmt.Errorf("operator doesn't exist, caused by %v", err)
+	}
+	if !native.ContextRef.CheckWitness(operator) {
+		return errors.NewErr("check operator witness failed!")
+	}
+	return nil
+}
+
 func NotifyRoleChange(native *native.NativeService, contract common.Address, functionName string,
 	newAddr common.Address) {
 	if !config.DefConfig.Common.EnableEventLog {
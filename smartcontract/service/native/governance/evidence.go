@@ -0,0 +1,285 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package governance
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/common/serialization"
+	vbftconfig "github.com/imZhuFei/zeepin/consensus/vbft/config"
+	"github.com/imZhuFei/zeepin/core/signature"
+	cstates "github.com/imZhuFei/zeepin/core/states"
+	scommon "github.com/imZhuFei/zeepin/core/store/common"
+	"github.com/imZhuFei/zeepin/core/types"
+	"github.com/imZhuFei/zeepin/errors"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/global_params"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/utils"
+	"github.com/ontio/ontology-crypto/keypair"
+)
+
+const (
+	// percent of init pos slashed by evidence if slash rate is not updated by admin
+	DEFAULT_SLASH_RATE = 100
+	// evidence of blocks older than this is rejected, so old headers can not punish a peer registered again later
+	EVIDENCE_EXPIRATION = 10000
+)
+
+// Verify the evidence of vbft equivocation of the peer with index peerIndex, return the block height of the evidence.
+// The two headers should be at the same height and signed by the peer, and they are conflicting if
+//  1. they are built on different previous blocks, no honest node signs blocks of two forks, or
+//  2. both are proposed by the peer with different consensus payloads, the block and empty block of
+//     one proposal share the consensus payload, so a proposer never signs two payloads at one height.
+func VerifyEvidence(evidence *EvidenceParam, peerIndex uint32) (uint32, error) {
+	pubkeyBytes, err := hex.DecodeString(evidence.PeerPubkey)
+	if err != nil {
+		return 0, errors.NewDetailErr(err, errors.ErrNoCode, "hex.DecodeString, peerPubkey format error!")
+	}
+	pubkey, err := keypair.DeserializePublicKey(pubkeyBytes)
+	if err != nil {
+		return 0, errors.NewDetailErr(err, errors.ErrNoCode, "keypair.DeserializePublicKey, peerPubkey format error!")
+	}
+	header1 := new(types.Header)
+	if err := header1.DeserializeUnsigned(bytes.NewBuffer(evidence.Header1)); err != nil {
+		return 0, errors.NewDetailErr(err, errors.ErrNoCode, "deserialize, deserialize header1 error!")
+	}
+	header2 := new(types.Header)
+	if err := header2.DeserializeUnsigned(bytes.NewBuffer(evidence.Header2)); err != nil {
+		return 0, errors.NewDetailErr(err, errors.ErrNoCode, "deserialize, deserialize header2 error!")
+	}
+	if header1.Height != header2.Height {
+		return 0, errors.NewErr("verifyEvidence, headers are not at the same height!")
+	}
+	hash1, hash2 := header1.Hash(), header2.Hash()
+	if hash1 == hash2 {
+		return 0, errors.NewErr("verifyEvidence, headers are the same!")
+	}
+	if err := signature.Verify(pubkey, hash1[:], evidence.Sig1); err != nil {
+		return 0, errors.NewDetailErr(err, errors.ErrNoCode, "verifyEvidence, verify sig1 error!")
+	}
+	if err := signature.Verify(pubkey, hash2[:], evidence.Sig2); err != nil {
+		return 0, errors.NewDetailErr(err, errors.ErrNoCode, "verifyEvidence, verify sig2 error!")
+	}
+	if header1.PrevBlockHash != header2.PrevBlockHash {
+		return header1.Height, nil
+	}
+	if !bytes.Equal(header1.ConsensusPayload, header2.ConsensusPayload) {
+		info1, info2 := new(vbftconfig.VbftBlockInfo), new(vbftconfig.VbftBlockInfo)
+		if err := json.Unmarshal(header1.ConsensusPayload, info1); err != nil {
+			return 0, errors.NewDetailErr(err, errors.ErrNoCode, "json.Unmarshal, unmarshal consensus payload1 error!")
+		}
+		if err := json.Unmarshal(header2.ConsensusPayload, info2); err != nil {
+			return 0, errors.NewDetailErr(err, errors.ErrNoCode, "json.Unmarshal, unmarshal consensus payload2 error!")
+		}
+		if info1.Proposer == peerIndex && info2.Proposer == peerIndex {
+			return header1.Height, nil
+		}
+	}
+	return 0, errors.NewErr("verifyEvidence, headers are not conflicting!")
+}
+
+// Submit the evidence of vbft equivocation, can be called by anyone.
+// The peer is put into black list, and slash rate percent of its init pos and penalty percent of vote pos are
+// punished when it quits, same as black node.
+func SubmitEvidence(native *native.NativeService) ([]byte, error) {
+	params := new(EvidenceParam)
+	if err := params.Deserialize(bytes.NewBuffer(native.Input)); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "deserialize, contract params deserialize error!")
+	}
+	contract := native.ContextRef.CurrentContext().ContractAddress
+
+	//get current view
+	view, err := GetView(native, contract)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "getView, get view error!")
+	}
+	//get peerPoolMap
+	peerPoolMap, err := GetPeerPoolMap(native, contract, view)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "getPeerPoolMap, get peerPoolMap error!")
+	}
+	peerPoolItem, ok := peerPoolMap.PeerPoolMap[params.PeerPubkey]
+	if !ok {
+		return utils.BYTE_FALSE, errors.NewErr("submitEvidence, peerPubkey is not in peerPoolMap!")
+	}
+	if peerPoolItem.Status == BlackStatus {
+		return utils.BYTE_FALSE, errors.NewErr("submitEvidence, peer is already in black list!")
+	}
+	height, err := VerifyEvidence(params, peerPoolItem.Index)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "verifyEvidence, verify evidence error!")
+	}
+	if height > native.Height+1 {
+		return utils.BYTE_FALSE, errors.NewErr("submitEvidence, evidence of future block!")
+	}
+	if isEvidenceExpired(height, native.Height) {
+		return utils.BYTE_FALSE, errors.NewErr("submitEvidence, evidence is expired!")
+	}
+	submitted, err := hasEvidence(native, contract, params.PeerPubkey, height)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "hasEvidence, get evidence error!")
+	}
+	if submitted {
+		return utils.BYTE_FALSE, errors.NewErr("submitEvidence, evidence of the peer at the height is already submitted!")
+	}
+
+	slashRate, err := getSlashRate(native, contract)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "getSlashRate, get slashRate error!")
+	}
+	err = putPeerSlashRate(native, contract, params.PeerPubkey, slashRate)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "putPeerSlashRate, put peer slashRate error!")
+	}
+	err = putEvidence(native, contract, params, height)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "putEvidence, put evidence error!")
+	}
+
+	commit, err := blackPeer(native, contract, view, peerPoolMap, params.PeerPubkey)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "blackPeer, black peer error!")
+	}
+	//commitDpos
+	if commit {
+		// get config
+		config, err := getConfig(native, contract)
+		if err != nil {
+			return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "getConfig, get config error!")
+		}
+		err = executeCommitDpos(native, contract, config)
+		if err != nil {
+			return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "executeCommitDpos, executeCommitDpos error!")
+		}
+	}
+	return utils.BYTE_TRUE, nil
+}
+
+// Update the percent of init pos slashed by evidence, used by admin.
+func UpdateSlashRate(native *native.NativeService) ([]byte, error) {
+	err := global_params.CheckParamWitness(native)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "updateSlashRate, checkWitness error!")
+	}
+
+	params := new(SlashRateParam)
+	if err := params.Deserialize(bytes.NewBuffer(native.Input)); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "deserialize, deserialize slashRateParam error!")
+	}
+	if params.SlashRate > 100 {
+		return utils.BYTE_FALSE, errors.NewErr("updateSlashRate. SlashRate must <= 100!")
+	}
+	contract := native.ContextRef.CurrentContext().ContractAddress
+
+	native.CloneCache.Add(scommon.ST_STORAGE, utils.ConcatKey(contract, []byte(SLASH_RATE)),
+		utils.GenUInt32StorageItem(params.SlashRate))
+	return utils.BYTE_TRUE, nil
+}
+
+func getSlashRate(native *native.NativeService, contract common.Address) (uint32, error) {
+	item, err := utils.GetStorageItem(native, utils.ConcatKey(contract, []byte(SLASH_RATE)))
+	if err != nil {
+		return 0, errors.NewDetailErr(err, errors.ErrNoCode, "getSlashRate, get slashRate error!")
+	}
+	if item == nil {
+		return DEFAULT_SLASH_RATE, nil
+	}
+	return serialization.ReadUint32(bytes.NewBuffer(item.Value))
+}
+
+// get the slash rate of a black peer, black nodes by admin are fully slashed
+func getPeerSlashRate(native *native.NativeService, contract common.Address, peerPubkey string) (uint32, error) {
+	peerPubkeyPrefix, err := hex.DecodeString(peerPubkey)
+	if err != nil {
+		return 0, errors.NewDetailErr(err, errors.ErrNoCode, "hex.DecodeString, peerPubkey format error!")
+	}
+	item, err := utils.GetStorageItem(native, utils.ConcatKey(contract, []byte(PEER_SLASH_RATE), peerPubkeyPrefix))
+	if err != nil {
+		return 0, errors.NewDetailErr(err, errors.ErrNoCode, "getPeerSlashRate, get slashRate error!")
+	}
+	if item == nil {
+		return 100, nil
+	}
+	return serialization.ReadUint32(bytes.NewBuffer(item.Value))
+}
+
+func putPeerSlashRate(native *native.NativeService, contract common.Address, peerPubkey string, slashRate uint32) error {
+	peerPubkeyPrefix, err := hex.DecodeString(peerPubkey)
+	if err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "hex.DecodeString, peerPubkey format error!")
+	}
+	native.CloneCache.Add(scommon.ST_STORAGE, utils.ConcatKey(contract, []byte(PEER_SLASH_RATE), peerPubkeyPrefix),
+		utils.GenUInt32StorageItem(slashRate))
+	return nil
+}
+
+func deletePeerSlashRate(native *native.NativeService, contract common.Address, peerPubkey string) {
+	peerPubkeyPrefix, err := hex.DecodeString(peerPubkey)
+	if err != nil {
+		return
+	}
+	native.CloneCache.Delete(scommon.ST_STORAGE, utils.ConcatKey(contract, []byte(PEER_SLASH_RATE), peerPubkeyPrefix))
+}
+
+// whether the evidence of block at height is expired at current height
+func isEvidenceExpired(height, current uint32) bool {
+	return current > EVIDENCE_EXPIRATION && height < current-EVIDENCE_EXPIRATION
+}
+
+func getEvidenceKey(contract common.Address, peerPubkey string, height uint32) ([]byte, error) {
+	peerPubkeyPrefix, err := hex.DecodeString(peerPubkey)
+	if err != nil {
+		return nil, errors.NewDetailErr(err, errors.ErrNoCode, "hex.DecodeString, peerPubkey format error!")
+	}
+	heightBytes, err := GetUint32Bytes(height)
+	if err != nil {
+		return nil, errors.NewDetailErr(err, errors.ErrNoCode, "GetUint32Bytes, get heightBytes error!")
+	}
+	return utils.ConcatKey(contract, []byte(EVIDENCE), peerPubkeyPrefix, heightBytes), nil
+}
+
+// whether the evidence of a peer at a height is submitted
+func hasEvidence(native *native.NativeService, contract common.Address, peerPubkey string, height uint32) (bool, error) {
+	key, err := getEvidenceKey(contract, peerPubkey, height)
+	if err != nil {
+		return false, err
+	}
+	item, err := native.CloneCache.Get(scommon.ST_STORAGE, key)
+	if err != nil {
+		return false, errors.NewDetailErr(err, errors.ErrNoCode, "hasEvidence, get evidence error!")
+	}
+	return item != nil, nil
+}
+
+// keep the evidence of a peer at a height
+func putEvidence(native *native.NativeService, contract common.Address, evidence *EvidenceParam, height uint32) error {
+	key, err := getEvidenceKey(contract, evidence.PeerPubkey, height)
+	if err != nil {
+		return err
+	}
+	bf := new(bytes.Buffer)
+	if err := evidence.Serialize(bf); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "serialize, serialize evidence error!")
+	}
+	native.CloneCache.Add(scommon.ST_STORAGE, key, &cstates.StorageItem{Value: bf.Bytes()})
+	return nil
+}
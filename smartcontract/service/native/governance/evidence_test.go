@@ -0,0 +1,154 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package governance
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/imZhuFei/zeepin/account"
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/common/config"
+	vbftconfig "github.com/imZhuFei/zeepin/consensus/vbft/config"
+	"github.com/imZhuFei/zeepin/core/signature"
+	"github.com/imZhuFei/zeepin/core/types"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native"
+	"github.com/ontio/ontology-crypto/keypair"
+)
+
+func newTestHeader(t *testing.T, prev common.Uint256, proposer uint32, vrf byte) *types.Header {
+	payload, err := json.Marshal(&vbftconfig.VbftBlockInfo{Proposer: proposer, VrfValue: []byte{vrf}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &types.Header{
+		PrevBlockHash:    prev,
+		Height:           10,
+		ConsensusPayload: payload,
+	}
+}
+
+func signTestHeader(t *testing.T, acct *account.Account, header *types.Header) ([]byte, []byte) {
+	buf := new(bytes.Buffer)
+	if err := header.SerializeUnsigned(buf); err != nil {
+		t.Fatal(err)
+	}
+	hash := header.Hash()
+	sig, err := signature.Sign(acct, hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes(), sig
+}
+
+func newTestEvidence(t *testing.T, acct *account.Account, header1, header2 *types.Header) *EvidenceParam {
+	h1, sig1 := signTestHeader(t, acct, header1)
+	h2, sig2 := signTestHeader(t, acct, header2)
+	return &EvidenceParam{
+		PeerPubkey: hex.EncodeToString(keypair.SerializePublicKey(acct.PublicKey)),
+		Header1:    h1,
+		Sig1:       sig1,
+		Header2:    h2,
+		Sig2:       sig2,
+	}
+}
+
+func TestVerifyEvidence(t *testing.T) {
+	acct := account.NewAccount("")
+	other := account.NewAccount("")
+	prev1 := common.Uint256{1}
+	prev2 := common.Uint256{2}
+
+	// blocks of two forks
+	evidence := newTestEvidence(t, acct, newTestHeader(t, prev1, 3, 0), newTestHeader(t, prev2, 3, 0))
+	height, err := VerifyEvidence(evidence, 5)
+	if err != nil {
+		t.Fatalf("verify fork evidence: %s", err)
+	}
+	if height != 10 {
+		t.Fatalf("height %d, expect 10", height)
+	}
+
+	// two proposals of the peer
+	evidence = newTestEvidence(t, acct, newTestHeader(t, prev1, 5, 0), newTestHeader(t, prev1, 5, 1))
+	if _, err := VerifyEvidence(evidence, 5); err != nil {
+		t.Fatalf("verify proposal evidence: %s", err)
+	}
+	// the proposals are not made by the peer
+	if _, err := VerifyEvidence(evidence, 3); err == nil {
+		t.Fatal("endorsements of two proposals should not be evidence")
+	}
+
+	// same header
+	header := newTestHeader(t, prev1, 5, 0)
+	evidence = newTestEvidence(t, acct, header, header)
+	if _, err := VerifyEvidence(evidence, 5); err == nil {
+		t.Fatal("same header should not be evidence")
+	}
+
+	// different height
+	header = newTestHeader(t, prev2, 5, 0)
+	header.Height = 11
+	evidence = newTestEvidence(t, acct, newTestHeader(t, prev1, 5, 0), header)
+	if _, err := VerifyEvidence(evidence, 5); err == nil {
+		t.Fatal("headers of different height should not be evidence")
+	}
+
+	// signed by others
+	evidence = newTestEvidence(t, acct, newTestHeader(t, prev1, 3, 0), newTestHeader(t, prev2, 3, 0))
+	_, evidence.Sig2 = signTestHeader(t, other, newTestHeader(t, prev2, 3, 0))
+	if _, err := VerifyEvidence(evidence, 5); err == nil {
+		t.Fatal("header signed by others should not be evidence")
+	}
+}
+
+func TestIsEvidenceExpired(t *testing.T) {
+	if isEvidenceExpired(0, EVIDENCE_EXPIRATION) {
+		t.Fatal("evidence within expiration should not be expired")
+	}
+	if !isEvidenceExpired(0, EVIDENCE_EXPIRATION+1) {
+		t.Fatal("evidence older than expiration should be expired")
+	}
+	if isEvidenceExpired(100, 100+EVIDENCE_EXPIRATION) {
+		t.Fatal("evidence within expiration should not be expired")
+	}
+}
+
+func TestRegisterEvidenceMethods(t *testing.T) {
+	networkId := config.DefConfig.P2PNode.NetworkId
+	defer func() { config.DefConfig.P2PNode.NetworkId = networkId }()
+
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_MAIN_NET
+	srvc := &native.NativeService{ServiceMap: make(map[string]native.Handler), Height: 10}
+	RegisterGovernanceContract(srvc)
+	for _, method := range []string{SUBMIT_EVIDENCE, UPDATE_SLASH_RATE} {
+		if _, ok := srvc.ServiceMap[method]; ok {
+			t.Fatalf("%s should not be registered below the governance height", method)
+		}
+	}
+
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_SOLO_NET
+	RegisterGovernanceContract(srvc)
+	for _, method := range []string{SUBMIT_EVIDENCE, UPDATE_SLASH_RATE} {
+		if _, ok := srvc.ServiceMap[method]; !ok {
+			t.Fatalf("%s should be registered from the governance height", method)
+		}
+	}
+}
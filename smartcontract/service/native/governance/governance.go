@@ -92,6 +92,16 @@ const (
 	GET_PEERPOOL_INFO                = "getPeerPoolInfo"
 	GET_VOTE_INFO                    = "getVoteInfo"
 	CHECK_VOTE_INFO                  = "checkVoteInfo"
+	SUBMIT_EVIDENCE                  = "submitEvidence"
+	UPDATE_SLASH_RATE                = "updateSlashRate"
+	SET_PROPOSAL_CONFIG              = "setProposalConfig"
+	CREATE_PARAM_PROPOSAL            = "createParamProposal"
+	APPROVE_PARAM_PROPOSAL           = "approveParamProposal"
+	EXECUTE_PARAM_PROPOSAL           = "executeParamProposal"
+	GET_PARAM_PROPOSAL               = "getParamProposal"
+	GET_PROPOSAL_CONFIG              = "getProposalConfig"
+	GET_STAKE_INFO                   = "getStakeInfo"
+	GET_SPLIT_PAYOUTS                = "getSplitPayouts"
 	//key prefix
 	GLOBAL_PARAM    = "globalParam"
 	VBFT_CONFIG     = "vbftConfig"
@@ -104,9 +114,17 @@ const (
 	TOTAL_STAKE     = "totalStake"
 	PENALTY_STAKE   = "penaltyStake"
 	SPLIT_CURVE     = "splitCurve"
+	EVIDENCE        = "evidence"
+	SLASH_RATE      = "slashRate"
+	PEER_SLASH_RATE = "peerSlashRate"
+	PROPOSAL_CONFIG = "proposalConfig"
+	PARAM_PROPOSAL  = "paramProposal"
+	PROPOSAL_ID     = "proposalID"
+	SPLIT_PAYOUT    = "splitPayout"
 
 	//global
-	PRECISE = 1000000
+	PRECISE                = 1000000
+	MAX_SPLIT_PAYOUT_VIEWS = 100
 )
 
 // candidate fee must >= 1 Gala
@@ -154,6 +172,23 @@ func RegisterGovernanceContract(native *native.NativeService) {
 	native.Register(GET_PEERPOOL_INFO, GetPeerpoolInfo)
 	native.Register(GET_VOTE_INFO, GetVoteInfo)
 	native.Register(CHECK_VOTE_INFO, CheckVoteInfo)
+	if isGovernanceHeight(native.Height) {
+		native.Register(SUBMIT_EVIDENCE, SubmitEvidence)
+		native.Register(UPDATE_SLASH_RATE, UpdateSlashRate)
+		native.Register(SET_PROPOSAL_CONFIG, SetProposalConfig)
+		native.Register(CREATE_PARAM_PROPOSAL, CreateParamProposal)
+		native.Register(APPROVE_PARAM_PROPOSAL, ApproveParamProposal)
+		native.Register(EXECUTE_PARAM_PROPOSAL, ExecuteParamProposal)
+		native.Register(GET_PARAM_PROPOSAL, GetParamProposal)
+		native.Register(GET_PROPOSAL_CONFIG, GetProposalConfig)
+	}
+	native.Register(GET_STAKE_INFO, GetStakeInfo)
+	native.Register(GET_SPLIT_PAYOUTS, GetSplitPayouts)
+}
+
+//Return whether the governance methods added after genesis are provided at height
+func isGovernanceHeight(height uint32) bool {
+	return height >= config.GetGovernanceHeight(config.DefConfig.P2PNode.NetworkId)
 }
 
 //Init governance contract, include vbft config, global param and Gid admin.
@@ -658,43 +693,11 @@ func BlackNode(native *native.NativeService) ([]byte, error) {
 	}
 	commit := false
 	for _, peerPubkey := range params.PeerPubkeyList {
-		peerPubkeyPrefix, err := hex.DecodeString(peerPubkey)
+		consensus, err := blackPeer(native, contract, view, peerPoolMap, peerPubkey)
 		if err != nil {
-			return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "hex.DecodeString, peerPubkey format error!")
-		}
-		peerPoolItem, ok := peerPoolMap.PeerPoolMap[peerPubkey]
-		if !ok {
-			return utils.BYTE_FALSE, errors.NewErr("blackNode, peerPubkey is not in peerPoolMap!")
-		}
-
-		blackListItem := &BlackListItem{
-			PeerPubkey: peerPoolItem.PeerPubkey,
-			Address:    peerPoolItem.Address,
-			InitPos:    peerPoolItem.InitPos,
-		}
-		bf := new(bytes.Buffer)
-		if err := blackListItem.Serialize(bf); err != nil {
-			return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "serialize, serialize blackListItem error!")
-		}
-		//put peer into black list
-		native.CloneCache.Add(scommon.ST_STORAGE, utils.ConcatKey(contract, []byte(BLACK_LIST), peerPubkeyPrefix), &cstates.StorageItem{Value: bf.Bytes()})
-		//change peerPool status
-		if peerPoolItem.Status == ConsensusStatus {
-			peerPoolItem.Status = BlackStatus
-			peerPoolMap.PeerPoolMap[peerPubkey] = peerPoolItem
-			err = putPeerPoolMap(native, contract, view, peerPoolMap)
-			if err != nil {
-				return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "putPeerPoolMap, put peerPoolMap error!")
-			}
-			commit = true
-		} else {
-			peerPoolItem.Status = BlackStatus
-			peerPoolMap.PeerPoolMap[peerPubkey] = peerPoolItem
-			err = putPeerPoolMap(native, contract, view, peerPoolMap)
-			if err != nil {
-				return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "putPeerPoolMap, put peerPoolMap error!")
-			}
+			return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "blackPeer, black peer error!")
 		}
+		commit = commit || consensus
 	}
 	//commitDpos
 	if commit {
@@ -1133,15 +1136,7 @@ func CommitDpos(native *native.NativeService) ([]byte, error) {
 
 //Update VBFT config
 func UpdateConfig(native *native.NativeService) ([]byte, error) {
-	// get admin from database
-	adminAddress, err := global_params.GetStorageRole(native,
-		global_params.GenerateOperatorKey(utils.ParamContractAddress))
-	if err != nil {
-		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "getAdmin, get admin error!")
-	}
-
-	//check witness
-	err = utils.ValidateOwner(native, adminAddress)
+	err := global_params.CheckParamWitness(native)
 	if err != nil {
 		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "updateConfig, checkWitness error!")
 	}
@@ -1213,15 +1208,7 @@ func UpdateConfig(native *native.NativeService) ([]byte, error) {
 
 //Update global params of this governance contract
 func UpdateGlobalParam(native *native.NativeService) ([]byte, error) {
-	// get admin from database
-	adminAddress, err := global_params.GetStorageRole(native,
-		global_params.GenerateOperatorKey(utils.ParamContractAddress))
-	if err != nil {
-		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "getAdmin, get admin error!")
-	}
-
-	//check witness
-	err = utils.ValidateOwner(native, adminAddress)
+	err := global_params.CheckParamWitness(native)
 	if err != nil {
 		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "updateGlobalParam, checkWitness error!")
 	}
@@ -1267,15 +1254,7 @@ func UpdateGlobalParam(native *native.NativeService) ([]byte, error) {
 
 //Update split curve
 func UpdateSplitCurve(native *native.NativeService) ([]byte, error) {
-	// get admin from database
-	adminAddress, err := global_params.GetStorageRole(native,
-		global_params.GenerateOperatorKey(utils.ParamContractAddress))
-	if err != nil {
-		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "getAdmin, get admin error!")
-	}
-
-	//check witness
-	err = utils.ValidateOwner(native, adminAddress)
+	err := global_params.CheckParamWitness(native)
 	if err != nil {
 		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "updateSplitCurve, checkWitness error!")
 	}
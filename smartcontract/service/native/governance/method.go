@@ -307,6 +307,39 @@ func normalQuit(native *native.NativeService, contract common.Address, peerPoolI
 	return nil
 }
 
+// put a peer into black list and set its status to BlackStatus, return true if it is a consensus peer
+func blackPeer(native *native.NativeService, contract common.Address, view uint32, peerPoolMap *PeerPoolMap, peerPubkey string) (bool, error) {
+	peerPubkeyPrefix, err := hex.DecodeString(peerPubkey)
+	if err != nil {
+		return false, errors.NewDetailErr(err, errors.ErrNoCode, "hex.DecodeString, peerPubkey format error!")
+	}
+	peerPoolItem, ok := peerPoolMap.PeerPoolMap[peerPubkey]
+	if !ok {
+		return false, errors.NewErr("blackNode, peerPubkey is not in peerPoolMap!")
+	}
+
+	blackListItem := &BlackListItem{
+		PeerPubkey: peerPoolItem.PeerPubkey,
+		Address:    peerPoolItem.Address,
+		InitPos:    peerPoolItem.InitPos,
+	}
+	bf := new(bytes.Buffer)
+	if err := blackListItem.Serialize(bf); err != nil {
+		return false, errors.NewDetailErr(err, errors.ErrNoCode, "serialize, serialize blackListItem error!")
+	}
+	//put peer into black list
+	native.CloneCache.Add(scommon.ST_STORAGE, utils.ConcatKey(contract, []byte(BLACK_LIST), peerPubkeyPrefix), &cstates.StorageItem{Value: bf.Bytes()})
+	//change peerPool status
+	consensus := peerPoolItem.Status == ConsensusStatus
+	peerPoolItem.Status = BlackStatus
+	peerPoolMap.PeerPoolMap[peerPubkey] = peerPoolItem
+	err = putPeerPoolMap(native, contract, view, peerPoolMap)
+	if err != nil {
+		return false, errors.NewDetailErr(err, errors.ErrNoCode, "putPeerPoolMap, put peerPoolMap error!")
+	}
+	return consensus, nil
+}
+
 func blackQuit(native *native.NativeService, contract common.Address, peerPoolItem *PeerPoolItem) error {
 	// zpt transfer to trigger unboundGala
 	err := appCallTransferZpt(native, utils.GovernanceContractAddress, utils.GovernanceContractAddress, peerPoolItem.InitPos)
@@ -314,13 +347,24 @@ func blackQuit(native *native.NativeService, contract common.Address, peerPoolIt
 		return errors.NewDetailErr(err, errors.ErrNoCode, "appCallTransferZpt, zpt transfer error!")
 	}
 
+	//peers slashed by evidence only lose part of init pos, the rest is unfrozen to the owner
+	initPos := peerPoolItem.InitPos
+	if isGovernanceHeight(native.Height) {
+		slashRate, err := getPeerSlashRate(native, contract, peerPoolItem.PeerPubkey)
+		if err != nil {
+			return errors.NewDetailErr(err, errors.ErrNoCode, "getPeerSlashRate, get peer slashRate error!")
+		}
+		if slashed := (uint64(slashRate)*peerPoolItem.InitPos + 99) / 100; slashed < initPos {
+			initPos = slashed
+		}
+	}
+
 	//update total stake
-	err = withdrawTotalStake(native, contract, peerPoolItem.Address, peerPoolItem.InitPos)
+	err = withdrawTotalStake(native, contract, peerPoolItem.Address, initPos)
 	if err != nil {
 		return errors.NewDetailErr(err, errors.ErrNoCode, "withdrawTotalStake, withdrawTotalStake error!")
 	}
 
-	initPos := peerPoolItem.InitPos
 	var votePos uint64
 
 	//get globalParam
@@ -369,6 +413,21 @@ func blackQuit(native *native.NativeService, contract common.Address, peerPoolIt
 		votePos = votePos + penalty
 	}
 
+	if refund := peerPoolItem.InitPos - initPos; refund > 0 {
+		voteInfo, err := getVoteInfo(native, contract, peerPoolItem.PeerPubkey, peerPoolItem.Address)
+		if err != nil {
+			return errors.NewDetailErr(err, errors.ErrNoCode, "getVoteInfo, get voteInfo error!")
+		}
+		voteInfo.WithdrawUnfreezePos = voteInfo.WithdrawUnfreezePos + refund
+		err = putVoteInfo(native, contract, voteInfo)
+		if err != nil {
+			return errors.NewDetailErr(err, errors.ErrNoCode, "putVoteInfo, put voteInfo error!")
+		}
+	}
+	if isGovernanceHeight(native.Height) {
+		deletePeerSlashRate(native, contract, peerPoolItem.PeerPubkey)
+	}
+
 	//add penalty stake
 	err = depositPenaltyStake(native, contract, peerPoolItem.PeerPubkey, initPos, votePos)
 	if err != nil {
@@ -812,6 +871,11 @@ func executeSplit(native *native.NativeService, contract common.Address, peerPoo
 	if err != nil {
 		return errors.NewDetailErr(err, errors.ErrNoCode, "executeSplit, getGalaBalance error!")
 	}
+	//payouts are recorded under the current view
+	view, err := GetView(native, contract)
+	if err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "executeSplit, get view error!")
+	}
 	//get globalParam
 	globalParam, err := getGlobalParam(native, contract)
 	if err != nil {
@@ -889,6 +953,10 @@ func executeSplit(native *native.NativeService, contract common.Address, peerPoo
 		if err != nil {
 			return errors.NewDetailErr(err, errors.ErrNoCode, "executeSplit, gala transfer error!")
 		}
+		err = addSplitPayout(native, contract, view, address, uint64(nodeAmount))
+		if err != nil {
+			return errors.NewDetailErr(err, errors.ErrNoCode, "executeSplit, add split payout error!")
+		}
 	}
 
 	//fee split of candidate peer
@@ -918,6 +986,10 @@ func executeSplit(native *native.NativeService, contract common.Address, peerPoo
 			if err != nil {
 				return errors.NewDetailErr(err, errors.ErrNoCode, "executeSplit, gala transfer error!")
 			}
+			err = addSplitPayout(native, contract, view, address, uint64(nodeAmount))
+			if err != nil {
+				return errors.NewDetailErr(err, errors.ErrNoCode, "executeSplit, add split payout error!")
+			}
 		}
 	} else {
 		for i := int(config.K); i < len(peersCandidate); i++ {
@@ -937,6 +1009,10 @@ func executeSplit(native *native.NativeService, contract common.Address, peerPoo
 			if err != nil {
 				return errors.NewDetailErr(err, errors.ErrNoCode, "executeSplit, gala transfer error!")
 			}
+			err = addSplitPayout(native, contract, view, address, uint64(nodeAmount))
+			if err != nil {
+				return errors.NewDetailErr(err, errors.ErrNoCode, "executeSplit, add split payout error!")
+			}
 		}
 	}
 
@@ -763,3 +763,326 @@ func (this *WithdrawGalaParam) Deserialize(r io.Reader) error {
 	this.Address = address
 	return nil
 }
+
+type EvidenceParam struct {
+	PeerPubkey string
+	Header1    []byte
+	Sig1       []byte
+	Header2    []byte
+	Sig2       []byte
+}
+
+func (this *EvidenceParam) Serialize(w io.Writer) error {
+	if err := serialization.WriteString(w, this.PeerPubkey); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "serialization.WriteString, serialize peerPubkey error!")
+	}
+	if err := serialization.WriteVarBytes(w, this.Header1); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "serialization.WriteVarBytes, serialize header1 error!")
+	}
+	if err := serialization.WriteVarBytes(w, this.Sig1); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "serialization.WriteVarBytes, serialize sig1 error!")
+	}
+	if err := serialization.WriteVarBytes(w, this.Header2); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "serialization.WriteVarBytes, serialize header2 error!")
+	}
+	if err := serialization.WriteVarBytes(w, this.Sig2); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "serialization.WriteVarBytes, serialize sig2 error!")
+	}
+	return nil
+}
+
+func (this *EvidenceParam) Deserialize(r io.Reader) error {
+	peerPubkey, err := serialization.ReadString(r)
+	if err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "serialization.ReadString, deserialize peerPubkey error!")
+	}
+	header1, err := serialization.ReadVarBytes(r)
+	if err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "serialization.ReadVarBytes, deserialize header1 error!")
+	}
+	sig1, err := serialization.ReadVarBytes(r)
+	if err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "serialization.ReadVarBytes, deserialize sig1 error!")
+	}
+	header2, err := serialization.ReadVarBytes(r)
+	if err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "serialization.ReadVarBytes, deserialize header2 error!")
+	}
+	sig2, err := serialization.ReadVarBytes(r)
+	if err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "serialization.ReadVarBytes, deserialize sig2 error!")
+	}
+	this.PeerPubkey = peerPubkey
+	this.Header1 = header1
+	this.Sig1 = sig1
+	this.Header2 = header2
+	this.Sig2 = sig2
+	return nil
+}
+
+type SlashRateParam struct {
+	SlashRate uint32
+}
+
+func (this *SlashRateParam) Serialize(w io.Writer) error {
+	if err := utils.WriteVarUint(w, uint64(this.SlashRate)); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "utils.WriteVarUint, serialize slashRate error!")
+	}
+	return nil
+}
+
+func (this *SlashRateParam) Deserialize(r io.Reader) error {
+	slashRate, err := utils.ReadVarUint(r)
+	if err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "utils.ReadVarUint, deserialize slashRate error!")
+	}
+	if slashRate > math.MaxUint32 {
+		return errors.NewErr("slashRate larger than max of uint32!")
+	}
+	this.SlashRate = uint32(slashRate)
+	return nil
+}
+
+type ProposalConfig struct {
+	Mode       uint32
+	Signers    []common.Address
+	Threshold  uint32
+	MinDelay   uint32
+	Expiration uint32
+}
+
+func (this *ProposalConfig) Serialize(w io.Writer) error {
+	if len(this.Signers) > 1024 {
+		return errors.NewErr("length of signers > 1024!")
+	}
+	if err := utils.WriteVarUint(w, uint64(this.Mode)); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "utils.WriteVarUint, serialize mode error!")
+	}
+	if err := utils.WriteVarUint(w, uint64(len(this.Signers))); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "utils.WriteVarUint, serialize signers length error!")
+	}
+	for _, v := range this.Signers {
+		if err := serialization.WriteVarBytes(w, v[:]); err != nil {
+			return errors.NewDetailErr(err, errors.ErrNoCode, "serialization.WriteVarBytes, serialize signer error!")
+		}
+	}
+	if err := utils.WriteVarUint(w, uint64(this.Threshold)); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "utils.WriteVarUint, serialize threshold error!")
+	}
+	if err := utils.WriteVarUint(w, uint64(this.MinDelay)); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "utils.WriteVarUint, serialize minDelay error!")
+	}
+	if err := utils.WriteVarUint(w, uint64(this.Expiration)); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "utils.WriteVarUint, serialize expiration error!")
+	}
+	return nil
+}
+
+func (this *ProposalConfig) Deserialize(r io.Reader) error {
+	mode, err := utils.ReadVarUint(r)
+	if err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "utils.ReadVarUint, deserialize mode error!")
+	}
+	if mode > math.MaxUint32 {
+		return errors.NewErr("mode larger than max of uint32!")
+	}
+	n, err := utils.ReadVarUint(r)
+	if err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "utils.ReadVarUint, deserialize signers length error!")
+	}
+	if n > 1024 {
+		return errors.NewErr("length of signers > 1024!")
+	}
+	signers := make([]common.Address, 0)
+	for i := 0; uint64(i) < n; i++ {
+		signer, err := utils.ReadAddress(r)
+		if err != nil {
+			return errors.NewDetailErr(err, errors.ErrNoCode, "utils.ReadAddress, deserialize signer error!")
+		}
+		signers = append(signers, signer)
+	}
+	threshold, err := utils.ReadVarUint(r)
+	if err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "utils.ReadVarUint, deserialize threshold error!")
+	}
+	if threshold > math.MaxUint32 {
+		return errors.NewErr("threshold larger than max of uint32!")
+	}
+	minDelay, err := utils.ReadVarUint(r)
+	if err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "utils.ReadVarUint, deserialize minDelay error!")
+	}
+	if minDelay > math.MaxUint32 {
+		return errors.NewErr("minDelay larger than max of uint32!")
+	}
+	expiration, err := utils.ReadVarUint(r)
+	if err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "utils.ReadVarUint, deserialize expiration error!")
+	}
+	if expiration > math.MaxUint32 {
+		return errors.NewErr("expiration larger than max of uint32!")
+	}
+	this.Mode = uint32(mode)
+	this.Signers = signers
+	this.Threshold = uint32(threshold)
+	this.MinDelay = uint32(minDelay)
+	this.Expiration = uint32(expiration)
+	return nil
+}
+
+type CreateProposalParam struct {
+	Proposer common.Address
+	Contract common.Address
+	Method   string
+	Args     []byte
+}
+
+func (this *CreateProposalParam) Serialize(w io.Writer) error {
+	if err := serialization.WriteVarBytes(w, this.Proposer[:]); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "serialization.WriteVarBytes, serialize proposer error!")
+	}
+	if err := serialization.WriteVarBytes(w, this.Contract[:]); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "serialization.WriteVarBytes, serialize contract error!")
+	}
+	if err := serialization.WriteString(w, this.Method); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "serialization.WriteString, serialize method error!")
+	}
+	if err := serialization.WriteVarBytes(w, this.Args); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "serialization.WriteVarBytes, serialize args error!")
+	}
+	return nil
+}
+
+func (this *CreateProposalParam) Deserialize(r io.Reader) error {
+	proposer, err := utils.ReadAddress(r)
+	if err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "utils.ReadAddress, deserialize proposer error!")
+	}
+	contract, err := utils.ReadAddress(r)
+	if err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "utils.ReadAddress, deserialize contract error!")
+	}
+	method, err := serialization.ReadString(r)
+	if err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "serialization.ReadString, deserialize method error!")
+	}
+	args, err := serialization.ReadVarBytes(r)
+	if err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "serialization.ReadVarBytes, deserialize args error!")
+	}
+	this.Proposer = proposer
+	this.Contract = contract
+	this.Method = method
+	this.Args = args
+	return nil
+}
+
+type ApproveProposalParam struct {
+	ID       uint64
+	Approver common.Address
+}
+
+func (this *ApproveProposalParam) Serialize(w io.Writer) error {
+	if err := utils.WriteVarUint(w, this.ID); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "utils.WriteVarUint, serialize id error!")
+	}
+	if err := serialization.WriteVarBytes(w, this.Approver[:]); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "serialization.WriteVarBytes, serialize approver error!")
+	}
+	return nil
+}
+
+func (this *ApproveProposalParam) Deserialize(r io.Reader) error {
+	id, err := utils.ReadVarUint(r)
+	if err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "utils.ReadVarUint, deserialize id error!")
+	}
+	approver, err := utils.ReadAddress(r)
+	if err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "utils.ReadAddress, deserialize approver error!")
+	}
+	this.ID = id
+	this.Approver = approver
+	return nil
+}
+
+type ProposalIDParam struct {
+	ID uint64
+}
+
+func (this *ProposalIDParam) Serialize(w io.Writer) error {
+	if err := utils.WriteVarUint(w, this.ID); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "utils.WriteVarUint, serialize id error!")
+	}
+	return nil
+}
+
+func (this *ProposalIDParam) Deserialize(r io.Reader) error {
+	id, err := utils.ReadVarUint(r)
+	if err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "utils.ReadVarUint, deserialize id error!")
+	}
+	this.ID = id
+	return nil
+}
+
+type GetStakeInfoParam struct {
+	Address common.Address
+}
+
+func (this *GetStakeInfoParam) Serialize(w io.Writer) error {
+	if err := utils.WriteAddress(w, this.Address); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "utils.WriteAddress, serialize address error!")
+	}
+	return nil
+}
+
+func (this *GetStakeInfoParam) Deserialize(r io.Reader) error {
+	address, err := utils.ReadAddress(r)
+	if err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "utils.ReadAddress, deserialize address error!")
+	}
+	this.Address = address
+	return nil
+}
+
+type GetSplitPayoutsParam struct {
+	Address   common.Address
+	StartView uint32
+	EndView   uint32
+}
+
+func (this *GetSplitPayoutsParam) Serialize(w io.Writer) error {
+	if err := utils.WriteAddress(w, this.Address); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "utils.WriteAddress, serialize address error!")
+	}
+	if err := utils.WriteVarUint(w, uint64(this.StartView)); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "utils.WriteVarUint, serialize startView error!")
+	}
+	if err := utils.WriteVarUint(w, uint64(this.EndView)); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "utils.WriteVarUint, serialize endView error!")
+	}
+	return nil
+}
+
+func (this *GetSplitPayoutsParam) Deserialize(r io.Reader) error {
+	address, err := utils.ReadAddress(r)
+	if err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "utils.ReadAddress, deserialize address error!")
+	}
+	startView, err := utils.ReadVarUint(r)
+	if err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "utils.ReadVarUint, deserialize startView error!")
+	}
+	endView, err := utils.ReadVarUint(r)
+	if err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "utils.ReadVarUint, deserialize endView error!")
+	}
+	if startView > math.MaxUint32 || endView > math.MaxUint32 {
+		return errors.NewErr("view out of range")
+	}
+	this.Address = address
+	this.StartView = uint32(startView)
+	this.EndView = uint32(endView)
+	return nil
+}
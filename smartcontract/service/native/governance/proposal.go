@@ -0,0 +1,457 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package governance
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/common/config"
+	"github.com/imZhuFei/zeepin/common/serialization"
+	cstates "github.com/imZhuFei/zeepin/core/states"
+	scommon "github.com/imZhuFei/zeepin/core/store/common"
+	"github.com/imZhuFei/zeepin/errors"
+	"github.com/imZhuFei/zeepin/smartcontract/event"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/global_params"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/utils"
+)
+
+const (
+	// approvals are counted by signers, threshold is the number of signers
+	PROPOSAL_MODE_SIGNERS = 0
+	// approvals are weighted by stake of consensus peers, threshold is the percent of total stake
+	PROPOSAL_MODE_STAKE = 1
+
+	// max of min delay and expiration, about one year of blocks
+	MAX_PROPOSAL_PERIOD = 6000000
+
+	// event of proposal expired
+	EXPIRE_PARAM_PROPOSAL = "expireParamProposal"
+)
+
+// methods which can be called by param proposals
+func isProposalTarget(contract common.Address, method string) bool {
+	switch contract {
+	case utils.ParamContractAddress:
+		return method == global_params.SET_GLOBAL_PARAM_NAME || method == global_params.SET_GOVERNOR
+	case utils.GovernanceContractAddress:
+		switch method {
+		case UPDATE_CONFIG, UPDATE_GLOBAL_PARAM, UPDATE_SPLIT_CURVE, UPDATE_SLASH_RATE, SET_PROPOSAL_CONFIG:
+			return true
+		}
+	}
+	return false
+}
+
+func checkProposalConfig(proposalConfig *ProposalConfig) error {
+	switch proposalConfig.Mode {
+	case PROPOSAL_MODE_SIGNERS:
+		if len(proposalConfig.Signers) == 0 {
+			return errors.NewErr("signers can not be empty in signers mode!")
+		}
+		signers := make(map[common.Address]bool)
+		for _, signer := range proposalConfig.Signers {
+			if signers[signer] {
+				return fmt.Errorf("duplicated signer %s", signer.ToBase58())
+			}
+			signers[signer] = true
+		}
+		if proposalConfig.Threshold == 0 || int(proposalConfig.Threshold) > len(proposalConfig.Signers) {
+			return errors.NewErr("threshold must be in [1, number of signers] in signers mode!")
+		}
+	case PROPOSAL_MODE_STAKE:
+		if len(proposalConfig.Signers) != 0 {
+			return errors.NewErr("signers must be empty in stake mode!")
+		}
+		if proposalConfig.Threshold == 0 || proposalConfig.Threshold > 100 {
+			return errors.NewErr("threshold must be in [1, 100] in stake mode!")
+		}
+	default:
+		return fmt.Errorf("unknown proposal mode %d", proposalConfig.Mode)
+	}
+	if proposalConfig.MinDelay > MAX_PROPOSAL_PERIOD {
+		return fmt.Errorf("minDelay must <= %d", MAX_PROPOSAL_PERIOD)
+	}
+	if proposalConfig.Expiration == 0 || proposalConfig.Expiration > MAX_PROPOSAL_PERIOD {
+		return fmt.Errorf("expiration must be in [1, %d]", MAX_PROPOSAL_PERIOD)
+	}
+	return nil
+}
+
+// Set the config of param proposals and hand over params to this contract.
+// It is called by operator and admin of params at the first time, then it can only be changed by proposals.
+func SetProposalConfig(native *native.NativeService) ([]byte, error) {
+	err := global_params.CheckParamWitness(native)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "setProposalConfig, checkWitness error!")
+	}
+
+	proposalConfig := new(ProposalConfig)
+	if err := proposalConfig.Deserialize(bytes.NewBuffer(native.Input)); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "deserialize, deserialize proposalConfig error!")
+	}
+	if err := checkProposalConfig(proposalConfig); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "setProposalConfig, check proposalConfig error!")
+	}
+	contract := native.ContextRef.CurrentContext().ContractAddress
+
+	if err := putProposalConfig(native, contract, proposalConfig); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "putProposalConfig, put proposalConfig error!")
+	}
+
+	//make this contract the governor of params
+	governor, err := global_params.GetStorageRole(native, global_params.GenerateGovernorKey(utils.ParamContractAddress))
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "getGovernor, get governor error!")
+	}
+	if governor != contract {
+		bf := new(bytes.Buffer)
+		if err := utils.WriteAddress(bf, contract); err != nil {
+			return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "utils.WriteAddress, write governor error!")
+		}
+		if _, err := native.NativeCall(utils.ParamContractAddress, global_params.SET_GOVERNOR, bf.Bytes()); err != nil {
+			return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "setGovernor, set governor of params error!")
+		}
+	}
+
+	addProposalNotification(native, contract, SET_PROPOSAL_CONFIG, proposalConfig.Mode, proposalConfig.Threshold,
+		proposalConfig.MinDelay, proposalConfig.Expiration)
+	return utils.BYTE_TRUE, nil
+}
+
+// Create a proposal to call a param method, the proposer should be an approver and approves it at the same time.
+func CreateParamProposal(native *native.NativeService) ([]byte, error) {
+	params := new(CreateProposalParam)
+	if err := params.Deserialize(bytes.NewBuffer(native.Input)); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "deserialize, deserialize createProposalParam error!")
+	}
+	if !isProposalTarget(params.Contract, params.Method) {
+		return utils.BYTE_FALSE, fmt.Errorf("createParamProposal, method %s of contract %s can not be proposed",
+			params.Method, params.Contract.ToHexString())
+	}
+
+	//check witness
+	err := utils.ValidateOwner(native, params.Proposer)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "createParamProposal, checkWitness error!")
+	}
+	contract := native.ContextRef.CurrentContext().ContractAddress
+
+	proposalConfig, err := getProposalConfig(native, contract)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "getProposalConfig, get proposalConfig error!")
+	}
+	weights, _, err := getApproverWeights(native, contract, proposalConfig)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "getApproverWeights, get approver weights error!")
+	}
+	if weights[params.Proposer] == 0 {
+		return utils.BYTE_FALSE, errors.NewErr("createParamProposal, proposer is not an approver!")
+	}
+
+	id, err := utils.GetStorageUInt64(native, utils.ConcatKey(contract, []byte(PROPOSAL_ID)))
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "getProposalID, get proposal id error!")
+	}
+	id = id + 1
+	native.CloneCache.Add(scommon.ST_STORAGE, utils.ConcatKey(contract, []byte(PROPOSAL_ID)), utils.GenUInt64StorageItem(id))
+
+	executeHeight := native.Height + proposalConfig.MinDelay
+	proposal := &ParamProposal{
+		ID:            id,
+		Proposer:      params.Proposer,
+		Contract:      params.Contract,
+		Method:        params.Method,
+		Args:          params.Args,
+		CreateHeight:  native.Height,
+		ExecuteHeight: executeHeight,
+		ExpireHeight:  executeHeight + proposalConfig.Expiration,
+		Status:        ProposalPending,
+		Approvers:     []common.Address{params.Proposer},
+	}
+	if err := putParamProposal(native, contract, proposal); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "putParamProposal, put proposal error!")
+	}
+
+	addProposalNotification(native, contract, CREATE_PARAM_PROPOSAL, id, params.Proposer.ToBase58(),
+		params.Contract.ToHexString(), params.Method, proposal.ExecuteHeight, proposal.ExpireHeight)
+	return utils.BYTE_TRUE, nil
+}
+
+// Approve a pending proposal, the approver should be an approver in proposal config.
+func ApproveParamProposal(native *native.NativeService) ([]byte, error) {
+	params := new(ApproveProposalParam)
+	if err := params.Deserialize(bytes.NewBuffer(native.Input)); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "deserialize, deserialize approveProposalParam error!")
+	}
+
+	//check witness
+	err := utils.ValidateOwner(native, params.Approver)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "approveParamProposal, checkWitness error!")
+	}
+	contract := native.ContextRef.CurrentContext().ContractAddress
+
+	proposal, err := getParamProposal(native, contract, params.ID)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "getParamProposal, get proposal error!")
+	}
+	if proposal.Status != ProposalPending {
+		return utils.BYTE_FALSE, errors.NewErr("approveParamProposal, proposal is not pending!")
+	}
+	if native.Height > proposal.ExpireHeight {
+		return utils.BYTE_FALSE, errors.NewErr("approveParamProposal, proposal is expired!")
+	}
+	for _, approver := range proposal.Approvers {
+		if approver == params.Approver {
+			return utils.BYTE_FALSE, errors.NewErr("approveParamProposal, proposal is already approved by approver!")
+		}
+	}
+
+	proposalConfig, err := getProposalConfig(native, contract)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "getProposalConfig, get proposalConfig error!")
+	}
+	weights, _, err := getApproverWeights(native, contract, proposalConfig)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "getApproverWeights, get approver weights error!")
+	}
+	if weights[params.Approver] == 0 {
+		return utils.BYTE_FALSE, errors.NewErr("approveParamProposal, approver is not an approver!")
+	}
+
+	proposal.Approvers = append(proposal.Approvers, params.Approver)
+	if err := putParamProposal(native, contract, proposal); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "putParamProposal, put proposal error!")
+	}
+
+	addProposalNotification(native, contract, APPROVE_PARAM_PROPOSAL, params.ID, params.Approver.ToBase58())
+	return utils.BYTE_TRUE, nil
+}
+
+// Execute a proposal after min delay if it is approved, can be called by anyone.
+// Approvals are counted with current proposal config and consensus peers.
+// A proposal not executed before expire height is marked as expired.
+func ExecuteParamProposal(native *native.NativeService) ([]byte, error) {
+	params := new(ProposalIDParam)
+	if err := params.Deserialize(bytes.NewBuffer(native.Input)); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "deserialize, deserialize proposalIDParam error!")
+	}
+	contract := native.ContextRef.CurrentContext().ContractAddress
+
+	proposal, err := getParamProposal(native, contract, params.ID)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "getParamProposal, get proposal error!")
+	}
+	if proposal.Status != ProposalPending {
+		return utils.BYTE_FALSE, errors.NewErr("executeParamProposal, proposal is not pending!")
+	}
+	if native.Height > proposal.ExpireHeight {
+		proposal.Status = ProposalExpired
+		if err := putParamProposal(native, contract, proposal); err != nil {
+			return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "putParamProposal, put proposal error!")
+		}
+		addProposalNotification(native, contract, EXPIRE_PARAM_PROPOSAL, params.ID)
+		return utils.BYTE_TRUE, nil
+	}
+	if native.Height < proposal.ExecuteHeight {
+		return utils.BYTE_FALSE, fmt.Errorf("executeParamProposal, proposal is time locked until height %d", proposal.ExecuteHeight)
+	}
+
+	proposalConfig, err := getProposalConfig(native, contract)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "getProposalConfig, get proposalConfig error!")
+	}
+	weights, total, err := getApproverWeights(native, contract, proposalConfig)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "getApproverWeights, get approver weights error!")
+	}
+	if !isProposalApproved(proposalConfig, weights, total, proposal.Approvers) {
+		return utils.BYTE_FALSE, errors.NewErr("executeParamProposal, proposal is not approved!")
+	}
+
+	proposal.Status = ProposalExecuted
+	if err := putParamProposal(native, contract, proposal); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "putParamProposal, put proposal error!")
+	}
+	if _, err := native.NativeCall(proposal.Contract, proposal.Method, proposal.Args); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "executeParamProposal, call proposal method error!")
+	}
+	//params take effect at once
+	if proposal.Contract == utils.ParamContractAddress && proposal.Method == global_params.SET_GLOBAL_PARAM_NAME {
+		if _, err := native.NativeCall(utils.ParamContractAddress, global_params.CREATE_SNAPSHOT_NAME, []byte{}); err != nil {
+			return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "executeParamProposal, create snapshot error!")
+		}
+	}
+
+	addProposalNotification(native, contract, EXECUTE_PARAM_PROPOSAL, params.ID)
+	return utils.BYTE_TRUE, nil
+}
+
+func GetParamProposal(native *native.NativeService) ([]byte, error) {
+	params := new(ProposalIDParam)
+	if err := params.Deserialize(bytes.NewBuffer(native.Input)); err != nil {
+		return nil, errors.NewDetailErr(err, errors.ErrNoCode, "deserialize, deserialize proposalIDParam error!")
+	}
+	contract := native.ContextRef.CurrentContext().ContractAddress
+
+	proposal, err := getParamProposal(native, contract, params.ID)
+	if err != nil {
+		return nil, errors.NewDetailErr(err, errors.ErrNoCode, "getParamProposal, get proposal error!")
+	}
+	bf := new(bytes.Buffer)
+	if err := proposal.Serialize(bf); err != nil {
+		return nil, errors.NewDetailErr(err, errors.ErrNoCode, "serialize, serialize proposal error!")
+	}
+	return bf.Bytes(), nil
+}
+
+func GetProposalConfig(native *native.NativeService) ([]byte, error) {
+	contract := native.ContextRef.CurrentContext().ContractAddress
+
+	proposalConfig, err := getProposalConfig(native, contract)
+	if err != nil {
+		return nil, errors.NewDetailErr(err, errors.ErrNoCode, "getProposalConfig, get proposalConfig error!")
+	}
+	bf := new(bytes.Buffer)
+	if err := proposalConfig.Serialize(bf); err != nil {
+		return nil, errors.NewDetailErr(err, errors.ErrNoCode, "serialize, serialize proposalConfig error!")
+	}
+	return bf.Bytes(), nil
+}
+
+// get weights of approvers, signers have weight 1 in signers mode, owners of consensus peers have weight of
+// init pos and votes of their peers in stake mode
+func getApproverWeights(native *native.NativeService, contract common.Address,
+	proposalConfig *ProposalConfig) (map[common.Address]uint64, uint64, error) {
+	weights := make(map[common.Address]uint64)
+	var total uint64
+	switch proposalConfig.Mode {
+	case PROPOSAL_MODE_SIGNERS:
+		for _, signer := range proposalConfig.Signers {
+			weights[signer] = 1
+			total = total + 1
+		}
+	case PROPOSAL_MODE_STAKE:
+		view, err := GetView(native, contract)
+		if err != nil {
+			return nil, 0, errors.NewDetailErr(err, errors.ErrNoCode, "getView, get view error!")
+		}
+		peerPoolMap, err := GetPeerPoolMap(native, contract, view)
+		if err != nil {
+			return nil, 0, errors.NewDetailErr(err, errors.ErrNoCode, "getPeerPoolMap, get peerPoolMap error!")
+		}
+		for _, peerPoolItem := range peerPoolMap.PeerPoolMap {
+			if peerPoolItem.Status != ConsensusStatus {
+				continue
+			}
+			stake := peerPoolItem.InitPos + peerPoolItem.TotalPos
+			weights[peerPoolItem.Address] = weights[peerPoolItem.Address] + stake
+			total = total + stake
+		}
+	default:
+		return nil, 0, fmt.Errorf("unknown proposal mode %d", proposalConfig.Mode)
+	}
+	return weights, total, nil
+}
+
+func isProposalApproved(proposalConfig *ProposalConfig, weights map[common.Address]uint64, total uint64,
+	approvers []common.Address) bool {
+	approved := new(big.Int)
+	for _, approver := range approvers {
+		approved.Add(approved, new(big.Int).SetUint64(weights[approver]))
+	}
+	if proposalConfig.Mode == PROPOSAL_MODE_SIGNERS {
+		return approved.Cmp(new(big.Int).SetUint64(uint64(proposalConfig.Threshold))) >= 0
+	}
+	if total == 0 {
+		return false
+	}
+	// approved / total >= threshold / 100
+	approved.Mul(approved, big.NewInt(100))
+	required := new(big.Int).Mul(new(big.Int).SetUint64(total), new(big.Int).SetUint64(uint64(proposalConfig.Threshold)))
+	return approved.Cmp(required) >= 0
+}
+
+func getProposalConfig(native *native.NativeService, contract common.Address) (*ProposalConfig, error) {
+	item, err := utils.GetStorageItem(native, utils.ConcatKey(contract, []byte(PROPOSAL_CONFIG)))
+	if err != nil {
+		return nil, errors.NewDetailErr(err, errors.ErrNoCode, "getProposalConfig, get proposalConfig error!")
+	}
+	if item == nil {
+		return nil, errors.NewErr("getProposalConfig, proposal config is not set!")
+	}
+	proposalConfig := new(ProposalConfig)
+	if err := proposalConfig.Deserialize(bytes.NewBuffer(item.Value)); err != nil {
+		return nil, errors.NewDetailErr(err, errors.ErrNoCode, "deserialize, deserialize proposalConfig error!")
+	}
+	return proposalConfig, nil
+}
+
+func putProposalConfig(native *native.NativeService, contract common.Address, proposalConfig *ProposalConfig) error {
+	bf := new(bytes.Buffer)
+	if err := proposalConfig.Serialize(bf); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "serialize, serialize proposalConfig error!")
+	}
+	native.CloneCache.Add(scommon.ST_STORAGE, utils.ConcatKey(contract, []byte(PROPOSAL_CONFIG)), &cstates.StorageItem{Value: bf.Bytes()})
+	return nil
+}
+
+func genParamProposalKey(contract common.Address, id uint64) []byte {
+	bf := new(bytes.Buffer)
+	serialization.WriteUint64(bf, id)
+	return utils.ConcatKey(contract, []byte(PARAM_PROPOSAL), bf.Bytes())
+}
+
+func getParamProposal(native *native.NativeService, contract common.Address, id uint64) (*ParamProposal, error) {
+	item, err := utils.GetStorageItem(native, genParamProposalKey(contract, id))
+	if err != nil {
+		return nil, errors.NewDetailErr(err, errors.ErrNoCode, "getParamProposal, get proposal error!")
+	}
+	if item == nil {
+		return nil, fmt.Errorf("getParamProposal, proposal %d not exist", id)
+	}
+	proposal := new(ParamProposal)
+	if err := proposal.Deserialize(bytes.NewBuffer(item.Value)); err != nil {
+		return nil, errors.NewDetailErr(err, errors.ErrNoCode, "deserialize, deserialize proposal error!")
+	}
+	return proposal, nil
+}
+
+func putParamProposal(native *native.NativeService, contract common.Address, proposal *ParamProposal) error {
+	bf := new(bytes.Buffer)
+	if err := proposal.Serialize(bf); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "serialize, serialize proposal error!")
+	}
+	native.CloneCache.Add(scommon.ST_STORAGE, genParamProposalKey(contract, proposal.ID), &cstates.StorageItem{Value: bf.Bytes()})
+	return nil
+}
+
+func addProposalNotification(native *native.NativeService, contract common.Address, states ...interface{}) {
+	if !config.DefConfig.Common.EnableEventLog {
+		return
+	}
+	native.Notifications = append(native.Notifications,
+		&event.NotifyEventInfo{
+			ContractAddress: contract,
+			States:          states,
+		})
+}
@@ -0,0 +1,259 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package governance
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/common/config"
+	"github.com/imZhuFei/zeepin/common/serialization"
+	"github.com/imZhuFei/zeepin/smartcontract/context"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/global_params"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/testutil"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func getGasPrice(t *testing.T, srvc *native.NativeService, ctx *testutil.Context) string {
+	ctx.PushContext(&context.Context{ContractAddress: utils.ParamContractAddress})
+	defer ctx.PopContext()
+	ret, err := testutil.Invoke(t, srvc, global_params.GetGlobalParam, &global_params.ParamNameList{"gasPrice"})
+	assert.Nil(t, err)
+	params := global_params.Params{}
+	assert.Nil(t, params.Deserialize(bytes.NewBuffer(ret)))
+	return params[0].Value
+}
+
+func getProposal(t *testing.T, srvc *native.NativeService, id uint64) *ParamProposal {
+	ret, err := testutil.Invoke(t, srvc, GetParamProposal, &ProposalIDParam{ID: id})
+	assert.Nil(t, err)
+	proposal := new(ParamProposal)
+	assert.Nil(t, proposal.Deserialize(bytes.NewBuffer(ret)))
+	return proposal
+}
+
+//initParamProposals init params with admin as operator and admin, then hand over params to proposals
+//approved by 2 of signers, with min delay 10 and expiration 5 at height 100.
+//The returned function closes the storage of native service.
+func initParamProposals(t *testing.T, admin common.Address,
+	signers []common.Address) (*native.NativeService, *testutil.Context, func()) {
+	ctx := testutil.NewContext(admin)
+	srvc, closeStore := testutil.NewNativeService(t, ctx)
+	srvc.Height = 100
+
+	ctx.PushContext(&context.Context{ContractAddress: utils.ParamContractAddress})
+	args := new(bytes.Buffer)
+	initParams := global_params.Params{{Key: "gasPrice", Value: "0"}}
+	assert.Nil(t, initParams.Serialize(args))
+	assert.Nil(t, utils.WriteAddress(args, admin))
+	input := new(bytes.Buffer)
+	assert.Nil(t, serialization.WriteVarBytes(input, args.Bytes()))
+	srvc.Input = input.Bytes()
+	_, err := global_params.ParamInit(srvc)
+	assert.Nil(t, err)
+	ctx.PopContext()
+
+	ctx.PushContext(&context.Context{ContractAddress: utils.GovernanceContractAddress})
+	_, err = testutil.Invoke(t, srvc, SetProposalConfig, &ProposalConfig{
+		Mode:       PROPOSAL_MODE_SIGNERS,
+		Signers:    signers,
+		Threshold:  2,
+		MinDelay:   10,
+		Expiration: 5,
+	})
+	assert.Nil(t, err)
+	return srvc, ctx, closeStore
+}
+
+func TestParamProposal(t *testing.T) {
+	InitGovernance()
+	global_params.InitGlobalParams()
+	networkId := config.DefConfig.P2PNode.NetworkId
+	defer func() { config.DefConfig.P2PNode.NetworkId = networkId }()
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_SOLO_NET
+
+	admin := common.Address{0x01}
+	signer1, signer2, signer3, other := common.Address{0x11}, common.Address{0x12}, common.Address{0x13}, common.Address{0x14}
+	srvc, ctx, closeStore := initParamProposals(t, admin, []common.Address{signer1, signer2, signer3})
+	defer closeStore()
+
+	// params can not be changed by operator any more
+	ctx.Reset(utils.ParamContractAddress)
+	_, err := testutil.Invoke(t, srvc, global_params.SetGlobalParam, &global_params.Params{{Key: "gasPrice", Value: "500"}})
+	assert.NotNil(t, err)
+	ctx.Reset(utils.GovernanceContractAddress)
+	_, err = testutil.Invoke(t, srvc, SetProposalConfig, &ProposalConfig{Mode: PROPOSAL_MODE_STAKE, Threshold: 50, Expiration: 5})
+	assert.NotNil(t, err)
+
+	paramArgs := new(bytes.Buffer)
+	assert.Nil(t, (&global_params.Params{{Key: "gasPrice", Value: "500"}}).Serialize(paramArgs))
+	create := &CreateProposalParam{
+		Proposer: signer1,
+		Contract: utils.ParamContractAddress,
+		Method:   global_params.SET_GLOBAL_PARAM_NAME,
+		Args:     paramArgs.Bytes(),
+	}
+	// proposer should be witnessed signer
+	_, err = testutil.Invoke(t, srvc, CreateParamProposal, create)
+	assert.NotNil(t, err)
+	ctx.Sign(signer1, other)
+	_, err = testutil.Invoke(t, srvc, CreateParamProposal, &CreateProposalParam{
+		Proposer: other, Contract: create.Contract, Method: create.Method, Args: create.Args})
+	assert.NotNil(t, err)
+	_, err = testutil.Invoke(t, srvc, CreateParamProposal, &CreateProposalParam{
+		Proposer: signer1, Contract: utils.GalaContractAddress, Method: "transfer", Args: create.Args})
+	assert.NotNil(t, err)
+	_, err = testutil.Invoke(t, srvc, CreateParamProposal, create)
+	assert.Nil(t, err)
+
+	proposal := getProposal(t, srvc, 1)
+	assert.Equal(t, uint32(110), proposal.ExecuteHeight)
+	assert.Equal(t, uint32(115), proposal.ExpireHeight)
+	assert.Equal(t, []common.Address{signer1}, proposal.Approvers)
+
+	// not enough approvals
+	srvc.Height = 110
+	_, err = testutil.Invoke(t, srvc, ExecuteParamProposal, &ProposalIDParam{ID: 1})
+	assert.NotNil(t, err)
+
+	ctx.Sign(signer2, other)
+	_, err = testutil.Invoke(t, srvc, ApproveParamProposal, &ApproveProposalParam{ID: 1, Approver: other})
+	assert.NotNil(t, err)
+	_, err = testutil.Invoke(t, srvc, ApproveParamProposal, &ApproveProposalParam{ID: 1, Approver: signer2})
+	assert.Nil(t, err)
+	_, err = testutil.Invoke(t, srvc, ApproveParamProposal, &ApproveProposalParam{ID: 1, Approver: signer2})
+	assert.NotNil(t, err)
+
+	// time locked
+	srvc.Height = 109
+	_, err = testutil.Invoke(t, srvc, ExecuteParamProposal, &ProposalIDParam{ID: 1})
+	assert.NotNil(t, err)
+	assert.Equal(t, "0", getGasPrice(t, srvc, ctx))
+
+	srvc.Height = 112
+	ctx.Sign()
+	_, err = testutil.Invoke(t, srvc, ExecuteParamProposal, &ProposalIDParam{ID: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, "500", getGasPrice(t, srvc, ctx))
+	assert.Equal(t, ProposalExecuted, getProposal(t, srvc, 1).Status)
+	_, err = testutil.Invoke(t, srvc, ExecuteParamProposal, &ProposalIDParam{ID: 1})
+	assert.NotNil(t, err)
+
+	// expired
+	ctx.Sign(signer3)
+	create.Proposer = signer3
+	_, err = testutil.Invoke(t, srvc, CreateParamProposal, create)
+	assert.Nil(t, err)
+	srvc.Height = 200
+	_, err = testutil.Invoke(t, srvc, ApproveParamProposal, &ApproveProposalParam{ID: 2, Approver: signer1})
+	assert.NotNil(t, err)
+	_, err = testutil.Invoke(t, srvc, ExecuteParamProposal, &ProposalIDParam{ID: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, ProposalExpired, getProposal(t, srvc, 2).Status)
+}
+
+func TestSetGovernorByProposal(t *testing.T) {
+	InitGovernance()
+	global_params.InitGlobalParams()
+	networkId := config.DefConfig.P2PNode.NetworkId
+	defer func() { config.DefConfig.P2PNode.NetworkId = networkId }()
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_SOLO_NET
+
+	admin := common.Address{0x01}
+	signer1, signer2 := common.Address{0x11}, common.Address{0x12}
+	srvc, ctx, closeStore := initParamProposals(t, admin, []common.Address{signer1, signer2})
+	defer closeStore()
+
+	// admin can not take params back from proposals
+	emptyGovernor := new(bytes.Buffer)
+	assert.Nil(t, utils.WriteAddress(emptyGovernor, common.ADDRESS_EMPTY))
+	ctx.Reset(utils.ParamContractAddress)
+	srvc.Input = emptyGovernor.Bytes()
+	_, err := global_params.SetGovernor(srvc)
+	assert.NotNil(t, err)
+	otherGovernor := new(bytes.Buffer)
+	assert.Nil(t, utils.WriteAddress(otherGovernor, admin))
+	srvc.Input = otherGovernor.Bytes()
+	_, err = global_params.SetGovernor(srvc)
+	assert.NotNil(t, err)
+	_, err = testutil.Invoke(t, srvc, global_params.SetGlobalParam, &global_params.Params{{Key: "gasPrice", Value: "500"}})
+	assert.NotNil(t, err)
+
+	// an executed proposal gives params back to the operator
+	ctx.Reset(utils.GovernanceContractAddress)
+	ctx.Sign(signer1)
+	_, err = testutil.Invoke(t, srvc, CreateParamProposal, &CreateProposalParam{
+		Proposer: signer1,
+		Contract: utils.ParamContractAddress,
+		Method:   global_params.SET_GOVERNOR,
+		Args:     emptyGovernor.Bytes(),
+	})
+	assert.Nil(t, err)
+	ctx.Sign(signer2)
+	_, err = testutil.Invoke(t, srvc, ApproveParamProposal, &ApproveProposalParam{ID: 1, Approver: signer2})
+	assert.Nil(t, err)
+	srvc.Height = 110
+	ctx.Sign()
+	_, err = testutil.Invoke(t, srvc, ExecuteParamProposal, &ProposalIDParam{ID: 1})
+	assert.Nil(t, err)
+
+	ctx.Sign(admin)
+	ctx.Reset(utils.ParamContractAddress)
+	_, err = testutil.Invoke(t, srvc, global_params.SetGlobalParam, &global_params.Params{{Key: "gasPrice", Value: "500"}})
+	assert.Nil(t, err)
+}
+
+func TestIsProposalApproved(t *testing.T) {
+	a, b, c := common.Address{0x01}, common.Address{0x02}, common.Address{0x03}
+	weights := map[common.Address]uint64{a: 60, b: 30, c: 10}
+	proposalConfig := &ProposalConfig{Mode: PROPOSAL_MODE_STAKE, Threshold: 67}
+	assert.False(t, isProposalApproved(proposalConfig, weights, 100, []common.Address{a}))
+	assert.False(t, isProposalApproved(proposalConfig, weights, 100, []common.Address{b, c}))
+	assert.True(t, isProposalApproved(proposalConfig, weights, 100, []common.Address{a, c}))
+	assert.False(t, isProposalApproved(proposalConfig, weights, 0, []common.Address{a, b, c}))
+
+	proposalConfig = &ProposalConfig{Mode: PROPOSAL_MODE_SIGNERS, Signers: []common.Address{a, b, c}, Threshold: 2}
+	signerWeights := map[common.Address]uint64{a: 1, b: 1, c: 1}
+	assert.False(t, isProposalApproved(proposalConfig, signerWeights, 3, []common.Address{a, common.Address{0x04}}))
+	assert.True(t, isProposalApproved(proposalConfig, signerWeights, 3, []common.Address{a, c}))
+}
+
+func TestRegisterProposalMethods(t *testing.T) {
+	networkId := config.DefConfig.P2PNode.NetworkId
+	defer func() { config.DefConfig.P2PNode.NetworkId = networkId }()
+	methods := []string{SET_PROPOSAL_CONFIG, CREATE_PARAM_PROPOSAL, APPROVE_PARAM_PROPOSAL,
+		EXECUTE_PARAM_PROPOSAL, GET_PARAM_PROPOSAL, GET_PROPOSAL_CONFIG}
+
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_MAIN_NET
+	srvc := &native.NativeService{ServiceMap: make(map[string]native.Handler), Height: 10}
+	RegisterGovernanceContract(srvc)
+	global_params.RegisterParamContract(srvc)
+	for _, method := range append(methods, global_params.SET_GOVERNOR) {
+		assert.NotContains(t, srvc.ServiceMap, method)
+	}
+
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_SOLO_NET
+	RegisterGovernanceContract(srvc)
+	global_params.RegisterParamContract(srvc)
+	for _, method := range append(methods, global_params.SET_GOVERNOR) {
+		assert.Contains(t, srvc.ServiceMap, method)
+	}
+}
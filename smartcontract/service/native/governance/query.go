@@ -0,0 +1,220 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package governance
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/common/constants"
+	cstates "github.com/imZhuFei/zeepin/core/states"
+	scommon "github.com/imZhuFei/zeepin/core/store/common"
+	"github.com/imZhuFei/zeepin/errors"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/utils"
+)
+
+//Get the stakes per peer, the pending and withdrawable zpt and the unbound gala of an address, used by users.
+func GetStakeInfo(native *native.NativeService) ([]byte, error) {
+	param := new(GetStakeInfoParam)
+	if err := param.Deserialize(bytes.NewBuffer(native.Input)); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "deserialize, deserialize getStakeInfoParam error!")
+	}
+	contract := native.ContextRef.CurrentContext().ContractAddress
+
+	governanceView, err := GetGovernanceView(native, contract)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "getGovernanceView, get governanceView error!")
+	}
+	config, err := getConfig(native, contract)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "getConfig, get config error!")
+	}
+	peerPoolMap, err := GetPeerPoolMap(native, contract, governanceView.View)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "getPeerPoolMap, get peerPoolMap error!")
+	}
+	totalStake, err := getTotalStake(native, contract, param.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "getTotalStake, get totalStake error!")
+	}
+
+	nextViewHeight := governanceView.Height + config.MaxBlockChangeView
+	stakeInfo := &StakeInfo{
+		Address:        param.Address,
+		View:           governanceView.View,
+		NextViewHeight: nextViewHeight,
+		TotalStake:     totalStake.Stake,
+		UnboundGala: utils.CalcUnbindGala(totalStake.Stake, totalStake.TimeOffset,
+			native.Time-constants.GENESIS_BLOCK_TIMESTAMP),
+		Peers: make([]*PeerStake, 0),
+	}
+	peerStakes, err := getPeerStakes(native, contract, param.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "getPeerStakes, get peer stakes error!")
+	}
+	for _, peerPoolItem := range peerPoolMap.PeerPoolMap {
+		if peerPoolItem.Address != param.Address {
+			continue
+		}
+		peerStake, ok := peerStakes[peerPoolItem.PeerPubkey]
+		if !ok {
+			peerStake = &PeerStake{PeerPubkey: peerPoolItem.PeerPubkey}
+			peerStakes[peerPoolItem.PeerPubkey] = peerStake
+		}
+		peerStake.InitPos = peerPoolItem.InitPos
+	}
+	for _, peerStake := range peerStakes {
+		// withdrawPos is unfrozen by two view changes and withdrawFreezePos by one
+		if peerStake.WithdrawPos != 0 {
+			peerStake.UnlockHeight = nextViewHeight + config.MaxBlockChangeView
+		} else if peerStake.WithdrawFreezePos != 0 {
+			peerStake.UnlockHeight = nextViewHeight
+		}
+		stakeInfo.Pending += peerStake.WithdrawPos + peerStake.WithdrawFreezePos
+		stakeInfo.Withdrawable += peerStake.WithdrawUnfreezePos
+		stakeInfo.Peers = append(stakeInfo.Peers, peerStake)
+	}
+	sort.SliceStable(stakeInfo.Peers, func(i, j int) bool {
+		return stakeInfo.Peers[i].PeerPubkey < stakeInfo.Peers[j].PeerPubkey
+	})
+
+	bf := new(bytes.Buffer)
+	if err := stakeInfo.Serialize(bf); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "serialize, serialize stakeInfo error!")
+	}
+	return bf.Bytes(), nil
+}
+
+//Get the gala paid to an address by the fee split of each view in [StartView, EndView], used by users.
+//EndView 0 means the current view, StartView 0 means MAX_SPLIT_PAYOUT_VIEWS views before EndView.
+func GetSplitPayouts(native *native.NativeService) ([]byte, error) {
+	param := new(GetSplitPayoutsParam)
+	if err := param.Deserialize(bytes.NewBuffer(native.Input)); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "deserialize, deserialize getSplitPayoutsParam error!")
+	}
+	contract := native.ContextRef.CurrentContext().ContractAddress
+
+	endView := param.EndView
+	if endView == 0 {
+		view, err := GetView(native, contract)
+		if err != nil {
+			return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "getView, get view error!")
+		}
+		endView = view
+	}
+	startView := param.StartView
+	if startView == 0 && endView >= MAX_SPLIT_PAYOUT_VIEWS {
+		startView = endView - MAX_SPLIT_PAYOUT_VIEWS + 1
+	}
+	if startView > endView {
+		return utils.BYTE_FALSE, errors.NewErr("getSplitPayouts, startView is larger than endView!")
+	}
+	if endView-startView >= MAX_SPLIT_PAYOUT_VIEWS {
+		return utils.BYTE_FALSE, errors.NewErr("getSplitPayouts, too many views!")
+	}
+
+	list := &SplitPayoutList{Payouts: make([]*SplitPayout, 0)}
+	for view := startView; ; view++ {
+		amount, err := getSplitPayout(native, contract, view, param.Address)
+		if err != nil {
+			return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "getSplitPayout, get split payout error!")
+		}
+		if amount != 0 {
+			list.Payouts = append(list.Payouts, &SplitPayout{View: view, Amount: amount})
+		}
+		if view == endView {
+			break
+		}
+	}
+
+	bf := new(bytes.Buffer)
+	if err := list.Serialize(bf); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "serialize, serialize splitPayoutList error!")
+	}
+	return bf.Bytes(), nil
+}
+
+// getPeerStakes returns the vote info of address on every peer, including the
+// peers which have quit but still hold withdrawable zpt of address
+func getPeerStakes(native *native.NativeService, contract common.Address, address common.Address) (map[string]*PeerStake, error) {
+	stateValues, err := native.CloneCache.Find(scommon.ST_STORAGE, utils.ConcatKey(contract, []byte(VOTE_INFO_POOL)))
+	if err != nil {
+		return nil, errors.NewDetailErr(err, errors.ErrNoCode, "native.CloneCache.Find, get all voteInfo error!")
+	}
+	peerStakes := make(map[string]*PeerStake)
+	for _, v := range stateValues {
+		voteInfoStore, ok := v.Value.(*cstates.StorageItem)
+		if !ok {
+			return nil, errors.NewErr("voteInfoStore is not available!")
+		}
+		voteInfo := new(VoteInfo)
+		if err := voteInfo.Deserialize(bytes.NewBuffer(voteInfoStore.Value)); err != nil {
+			return nil, errors.NewDetailErr(err, errors.ErrNoCode, "deserialize, deserialize voteInfo error!")
+		}
+		if voteInfo.Address != address {
+			continue
+		}
+		peerStakes[voteInfo.PeerPubkey] = &PeerStake{
+			PeerPubkey:          voteInfo.PeerPubkey,
+			ConsensusPos:        voteInfo.ConsensusPos,
+			FreezePos:           voteInfo.FreezePos,
+			NewPos:              voteInfo.NewPos,
+			WithdrawPos:         voteInfo.WithdrawPos,
+			WithdrawFreezePos:   voteInfo.WithdrawFreezePos,
+			WithdrawUnfreezePos: voteInfo.WithdrawUnfreezePos,
+		}
+	}
+	return peerStakes, nil
+}
+
+func genSplitPayoutKey(contract common.Address, view uint32, address common.Address) ([]byte, error) {
+	viewBytes, err := GetUint32Bytes(view)
+	if err != nil {
+		return nil, errors.NewDetailErr(err, errors.ErrNoCode, "GetUint32Bytes, get viewBytes error!")
+	}
+	return utils.ConcatKey(contract, []byte(SPLIT_PAYOUT), viewBytes, address[:]), nil
+}
+
+func getSplitPayout(native *native.NativeService, contract common.Address, view uint32, address common.Address) (uint64, error) {
+	key, err := genSplitPayoutKey(contract, view, address)
+	if err != nil {
+		return 0, err
+	}
+	return utils.GetStorageUInt64(native, key)
+}
+
+// addSplitPayout records the gala paid to address by the fee split of view,
+// the payouts of the nodes owned by the same address are summed up
+func addSplitPayout(native *native.NativeService, contract common.Address, view uint32, address common.Address, amount uint64) error {
+	if amount == 0 {
+		return nil
+	}
+	key, err := genSplitPayoutKey(contract, view, address)
+	if err != nil {
+		return err
+	}
+	payout, err := utils.GetStorageUInt64(native, key)
+	if err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "getSplitPayout, get split payout error!")
+	}
+	native.CloneCache.Add(scommon.ST_STORAGE, key, utils.GenUInt64StorageItem(payout+amount))
+	return nil
+}
@@ -0,0 +1,92 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package governance
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/common/constants"
+	"github.com/imZhuFei/zeepin/common/log"
+	"github.com/imZhuFei/zeepin/smartcontract/context"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/testutil"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStakeQuery(t *testing.T) {
+	log.InitLog(log.InfoLog, log.Stdout)
+	ctx := testutil.NewContext()
+	ctx.PushContext(&context.Context{ContractAddress: utils.GovernanceContractAddress})
+	srvc, closeStore := testutil.NewNativeService(t, ctx)
+	defer closeStore()
+	srvc.Height = 1050
+	srvc.Time = constants.GENESIS_BLOCK_TIMESTAMP + 3600
+	contract := utils.GovernanceContractAddress
+	voter, other := common.Address{0x01}, common.Address{0x02}
+	peerA, peerB := "02aa", "02bb"
+
+	assert.Nil(t, putGovernanceView(srvc, contract, &GovernanceView{View: 5, Height: 1000}))
+	assert.Nil(t, putConfig(srvc, contract, &Configuration{MaxBlockChangeView: 100}))
+	assert.Nil(t, putPeerPoolMap(srvc, contract, 5, &PeerPoolMap{PeerPoolMap: map[string]*PeerPoolItem{
+		peerA: {PeerPubkey: peerA, Address: voter, Status: ConsensusStatus, InitPos: 1000, TotalPos: 507},
+	}}))
+	assert.Nil(t, putVoteInfo(srvc, contract, &VoteInfo{PeerPubkey: peerA, Address: voter, ConsensusPos: 500, WithdrawFreezePos: 50}))
+	assert.Nil(t, putVoteInfo(srvc, contract, &VoteInfo{PeerPubkey: peerA, Address: other, ConsensusPos: 7}))
+	// peerB has quit, the zpt of voter is still waiting for unlock on it
+	assert.Nil(t, putVoteInfo(srvc, contract, &VoteInfo{PeerPubkey: peerB, Address: voter, WithdrawPos: 30, WithdrawUnfreezePos: 20}))
+	assert.Nil(t, putTotalStake(srvc, contract, &TotalStake{Address: voter, Stake: 1600, TimeOffset: 0}))
+
+	ret, err := testutil.Invoke(t, srvc, GetStakeInfo, &GetStakeInfoParam{Address: voter})
+	assert.Nil(t, err)
+	stakeInfo := new(StakeInfo)
+	assert.Nil(t, stakeInfo.Deserialize(bytes.NewBuffer(ret)))
+	assert.Equal(t, &StakeInfo{
+		Address:        voter,
+		View:           5,
+		NextViewHeight: 1100,
+		TotalStake:     1600,
+		UnboundGala:    utils.CalcUnbindGala(1600, 0, 3600),
+		Pending:        80,
+		Withdrawable:   20,
+		Peers: []*PeerStake{
+			{PeerPubkey: peerA, InitPos: 1000, ConsensusPos: 500, WithdrawFreezePos: 50, UnlockHeight: 1100},
+			{PeerPubkey: peerB, WithdrawPos: 30, WithdrawUnfreezePos: 20, UnlockHeight: 1200},
+		},
+	}, stakeInfo)
+
+	assert.Nil(t, addSplitPayout(srvc, contract, 3, voter, 10))
+	assert.Nil(t, addSplitPayout(srvc, contract, 3, voter, 5))
+	assert.Nil(t, addSplitPayout(srvc, contract, 5, voter, 7))
+	assert.Nil(t, addSplitPayout(srvc, contract, 5, other, 9))
+	ret, err = testutil.Invoke(t, srvc, GetSplitPayouts, &GetSplitPayoutsParam{Address: voter})
+	assert.Nil(t, err)
+	payouts := new(SplitPayoutList)
+	assert.Nil(t, payouts.Deserialize(bytes.NewBuffer(ret)))
+	assert.Equal(t, []*SplitPayout{{View: 3, Amount: 15}, {View: 5, Amount: 7}}, payouts.Payouts)
+
+	ret, err = testutil.Invoke(t, srvc, GetSplitPayouts, &GetSplitPayoutsParam{Address: voter, StartView: 4, EndView: 4})
+	assert.Nil(t, err)
+	assert.Nil(t, payouts.Deserialize(bytes.NewBuffer(ret)))
+	assert.Equal(t, 0, len(payouts.Payouts))
+	_, err = testutil.Invoke(t, srvc, GetSplitPayouts, &GetSplitPayoutsParam{Address: voter, StartView: 5, EndView: 4})
+	assert.NotNil(t, err)
+	_, err = testutil.Invoke(t, srvc, GetSplitPayouts, &GetSplitPayoutsParam{Address: voter, StartView: 1, EndView: MAX_SPLIT_PAYOUT_VIEWS + 1})
+	assert.NotNil(t, err)
+}
@@ -510,3 +510,290 @@ type SyncNodeSplitInfo struct {
 	InitPos    uint64
 	S          uint64
 }
+
+type ProposalStatus uint8
+
+const (
+	ProposalPending ProposalStatus = iota
+	ProposalExecuted
+	ProposalExpired
+)
+
+type ParamProposal struct {
+	ID            uint64
+	Proposer      common.Address
+	Contract      common.Address
+	Method        string
+	Args          []byte
+	CreateHeight  uint32
+	ExecuteHeight uint32
+	ExpireHeight  uint32
+	Status        ProposalStatus
+	Approvers     []common.Address
+}
+
+func (this *ParamProposal) Serialize(w io.Writer) error {
+	if err := serialization.WriteUint64(w, this.ID); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "serialization.WriteUint64, serialize id error!")
+	}
+	if err := this.Proposer.Serialize(w); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "address.Serialize, serialize proposer error!")
+	}
+	if err := this.Contract.Serialize(w); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "address.Serialize, serialize contract error!")
+	}
+	if err := serialization.WriteString(w, this.Method); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "serialization.WriteString, serialize method error!")
+	}
+	if err := serialization.WriteVarBytes(w, this.Args); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "serialization.WriteVarBytes, serialize args error!")
+	}
+	if err := serialization.WriteUint32(w, this.CreateHeight); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "serialization.WriteUint32, serialize createHeight error!")
+	}
+	if err := serialization.WriteUint32(w, this.ExecuteHeight); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "serialization.WriteUint32, serialize executeHeight error!")
+	}
+	if err := serialization.WriteUint32(w, this.ExpireHeight); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "serialization.WriteUint32, serialize expireHeight error!")
+	}
+	if err := serialization.WriteUint8(w, uint8(this.Status)); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "serialization.WriteUint8, serialize status error!")
+	}
+	if err := serialization.WriteUint32(w, uint32(len(this.Approvers))); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "serialization.WriteUint32, serialize approvers length error!")
+	}
+	for _, v := range this.Approvers {
+		if err := v.Serialize(w); err != nil {
+			return errors.NewDetailErr(err, errors.ErrNoCode, "address.Serialize, serialize approver error!")
+		}
+	}
+	return nil
+}
+
+func (this *ParamProposal) Deserialize(r io.Reader) error {
+	id, err := serialization.ReadUint64(r)
+	if err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "serialization.ReadUint64, deserialize id error!")
+	}
+	proposer := new(common.Address)
+	if err := proposer.Deserialize(r); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "address.Deserialize, deserialize proposer error!")
+	}
+	contract := new(common.Address)
+	if err := contract.Deserialize(r); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "address.Deserialize, deserialize contract error!")
+	}
+	method, err := serialization.ReadString(r)
+	if err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "serialization.ReadString, deserialize method error!")
+	}
+	args, err := serialization.ReadVarBytes(r)
+	if err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "serialization.ReadVarBytes, deserialize args error!")
+	}
+	createHeight, err := serialization.ReadUint32(r)
+	if err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "serialization.ReadUint32, deserialize createHeight error!")
+	}
+	executeHeight, err := serialization.ReadUint32(r)
+	if err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "serialization.ReadUint32, deserialize executeHeight error!")
+	}
+	expireHeight, err := serialization.ReadUint32(r)
+	if err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "serialization.ReadUint32, deserialize expireHeight error!")
+	}
+	status, err := serialization.ReadUint8(r)
+	if err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "serialization.ReadUint8, deserialize status error!")
+	}
+	n, err := serialization.ReadUint32(r)
+	if err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "serialization.ReadUint32, deserialize approvers length error!")
+	}
+	approvers := make([]common.Address, 0)
+	for i := uint32(0); i < n; i++ {
+		approver := new(common.Address)
+		if err := approver.Deserialize(r); err != nil {
+			return errors.NewDetailErr(err, errors.ErrNoCode, "address.Deserialize, deserialize approver error!")
+		}
+		approvers = append(approvers, *approver)
+	}
+	this.ID = id
+	this.Proposer = *proposer
+	this.Contract = *contract
+	this.Method = method
+	this.Args = args
+	this.CreateHeight = createHeight
+	this.ExecuteHeight = executeHeight
+	this.ExpireHeight = expireHeight
+	this.Status = ProposalStatus(status)
+	this.Approvers = approvers
+	return nil
+}
+
+// PeerStake is the stake of an address on a peer, InitPos is set if the address
+// is the owner of the peer. UnlockHeight is the earliest height at which all of
+// WithdrawPos and WithdrawFreezePos become withdrawable, 0 if nothing is pending.
+type PeerStake struct {
+	PeerPubkey          string
+	InitPos             uint64
+	ConsensusPos        uint64
+	FreezePos           uint64
+	NewPos              uint64
+	WithdrawPos         uint64
+	WithdrawFreezePos   uint64
+	WithdrawUnfreezePos uint64
+	UnlockHeight        uint32
+}
+
+func (this *PeerStake) Serialize(w io.Writer) error {
+	if err := serialization.WriteString(w, this.PeerPubkey); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "serialization.WriteString, serialize peerPubkey error!")
+	}
+	for _, v := range []uint64{this.InitPos, this.ConsensusPos, this.FreezePos, this.NewPos, this.WithdrawPos,
+		this.WithdrawFreezePos, this.WithdrawUnfreezePos} {
+		if err := serialization.WriteUint64(w, v); err != nil {
+			return errors.NewDetailErr(err, errors.ErrNoCode, "serialization.WriteUint64, serialize pos error!")
+		}
+	}
+	if err := serialization.WriteUint32(w, this.UnlockHeight); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "serialization.WriteUint32, serialize unlockHeight error!")
+	}
+	return nil
+}
+
+func (this *PeerStake) Deserialize(r io.Reader) error {
+	peerPubkey, err := serialization.ReadString(r)
+	if err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "serialization.ReadString, deserialize peerPubkey error!")
+	}
+	this.PeerPubkey = peerPubkey
+	for _, v := range []*uint64{&this.InitPos, &this.ConsensusPos, &this.FreezePos, &this.NewPos, &this.WithdrawPos,
+		&this.WithdrawFreezePos, &this.WithdrawUnfreezePos} {
+		if *v, err = serialization.ReadUint64(r); err != nil {
+			return errors.NewDetailErr(err, errors.ErrNoCode, "serialization.ReadUint64, deserialize pos error!")
+		}
+	}
+	if this.UnlockHeight, err = serialization.ReadUint32(r); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "serialization.ReadUint32, deserialize unlockHeight error!")
+	}
+	return nil
+}
+
+// StakeInfo is the stake position of an address in the governance contract.
+// TotalStake is the zpt deposited, UnboundGala is the gala withdrawable by
+// withdrawGala, Pending and Withdrawable are the zpt waiting for unlock and
+// withdrawable by withdraw over all peers.
+type StakeInfo struct {
+	Address        common.Address
+	View           uint32
+	NextViewHeight uint32
+	TotalStake     uint64
+	UnboundGala    uint64
+	Pending        uint64
+	Withdrawable   uint64
+	Peers          []*PeerStake
+}
+
+func (this *StakeInfo) Serialize(w io.Writer) error {
+	if err := this.Address.Serialize(w); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "address.Serialize, serialize address error!")
+	}
+	if err := serialization.WriteUint32(w, this.View); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "serialization.WriteUint32, serialize view error!")
+	}
+	if err := serialization.WriteUint32(w, this.NextViewHeight); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "serialization.WriteUint32, serialize nextViewHeight error!")
+	}
+	for _, v := range []uint64{this.TotalStake, this.UnboundGala, this.Pending, this.Withdrawable} {
+		if err := serialization.WriteUint64(w, v); err != nil {
+			return errors.NewDetailErr(err, errors.ErrNoCode, "serialization.WriteUint64, serialize amount error!")
+		}
+	}
+	if err := serialization.WriteUint32(w, uint32(len(this.Peers))); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "serialization.WriteUint32, serialize peers length error!")
+	}
+	for _, v := range this.Peers {
+		if err := v.Serialize(w); err != nil {
+			return errors.NewDetailErr(err, errors.ErrNoCode, "peerStake.Serialize, serialize peer stake error!")
+		}
+	}
+	return nil
+}
+
+func (this *StakeInfo) Deserialize(r io.Reader) error {
+	if err := this.Address.Deserialize(r); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "address.Deserialize, deserialize address error!")
+	}
+	var err error
+	if this.View, err = serialization.ReadUint32(r); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "serialization.ReadUint32, deserialize view error!")
+	}
+	if this.NextViewHeight, err = serialization.ReadUint32(r); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "serialization.ReadUint32, deserialize nextViewHeight error!")
+	}
+	for _, v := range []*uint64{&this.TotalStake, &this.UnboundGala, &this.Pending, &this.Withdrawable} {
+		if *v, err = serialization.ReadUint64(r); err != nil {
+			return errors.NewDetailErr(err, errors.ErrNoCode, "serialization.ReadUint64, deserialize amount error!")
+		}
+	}
+	n, err := serialization.ReadUint32(r)
+	if err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "serialization.ReadUint32, deserialize peers length error!")
+	}
+	this.Peers = make([]*PeerStake, 0)
+	for i := uint32(0); i < n; i++ {
+		peerStake := new(PeerStake)
+		if err := peerStake.Deserialize(r); err != nil {
+			return errors.NewDetailErr(err, errors.ErrNoCode, "peerStake.Deserialize, deserialize peer stake error!")
+		}
+		this.Peers = append(this.Peers, peerStake)
+	}
+	return nil
+}
+
+// SplitPayout is the gala paid to an address by the fee split of a view
+type SplitPayout struct {
+	View   uint32
+	Amount uint64
+}
+
+type SplitPayoutList struct {
+	Payouts []*SplitPayout
+}
+
+func (this *SplitPayoutList) Serialize(w io.Writer) error {
+	if err := serialization.WriteUint32(w, uint32(len(this.Payouts))); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "serialization.WriteUint32, serialize payouts length error!")
+	}
+	for _, v := range this.Payouts {
+		if err := serialization.WriteUint32(w, v.View); err != nil {
+			return errors.NewDetailErr(err, errors.ErrNoCode, "serialization.WriteUint32, serialize view error!")
+		}
+		if err := serialization.WriteUint64(w, v.Amount); err != nil {
+			return errors.NewDetailErr(err, errors.ErrNoCode, "serialization.WriteUint64, serialize amount error!")
+		}
+	}
+	return nil
+}
+
+func (this *SplitPayoutList) Deserialize(r io.Reader) error {
+	n, err := serialization.ReadUint32(r)
+	if err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "serialization.ReadUint32, deserialize payouts length error!")
+	}
+	this.Payouts = make([]*SplitPayout, 0)
+	for i := uint32(0); i < n; i++ {
+		payout := new(SplitPayout)
+		if payout.View, err = serialization.ReadUint32(r); err != nil {
+			return errors.NewDetailErr(err, errors.ErrNoCode, "serialization.ReadUint32, deserialize view error!")
+		}
+		if payout.Amount, err = serialization.ReadUint64(r); err != nil {
+			return errors.NewDetailErr(err, errors.ErrNoCode, "serialization.ReadUint64, deserialize amount error!")
+		}
+		this.Payouts = append(this.Payouts, payout)
+	}
+	return nil
+}
@@ -43,12 +43,17 @@ import (
 	invoke "github.com/imZhuFei/zeepin/core/utils"
 	vm "github.com/imZhuFei/zeepin/embed/simulator"
 	"github.com/imZhuFei/zeepin/smartcontract/service/native/auth"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/claim"
 	"github.com/imZhuFei/zeepin/smartcontract/service/native/embed"
 	"github.com/imZhuFei/zeepin/smartcontract/service/native/gala"
 	"github.com/imZhuFei/zeepin/smartcontract/service/native/gid"
 	params "github.com/imZhuFei/zeepin/smartcontract/service/native/global_params"
 	"github.com/imZhuFei/zeepin/smartcontract/service/native/governance"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/multisig"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/nft"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/token"
 	"github.com/imZhuFei/zeepin/smartcontract/service/native/utils"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/vesting"
 	"github.com/imZhuFei/zeepin/smartcontract/service/native/zpt"
 )
 
@@ -63,6 +68,11 @@ func init() {
 	gid.Init()
 	auth.Init()
 	governance.InitGovernance()
+	token.InitToken()
+	nft.InitNFT()
+	claim.InitClaim()
+	vesting.InitVesting()
+	multisig.InitMultisig()
 }
 
 func InitBytes(addr common.Address, method string) []byte {
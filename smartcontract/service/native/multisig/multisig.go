@@ -0,0 +1,260 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+// Package multisig is a native multi-signature wallet contract. The owners and
+// the threshold of a wallet are stored on chain, owners submit and confirm
+// transfer or invoke proposals by transaction, and a proposal is executed in
+// the name of the wallet address once threshold owners confirmed it.
+package multisig
+
+import (
+	"bytes"
+	"math/big"
+
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/embed/simulator/types"
+	"github.com/imZhuFei/zeepin/errors"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/utils"
+)
+
+func InitMultisig() {
+	native.Contracts[utils.MultisigContractAddress] = RegisterMultisigContract
+}
+
+func RegisterMultisigContract(native *native.NativeService) {
+	if !utils.IsNativeContractHeight(native.Height) {
+		return
+	}
+	native.Register(CREATE_WALLET_NAME, CreateWallet)
+	native.Register(CHANGE_OWNERS_NAME, ChangeOwners)
+	native.Register(SUBMIT_PROPOSAL_NAME, SubmitProposal)
+	native.Register(CONFIRM_PROPOSAL_NAME, ConfirmProposal)
+	native.Register(REVOKE_CONFIRMATION_NAME, RevokeConfirmation)
+	native.Register(GET_WALLET_NAME, GetWallet)
+	native.Register(GET_PROPOSAL_NAME, GetProposal)
+}
+
+// CreateWallet creates a wallet and returns its id, the address of the wallet
+// is queried by getWallet
+func CreateWallet(native *native.NativeService) ([]byte, error) {
+	param := new(CreateWalletParam)
+	if err := param.Deserialize(bytes.NewBuffer(native.Input)); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[CreateWallet] param deserialize error!")
+	}
+	if err := checkOwners(param.Owners, param.Threshold); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[CreateWallet] invalid owners!")
+	}
+	if err := utils.ValidateOwner(native, param.Creator); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[CreateWallet] check witness error!")
+	}
+	contract := native.ContextRef.CurrentContext().ContractAddress
+	walletID, err := nextID(native, utils.ConcatKey(contract, []byte(WALLET_COUNT)))
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[CreateWallet] get wallet count error!")
+	}
+	wallet := &Wallet{
+		Address:   genWalletAddress(contract, walletID),
+		Owners:    param.Owners,
+		Threshold: param.Threshold,
+	}
+	if err := putWallet(native, contract, walletID, wallet); err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	addCreateWalletNotification(native, contract, walletID, wallet)
+	return types.BigIntToBytes(new(big.Int).SetUint64(walletID)), nil
+}
+
+// ChangeOwners replaces the owners and the threshold of a wallet, should be
+// witnessed by the wallet address, that is called by an invoke proposal of the
+// wallet
+func ChangeOwners(native *native.NativeService) ([]byte, error) {
+	param := new(ChangeOwnersParam)
+	if err := param.Deserialize(bytes.NewBuffer(native.Input)); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[ChangeOwners] param deserialize error!")
+	}
+	if err := checkOwners(param.Owners, param.Threshold); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[ChangeOwners] invalid owners!")
+	}
+	contract := native.ContextRef.CurrentContext().ContractAddress
+	wallet, err := getWallet(native, contract, param.WalletID)
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	if err := utils.ValidateOwner(native, wallet.Address); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[ChangeOwners] check witness error!")
+	}
+	wallet.Owners = param.Owners
+	wallet.Threshold = param.Threshold
+	if err := putWallet(native, contract, param.WalletID, wallet); err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	addChangeOwnersNotification(native, contract, param.WalletID, wallet)
+	return utils.BYTE_TRUE, nil
+}
+
+// SubmitProposal submits a proposal confirmed by the proposer and returns its
+// id, the proposal is executed at once if the threshold is 1
+func SubmitProposal(native *native.NativeService) ([]byte, error) {
+	param := new(SubmitParam)
+	if err := param.Deserialize(bytes.NewBuffer(native.Input)); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[SubmitProposal] param deserialize error!")
+	}
+	if err := checkSubmitParam(param); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[SubmitProposal] invalid param!")
+	}
+	contract := native.ContextRef.CurrentContext().ContractAddress
+	wallet, err := getWallet(native, contract, param.WalletID)
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	if !isOwner(wallet, param.Proposer) {
+		return utils.BYTE_FALSE, errors.NewErr("[SubmitProposal] proposer is not an owner of wallet!")
+	}
+	if err := utils.ValidateOwner(native, param.Proposer); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[SubmitProposal] check witness error!")
+	}
+	proposalID, err := nextID(native, utils.ConcatKey(contract, []byte(PROPOSAL_COUNT)))
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[SubmitProposal] get proposal count error!")
+	}
+	proposal := &Proposal{
+		WalletID:      param.WalletID,
+		Proposer:      param.Proposer,
+		Kind:          param.Kind,
+		Contract:      param.Contract,
+		Method:        param.Method,
+		Args:          param.Args,
+		To:            param.To,
+		Amount:        param.Amount,
+		Confirmations: []common.Address{param.Proposer},
+	}
+	addSubmitNotification(native, contract, proposalID, proposal)
+	if err := executeIfApproved(native, contract, proposalID, wallet, proposal); err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	return types.BigIntToBytes(new(big.Int).SetUint64(proposalID)), nil
+}
+
+// ConfirmProposal confirms a proposal by an owner, the proposal is executed
+// once confirmed by threshold owners
+func ConfirmProposal(native *native.NativeService) ([]byte, error) {
+	param := new(ConfirmParam)
+	if err := param.Deserialize(bytes.NewBuffer(native.Input)); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[ConfirmProposal] param deserialize error!")
+	}
+	contract := native.ContextRef.CurrentContext().ContractAddress
+	wallet, proposal, err := getPendingProposal(native, contract, param)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[ConfirmProposal] check proposal error!")
+	}
+	for _, addr := range proposal.Confirmations {
+		if addr == param.Owner {
+			return utils.BYTE_FALSE, errors.NewErr("[ConfirmProposal] proposal is already confirmed by owner!")
+		}
+	}
+	proposal.Confirmations = append(proposal.Confirmations, param.Owner)
+	addConfirmNotification(native, contract, CONFIRM_PROPOSAL_NAME, param.ProposalID, param.Owner)
+	if err := executeIfApproved(native, contract, param.ProposalID, wallet, proposal); err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	return utils.BYTE_TRUE, nil
+}
+
+// RevokeConfirmation withdraws the confirmation of an owner from a proposal
+// not executed yet
+func RevokeConfirmation(native *native.NativeService) ([]byte, error) {
+	param := new(ConfirmParam)
+	if err := param.Deserialize(bytes.NewBuffer(native.Input)); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[RevokeConfirmation] param deserialize error!")
+	}
+	contract := native.ContextRef.CurrentContext().ContractAddress
+	_, proposal, err := getPendingProposal(native, contract, param)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[RevokeConfirmation] check proposal error!")
+	}
+	confirmations := make([]common.Address, 0, len(proposal.Confirmations))
+	for _, addr := range proposal.Confirmations {
+		if addr != param.Owner {
+			confirmations = append(confirmations, addr)
+		}
+	}
+	if len(confirmations) == len(proposal.Confirmations) {
+		return utils.BYTE_FALSE, errors.NewErr("[RevokeConfirmation] proposal is not confirmed by owner!")
+	}
+	proposal.Confirmations = confirmations
+	if err := putProposal(native, contract, param.ProposalID, proposal); err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	addConfirmNotification(native, contract, REVOKE_CONFIRMATION_NAME, param.ProposalID, param.Owner)
+	return utils.BYTE_TRUE, nil
+}
+
+func GetWallet(native *native.NativeService) ([]byte, error) {
+	walletID, err := utils.ReadVarUint(bytes.NewBuffer(native.Input))
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[GetWallet] get wallet id error!")
+	}
+	wallet, err := getWallet(native, native.ContextRef.CurrentContext().ContractAddress, walletID)
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	bf := new(bytes.Buffer)
+	if err := wallet.Serialize(bf); err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	return bf.Bytes(), nil
+}
+
+func GetProposal(native *native.NativeService) ([]byte, error) {
+	proposalID, err := utils.ReadVarUint(bytes.NewBuffer(native.Input))
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[GetProposal] get proposal id error!")
+	}
+	proposal, err := getProposal(native, native.ContextRef.CurrentContext().ContractAddress, proposalID)
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	bf := new(bytes.Buffer)
+	if err := proposal.Serialize(bf); err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	return bf.Bytes(), nil
+}
+
+// getPendingProposal returns a proposal not executed and its wallet, the owner
+// of param should be a witnessed owner of the wallet
+func getPendingProposal(native *native.NativeService, contract common.Address, param *ConfirmParam) (*Wallet, *Proposal, error) {
+	proposal, err := getProposal(native, contract, param.ProposalID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if proposal.Executed {
+		return nil, nil, errors.NewErr("proposal is already executed")
+	}
+	wallet, err := getWallet(native, contract, proposal.WalletID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !isOwner(wallet, param.Owner) {
+		return nil, nil, errors.NewErr("address is not an owner of wallet")
+	}
+	if err := utils.ValidateOwner(native, param.Owner); err != nil {
+		return nil, nil, err
+	}
+	return wallet, proposal, nil
+}
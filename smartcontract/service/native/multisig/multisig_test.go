@@ -0,0 +1,187 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package multisig
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/common/config"
+	scommon "github.com/imZhuFei/zeepin/core/store/common"
+	"github.com/imZhuFei/zeepin/embed/simulator/types"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/gala"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/testutil"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/utils"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/zpt"
+	"github.com/stretchr/testify/assert"
+)
+
+// invoke calls a handler of multisig contract signed by signer
+func invoke(t *testing.T, srvc *native.NativeService, ctx *testutil.Context, signer common.Address, handler native.Handler, param interface {
+	Serialize(w io.Writer) error
+}) ([]byte, error) {
+	ctx.Reset(utils.MultisigContractAddress)
+	ctx.Sign(signer)
+	return testutil.Invoke(t, srvc, handler, param)
+}
+
+func galaBalance(t *testing.T, srvc *native.NativeService, addr common.Address) uint64 {
+	balance, err := utils.GetStorageUInt64(srvc, zpt.GenBalanceKey(utils.GalaContractAddress, addr))
+	assert.Nil(t, err)
+	return balance
+}
+
+func queryWallet(t *testing.T, srvc *native.NativeService, walletID uint64) *Wallet {
+	bf := new(bytes.Buffer)
+	assert.Nil(t, utils.WriteVarUint(bf, walletID))
+	srvc.Input = bf.Bytes()
+	ret, err := GetWallet(srvc)
+	assert.Nil(t, err)
+	wallet := new(Wallet)
+	assert.Nil(t, wallet.Deserialize(bytes.NewBuffer(ret)))
+	return wallet
+}
+
+func TestMultisig(t *testing.T) {
+	gala.InitGala()
+	InitMultisig()
+	networkId := config.DefConfig.P2PNode.NetworkId
+	defer func() { config.DefConfig.P2PNode.NetworkId = networkId }()
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_SOLO_NET
+
+	owner1, owner2, owner3, other := common.Address{1}, common.Address{2}, common.Address{3}, common.Address{4}
+	ctx := testutil.NewContext()
+	srvc, closeStore := testutil.NewNativeService(t, ctx)
+	defer closeStore()
+	config.DefConfig.Common.EnableEventLog = true
+
+	_, err := invoke(t, srvc, ctx, owner1, CreateWallet, &CreateWalletParam{Creator: owner1, Owners: []common.Address{owner1, owner1}, Threshold: 1})
+	assert.NotNil(t, err)
+	_, err = invoke(t, srvc, ctx, owner1, CreateWallet, &CreateWalletParam{Creator: owner1, Owners: []common.Address{owner1}, Threshold: 2})
+	assert.NotNil(t, err)
+	ret, err := invoke(t, srvc, ctx, owner1, CreateWallet, &CreateWalletParam{
+		Creator:   owner1,
+		Owners:    []common.Address{owner1, owner2, owner3},
+		Threshold: 2,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), types.BigIntFromBytes(ret).Int64())
+	wallet := queryWallet(t, srvc, 1)
+	assert.Equal(t, genWalletAddress(utils.MultisigContractAddress, 1), wallet.Address)
+	srvc.CloneCache.Add(scommon.ST_STORAGE, zpt.GenBalanceKey(utils.GalaContractAddress, wallet.Address), utils.GenUInt64StorageItem(1000))
+
+	transfer := &SubmitParam{WalletID: 1, Proposer: owner1, Kind: PROPOSAL_TRANSFER, Contract: utils.GalaContractAddress, To: other, Amount: 300}
+	_, err = invoke(t, srvc, ctx, other, SubmitProposal, &SubmitParam{WalletID: 1, Proposer: other, Kind: PROPOSAL_TRANSFER, Contract: utils.GalaContractAddress, To: other, Amount: 300})
+	assert.NotNil(t, err)
+	ret, err = invoke(t, srvc, ctx, owner1, SubmitProposal, transfer)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), types.BigIntFromBytes(ret).Int64())
+	assert.Equal(t, uint64(0), galaBalance(t, srvc, other))
+
+	_, err = invoke(t, srvc, ctx, owner1, ConfirmProposal, &ConfirmParam{ProposalID: 1, Owner: owner2})
+	assert.NotNil(t, err)
+	_, err = invoke(t, srvc, ctx, owner1, ConfirmProposal, &ConfirmParam{ProposalID: 1, Owner: owner1})
+	assert.NotNil(t, err)
+	_, err = invoke(t, srvc, ctx, other, ConfirmProposal, &ConfirmParam{ProposalID: 1, Owner: other})
+	assert.NotNil(t, err)
+	_, err = invoke(t, srvc, ctx, owner2, ConfirmProposal, &ConfirmParam{ProposalID: 1, Owner: owner2})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(300), galaBalance(t, srvc, other))
+	assert.Equal(t, uint64(700), galaBalance(t, srvc, wallet.Address))
+	_, err = invoke(t, srvc, ctx, owner3, ConfirmProposal, &ConfirmParam{ProposalID: 1, Owner: owner3})
+	assert.NotNil(t, err)
+
+	// owners and threshold are changed by a proposal of the wallet only
+	change := &ChangeOwnersParam{WalletID: 1, Owners: []common.Address{owner1, owner2}, Threshold: 1}
+	_, err = invoke(t, srvc, ctx, owner1, ChangeOwners, change)
+	assert.NotNil(t, err)
+	args := new(bytes.Buffer)
+	assert.Nil(t, change.Serialize(args))
+	invokeChange := &SubmitParam{
+		WalletID: 1,
+		Proposer: owner1,
+		Kind:     PROPOSAL_INVOKE,
+		Contract: utils.MultisigContractAddress,
+		Method:   CHANGE_OWNERS_NAME,
+		Args:     args.Bytes(),
+	}
+	ret, err = invoke(t, srvc, ctx, owner1, SubmitProposal, invokeChange)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), types.BigIntFromBytes(ret).Int64())
+	_, err = invoke(t, srvc, ctx, owner1, RevokeConfirmation, &ConfirmParam{ProposalID: 2, Owner: owner1})
+	assert.Nil(t, err)
+	_, err = invoke(t, srvc, ctx, owner1, RevokeConfirmation, &ConfirmParam{ProposalID: 2, Owner: owner1})
+	assert.NotNil(t, err)
+	_, err = invoke(t, srvc, ctx, owner3, ConfirmProposal, &ConfirmParam{ProposalID: 2, Owner: owner3})
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(2), queryWallet(t, srvc, 1).Threshold)
+	_, err = invoke(t, srvc, ctx, owner2, ConfirmProposal, &ConfirmParam{ProposalID: 2, Owner: owner2})
+	assert.Nil(t, err)
+	wallet = queryWallet(t, srvc, 1)
+	assert.Equal(t, []common.Address{owner1, owner2}, wallet.Owners)
+	assert.Equal(t, uint32(1), wallet.Threshold)
+
+	// removed owner can not propose, threshold 1 executes at once
+	transfer.Proposer = owner3
+	_, err = invoke(t, srvc, ctx, owner3, SubmitProposal, transfer)
+	assert.NotNil(t, err)
+	transfer.Proposer = owner2
+	transfer.Amount = 100
+	_, err = invoke(t, srvc, ctx, owner2, SubmitProposal, transfer)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(400), galaBalance(t, srvc, other))
+
+	// the wallet can not spend more than its balance
+	transfer.Amount = 1000
+	_, err = invoke(t, srvc, ctx, owner2, SubmitProposal, transfer)
+	assert.NotNil(t, err)
+}
+
+func TestCheckSubmitParam(t *testing.T) {
+	InitMultisig()
+	param := &SubmitParam{Kind: PROPOSAL_TRANSFER, Contract: utils.ZptContractAddress, To: common.Address{1}, Amount: 1}
+	assert.Nil(t, checkSubmitParam(param))
+	param.Contract = utils.NFTContractAddress
+	assert.NotNil(t, checkSubmitParam(param))
+	param.Kind = PROPOSAL_INVOKE
+	param.Contract = utils.MultisigContractAddress
+	assert.NotNil(t, checkSubmitParam(param))
+	param.Method = CHANGE_OWNERS_NAME
+	assert.Nil(t, checkSubmitParam(param))
+	param.Contract = common.Address{1}
+	assert.NotNil(t, checkSubmitParam(param))
+	param.Kind = 2
+	assert.NotNil(t, checkSubmitParam(param))
+}
+
+func TestRegisterMultisigContract(t *testing.T) {
+	networkId := config.DefConfig.P2PNode.NetworkId
+	defer func() { config.DefConfig.P2PNode.NetworkId = networkId }()
+
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_MAIN_NET
+	srvc := &native.NativeService{ServiceMap: make(map[string]native.Handler), Height: 10}
+	RegisterMultisigContract(srvc)
+	assert.Empty(t, srvc.ServiceMap)
+
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_SOLO_NET
+	RegisterMultisigContract(srvc)
+	assert.Contains(t, srvc.ServiceMap, CREATE_WALLET_NAME)
+}
@@ -0,0 +1,340 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package multisig
+
+import (
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/common/serialization"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/utils"
+)
+
+// Wallet is a multi-signature wallet, proposals of the wallet are executed in
+// the name of Address once Threshold of Owners confirmed them
+type Wallet struct {
+	Address   common.Address
+	Owners    []common.Address
+	Threshold uint32
+}
+
+func (this *Wallet) Serialize(w io.Writer) error {
+	if err := utils.WriteAddress(w, this.Address); err != nil {
+		return fmt.Errorf("[Wallet] serialize address error:%v", err)
+	}
+	if err := writeOwners(w, this.Owners, this.Threshold); err != nil {
+		return fmt.Errorf("[Wallet] %v", err)
+	}
+	return nil
+}
+
+func (this *Wallet) Deserialize(r io.Reader) error {
+	var err error
+	if this.Address, err = utils.ReadAddress(r); err != nil {
+		return fmt.Errorf("[Wallet] deserialize address error:%v", err)
+	}
+	if this.Owners, this.Threshold, err = readOwners(r); err != nil {
+		return fmt.Errorf("[Wallet] %v", err)
+	}
+	return nil
+}
+
+// CreateWalletParam creates a wallet of Owners, Creator pays for it
+type CreateWalletParam struct {
+	Creator   common.Address
+	Owners    []common.Address
+	Threshold uint32
+}
+
+func (this *CreateWalletParam) Serialize(w io.Writer) error {
+	if err := utils.WriteAddress(w, this.Creator); err != nil {
+		return fmt.Errorf("[CreateWalletParam] serialize creator error:%v", err)
+	}
+	if err := writeOwners(w, this.Owners, this.Threshold); err != nil {
+		return fmt.Errorf("[CreateWalletParam] %v", err)
+	}
+	return nil
+}
+
+func (this *CreateWalletParam) Deserialize(r io.Reader) error {
+	var err error
+	if this.Creator, err = utils.ReadAddress(r); err != nil {
+		return fmt.Errorf("[CreateWalletParam] deserialize creator error:%v", err)
+	}
+	if this.Owners, this.Threshold, err = readOwners(r); err != nil {
+		return fmt.Errorf("[CreateWalletParam] %v", err)
+	}
+	return nil
+}
+
+// ChangeOwnersParam replaces the owners and the threshold of a wallet, it is
+// only invoked by a proposal of the wallet itself
+type ChangeOwnersParam struct {
+	WalletID  uint64
+	Owners    []common.Address
+	Threshold uint32
+}
+
+func (this *ChangeOwnersParam) Serialize(w io.Writer) error {
+	if err := utils.WriteVarUint(w, this.WalletID); err != nil {
+		return fmt.Errorf("[ChangeOwnersParam] serialize wallet id error:%v", err)
+	}
+	if err := writeOwners(w, this.Owners, this.Threshold); err != nil {
+		return fmt.Errorf("[ChangeOwnersParam] %v", err)
+	}
+	return nil
+}
+
+func (this *ChangeOwnersParam) Deserialize(r io.Reader) error {
+	var err error
+	if this.WalletID, err = utils.ReadVarUint(r); err != nil {
+		return fmt.Errorf("[ChangeOwnersParam] deserialize wallet id error:%v", err)
+	}
+	if this.Owners, this.Threshold, err = readOwners(r); err != nil {
+		return fmt.Errorf("[ChangeOwnersParam] %v", err)
+	}
+	return nil
+}
+
+// SubmitParam submits a proposal of a wallet. A transfer proposal moves
+// Amount of the zpt or gala contract Contract to To, an invoke proposal calls
+// Method of the native contract Contract with Args.
+type SubmitParam struct {
+	WalletID uint64
+	Proposer common.Address
+	Kind     byte
+	Contract common.Address
+	Method   string
+	Args     []byte
+	To       common.Address
+	Amount   uint64
+}
+
+func (this *SubmitParam) Serialize(w io.Writer) error {
+	if err := utils.WriteVarUint(w, this.WalletID); err != nil {
+		return fmt.Errorf("[SubmitParam] serialize wallet id error:%v", err)
+	}
+	if err := utils.WriteAddress(w, this.Proposer); err != nil {
+		return fmt.Errorf("[SubmitParam] serialize proposer error:%v", err)
+	}
+	if err := writeAction(w, this.Kind, this.Contract, this.Method, this.Args, this.To, this.Amount); err != nil {
+		return fmt.Errorf("[SubmitParam] %v", err)
+	}
+	return nil
+}
+
+func (this *SubmitParam) Deserialize(r io.Reader) error {
+	var err error
+	if this.WalletID, err = utils.ReadVarUint(r); err != nil {
+		return fmt.Errorf("[SubmitParam] deserialize wallet id error:%v", err)
+	}
+	if this.Proposer, err = utils.ReadAddress(r); err != nil {
+		return fmt.Errorf("[SubmitParam] deserialize proposer error:%v", err)
+	}
+	if this.Kind, this.Contract, this.Method, this.Args, this.To, this.Amount, err = readAction(r); err != nil {
+		return fmt.Errorf("[SubmitParam] %v", err)
+	}
+	return nil
+}
+
+// Proposal is a proposal of a wallet with the owners confirmed it
+type Proposal struct {
+	WalletID      uint64
+	Proposer      common.Address
+	Kind          byte
+	Contract      common.Address
+	Method        string
+	Args          []byte
+	To            common.Address
+	Amount        uint64
+	Confirmations []common.Address
+	Executed      bool
+}
+
+func (this *Proposal) Serialize(w io.Writer) error {
+	if err := utils.WriteVarUint(w, this.WalletID); err != nil {
+		return fmt.Errorf("[Proposal] serialize wallet id error:%v", err)
+	}
+	if err := utils.WriteAddress(w, this.Proposer); err != nil {
+		return fmt.Errorf("[Proposal] serialize proposer error:%v", err)
+	}
+	if err := writeAction(w, this.Kind, this.Contract, this.Method, this.Args, this.To, this.Amount); err != nil {
+		return fmt.Errorf("[Proposal] %v", err)
+	}
+	if err := writeAddresses(w, this.Confirmations); err != nil {
+		return fmt.Errorf("[Proposal] serialize confirmations error:%v", err)
+	}
+	if err := serialization.WriteBool(w, this.Executed); err != nil {
+		return fmt.Errorf("[Proposal] serialize executed error:%v", err)
+	}
+	return nil
+}
+
+func (this *Proposal) Deserialize(r io.Reader) error {
+	var err error
+	if this.WalletID, err = utils.ReadVarUint(r); err != nil {
+		return fmt.Errorf("[Proposal] deserialize wallet id error:%v", err)
+	}
+	if this.Proposer, err = utils.ReadAddress(r); err != nil {
+		return fmt.Errorf("[Proposal] deserialize proposer error:%v", err)
+	}
+	if this.Kind, this.Contract, this.Method, this.Args, this.To, this.Amount, err = readAction(r); err != nil {
+		return fmt.Errorf("[Proposal] %v", err)
+	}
+	if this.Confirmations, err = readAddresses(r); err != nil {
+		return fmt.Errorf("[Proposal] deserialize confirmations error:%v", err)
+	}
+	if this.Executed, err = serialization.ReadBool(r); err != nil {
+		return fmt.Errorf("[Proposal] deserialize executed error:%v", err)
+	}
+	return nil
+}
+
+// ConfirmParam confirms a proposal, or revokes the confirmation, by Owner
+type ConfirmParam struct {
+	ProposalID uint64
+	Owner      common.Address
+}
+
+func (this *ConfirmParam) Serialize(w io.Writer) error {
+	if err := utils.WriteVarUint(w, this.ProposalID); err != nil {
+		return fmt.Errorf("[ConfirmParam] serialize proposal id error:%v", err)
+	}
+	if err := utils.WriteAddress(w, this.Owner); err != nil {
+		return fmt.Errorf("[ConfirmParam] serialize owner error:%v", err)
+	}
+	return nil
+}
+
+func (this *ConfirmParam) Deserialize(r io.Reader) error {
+	var err error
+	if this.ProposalID, err = utils.ReadVarUint(r); err != nil {
+		return fmt.Errorf("[ConfirmParam] deserialize proposal id error:%v", err)
+	}
+	if this.Owner, err = utils.ReadAddress(r); err != nil {
+		return fmt.Errorf("[ConfirmParam] deserialize owner error:%v", err)
+	}
+	return nil
+}
+
+func writeAddresses(w io.Writer, addrs []common.Address) error {
+	if err := utils.WriteVarUint(w, uint64(len(addrs))); err != nil {
+		return err
+	}
+	for _, addr := range addrs {
+		if err := utils.WriteAddress(w, addr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readAddresses(r io.Reader) ([]common.Address, error) {
+	n, err := utils.ReadVarUint(r)
+	if err != nil {
+		return nil, err
+	}
+	if n > MAX_OWNERS {
+		return nil, fmt.Errorf("address count %d over max %d", n, MAX_OWNERS)
+	}
+	addrs := make([]common.Address, 0, n)
+	for i := uint64(0); i < n; i++ {
+		addr, err := utils.ReadAddress(r)
+		if err != nil {
+			return nil, err
+		}
+		addrs = append(addrs, addr)
+	}
+	return addrs, nil
+}
+
+func writeOwners(w io.Writer, owners []common.Address, threshold uint32) error {
+	if err := writeAddresses(w, owners); err != nil {
+		return fmt.Errorf("serialize owners error:%v", err)
+	}
+	if err := utils.WriteVarUint(w, uint64(threshold)); err != nil {
+		return fmt.Errorf("serialize threshold error:%v", err)
+	}
+	return nil
+}
+
+func readOwners(r io.Reader) ([]common.Address, uint32, error) {
+	owners, err := readAddresses(r)
+	if err != nil {
+		return nil, 0, fmt.Errorf("deserialize owners error:%v", err)
+	}
+	threshold, err := utils.ReadVarUint(r)
+	if err != nil {
+		return nil, 0, fmt.Errorf("deserialize threshold error:%v", err)
+	}
+	if threshold > math.MaxUint32 {
+		return nil, 0, fmt.Errorf("threshold %d over max uint32", threshold)
+	}
+	return owners, uint32(threshold), nil
+}
+
+func writeAction(w io.Writer, kind byte, contract common.Address, method string, args []byte, to common.Address, amount uint64) error {
+	if err := serialization.WriteByte(w, kind); err != nil {
+		return fmt.Errorf("serialize kind error:%v", err)
+	}
+	if err := utils.WriteAddress(w, contract); err != nil {
+		return fmt.Errorf("serialize contract error:%v", err)
+	}
+	if err := serialization.WriteString(w, method); err != nil {
+		return fmt.Errorf("serialize method error:%v", err)
+	}
+	if err := serialization.WriteVarBytes(w, args); err != nil {
+		return fmt.Errorf("serialize args error:%v", err)
+	}
+	if err := utils.WriteAddress(w, to); err != nil {
+		return fmt.Errorf("serialize to error:%v", err)
+	}
+	if err := utils.WriteVarUint(w, amount); err != nil {
+		return fmt.Errorf("serialize amount error:%v", err)
+	}
+	return nil
+}
+
+func readAction(r io.Reader) (kind byte, contract common.Address, method string, args []byte, to common.Address, amount uint64, err error) {
+	if kind, err = serialization.ReadByte(r); err != nil {
+		err = fmt.Errorf("deserialize kind error:%v", err)
+		return
+	}
+	if contract, err = utils.ReadAddress(r); err != nil {
+		err = fmt.Errorf("deserialize contract error:%v", err)
+		return
+	}
+	if method, err = serialization.ReadString(r); err != nil {
+		err = fmt.Errorf("deserialize method error:%v", err)
+		return
+	}
+	if args, err = serialization.ReadVarBytes(r); err != nil {
+		err = fmt.Errorf("deserialize args error:%v", err)
+		return
+	}
+	if to, err = utils.ReadAddress(r); err != nil {
+		err = fmt.Errorf("deserialize to error:%v", err)
+		return
+	}
+	if amount, err = utils.ReadVarUint(r); err != nil {
+		err = fmt.Errorf("deserialize amount error:%v", err)
+	}
+	return
+}
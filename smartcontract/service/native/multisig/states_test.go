@@ -0,0 +1,57 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package multisig
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWallet_Serialize(t *testing.T) {
+	wallet := &Wallet{
+		Address:   common.Address{9},
+		Owners:    []common.Address{{1}, {2}, {3}},
+		Threshold: 2,
+	}
+	bf := new(bytes.Buffer)
+	assert.Nil(t, wallet.Serialize(bf))
+	wallet2 := new(Wallet)
+	assert.Nil(t, wallet2.Deserialize(bf))
+	assert.Equal(t, wallet, wallet2)
+}
+
+func TestProposal_Serialize(t *testing.T) {
+	proposal := &Proposal{
+		WalletID:      1,
+		Proposer:      common.Address{1},
+		Kind:          PROPOSAL_INVOKE,
+		Contract:      common.Address{7},
+		Method:        "changeOwners",
+		Args:          []byte{1, 2, 3},
+		Confirmations: []common.Address{{1}, {2}},
+		Executed:      true,
+	}
+	bf := new(bytes.Buffer)
+	assert.Nil(t, proposal.Serialize(bf))
+	proposal2 := new(Proposal)
+	assert.Nil(t, proposal2.Deserialize(bf))
+	assert.Equal(t, proposal, proposal2)
+}
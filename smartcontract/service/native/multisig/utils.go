@@ -0,0 +1,275 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package multisig
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/common/config"
+	"github.com/imZhuFei/zeepin/common/serialization"
+	cstates "github.com/imZhuFei/zeepin/core/states"
+	scommon "github.com/imZhuFei/zeepin/core/store/common"
+	"github.com/imZhuFei/zeepin/errors"
+	"github.com/imZhuFei/zeepin/smartcontract/context"
+	"github.com/imZhuFei/zeepin/smartcontract/event"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/utils"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/zpt"
+)
+
+const (
+	CREATE_WALLET_NAME       = "createWallet"
+	CHANGE_OWNERS_NAME       = "changeOwners"
+	SUBMIT_PROPOSAL_NAME     = "submitProposal"
+	CONFIRM_PROPOSAL_NAME    = "confirmProposal"
+	REVOKE_CONFIRMATION_NAME = "revokeConfirmation"
+	GET_WALLET_NAME          = "getWallet"
+	GET_PROPOSAL_NAME        = "getProposal"
+	EXECUTE_PROPOSAL_EVENT   = "executeProposal"
+
+	//storage key prefix
+	WALLET_COUNT   = "walletCount"
+	WALLET         = "wallet"
+	PROPOSAL_COUNT = "proposalCount"
+	PROPOSAL       = "proposal"
+
+	//kind of proposal
+	PROPOSAL_TRANSFER = 0
+	PROPOSAL_INVOKE   = 1
+
+	MAX_OWNERS      = 32
+	MAX_METHOD_LEN  = 64
+	MAX_ARGS_LEN    = 1024 * 64
+	WALLET_ADDR_TAG = "multisig wallet"
+)
+
+func getIDBytes(id uint64) []byte {
+	bf := new(bytes.Buffer)
+	serialization.WriteUint64(bf, id)
+	return bf.Bytes()
+}
+
+func genWalletKey(contract common.Address, walletID uint64) []byte {
+	return utils.ConcatKey(contract, []byte(WALLET), getIDBytes(walletID))
+}
+
+func genProposalKey(contract common.Address, proposalID uint64) []byte {
+	return utils.ConcatKey(contract, []byte(PROPOSAL), getIDBytes(proposalID))
+}
+
+// genWalletAddress derives the address of a wallet. It is not the hash of any
+// program, so only the multisig contract can witness it.
+func genWalletAddress(contract common.Address, walletID uint64) common.Address {
+	hash := sha256.Sum256(utils.ConcatKey(contract, []byte(WALLET_ADDR_TAG), getIDBytes(walletID)))
+	var addr common.Address
+	copy(addr[:], hash[:common.ADDR_LEN])
+	return addr
+}
+
+// nextID increases the counter at key and returns the new value
+func nextID(native *native.NativeService, key []byte) (uint64, error) {
+	count, err := utils.GetStorageUInt64(native, key)
+	if err != nil {
+		return 0, err
+	}
+	native.CloneCache.Add(scommon.ST_STORAGE, key, utils.GenUInt64StorageItem(count+1))
+	return count + 1, nil
+}
+
+func checkOwners(owners []common.Address, threshold uint32) error {
+	if len(owners) == 0 {
+		return errors.NewErr("owners are empty")
+	}
+	if len(owners) > MAX_OWNERS {
+		return fmt.Errorf("owner count %d over max %d", len(owners), MAX_OWNERS)
+	}
+	if threshold == 0 || int(threshold) > len(owners) {
+		return fmt.Errorf("threshold %d out of range [1, %d]", threshold, len(owners))
+	}
+	exist := make(map[common.Address]bool, len(owners))
+	for _, owner := range owners {
+		if owner == common.ADDRESS_EMPTY {
+			return errors.NewErr("owner is empty")
+		}
+		if exist[owner] {
+			return fmt.Errorf("duplicated owner %s", owner.ToBase58())
+		}
+		exist[owner] = true
+	}
+	return nil
+}
+
+func checkSubmitParam(param *SubmitParam) error {
+	switch param.Kind {
+	case PROPOSAL_TRANSFER:
+		if param.Contract != utils.ZptContractAddress && param.Contract != utils.GalaContractAddress {
+			return fmt.Errorf("asset %s is not zpt or gala", param.Contract.ToHexString())
+		}
+		if param.To == common.ADDRESS_EMPTY {
+			return errors.NewErr("transfer to empty address")
+		}
+		if param.Amount == 0 {
+			return errors.NewErr("amount is zero")
+		}
+	case PROPOSAL_INVOKE:
+		if _, ok := native.Contracts[param.Contract]; !ok {
+			return fmt.Errorf("contract %s is not a native contract", param.Contract.ToHexString())
+		}
+		if len(param.Method) == 0 || len(param.Method) > MAX_METHOD_LEN {
+			return fmt.Errorf("method length out of range [1, %d]", MAX_METHOD_LEN)
+		}
+		if len(param.Args) > MAX_ARGS_LEN {
+			return fmt.Errorf("args length over max %d", MAX_ARGS_LEN)
+		}
+	default:
+		return fmt.Errorf("unknown proposal kind %d", param.Kind)
+	}
+	return nil
+}
+
+func isOwner(wallet *Wallet, addr common.Address) bool {
+	for _, owner := range wallet.Owners {
+		if owner == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// countConfirmations counts the confirmations of the current owners, the
+// confirmations of removed owners are ignored
+func countConfirmations(wallet *Wallet, proposal *Proposal) uint32 {
+	count := uint32(0)
+	for _, addr := range proposal.Confirmations {
+		if isOwner(wallet, addr) {
+			count++
+		}
+	}
+	return count
+}
+
+func getWallet(native *native.NativeService, contract common.Address, walletID uint64) (*Wallet, error) {
+	item, err := utils.GetStorageItem(native, genWalletKey(contract, walletID))
+	if err != nil {
+		return nil, errors.NewDetailErr(err, errors.ErrNoCode, "[getWallet] get wallet error!")
+	}
+	if item == nil {
+		return nil, fmt.Errorf("[getWallet] wallet %d not exist", walletID)
+	}
+	wallet := new(Wallet)
+	if err := wallet.Deserialize(bytes.NewBuffer(item.Value)); err != nil {
+		return nil, errors.NewDetailErr(err, errors.ErrNoCode, "[getWallet] deserialize wallet error!")
+	}
+	return wallet, nil
+}
+
+func putWallet(native *native.NativeService, contract common.Address, walletID uint64, wallet *Wallet) error {
+	bf := new(bytes.Buffer)
+	if err := wallet.Serialize(bf); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "[putWallet] serialize wallet error!")
+	}
+	native.CloneCache.Add(scommon.ST_STORAGE, genWalletKey(contract, walletID), &cstates.StorageItem{Value: bf.Bytes()})
+	return nil
+}
+
+func getProposal(native *native.NativeService, contract common.Address, proposalID uint64) (*Proposal, error) {
+	item, err := utils.GetStorageItem(native, genProposalKey(contract, proposalID))
+	if err != nil {
+		return nil, errors.NewDetailErr(err, errors.ErrNoCode, "[getProposal] get proposal error!")
+	}
+	if item == nil {
+		return nil, fmt.Errorf("[getProposal] proposal %d not exist", proposalID)
+	}
+	proposal := new(Proposal)
+	if err := proposal.Deserialize(bytes.NewBuffer(item.Value)); err != nil {
+		return nil, errors.NewDetailErr(err, errors.ErrNoCode, "[getProposal] deserialize proposal error!")
+	}
+	return proposal, nil
+}
+
+func putProposal(native *native.NativeService, contract common.Address, proposalID uint64, proposal *Proposal) error {
+	bf := new(bytes.Buffer)
+	if err := proposal.Serialize(bf); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "[putProposal] serialize proposal error!")
+	}
+	native.CloneCache.Add(scommon.ST_STORAGE, genProposalKey(contract, proposalID), &cstates.StorageItem{Value: bf.Bytes()})
+	return nil
+}
+
+// executeIfApproved executes the proposal once it is confirmed by threshold
+// owners of the wallet. The proposal is called with the wallet address as
+// the calling context, so that the callee accepts the wallet as witness.
+func executeIfApproved(native *native.NativeService, contract common.Address, proposalID uint64, wallet *Wallet, proposal *Proposal) error {
+	if countConfirmations(wallet, proposal) < wallet.Threshold {
+		return putProposal(native, contract, proposalID, proposal)
+	}
+	proposal.Executed = true
+	if err := putProposal(native, contract, proposalID, proposal); err != nil {
+		return err
+	}
+	method, args := proposal.Method, proposal.Args
+	if proposal.Kind == PROPOSAL_TRANSFER {
+		transfers := zpt.Transfers{
+			States: []zpt.State{{From: wallet.Address, To: proposal.To, Value: proposal.Amount}},
+		}
+		sink := common.NewZeroCopySink(nil)
+		transfers.Serialization(sink)
+		method, args = zpt.TRANSFER_NAME, sink.Bytes()
+	}
+	native.ContextRef.PushContext(&context.Context{ContractAddress: wallet.Address})
+	if _, err := native.NativeCall(proposal.Contract, method, args); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "[executeIfApproved] execute proposal error!")
+	}
+	native.ContextRef.PopContext()
+	addExecuteNotification(native, contract, proposalID, proposal.WalletID)
+	return nil
+}
+
+func addNotification(native *native.NativeService, contract common.Address, states []interface{}) {
+	if !config.DefConfig.Common.EnableEventLog {
+		return
+	}
+	native.Notifications = append(native.Notifications,
+		&event.NotifyEventInfo{
+			ContractAddress: contract,
+			States:          states,
+		})
+}
+
+func addCreateWalletNotification(native *native.NativeService, contract common.Address, walletID uint64, wallet *Wallet) {
+	addNotification(native, contract, []interface{}{CREATE_WALLET_NAME, walletID, wallet.Address.ToBase58(), wallet.Threshold})
+}
+
+func addChangeOwnersNotification(native *native.NativeService, contract common.Address, walletID uint64, wallet *Wallet) {
+	addNotification(native, contract, []interface{}{CHANGE_OWNERS_NAME, walletID, wallet.Address.ToBase58(), wallet.Threshold})
+}
+
+func addSubmitNotification(native *native.NativeService, contract common.Address, proposalID uint64, proposal *Proposal) {
+	addNotification(native, contract, []interface{}{SUBMIT_PROPOSAL_NAME, proposalID, proposal.WalletID, proposal.Proposer.ToBase58()})
+}
+
+func addConfirmNotification(native *native.NativeService, contract common.Address, method string, proposalID uint64, owner common.Address) {
+	addNotification(native, contract, []interface{}{method, proposalID, owner.ToBase58()})
+}
+
+func addExecuteNotification(native *native.NativeService, contract common.Address, proposalID, walletID uint64) {
+	addNotification(native, contract, []interface{}{EXECUTE_PROPOSAL_EVENT, proposalID, walletID})
+}
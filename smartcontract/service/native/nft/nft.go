@@ -0,0 +1,308 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package nft is a native non-fungible token contract for cultural assets.
+// Every token carries its metadata hash/uri and a royalty recipient and rate,
+// tokens of an owner can be enumerated.
+package nft
+
+import (
+	"bytes"
+	"math/big"
+
+	"github.com/imZhuFei/zeepin/common"
+	scommon "github.com/imZhuFei/zeepin/core/store/common"
+	"github.com/imZhuFei/zeepin/embed/simulator/types"
+	"github.com/imZhuFei/zeepin/errors"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/utils"
+)
+
+func InitNFT() {
+	native.Contracts[utils.NFTContractAddress] = RegisterNFTContract
+}
+
+func RegisterNFTContract(native *native.NativeService) {
+	if !utils.IsNativeContractHeight(native.Height) {
+		return
+	}
+	native.Register(MINT_NAME, Mint)
+	native.Register(TRANSFER_NAME, Transfer)
+	native.Register(APPROVE_NAME, Approve)
+	native.Register(TRANSFERFROM_NAME, TransferFrom)
+	native.Register(BURN_NAME, Burn)
+	native.Register(OWNEROF_NAME, OwnerOf)
+	native.Register(BALANCEOF_NAME, BalanceOf)
+	native.Register(TOKENINFO_NAME, TokenInfo)
+	native.Register(TOKENSOF_NAME, TokensOf)
+	native.Register(ROYALTYINFO_NAME, RoyaltyInfo)
+	native.Register(TOTALSUPPLY_NAME, TotalSupply)
+}
+
+func Mint(native *native.NativeService) ([]byte, error) {
+	param := new(MintParam)
+	if err := param.Deserialize(bytes.NewBuffer(native.Input)); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[Mint] param deserialize error!")
+	}
+	if err := checkMintParam(param); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[Mint] invalid param!")
+	}
+	if err := utils.ValidateOwner(native, param.Owner); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[Mint] check witness error!")
+	}
+
+	contract := native.ContextRef.CurrentContext().ContractAddress
+	countKey := utils.ConcatKey(contract, []byte(TOKEN_COUNT))
+	count, err := utils.GetStorageUInt64(native, countKey)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[Mint] get token count error!")
+	}
+	supplyKey := utils.ConcatKey(contract, []byte(TOTAL_SUPPLY))
+	supply, err := utils.GetStorageUInt64(native, supplyKey)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[Mint] get total supply error!")
+	}
+	tokenID := count + 1
+	info := &NFTInfo{
+		Owner:            param.Owner,
+		Creator:          param.Owner,
+		MetadataHash:     param.MetadataHash,
+		URI:              param.URI,
+		RoyaltyRecipient: param.RoyaltyRecipient,
+		RoyaltyRate:      param.RoyaltyRate,
+	}
+	if err := putTokenInfo(native, contract, tokenID, info); err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	if err := addOwnerToken(native, contract, param.Owner, tokenID); err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	native.CloneCache.Add(scommon.ST_STORAGE, countKey, utils.GenUInt64StorageItem(tokenID))
+	native.CloneCache.Add(scommon.ST_STORAGE, supplyKey, utils.GenUInt64StorageItem(supply+1))
+	addMintNotification(native, contract, tokenID, info)
+	return types.BigIntToBytes(new(big.Int).SetUint64(tokenID)), nil
+}
+
+func Transfer(native *native.NativeService) ([]byte, error) {
+	param := new(TransferParam)
+	if err := param.Deserialize(bytes.NewBuffer(native.Input)); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[Transfer] param deserialize error!")
+	}
+	if err := utils.ValidateOwner(native, param.From); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[Transfer] check witness error!")
+	}
+	if err := transfer(native, param.From, param.To, param.TokenID); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[Transfer] transfer error!")
+	}
+	return utils.BYTE_TRUE, nil
+}
+
+func TransferFrom(native *native.NativeService) ([]byte, error) {
+	param := new(TransferFromParam)
+	if err := param.Deserialize(bytes.NewBuffer(native.Input)); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[TransferFrom] param deserialize error!")
+	}
+	if err := utils.ValidateOwner(native, param.Sender); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[TransferFrom] check witness error!")
+	}
+	contract := native.ContextRef.CurrentContext().ContractAddress
+	info, err := getTokenInfo(native, contract, param.TokenID)
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	if info.Approved == common.ADDRESS_EMPTY || info.Approved != param.Sender {
+		return utils.BYTE_FALSE, errors.NewErr("[TransferFrom] sender is not approved!")
+	}
+	if err := transfer(native, param.From, param.To, param.TokenID); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[TransferFrom] transfer error!")
+	}
+	return utils.BYTE_TRUE, nil
+}
+
+// transfer moves the token from to, the approval of the token is cleared
+func transfer(native *native.NativeService, from, to common.Address, tokenID uint64) error {
+	if to == common.ADDRESS_EMPTY {
+		return errors.NewErr("transfer to empty address")
+	}
+	contract := native.ContextRef.CurrentContext().ContractAddress
+	info, err := getTokenInfo(native, contract, tokenID)
+	if err != nil {
+		return err
+	}
+	if info.Owner != from {
+		return errors.NewErr("from is not the owner of token")
+	}
+	if from == to {
+		return nil
+	}
+	if err := removeOwnerToken(native, contract, from, tokenID); err != nil {
+		return err
+	}
+	if err := addOwnerToken(native, contract, to, tokenID); err != nil {
+		return err
+	}
+	info.Owner = to
+	info.Approved = common.ADDRESS_EMPTY
+	if err := putTokenInfo(native, contract, tokenID, info); err != nil {
+		return err
+	}
+	addTransferNotification(native, contract, from, to, tokenID)
+	return nil
+}
+
+func Approve(native *native.NativeService) ([]byte, error) {
+	param := new(ApproveParam)
+	if err := param.Deserialize(bytes.NewBuffer(native.Input)); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[Approve] param deserialize error!")
+	}
+	if err := utils.ValidateOwner(native, param.Owner); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[Approve] check witness error!")
+	}
+	contract := native.ContextRef.CurrentContext().ContractAddress
+	info, err := getTokenInfo(native, contract, param.TokenID)
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	if info.Owner != param.Owner {
+		return utils.BYTE_FALSE, errors.NewErr("[Approve] approver is not the owner of token!")
+	}
+	info.Approved = param.Approved
+	if err := putTokenInfo(native, contract, param.TokenID, info); err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	addApproveNotification(native, contract, param.Owner, param.Approved, param.TokenID)
+	return utils.BYTE_TRUE, nil
+}
+
+func Burn(native *native.NativeService) ([]byte, error) {
+	param := new(BurnParam)
+	if err := param.Deserialize(bytes.NewBuffer(native.Input)); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[Burn] param deserialize error!")
+	}
+	if err := utils.ValidateOwner(native, param.Owner); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[Burn] check witness error!")
+	}
+	contract := native.ContextRef.CurrentContext().ContractAddress
+	info, err := getTokenInfo(native, contract, param.TokenID)
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	if info.Owner != param.Owner {
+		return utils.BYTE_FALSE, errors.NewErr("[Burn] burner is not the owner of token!")
+	}
+	if err := removeOwnerToken(native, contract, param.Owner, param.TokenID); err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	supplyKey := utils.ConcatKey(contract, []byte(TOTAL_SUPPLY))
+	supply, err := utils.GetStorageUInt64(native, supplyKey)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[Burn] get total supply error!")
+	}
+	native.CloneCache.Add(scommon.ST_STORAGE, supplyKey, utils.GenUInt64StorageItem(supply-1))
+	native.CloneCache.Delete(scommon.ST_STORAGE, genTokenInfoKey(contract, param.TokenID))
+	addBurnNotification(native, contract, param.Owner, param.TokenID)
+	return utils.BYTE_TRUE, nil
+}
+
+func OwnerOf(native *native.NativeService) ([]byte, error) {
+	info, err := getQueryTokenInfo(native)
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	return info.Owner[:], nil
+}
+
+func TokenInfo(native *native.NativeService) ([]byte, error) {
+	info, err := getQueryTokenInfo(native)
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	bf := new(bytes.Buffer)
+	if err := info.Serialize(bf); err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	return bf.Bytes(), nil
+}
+
+func BalanceOf(native *native.NativeService) ([]byte, error) {
+	owner, err := utils.ReadAddress(bytes.NewBuffer(native.Input))
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[BalanceOf] get owner address error!")
+	}
+	contract := native.ContextRef.CurrentContext().ContractAddress
+	balance, err := utils.GetStorageUInt64(native, genOwnerBalanceKey(contract, owner))
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[BalanceOf] get balance error!")
+	}
+	return types.BigIntToBytes(new(big.Int).SetUint64(balance)), nil
+}
+
+func TotalSupply(native *native.NativeService) ([]byte, error) {
+	contract := native.ContextRef.CurrentContext().ContractAddress
+	supply, err := utils.GetStorageUInt64(native, utils.ConcatKey(contract, []byte(TOTAL_SUPPLY)))
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[TotalSupply] get total supply error!")
+	}
+	return types.BigIntToBytes(new(big.Int).SetUint64(supply)), nil
+}
+
+func TokensOf(native *native.NativeService) ([]byte, error) {
+	param := new(TokensOfParam)
+	if err := param.Deserialize(bytes.NewBuffer(native.Input)); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[TokensOf] param deserialize error!")
+	}
+	contract := native.ContextRef.CurrentContext().ContractAddress
+	list, err := getOwnerTokens(native, contract, param)
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	bf := new(bytes.Buffer)
+	if err := list.Serialize(bf); err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	return bf.Bytes(), nil
+}
+
+func RoyaltyInfo(native *native.NativeService) ([]byte, error) {
+	param := new(RoyaltyParam)
+	if err := param.Deserialize(bytes.NewBuffer(native.Input)); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[RoyaltyInfo] param deserialize error!")
+	}
+	contract := native.ContextRef.CurrentContext().ContractAddress
+	info, err := getTokenInfo(native, contract, param.TokenID)
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	royalty := &Royalty{
+		Recipient: info.RoyaltyRecipient,
+		Amount:    calcRoyalty(param.SalePrice, info.RoyaltyRate),
+	}
+	bf := new(bytes.Buffer)
+	if err := royalty.Serialize(bf); err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	return bf.Bytes(), nil
+}
+
+func getQueryTokenInfo(native *native.NativeService) (*NFTInfo, error) {
+	tokenID, err := utils.ReadVarUint(bytes.NewBuffer(native.Input))
+	if err != nil {
+		return nil, errors.NewDetailErr(err, errors.ErrNoCode, "[getQueryTokenInfo] get token id error!")
+	}
+	return getTokenInfo(native, native.ContextRef.CurrentContext().ContractAddress, tokenID)
+}
@@ -0,0 +1,122 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nft
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/common/config"
+	"github.com/imZhuFei/zeepin/embed/simulator/types"
+	"github.com/imZhuFei/zeepin/smartcontract/context"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/testutil"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func tokensOf(t *testing.T, srvc *native.NativeService, param *TokensOfParam) *TokenList {
+	ret, err := testutil.Invoke(t, srvc, TokensOf, param)
+	assert.Nil(t, err)
+	list := new(TokenList)
+	assert.Nil(t, list.Deserialize(bytes.NewBuffer(ret)))
+	return list
+}
+
+func TestNFT(t *testing.T) {
+	artist, collector, market := common.Address{1}, common.Address{2}, common.Address{3}
+	ctx := testutil.NewContext(artist)
+	ctx.PushContext(&context.Context{ContractAddress: utils.NFTContractAddress})
+	srvc, closeStore := testutil.NewNativeService(t, ctx)
+	defer closeStore()
+	config.DefConfig.Common.EnableEventLog = true
+
+	mint := &MintParam{Owner: artist, URI: "ipfs://artwork", RoyaltyRecipient: artist, RoyaltyRate: 500}
+	for i := 1; i <= 3; i++ {
+		ret, err := testutil.Invoke(t, srvc, Mint, mint)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(i), types.BigIntFromBytes(ret).Int64())
+	}
+	_, err := testutil.Invoke(t, srvc, Mint, &MintParam{Owner: artist, URI: "ipfs://artwork", RoyaltyRate: 500})
+	assert.NotNil(t, err)
+	_, err = testutil.Invoke(t, srvc, Mint, &MintParam{Owner: collector, URI: "ipfs://artwork"})
+	assert.NotNil(t, err)
+
+	list := tokensOf(t, srvc, &TokensOfParam{Owner: artist, Limit: 2})
+	assert.Equal(t, []uint64{3, 2}, list.TokenIDs)
+	assert.Equal(t, uint64(1), list.Next)
+	list = tokensOf(t, srvc, &TokensOfParam{Owner: artist, Start: list.Next})
+	assert.Equal(t, []uint64{1}, list.TokenIDs)
+	assert.Equal(t, uint64(0), list.Next)
+
+	_, err = testutil.Invoke(t, srvc, Transfer, &TransferParam{From: artist, To: collector, TokenID: 2})
+	assert.Nil(t, err)
+	_, err = testutil.Invoke(t, srvc, Transfer, &TransferParam{From: artist, To: collector, TokenID: 2})
+	assert.NotNil(t, err)
+	assert.Equal(t, []uint64{3, 1}, tokensOf(t, srvc, &TokensOfParam{Owner: artist}).TokenIDs)
+	assert.Equal(t, []uint64{2}, tokensOf(t, srvc, &TokensOfParam{Owner: collector}).TokenIDs)
+
+	// the approved market transfers the token sold by collector back to artist
+	ctx.Sign(collector)
+	_, err = testutil.Invoke(t, srvc, Approve, &ApproveParam{Owner: collector, Approved: market, TokenID: 2})
+	assert.Nil(t, err)
+	ctx.Sign(market)
+	_, err = testutil.Invoke(t, srvc, TransferFrom, &TransferFromParam{Sender: market, From: collector, To: artist, TokenID: 2})
+	assert.Nil(t, err)
+	_, err = testutil.Invoke(t, srvc, TransferFrom, &TransferFromParam{Sender: market, From: artist, To: market, TokenID: 2})
+	assert.NotNil(t, err)
+
+	ret, err := testutil.Invoke(t, srvc, RoyaltyInfo, &RoyaltyParam{TokenID: 2, SalePrice: 10000})
+	assert.Nil(t, err)
+	royalty := new(Royalty)
+	assert.Nil(t, royalty.Deserialize(bytes.NewBuffer(ret)))
+	assert.Equal(t, &Royalty{Recipient: artist, Amount: 500}, royalty)
+
+	ctx.Sign(artist)
+	_, err = testutil.Invoke(t, srvc, Burn, &BurnParam{Owner: artist, TokenID: 3})
+	assert.Nil(t, err)
+	srvc.Input = nil
+	ret, err = TotalSupply(srvc)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), types.BigIntFromBytes(ret).Int64())
+	assert.Equal(t, []uint64{2, 1}, tokensOf(t, srvc, &TokensOfParam{Owner: artist}).TokenIDs)
+
+	bf := new(bytes.Buffer)
+	utils.WriteAddress(bf, artist)
+	srvc.Input = bf.Bytes()
+	ret, err = BalanceOf(srvc)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), types.BigIntFromBytes(ret).Int64())
+	assert.Equal(t, 7, len(srvc.Notifications))
+}
+
+func TestRegisterNFTContract(t *testing.T) {
+	networkId := config.DefConfig.P2PNode.NetworkId
+	defer func() { config.DefConfig.P2PNode.NetworkId = networkId }()
+
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_MAIN_NET
+	srvc := &native.NativeService{ServiceMap: make(map[string]native.Handler), Height: 10}
+	RegisterNFTContract(srvc)
+	assert.Empty(t, srvc.ServiceMap)
+
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_SOLO_NET
+	RegisterNFTContract(srvc)
+	assert.Contains(t, srvc.ServiceMap, MINT_NAME)
+}
@@ -0,0 +1,390 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nft
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/common/serialization"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/utils"
+)
+
+// NFTInfo is the state of a minted token
+type NFTInfo struct {
+	Owner            common.Address
+	Creator          common.Address
+	MetadataHash     []byte
+	URI              string
+	RoyaltyRecipient common.Address
+	RoyaltyRate      uint64
+	Approved         common.Address
+}
+
+func (this *NFTInfo) Serialize(w io.Writer) error {
+	if err := utils.WriteAddress(w, this.Owner); err != nil {
+		return fmt.Errorf("[NFTInfo] serialize owner error:%v", err)
+	}
+	if err := utils.WriteAddress(w, this.Creator); err != nil {
+		return fmt.Errorf("[NFTInfo] serialize creator error:%v", err)
+	}
+	if err := serialization.WriteVarBytes(w, this.MetadataHash); err != nil {
+		return fmt.Errorf("[NFTInfo] serialize metadata hash error:%v", err)
+	}
+	if err := serialization.WriteString(w, this.URI); err != nil {
+		return fmt.Errorf("[NFTInfo] serialize uri error:%v", err)
+	}
+	if err := utils.WriteAddress(w, this.RoyaltyRecipient); err != nil {
+		return fmt.Errorf("[NFTInfo] serialize royalty recipient error:%v", err)
+	}
+	if err := utils.WriteVarUint(w, this.RoyaltyRate); err != nil {
+		return fmt.Errorf("[NFTInfo] serialize royalty rate error:%v", err)
+	}
+	if err := utils.WriteAddress(w, this.Approved); err != nil {
+		return fmt.Errorf("[NFTInfo] serialize approved error:%v", err)
+	}
+	return nil
+}
+
+func (this *NFTInfo) Deserialize(r io.Reader) error {
+	var err error
+	if this.Owner, err = utils.ReadAddress(r); err != nil {
+		return fmt.Errorf("[NFTInfo] deserialize owner error:%v", err)
+	}
+	if this.Creator, err = utils.ReadAddress(r); err != nil {
+		return fmt.Errorf("[NFTInfo] deserialize creator error:%v", err)
+	}
+	if this.MetadataHash, err = serialization.ReadVarBytes(r); err != nil {
+		return fmt.Errorf("[NFTInfo] deserialize metadata hash error:%v", err)
+	}
+	if this.URI, err = serialization.ReadString(r); err != nil {
+		return fmt.Errorf("[NFTInfo] deserialize uri error:%v", err)
+	}
+	if this.RoyaltyRecipient, err = utils.ReadAddress(r); err != nil {
+		return fmt.Errorf("[NFTInfo] deserialize royalty recipient error:%v", err)
+	}
+	if this.RoyaltyRate, err = utils.ReadVarUint(r); err != nil {
+		return fmt.Errorf("[NFTInfo] deserialize royalty rate error:%v", err)
+	}
+	if this.Approved, err = utils.ReadAddress(r); err != nil {
+		return fmt.Errorf("[NFTInfo] deserialize approved error:%v", err)
+	}
+	return nil
+}
+
+// MintParam mints a token to Owner, the royalty rate is in basis points of
+// ROYALTY_RATE_BASE
+type MintParam struct {
+	Owner            common.Address
+	MetadataHash     []byte
+	URI              string
+	RoyaltyRecipient common.Address
+	RoyaltyRate      uint64
+}
+
+func (this *MintParam) Serialize(w io.Writer) error {
+	if err := utils.WriteAddress(w, this.Owner); err != nil {
+		return fmt.Errorf("[MintParam] serialize owner error:%v", err)
+	}
+	if err := serialization.WriteVarBytes(w, this.MetadataHash); err != nil {
+		return fmt.Errorf("[MintParam] serialize metadata hash error:%v", err)
+	}
+	if err := serialization.WriteString(w, this.URI); err != nil {
+		return fmt.Errorf("[MintParam] serialize uri error:%v", err)
+	}
+	if err := utils.WriteAddress(w, this.RoyaltyRecipient); err != nil {
+		return fmt.Errorf("[MintParam] serialize royalty recipient error:%v", err)
+	}
+	if err := utils.WriteVarUint(w, this.RoyaltyRate); err != nil {
+		return fmt.Errorf("[MintParam] serialize royalty rate error:%v", err)
+	}
+	return nil
+}
+
+func (this *MintParam) Deserialize(r io.Reader) error {
+	var err error
+	if this.Owner, err = utils.ReadAddress(r); err != nil {
+		return fmt.Errorf("[MintParam] deserialize owner error:%v", err)
+	}
+	if this.MetadataHash, err = serialization.ReadVarBytes(r); err != nil {
+		return fmt.Errorf("[MintParam] deserialize metadata hash error:%v", err)
+	}
+	if this.URI, err = serialization.ReadString(r); err != nil {
+		return fmt.Errorf("[MintParam] deserialize uri error:%v", err)
+	}
+	if this.RoyaltyRecipient, err = utils.ReadAddress(r); err != nil {
+		return fmt.Errorf("[MintParam] deserialize royalty recipient error:%v", err)
+	}
+	if this.RoyaltyRate, err = utils.ReadVarUint(r); err != nil {
+		return fmt.Errorf("[MintParam] deserialize royalty rate error:%v", err)
+	}
+	return nil
+}
+
+type TransferParam struct {
+	From    common.Address
+	To      common.Address
+	TokenID uint64
+}
+
+func (this *TransferParam) Serialize(w io.Writer) error {
+	if err := utils.WriteAddress(w, this.From); err != nil {
+		return fmt.Errorf("[TransferParam] serialize from error:%v", err)
+	}
+	if err := utils.WriteAddress(w, this.To); err != nil {
+		return fmt.Errorf("[TransferParam] serialize to error:%v", err)
+	}
+	if err := utils.WriteVarUint(w, this.TokenID); err != nil {
+		return fmt.Errorf("[TransferParam] serialize token id error:%v", err)
+	}
+	return nil
+}
+
+func (this *TransferParam) Deserialize(r io.Reader) error {
+	var err error
+	if this.From, err = utils.ReadAddress(r); err != nil {
+		return fmt.Errorf("[TransferParam] deserialize from error:%v", err)
+	}
+	if this.To, err = utils.ReadAddress(r); err != nil {
+		return fmt.Errorf("[TransferParam] deserialize to error:%v", err)
+	}
+	if this.TokenID, err = utils.ReadVarUint(r); err != nil {
+		return fmt.Errorf("[TransferParam] deserialize token id error:%v", err)
+	}
+	return nil
+}
+
+// ApproveParam approves Approved to transfer the token, an empty Approved
+// revokes the approval
+type ApproveParam struct {
+	Owner    common.Address
+	Approved common.Address
+	TokenID  uint64
+}
+
+func (this *ApproveParam) Serialize(w io.Writer) error {
+	if err := utils.WriteAddress(w, this.Owner); err != nil {
+		return fmt.Errorf("[ApproveParam] serialize owner error:%v", err)
+	}
+	if err := utils.WriteAddress(w, this.Approved); err != nil {
+		return fmt.Errorf("[ApproveParam] serialize approved error:%v", err)
+	}
+	if err := utils.WriteVarUint(w, this.TokenID); err != nil {
+		return fmt.Errorf("[ApproveParam] serialize token id error:%v", err)
+	}
+	return nil
+}
+
+func (this *ApproveParam) Deserialize(r io.Reader) error {
+	var err error
+	if this.Owner, err = utils.ReadAddress(r); err != nil {
+		return fmt.Errorf("[ApproveParam] deserialize owner error:%v", err)
+	}
+	if this.Approved, err = utils.ReadAddress(r); err != nil {
+		return fmt.Errorf("[ApproveParam] deserialize approved error:%v", err)
+	}
+	if this.TokenID, err = utils.ReadVarUint(r); err != nil {
+		return fmt.Errorf("[ApproveParam] deserialize token id error:%v", err)
+	}
+	return nil
+}
+
+type TransferFromParam struct {
+	Sender  common.Address
+	From    common.Address
+	To      common.Address
+	TokenID uint64
+}
+
+func (this *TransferFromParam) Serialize(w io.Writer) error {
+	if err := utils.WriteAddress(w, this.Sender); err != nil {
+		return fmt.Errorf("[TransferFromParam] serialize sender error:%v", err)
+	}
+	transfer := &TransferParam{From: this.From, To: this.To, TokenID: this.TokenID}
+	return transfer.Serialize(w)
+}
+
+func (this *TransferFromParam) Deserialize(r io.Reader) error {
+	var err error
+	if this.Sender, err = utils.ReadAddress(r); err != nil {
+		return fmt.Errorf("[TransferFromParam] deserialize sender error:%v", err)
+	}
+	transfer := new(TransferParam)
+	if err := transfer.Deserialize(r); err != nil {
+		return err
+	}
+	this.From, this.To, this.TokenID = transfer.From, transfer.To, transfer.TokenID
+	return nil
+}
+
+type BurnParam struct {
+	Owner   common.Address
+	TokenID uint64
+}
+
+func (this *BurnParam) Serialize(w io.Writer) error {
+	if err := utils.WriteAddress(w, this.Owner); err != nil {
+		return fmt.Errorf("[BurnParam] serialize owner error:%v", err)
+	}
+	if err := utils.WriteVarUint(w, this.TokenID); err != nil {
+		return fmt.Errorf("[BurnParam] serialize token id error:%v", err)
+	}
+	return nil
+}
+
+func (this *BurnParam) Deserialize(r io.Reader) error {
+	var err error
+	if this.Owner, err = utils.ReadAddress(r); err != nil {
+		return fmt.Errorf("[BurnParam] deserialize owner error:%v", err)
+	}
+	if this.TokenID, err = utils.ReadVarUint(r); err != nil {
+		return fmt.Errorf("[BurnParam] deserialize token id error:%v", err)
+	}
+	return nil
+}
+
+// TokensOfParam enumerates at most Limit tokens of Owner, starting from the
+// token Start or from the latest received token if Start is 0
+type TokensOfParam struct {
+	Owner common.Address
+	Start uint64
+	Limit uint64
+}
+
+func (this *TokensOfParam) Serialize(w io.Writer) error {
+	if err := utils.WriteAddress(w, this.Owner); err != nil {
+		return fmt.Errorf("[TokensOfParam] serialize owner error:%v", err)
+	}
+	if err := utils.WriteVarUint(w, this.Start); err != nil {
+		return fmt.Errorf("[TokensOfParam] serialize start error:%v", err)
+	}
+	if err := utils.WriteVarUint(w, this.Limit); err != nil {
+		return fmt.Errorf("[TokensOfParam] serialize limit error:%v", err)
+	}
+	return nil
+}
+
+func (this *TokensOfParam) Deserialize(r io.Reader) error {
+	var err error
+	if this.Owner, err = utils.ReadAddress(r); err != nil {
+		return fmt.Errorf("[TokensOfParam] deserialize owner error:%v", err)
+	}
+	if this.Start, err = utils.ReadVarUint(r); err != nil {
+		return fmt.Errorf("[TokensOfParam] deserialize start error:%v", err)
+	}
+	if this.Limit, err = utils.ReadVarUint(r); err != nil {
+		return fmt.Errorf("[TokensOfParam] deserialize limit error:%v", err)
+	}
+	return nil
+}
+
+// TokenList is the result of tokensOf, Next is the token to continue the
+// enumeration with, 0 if there is no more token
+type TokenList struct {
+	TokenIDs []uint64
+	Next     uint64
+}
+
+func (this *TokenList) Serialize(w io.Writer) error {
+	if err := utils.WriteVarUint(w, uint64(len(this.TokenIDs))); err != nil {
+		return fmt.Errorf("[TokenList] serialize length error:%v", err)
+	}
+	for _, id := range this.TokenIDs {
+		if err := utils.WriteVarUint(w, id); err != nil {
+			return fmt.Errorf("[TokenList] serialize token id error:%v", err)
+		}
+	}
+	if err := utils.WriteVarUint(w, this.Next); err != nil {
+		return fmt.Errorf("[TokenList] serialize next error:%v", err)
+	}
+	return nil
+}
+
+func (this *TokenList) Deserialize(r io.Reader) error {
+	n, err := utils.ReadVarUint(r)
+	if err != nil {
+		return fmt.Errorf("[TokenList] deserialize length error:%v", err)
+	}
+	this.TokenIDs = make([]uint64, 0, n)
+	for i := uint64(0); i < n; i++ {
+		id, err := utils.ReadVarUint(r)
+		if err != nil {
+			return fmt.Errorf("[TokenList] deserialize token id error:%v", err)
+		}
+		this.TokenIDs = append(this.TokenIDs, id)
+	}
+	if this.Next, err = utils.ReadVarUint(r); err != nil {
+		return fmt.Errorf("[TokenList] deserialize next error:%v", err)
+	}
+	return nil
+}
+
+// RoyaltyParam queries the royalty of the token sold at SalePrice
+type RoyaltyParam struct {
+	TokenID   uint64
+	SalePrice uint64
+}
+
+func (this *RoyaltyParam) Serialize(w io.Writer) error {
+	if err := utils.WriteVarUint(w, this.TokenID); err != nil {
+		return fmt.Errorf("[RoyaltyParam] serialize token id error:%v", err)
+	}
+	if err := utils.WriteVarUint(w, this.SalePrice); err != nil {
+		return fmt.Errorf("[RoyaltyParam] serialize sale price error:%v", err)
+	}
+	return nil
+}
+
+func (this *RoyaltyParam) Deserialize(r io.Reader) error {
+	var err error
+	if this.TokenID, err = utils.ReadVarUint(r); err != nil {
+		return fmt.Errorf("[RoyaltyParam] deserialize token id error:%v", err)
+	}
+	if this.SalePrice, err = utils.ReadVarUint(r); err != nil {
+		return fmt.Errorf("[RoyaltyParam] deserialize sale price error:%v", err)
+	}
+	return nil
+}
+
+type Royalty struct {
+	Recipient common.Address
+	Amount    uint64
+}
+
+func (this *Royalty) Serialize(w io.Writer) error {
+	if err := utils.WriteAddress(w, this.Recipient); err != nil {
+		return fmt.Errorf("[Royalty] serialize recipient error:%v", err)
+	}
+	if err := utils.WriteVarUint(w, this.Amount); err != nil {
+		return fmt.Errorf("[Royalty] serialize amount error:%v", err)
+	}
+	return nil
+}
+
+func (this *Royalty) Deserialize(r io.Reader) error {
+	var err error
+	if this.Recipient, err = utils.ReadAddress(r); err != nil {
+		return fmt.Errorf("[Royalty] deserialize recipient error:%v", err)
+	}
+	if this.Amount, err = utils.ReadVarUint(r); err != nil {
+		return fmt.Errorf("[Royalty] deserialize amount error:%v", err)
+	}
+	return nil
+}
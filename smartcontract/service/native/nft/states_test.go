@@ -0,0 +1,58 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nft
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNFTInfo_Serialize(t *testing.T) {
+	info := &NFTInfo{
+		Owner:            common.Address{1},
+		Creator:          common.Address{2},
+		MetadataHash:     []byte{1, 2, 3},
+		URI:              "ipfs://artwork",
+		RoyaltyRecipient: common.Address{2},
+		RoyaltyRate:      250,
+	}
+	bf := new(bytes.Buffer)
+	assert.Nil(t, info.Serialize(bf))
+	info2 := new(NFTInfo)
+	assert.Nil(t, info2.Deserialize(bf))
+	assert.Equal(t, info, info2)
+}
+
+func TestTokenList_Serialize(t *testing.T) {
+	list := &TokenList{TokenIDs: []uint64{3, 2, 1}, Next: 0}
+	bf := new(bytes.Buffer)
+	assert.Nil(t, list.Serialize(bf))
+	list2 := new(TokenList)
+	assert.Nil(t, list2.Deserialize(bf))
+	assert.Equal(t, list, list2)
+}
+
+func TestCalcRoyalty(t *testing.T) {
+	assert.Equal(t, uint64(250), calcRoyalty(10000, 250))
+	assert.Equal(t, uint64(0), calcRoyalty(10000, 0))
+	assert.Equal(t, uint64(18446744073709551615/2), calcRoyalty(18446744073709551615, ROYALTY_RATE_BASE/2))
+}
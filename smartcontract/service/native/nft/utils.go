@@ -0,0 +1,238 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nft
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/common/config"
+	"github.com/imZhuFei/zeepin/common/serialization"
+	cstates "github.com/imZhuFei/zeepin/core/states"
+	scommon "github.com/imZhuFei/zeepin/core/store/common"
+	"github.com/imZhuFei/zeepin/errors"
+	"github.com/imZhuFei/zeepin/smartcontract/event"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/utils"
+)
+
+const (
+	MINT_NAME         = "mint"
+	TRANSFER_NAME     = "transfer"
+	APPROVE_NAME      = "approve"
+	TRANSFERFROM_NAME = "transferFrom"
+	BURN_NAME         = "burn"
+	OWNEROF_NAME      = "ownerOf"
+	BALANCEOF_NAME    = "balanceOf"
+	TOKENINFO_NAME    = "tokenInfo"
+	TOKENSOF_NAME     = "tokensOf"
+	ROYALTYINFO_NAME  = "royaltyInfo"
+	TOTALSUPPLY_NAME  = "totalSupply"
+
+	//storage key prefix
+	TOKEN_COUNT   = "tokenCount"
+	TOTAL_SUPPLY  = "totalSupply"
+	TOKEN_INFO    = "tokenInfo"
+	OWNER_TOKENS  = "ownerTokens"
+	OWNER_BALANCE = "ownerBalance"
+
+	MAX_METADATA_HASH_LEN = 64
+	MAX_URI_LEN           = 512
+	ROYALTY_RATE_BASE     = 10000
+	MAX_TOKENSOF_LIMIT    = 100
+)
+
+func getTokenIDBytes(tokenID uint64) []byte {
+	bf := new(bytes.Buffer)
+	serialization.WriteUint64(bf, tokenID)
+	return bf.Bytes()
+}
+
+func getBytesTokenID(b []byte) (uint64, error) {
+	return serialization.ReadUint64(bytes.NewBuffer(b))
+}
+
+func genTokenInfoKey(contract common.Address, tokenID uint64) []byte {
+	return utils.ConcatKey(contract, []byte(TOKEN_INFO), getTokenIDBytes(tokenID))
+}
+
+func genOwnerTokensKey(contract, owner common.Address) []byte {
+	return utils.ConcatKey(contract, []byte(OWNER_TOKENS), owner[:])
+}
+
+func genOwnerBalanceKey(contract, owner common.Address) []byte {
+	return utils.ConcatKey(contract, []byte(OWNER_BALANCE), owner[:])
+}
+
+func checkMintParam(param *MintParam) error {
+	if param.Owner == common.ADDRESS_EMPTY {
+		return errors.NewErr("owner is empty")
+	}
+	if len(param.MetadataHash) == 0 && len(param.URI) == 0 {
+		return errors.NewErr("metadata hash and uri are both empty")
+	}
+	if len(param.MetadataHash) > MAX_METADATA_HASH_LEN {
+		return fmt.Errorf("metadata hash length over max %d", MAX_METADATA_HASH_LEN)
+	}
+	if len(param.URI) > MAX_URI_LEN {
+		return fmt.Errorf("uri length over max %d", MAX_URI_LEN)
+	}
+	if param.RoyaltyRate > ROYALTY_RATE_BASE {
+		return fmt.Errorf("royalty rate %d over %d", param.RoyaltyRate, ROYALTY_RATE_BASE)
+	}
+	if param.RoyaltyRate > 0 && param.RoyaltyRecipient == common.ADDRESS_EMPTY {
+		return errors.NewErr("royalty recipient is empty")
+	}
+	return nil
+}
+
+// calcRoyalty returns salePrice * rate / ROYALTY_RATE_BASE
+func calcRoyalty(salePrice, rate uint64) uint64 {
+	amount := new(big.Int).Mul(new(big.Int).SetUint64(salePrice), new(big.Int).SetUint64(rate))
+	return amount.Div(amount, big.NewInt(ROYALTY_RATE_BASE)).Uint64()
+}
+
+func getTokenInfo(native *native.NativeService, contract common.Address, tokenID uint64) (*NFTInfo, error) {
+	item, err := utils.GetStorageItem(native, genTokenInfoKey(contract, tokenID))
+	if err != nil {
+		return nil, errors.NewDetailErr(err, errors.ErrNoCode, "[getTokenInfo] get token info error!")
+	}
+	if item == nil {
+		return nil, fmt.Errorf("[getTokenInfo] token %d not exist", tokenID)
+	}
+	info := new(NFTInfo)
+	if err := info.Deserialize(bytes.NewBuffer(item.Value)); err != nil {
+		return nil, errors.NewDetailErr(err, errors.ErrNoCode, "[getTokenInfo] deserialize token info error!")
+	}
+	return info, nil
+}
+
+func putTokenInfo(native *native.NativeService, contract common.Address, tokenID uint64, info *NFTInfo) error {
+	bf := new(bytes.Buffer)
+	if err := info.Serialize(bf); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "[putTokenInfo] serialize token info error!")
+	}
+	native.CloneCache.Add(scommon.ST_STORAGE, genTokenInfoKey(contract, tokenID), &cstates.StorageItem{Value: bf.Bytes()})
+	return nil
+}
+
+// addOwnerToken appends the token to the enumeration list of owner
+func addOwnerToken(native *native.NativeService, contract, owner common.Address, tokenID uint64) error {
+	if err := utils.LinkedlistInsert(native, genOwnerTokensKey(contract, owner), getTokenIDBytes(tokenID), []byte{}); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "[addOwnerToken] insert owner token error!")
+	}
+	balanceKey := genOwnerBalanceKey(contract, owner)
+	balance, err := utils.GetStorageUInt64(native, balanceKey)
+	if err != nil {
+		return err
+	}
+	native.CloneCache.Add(scommon.ST_STORAGE, balanceKey, utils.GenUInt64StorageItem(balance+1))
+	return nil
+}
+
+// removeOwnerToken removes the token from the enumeration list of owner
+func removeOwnerToken(native *native.NativeService, contract, owner common.Address, tokenID uint64) error {
+	ok, err := utils.LinkedlistDelete(native, genOwnerTokensKey(contract, owner), getTokenIDBytes(tokenID))
+	if err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "[removeOwnerToken] delete owner token error!")
+	}
+	if !ok {
+		return fmt.Errorf("[removeOwnerToken] token %d not owned by %s", tokenID, owner.ToBase58())
+	}
+	balanceKey := genOwnerBalanceKey(contract, owner)
+	balance, err := utils.GetStorageUInt64(native, balanceKey)
+	if err != nil {
+		return err
+	}
+	if balance <= 1 {
+		native.CloneCache.Delete(scommon.ST_STORAGE, balanceKey)
+	} else {
+		native.CloneCache.Add(scommon.ST_STORAGE, balanceKey, utils.GenUInt64StorageItem(balance-1))
+	}
+	return nil
+}
+
+func getOwnerTokens(native *native.NativeService, contract common.Address, param *TokensOfParam) (*TokenList, error) {
+	index := genOwnerTokensKey(contract, param.Owner)
+	var item []byte
+	if param.Start == 0 {
+		head, err := utils.LinkedlistGetHead(native, index)
+		if err != nil {
+			return nil, errors.NewDetailErr(err, errors.ErrNoCode, "[getOwnerTokens] get list head error!")
+		}
+		item = head
+	} else {
+		item = getTokenIDBytes(param.Start)
+	}
+	limit := param.Limit
+	if limit == 0 || limit > MAX_TOKENSOF_LIMIT {
+		limit = MAX_TOKENSOF_LIMIT
+	}
+	list := &TokenList{TokenIDs: make([]uint64, 0)}
+	for len(item) > 0 {
+		tokenID, err := getBytesTokenID(item)
+		if err != nil {
+			return nil, err
+		}
+		if uint64(len(list.TokenIDs)) == limit {
+			list.Next = tokenID
+			break
+		}
+		node, err := utils.LinkedlistGetItem(native, index, item)
+		if err != nil {
+			return nil, errors.NewDetailErr(err, errors.ErrNoCode, "[getOwnerTokens] get list item error!")
+		}
+		if node == nil {
+			return nil, fmt.Errorf("[getOwnerTokens] token %d not owned by %s", tokenID, param.Owner.ToBase58())
+		}
+		list.TokenIDs = append(list.TokenIDs, tokenID)
+		item = node.GetNext()
+	}
+	return list, nil
+}
+
+func addNotification(native *native.NativeService, contract common.Address, states []interface{}) {
+	if !config.DefConfig.Common.EnableEventLog {
+		return
+	}
+	native.Notifications = append(native.Notifications,
+		&event.NotifyEventInfo{
+			ContractAddress: contract,
+			States:          states,
+		})
+}
+
+func addMintNotification(native *native.NativeService, contract common.Address, tokenID uint64, info *NFTInfo) {
+	addNotification(native, contract, []interface{}{MINT_NAME, info.Owner.ToBase58(), tokenID, info.URI, hex.EncodeToString(info.MetadataHash)})
+}
+
+func addTransferNotification(native *native.NativeService, contract, from, to common.Address, tokenID uint64) {
+	addNotification(native, contract, []interface{}{TRANSFER_NAME, from.ToBase58(), to.ToBase58(), tokenID})
+}
+
+func addApproveNotification(native *native.NativeService, contract, owner, approved common.Address, tokenID uint64) {
+	addNotification(native, contract, []interface{}{APPROVE_NAME, owner.ToBase58(), approved.ToBase58(), tokenID})
+}
+
+func addBurnNotification(native *native.NativeService, contract, owner common.Address, tokenID uint64) {
+	addNotification(native, contract, []interface{}{BURN_NAME, owner.ToBase58(), tokenID})
+}
@@ -0,0 +1,157 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package testutil provides the context and storage for tests of native contracts
+package testutil
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/core/store/leveldbstore"
+	"github.com/imZhuFei/zeepin/core/store/statestore"
+	"github.com/imZhuFei/zeepin/smartcontract/context"
+	"github.com/imZhuFei/zeepin/smartcontract/event"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native"
+	"github.com/imZhuFei/zeepin/smartcontract/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+// Context is the ContextRef of native service in tests. An address is witnessed if it's marked in Witnesses,
+// or it's the address of calling contract.
+type Context struct {
+	Contexts  []*context.Context
+	Witnesses map[common.Address]bool
+}
+
+// NewContext return a context with empty stack, witnessed by signers
+func NewContext(signers ...common.Address) *Context {
+	ctx := &Context{}
+	ctx.Sign(signers...)
+	return ctx
+}
+
+// Sign replace the witnesses by signers
+func (this *Context) Sign(signers ...common.Address) {
+	this.Witnesses = make(map[common.Address]bool)
+	for _, signer := range signers {
+		this.Witnesses[signer] = true
+	}
+}
+
+// Reset the stack of contexts to contract. A failed native call leaves its context on the stack,
+// so tests reset it before calling a handler after failures.
+func (this *Context) Reset(contract common.Address) {
+	this.Contexts = []*context.Context{{ContractAddress: contract}}
+}
+
+func (this *Context) PushContext(ctx *context.Context) {
+	this.Contexts = append(this.Contexts, ctx)
+}
+
+func (this *Context) CurrentContext() *context.Context {
+	return this.Contexts[len(this.Contexts)-1]
+}
+
+func (this *Context) CallingContext() *context.Context {
+	if len(this.Contexts) < 2 {
+		return nil
+	}
+	return this.Contexts[len(this.Contexts)-2]
+}
+
+func (this *Context) EntryContext() *context.Context {
+	return this.Contexts[0]
+}
+
+func (this *Context) PopContext() {
+	this.Contexts = this.Contexts[:len(this.Contexts)-1]
+}
+
+func (this *Context) CheckWitness(address common.Address) bool {
+	if this.Witnesses[address] {
+		return true
+	}
+	calling := this.CallingContext()
+	return calling != nil && calling.ContractAddress == address
+}
+
+func (this *Context) PushNotifications(notifications []*event.NotifyEventInfo) {}
+
+func (this *Context) NewExecuteEngine(code []byte) (context.Engine, error) {
+	return nil, nil
+}
+
+func (this *Context) NewWasmExecuteEngine(code []byte) (context.Engine, error) {
+	return nil, nil
+}
+
+func (this *Context) NewNativeExecuteEngine(code []byte) (context.Engine, error) {
+	return nil, nil
+}
+
+func (this *Context) VerifyCode(code []byte) error {
+	return nil
+}
+
+func (this *Context) CheckUseGas(gas uint64) bool {
+	return true
+}
+
+func (this *Context) CheckExecStep() bool {
+	return true
+}
+
+// NewNativeService return a native service of ctx, whose storage is a leveldb in a temp dir.
+// The returned function closes and removes the storage.
+func NewNativeService(t *testing.T, ctx *Context) (*native.NativeService, func()) {
+	dir, err := ioutil.TempDir("", "native")
+	if err != nil {
+		t.Fatalf("create temp dir error %s", err)
+	}
+	db, err := leveldbstore.NewLevelDBStore(dir)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("NewLevelDBStore error %s", err)
+	}
+	srvc := &native.NativeService{
+		CloneCache: storage.NewCloneCache(statestore.NewStateStoreBatch(statestore.NewMemDatabase(), db)),
+		ServiceMap: make(map[string]native.Handler),
+		ContextRef: ctx,
+	}
+	return srvc, func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+// Invoke call handler with the serialized param as input, nil param means empty input
+func Invoke(t *testing.T, srvc *native.NativeService, handler native.Handler, param interface {
+	Serialize(w io.Writer) error
+}) ([]byte, error) {
+	bf := new(bytes.Buffer)
+	if param != nil {
+		assert.Nil(t, param.Serialize(bf))
+	}
+	srvc.Input = bf.Bytes()
+	return handler(srvc)
+}
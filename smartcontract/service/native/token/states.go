@@ -0,0 +1,299 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package token
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/common/serialization"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/utils"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/zpt"
+)
+
+// TokenInfo is the metadata of an issued token, stored under the token id
+type TokenInfo struct {
+	Name      string
+	Symbol    string
+	Decimals  uint64
+	Issuer    []byte
+	Authority common.Address
+}
+
+// Mintable returns whether the token has a mint/burn authority
+func (this *TokenInfo) Mintable() bool {
+	return this.Authority != common.ADDRESS_EMPTY
+}
+
+func (this *TokenInfo) Serialize(w io.Writer) error {
+	if err := serialization.WriteString(w, this.Name); err != nil {
+		return fmt.Errorf("[TokenInfo] serialize name error:%v", err)
+	}
+	if err := serialization.WriteString(w, this.Symbol); err != nil {
+		return fmt.Errorf("[TokenInfo] serialize symbol error:%v", err)
+	}
+	if err := utils.WriteVarUint(w, this.Decimals); err != nil {
+		return fmt.Errorf("[TokenInfo] serialize decimals error:%v", err)
+	}
+	if err := serialization.WriteVarBytes(w, this.Issuer); err != nil {
+		return fmt.Errorf("[TokenInfo] serialize issuer error:%v", err)
+	}
+	if err := utils.WriteAddress(w, this.Authority); err != nil {
+		return fmt.Errorf("[TokenInfo] serialize authority error:%v", err)
+	}
+	return nil
+}
+
+func (this *TokenInfo) Deserialize(r io.Reader) error {
+	var err error
+	if this.Name, err = serialization.ReadString(r); err != nil {
+		return fmt.Errorf("[TokenInfo] deserialize name error:%v", err)
+	}
+	if this.Symbol, err = serialization.ReadString(r); err != nil {
+		return fmt.Errorf("[TokenInfo] deserialize symbol error:%v", err)
+	}
+	if this.Decimals, err = utils.ReadVarUint(r); err != nil {
+		return fmt.Errorf("[TokenInfo] deserialize decimals error:%v", err)
+	}
+	if this.Issuer, err = serialization.ReadVarBytes(r); err != nil {
+		return fmt.Errorf("[TokenInfo] deserialize issuer error:%v", err)
+	}
+	if this.Authority, err = utils.ReadAddress(r); err != nil {
+		return fmt.Errorf("[TokenInfo] deserialize authority error:%v", err)
+	}
+	return nil
+}
+
+// RegisterParam issues a new token. The whole initial supply goes to Owner,
+// an empty Authority makes the supply fixed.
+type RegisterParam struct {
+	Issuer      []byte
+	KeyNo       uint64
+	Name        string
+	Symbol      string
+	Decimals    uint64
+	TotalSupply uint64
+	Owner       common.Address
+	Authority   common.Address
+}
+
+func (this *RegisterParam) Serialize(w io.Writer) error {
+	if err := serialization.WriteVarBytes(w, this.Issuer); err != nil {
+		return fmt.Errorf("[RegisterParam] serialize issuer error:%v", err)
+	}
+	if err := utils.WriteVarUint(w, this.KeyNo); err != nil {
+		return fmt.Errorf("[RegisterParam] serialize keyNo error:%v", err)
+	}
+	if err := serialization.WriteString(w, this.Name); err != nil {
+		return fmt.Errorf("[RegisterParam] serialize name error:%v", err)
+	}
+	if err := serialization.WriteString(w, this.Symbol); err != nil {
+		return fmt.Errorf("[RegisterParam] serialize symbol error:%v", err)
+	}
+	if err := utils.WriteVarUint(w, this.Decimals); err != nil {
+		return fmt.Errorf("[RegisterParam] serialize decimals error:%v", err)
+	}
+	if err := utils.WriteVarUint(w, this.TotalSupply); err != nil {
+		return fmt.Errorf("[RegisterParam] serialize totalSupply error:%v", err)
+	}
+	if err := utils.WriteAddress(w, this.Owner); err != nil {
+		return fmt.Errorf("[RegisterParam] serialize owner error:%v", err)
+	}
+	if err := utils.WriteAddress(w, this.Authority); err != nil {
+		return fmt.Errorf("[RegisterParam] serialize authority error:%v", err)
+	}
+	return nil
+}
+
+func (this *RegisterParam) Deserialize(r io.Reader) error {
+	var err error
+	if this.Issuer, err = serialization.ReadVarBytes(r); err != nil {
+		return fmt.Errorf("[RegisterParam] deserialize issuer error:%v", err)
+	}
+	if this.KeyNo, err = utils.ReadVarUint(r); err != nil {
+		return fmt.Errorf("[RegisterParam] deserialize keyNo error:%v", err)
+	}
+	if this.Name, err = serialization.ReadString(r); err != nil {
+		return fmt.Errorf("[RegisterParam] deserialize name error:%v", err)
+	}
+	if this.Symbol, err = serialization.ReadString(r); err != nil {
+		return fmt.Errorf("[RegisterParam] deserialize symbol error:%v", err)
+	}
+	if this.Decimals, err = utils.ReadVarUint(r); err != nil {
+		return fmt.Errorf("[RegisterParam] deserialize decimals error:%v", err)
+	}
+	if this.TotalSupply, err = utils.ReadVarUint(r); err != nil {
+		return fmt.Errorf("[RegisterParam] deserialize totalSupply error:%v", err)
+	}
+	if this.Owner, err = utils.ReadAddress(r); err != nil {
+		return fmt.Errorf("[RegisterParam] deserialize owner error:%v", err)
+	}
+	if this.Authority, err = utils.ReadAddress(r); err != nil {
+		return fmt.Errorf("[RegisterParam] deserialize authority error:%v", err)
+	}
+	return nil
+}
+
+// TokenTransfers is zpt.Transfers of one token
+type TokenTransfers struct {
+	Token  common.Address
+	States []zpt.State
+}
+
+func (this *TokenTransfers) Serialize(w io.Writer) error {
+	if err := utils.WriteAddress(w, this.Token); err != nil {
+		return fmt.Errorf("[TokenTransfers] serialize token error:%v", err)
+	}
+	transfers := &zpt.Transfers{States: this.States}
+	return transfers.Serialize(w)
+}
+
+func (this *TokenTransfers) Deserialize(r io.Reader) error {
+	var err error
+	if this.Token, err = utils.ReadAddress(r); err != nil {
+		return fmt.Errorf("[TokenTransfers] deserialize token error:%v", err)
+	}
+	transfers := new(zpt.Transfers)
+	if err := transfers.Deserialize(r); err != nil {
+		return err
+	}
+	this.States = transfers.States
+	return nil
+}
+
+// TokenState is zpt.State of one token, used by approve
+type TokenState struct {
+	Token common.Address
+	From  common.Address
+	To    common.Address
+	Value uint64
+}
+
+func (this *TokenState) Serialize(w io.Writer) error {
+	if err := utils.WriteAddress(w, this.Token); err != nil {
+		return fmt.Errorf("[TokenState] serialize token error:%v", err)
+	}
+	state := &zpt.State{From: this.From, To: this.To, Value: this.Value}
+	return state.Serialize(w)
+}
+
+func (this *TokenState) Deserialize(r io.Reader) error {
+	var err error
+	if this.Token, err = utils.ReadAddress(r); err != nil {
+		return fmt.Errorf("[TokenState] deserialize token error:%v", err)
+	}
+	state := new(zpt.State)
+	if err := state.Deserialize(r); err != nil {
+		return err
+	}
+	this.From, this.To, this.Value = state.From, state.To, state.Value
+	return nil
+}
+
+// TokenTransferFromState is zpt.TransferFrom of one token
+type TokenTransferFromState struct {
+	Token  common.Address
+	Sender common.Address
+	From   common.Address
+	To     common.Address
+	Value  uint64
+}
+
+func (this *TokenTransferFromState) Serialize(w io.Writer) error {
+	if err := utils.WriteAddress(w, this.Token); err != nil {
+		return fmt.Errorf("[TokenTransferFrom] serialize token error:%v", err)
+	}
+	state := &zpt.TransferFrom{Sender: this.Sender, From: this.From, To: this.To, Value: this.Value}
+	return state.Serialize(w)
+}
+
+func (this *TokenTransferFromState) Deserialize(r io.Reader) error {
+	var err error
+	if this.Token, err = utils.ReadAddress(r); err != nil {
+		return fmt.Errorf("[TokenTransferFrom] deserialize token error:%v", err)
+	}
+	state := new(zpt.TransferFrom)
+	if err := state.Deserialize(r); err != nil {
+		return err
+	}
+	this.Sender, this.From, this.To, this.Value = state.Sender, state.From, state.To, state.Value
+	return nil
+}
+
+// MintParam mints Value new tokens to To, signed by the token authority
+type MintParam struct {
+	Token common.Address
+	To    common.Address
+	Value uint64
+}
+
+func (this *MintParam) Serialize(w io.Writer) error {
+	if err := utils.WriteAddress(w, this.Token); err != nil {
+		return fmt.Errorf("[MintParam] serialize token error:%v", err)
+	}
+	if err := utils.WriteAddress(w, this.To); err != nil {
+		return fmt.Errorf("[MintParam] serialize to error:%v", err)
+	}
+	if err := utils.WriteVarUint(w, this.Value); err != nil {
+		return fmt.Errorf("[MintParam] serialize value error:%v", err)
+	}
+	return nil
+}
+
+func (this *MintParam) Deserialize(r io.Reader) error {
+	var err error
+	if this.Token, err = utils.ReadAddress(r); err != nil {
+		return fmt.Errorf("[MintParam] deserialize token error:%v", err)
+	}
+	if this.To, err = utils.ReadAddress(r); err != nil {
+		return fmt.Errorf("[MintParam] deserialize to error:%v", err)
+	}
+	if this.Value, err = utils.ReadVarUint(r); err != nil {
+		return fmt.Errorf("[MintParam] deserialize value error:%v", err)
+	}
+	return nil
+}
+
+// BurnParam burns Value tokens from the balance of the token authority
+type BurnParam struct {
+	Token common.Address
+	Value uint64
+}
+
+func (this *BurnParam) Serialize(w io.Writer) error {
+	if err := utils.WriteAddress(w, this.Token); err != nil {
+		return fmt.Errorf("[BurnParam] serialize token error:%v", err)
+	}
+	if err := utils.WriteVarUint(w, this.Value); err != nil {
+		return fmt.Errorf("[BurnParam] serialize value error:%v", err)
+	}
+	return nil
+}
+
+func (this *BurnParam) Deserialize(r io.Reader) error {
+	var err error
+	if this.Token, err = utils.ReadAddress(r); err != nil {
+		return fmt.Errorf("[BurnParam] deserialize token error:%v", err)
+	}
+	if this.Value, err = utils.ReadVarUint(r); err != nil {
+		return fmt.Errorf("[BurnParam] deserialize value error:%v", err)
+	}
+	return nil
+}
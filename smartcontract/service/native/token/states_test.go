@@ -0,0 +1,83 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package token
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/zpt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterParam_Serialize(t *testing.T) {
+	param := &RegisterParam{
+		Issuer:      []byte("did:zpt:issuer"),
+		KeyNo:       1,
+		Name:        "Fan Token",
+		Symbol:      "FAN",
+		Decimals:    8,
+		TotalSupply: 1000000,
+		Owner:       common.Address{1},
+		Authority:   common.Address{2},
+	}
+	bf := new(bytes.Buffer)
+	assert.Nil(t, param.Serialize(bf))
+	param2 := new(RegisterParam)
+	assert.Nil(t, param2.Deserialize(bf))
+	assert.Equal(t, param, param2)
+}
+
+func TestTokenInfo_Serialize(t *testing.T) {
+	info := &TokenInfo{Name: "Fan Token", Symbol: "FAN", Decimals: 0, Issuer: []byte("did:zpt:issuer")}
+	bf := new(bytes.Buffer)
+	assert.Nil(t, info.Serialize(bf))
+	info2 := new(TokenInfo)
+	assert.Nil(t, info2.Deserialize(bf))
+	assert.Equal(t, info, info2)
+	assert.False(t, info2.Mintable())
+}
+
+func TestTokenTransfers_Serialize(t *testing.T) {
+	transfers := &TokenTransfers{
+		Token:  GenTokenID("FAN"),
+		States: []zpt.State{{From: common.Address{1}, To: common.Address{2}, Value: 10}},
+	}
+	bf := new(bytes.Buffer)
+	assert.Nil(t, transfers.Serialize(bf))
+	transfers2 := new(TokenTransfers)
+	assert.Nil(t, transfers2.Deserialize(bf))
+	assert.Equal(t, transfers, transfers2)
+
+	state := &TokenTransferFromState{Token: GenTokenID("FAN"), Sender: common.Address{3}, From: common.Address{1}, To: common.Address{2}, Value: 5}
+	bf.Reset()
+	assert.Nil(t, state.Serialize(bf))
+	state2 := new(TokenTransferFromState)
+	assert.Nil(t, state2.Deserialize(bf))
+	assert.Equal(t, state, state2)
+}
+
+func TestCheckSymbol(t *testing.T) {
+	assert.Nil(t, checkSymbol("FAN1"))
+	assert.NotNil(t, checkSymbol(""))
+	assert.NotNil(t, checkSymbol("fan"))
+	assert.NotNil(t, checkSymbol("FANTOKENFANTOKEN1"))
+	assert.NotEqual(t, GenTokenID("FAN"), GenTokenID("FAN1"))
+}
@@ -0,0 +1,341 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package token is a native token factory. Any GID holder can issue a named
+// token, the ledger of every token reuses the zpt transfer/approve logic
+// keyed by the token id instead of a contract address.
+package token
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+
+	"github.com/imZhuFei/zeepin/common"
+	scommon "github.com/imZhuFei/zeepin/core/store/common"
+	"github.com/imZhuFei/zeepin/embed/simulator/types"
+	"github.com/imZhuFei/zeepin/errors"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/utils"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/zpt"
+)
+
+func InitToken() {
+	native.Contracts[utils.TokenContractAddress] = RegisterTokenContract
+}
+
+func RegisterTokenContract(native *native.NativeService) {
+	if !utils.IsNativeContractHeight(native.Height) {
+		return
+	}
+	native.Register(REGISTER_NAME, TokenRegister)
+	native.Register(TRANSFER_NAME, TokenTransfer)
+	native.Register(APPROVE_NAME, TokenApprove)
+	native.Register(TRANSFERFROM_NAME, TokenTransferFrom)
+	native.Register(MINT_NAME, TokenMint)
+	native.Register(BURN_NAME, TokenBurn)
+	native.Register(NAME_NAME, TokenName)
+	native.Register(SYMBOL_NAME, TokenSymbol)
+	native.Register(DECIMALS_NAME, TokenDecimals)
+	native.Register(TOTALSUPPLY_NAME, TokenTotalSupply)
+	native.Register(BALANCEOF_NAME, TokenBalanceOf)
+	native.Register(ALLOWANCE_NAME, TokenAllowance)
+	native.Register(TOKENINFO_NAME, TokenGetInfo)
+}
+
+func TokenRegister(native *native.NativeService) ([]byte, error) {
+	param := new(RegisterParam)
+	if err := param.Deserialize(bytes.NewBuffer(native.Input)); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[TokenRegister] param deserialize error!")
+	}
+	if err := checkSymbol(param.Symbol); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[TokenRegister] invalid symbol!")
+	}
+	if len(param.Name) == 0 || len(param.Name) > MAX_NAME_LEN {
+		return utils.BYTE_FALSE, fmt.Errorf("[TokenRegister] name length should be in [1, %d]", MAX_NAME_LEN)
+	}
+	if param.Decimals > MAX_DECIMALS {
+		return utils.BYTE_FALSE, fmt.Errorf("[TokenRegister] decimals %d over max %d", param.Decimals, MAX_DECIMALS)
+	}
+	info := &TokenInfo{
+		Name:      param.Name,
+		Symbol:    param.Symbol,
+		Decimals:  param.Decimals,
+		Issuer:    param.Issuer,
+		Authority: param.Authority,
+	}
+	if param.TotalSupply == 0 && !info.Mintable() {
+		return utils.BYTE_FALSE, errors.NewErr("[TokenRegister] token without supply should have a mint authority!")
+	}
+	if param.TotalSupply > 0 && param.Owner == common.ADDRESS_EMPTY {
+		return utils.BYTE_FALSE, errors.NewErr("[TokenRegister] owner of the initial supply is empty!")
+	}
+	if err := verifyIssuer(native, param.Issuer, param.KeyNo); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[TokenRegister] verify issuer error!")
+	}
+
+	contract := native.ContextRef.CurrentContext().ContractAddress
+	token := GenTokenID(param.Symbol)
+	item, err := utils.GetStorageItem(native, genTokenInfoKey(contract, token))
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[TokenRegister] get token info error!")
+	}
+	if item != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[TokenRegister] symbol %s has been registered", param.Symbol)
+	}
+	if err := putTokenInfo(native, contract, token, info); err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	if param.TotalSupply > 0 {
+		native.CloneCache.Add(scommon.ST_STORAGE, GenBalanceKey(token, param.Owner), utils.GenUInt64StorageItem(param.TotalSupply))
+		native.CloneCache.Add(scommon.ST_STORAGE, GenTotalSupplyKey(token), utils.GenUInt64StorageItem(param.TotalSupply))
+		zpt.AddNotifications(native, token, &zpt.State{To: param.Owner, Value: param.TotalSupply})
+	}
+	addRegisterNotification(native, contract, token, info)
+	return utils.BYTE_TRUE, nil
+}
+
+func TokenTransfer(native *native.NativeService) ([]byte, error) {
+	transfers := new(TokenTransfers)
+	if err := transfers.Deserialize(bytes.NewBuffer(native.Input)); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[TokenTransfer] Transfers deserialize error!")
+	}
+	contract := native.ContextRef.CurrentContext().ContractAddress
+	if _, err := getTokenInfo(native, contract, transfers.Token); err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	for _, v := range transfers.States {
+		if v.Value == 0 {
+			continue
+		}
+		if err := transfer(native, transfers.Token, &v); err != nil {
+			return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[TokenTransfer] transfer error!")
+		}
+		zpt.AddNotifications(native, transfers.Token, &v)
+	}
+	return utils.BYTE_TRUE, nil
+}
+
+func TokenTransferFrom(native *native.NativeService) ([]byte, error) {
+	state := new(TokenTransferFromState)
+	if err := state.Deserialize(bytes.NewBuffer(native.Input)); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[TokenTransferFrom] State deserialize error!")
+	}
+	if state.Value == 0 {
+		return utils.BYTE_FALSE, nil
+	}
+	contract := native.ContextRef.CurrentContext().ContractAddress
+	if _, err := getTokenInfo(native, contract, state.Token); err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	if err := transferFrom(native, state.Token, state); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[TokenTransferFrom] transfer error!")
+	}
+	zpt.AddNotifications(native, state.Token, &zpt.State{From: state.From, To: state.To, Value: state.Value})
+	return utils.BYTE_TRUE, nil
+}
+
+func TokenApprove(native *native.NativeService) ([]byte, error) {
+	state := new(TokenState)
+	if err := state.Deserialize(bytes.NewBuffer(native.Input)); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[TokenApprove] state deserialize error!")
+	}
+	if state.Value == 0 {
+		return utils.BYTE_FALSE, nil
+	}
+	contract := native.ContextRef.CurrentContext().ContractAddress
+	if _, err := getTokenInfo(native, contract, state.Token); err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	if !native.ContextRef.CheckWitness(state.From) {
+		return utils.BYTE_FALSE, errors.NewErr("authentication failed!")
+	}
+	native.CloneCache.Add(scommon.ST_STORAGE, GenApproveKey(state.Token, state.From, state.To), utils.GenUInt64StorageItem(state.Value))
+	return utils.BYTE_TRUE, nil
+}
+
+func TokenMint(native *native.NativeService) ([]byte, error) {
+	param := new(MintParam)
+	if err := param.Deserialize(bytes.NewBuffer(native.Input)); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[TokenMint] param deserialize error!")
+	}
+	if param.Value == 0 {
+		return utils.BYTE_FALSE, nil
+	}
+	contract := native.ContextRef.CurrentContext().ContractAddress
+	info, err := getTokenInfo(native, contract, param.Token)
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	if !info.Mintable() {
+		return utils.BYTE_FALSE, fmt.Errorf("[TokenMint] token %s is not mintable", info.Symbol)
+	}
+	if !native.ContextRef.CheckWitness(info.Authority) {
+		return utils.BYTE_FALSE, errors.NewErr("authentication failed!")
+	}
+	supply, err := utils.GetStorageUInt64(native, GenTotalSupplyKey(param.Token))
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	supply, overflow := common.SafeAdd(supply, param.Value)
+	if overflow {
+		return utils.BYTE_FALSE, errors.NewErr("[TokenMint] total supply overflow!")
+	}
+	toKey := GenBalanceKey(param.Token, param.To)
+	toBalance, err := utils.GetStorageUInt64(native, toKey)
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	native.CloneCache.Add(scommon.ST_STORAGE, toKey, zpt.GetToUInt64StorageItem(toBalance, param.Value))
+	native.CloneCache.Add(scommon.ST_STORAGE, GenTotalSupplyKey(param.Token), utils.GenUInt64StorageItem(supply))
+	zpt.AddNotifications(native, param.Token, &zpt.State{To: param.To, Value: param.Value})
+	return utils.BYTE_TRUE, nil
+}
+
+func TokenBurn(native *native.NativeService) ([]byte, error) {
+	param := new(BurnParam)
+	if err := param.Deserialize(bytes.NewBuffer(native.Input)); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[TokenBurn] param deserialize error!")
+	}
+	if param.Value == 0 {
+		return utils.BYTE_FALSE, nil
+	}
+	contract := native.ContextRef.CurrentContext().ContractAddress
+	info, err := getTokenInfo(native, contract, param.Token)
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	if !info.Mintable() {
+		return utils.BYTE_FALSE, fmt.Errorf("[TokenBurn] token %s is not burnable", info.Symbol)
+	}
+	if !native.ContextRef.CheckWitness(info.Authority) {
+		return utils.BYTE_FALSE, errors.NewErr("authentication failed!")
+	}
+	fromKey := GenBalanceKey(param.Token, info.Authority)
+	fromBalance, err := utils.GetStorageUInt64(native, fromKey)
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	if fromBalance < param.Value {
+		return utils.BYTE_FALSE, fmt.Errorf("[TokenBurn] balance insufficient, balance:%d, burn amount:%d", fromBalance, param.Value)
+	} else if fromBalance == param.Value {
+		native.CloneCache.Delete(scommon.ST_STORAGE, fromKey)
+	} else {
+		native.CloneCache.Add(scommon.ST_STORAGE, fromKey, utils.GenUInt64StorageItem(fromBalance-param.Value))
+	}
+	supply, err := utils.GetStorageUInt64(native, GenTotalSupplyKey(param.Token))
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	native.CloneCache.Add(scommon.ST_STORAGE, GenTotalSupplyKey(param.Token), utils.GenUInt64StorageItem(supply-param.Value))
+	zpt.AddNotifications(native, param.Token, &zpt.State{From: info.Authority, Value: param.Value})
+	return utils.BYTE_TRUE, nil
+}
+
+func TokenName(native *native.NativeService) ([]byte, error) {
+	info, err := getQueryTokenInfo(native)
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	return []byte(info.Name), nil
+}
+
+func TokenSymbol(native *native.NativeService) ([]byte, error) {
+	info, err := getQueryTokenInfo(native)
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	return []byte(info.Symbol), nil
+}
+
+func TokenDecimals(native *native.NativeService) ([]byte, error) {
+	info, err := getQueryTokenInfo(native)
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	return types.BigIntToBytes(new(big.Int).SetUint64(info.Decimals)), nil
+}
+
+func TokenGetInfo(native *native.NativeService) ([]byte, error) {
+	info, err := getQueryTokenInfo(native)
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	bf := new(bytes.Buffer)
+	if err := info.Serialize(bf); err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	return bf.Bytes(), nil
+}
+
+func TokenTotalSupply(native *native.NativeService) ([]byte, error) {
+	source := common.NewZeroCopySource(native.Input)
+	token, err := utils.DecodeAddress(source)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[TokenTotalSupply] get token address error!")
+	}
+	amount, err := utils.GetStorageUInt64(native, GenTotalSupplyKey(token))
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[TokenTotalSupply] get totalSupply error!")
+	}
+	return types.BigIntToBytes(new(big.Int).SetUint64(amount)), nil
+}
+
+func TokenBalanceOf(native *native.NativeService) ([]byte, error) {
+	return getBalanceValue(native, zpt.TRANSFER_FLAG)
+}
+
+func TokenAllowance(native *native.NativeService) ([]byte, error) {
+	return getBalanceValue(native, zpt.APPROVE_FLAG)
+}
+
+func getQueryTokenInfo(native *native.NativeService) (*TokenInfo, error) {
+	source := common.NewZeroCopySource(native.Input)
+	token, err := utils.DecodeAddress(source)
+	if err != nil {
+		return nil, errors.NewDetailErr(err, errors.ErrNoCode, "[getQueryTokenInfo] get token address error!")
+	}
+	return getTokenInfo(native, native.ContextRef.CurrentContext().ContractAddress, token)
+}
+
+func getBalanceValue(native *native.NativeService, flag byte) ([]byte, error) {
+	source := common.NewZeroCopySource(native.Input)
+	token, err := utils.DecodeAddress(source)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[getBalanceValue] get token address error!")
+	}
+	from, err := utils.DecodeAddress(source)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[getBalanceValue] get from address error!")
+	}
+	var key []byte
+	if flag == zpt.APPROVE_FLAG {
+		to, err := utils.DecodeAddress(source)
+		if err != nil {
+			return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[getBalanceValue] get to address error!")
+		}
+		key = GenApproveKey(token, from, to)
+	} else {
+		key = GenBalanceKey(token, from)
+	}
+	amount, err := utils.GetStorageUInt64(native, key)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[getBalanceValue] get balance error!")
+	}
+	return types.BigIntToBytes(new(big.Int).SetUint64(amount)), nil
+}
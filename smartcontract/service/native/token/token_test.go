@@ -0,0 +1,117 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package token
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/common/config"
+	"github.com/imZhuFei/zeepin/embed/simulator/types"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/testutil"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/utils"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/zpt"
+	"github.com/stretchr/testify/assert"
+)
+
+func balanceOf(t *testing.T, srvc *native.NativeService, token, addr common.Address) uint64 {
+	amount, err := utils.GetStorageUInt64(srvc, GenBalanceKey(token, addr))
+	assert.Nil(t, err)
+	return amount
+}
+
+func TestTokenLedger(t *testing.T) {
+	owner, authority, other := common.Address{1}, common.Address{2}, common.Address{3}
+	ctx := testutil.NewContext(owner, authority)
+	ctx.Reset(utils.TokenContractAddress)
+	srvc, closeStore := testutil.NewNativeService(t, ctx)
+	defer closeStore()
+
+	token := GenTokenID("FAN")
+	info := &TokenInfo{Name: "Fan Token", Symbol: "FAN", Decimals: 2, Issuer: []byte("did:zpt:issuer"), Authority: authority}
+	assert.Nil(t, putTokenInfo(srvc, utils.TokenContractAddress, token, info))
+
+	_, err := testutil.Invoke(t, srvc, TokenMint, &MintParam{Token: token, To: owner, Value: 100})
+	assert.Nil(t, err)
+	ctx.Witnesses[authority] = false
+	_, err = testutil.Invoke(t, srvc, TokenMint, &MintParam{Token: token, To: owner, Value: 100})
+	assert.NotNil(t, err)
+	ctx.Witnesses[authority] = true
+
+	transfers := &TokenTransfers{Token: token, States: []zpt.State{{From: owner, To: authority, Value: 30}}}
+	_, err = testutil.Invoke(t, srvc, TokenTransfer, transfers)
+	assert.Nil(t, err)
+	transfers.States[0].Value = 100
+	_, err = testutil.Invoke(t, srvc, TokenTransfer, transfers)
+	assert.NotNil(t, err)
+	assert.Equal(t, uint64(70), balanceOf(t, srvc, token, owner))
+	assert.Equal(t, uint64(30), balanceOf(t, srvc, token, authority))
+
+	_, err = testutil.Invoke(t, srvc, TokenApprove, &TokenState{Token: token, From: owner, To: other, Value: 20})
+	assert.Nil(t, err)
+	ctx.Witnesses[other] = true
+	_, err = testutil.Invoke(t, srvc, TokenTransferFrom, &TokenTransferFromState{Token: token, Sender: other, From: owner, To: other, Value: 15})
+	assert.Nil(t, err)
+	_, err = testutil.Invoke(t, srvc, TokenTransferFrom, &TokenTransferFromState{Token: token, Sender: other, From: owner, To: other, Value: 15})
+	assert.NotNil(t, err)
+	assert.Equal(t, uint64(15), balanceOf(t, srvc, token, other))
+
+	_, err = testutil.Invoke(t, srvc, TokenBurn, &BurnParam{Token: token, Value: 10})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(20), balanceOf(t, srvc, token, authority))
+
+	sink := common.NewZeroCopySink(nil)
+	utils.EncodeAddress(sink, token)
+	srvc.Input = sink.Bytes()
+	supply, err := TokenTotalSupply(srvc)
+	assert.Nil(t, err)
+	assert.Equal(t, types.BigIntToBytes(big.NewInt(90)), supply)
+
+	// an unregistered token id is rejected by the ledger
+	transfers.Token = GenTokenID("NONE")
+	_, err = testutil.Invoke(t, srvc, TokenTransfer, transfers)
+	assert.NotNil(t, err)
+}
+
+func TestTokenKeys(t *testing.T) {
+	token, addr := GenTokenID("FAN"), common.Address{1}
+	// the ledger of the token is kept in the storage of the token contract,
+	// not in the storage of a contract deployed at the token id
+	for _, key := range [][]byte{GenBalanceKey(token, addr), GenApproveKey(token, addr, addr), GenTotalSupplyKey(token)} {
+		assert.True(t, bytes.HasPrefix(key, append(utils.TokenContractAddress[:], token[:]...)))
+		assert.False(t, bytes.HasPrefix(key, token[:]))
+	}
+}
+
+func TestRegisterTokenContract(t *testing.T) {
+	networkId := config.DefConfig.P2PNode.NetworkId
+	defer func() { config.DefConfig.P2PNode.NetworkId = networkId }()
+
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_MAIN_NET
+	srvc := &native.NativeService{ServiceMap: make(map[string]native.Handler), Height: 10}
+	RegisterTokenContract(srvc)
+	assert.Empty(t, srvc.ServiceMap)
+
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_SOLO_NET
+	RegisterTokenContract(srvc)
+	assert.Contains(t, srvc.ServiceMap, TRANSFER_NAME)
+}
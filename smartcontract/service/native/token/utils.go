@@ -0,0 +1,198 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package token
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/common/serialization"
+	cstates "github.com/imZhuFei/zeepin/core/states"
+	scommon "github.com/imZhuFei/zeepin/core/store/common"
+	"github.com/imZhuFei/zeepin/core/types"
+	"github.com/imZhuFei/zeepin/errors"
+	"github.com/imZhuFei/zeepin/smartcontract/event"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/utils"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/zpt"
+)
+
+const (
+	REGISTER_NAME     = "register"
+	TRANSFER_NAME     = "transfer"
+	APPROVE_NAME      = "approve"
+	TRANSFERFROM_NAME = "transferFrom"
+	MINT_NAME         = "mint"
+	BURN_NAME         = "burn"
+	NAME_NAME         = "name"
+	SYMBOL_NAME       = "symbol"
+	DECIMALS_NAME     = "decimals"
+	TOTALSUPPLY_NAME  = "totalSupply"
+	BALANCEOF_NAME    = "balanceOf"
+	ALLOWANCE_NAME    = "allowance"
+	TOKENINFO_NAME    = "tokenInfo"
+
+	TOKEN_INFO   = "tokenInfo"
+	TOTAL_SUPPLY = "totalSupply"
+
+	MAX_NAME_LEN   = 64
+	MAX_SYMBOL_LEN = 16
+	MAX_DECIMALS   = 18
+)
+
+// GenTokenID returns the id of the token with symbol
+func GenTokenID(symbol string) common.Address {
+	return types.AddressFromVmCode(append(utils.TokenContractAddress[:], symbol...))
+}
+
+// GenBalanceKey returns the storage key of the balance of addr, balances, approvals and total supply
+// of the token are kept under the token contract address followed by the token id
+func GenBalanceKey(token, addr common.Address) []byte {
+	return utils.ConcatKey(utils.TokenContractAddress, token[:], addr[:])
+}
+
+// GenApproveKey returns the storage key of the amount from approves to spend
+func GenApproveKey(token, from, to common.Address) []byte {
+	return utils.ConcatKey(utils.TokenContractAddress, token[:], from[:], to[:])
+}
+
+// GenTotalSupplyKey returns the storage key of the total supply of token
+func GenTotalSupplyKey(token common.Address) []byte {
+	return utils.ConcatKey(utils.TokenContractAddress, token[:], []byte(TOTAL_SUPPLY))
+}
+
+func genTokenInfoKey(contract, token common.Address) []byte {
+	temp := append(contract[:], TOKEN_INFO...)
+	return append(temp, token[:]...)
+}
+
+func checkSymbol(symbol string) error {
+	if len(symbol) == 0 || len(symbol) > MAX_SYMBOL_LEN {
+		return fmt.Errorf("symbol length should be in [1, %d]", MAX_SYMBOL_LEN)
+	}
+	for _, c := range symbol {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			return fmt.Errorf("symbol %s should only contain upper case letters and digits", symbol)
+		}
+	}
+	return nil
+}
+
+func getTokenInfo(native *native.NativeService, contract, token common.Address) (*TokenInfo, error) {
+	item, err := utils.GetStorageItem(native, genTokenInfoKey(contract, token))
+	if err != nil {
+		return nil, errors.NewDetailErr(err, errors.ErrNoCode, "[getTokenInfo] get token info error!")
+	}
+	if item == nil {
+		return nil, fmt.Errorf("[getTokenInfo] token %s not registered", token.ToBase58())
+	}
+	info := new(TokenInfo)
+	if err := info.Deserialize(bytes.NewBuffer(item.Value)); err != nil {
+		return nil, errors.NewDetailErr(err, errors.ErrNoCode, "[getTokenInfo] deserialize token info error!")
+	}
+	return info, nil
+}
+
+func putTokenInfo(native *native.NativeService, contract, token common.Address, info *TokenInfo) error {
+	bf := new(bytes.Buffer)
+	if err := info.Serialize(bf); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "[putTokenInfo] serialize token info error!")
+	}
+	native.CloneCache.Add(scommon.ST_STORAGE, genTokenInfoKey(contract, token), &cstates.StorageItem{Value: bf.Bytes()})
+	return nil
+}
+
+func transfer(native *native.NativeService, token common.Address, state *zpt.State) error {
+	if !native.ContextRef.CheckWitness(state.From) {
+		return errors.NewErr("authentication failed!")
+	}
+	if err := subBalance(native, GenBalanceKey(token, state.From), state.Value); err != nil {
+		return err
+	}
+	return addBalance(native, GenBalanceKey(token, state.To), state.Value)
+}
+
+func transferFrom(native *native.NativeService, token common.Address, state *TokenTransferFromState) error {
+	if !native.ContextRef.CheckWitness(state.Sender) {
+		return errors.NewErr("authentication failed!")
+	}
+	if err := subBalance(native, GenApproveKey(token, state.From, state.Sender), state.Value); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "[transferFrom] approve balance insufficient!")
+	}
+	if err := subBalance(native, GenBalanceKey(token, state.From), state.Value); err != nil {
+		return err
+	}
+	return addBalance(native, GenBalanceKey(token, state.To), state.Value)
+}
+
+func subBalance(native *native.NativeService, key []byte, value uint64) error {
+	balance, err := utils.GetStorageUInt64(native, key)
+	if err != nil {
+		return err
+	}
+	if balance < value {
+		return fmt.Errorf("balance insufficient, balance:%d, amount:%d", balance, value)
+	} else if balance == value {
+		native.CloneCache.Delete(scommon.ST_STORAGE, key)
+	} else {
+		native.CloneCache.Add(scommon.ST_STORAGE, key, utils.GenUInt64StorageItem(balance-value))
+	}
+	return nil
+}
+
+func addBalance(native *native.NativeService, key []byte, value uint64) error {
+	balance, err := utils.GetStorageUInt64(native, key)
+	if err != nil {
+		return err
+	}
+	balance, overflow := common.SafeAdd(balance, value)
+	if overflow {
+		return errors.NewErr("balance overflow!")
+	}
+	native.CloneCache.Add(scommon.ST_STORAGE, key, utils.GenUInt64StorageItem(balance))
+	return nil
+}
+
+func verifyIssuer(native *native.NativeService, issuer []byte, keyNo uint64) error {
+	bf := new(bytes.Buffer)
+	if err := serialization.WriteVarBytes(bf, issuer); err != nil {
+		return err
+	}
+	if err := utils.WriteVarUint(bf, keyNo); err != nil {
+		return err
+	}
+	ret, err := native.NativeCall(utils.GIDContractAddress, "verifySignature", bf.Bytes())
+	if err != nil {
+		return err
+	}
+	valid, ok := ret.([]byte)
+	if !ok || !bytes.Equal(valid, utils.BYTE_TRUE) {
+		return errors.NewErr("issuer signature verification failed")
+	}
+	return nil
+}
+
+func addRegisterNotification(native *native.NativeService, contract, token common.Address, info *TokenInfo) {
+	native.Notifications = append(native.Notifications,
+		&event.NotifyEventInfo{
+			ContractAddress: contract,
+			States:          []interface{}{REGISTER_NAME, token.ToBase58(), info.Symbol, string(info.Issuer)},
+		})
+}
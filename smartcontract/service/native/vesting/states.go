@@ -0,0 +1,295 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package vesting
+
+import (
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/common/serialization"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/utils"
+)
+
+// Schedule describes how the amount of a grant is released. Start, Cliff,
+// Duration and Step are block heights or unix timestamps according to
+// TimeUnit. Nothing is released before Start+Cliff, then the released amount
+// grows linearly to the full amount at Start+Duration, in steps of Step if
+// Step is larger than 1.
+type Schedule struct {
+	TimeUnit byte
+	Start    uint32
+	Cliff    uint32
+	Duration uint32
+	Step     uint32
+}
+
+func (this *Schedule) Serialize(w io.Writer) error {
+	if err := serialization.WriteByte(w, this.TimeUnit); err != nil {
+		return fmt.Errorf("[Schedule] serialize time unit error:%v", err)
+	}
+	if err := utils.WriteVarUint(w, uint64(this.Start)); err != nil {
+		return fmt.Errorf("[Schedule] serialize start error:%v", err)
+	}
+	if err := utils.WriteVarUint(w, uint64(this.Cliff)); err != nil {
+		return fmt.Errorf("[Schedule] serialize cliff error:%v", err)
+	}
+	if err := utils.WriteVarUint(w, uint64(this.Duration)); err != nil {
+		return fmt.Errorf("[Schedule] serialize duration error:%v", err)
+	}
+	if err := utils.WriteVarUint(w, uint64(this.Step)); err != nil {
+		return fmt.Errorf("[Schedule] serialize step error:%v", err)
+	}
+	return nil
+}
+
+func (this *Schedule) Deserialize(r io.Reader) error {
+	var err error
+	if this.TimeUnit, err = serialization.ReadByte(r); err != nil {
+		return fmt.Errorf("[Schedule] deserialize time unit error:%v", err)
+	}
+	if this.Start, err = readUint32(r); err != nil {
+		return fmt.Errorf("[Schedule] deserialize start error:%v", err)
+	}
+	if this.Cliff, err = readUint32(r); err != nil {
+		return fmt.Errorf("[Schedule] deserialize cliff error:%v", err)
+	}
+	if this.Duration, err = readUint32(r); err != nil {
+		return fmt.Errorf("[Schedule] deserialize duration error:%v", err)
+	}
+	if this.Step, err = readUint32(r); err != nil {
+		return fmt.Errorf("[Schedule] deserialize step error:%v", err)
+	}
+	return nil
+}
+
+// Grant is the state of a vesting grant, Amount is reduced to the vested
+// amount when the grant is revoked
+type Grant struct {
+	Grantor     common.Address
+	Beneficiary common.Address
+	Asset       common.Address
+	Amount      uint64
+	Claimed     uint64
+	Schedule    Schedule
+	Revocable   bool
+	Revoked     bool
+}
+
+func (this *Grant) Serialize(w io.Writer) error {
+	if err := utils.WriteAddress(w, this.Grantor); err != nil {
+		return fmt.Errorf("[Grant] serialize grantor error:%v", err)
+	}
+	if err := utils.WriteAddress(w, this.Beneficiary); err != nil {
+		return fmt.Errorf("[Grant] serialize beneficiary error:%v", err)
+	}
+	if err := utils.WriteAddress(w, this.Asset); err != nil {
+		return fmt.Errorf("[Grant] serialize asset error:%v", err)
+	}
+	if err := utils.WriteVarUint(w, this.Amount); err != nil {
+		return fmt.Errorf("[Grant] serialize amount error:%v", err)
+	}
+	if err := utils.WriteVarUint(w, this.Claimed); err != nil {
+		return fmt.Errorf("[Grant] serialize claimed error:%v", err)
+	}
+	if err := this.Schedule.Serialize(w); err != nil {
+		return err
+	}
+	if err := serialization.WriteBool(w, this.Revocable); err != nil {
+		return fmt.Errorf("[Grant] serialize revocable error:%v", err)
+	}
+	if err := serialization.WriteBool(w, this.Revoked); err != nil {
+		return fmt.Errorf("[Grant] serialize revoked error:%v", err)
+	}
+	return nil
+}
+
+func (this *Grant) Deserialize(r io.Reader) error {
+	var err error
+	if this.Grantor, err = utils.ReadAddress(r); err != nil {
+		return fmt.Errorf("[Grant] deserialize grantor error:%v", err)
+	}
+	if this.Beneficiary, err = utils.ReadAddress(r); err != nil {
+		return fmt.Errorf("[Grant] deserialize beneficiary error:%v", err)
+	}
+	if this.Asset, err = utils.ReadAddress(r); err != nil {
+		return fmt.Errorf("[Grant] deserialize asset error:%v", err)
+	}
+	if this.Amount, err = utils.ReadVarUint(r); err != nil {
+		return fmt.Errorf("[Grant] deserialize amount error:%v", err)
+	}
+	if this.Claimed, err = utils.ReadVarUint(r); err != nil {
+		return fmt.Errorf("[Grant] deserialize claimed error:%v", err)
+	}
+	if err := this.Schedule.Deserialize(r); err != nil {
+		return err
+	}
+	if this.Revocable, err = serialization.ReadBool(r); err != nil {
+		return fmt.Errorf("[Grant] deserialize revocable error:%v", err)
+	}
+	if this.Revoked, err = serialization.ReadBool(r); err != nil {
+		return fmt.Errorf("[Grant] deserialize revoked error:%v", err)
+	}
+	return nil
+}
+
+// CreateGrantParam locks Amount of Asset from Grantor for Beneficiary
+type CreateGrantParam struct {
+	Grantor     common.Address
+	Beneficiary common.Address
+	Asset       common.Address
+	Amount      uint64
+	Schedule    Schedule
+	Revocable   bool
+}
+
+func (this *CreateGrantParam) Serialize(w io.Writer) error {
+	if err := utils.WriteAddress(w, this.Grantor); err != nil {
+		return fmt.Errorf("[CreateGrantParam] serialize grantor error:%v", err)
+	}
+	if err := utils.WriteAddress(w, this.Beneficiary); err != nil {
+		return fmt.Errorf("[CreateGrantParam] serialize beneficiary error:%v", err)
+	}
+	if err := utils.WriteAddress(w, this.Asset); err != nil {
+		return fmt.Errorf("[CreateGrantParam] serialize asset error:%v", err)
+	}
+	if err := utils.WriteVarUint(w, this.Amount); err != nil {
+		return fmt.Errorf("[CreateGrantParam] serialize amount error:%v", err)
+	}
+	if err := this.Schedule.Serialize(w); err != nil {
+		return err
+	}
+	if err := serialization.WriteBool(w, this.Revocable); err != nil {
+		return fmt.Errorf("[CreateGrantParam] serialize revocable error:%v", err)
+	}
+	return nil
+}
+
+func (this *CreateGrantParam) Deserialize(r io.Reader) error {
+	var err error
+	if this.Grantor, err = utils.ReadAddress(r); err != nil {
+		return fmt.Errorf("[CreateGrantParam] deserialize grantor error:%v", err)
+	}
+	if this.Beneficiary, err = utils.ReadAddress(r); err != nil {
+		return fmt.Errorf("[CreateGrantParam] deserialize beneficiary error:%v", err)
+	}
+	if this.Asset, err = utils.ReadAddress(r); err != nil {
+		return fmt.Errorf("[CreateGrantParam] deserialize asset error:%v", err)
+	}
+	if this.Amount, err = utils.ReadVarUint(r); err != nil {
+		return fmt.Errorf("[CreateGrantParam] deserialize amount error:%v", err)
+	}
+	if err := this.Schedule.Deserialize(r); err != nil {
+		return err
+	}
+	if this.Revocable, err = serialization.ReadBool(r); err != nil {
+		return fmt.Errorf("[CreateGrantParam] deserialize revocable error:%v", err)
+	}
+	return nil
+}
+
+// GrantsOfParam enumerates grants of Beneficiary from grant Start, 0 means
+// from the latest one
+type GrantsOfParam struct {
+	Beneficiary common.Address
+	Start       uint64
+	Limit       uint64
+}
+
+func (this *GrantsOfParam) Serialize(w io.Writer) error {
+	if err := utils.WriteAddress(w, this.Beneficiary); err != nil {
+		return fmt.Errorf("[GrantsOfParam] serialize beneficiary error:%v", err)
+	}
+	if err := utils.WriteVarUint(w, this.Start); err != nil {
+		return fmt.Errorf("[GrantsOfParam] serialize start error:%v", err)
+	}
+	if err := utils.WriteVarUint(w, this.Limit); err != nil {
+		return fmt.Errorf("[GrantsOfParam] serialize limit error:%v", err)
+	}
+	return nil
+}
+
+func (this *GrantsOfParam) Deserialize(r io.Reader) error {
+	var err error
+	if this.Beneficiary, err = utils.ReadAddress(r); err != nil {
+		return fmt.Errorf("[GrantsOfParam] deserialize beneficiary error:%v", err)
+	}
+	if this.Start, err = utils.ReadVarUint(r); err != nil {
+		return fmt.Errorf("[GrantsOfParam] deserialize start error:%v", err)
+	}
+	if this.Limit, err = utils.ReadVarUint(r); err != nil {
+		return fmt.Errorf("[GrantsOfParam] deserialize limit error:%v", err)
+	}
+	return nil
+}
+
+// GrantList is a page of grant ids, Next is the grant id to start the next
+// page, 0 if no more grants
+type GrantList struct {
+	GrantIDs []uint64
+	Next     uint64
+}
+
+func (this *GrantList) Serialize(w io.Writer) error {
+	if err := utils.WriteVarUint(w, uint64(len(this.GrantIDs))); err != nil {
+		return fmt.Errorf("[GrantList] serialize length error:%v", err)
+	}
+	for _, id := range this.GrantIDs {
+		if err := utils.WriteVarUint(w, id); err != nil {
+			return fmt.Errorf("[GrantList] serialize grant id error:%v", err)
+		}
+	}
+	if err := utils.WriteVarUint(w, this.Next); err != nil {
+		return fmt.Errorf("[GrantList] serialize next error:%v", err)
+	}
+	return nil
+}
+
+func (this *GrantList) Deserialize(r io.Reader) error {
+	n, err := utils.ReadVarUint(r)
+	if err != nil {
+		return fmt.Errorf("[GrantList] deserialize length error:%v", err)
+	}
+	if n > MAX_GRANTSOF_LIMIT {
+		return fmt.Errorf("[GrantList] length %d over max %d", n, MAX_GRANTSOF_LIMIT)
+	}
+	this.GrantIDs = make([]uint64, 0, n)
+	for i := uint64(0); i < n; i++ {
+		id, err := utils.ReadVarUint(r)
+		if err != nil {
+			return fmt.Errorf("[GrantList] deserialize grant id error:%v", err)
+		}
+		this.GrantIDs = append(this.GrantIDs, id)
+	}
+	if this.Next, err = utils.ReadVarUint(r); err != nil {
+		return fmt.Errorf("[GrantList] deserialize next error:%v", err)
+	}
+	return nil
+}
+
+func readUint32(r io.Reader) (uint32, error) {
+	v, err := utils.ReadVarUint(r)
+	if err != nil {
+		return 0, err
+	}
+	if v > math.MaxUint32 {
+		return 0, fmt.Errorf("value %d over max uint32", v)
+	}
+	return uint32(v), nil
+}
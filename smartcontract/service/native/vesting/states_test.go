@@ -0,0 +1,71 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package vesting
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGrant_Serialize(t *testing.T) {
+	grant := &Grant{
+		Grantor:     common.Address{1},
+		Beneficiary: common.Address{2},
+		Asset:       common.Address{3},
+		Amount:      1000,
+		Claimed:     10,
+		Schedule:    Schedule{TimeUnit: TIME_UNIT_TIMESTAMP, Start: 1, Cliff: 2, Duration: 3, Step: 1},
+		Revocable:   true,
+	}
+	bf := new(bytes.Buffer)
+	assert.Nil(t, grant.Serialize(bf))
+	grant2 := new(Grant)
+	assert.Nil(t, grant2.Deserialize(bf))
+	assert.Equal(t, grant, grant2)
+}
+
+func TestGrantList_Serialize(t *testing.T) {
+	list := &GrantList{GrantIDs: []uint64{3, 2, 1}, Next: 0}
+	bf := new(bytes.Buffer)
+	assert.Nil(t, list.Serialize(bf))
+	list2 := new(GrantList)
+	assert.Nil(t, list2.Deserialize(bf))
+	assert.Equal(t, list, list2)
+}
+
+func TestVestedAmount(t *testing.T) {
+	grant := &Grant{Amount: 1000, Schedule: Schedule{Start: 100, Cliff: 25, Duration: 100}}
+	assert.Equal(t, uint64(0), vestedAmount(grant, 50))
+	assert.Equal(t, uint64(0), vestedAmount(grant, 124))
+	assert.Equal(t, uint64(250), vestedAmount(grant, 125))
+	assert.Equal(t, uint64(990), vestedAmount(grant, 199))
+	assert.Equal(t, uint64(1000), vestedAmount(grant, 200))
+	assert.Equal(t, uint64(1000), vestedAmount(grant, 0xffffffff))
+
+	grant.Schedule.Step = 30
+	assert.Equal(t, uint64(0), vestedAmount(grant, 125))
+	assert.Equal(t, uint64(300), vestedAmount(grant, 159))
+	assert.Equal(t, uint64(900), vestedAmount(grant, 199))
+	assert.Equal(t, uint64(1000), vestedAmount(grant, 200))
+
+	grant = &Grant{Amount: 0xffffffffffffffff, Schedule: Schedule{Duration: 2}}
+	assert.Equal(t, uint64(0xffffffffffffffff/2), vestedAmount(grant, 1))
+}
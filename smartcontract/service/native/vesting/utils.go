@@ -0,0 +1,239 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package vesting
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/common/config"
+	"github.com/imZhuFei/zeepin/common/serialization"
+	cstates "github.com/imZhuFei/zeepin/core/states"
+	scommon "github.com/imZhuFei/zeepin/core/store/common"
+	"github.com/imZhuFei/zeepin/errors"
+	"github.com/imZhuFei/zeepin/smartcontract/event"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/utils"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/zpt"
+)
+
+const (
+	CREATE_GRANT_NAME  = "createGrant"
+	CLAIM_NAME         = "claim"
+	REVOKE_NAME        = "revoke"
+	GET_GRANT_NAME     = "getGrant"
+	GET_CLAIMABLE_NAME = "getClaimable"
+	GRANTS_OF_NAME     = "grantsOf"
+
+	//storage key prefix
+	GRANT_COUNT        = "grantCount"
+	GRANT              = "grant"
+	BENEFICIARY_GRANTS = "beneficiaryGrants"
+
+	//time unit of schedule
+	TIME_UNIT_HEIGHT    = 0
+	TIME_UNIT_TIMESTAMP = 1
+
+	MAX_GRANTSOF_LIMIT = 100
+)
+
+func getGrantIDBytes(grantID uint64) []byte {
+	bf := new(bytes.Buffer)
+	serialization.WriteUint64(bf, grantID)
+	return bf.Bytes()
+}
+
+func getBytesGrantID(b []byte) (uint64, error) {
+	return serialization.ReadUint64(bytes.NewBuffer(b))
+}
+
+func genGrantKey(contract common.Address, grantID uint64) []byte {
+	return utils.ConcatKey(contract, []byte(GRANT), getGrantIDBytes(grantID))
+}
+
+func genBeneficiaryGrantsKey(contract, beneficiary common.Address) []byte {
+	return utils.ConcatKey(contract, []byte(BENEFICIARY_GRANTS), beneficiary[:])
+}
+
+func checkCreateGrantParam(param *CreateGrantParam) error {
+	if param.Beneficiary == common.ADDRESS_EMPTY {
+		return errors.NewErr("beneficiary is empty")
+	}
+	if param.Asset != utils.ZptContractAddress && param.Asset != utils.GalaContractAddress {
+		return fmt.Errorf("asset %s is not zpt or gala", param.Asset.ToHexString())
+	}
+	if param.Amount == 0 {
+		return errors.NewErr("amount is zero")
+	}
+	return checkSchedule(&param.Schedule)
+}
+
+func checkSchedule(schedule *Schedule) error {
+	if schedule.TimeUnit != TIME_UNIT_HEIGHT && schedule.TimeUnit != TIME_UNIT_TIMESTAMP {
+		return fmt.Errorf("unknown time unit %d", schedule.TimeUnit)
+	}
+	if schedule.Duration == 0 {
+		return errors.NewErr("duration is zero")
+	}
+	if schedule.Cliff > schedule.Duration {
+		return errors.NewErr("cliff is longer than duration")
+	}
+	if schedule.Step > schedule.Duration {
+		return errors.NewErr("step is longer than duration")
+	}
+	if uint64(schedule.Start)+uint64(schedule.Duration) > math.MaxUint32 {
+		return errors.NewErr("end of schedule overflows")
+	}
+	return nil
+}
+
+// currentTime returns the block height or the block timestamp according to
+// the time unit of schedule
+func currentTime(native *native.NativeService, schedule *Schedule) uint32 {
+	if schedule.TimeUnit == TIME_UNIT_TIMESTAMP {
+		return native.Time
+	}
+	return native.Height
+}
+
+// vestedAmount returns the amount of grant released at t
+func vestedAmount(grant *Grant, t uint32) uint64 {
+	if grant.Revoked {
+		return grant.Amount
+	}
+	schedule := &grant.Schedule
+	if t < schedule.Start {
+		return 0
+	}
+	elapsed := t - schedule.Start
+	if elapsed < schedule.Cliff {
+		return 0
+	}
+	if elapsed >= schedule.Duration {
+		return grant.Amount
+	}
+	if schedule.Step > 1 {
+		elapsed -= elapsed % schedule.Step
+	}
+	amount := new(big.Int).Mul(new(big.Int).SetUint64(grant.Amount), new(big.Int).SetUint64(uint64(elapsed)))
+	return amount.Div(amount, new(big.Int).SetUint64(uint64(schedule.Duration))).Uint64()
+}
+
+func getGrant(native *native.NativeService, contract common.Address, grantID uint64) (*Grant, error) {
+	item, err := utils.GetStorageItem(native, genGrantKey(contract, grantID))
+	if err != nil {
+		return nil, errors.NewDetailErr(err, errors.ErrNoCode, "[getGrant] get grant error!")
+	}
+	if item == nil {
+		return nil, fmt.Errorf("[getGrant] grant %d not exist", grantID)
+	}
+	grant := new(Grant)
+	if err := grant.Deserialize(bytes.NewBuffer(item.Value)); err != nil {
+		return nil, errors.NewDetailErr(err, errors.ErrNoCode, "[getGrant] deserialize grant error!")
+	}
+	return grant, nil
+}
+
+func putGrant(native *native.NativeService, contract common.Address, grantID uint64, grant *Grant) error {
+	bf := new(bytes.Buffer)
+	if err := grant.Serialize(bf); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "[putGrant] serialize grant error!")
+	}
+	native.CloneCache.Add(scommon.ST_STORAGE, genGrantKey(contract, grantID), &cstates.StorageItem{Value: bf.Bytes()})
+	return nil
+}
+
+func getBeneficiaryGrants(native *native.NativeService, contract common.Address, param *GrantsOfParam) (*GrantList, error) {
+	index := genBeneficiaryGrantsKey(contract, param.Beneficiary)
+	var item []byte
+	if param.Start == 0 {
+		head, err := utils.LinkedlistGetHead(native, index)
+		if err != nil {
+			return nil, errors.NewDetailErr(err, errors.ErrNoCode, "[getBeneficiaryGrants] get list head error!")
+		}
+		item = head
+	} else {
+		item = getGrantIDBytes(param.Start)
+	}
+	limit := param.Limit
+	if limit == 0 || limit > MAX_GRANTSOF_LIMIT {
+		limit = MAX_GRANTSOF_LIMIT
+	}
+	list := &GrantList{GrantIDs: make([]uint64, 0)}
+	for len(item) > 0 {
+		grantID, err := getBytesGrantID(item)
+		if err != nil {
+			return nil, err
+		}
+		if uint64(len(list.GrantIDs)) == limit {
+			list.Next = grantID
+			break
+		}
+		node, err := utils.LinkedlistGetItem(native, index, item)
+		if err != nil {
+			return nil, errors.NewDetailErr(err, errors.ErrNoCode, "[getBeneficiaryGrants] get list item error!")
+		}
+		if node == nil {
+			return nil, fmt.Errorf("[getBeneficiaryGrants] grant %d not of %s", grantID, param.Beneficiary.ToBase58())
+		}
+		list.GrantIDs = append(list.GrantIDs, grantID)
+		item = node.GetNext()
+	}
+	return list, nil
+}
+
+// appCallTransfer moves amount of asset by the transfer method of the asset
+// contract, so that gala of zpt holders is granted as usual
+func appCallTransfer(native *native.NativeService, asset, from, to common.Address, amount uint64) error {
+	transfers := zpt.Transfers{
+		States: []zpt.State{{From: from, To: to, Value: amount}},
+	}
+	sink := common.NewZeroCopySink(nil)
+	transfers.Serialization(sink)
+	if _, err := native.NativeCall(asset, zpt.TRANSFER_NAME, sink.Bytes()); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "[appCallTransfer] call transfer error!")
+	}
+	return nil
+}
+
+func addNotification(native *native.NativeService, contract common.Address, states []interface{}) {
+	if !config.DefConfig.Common.EnableEventLog {
+		return
+	}
+	native.Notifications = append(native.Notifications,
+		&event.NotifyEventInfo{
+			ContractAddress: contract,
+			States:          states,
+		})
+}
+
+func addCreateGrantNotification(native *native.NativeService, contract common.Address, grantID uint64, grant *Grant) {
+	addNotification(native, contract, []interface{}{CREATE_GRANT_NAME, grantID, grant.Grantor.ToBase58(),
+		grant.Beneficiary.ToBase58(), grant.Asset.ToHexString(), grant.Amount})
+}
+
+func addClaimNotification(native *native.NativeService, contract common.Address, grantID uint64, beneficiary common.Address, amount uint64) {
+	addNotification(native, contract, []interface{}{CLAIM_NAME, grantID, beneficiary.ToBase58(), amount})
+}
+
+func addRevokeNotification(native *native.NativeService, contract common.Address, grantID uint64, grantor common.Address, refund uint64) {
+	addNotification(native, contract, []interface{}{REVOKE_NAME, grantID, grantor.ToBase58(), refund})
+}
@@ -0,0 +1,207 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+// Package vesting is a native contract locking zpt or gala of a grantor for a
+// beneficiary. Every grant is released by a schedule in block heights or
+// timestamps: nothing before the cliff, then linearly or stepwise until the
+// end of the schedule. The beneficiary claims the released amount, the grantor
+// of a revocable grant may take back the part not released yet.
+package vesting
+
+import (
+	"bytes"
+	"math/big"
+
+	scommon "github.com/imZhuFei/zeepin/core/store/common"
+	"github.com/imZhuFei/zeepin/embed/simulator/types"
+	"github.com/imZhuFei/zeepin/errors"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/utils"
+)
+
+func InitVesting() {
+	native.Contracts[utils.VestingContractAddress] = RegisterVestingContract
+}
+
+func RegisterVestingContract(native *native.NativeService) {
+	if !utils.IsNativeContractHeight(native.Height) {
+		return
+	}
+	native.Register(CREATE_GRANT_NAME, CreateGrant)
+	native.Register(CLAIM_NAME, Claim)
+	native.Register(REVOKE_NAME, Revoke)
+	native.Register(GET_GRANT_NAME, GetGrant)
+	native.Register(GET_CLAIMABLE_NAME, GetClaimable)
+	native.Register(GRANTS_OF_NAME, GrantsOf)
+}
+
+// CreateGrant locks the amount of grantor in the vesting contract, returns the
+// id of the new grant
+func CreateGrant(native *native.NativeService) ([]byte, error) {
+	param := new(CreateGrantParam)
+	if err := param.Deserialize(bytes.NewBuffer(native.Input)); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[CreateGrant] param deserialize error!")
+	}
+	if err := checkCreateGrantParam(param); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[CreateGrant] invalid param!")
+	}
+	if err := utils.ValidateOwner(native, param.Grantor); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[CreateGrant] check witness error!")
+	}
+
+	contract := native.ContextRef.CurrentContext().ContractAddress
+	if err := appCallTransfer(native, param.Asset, param.Grantor, contract, param.Amount); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[CreateGrant] lock amount error!")
+	}
+	countKey := utils.ConcatKey(contract, []byte(GRANT_COUNT))
+	count, err := utils.GetStorageUInt64(native, countKey)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[CreateGrant] get grant count error!")
+	}
+	grantID := count + 1
+	grant := &Grant{
+		Grantor:     param.Grantor,
+		Beneficiary: param.Beneficiary,
+		Asset:       param.Asset,
+		Amount:      param.Amount,
+		Schedule:    param.Schedule,
+		Revocable:   param.Revocable,
+	}
+	if err := putGrant(native, contract, grantID, grant); err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	if err := utils.LinkedlistInsert(native, genBeneficiaryGrantsKey(contract, param.Beneficiary), getGrantIDBytes(grantID), []byte{}); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[CreateGrant] insert beneficiary grant error!")
+	}
+	native.CloneCache.Add(scommon.ST_STORAGE, countKey, utils.GenUInt64StorageItem(grantID))
+	addCreateGrantNotification(native, contract, grantID, grant)
+	return types.BigIntToBytes(new(big.Int).SetUint64(grantID)), nil
+}
+
+// Claim transfers the released and not yet claimed amount of a grant to the
+// beneficiary
+func Claim(native *native.NativeService) ([]byte, error) {
+	grantID, err := utils.ReadVarUint(bytes.NewBuffer(native.Input))
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[Claim] get grant id error!")
+	}
+	contract := native.ContextRef.CurrentContext().ContractAddress
+	grant, err := getGrant(native, contract, grantID)
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	if err := utils.ValidateOwner(native, grant.Beneficiary); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[Claim] check witness error!")
+	}
+	amount := vestedAmount(grant, currentTime(native, &grant.Schedule)) - grant.Claimed
+	if amount == 0 {
+		return utils.BYTE_FALSE, errors.NewErr("[Claim] nothing to claim!")
+	}
+	grant.Claimed += amount
+	if err := putGrant(native, contract, grantID, grant); err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	if err := appCallTransfer(native, grant.Asset, contract, grant.Beneficiary, amount); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[Claim] transfer error!")
+	}
+	addClaimNotification(native, contract, grantID, grant.Beneficiary, amount)
+	return utils.BYTE_TRUE, nil
+}
+
+// Revoke returns the amount of a revocable grant not released yet to the
+// grantor, the released amount can still be claimed by the beneficiary
+func Revoke(native *native.NativeService) ([]byte, error) {
+	grantID, err := utils.ReadVarUint(bytes.NewBuffer(native.Input))
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[Revoke] get grant id error!")
+	}
+	contract := native.ContextRef.CurrentContext().ContractAddress
+	grant, err := getGrant(native, contract, grantID)
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	if err := utils.ValidateOwner(native, grant.Grantor); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[Revoke] check witness error!")
+	}
+	if !grant.Revocable {
+		return utils.BYTE_FALSE, errors.NewErr("[Revoke] grant is not revocable!")
+	}
+	if grant.Revoked {
+		return utils.BYTE_FALSE, errors.NewErr("[Revoke] grant is already revoked!")
+	}
+	vested := vestedAmount(grant, currentTime(native, &grant.Schedule))
+	refund := grant.Amount - vested
+	if refund == 0 {
+		return utils.BYTE_FALSE, errors.NewErr("[Revoke] grant is fully released!")
+	}
+	grant.Amount = vested
+	grant.Revoked = true
+	if err := putGrant(native, contract, grantID, grant); err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	if err := appCallTransfer(native, grant.Asset, contract, grant.Grantor, refund); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[Revoke] refund error!")
+	}
+	addRevokeNotification(native, contract, grantID, grant.Grantor, refund)
+	return utils.BYTE_TRUE, nil
+}
+
+func GetGrant(native *native.NativeService) ([]byte, error) {
+	grantID, err := utils.ReadVarUint(bytes.NewBuffer(native.Input))
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[GetGrant] get grant id error!")
+	}
+	grant, err := getGrant(native, native.ContextRef.CurrentContext().ContractAddress, grantID)
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	bf := new(bytes.Buffer)
+	if err := grant.Serialize(bf); err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	return bf.Bytes(), nil
+}
+
+// GetClaimable returns the amount the beneficiary can claim at current block
+func GetClaimable(native *native.NativeService) ([]byte, error) {
+	grantID, err := utils.ReadVarUint(bytes.NewBuffer(native.Input))
+	if err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[GetClaimable] get grant id error!")
+	}
+	grant, err := getGrant(native, native.ContextRef.CurrentContext().ContractAddress, grantID)
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	amount := vestedAmount(grant, currentTime(native, &grant.Schedule)) - grant.Claimed
+	return types.BigIntToBytes(new(big.Int).SetUint64(amount)), nil
+}
+
+func GrantsOf(native *native.NativeService) ([]byte, error) {
+	param := new(GrantsOfParam)
+	if err := param.Deserialize(bytes.NewBuffer(native.Input)); err != nil {
+		return utils.BYTE_FALSE, errors.NewDetailErr(err, errors.ErrNoCode, "[GrantsOf] param deserialize error!")
+	}
+	list, err := getBeneficiaryGrants(native, native.ContextRef.CurrentContext().ContractAddress, param)
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	bf := new(bytes.Buffer)
+	if err := list.Serialize(bf); err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	return bf.Bytes(), nil
+}
@@ -0,0 +1,184 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package vesting
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/common/config"
+	scommon "github.com/imZhuFei/zeepin/core/store/common"
+	"github.com/imZhuFei/zeepin/embed/simulator/types"
+	"github.com/imZhuFei/zeepin/smartcontract/context"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/gala"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/testutil"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/utils"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/zpt"
+	"github.com/stretchr/testify/assert"
+)
+
+// invokeID calls a handler of vesting contract taking a grant id
+func invokeID(t *testing.T, srvc *native.NativeService, ctx *testutil.Context, handler native.Handler, grantID uint64) ([]byte, error) {
+	ctx.Reset(utils.VestingContractAddress)
+	bf := new(bytes.Buffer)
+	assert.Nil(t, utils.WriteVarUint(bf, grantID))
+	srvc.Input = bf.Bytes()
+	return handler(srvc)
+}
+
+func galaBalance(t *testing.T, srvc *native.NativeService, addr common.Address) uint64 {
+	balance, err := utils.GetStorageUInt64(srvc, zpt.GenBalanceKey(utils.GalaContractAddress, addr))
+	assert.Nil(t, err)
+	return balance
+}
+
+func TestVesting(t *testing.T) {
+	gala.InitGala()
+	InitVesting()
+
+	grantor, beneficiary := common.Address{1}, common.Address{2}
+	ctx := testutil.NewContext(grantor)
+	ctx.PushContext(&context.Context{ContractAddress: utils.VestingContractAddress})
+	srvc, closeStore := testutil.NewNativeService(t, ctx)
+	defer closeStore()
+	srvc.Height = 100
+	config.DefConfig.Common.EnableEventLog = true
+	srvc.CloneCache.Add(scommon.ST_STORAGE, zpt.GenBalanceKey(utils.GalaContractAddress, grantor), utils.GenUInt64StorageItem(1000))
+
+	create := &CreateGrantParam{
+		Grantor:     grantor,
+		Beneficiary: beneficiary,
+		Asset:       utils.GalaContractAddress,
+		Amount:      1000,
+		Schedule:    Schedule{TimeUnit: TIME_UNIT_HEIGHT, Start: 100, Cliff: 10, Duration: 100},
+		Revocable:   true,
+	}
+	ret, err := testutil.Invoke(t, srvc, CreateGrant, create)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), types.BigIntFromBytes(ret).Int64())
+	assert.Equal(t, uint64(0), galaBalance(t, srvc, grantor))
+	assert.Equal(t, uint64(1000), galaBalance(t, srvc, utils.VestingContractAddress))
+
+	// not enough balance for another grant
+	_, err = testutil.Invoke(t, srvc, CreateGrant, create)
+	assert.NotNil(t, err)
+
+	// nothing released before the cliff, only the beneficiary can claim
+	ctx.Sign(beneficiary)
+	srvc.Height = 105
+	_, err = invokeID(t, srvc, ctx, Claim, 1)
+	assert.NotNil(t, err)
+	srvc.Height = 150
+	ret, err = invokeID(t, srvc, ctx, GetClaimable, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(500), types.BigIntFromBytes(ret).Int64())
+	_, err = invokeID(t, srvc, ctx, Claim, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(500), galaBalance(t, srvc, beneficiary))
+	_, err = invokeID(t, srvc, ctx, Revoke, 1)
+	assert.NotNil(t, err)
+
+	// the grantor takes back the part not released
+	ctx.Sign(grantor)
+	srvc.Height = 175
+	_, err = invokeID(t, srvc, ctx, Revoke, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(250), galaBalance(t, srvc, grantor))
+	_, err = invokeID(t, srvc, ctx, Revoke, 1)
+	assert.NotNil(t, err)
+
+	// the released part stays claimable after revocation
+	ctx.Sign(beneficiary)
+	srvc.Height = 300
+	_, err = invokeID(t, srvc, ctx, Claim, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(750), galaBalance(t, srvc, beneficiary))
+	assert.Equal(t, uint64(0), galaBalance(t, srvc, utils.VestingContractAddress))
+	_, err = invokeID(t, srvc, ctx, Claim, 1)
+	assert.NotNil(t, err)
+
+	ret, err = invokeID(t, srvc, ctx, GetGrant, 1)
+	assert.Nil(t, err)
+	grant := new(Grant)
+	assert.Nil(t, grant.Deserialize(bytes.NewBuffer(ret)))
+	assert.Equal(t, uint64(750), grant.Amount)
+	assert.Equal(t, uint64(750), grant.Claimed)
+	assert.True(t, grant.Revoked)
+
+	// a grant not revocable
+	ctx.Sign(beneficiary)
+	create = &CreateGrantParam{
+		Grantor:     beneficiary,
+		Beneficiary: beneficiary,
+		Asset:       utils.GalaContractAddress,
+		Amount:      100,
+		Schedule:    Schedule{TimeUnit: TIME_UNIT_TIMESTAMP, Start: 1000, Duration: 100, Step: 50},
+	}
+	ret, err = testutil.Invoke(t, srvc, CreateGrant, create)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), types.BigIntFromBytes(ret).Int64())
+	_, err = invokeID(t, srvc, ctx, Revoke, 2)
+	assert.NotNil(t, err)
+	srvc.Time = 1099
+	ret, err = invokeID(t, srvc, ctx, GetClaimable, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(50), types.BigIntFromBytes(ret).Int64())
+
+	ret, err = testutil.Invoke(t, srvc, GrantsOf, &GrantsOfParam{Beneficiary: beneficiary, Limit: 1})
+	assert.Nil(t, err)
+	list := new(GrantList)
+	assert.Nil(t, list.Deserialize(bytes.NewBuffer(ret)))
+	assert.Equal(t, &GrantList{GrantIDs: []uint64{2}, Next: 1}, list)
+}
+
+func TestCheckCreateGrantParam(t *testing.T) {
+	param := &CreateGrantParam{
+		Beneficiary: common.Address{2},
+		Asset:       utils.ZptContractAddress,
+		Amount:      1,
+		Schedule:    Schedule{Start: 10, Cliff: 5, Duration: 10, Step: 10},
+	}
+	assert.Nil(t, checkCreateGrantParam(param))
+	param.Asset = utils.NFTContractAddress
+	assert.NotNil(t, checkCreateGrantParam(param))
+	param.Asset = utils.GalaContractAddress
+	param.Schedule.Cliff = 11
+	assert.NotNil(t, checkCreateGrantParam(param))
+	param.Schedule.Cliff = 0
+	param.Schedule.Start = 0xffffffff
+	assert.NotNil(t, checkCreateGrantParam(param))
+	param.Schedule.Start = 0
+	param.Schedule.TimeUnit = 2
+	assert.NotNil(t, checkCreateGrantParam(param))
+}
+
+func TestRegisterVestingContract(t *testing.T) {
+	networkId := config.DefConfig.P2PNode.NetworkId
+	defer func() { config.DefConfig.P2PNode.NetworkId = networkId }()
+
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_MAIN_NET
+	srvc := &native.NativeService{ServiceMap: make(map[string]native.Handler), Height: 10}
+	RegisterVestingContract(srvc)
+	assert.Empty(t, srvc.ServiceMap)
+
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_SOLO_NET
+	RegisterVestingContract(srvc)
+	assert.Contains(t, srvc.ServiceMap, CREATE_GRANT_NAME)
+}
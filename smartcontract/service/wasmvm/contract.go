@@ -0,0 +1,141 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package wasmvm
+
+import (
+	"bytes"
+
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/core/payload"
+	scommon "github.com/imZhuFei/zeepin/core/store/common"
+	"github.com/imZhuFei/zeepin/core/types"
+	"github.com/imZhuFei/zeepin/errors"
+	"github.com/imZhuFei/zeepin/smartcontract/event"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/embed"
+	"github.com/imZhuFei/zeepin/vm/wasmvm/exec"
+	"github.com/imZhuFei/zeepin/vm/wasmvm/util"
+)
+
+// contractMigrate
+// migrate current contract to a new wasm contract, move its storage and destroy it
+// need 7 parameters
+//0: new contract code in hex
+//1: need storage
+//2: name
+//3: version
+//4: author
+//5: email
+//6: description
+func (this *WasmVmService) contractMigrate(engine *exec.ExecutionEngine) (bool, error) {
+	vm := engine.GetVM()
+	envCall := vm.GetEnvCall()
+	params := envCall.GetParams()
+	if len(params) != 7 {
+		return false, errors.NewErr("[contractMigrate] parameter count error")
+	}
+	hexCode, err := vm.GetPointerMemory(params[0])
+	if err != nil {
+		return false, err
+	}
+	code, err := common.HexToBytes(util.TrimBuffToString(hexCode))
+	if err != nil {
+		return false, errors.NewErr("[contractMigrate] contract code invalid:" + err.Error())
+	}
+	if len(code) > 1024*1024 {
+		return false, errors.NewErr("[contractMigrate] Code too long!")
+	}
+	if !bytes.HasPrefix(code, wasmMagic) {
+		return false, errors.NewErr("[contractMigrate] Code is not wasm!")
+	}
+	if err := VerifyCode(code, this.Height); err != nil {
+		return false, err
+	}
+	fields := make([]string, 5)
+	limits := []int{252, 252, 252, 252, 65536}
+	for i := range fields {
+		field, err := vm.GetPointerMemory(params[i+2])
+		if err != nil {
+			return false, err
+		}
+		fields[i] = util.TrimBuffToString(field)
+		if len(fields[i]) > limits[i] {
+			return false, errors.NewErr("[contractMigrate] contract parameters too long!")
+		}
+	}
+	contract := &payload.DeployCode{
+		Code:        code,
+		NeedStorage: params[1] != 0,
+		Name:        fields[0],
+		Version:     fields[1],
+		Author:      fields[2],
+		Email:       fields[3],
+		Description: fields[4],
+	}
+
+	oldAddress := this.ContextRef.CurrentContext().ContractAddress
+	newAddress := types.AddressFromVmCode(code)
+	item, err := this.CloneCache.Get(scommon.ST_CONTRACT, newAddress[:])
+	if err != nil || item != nil {
+		return false, exec.Trap(errors.NewErr("[contractMigrate] get contract error or contract exist!"))
+	}
+
+	this.CloneCache.Add(scommon.ST_CONTRACT, newAddress[:], contract)
+	if err := embed.MigrateContractStorage(this.CloneCache, oldAddress, newAddress); err != nil {
+		return false, exec.Trap(errors.NewDetailErr(err, errors.ErrNoCode, "[contractMigrate] contract store migration error!"))
+	}
+	this.CloneCache.Delete(scommon.ST_CONTRACT, oldAddress[:])
+	exec.DefModuleCache.Invalidate(oldAddress)
+	this.Notifications = append(this.Notifications, &event.NotifyEventInfo{
+		ContractAddress: oldAddress,
+		States:          []interface{}{"migrate", oldAddress.ToHexString(), newAddress.ToHexString()},
+	})
+
+	idx, err := vm.SetPointerMemory(common.ToHexString(newAddress[:]))
+	if err != nil {
+		return false, err
+	}
+	vm.RestoreCtx()
+	if envCall.GetReturns() {
+		vm.PushResult(uint64(idx))
+	}
+	return true, nil
+}
+
+// contractDestroy
+// destroy current contract and delete all its storage
+func (this *WasmVmService) contractDestroy(engine *exec.ExecutionEngine) (bool, error) {
+	vm := engine.GetVM()
+	address := this.ContextRef.CurrentContext().ContractAddress
+	item, err := this.CloneCache.Get(scommon.ST_CONTRACT, address[:])
+	if err != nil || item == nil {
+		return false, exec.Trap(errors.NewErr("[contractDestroy] get current contract fail!"))
+	}
+
+	this.CloneCache.Delete(scommon.ST_CONTRACT, address[:])
+	exec.DefModuleCache.Invalidate(address)
+	if err := embed.DestroyContractStorage(this.CloneCache, address); err != nil {
+		return false, exec.Trap(errors.NewDetailErr(err, errors.ErrNoCode, "[contractDestroy] delete storage error!"))
+	}
+	this.Notifications = append(this.Notifications, &event.NotifyEventInfo{
+		ContractAddress: address,
+		States:          []interface{}{"destroy", address.ToHexString()},
+	})
+	vm.RestoreCtx()
+	return true, nil
+}
@@ -0,0 +1,129 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package wasmvm
+
+import (
+	"sync"
+
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/embed"
+	"github.com/imZhuFei/zeepin/vm/wasmvm/exec"
+)
+
+var (
+	//Gas Limit
+	WASM_BASE_GAS        uint64 = 1
+	WASM_ARITH_GAS       uint64 = 1
+	WASM_MUL_GAS         uint64 = 2
+	WASM_DIV_GAS         uint64 = 4
+	WASM_MEMORY_GAS      uint64 = 2
+	WASM_BRANCH_GAS      uint64 = 2
+	WASM_CALL_GAS        uint64 = 10
+	WASM_MEMORY_PAGE_GAS uint64 = 1000
+	WASM_HOST_CALL_GAS   uint64 = 10
+
+	// Gas Name
+	WASM_BASE_NAME        = "Wasm.Base.Gas"
+	WASM_ARITH_NAME       = "Wasm.Arith.Gas"
+	WASM_MUL_NAME         = "Wasm.Mul.Gas"
+	WASM_DIV_NAME         = "Wasm.Div.Gas"
+	WASM_MEMORY_NAME      = "Wasm.Memory.Gas"
+	WASM_BRANCH_NAME      = "Wasm.Branch.Gas"
+	WASM_CALL_NAME        = "Wasm.Call.Gas"
+	WASM_MEMORY_PAGE_NAME = "Wasm.MemoryPage.Gas"
+	WASM_HOST_CALL_NAME   = "Wasm.HostCall.Gas"
+
+	// WASM_GAS_TABLE hold the instruction prices of wasm vm, it is refreshed
+	// from the global params contract like the embed.GAS_TABLE
+	WASM_GAS_TABLE = initWasmGasTable()
+
+	WASM_GAS_TABLE_KEYS = []string{
+		WASM_BASE_NAME,
+		WASM_ARITH_NAME,
+		WASM_MUL_NAME,
+		WASM_DIV_NAME,
+		WASM_MEMORY_NAME,
+		WASM_BRANCH_NAME,
+		WASM_CALL_NAME,
+		WASM_MEMORY_PAGE_NAME,
+		WASM_HOST_CALL_NAME,
+	}
+
+	// host services which cost the same gas as their embedded vm syscall
+	hostGasNames = map[string]string{
+		"ZPT_Storage_Get":                  embed.STORAGE_GET_NAME,
+		"ZPT_Storage_Delete":               embed.STORAGE_DELETE_NAME,
+		"ZPT_Storage_Find":                 embed.STORAGE_GET_NAME, //and embed.FindGasCost for the items found
+		"ZPT_Runtime_CheckWitness":         embed.RUNTIME_CHECKWITNESS_NAME,
+		"ZPT_BlockChain_GetHeaderByHeight": embed.BLOCKCHAIN_GETHEADER_NAME,
+		"ZPT_BlockChain_GetHeaderByHash":   embed.BLOCKCHAIN_GETHEADER_NAME,
+		"ZPT_BlockChain_GetBlockByHeight":  embed.BLOCKCHAIN_GETBLOCK_NAME,
+		"ZPT_BlockChain_GetBlockByHash":    embed.BLOCKCHAIN_GETBLOCK_NAME,
+		"ZPT_BlockChain_GetContract":       embed.BLOCKCHAIN_GETCONTRACT_NAME,
+		"ZPT_Block_GetTransactionByHash":   embed.BLOCKCHAIN_GETTRANSACTION_NAME,
+		"ZPT_Contract_Migrate":             embed.CONTRACT_MIGRATE_NAME,
+	}
+)
+
+func initWasmGasTable() *sync.Map {
+	m := sync.Map{}
+	m.Store(WASM_BASE_NAME, WASM_BASE_GAS)
+	m.Store(WASM_ARITH_NAME, WASM_ARITH_GAS)
+	m.Store(WASM_MUL_NAME, WASM_MUL_GAS)
+	m.Store(WASM_DIV_NAME, WASM_DIV_GAS)
+	m.Store(WASM_MEMORY_NAME, WASM_MEMORY_GAS)
+	m.Store(WASM_BRANCH_NAME, WASM_BRANCH_GAS)
+	m.Store(WASM_CALL_NAME, WASM_CALL_GAS)
+	m.Store(WASM_MEMORY_PAGE_NAME, WASM_MEMORY_PAGE_GAS)
+	m.Store(WASM_HOST_CALL_NAME, WASM_HOST_CALL_GAS)
+
+	return &m
+}
+
+// GasTable return the gas table of the wasm vm currently in effect
+func GasTable() *exec.GasTable {
+	table := &exec.GasTable{
+		Base:       loadGas(WASM_GAS_TABLE, WASM_BASE_NAME),
+		Arith:      loadGas(WASM_GAS_TABLE, WASM_ARITH_NAME),
+		Mul:        loadGas(WASM_GAS_TABLE, WASM_MUL_NAME),
+		Div:        loadGas(WASM_GAS_TABLE, WASM_DIV_NAME),
+		Memory:     loadGas(WASM_GAS_TABLE, WASM_MEMORY_NAME),
+		Branch:     loadGas(WASM_GAS_TABLE, WASM_BRANCH_NAME),
+		Call:       loadGas(WASM_GAS_TABLE, WASM_CALL_NAME),
+		MemoryPage: loadGas(WASM_GAS_TABLE, WASM_MEMORY_PAGE_NAME),
+		HostCall:   loadGas(WASM_GAS_TABLE, WASM_HOST_CALL_NAME),
+		HostCalls:  make(map[string]uint64, len(hostGasNames)),
+	}
+	for service, name := range hostGasNames {
+		table.HostCalls[service] = loadGas(embed.GAS_TABLE, name)
+	}
+	return table
+}
+
+// storeGasCost return the gas of putting key and value into storage,
+// priced per kilobyte like the embedded vm System.Storage.Put syscall
+func storeGasCost(key, value []byte) uint64 {
+	return uint64((len(key)+len(value)-1)/1024+1) * loadGas(embed.GAS_TABLE, embed.STORAGE_PUT_NAME)
+}
+
+func loadGas(table *sync.Map, name string) uint64 {
+	if value, ok := table.Load(name); ok {
+		return value.(uint64)
+	}
+	return embed.OPCODE_GAS
+}
@@ -38,6 +38,7 @@ import (
 	"github.com/imZhuFei/zeepin/core/signature"
 	"github.com/imZhuFei/zeepin/errors"
 	"github.com/imZhuFei/zeepin/smartcontract/event"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/embed"
 	"github.com/imZhuFei/zeepin/vm/wasmvm/exec"
 	"github.com/imZhuFei/zeepin/vm/wasmvm/util"
 )
@@ -119,6 +120,31 @@ func (this *WasmVmService) runtimeNotify(engine *exec.ExecutionEngine) (bool, er
 	return true, nil
 }
 
+// runtimeRegisterEventSchema
+// register the event schema in json of current contract
+func (this *WasmVmService) runtimeRegisterEventSchema(engine *exec.ExecutionEngine) (bool, error) {
+	vm := engine.GetVM()
+	envCall := vm.GetEnvCall()
+	params := envCall.GetParams()
+	if len(params) != 1 {
+		return false, errors.NewErr("[RuntimeRegisterEventSchema]parameter count error ")
+	}
+	item, err := vm.GetPointerMemory(params[0])
+	if err != nil {
+		return false, err
+	}
+	data := []byte(util.TrimBuffToString(item))
+	if err := engine.UseGas(storeGasCost(data, nil)); err != nil {
+		return false, err
+	}
+	context := this.ContextRef.CurrentContext()
+	if err := embed.RegisterEventSchema(this.CloneCache, context.ContractAddress, data); err != nil {
+		return false, exec.Trap(err)
+	}
+	vm.RestoreCtx()
+	return true, nil
+}
+
 func (this *WasmVmService) runtimeCheckWitness(engine *exec.ExecutionEngine) (bool, error) {
 	vm := engine.GetVM()
 
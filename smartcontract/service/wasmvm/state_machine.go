@@ -56,6 +56,7 @@ type ParamType byte
 const (
 	Json ParamType = iota
 	Raw
+	Binary
 )
 
 type WasmStateMachine struct {
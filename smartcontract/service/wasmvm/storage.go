@@ -35,11 +35,14 @@ package wasmvm
 
 import (
 	"bytes"
+	"sort"
 
 	"github.com/imZhuFei/zeepin/common"
 	"github.com/imZhuFei/zeepin/core/states"
 	scommon "github.com/imZhuFei/zeepin/core/store/common"
 	"github.com/imZhuFei/zeepin/errors"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/embed"
+	"github.com/imZhuFei/zeepin/smartcontract/storage"
 	"github.com/imZhuFei/zeepin/vm/wasmvm/exec"
 	"github.com/imZhuFei/zeepin/vm/wasmvm/memory"
 	"github.com/imZhuFei/zeepin/vm/wasmvm/util"
@@ -58,7 +61,7 @@ func (this *WasmVmService) putstore(engine *exec.ExecutionEngine) (bool, error)
 	if err != nil {
 		return false, err
 	}
-	if len(key) > 1024 {
+	if len(key) > MAX_STORAGE_KEY_LEN {
 		return false, errors.NewErr("[putstore] Get Storage key to long")
 	}
 
@@ -66,6 +69,9 @@ func (this *WasmVmService) putstore(engine *exec.ExecutionEngine) (bool, error)
 	if err != nil {
 		return false, err
 	}
+	if err := engine.UseGas(storeGasCost(key, value)); err != nil {
+		return false, err
+	}
 	k, err := serializeStorageKey(vm.ContractAddress, []byte(util.TrimBuffToString(key)))
 	if err != nil {
 		return false, err
@@ -144,6 +150,132 @@ func (this *WasmVmService) deletestore(engine *exec.ExecutionEngine) (bool, erro
 	return true, nil
 }
 
+func (this *WasmVmService) findstore(engine *exec.ExecutionEngine) (bool, error) {
+	vm := engine.GetVM()
+	envCall := vm.GetEnvCall()
+	params := envCall.GetParams()
+
+	if len(params) != 1 {
+		return false, errors.NewErr("[findstore] parameter count error")
+	}
+
+	prefix, err := vm.GetPointerMemory(params[0])
+	if err != nil {
+		return false, err
+	}
+	if len(this.iterators) >= MAX_ITERATORS {
+		return false, errors.NewErr("[findstore] too many iterators")
+	}
+	items, err := findStorage(this.CloneCache, vm.ContractAddress, []byte(util.TrimBuffToString(prefix)), func() error {
+		return engine.UseGas(embed.FindGasCost(1))
+	})
+	if err != nil {
+		return false, err
+	}
+	this.iterators = append(this.iterators, storage.NewIterator(items))
+
+	vm.RestoreCtx()
+	if envCall.GetReturns() {
+		vm.PushResult(uint64(len(this.iterators) - 1))
+	}
+	return true, nil
+}
+
+func (this *WasmVmService) iteratorNext(engine *exec.ExecutionEngine) (bool, error) {
+	vm := engine.GetVM()
+	envCall := vm.GetEnvCall()
+	iterator, err := this.getIterator(envCall.GetParams())
+	if err != nil {
+		return false, err
+	}
+
+	var next uint64
+	if iterator.Next() {
+		next = 1
+	}
+	vm.RestoreCtx()
+	if envCall.GetReturns() {
+		vm.PushResult(next)
+	}
+	return true, nil
+}
+
+func (this *WasmVmService) iteratorKey(engine *exec.ExecutionEngine) (bool, error) {
+	vm := engine.GetVM()
+	envCall := vm.GetEnvCall()
+	iterator, err := this.getIterator(envCall.GetParams())
+	if err != nil {
+		return false, err
+	}
+	return pushIteratorData(vm, envCall, iterator.Key())
+}
+
+func (this *WasmVmService) iteratorValue(engine *exec.ExecutionEngine) (bool, error) {
+	vm := engine.GetVM()
+	envCall := vm.GetEnvCall()
+	iterator, err := this.getIterator(envCall.GetParams())
+	if err != nil {
+		return false, err
+	}
+	return pushIteratorData(vm, envCall, iterator.Value())
+}
+
+func (this *WasmVmService) getIterator(params []uint64) (*storage.Iterator, error) {
+	if len(params) != 1 {
+		return nil, errors.NewErr("[getIterator] parameter count error")
+	}
+	if params[0] >= uint64(len(this.iterators)) {
+		return nil, errors.NewErr("[getIterator] invalid iterator")
+	}
+	return this.iterators[params[0]], nil
+}
+
+// findStorage return the storage items of contract whose key has prefix, sorted by key.
+// Storage keys are serialized with their length, so the storage of contract is scanned once,
+// and every item scanned is charged before it's decoded. The scan stops when charge fails
+func findStorage(cache *storage.CloneCache, address common.Address, prefix []byte,
+	charge func() error) ([]*scommon.StateItem, error) {
+	var items []*scommon.StateItem
+	err := cache.Iterate(scommon.ST_STORAGE, address[:], func(item *scommon.StateItem) error {
+		if err := charge(); err != nil {
+			return err
+		}
+		storageKey := new(states.StorageKey)
+		if err := storageKey.Deserialize(bytes.NewBufferString(item.Key)); err != nil {
+			return err
+		}
+		if bytes.HasPrefix(storageKey.Key, prefix) {
+			items = append(items, &scommon.StateItem{Key: string(storageKey.Key), Value: item.Value})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Key < items[j].Key })
+	return items, nil
+}
+
+func pushIteratorData(vm *exec.VM, envCall *exec.EnvCall, data []byte) (bool, error) {
+	if data == nil {
+		vm.RestoreCtx()
+		if envCall.GetReturns() {
+			vm.PushResult(uint64(memory.VM_NIL_POINTER))
+		}
+		return true, nil
+	}
+	idx, err := vm.SetPointerMemory(data)
+	if err != nil {
+		return false, err
+	}
+
+	vm.RestoreCtx()
+	if envCall.GetReturns() {
+		vm.PushResult(uint64(idx))
+	}
+	return true, nil
+}
+
 func serializeStorageKey(contractAddress common.Address, key []byte) ([]byte, error) {
 	bf := new(bytes.Buffer)
 	storageKey := &states.StorageKey{ContractAddress: contractAddress, Key: key}
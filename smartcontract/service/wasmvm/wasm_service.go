@@ -36,23 +36,41 @@ package wasmvm
 import (
 	"bytes"
 	"encoding/binary"
+	"encoding/json"
 	"fmt"
 	"math/big"
 	"strconv"
 	"strings"
 
 	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/common/config"
 	"github.com/imZhuFei/zeepin/core/store"
 	"github.com/imZhuFei/zeepin/core/types"
 	"github.com/imZhuFei/zeepin/embed/simulator"
+	ntypes "github.com/imZhuFei/zeepin/embed/simulator/types"
 	"github.com/imZhuFei/zeepin/errors"
 	"github.com/imZhuFei/zeepin/smartcontract/context"
 	"github.com/imZhuFei/zeepin/smartcontract/event"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/embed"
 	nstates "github.com/imZhuFei/zeepin/smartcontract/service/native/zpt"
 	"github.com/imZhuFei/zeepin/smartcontract/states"
 	"github.com/imZhuFei/zeepin/smartcontract/storage"
 	"github.com/imZhuFei/zeepin/vm/wasmvm/exec"
+	"github.com/imZhuFei/zeepin/vm/wasmvm/memory"
 	"github.com/imZhuFei/zeepin/vm/wasmvm/util"
+	"github.com/imZhuFei/zeepin/vm/wasmvm/validate"
+)
+
+const (
+	MAX_STORAGE_KEY_LEN = 1024 //max length of storage key
+	MAX_ITERATORS       = 1024 //max storage iterators opened by an invocation of contract
+)
+
+var (
+	ERR_GAS_INSUFFICIENT = errors.NewErr("[WasmVmService] gas insufficient")
+
+	wasmMagic = []byte{0x00, 0x61, 0x73, 0x6d}
 )
 
 type WasmVmService struct {
@@ -64,20 +82,93 @@ type WasmVmService struct {
 	Tx            *types.Transaction
 	Time          uint32
 	Height        uint32
+	Tracer        exec.Tracer
+
+	iterators []*storage.Iterator
+	version   byte
 }
 
 func (this *WasmVmService) Invoke() (interface{}, error) {
+	stateMachine := this.newStateMachine()
+
+	engine := exec.NewExecutionEngine(
+		this.Tx,
+		new(util.ECDsaCrypto),
+		stateMachine,
+	)
+	if isGasHeight(this.Height) {
+		engine.SetGasMeter(this.ContextRef, GasTable())
+	}
+	if this.Tracer != nil {
+		engine.SetTracer(this.Tracer)
+	}
+	if isVerifyHeight(this.Height) {
+		engine.SetMaxMemoryPages(validate.ConsensusProfile.MaxMemoryPages)
+	}
+
+	contract := &states.Contract{}
+	contract.Deserialize(bytes.NewBuffer(this.Code))
+	addr := contract.Address
+	dpcode, err := this.GetContractCodeFromAddress(addr)
+	if err != nil {
+		errStr := err.Error()
+		fmt.Printf("err %s %s\n", errStr, addr.ToHexString())
+		return nil, fmt.Errorf("get contract  error: %s", addr.ToHexString())
+	}
+	ccode := dpcode
+
+	var caller common.Address
+	if this.ContextRef.CallingContext() == nil {
+		caller = common.Address{}
+	} else {
+		caller = this.ContextRef.CallingContext().ContractAddress
+	}
+	this.version = contract.Version
+	this.ContextRef.PushContext(&context.Context{ContractAddress: contract.Address})
+	res, err := engine.Call(caller, ccode, contract.Method, contract.Args, contract.Version)
+
+	if err != nil {
+		this.ContextRef.PopContext()
+		if err == exec.ErrOutOfGas {
+			return nil, ERR_GAS_INSUFFICIENT
+		}
+		return nil, err
+	}
+
+	//get the return message
+	result, err := engine.GetVM().GetPointerMemory(uint64(binary.LittleEndian.Uint32(res)))
+	if err != nil {
+		this.ContextRef.PopContext()
+		return nil, err
+	}
+
+	this.ContextRef.PopContext()
+	this.ContextRef.PushNotifications(this.Notifications)
+	return result, nil
+}
+
+// newStateMachine register the host functions provided to the contract
+func (this *WasmVmService) newStateMachine() *WasmStateMachine {
 	stateMachine := NewWasmStateMachine()
 	//register the "CallContract" function
-	//stateMachine.Register("ZPT_CallContract", this.callContract)
+	if isApiHeight(this.Height) {
+		stateMachine.Register("ZPT_CallContract", this.callContract)
+	}
 	stateMachine.Register("ZPT_MarshalNativeParams", this.marshalNativeParams)
 	stateMachine.Register("ZPT_MarshalEmbededParams", this.marshalEmbeddedParams)
+	//binary abi apis
+	if isApiHeight(this.Height) {
+		exec.RegisterAbiService(stateMachine)
+	}
 	//runtime
 	stateMachine.Register("ZPT_Runtime_CheckWitness", this.runtimeCheckWitness)
 	stateMachine.Register("ZPT_Runtime_Notify", this.runtimeNotify)
 	stateMachine.Register("ZPT_Runtime_CheckSig", this.runtimeCheckSig)
 	stateMachine.Register("ZPT_Runtime_GetTime", this.runtimeGetTime)
 	stateMachine.Register("ZPT_Runtime_Log", this.runtimeLog)
+	if isApiHeight(this.Height) {
+		stateMachine.Register("ZPT_Runtime_RegisterEventSchema", this.runtimeRegisterEventSchema)
+	}
 	//attribute
 	stateMachine.Register("ZPT_Attribute_GetUsage", this.attributeGetUsage)
 	stateMachine.Register("ZPT_Attribute_GetData", this.attributeGetData)
@@ -112,51 +203,57 @@ func (this *WasmVmService) Invoke() (interface{}, error) {
 	stateMachine.Register("ZPT_Storage_Put", this.putstore)
 	stateMachine.Register("ZPT_Storage_Get", this.getstore)
 	stateMachine.Register("ZPT_Storage_Delete", this.deletestore)
+	if isApiHeight(this.Height) {
+		stateMachine.Register("ZPT_Storage_Find", this.findstore)
+		stateMachine.Register("ZPT_Iterator_Next", this.iteratorNext)
+		stateMachine.Register("ZPT_Iterator_Key", this.iteratorKey)
+		stateMachine.Register("ZPT_Iterator_Value", this.iteratorValue)
+	}
+
+	//contract apis
+	if isApiHeight(this.Height) {
+		stateMachine.Register("ZPT_Contract_Migrate", this.contractMigrate)
+		stateMachine.Register("ZPT_Contract_Destroy", this.contractDestroy)
+	}
 
 	//transaction
 	stateMachine.Register("ZPT_Transaction_GetHash", this.transactionGetHash)
 	stateMachine.Register("ZPT_Transaction_GetType", this.transactionGetType)
 	stateMachine.Register("ZPT_Transaction_GetAttributes", this.transactionGetAttributes)
+	return stateMachine
+}
 
-	engine := exec.NewExecutionEngine(
-		this.Tx,
-		new(util.ECDsaCrypto),
-		stateMachine,
-	)
-
-	contract := &states.Contract{}
-	contract.Deserialize(bytes.NewBuffer(this.Code))
-	addr := contract.Address
-	dpcode, err := this.GetContractCodeFromAddress(addr)
-	if err != nil {
-		errStr := err.Error()
-		fmt.Printf("err %s %s\n", errStr, addr.ToHexString())
-		return nil, fmt.Errorf("get contract  error: %s", addr.ToHexString())
+// VerifyCode checks the wasm code against the consensus validation profile,
+// only the functions provided by the host can be imported. Codes of other vms,
+// and codes deployed below the wasm verify height of the network are not checked.
+func VerifyCode(code []byte, height uint32) error {
+	if !bytes.HasPrefix(code, wasmMagic) || !isVerifyHeight(height) {
+		return nil
 	}
-	ccode := dpcode
-
-	var caller common.Address
-	if this.ContextRef.CallingContext() == nil {
-		caller = common.Address{}
-	} else {
-		caller = this.ContextRef.CallingContext().ContractAddress
+	builtin := exec.NewInteropService()
+	stateMachine := (&WasmVmService{Height: height}).newStateMachine()
+	isHostFunc := func(name string) bool {
+		return builtin.Exists(name) || stateMachine.Exists(name)
 	}
-	this.ContextRef.PushContext(&context.Context{ContractAddress: contract.Address})
-	res, err := engine.Call(caller, ccode, contract.Method, contract.Args, contract.Version)
-
-	if err != nil {
-		return nil, err
+	if err := validate.VerifyProfile(code, validate.ConsensusProfile, isHostFunc); err != nil {
+		return errors.NewDetailErr(err, errors.ErrNoCode, "[VerifyCode] wasm code validation failed")
 	}
+	return nil
+}
 
-	//get the return message
-	result, err := engine.GetVM().GetPointerMemory(uint64(binary.LittleEndian.Uint32(res)))
-	if err != nil {
-		return nil, err
-	}
+// isVerifyHeight return whether the wasm validation profile is applied at height
+func isVerifyHeight(height uint32) bool {
+	return height >= config.GetWasmVerifyHeight(config.DefConfig.P2PNode.NetworkId)
+}
 
-	this.ContextRef.PopContext()
-	this.ContextRef.PushNotifications(this.Notifications)
-	return result, nil
+// isGasHeight return whether the wasm execution is charged gas at height
+func isGasHeight(height uint32) bool {
+	return height >= config.GetWasmGasHeight(config.DefConfig.P2PNode.NetworkId)
+}
+
+// isApiHeight return whether the host functions added after genesis are provided at height
+func isApiHeight(height uint32) bool {
+	return height >= config.GetContractApiHeight(config.DefConfig.P2PNode.NetworkId)
 }
 
 func (this *WasmVmService) marshalEmbeddedParams(engine *exec.ExecutionEngine) (bool, error) {
@@ -296,127 +393,188 @@ func (this *WasmVmService) marshalNativeParams(engine *exec.ExecutionEngine) (bo
 }
 
 // callContract
-// need 4 parameters
+// need 3 parameters
 //0: contract address
-//1: contract code
-//2: method name
-//3: args
-/*func (this *WasmVmService) callContract(engine *exec.ExecutionEngine) (bool, error) {
+//1: method name
+//2: args
+// args of native contract are made by ZPT_MarshalNativeParams, args of embedded contract
+// are made by ZPT_MarshalEmbededParams, args of wasm contract are passed through to it
+func (this *WasmVmService) callContract(engine *exec.ExecutionEngine) (bool, error) {
 	vm := engine.GetVM()
 	envCall := vm.GetEnvCall()
 	params := envCall.GetParams()
-	if len(params) != 4 {
-		return false, errors.NewErr("[callContract]parameter count error while call readMessage")
-	}
-	var contractAddress common.Address
-	var contractBytes []byte
-	//get contract address
-	contractAddressIdx := params[0]
-	addr, err := vm.GetPointerMemory(contractAddressIdx)
+	if len(params) != 3 {
+		return false, errors.NewErr("[callContract]parameter count error while call callContract")
+	}
+	addr, err := vm.GetPointerMemory(params[0])
 	if err != nil {
 		return false, errors.NewErr("[callContract]get Contract address failed:" + err.Error())
 	}
+	addrbytes, err := common.HexToBytes(util.TrimBuffToString(addr))
+	if err != nil {
+		return false, errors.NewErr("[callContract]get contract address error:" + err.Error())
+	}
+	contractAddress, err := common.AddressParseFromBytes(addrbytes)
+	if err != nil {
+		return false, errors.NewErr("[callContract]get contract address error:" + err.Error())
+	}
+	methodName, err := vm.GetPointerMemory(params[1])
+	if err != nil {
+		return false, errors.NewErr("[callContract]get Contract methodName failed:" + err.Error())
+	}
+	method := util.TrimBuffToString(methodName)
+	if len(method) > embed.METHOD_LENGTH_LIMIT {
+		return false, errors.NewErr("[callContract]method name too long")
+	}
+	arg, err := vm.GetPointerMemory(params[2])
+	if err != nil {
+		return false, errors.NewErr("[callContract]get Contract arg failed:" + err.Error())
+	}
 
-	if addr != nil {
-		addrbytes, err := common.HexToBytes(util.TrimBuffToString(addr))
+	//the callee run in the context of its own, it fail the caller if it fail
+	var result []byte
+	if _, ok := native.Contracts[contractAddress]; ok {
+		result, err = this.callNativeContract(contractAddress, method, arg)
+	} else {
+		var code []byte
+		code, err = this.GetContractCodeFromAddress(contractAddress)
 		if err != nil {
-			return false, errors.NewErr("[callContract]get contract address error:" + err.Error())
+			return false, exec.Trap(errors.NewErr("[callContract]get contract code failed:" + err.Error()))
 		}
-		contractAddress, err = common.AddressParseFromBytes(addrbytes)
-		if err != nil {
-			return false, errors.NewErr("[callContract]get contract address error:" + err.Error())
+		if bytes.HasPrefix(code, wasmMagic) {
+			result, err = this.callWasmContract(contractAddress, method, arg)
+		} else {
+			result, err = this.callEmbeddedContract(code, arg)
 		}
+	}
+	if err != nil {
+		return false, exec.Trap(err)
+	}
 
+	vm.RestoreCtx()
+	if envCall.GetReturns() {
+		if result == nil {
+			vm.PushResult(uint64(memory.VM_NIL_POINTER))
+			return true, nil
+		}
+		idx, err := vm.SetPointerMemory(result)
+		if err != nil {
+			return false, errors.NewErr("[callContract]SetPointerMemory failed:" + err.Error())
+		}
+		vm.PushResult(uint64(idx))
 	}
+	return true, nil
+}
 
-	//get contract code
-	codeIdx := params[1]
+func (this *WasmVmService) callNativeContract(address common.Address, method string, args []byte) ([]byte, error) {
+	contract := &states.Contract{
+		Address: address,
+		Method:  method,
+		Args:    args,
+	}
+	sink := common.ZeroCopySink{}
+	contract.Serialization(&sink)
 
-	offchainContractCode, err := vm.GetPointerMemory(codeIdx)
+	engine, err := this.ContextRef.NewNativeExecuteEngine(sink.Bytes())
 	if err != nil {
-		return false, errors.NewErr("[callContract]get Contract address failed:" + err.Error())
+		return nil, err
 	}
-	if offchainContractCode != nil {
-		contractBytes, err = common.HexToBytes(util.TrimBuffToString(offchainContractCode))
-		if err != nil {
-			return false, err
+	result, err := engine.Invoke()
+	if err != nil {
+		return nil, err
+	}
+	switch v := result.(type) {
+	case []byte:
+		return v, nil
+	case bool:
+		return []byte(strconv.FormatBool(v)), nil
+	default:
+		return nil, nil
+	}
+}
 
-		}
-		//compute the offchain code address
-		codestring := util.TrimBuffToString(offchainContractCode)
-		contractAddress = GetContractAddress(codestring, vmtypes.WASMVM)
+func (this *WasmVmService) callWasmContract(address common.Address, method string, args []byte) ([]byte, error) {
+	contract := &states.Contract{
+		Version: this.version,
+		Address: address,
+		Method:  method,
+		Args:    args,
+	}
+	bf := new(bytes.Buffer)
+	if err := contract.Serialize(bf); err != nil {
+		return nil, err
 	}
-	//get method
-	methodName, err := vm.GetPointerMemory(params[2])
+	engine, err := this.ContextRef.NewWasmExecuteEngine(bf.Bytes())
 	if err != nil {
-		return false, errors.NewErr("[callContract]get Contract methodName failed:" + err.Error())
+		return nil, err
 	}
-	//get args
-	arg, err := vm.GetPointerMemory(params[3])
-
+	result, err := engine.Invoke()
 	if err != nil {
-		return false, errors.NewErr("[callContract]get Contract arg failed:" + err.Error())
+		return nil, err
 	}
-	this.ContextRef.PushContext(&context.Context{
-		Code:            vm.VMCode,
-		ContractAddress: vm.ContractAddress})
-	result, err := this.ContextRef.AppCall(contractAddress, util.TrimBuffToString(methodName), contractBytes, arg)
+	if result == nil {
+		return nil, nil
+	}
+	return result.([]byte), nil
+}
 
-	this.ContextRef.PopContext()
+func (this *WasmVmService) callEmbeddedContract(code []byte, args []byte) ([]byte, error) {
+	//run the parameter script first, then the contract with the pushed parameters
+	paramEngine, err := this.ContextRef.NewExecuteEngine(args)
 	if err != nil {
-		return false, errors.NewErr("[callContract]AppCall failed:" + err.Error())
+		return nil, err
 	}
-	vm.RestoreCtx()
-	var res string
-	if envCall.GetReturns() {
-		if contractAddress[0] == byte(vmtypes.Embed) {
-			result = sccommon.ConvertEmbeddedReturnTypes(result)
-			switch result.(type) {
-			case int:
-				res = strconv.Itoa(result.(int))
-			case int64:
-				res = strconv.FormatInt(result.(int64), 10)
-			case string:
-				res = result.(string)
-			case []byte:
-				tmp := result.([]byte)
-				if len(tmp) == 1 {
-					if tmp[0] == byte(1) {
-						res = "true"
-					}
-					if tmp[0] == byte(0) {
-						res = "false"
-					}
-				} else {
-					res = string(result.([]byte))
-				}
-			default:
-				res = fmt.Sprintf("%s", result)
-			}
-
-		}
-		if contractAddress[0] == byte(vmtypes.Native) {
-			bresult := result.(bool)
-			if bresult == true {
-				res = "true"
-			} else {
-				res = "false"
-			}
+	paramService := paramEngine.(*embed.EmbeddedService)
+	if _, err := paramService.Invoke(); err != nil {
+		return nil, err
+	}
+	engine, err := this.ContextRef.NewExecuteEngine(code)
+	if err != nil {
+		return nil, err
+	}
+	service := engine.(*embed.EmbeddedService)
+	paramService.Engine.EvaluationStack.CopyTo(service.Engine.EvaluationStack)
+	result, err := service.Invoke()
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, nil
+	}
+	return convertEmbeddedResult(result.(ntypes.StackItems))
+}
 
+// convertEmbeddedResult convert the return value of embedded contract to bytes,
+// integers and booleans are converted to string, arrays to json string array
+func convertEmbeddedResult(item ntypes.StackItems) ([]byte, error) {
+	switch v := item.(type) {
+	case *ntypes.Boolean:
+		b, _ := v.GetBoolean()
+		return []byte(strconv.FormatBool(b)), nil
+	case *ntypes.Integer:
+		i, err := v.GetBigInteger()
+		if err != nil {
+			return nil, err
 		}
-		if contractAddress[0] == byte(vmtypes.WASMVM) {
-			res = fmt.Sprintf("%s", result)
-		}
-
-		idx, err := vm.SetPointerMemory(res)
+		return []byte(i.String()), nil
+	case *ntypes.Array, *ntypes.Struct:
+		items, err := v.GetArray()
 		if err != nil {
-			return false, errors.NewErr("[callContract]SetPointerMemory failed:" + err.Error())
+			return nil, err
 		}
-		vm.PushResult(uint64(idx))
+		list := make([]string, 0, len(items))
+		for _, item := range items {
+			b, err := convertEmbeddedResult(item)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, string(b))
+		}
+		return json.Marshal(list)
+	default:
+		return item.GetByteArray()
 	}
-
-	return true, nil
-}*/
+}
 
 func (this *WasmVmService) GetContractCodeFromAddress(address common.Address) ([]byte, error) {
 
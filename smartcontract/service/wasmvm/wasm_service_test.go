@@ -0,0 +1,79 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package wasmvm
+
+import (
+	"testing"
+
+	"github.com/imZhuFei/zeepin/common/config"
+)
+
+// apiFuncs are the host functions provided from the contract api height
+var apiFuncs = []string{
+	"ZPT_CallContract",
+	"ZPT_Runtime_RegisterEventSchema",
+	"ZPT_Storage_Find",
+	"ZPT_Iterator_Next",
+	"ZPT_Iterator_Key",
+	"ZPT_Iterator_Value",
+	"ZPT_Contract_Migrate",
+	"ZPT_Contract_Destroy",
+	"ZPT_AbiLen",
+	"ZPT_AbiReadBytes",
+	"ZPT_AbiNewEncoder",
+	"ZPT_AbiEncode",
+}
+
+func TestNewStateMachineApiHeight(t *testing.T) {
+	networkId := config.DefConfig.P2PNode.NetworkId
+	defer func() { config.DefConfig.P2PNode.NetworkId = networkId }()
+
+	service := &WasmVmService{Height: 10}
+
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_MAIN_NET
+	sm := service.newStateMachine()
+	for _, name := range apiFuncs {
+		if sm.Exists(name) {
+			t.Errorf("%s should not be registered below the contract api height", name)
+		}
+	}
+
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_SOLO_NET
+	sm = service.newStateMachine()
+	for _, name := range apiFuncs {
+		if !sm.Exists(name) {
+			t.Errorf("%s should be registered from the contract api height", name)
+		}
+	}
+}
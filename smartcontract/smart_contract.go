@@ -46,6 +46,7 @@ import (
 	"github.com/imZhuFei/zeepin/smartcontract/service/native/embed"
 	"github.com/imZhuFei/zeepin/smartcontract/service/wasmvm"
 	"github.com/imZhuFei/zeepin/smartcontract/storage"
+	"github.com/imZhuFei/zeepin/smartcontract/trace"
 )
 
 const (
@@ -61,6 +62,7 @@ type SmartContract struct {
 	Notifications []*event.NotifyEventInfo // all execute smart contract event notify info
 	Gas           uint64
 	ExecStep      int
+	Tracer        *trace.Tracer // record the execution steps when it is set
 }
 
 // Config describe smart contract need parameters configuration
@@ -140,6 +142,10 @@ func (this *SmartContract) NewExecuteEngine(code []byte) (context.Engine, error)
 	if !this.checkContexts() {
 		return nil, fmt.Errorf("%s", "engine over max limit!")
 	}
+	engine := vm.NewExecutionEngine()
+	if this.Tracer != nil {
+		engine.Tracer = this.Tracer.EmbeddedTracer()
+	}
 	service := &embed.EmbeddedService{
 		Store:      this.Store,
 		CloneCache: this.CloneCache,
@@ -148,7 +154,7 @@ func (this *SmartContract) NewExecuteEngine(code []byte) (context.Engine, error)
 		Tx:         this.Config.Tx,
 		Time:       this.Config.Time,
 		Height:     this.Config.Height,
-		Engine:     vm.NewExecutionEngine(),
+		Engine:     engine,
 	}
 	return service, nil
 }
@@ -168,9 +174,17 @@ func (this *SmartContract) NewWasmExecuteEngine(code []byte) (context.Engine, er
 		Time:       this.Config.Time,
 		Height:     this.Config.Height,
 	}
+	if this.Tracer != nil {
+		service.Tracer = this.Tracer.WasmTracer()
+	}
 	return service, nil
 }
 
+// VerifyCode validate the code of contract created or migrated by another contract
+func (this *SmartContract) VerifyCode(code []byte) error {
+	return wasmvm.VerifyCode(code, this.Config.Height)
+}
+
 func (this *SmartContract) NewNativeService() (*native.NativeService, error) {
 	if !this.checkContexts() {
 		return nil, fmt.Errorf("%s", "engine over max limit!")
@@ -186,6 +200,16 @@ func (this *SmartContract) NewNativeService() (*native.NativeService, error) {
 	return service, nil
 }
 
+// NewNativeExecuteEngine return the native service to invoke the native contract called by code
+func (this *SmartContract) NewNativeExecuteEngine(code []byte) (context.Engine, error) {
+	service, err := this.NewNativeService()
+	if err != nil {
+		return nil, err
+	}
+	service.Code = code
+	return service, nil
+}
+
 // CheckWitness check whether authorization correct
 // If address is wallet address, check whether in the signature addressed list
 // Else check whether address is calling contract address
@@ -35,6 +35,9 @@
 package storage
 
 import (
+	"sort"
+	"strings"
+
 	"github.com/imZhuFei/zeepin/core/states"
 	"github.com/imZhuFei/zeepin/core/store/common"
 )
@@ -142,3 +145,32 @@ func (this *CloneCache) Delete(prefix common.DataEntryPrefix, key []byte) {
 		}
 	}
 }
+
+// Find return all items whose key start with key by Iterate, so uncommitted items in cache override
+// the items in store, and the result is sorted by key
+func (this *CloneCache) Find(prefix common.DataEntryPrefix, key []byte) ([]*common.StateItem, error) {
+	var items []*common.StateItem
+	err := this.Iterate(prefix, key, func(item *common.StateItem) error {
+		items = append(items, item)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// Iterate call fn with the items whose key start with key in key order, until fn return error.
+// Uncommitted items in cache override the items in store, which are read lazily
+func (this *CloneCache) Iterate(prefix common.DataEntryPrefix, key []byte, fn func(item *common.StateItem) error) error {
+	var overlay []*common.StateItem
+	for _, v := range this.Memory {
+		if v.Prefix == prefix && strings.HasPrefix(v.Key, string(key)) {
+			overlay = append(overlay, &common.StateItem{Key: v.Key, Value: v.Value, State: v.State})
+		}
+	}
+	sort.Slice(overlay, func(i, j int) bool { return overlay[i].Key < overlay[j].Key })
+	return common.OverlayIterate(overlay, func(fn func(item *common.StateItem) error) error {
+		return this.Store.Iterate(prefix, key, fn)
+	}, fn)
+}
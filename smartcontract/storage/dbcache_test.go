@@ -0,0 +1,146 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package storage
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/imZhuFei/zeepin/core/states"
+	"github.com/imZhuFei/zeepin/core/store/common"
+)
+
+type testStateStore struct {
+	items map[string]*common.StateItem
+}
+
+func (this *testStateStore) TryAdd(prefix common.DataEntryPrefix, key []byte, value states.StateValue) {
+	this.items[string(key)] = &common.StateItem{Key: string(key), Value: value, State: common.Changed}
+}
+
+func (this *testStateStore) TryGetOrAdd(prefix common.DataEntryPrefix, key []byte, value states.StateValue) error {
+	return nil
+}
+
+func (this *testStateStore) TryGet(prefix common.DataEntryPrefix, key []byte) (*common.StateItem, error) {
+	return this.items[string(key)], nil
+}
+
+func (this *testStateStore) TryDelete(prefix common.DataEntryPrefix, key []byte) {
+	delete(this.items, string(key))
+}
+
+func (this *testStateStore) Find(prefix common.DataEntryPrefix, key []byte) ([]*common.StateItem, error) {
+	var items []*common.StateItem
+	for k, v := range this.items {
+		if strings.HasPrefix(k, string(key)) {
+			items = append(items, v)
+		}
+	}
+	return items, nil
+}
+
+func (this *testStateStore) Iterate(prefix common.DataEntryPrefix, key []byte, fn func(item *common.StateItem) error) error {
+	items, _ := this.Find(prefix, key)
+	sort.Slice(items, func(i, j int) bool { return items[i].Key < items[j].Key })
+	for _, item := range items {
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestCloneCacheFind(t *testing.T) {
+	store := &testStateStore{items: make(map[string]*common.StateItem)}
+	store.TryAdd(common.ST_STORAGE, []byte("key3"), &states.StorageItem{Value: []byte("v3")})
+	store.TryAdd(common.ST_STORAGE, []byte("key1"), &states.StorageItem{Value: []byte("v1")})
+	store.TryAdd(common.ST_STORAGE, []byte("key2"), &states.StorageItem{Value: []byte("v2")})
+
+	cache := NewCloneCache(store)
+	cache.Add(common.ST_STORAGE, []byte("key0"), &states.StorageItem{Value: []byte("v0")})
+	cache.Add(common.ST_STORAGE, []byte("key1"), &states.StorageItem{Value: []byte("new")})
+	cache.Delete(common.ST_STORAGE, []byte("key2"))
+	cache.Add(common.ST_STORAGE, []byte("other"), &states.StorageItem{Value: []byte("v")})
+
+	items, err := cache.Find(common.ST_STORAGE, []byte("key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	iter := NewIterator(items)
+	var keys, values []string
+	for iter.Next() {
+		keys = append(keys, string(iter.Key()))
+		values = append(values, string(iter.Value()))
+	}
+	if strings.Join(keys, ",") != "key0,key1,key3" {
+		t.Errorf("unexpected keys %v", keys)
+	}
+	if strings.Join(values, ",") != "v0,new,v3" {
+		t.Errorf("unexpected values %v", values)
+	}
+	if iter.Next() || iter.Key() != nil {
+		t.Error("iterator should be exhausted")
+	}
+}
+
+func TestCloneCacheIterate(t *testing.T) {
+	store := &testStateStore{items: make(map[string]*common.StateItem)}
+	store.TryAdd(common.ST_STORAGE, []byte("key3"), &states.StorageItem{Value: []byte("v3")})
+	store.TryAdd(common.ST_STORAGE, []byte("key1"), &states.StorageItem{Value: []byte("v1")})
+	store.TryAdd(common.ST_STORAGE, []byte("key2"), &states.StorageItem{Value: []byte("v2")})
+
+	cache := NewCloneCache(store)
+	cache.Add(common.ST_STORAGE, []byte("key0"), &states.StorageItem{Value: []byte("v0")})
+	cache.Add(common.ST_STORAGE, []byte("key1"), &states.StorageItem{Value: []byte("new")})
+	cache.Delete(common.ST_STORAGE, []byte("key2"))
+	cache.Add(common.ST_STORAGE, []byte("key4"), &states.StorageItem{Value: []byte("v4")})
+
+	var keys, values []string
+	err := cache.Iterate(common.ST_STORAGE, []byte("key"), func(item *common.StateItem) error {
+		keys = append(keys, item.Key)
+		values = append(values, string(item.Value.(*states.StorageItem).Value))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(keys, ",") != "key0,key1,key3,key4" {
+		t.Errorf("unexpected keys %v", keys)
+	}
+	if strings.Join(values, ",") != "v0,new,v3,v4" {
+		t.Errorf("unexpected values %v", values)
+	}
+
+	// the iteration stops at the first error
+	stop := errors.New("stop")
+	keys = nil
+	err = cache.Iterate(common.ST_STORAGE, []byte("key"), func(item *common.StateItem) error {
+		keys = append(keys, item.Key)
+		if len(keys) == 2 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop || strings.Join(keys, ",") != "key0,key1" {
+		t.Errorf("unexpected result %v %v", err, keys)
+	}
+}
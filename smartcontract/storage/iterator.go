@@ -0,0 +1,63 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package storage
+
+import (
+	"github.com/imZhuFei/zeepin/core/states"
+	"github.com/imZhuFei/zeepin/core/store/common"
+)
+
+// Iterator walk through the storage items found by CloneCache.Find
+type Iterator struct {
+	items []*common.StateItem
+	index int
+}
+
+// NewIterator return a iterator positioned before the first item
+func NewIterator(items []*common.StateItem) *Iterator {
+	return &Iterator{items: items, index: -1}
+}
+
+// Next move to next item, return false if there is no more item
+func (this *Iterator) Next() bool {
+	if this.index < len(this.items) {
+		this.index++
+	}
+	return this.index < len(this.items)
+}
+
+// Key return the key of current item
+func (this *Iterator) Key() []byte {
+	if this.index < 0 || this.index >= len(this.items) {
+		return nil
+	}
+	return []byte(this.items[this.index].Key)
+}
+
+// Value return the storage value of current item
+func (this *Iterator) Value() []byte {
+	if this.index < 0 || this.index >= len(this.items) {
+		return nil
+	}
+	item, ok := this.items[this.index].Value.(*states.StorageItem)
+	if !ok {
+		return nil
+	}
+	return item.Value
+}
@@ -0,0 +1,75 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/imZhuFei/zeepin/core/types"
+	"github.com/imZhuFei/zeepin/embed/simulator"
+	httpcom "github.com/imZhuFei/zeepin/http/base/common"
+	. "github.com/imZhuFei/zeepin/smartcontract"
+	scommon "github.com/imZhuFei/zeepin/smartcontract/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmbeddedCompoundParam(t *testing.T) {
+	param := httpcom.EmbeddedStruct{
+		int64(1),
+		"foo",
+		[]byte{1, 2},
+		[]interface{}{int64(2), []interface{}{true}},
+		map[string]interface{}{"b": int64(3), "a": httpcom.EmbeddedStruct{"bar"}},
+	}
+	builder := simulator.NewParamsBuilder(new(bytes.Buffer))
+	err := httpcom.BuildEmbeddedParam(builder, []interface{}{param})
+	assert.Nil(t, err)
+	code := append(builder.ToArray(), byte(simulator.RET))
+
+	config := &Config{
+		Time:   10,
+		Height: 10,
+		Tx:     &types.Transaction{},
+	}
+	sc := SmartContract{
+		Config: config,
+		Gas:    10000,
+	}
+	engine, err := sc.NewExecuteEngine(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := engine.Invoke()
+	assert.Nil(t, err)
+
+	hexStr := func(s string) string { return hex.EncodeToString([]byte(s)) }
+	expect := []interface{}{
+		"01",
+		hexStr("foo"),
+		"0102",
+		[]interface{}{"02", []interface{}{"01"}},
+		map[string]interface{}{
+			hexStr("a"): []interface{}{hexStr("bar")},
+			hexStr("b"): "03",
+		},
+	}
+	assert.Equal(t, expect, scommon.ConvertEmbededTypeHexString(res))
+}
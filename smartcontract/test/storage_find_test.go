@@ -0,0 +1,156 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/core/states"
+	scommon "github.com/imZhuFei/zeepin/core/store/common"
+	"github.com/imZhuFei/zeepin/core/store/leveldbstore"
+	"github.com/imZhuFei/zeepin/core/store/statestore"
+	"github.com/imZhuFei/zeepin/core/types"
+	. "github.com/imZhuFei/zeepin/smartcontract"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native/embed"
+	"github.com/imZhuFei/zeepin/smartcontract/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	// finderCode find the storage items with prefix args
+	finderCode = wasmContract("ZPT_Storage_Find", []byte{0x60, 0x01, 0x7f, 0x01, 0x7f},
+		[]byte{0x20, 0x01, 0x10, 0x00})
+	// loopFinderCode find the storage items with prefix args until it fails
+	loopFinderCode = wasmContract("ZPT_Storage_Find", []byte{0x60, 0x01, 0x7f, 0x01, 0x7f},
+		[]byte{0x03, 0x40, 0x20, 0x01, 0x10, 0x00, 0x1a, 0x0c, 0x00, 0x0b, 0x41, 0x00})
+
+	finderAddress     = types.AddressFromVmCode(finderCode)
+	loopFinderAddress = types.AddressFromVmCode(loopFinderCode)
+)
+
+// newStorageCache return a storage cache holding the items of contract, whose keys are made by storageKey
+func newStorageCache(t *testing.T, keys [][]byte) (*storage.CloneCache, func()) {
+	dir, err := ioutil.TempDir("", "find")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := leveldbstore.NewLevelDBStore(dir)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	cache := storage.NewCloneCache(statestore.NewStateStoreBatch(statestore.NewMemDatabase(), db))
+	for i, key := range keys {
+		cache.Add(scommon.ST_STORAGE, key, &states.StorageItem{Value: []byte{byte(i)}})
+		// the first items are committed, the others are left in cache
+		if i == len(keys)/2 {
+			cache.Commit()
+		}
+	}
+	return cache, func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func wasmStorageKeys(address common.Address, keys ...string) [][]byte {
+	var result [][]byte
+	for _, key := range keys {
+		storageKey := &states.StorageKey{ContractAddress: address, Key: []byte(key)}
+		result = append(result, storageKey.ToArray())
+	}
+	return result
+}
+
+func TestWasmStorageFind(t *testing.T) {
+	others := wasmStorageKeys(calleeAddress, "a2")
+	emptyCache, closeEmpty := newStorageCache(t, others)
+	defer closeEmpty()
+	cache, closeCache := newStorageCache(t, append(wasmStorageKeys(finderAddress, "a1", "ab", "a", "b1", "ba"), others...))
+	defer closeCache()
+
+	find := func(cache *storage.CloneCache, prefix string, gas uint64) (uint64, error) {
+		sc := newWasmCallContract(gas)
+		sc.CloneCache = cache
+		_, err := invokeWasm(sc, finderAddress, "find", []byte(prefix))
+		return gas - sc.Gas, err
+	}
+	// every item of the contract is scanned and charged, the items of other contracts are not
+	empty, err := find(emptyCache, "a", 1000000)
+	assert.Nil(t, err)
+	gas, err := find(cache, "a", 1000000)
+	assert.Nil(t, err)
+	assert.Equal(t, empty+embed.FindGasCost(5), gas)
+	gas, err = find(cache, "z", 1000000)
+	assert.Nil(t, err)
+	assert.Equal(t, empty+embed.FindGasCost(5), gas)
+
+	// the scan stops when gas runs out
+	_, err = find(cache, "a", empty+embed.FindGasCost(4))
+	assert.NotNil(t, err)
+}
+
+func TestWasmStorageFindIterators(t *testing.T) {
+	cache, closeCache := newStorageCache(t, nil)
+	defer closeCache()
+
+	sc := newWasmCallContract(1000000000)
+	sc.CloneCache = cache
+	_, err := invokeWasm(sc, loopFinderAddress, "find", []byte("a"))
+	assert.NotNil(t, err)
+	// the loop is stopped by the iterator cap long before the gas runs out
+	assert.True(t, sc.Gas > 1000000000/2)
+}
+
+func TestEmbeddedStorageFind(t *testing.T) {
+	find := func(prefix byte) []byte {
+		code := []byte{0x01, prefix, 0x68} // PUSHBYTES1 prefix SYSCALL
+		code = append(append(code, byte(len(embed.STORAGE_GETCONTEXT_NAME))), embed.STORAGE_GETCONTEXT_NAME...)
+		code = append(append(append(code, 0x68), byte(len(embed.STORAGE_FIND_NAME))), embed.STORAGE_FIND_NAME...)
+		return append(code, 0x75, 0x51, 0x66) // DROP PUSH1 RET
+	}
+	address := types.AddressFromVmCode(find('a'))
+	var keys [][]byte
+	for _, key := range []string{"a1", "ab", "b1"} {
+		keys = append(keys, append(address[:], key...))
+	}
+	cache, closeCache := newStorageCache(t, keys)
+	defer closeCache()
+
+	gas := func(code []byte) uint64 {
+		sc := SmartContract{
+			Config:     &Config{Time: 10, Height: 10, Tx: &types.Transaction{}},
+			CloneCache: cache,
+			Gas:        1000000,
+		}
+		engine, err := sc.NewExecuteEngine(code)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = engine.Invoke()
+		assert.Nil(t, err)
+		return 1000000 - sc.Gas
+	}
+	// the storage of other contract is not found
+	assert.Equal(t, gas(find('z'))+embed.FindGasCost(2), gas(find('a')))
+	assert.Equal(t, gas(find('z')), gas(find('b')))
+}
@@ -0,0 +1,72 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package test
+
+import (
+	"testing"
+
+	"github.com/imZhuFei/zeepin/core/types"
+	. "github.com/imZhuFei/zeepin/smartcontract"
+	"github.com/imZhuFei/zeepin/smartcontract/trace"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTraceEmbedded(t *testing.T) {
+	code := []byte{0x51, 0x52, 0x93, 0x66} // PUSH1 PUSH2 ADD RET
+	config := &Config{
+		Time:   10,
+		Height: 10,
+		Tx:     &types.Transaction{},
+	}
+
+	tracer := trace.NewTracer(0)
+	sc := SmartContract{
+		Config: config,
+		Gas:    10000,
+		Tracer: tracer,
+	}
+	engine, err := sc.NewExecuteEngine(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = engine.Invoke()
+	assert.Nil(t, err)
+
+	address := types.AddressFromVmCode(code)
+	steps := tracer.Trace().Steps
+	assert.Equal(t, 4, len(steps))
+	assert.Equal(t, "PUSH1", steps[0].Op)
+	assert.Equal(t, int64(0), steps[0].Pc)
+	assert.Equal(t, "ADD", steps[2].Op)
+	assert.Equal(t, []string{"2", "1"}, steps[2].Stack) // top of stack first
+	assert.Equal(t, address.ToHexString(), steps[0].Contract)
+	assert.False(t, tracer.Trace().Truncated)
+
+	tracer = trace.NewTracer(2)
+	sc = SmartContract{
+		Config: config,
+		Gas:    10000,
+		Tracer: tracer,
+	}
+	engine, _ = sc.NewExecuteEngine(code)
+	_, err = engine.Invoke()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(tracer.Trace().Steps))
+	assert.True(t, tracer.Trace().Truncated)
+}
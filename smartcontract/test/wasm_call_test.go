@@ -0,0 +1,209 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/common/config"
+	"github.com/imZhuFei/zeepin/core/payload"
+	"github.com/imZhuFei/zeepin/core/store"
+	"github.com/imZhuFei/zeepin/core/types"
+	. "github.com/imZhuFei/zeepin/smartcontract"
+	"github.com/imZhuFei/zeepin/smartcontract/event"
+	"github.com/imZhuFei/zeepin/smartcontract/service/native"
+	"github.com/imZhuFei/zeepin/smartcontract/states"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMain run the tests on the solo net, where wasm execution is charged gas since genesis
+func TestMain(m *testing.M) {
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_SOLO_NET
+	os.Exit(m.Run())
+}
+
+// section encode a wasm section with its size
+func section(id byte, content ...byte) []byte {
+	return append([]byte{id, byte(len(content))}, content...)
+}
+
+func name(s string) []byte {
+	return append([]byte{byte(len(s))}, s...)
+}
+
+// wasmContract return a contract exporting invoke(method, args i32) i32, which imports the
+// host function of type importType, and runs body
+func wasmContract(importName string, importType []byte, body []byte) []byte {
+	code := []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+	sigs := append(append([]byte{0x02}, importType...), 0x60, 0x02, 0x7f, 0x7f, 0x01, 0x7f)
+	code = append(code, section(0x01, sigs...)...)
+	imports := append(append(append([]byte{0x01}, name("env")...), name(importName)...), 0x00, 0x00)
+	code = append(code, section(0x02, imports...)...)
+	code = append(code, section(0x03, 0x01, 0x01)...)
+	code = append(code, section(0x05, 0x01, 0x00, 0x01)...)
+	code = append(code, section(0x07, append(append([]byte{0x01}, name("invoke")...), 0x00, 0x01)...)...)
+	body = append([]byte{0x00}, append(body, 0x0b)...)
+	code = append(code, section(0x0a, append([]byte{0x01, byte(len(body))}, body...)...)...)
+	return code
+}
+
+var (
+	// callerCode call the contract whose hex address is method, with the same method and args
+	callerCode = wasmContract("ZPT_CallContract", []byte{0x60, 0x03, 0x7f, 0x7f, 0x7f, 0x01, 0x7f},
+		[]byte{0x20, 0x00, 0x20, 0x00, 0x20, 0x01, 0x10, 0x00})
+	// calleeCode notify args and return it
+	calleeCode = wasmContract("ZPT_Runtime_Notify", []byte{0x60, 0x01, 0x7f, 0x00},
+		[]byte{0x20, 0x01, 0x10, 0x00, 0x20, 0x01})
+	// embeddedCode add the pushed parameter by 1
+	embeddedCode = []byte{0x51, 0x93, 0x66} // PUSH1 ADD RET
+
+	callerAddress   = types.AddressFromVmCode(callerCode)
+	calleeAddress   = types.AddressFromVmCode(calleeCode)
+	embeddedAddress = types.AddressFromVmCode(embeddedCode)
+	nativeAddress   = common.Address{0xee}
+)
+
+// contractStore is a ledger store only holding the deployed contracts
+type contractStore struct {
+	store.LedgerStore
+	contracts map[common.Address]*payload.DeployCode
+}
+
+func (this *contractStore) GetContractState(address common.Address) (*payload.DeployCode, error) {
+	contract, ok := this.contracts[address]
+	if !ok {
+		return nil, errors.New("contract not found")
+	}
+	return contract, nil
+}
+
+func newWasmCallContract(gas uint64) *SmartContract {
+	return &SmartContract{
+		Config: &Config{Time: 10, Height: 10, Tx: &types.Transaction{}},
+		Store: &contractStore{contracts: map[common.Address]*payload.DeployCode{
+			callerAddress:     {Code: callerCode},
+			calleeAddress:     {Code: calleeCode},
+			embeddedAddress:   {Code: embeddedCode},
+			finderAddress:     {Code: finderCode},
+			loopFinderAddress: {Code: loopFinderCode},
+		}},
+		Gas: gas,
+	}
+}
+
+func invokeWasm(sc *SmartContract, address common.Address, method string, args []byte) ([]byte, error) {
+	contract := &states.Contract{Version: 1, Address: address, Method: method, Args: args}
+	bf := new(bytes.Buffer)
+	if err := contract.Serialize(bf); err != nil {
+		return nil, err
+	}
+	engine, err := sc.NewWasmExecuteEngine(bf.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	result, err := engine.Invoke()
+	if err != nil {
+		return nil, err
+	}
+	return result.([]byte), nil
+}
+
+func TestWasmCallWasm(t *testing.T) {
+	sc := newWasmCallContract(1000000)
+	result, err := invokeWasm(sc, calleeAddress, common.ToHexString(calleeAddress[:]), []byte("args"))
+	assert.Nil(t, err)
+	assert.Equal(t, "args", string(result))
+	calleeGas := 1000000 - sc.Gas
+
+	sc = newWasmCallContract(1000000)
+	result, err = invokeWasm(sc, callerAddress, common.ToHexString(calleeAddress[:]), []byte("args"))
+	assert.Nil(t, err)
+	assert.Equal(t, "args", string(result))
+	assert.Equal(t, 1, len(sc.Contexts))
+	assert.Equal(t, callerAddress, sc.CurrentContext().ContractAddress)
+	assert.Equal(t, 1, len(sc.Notifications))
+	assert.Equal(t, calleeAddress, sc.Notifications[0].ContractAddress)
+	assert.Equal(t, []string{"args"}, sc.Notifications[0].States)
+	gas := 1000000 - sc.Gas
+	assert.True(t, gas > calleeGas)
+
+	// the callee runs out of the gas left by the caller
+	sc = newWasmCallContract(gas - 1)
+	_, err = invokeWasm(sc, callerAddress, common.ToHexString(calleeAddress[:]), []byte("args"))
+	assert.NotNil(t, err)
+}
+
+func TestWasmGasHeight(t *testing.T) {
+	defer func() { config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_SOLO_NET }()
+
+	// wasm execution is not charged below the wasm gas height
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_MAIN_NET
+	sc := newWasmCallContract(1000000)
+	_, err := invokeWasm(sc, calleeAddress, common.ToHexString(calleeAddress[:]), []byte("args"))
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1000000), sc.Gas)
+}
+
+func TestWasmCallNative(t *testing.T) {
+	method := common.ToHexString(nativeAddress[:])
+	var calling, current common.Address
+	native.Contracts[nativeAddress] = func(srvc *native.NativeService) {
+		srvc.Register(method, func(srvc *native.NativeService) ([]byte, error) {
+			sc := srvc.ContextRef.(*SmartContract)
+			calling = sc.CallingContext().ContractAddress
+			current = sc.CurrentContext().ContractAddress
+			srvc.Notifications = append(srvc.Notifications, &event.NotifyEventInfo{ContractAddress: nativeAddress, States: srvc.Input})
+			return []byte("native"), nil
+		})
+	}
+	defer delete(native.Contracts, nativeAddress)
+
+	sc := newWasmCallContract(1000000)
+	result, err := invokeWasm(sc, callerAddress, method, []byte("args"))
+	assert.Nil(t, err)
+	assert.Equal(t, "native", string(result))
+	assert.Equal(t, callerAddress, calling)
+	assert.Equal(t, nativeAddress, current)
+	assert.Equal(t, 1, len(sc.Contexts))
+	assert.Equal(t, 1, len(sc.Notifications))
+	assert.Equal(t, []byte("args"), sc.Notifications[0].States)
+}
+
+func TestWasmCallEmbedded(t *testing.T) {
+	sc := newWasmCallContract(1000000)
+	// the args are the parameter script of embedded contract
+	result, err := invokeWasm(sc, callerAddress, common.ToHexString(embeddedAddress[:]), []byte{0x52}) // PUSH2
+	assert.Nil(t, err)
+	assert.Equal(t, "3", string(result))
+	assert.Equal(t, 1, len(sc.Contexts))
+	assert.Equal(t, callerAddress, sc.CurrentContext().ContractAddress)
+}
+
+func TestWasmCallDepthLimit(t *testing.T) {
+	sc := newWasmCallContract(1000000000)
+	// the caller calls itself until the depth limit
+	_, err := invokeWasm(sc, callerAddress, common.ToHexString(callerAddress[:]), []byte("args"))
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "engine over max limit")
+	assert.Equal(t, 1, len(sc.Contexts))
+}
@@ -0,0 +1,260 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package trace record the execution of embedded and wasm contracts step by step,
+// it is used to debug contract invocations by pre-executing them
+package trace
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/core/types"
+	vm "github.com/imZhuFei/zeepin/embed/simulator"
+	vmtypes "github.com/imZhuFei/zeepin/embed/simulator/types"
+	"github.com/imZhuFei/zeepin/vm/wasmvm/exec"
+)
+
+const (
+	VM_EMBEDDED = "embedded"
+	VM_WASM     = "wasm"
+
+	MAX_TRACE_STEPS = 100000
+	MAX_STACK_ITEMS = 16
+)
+
+// MemoryAccess is the wasm linear memory accessed by a step
+type MemoryAccess struct {
+	Write bool   `json:"write"`
+	Addr  uint64 `json:"addr"`
+	Size  int    `json:"size"`
+}
+
+// Step is the vm state before an opcode or a system call is executed
+type Step struct {
+	VM       string        `json:"vm"`
+	Contract string        `json:"contract"`
+	Func     int64         `json:"func,omitempty"`
+	Pc       int64         `json:"pc"`
+	Op       string        `json:"op"`
+	Gas      uint64        `json:"gas"`
+	Stack    []string      `json:"stack,omitempty"`
+	AltStack []string      `json:"altStack,omitempty"`
+	Locals   []string      `json:"locals,omitempty"`
+	Memory   *MemoryAccess `json:"memory,omitempty"`
+	Syscall  string        `json:"syscall,omitempty"`
+}
+
+// Trace is the recorded execution of a transaction
+type Trace struct {
+	State     byte        `json:"state"`
+	Gas       uint64      `json:"gas"`
+	Result    interface{} `json:"result"`
+	Error     string      `json:"error,omitempty"`
+	Truncated bool        `json:"truncated"`
+	Steps     []*Step     `json:"steps"`
+}
+
+// Tracer record the steps of both vms, the steps exceed max steps are dropped
+type Tracer struct {
+	maxSteps int
+	trace    *Trace
+
+	embedCode    []byte
+	embedAddress string
+}
+
+func NewTracer(maxSteps int) *Tracer {
+	if maxSteps <= 0 || maxSteps > MAX_TRACE_STEPS {
+		maxSteps = MAX_TRACE_STEPS
+	}
+	return &Tracer{
+		maxSteps: maxSteps,
+		trace:    &Trace{Steps: make([]*Step, 0)},
+	}
+}
+
+// Trace return the recorded trace
+func (this *Tracer) Trace() *Trace {
+	return this.trace
+}
+
+// EmbeddedTracer return the tracer hooked into the embedded vm
+func (this *Tracer) EmbeddedTracer() vm.Tracer {
+	return &embeddedTracer{this}
+}
+
+// WasmTracer return the tracer hooked into the wasm vm
+func (this *Tracer) WasmTracer() exec.Tracer {
+	return &wasmTracer{this}
+}
+
+func (this *Tracer) addStep(step *Step) {
+	if len(this.trace.Steps) >= this.maxSteps {
+		this.trace.Truncated = true
+		return
+	}
+	this.trace.Steps = append(this.trace.Steps, step)
+}
+
+// the address of embedded contract is cached as the code rarely changes between steps
+func (this *Tracer) embeddedAddress(code []byte) string {
+	if len(code) != len(this.embedCode) || len(code) == 0 || &code[0] != &this.embedCode[0] {
+		this.embedCode = code
+		address := types.AddressFromVmCode(code)
+		this.embedAddress = address.ToHexString()
+	}
+	return this.embedAddress
+}
+
+type embeddedTracer struct {
+	*Tracer
+}
+
+func (this *embeddedTracer) CaptureStep(engine *vm.ExecutionEngine, gas uint64) {
+	if engine.Context == nil {
+		return
+	}
+	this.addStep(&Step{
+		VM:       VM_EMBEDDED,
+		Contract: this.embeddedAddress(engine.Context.Code),
+		Pc:       int64(engine.Context.GetInstructionPointer() - 1),
+		Op:       vm.OpName(engine.OpCode),
+		Gas:      gas,
+		Stack:    stackItems(engine.EvaluationStack),
+		AltStack: stackItems(engine.AltStack),
+	})
+}
+
+func (this *embeddedTracer) CaptureSyscall(engine *vm.ExecutionEngine, name string, gas uint64) {
+	if engine.Context == nil {
+		return
+	}
+	this.addStep(&Step{
+		VM:       VM_EMBEDDED,
+		Contract: this.embeddedAddress(engine.Context.Code),
+		Pc:       int64(engine.Context.GetInstructionPointer()),
+		Op:       vm.OpName(vm.SYSCALL),
+		Gas:      gas,
+		Syscall:  name,
+	})
+}
+
+type wasmTracer struct {
+	*Tracer
+}
+
+func (this *wasmTracer) CaptureStep(step *exec.Step) {
+	s := &Step{
+		VM:       VM_WASM,
+		Contract: step.Contract.ToHexString(),
+		Func:     step.Func,
+		Pc:       step.Pc,
+		Op:       step.Op,
+		Gas:      step.Gas,
+		Stack:    uint64Values(step.Stack),
+		Locals:   uint64Values(step.Locals),
+	}
+	if step.Memory != nil {
+		s.Memory = &MemoryAccess{
+			Write: step.Memory.Write,
+			Addr:  step.Memory.Addr,
+			Size:  step.Memory.Size,
+		}
+	}
+	this.addStep(s)
+}
+
+func (this *wasmTracer) CaptureHostCall(contract common.Address, name string, gas uint64) {
+	this.addStep(&Step{
+		VM:       VM_WASM,
+		Contract: contract.ToHexString(),
+		Op:       "call",
+		Gas:      gas,
+		Syscall:  name,
+	})
+}
+
+func uint64Values(values []uint64) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		result = append(result, strconv.FormatUint(v, 10))
+	}
+	return result
+}
+
+// stackItems return the items from the top of stack
+func stackItems(stack *vm.RandomAccessStack) []string {
+	count := stack.Count()
+	if count > MAX_STACK_ITEMS {
+		count = MAX_STACK_ITEMS
+	}
+	if count == 0 {
+		return nil
+	}
+	items := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		items = append(items, StackItemString(stack.Peek(i), 0))
+	}
+	return items
+}
+
+// StackItemString return the readable string of embedded vm stack item
+func StackItemString(item vmtypes.StackItems, depth int) string {
+	if depth > 4 {
+		return "..."
+	}
+	switch v := item.(type) {
+	case *vmtypes.Boolean:
+		b, _ := v.GetBoolean()
+		return strconv.FormatBool(b)
+	case *vmtypes.Integer:
+		i, _ := v.GetBigInteger()
+		return i.String()
+	case *vmtypes.ByteArray:
+		data, _ := v.GetByteArray()
+		return hex.EncodeToString(data)
+	case *vmtypes.Array:
+		arr, _ := v.GetArray()
+		return "[" + stackItemsString(arr, depth) + "]"
+	case *vmtypes.Struct:
+		arr, _ := v.GetStruct()
+		return "{" + stackItemsString(arr, depth) + "}"
+	case *vmtypes.Map:
+		m, _ := v.GetMap()
+		return fmt.Sprintf("map[%d]", len(m))
+	case *vmtypes.Interop:
+		return "interop"
+	default:
+		return fmt.Sprintf("%T", item)
+	}
+}
+
+func stackItemsString(items []vmtypes.StackItems, depth int) string {
+	strs := make([]string, 0, len(items))
+	for _, item := range items {
+		strs = append(strs, StackItemString(item, depth+1))
+	}
+	return strings.Join(strs, ",")
+}
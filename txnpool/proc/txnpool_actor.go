@@ -78,7 +78,7 @@ func NewVerifyRspActor(s *TXPoolServer) *VerifyRspActor {
 
 // isBalanceEnough checks if the tranactor has enough to cover gas cost
 func isBalanceEnough(address common.Address, gas uint64) bool {
-	balance, err := hComm.GetContractBalance(0, utils.GalaContractAddress, address)
+	balance, err := hComm.GetContractBalance(0, utils.GalaContractAddress, address, ledger.DefLedger.GetCurrentBlockHeight())
 	if err != nil {
 		log.Debugf("failed to get contract balance %s err %v",
 			address.ToHexString(), err)
@@ -348,6 +348,13 @@ func (tpa *TxPoolActor) Receive(context actor.Context) {
 
 		tpa.server.verifyBlock(msg, sender)
 
+	case *tc.TxReq:
+		log.Debugf("txpool actor receives tx from %v", msg.Sender.Sender())
+
+		if pid := tpa.server.GetPID(tc.TxActor); pid != nil {
+			pid.Tell(msg)
+		}
+
 	case *message.SaveBlockCompleteMsg:
 		sender := context.Sender()
 
@@ -0,0 +1,141 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package abi implement the binary parameter encoding of wasm contracts.
+//
+// Encoded parameters start with the abi version and the count of values,
+// every value is a type tag followed by its payload:
+//
+//	bytes, string    var bytes
+//	bool             1 byte
+//	address          20 bytes
+//	int128, uint128  16 bytes little endian, int128 is two's complement
+//	list, struct     var uint count followed by the values
+package abi
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/imZhuFei/zeepin/common"
+)
+
+const ABI_VERSION byte = 1
+
+const (
+	TYPE_BYTES   byte = 0x00
+	TYPE_STRING  byte = 0x01
+	TYPE_BOOL    byte = 0x02
+	TYPE_ADDRESS byte = 0x03
+	TYPE_INT128  byte = 0x04
+	TYPE_UINT128 byte = 0x05
+	TYPE_LIST    byte = 0x06
+	TYPE_STRUCT  byte = 0x07
+)
+
+const (
+	INT128_SIZE = 16
+	MAX_DEPTH   = 16
+)
+
+var (
+	ErrVersion       = errors.New("abi: unsupported version")
+	ErrUnexpectedEOF = errors.New("abi: unexpected end of data")
+	ErrType          = errors.New("abi: type mismatch")
+	ErrOverflow      = errors.New("abi: integer overflow")
+	ErrDepth         = errors.New("abi: nesting too deep")
+	ErrUnfinished    = errors.New("abi: unfinished list or struct")
+)
+
+var (
+	maxInt128  = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 127), big.NewInt(1))
+	minInt128  = new(big.Int).Neg(new(big.Int).Lsh(big.NewInt(1), 127))
+	maxUint128 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 128), big.NewInt(1))
+)
+
+// Uint128 is an unsigned 128 bits integer value, plain *big.Int values are encoded as int128
+type Uint128 struct {
+	Value *big.Int
+}
+
+// Struct is an ordered list of fields, plain []interface{} values are encoded as list
+type Struct []interface{}
+
+// Encode encode values to abi bytes, supported go types are
+// []byte, string, bool, common.Address, int, int32, int64, uint32, uint64,
+// *big.Int, Uint128, []interface{} and Struct
+func Encode(values []interface{}) ([]byte, error) {
+	w := NewWriter()
+	for _, v := range values {
+		if err := w.WriteValue(v); err != nil {
+			return nil, err
+		}
+	}
+	return w.Bytes()
+}
+
+// Decode decode abi bytes to values, integers are decoded as *big.Int or Uint128
+func Decode(data []byte) ([]interface{}, error) {
+	r, err := NewReader(data)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]interface{}, 0, r.Len())
+	for i := 0; i < r.Len(); i++ {
+		v, err := r.ReadValue()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// IsAbiData return whether data look like abi encoded parameters
+func IsAbiData(data []byte) bool {
+	return len(data) > 0 && data[0] == ABI_VERSION
+}
+
+func int128ToBytes(v *big.Int, signed bool) ([]byte, error) {
+	if signed {
+		if v.Cmp(minInt128) < 0 || v.Cmp(maxInt128) > 0 {
+			return nil, ErrOverflow
+		}
+	} else if v.Sign() < 0 || v.Cmp(maxUint128) > 0 {
+		return nil, ErrOverflow
+	}
+	n := new(big.Int).Set(v)
+	if n.Sign() < 0 {
+		n.Add(n, new(big.Int).Lsh(big.NewInt(1), 128))
+	}
+	buf := make([]byte, INT128_SIZE)
+	be := n.Bytes()
+	for i, b := range be {
+		buf[len(be)-1-i] = b
+	}
+	return buf, nil
+}
+
+func int128FromBytes(buf []byte, signed bool) *big.Int {
+	be := common.ToArrayReverse(buf)
+	n := new(big.Int).SetBytes(be)
+	if signed && buf[INT128_SIZE-1]&0x80 != 0 {
+		n.Sub(n, new(big.Int).Lsh(big.NewInt(1), 128))
+	}
+	return n
+}
@@ -0,0 +1,146 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package abi
+
+import (
+	"bytes"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/imZhuFei/zeepin/common"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	addr := common.Address{1, 2, 3}
+	neg, _ := new(big.Int).SetString("-170141183460469231731687303715884105728", 10)
+	max, _ := new(big.Int).SetString("340282366920938463463374607431768211455", 10)
+	values := []interface{}{
+		[]byte("data"),
+		"hello",
+		true,
+		addr,
+		big.NewInt(-1),
+		neg,
+		Uint128{Value: max},
+		[]interface{}{"a", big.NewInt(2), []interface{}{}},
+		Struct{addr, Uint128{Value: big.NewInt(100)}},
+	}
+	data, err := Encode(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsAbiData(data) {
+		t.Fatal("missing abi version")
+	}
+	decoded, err := Decode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(values, decoded) {
+		t.Fatalf("decoded %v, expect %v", decoded, values)
+	}
+}
+
+func TestReader(t *testing.T) {
+	w := NewWriter()
+	w.WriteString("transfer")
+	if err := w.BeginList(2); err != nil {
+		t.Fatal(err)
+	}
+	w.WriteAddress(common.Address{9})
+	if err := w.WriteInt128(big.NewInt(42)); err != nil {
+		t.Fatal(err)
+	}
+	w.WriteBool(false)
+	data, err := w.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewReader(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Len() != 3 {
+		t.Fatalf("value count %d, expect 3", r.Len())
+	}
+	if _, err := r.ReadBytes(); err != ErrType {
+		t.Fatalf("read string as bytes: %v", err)
+	}
+	method, err := r.ReadString()
+	if err != nil || method != "transfer" {
+		t.Fatalf("method %s, err %v", method, err)
+	}
+	n, err := r.ReadListLen()
+	if err != nil || n != 2 {
+		t.Fatalf("list len %d, err %v", n, err)
+	}
+	to, err := r.ReadAddress()
+	if err != nil || to != (common.Address{9}) {
+		t.Fatalf("address %v, err %v", to, err)
+	}
+	amount, err := r.ReadInt64()
+	if err != nil || amount != 42 {
+		t.Fatalf("amount %d, err %v", amount, err)
+	}
+	flag, err := r.ReadBool()
+	if err != nil || flag {
+		t.Fatalf("flag %v, err %v", flag, err)
+	}
+}
+
+func TestInvalidData(t *testing.T) {
+	if _, err := Decode(nil); err != ErrUnexpectedEOF {
+		t.Fatalf("empty data: %v", err)
+	}
+	if _, err := Decode([]byte{2, 0}); err != ErrVersion {
+		t.Fatalf("bad version: %v", err)
+	}
+	data, _ := Encode([]interface{}{"hello"})
+	if _, err := Decode(data[:len(data)-1]); err != ErrUnexpectedEOF {
+		t.Fatalf("truncated data: %v", err)
+	}
+
+	w := NewWriter()
+	w.BeginStruct(2)
+	w.WriteBool(true)
+	if _, err := w.Bytes(); err != ErrUnfinished {
+		t.Fatalf("unfinished struct: %v", err)
+	}
+	if err := w.WriteUint128(big.NewInt(-1)); err != ErrOverflow {
+		t.Fatalf("negative uint128: %v", err)
+	}
+	if err := w.WriteInt128(new(big.Int).Lsh(big.NewInt(1), 127)); err != ErrOverflow {
+		t.Fatalf("int128 overflow: %v", err)
+	}
+}
+
+func TestInt128Bytes(t *testing.T) {
+	buf, _ := int128ToBytes(big.NewInt(-2), true)
+	expect := bytes.Repeat([]byte{0xff}, INT128_SIZE)
+	expect[0] = 0xfe
+	if !bytes.Equal(buf, expect) {
+		t.Fatalf("encoded %x, expect %x", buf, expect)
+	}
+	buf, _ = int128ToBytes(big.NewInt(0x0102), false)
+	if buf[0] != 0x02 || buf[1] != 0x01 {
+		t.Fatalf("not little endian %x", buf)
+	}
+}
@@ -0,0 +1,234 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package abi
+
+import (
+	"math/big"
+
+	"github.com/imZhuFei/zeepin/common"
+)
+
+// Reader decode abi values one by one in the order they are written
+type Reader struct {
+	source *common.ZeroCopySource
+	count  int
+}
+
+// NewReader check the abi version of data and return a reader of its values
+func NewReader(data []byte) (*Reader, error) {
+	source := common.NewZeroCopySource(data)
+	version, eof := source.NextByte()
+	if eof {
+		return nil, ErrUnexpectedEOF
+	}
+	if version != ABI_VERSION {
+		return nil, ErrVersion
+	}
+	count, _, irregular, eof := source.NextVarUint()
+	if irregular {
+		return nil, common.ErrIrregularData
+	}
+	if eof {
+		return nil, ErrUnexpectedEOF
+	}
+	if count > source.Len() {
+		return nil, ErrUnexpectedEOF
+	}
+	return &Reader{source: source, count: int(count)}, nil
+}
+
+// Len return the count of top level values
+func (r *Reader) Len() int {
+	return r.count
+}
+
+// PeekType return the type tag of next value
+func (r *Reader) PeekType() (byte, error) {
+	tag, eof := r.source.NextByte()
+	if eof {
+		return 0, ErrUnexpectedEOF
+	}
+	r.source.BackUp(1)
+	return tag, nil
+}
+
+func (r *Reader) expect(tag byte) error {
+	t, eof := r.source.NextByte()
+	if eof {
+		return ErrUnexpectedEOF
+	}
+	if t != tag {
+		r.source.BackUp(1)
+		return ErrType
+	}
+	return nil
+}
+
+func (r *Reader) ReadBytes() ([]byte, error) {
+	if err := r.expect(TYPE_BYTES); err != nil {
+		return nil, err
+	}
+	return r.varBytes()
+}
+
+func (r *Reader) ReadString() (string, error) {
+	if err := r.expect(TYPE_STRING); err != nil {
+		return "", err
+	}
+	data, err := r.varBytes()
+	return string(data), err
+}
+
+func (r *Reader) ReadBool() (bool, error) {
+	if err := r.expect(TYPE_BOOL); err != nil {
+		return false, err
+	}
+	data, irregular, eof := r.source.NextBool()
+	if irregular {
+		return false, common.ErrIrregularData
+	}
+	if eof {
+		return false, ErrUnexpectedEOF
+	}
+	return data, nil
+}
+
+func (r *Reader) ReadAddress() (common.Address, error) {
+	if err := r.expect(TYPE_ADDRESS); err != nil {
+		return common.Address{}, err
+	}
+	addr, eof := r.source.NextAddress()
+	if eof {
+		return common.Address{}, ErrUnexpectedEOF
+	}
+	return addr, nil
+}
+
+// ReadInt read an int128 or uint128 value
+func (r *Reader) ReadInt() (*big.Int, error) {
+	tag, err := r.PeekType()
+	if err != nil {
+		return nil, err
+	}
+	if tag != TYPE_INT128 && tag != TYPE_UINT128 {
+		return nil, ErrType
+	}
+	r.source.Skip(1)
+	buf, eof := r.source.NextBytes(INT128_SIZE)
+	if eof {
+		return nil, ErrUnexpectedEOF
+	}
+	return int128FromBytes(buf, tag == TYPE_INT128), nil
+}
+
+// ReadInt64 read an integer value which must fit in int64
+func (r *Reader) ReadInt64() (int64, error) {
+	v, err := r.ReadInt()
+	if err != nil {
+		return 0, err
+	}
+	if !v.IsInt64() {
+		return 0, ErrOverflow
+	}
+	return v.Int64(), nil
+}
+
+// ReadListLen read the header of a list or struct and return its length,
+// the elements follow and are read with the other read methods
+func (r *Reader) ReadListLen() (int, error) {
+	tag, err := r.PeekType()
+	if err != nil {
+		return 0, err
+	}
+	if tag != TYPE_LIST && tag != TYPE_STRUCT {
+		return 0, ErrType
+	}
+	r.source.Skip(1)
+	n, _, irregular, eof := r.source.NextVarUint()
+	if irregular {
+		return 0, common.ErrIrregularData
+	}
+	if eof || n > r.source.Len() {
+		return 0, ErrUnexpectedEOF
+	}
+	return int(n), nil
+}
+
+// ReadValue read next value as go value
+func (r *Reader) ReadValue() (interface{}, error) {
+	return r.readValue(0)
+}
+
+func (r *Reader) readValue(depth int) (interface{}, error) {
+	if depth > MAX_DEPTH {
+		return nil, ErrDepth
+	}
+	tag, err := r.PeekType()
+	if err != nil {
+		return nil, err
+	}
+	switch tag {
+	case TYPE_BYTES:
+		return r.ReadBytes()
+	case TYPE_STRING:
+		return r.ReadString()
+	case TYPE_BOOL:
+		return r.ReadBool()
+	case TYPE_ADDRESS:
+		return r.ReadAddress()
+	case TYPE_INT128:
+		return r.ReadInt()
+	case TYPE_UINT128:
+		v, err := r.ReadInt()
+		if err != nil {
+			return nil, err
+		}
+		return Uint128{Value: v}, nil
+	case TYPE_LIST, TYPE_STRUCT:
+		n, err := r.ReadListLen()
+		if err != nil {
+			return nil, err
+		}
+		items := make([]interface{}, 0, n)
+		for i := 0; i < n; i++ {
+			item, err := r.readValue(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		if tag == TYPE_STRUCT {
+			return Struct(items), nil
+		}
+		return items, nil
+	default:
+		return nil, ErrType
+	}
+}
+
+func (r *Reader) varBytes() ([]byte, error) {
+	data, _, irregular, eof := r.source.NextVarBytes()
+	if irregular {
+		return nil, common.ErrIrregularData
+	}
+	if eof {
+		return nil, ErrUnexpectedEOF
+	}
+	return data, nil
+}
@@ -0,0 +1,182 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package abi
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/imZhuFei/zeepin/common"
+)
+
+// Writer encode abi values one by one, lists and structs are started with
+// their length and finished automatically after the last element is written
+type Writer struct {
+	sink    *common.ZeroCopySink
+	count   uint64
+	pending []uint64
+}
+
+// NewWriter return a new abi writer
+func NewWriter() *Writer {
+	return &Writer{sink: common.NewZeroCopySink(nil)}
+}
+
+// Bytes return the encoded parameters
+func (w *Writer) Bytes() ([]byte, error) {
+	if len(w.pending) != 0 {
+		return nil, ErrUnfinished
+	}
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteByte(ABI_VERSION)
+	sink.WriteVarUint(w.count)
+	sink.WriteBytes(w.sink.Bytes())
+	return sink.Bytes(), nil
+}
+
+func (w *Writer) done() {
+	for len(w.pending) > 0 {
+		last := len(w.pending) - 1
+		w.pending[last]--
+		if w.pending[last] != 0 {
+			return
+		}
+		w.pending = w.pending[:last]
+	}
+	w.count++
+}
+
+func (w *Writer) WriteBytes(data []byte) {
+	w.sink.WriteByte(TYPE_BYTES)
+	w.sink.WriteVarBytes(data)
+	w.done()
+}
+
+func (w *Writer) WriteString(data string) {
+	w.sink.WriteByte(TYPE_STRING)
+	w.sink.WriteString(data)
+	w.done()
+}
+
+func (w *Writer) WriteBool(data bool) {
+	w.sink.WriteByte(TYPE_BOOL)
+	w.sink.WriteBool(data)
+	w.done()
+}
+
+func (w *Writer) WriteAddress(addr common.Address) {
+	w.sink.WriteByte(TYPE_ADDRESS)
+	w.sink.WriteAddress(addr)
+	w.done()
+}
+
+func (w *Writer) WriteInt128(v *big.Int) error {
+	buf, err := int128ToBytes(v, true)
+	if err != nil {
+		return err
+	}
+	w.sink.WriteByte(TYPE_INT128)
+	w.sink.WriteBytes(buf)
+	w.done()
+	return nil
+}
+
+func (w *Writer) WriteUint128(v *big.Int) error {
+	buf, err := int128ToBytes(v, false)
+	if err != nil {
+		return err
+	}
+	w.sink.WriteByte(TYPE_UINT128)
+	w.sink.WriteBytes(buf)
+	w.done()
+	return nil
+}
+
+// BeginList start a list of n elements
+func (w *Writer) BeginList(n uint64) error {
+	return w.begin(TYPE_LIST, n)
+}
+
+// BeginStruct start a struct of n fields
+func (w *Writer) BeginStruct(n uint64) error {
+	return w.begin(TYPE_STRUCT, n)
+}
+
+func (w *Writer) begin(tag byte, n uint64) error {
+	if len(w.pending) >= MAX_DEPTH {
+		return ErrDepth
+	}
+	w.sink.WriteByte(tag)
+	w.sink.WriteVarUint(n)
+	if n == 0 {
+		w.done()
+		return nil
+	}
+	w.pending = append(w.pending, n)
+	return nil
+}
+
+// WriteValue write a go value, see Encode for the supported types
+func (w *Writer) WriteValue(value interface{}) error {
+	switch v := value.(type) {
+	case []byte:
+		w.WriteBytes(v)
+	case string:
+		w.WriteString(v)
+	case bool:
+		w.WriteBool(v)
+	case common.Address:
+		w.WriteAddress(v)
+	case int:
+		return w.WriteInt128(big.NewInt(int64(v)))
+	case int32:
+		return w.WriteInt128(big.NewInt(int64(v)))
+	case int64:
+		return w.WriteInt128(big.NewInt(v))
+	case uint32:
+		return w.WriteUint128(new(big.Int).SetUint64(uint64(v)))
+	case uint64:
+		return w.WriteUint128(new(big.Int).SetUint64(v))
+	case *big.Int:
+		return w.WriteInt128(v)
+	case Uint128:
+		return w.WriteUint128(v.Value)
+	case []interface{}:
+		if err := w.BeginList(uint64(len(v))); err != nil {
+			return err
+		}
+		for _, item := range v {
+			if err := w.WriteValue(item); err != nil {
+				return err
+			}
+		}
+	case Struct:
+		if err := w.BeginStruct(uint64(len(v))); err != nil {
+			return err
+		}
+		for _, item := range v {
+			if err := w.WriteValue(item); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("abi: unsupported type %T", value)
+	}
+	return nil
+}
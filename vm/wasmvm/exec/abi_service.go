@@ -0,0 +1,349 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package exec
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/vm/wasmvm/abi"
+	"github.com/imZhuFei/zeepin/vm/wasmvm/memory"
+	"github.com/imZhuFei/zeepin/vm/wasmvm/util"
+)
+
+// RegisterAbiService register the binary abi apis to service
+func RegisterAbiService(service InteropServiceInterface) {
+	service.Register("ZPT_AbiLen", abiLen)
+	service.Register("ZPT_AbiReadBytes", abiReadBytes)
+	service.Register("ZPT_AbiReadString", abiReadString)
+	service.Register("ZPT_AbiReadBool", abiReadBool)
+	service.Register("ZPT_AbiReadAddress", abiReadAddress)
+	service.Register("ZPT_AbiReadInt64", abiReadInt64)
+	service.Register("ZPT_AbiReadInt128", abiReadInt128)
+	service.Register("ZPT_AbiReadListLen", abiReadListLen)
+	service.Register("ZPT_AbiNewEncoder", abiNewEncoder)
+	service.Register("ZPT_AbiWriteBytes", abiWriteBytes)
+	service.Register("ZPT_AbiWriteString", abiWriteString)
+	service.Register("ZPT_AbiWriteBool", abiWriteBool)
+	service.Register("ZPT_AbiWriteAddress", abiWriteAddress)
+	service.Register("ZPT_AbiWriteInt64", abiWriteInt64)
+	service.Register("ZPT_AbiWriteInt128", abiWriteInt128)
+	service.Register("ZPT_AbiBeginList", abiBeginList)
+	service.Register("ZPT_AbiBeginStruct", abiBeginStruct)
+	service.Register("ZPT_AbiEncode", abiEncode)
+}
+
+// abi readers keyed by the args pointer, values are read in order
+func (vm *VM) abiReader(addr uint64) (*abi.Reader, error) {
+	if r, ok := vm.abiReaders[addr]; ok {
+		return r, nil
+	}
+	data, err := vm.GetPointerMemory(addr)
+	if err != nil {
+		return nil, err
+	}
+	r, err := abi.NewReader(data)
+	if err != nil {
+		return nil, err
+	}
+	if vm.abiReaders == nil {
+		vm.abiReaders = make(map[uint64]*abi.Reader)
+	}
+	vm.abiReaders[addr] = r
+	return r, nil
+}
+
+// abi writers are referred by their index
+func (vm *VM) abiWriter(handle uint64) (*abi.Writer, error) {
+	if handle >= uint64(len(vm.abiWriters)) {
+		return nil, errors.New("invalid abi encoder")
+	}
+	return vm.abiWriters[handle], nil
+}
+
+func abiReaderParam(engine *ExecutionEngine, name string) (*abi.Reader, error) {
+	params := engine.vm.envCall.envParams
+	if len(params) != 1 {
+		return nil, errors.New("parameter count error while call " + name)
+	}
+	return engine.vm.abiReader(params[0])
+}
+
+func abiReturn(engine *ExecutionEngine, ret uint64) (bool, error) {
+	engine.vm.RestoreCtx()
+	if engine.vm.envCall.envReturns {
+		engine.vm.pushUint64(ret)
+	}
+	return true, nil
+}
+
+func abiReturnPointer(engine *ExecutionEngine, val interface{}) (bool, error) {
+	idx, err := engine.vm.SetPointerMemory(val)
+	if err != nil {
+		return false, err
+	}
+	return abiReturn(engine, uint64(idx))
+}
+
+// return the count of top level values in abi args
+func abiLen(engine *ExecutionEngine) (bool, error) {
+	r, err := abiReaderParam(engine, "abiLen")
+	if err != nil {
+		return false, err
+	}
+	return abiReturn(engine, uint64(r.Len()))
+}
+
+func abiReadBytes(engine *ExecutionEngine) (bool, error) {
+	r, err := abiReaderParam(engine, "abiReadBytes")
+	if err != nil {
+		return false, err
+	}
+	data, err := r.ReadBytes()
+	if err != nil {
+		return false, err
+	}
+	if len(data) == 0 {
+		return abiReturn(engine, uint64(memory.VM_NIL_POINTER))
+	}
+	return abiReturnPointer(engine, data)
+}
+
+func abiReadString(engine *ExecutionEngine) (bool, error) {
+	r, err := abiReaderParam(engine, "abiReadString")
+	if err != nil {
+		return false, err
+	}
+	data, err := r.ReadString()
+	if err != nil {
+		return false, err
+	}
+	return abiReturnPointer(engine, data)
+}
+
+func abiReadBool(engine *ExecutionEngine) (bool, error) {
+	r, err := abiReaderParam(engine, "abiReadBool")
+	if err != nil {
+		return false, err
+	}
+	data, err := r.ReadBool()
+	if err != nil {
+		return false, err
+	}
+	if data {
+		return abiReturn(engine, 1)
+	}
+	return abiReturn(engine, 0)
+}
+
+// address is returned as base58 string like ZPT_GetCallerAddress
+func abiReadAddress(engine *ExecutionEngine) (bool, error) {
+	r, err := abiReaderParam(engine, "abiReadAddress")
+	if err != nil {
+		return false, err
+	}
+	addr, err := r.ReadAddress()
+	if err != nil {
+		return false, err
+	}
+	return abiReturnPointer(engine, addr.ToBase58())
+}
+
+func abiReadInt64(engine *ExecutionEngine) (bool, error) {
+	r, err := abiReaderParam(engine, "abiReadInt64")
+	if err != nil {
+		return false, err
+	}
+	data, err := r.ReadInt64()
+	if err != nil {
+		return false, err
+	}
+	return abiReturn(engine, uint64(data))
+}
+
+// 128 bits integer is returned as decimal string
+func abiReadInt128(engine *ExecutionEngine) (bool, error) {
+	r, err := abiReaderParam(engine, "abiReadInt128")
+	if err != nil {
+		return false, err
+	}
+	data, err := r.ReadInt()
+	if err != nil {
+		return false, err
+	}
+	return abiReturnPointer(engine, data.String())
+}
+
+// read the header of list or struct, return the count of its elements
+func abiReadListLen(engine *ExecutionEngine) (bool, error) {
+	r, err := abiReaderParam(engine, "abiReadListLen")
+	if err != nil {
+		return false, err
+	}
+	n, err := r.ReadListLen()
+	if err != nil {
+		return false, err
+	}
+	return abiReturn(engine, uint64(n))
+}
+
+// create an abi encoder and return its handle
+func abiNewEncoder(engine *ExecutionEngine) (bool, error) {
+	if len(engine.vm.envCall.envParams) != 0 {
+		return false, errors.New("parameter count error while call abiNewEncoder")
+	}
+	engine.vm.abiWriters = append(engine.vm.abiWriters, abi.NewWriter())
+	return abiReturn(engine, uint64(len(engine.vm.abiWriters)-1))
+}
+
+func abiWriterParams(engine *ExecutionEngine, name string) (*abi.Writer, uint64, error) {
+	params := engine.vm.envCall.envParams
+	if len(params) != 2 {
+		return nil, 0, errors.New("parameter count error while call " + name)
+	}
+	w, err := engine.vm.abiWriter(params[0])
+	if err != nil {
+		return nil, 0, err
+	}
+	return w, params[1], nil
+}
+
+func abiWriteBytes(engine *ExecutionEngine) (bool, error) {
+	w, addr, err := abiWriterParams(engine, "abiWriteBytes")
+	if err != nil {
+		return false, err
+	}
+	data, err := engine.vm.GetPointerMemory(addr)
+	if err != nil {
+		return false, err
+	}
+	w.WriteBytes(data)
+	return abiReturn(engine, 0)
+}
+
+func abiWriteString(engine *ExecutionEngine) (bool, error) {
+	w, addr, err := abiWriterParams(engine, "abiWriteString")
+	if err != nil {
+		return false, err
+	}
+	data, err := engine.vm.GetPointerMemory(addr)
+	if err != nil {
+		return false, err
+	}
+	w.WriteString(util.TrimBuffToString(data))
+	return abiReturn(engine, 0)
+}
+
+func abiWriteBool(engine *ExecutionEngine) (bool, error) {
+	w, val, err := abiWriterParams(engine, "abiWriteBool")
+	if err != nil {
+		return false, err
+	}
+	w.WriteBool(val != 0)
+	return abiReturn(engine, 0)
+}
+
+// address is passed as base58 string
+func abiWriteAddress(engine *ExecutionEngine) (bool, error) {
+	w, addr, err := abiWriterParams(engine, "abiWriteAddress")
+	if err != nil {
+		return false, err
+	}
+	data, err := engine.vm.GetPointerMemory(addr)
+	if err != nil {
+		return false, err
+	}
+	address, err := common.AddressFromBase58(util.TrimBuffToString(data))
+	if err != nil {
+		return false, err
+	}
+	w.WriteAddress(address)
+	return abiReturn(engine, 0)
+}
+
+func abiWriteInt64(engine *ExecutionEngine) (bool, error) {
+	w, val, err := abiWriterParams(engine, "abiWriteInt64")
+	if err != nil {
+		return false, err
+	}
+	if err := w.WriteInt128(big.NewInt(int64(val))); err != nil {
+		return false, err
+	}
+	return abiReturn(engine, 0)
+}
+
+// 128 bits integer is passed as decimal string
+func abiWriteInt128(engine *ExecutionEngine) (bool, error) {
+	w, addr, err := abiWriterParams(engine, "abiWriteInt128")
+	if err != nil {
+		return false, err
+	}
+	data, err := engine.vm.GetPointerMemory(addr)
+	if err != nil {
+		return false, err
+	}
+	val, ok := new(big.Int).SetString(util.TrimBuffToString(data), 10)
+	if !ok {
+		return false, errors.New("invalid int128 value")
+	}
+	if err := w.WriteInt128(val); err != nil {
+		return false, err
+	}
+	return abiReturn(engine, 0)
+}
+
+func abiBeginList(engine *ExecutionEngine) (bool, error) {
+	w, n, err := abiWriterParams(engine, "abiBeginList")
+	if err != nil {
+		return false, err
+	}
+	if err := w.BeginList(n); err != nil {
+		return false, err
+	}
+	return abiReturn(engine, 0)
+}
+
+func abiBeginStruct(engine *ExecutionEngine) (bool, error) {
+	w, n, err := abiWriterParams(engine, "abiBeginStruct")
+	if err != nil {
+		return false, err
+	}
+	if err := w.BeginStruct(n); err != nil {
+		return false, err
+	}
+	return abiReturn(engine, 0)
+}
+
+// finish the encoder and return the pointer of encoded bytes
+func abiEncode(engine *ExecutionEngine) (bool, error) {
+	params := engine.vm.envCall.envParams
+	if len(params) != 1 {
+		return false, errors.New("parameter count error while call abiEncode")
+	}
+	w, err := engine.vm.abiWriter(params[0])
+	if err != nil {
+		return false, err
+	}
+	data, err := w.Bytes()
+	if err != nil {
+		return false, err
+	}
+	return abiReturnPointer(engine, data)
+}
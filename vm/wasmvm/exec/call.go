@@ -79,7 +79,17 @@ func (vm *VM) doCall(compiled compiledFunction, index int64) {
 
 		v, ok := vm.Services[compiled.name]
 		if ok {
+			if vm.Engine != nil {
+				gas := vm.Engine.gasTable.HostCall + vm.Engine.gasTable.HostCalls[compiled.name]
+				if vm.Engine.tracer != nil {
+					vm.Engine.tracer.CaptureHostCall(vm.ContractAddress, compiled.name, gas)
+				}
+				vm.useGas(gas)
+			}
 			rtn, err := v(vm.Engine)
+			if _, ok := err.(*trapError); ok || err == ErrOutOfGas {
+				panic(err)
+			}
 			if err != nil || !rtn {
 				log.Errorf("call method :%s failed: %s\n", compiled.name, err)
 			}
@@ -114,6 +124,21 @@ var (
 	ErrUndefinedElementIndex = errors.New("exec: undefined element index")
 )
 
+// trapError is a host service error which abort the execution
+type trapError struct {
+	err error
+}
+
+func (e *trapError) Error() string {
+	return e.err.Error()
+}
+
+// Trap wrap the error of a host service, the vm stop executing when
+// the service return it and the engine call return err
+func Trap(err error) error {
+	return &trapError{err: err}
+}
+
 func (vm *VM) call() {
 	index := vm.fetchUint32()
 	vm.doCall(vm.compiledFuncs[index], int64(index))
@@ -36,6 +36,7 @@ package exec
 
 import (
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"math"
@@ -147,6 +148,32 @@ func TestEnvAddTwo(t *testing.T) {
 	}
 }
 
+func TestEnvTrap(t *testing.T) {
+	trapService := NewInteropService()
+	trapErr := errors.New("call contract failed")
+	trapService.Register("addOne", func(engine *ExecutionEngine) (bool, error) {
+		return false, Trap(trapErr)
+	})
+
+	engine := NewExecutionEngine(nil, nil, trapService)
+
+	code, err := ioutil.ReadFile("./test_data2/testenv.wasm")
+	if err != nil {
+		t.Fatal("error in read file", err.Error())
+	}
+	method := "addTwo"
+
+	input := make([]byte, 8)
+	input[0] = byte(len(method))
+	copy(input[1:len(method)+1], []byte(method))
+	input[len(method)+1] = byte(0)
+
+	_, err = engine.Call(common.Address{}, code, "", input, 0)
+	if err != trapErr {
+		t.Errorf("call should return the trapped error, got %v", err)
+	}
+}
+
 func TestBlockHeight(t *testing.T) {
 
 	service.Register("getBlockHeight", func(engine *ExecutionEngine) (bool, error) {
@@ -104,11 +104,51 @@ func NewExecutionEngine(container interfaces.CodeContainer, crypto interfaces.Cr
 }
 
 type ExecutionEngine struct {
-	crypto        interfaces.Crypto
-	service       *InteropService
-	CodeContainer interfaces.CodeContainer
-	vm            *VM
-	backupVM      *vmstack
+	crypto         interfaces.Crypto
+	service        *InteropService
+	CodeContainer  interfaces.CodeContainer
+	vm             *VM
+	backupVM       *vmstack
+	gasMeter       GasMeter
+	gasTable       GasTable
+	opGas          *[256]uint64
+	tracer         Tracer
+	maxMemoryPages uint32
+}
+
+//SetGasMeter enable gas accounting, every instruction, grown memory page
+//and host call executed by the engine is charged to meter with the cost in table
+func (e *ExecutionEngine) SetGasMeter(meter GasMeter, table *GasTable) {
+	if table == nil {
+		table = &DefaultGasTable
+	}
+	e.gasMeter = meter
+	e.gasTable = *table
+	e.opGas = table.opGasTable()
+}
+
+//SetMaxMemoryPages limit the pages of linear memory, grow_memory fails
+//when the memory would exceed pages. No limit is applied if it is 0
+func (e *ExecutionEngine) SetMaxMemoryPages(pages uint32) {
+	e.maxMemoryPages = pages
+}
+
+//SetTracer record every instruction and host call executed by the engine
+//to tracer, it slows down the execution and is used for debugging only
+func (e *ExecutionEngine) SetTracer(tracer Tracer) {
+	e.tracer = tracer
+}
+
+//UseGas charge gas for the engine, host services use it to price
+//their own work, ErrOutOfGas is returned if the meter is exhausted
+func (e *ExecutionEngine) UseGas(gas uint64) error {
+	if e.gasMeter == nil {
+		return nil
+	}
+	if !e.gasMeter.CheckUseGas(gas) {
+		return ErrOutOfGas
+	}
+	return nil
 }
 
 //GetVM return vm pointer
@@ -337,7 +377,7 @@ func (e *ExecutionEngine) Call(caller common.Address,
 	defer func() {
 		if err := recover(); err != nil {
 			returnbytes = nil
-			er = errors.NewErr("[Call] error happened while call wasmvm")
+			er = recoverError(err)
 		}
 	}()
 
@@ -360,7 +400,7 @@ func (e *ExecutionEngine) InitCall(caller common.Address,
 	defer func() {
 		if err := recover(); err != nil {
 			returnbytes = nil
-			er = errors.NewErr("[Call] error happened while call wasmvm")
+			er = recoverError(err)
 		}
 	}()
 
@@ -368,6 +408,17 @@ func (e *ExecutionEngine) InitCall(caller common.Address,
 
 }
 
+// recoverError convert the value recovered from a vm panic to the error returned by engine
+func recoverError(r interface{}) error {
+	if r == ErrOutOfGas {
+		return ErrOutOfGas
+	}
+	if t, ok := r.(*trapError); ok {
+		return t.err
+	}
+	return errors.NewErr("[Call] error happened while call wasmvm")
+}
+
 // call to execute wasm vm
 func (e *ExecutionEngine) call(caller common.Address,
 	code []byte,
@@ -376,24 +427,15 @@ func (e *ExecutionEngine) call(caller common.Address,
 	ver byte) (returnbytes []byte, er error) {
 	if ver > 0 { //production contract version
 		methodName := CONTRACT_METHOD_NAME //fix to "invoke"
-		//1. read code
-		bf := bytes.NewBuffer(code)
-
-		//2. read module
-		m, err := wasm.ReadModule(bf, importer)
+		contractAddress := types.AddressFromVmCode(code)
+		//1. read, verify and compile the code, or get it from the module cache
+		compiled, err := DefModuleCache.GetModule(contractAddress, code)
 		if err != nil {
-			return nil, errors.NewErr("[Call]Verify wasm failed!" + err.Error())
+			return nil, errors.NewErr("[Call]" + err.Error())
 		}
-		//3. verify the module
-		//already verified in step 2
+		m := compiled.Module()
 
-		//4. check the export
-		//every wasm should have at least 1 export
-		if m.Export == nil {
-			return nil, errors.NewErr("[Call]No export in wasm!")
-		}
-
-		vm, err := NewVM(m)
+		vm, err := NewVMFromCompiled(compiled)
 		if err != nil {
 			return nil, err
 		}
@@ -408,7 +450,7 @@ func (e *ExecutionEngine) call(caller common.Address,
 		vm.Caller = caller
 
 		vm.VMCode = code
-		vm.ContractAddress = types.AddressFromVmCode(code)
+		vm.ContractAddress = contractAddress
 
 		entry, ok := m.Export.Entries[methodName]
 
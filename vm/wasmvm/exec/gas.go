@@ -0,0 +1,109 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package exec
+
+import (
+	"errors"
+
+	"github.com/imZhuFei/zeepin/vm/wasmvm/exec/internal/compile"
+	ops "github.com/imZhuFei/zeepin/vm/wasmvm/wasm/operators"
+)
+
+// ErrOutOfGas is the error value used while trapping the VM when the gas
+// available to the invocation is exhausted.
+var ErrOutOfGas = errors.New("exec: out of gas")
+
+// GasMeter is the gas account the engine charges while executing a contract.
+// CheckUseGas should deduct gas and return false if the balance is insufficient.
+type GasMeter interface {
+	CheckUseGas(gas uint64) bool
+}
+
+// GasTable describes the gas cost of every instruction class of the VM.
+type GasTable struct {
+	Base       uint64 // constants, locals, globals, comparisons and stack operators
+	Arith      uint64 // arithmetic, bitwise and conversion operators
+	Mul        uint64 // multiplication
+	Div        uint64 // division and remainder
+	Memory     uint64 // linear memory loads and stores
+	Branch     uint64 // jumps and branch tables
+	Call       uint64 // direct and indirect function calls
+	MemoryPage uint64 // every 64KB page added by grow_memory
+	HostCall   uint64 // every call into a registered host service
+
+	// HostCalls is the extra cost of the named host services on top of HostCall
+	HostCalls map[string]uint64
+}
+
+// DefaultGasTable is used when no table is supplied to SetGasMeter
+var DefaultGasTable = GasTable{
+	Base:       1,
+	Arith:      1,
+	Mul:        2,
+	Div:        4,
+	Memory:     2,
+	Branch:     2,
+	Call:       10,
+	MemoryPage: 1000,
+	HostCall:   10,
+}
+
+// opGasTable maps every opcode of the compiled code to its gas cost
+func (t *GasTable) opGasTable() *[256]uint64 {
+	var costs [256]uint64
+	for i := range costs {
+		costs[i] = t.Arith
+	}
+	for op := ops.I32Load; op <= ops.I64Store32; op++ {
+		costs[op] = t.Memory
+	}
+	for op := ops.I32Const; op <= ops.F64Ge; op++ {
+		costs[op] = t.Base
+	}
+	for _, op := range []byte{ops.Unreachable, ops.Nop, ops.Return, ops.Drop, ops.Select,
+		ops.GetLocal, ops.SetLocal, ops.TeeLocal, ops.GetGlobal, ops.SetGlobal,
+		ops.CurrentMemory, ops.GrowMemory, compile.OpDiscard, compile.OpDiscardPreserveTop} {
+		costs[op] = t.Base
+	}
+	for _, op := range []byte{compile.OpJmp, compile.OpJmpZ, compile.OpJmpNz, ops.BrTable} {
+		costs[op] = t.Branch
+	}
+	for _, op := range []byte{ops.Call, ops.CallIndirect} {
+		costs[op] = t.Call
+	}
+	for _, op := range []byte{ops.I32Mul, ops.I64Mul, ops.F32Mul, ops.F64Mul} {
+		costs[op] = t.Mul
+	}
+	for _, op := range []byte{ops.I32DivS, ops.I32DivU, ops.I32RemS, ops.I32RemU,
+		ops.I64DivS, ops.I64DivU, ops.I64RemS, ops.I64RemU, ops.F32Div, ops.F64Div} {
+		costs[op] = t.Div
+	}
+	return &costs
+}
+
+// useGas charges gas to the meter of the engine running vm,
+// the vm is trapped with ErrOutOfGas if the meter is exhausted
+func (vm *VM) useGas(gas uint64) {
+	if vm.Engine == nil || vm.Engine.gasMeter == nil {
+		return
+	}
+	if !vm.Engine.gasMeter.CheckUseGas(gas) {
+		panic(ErrOutOfGas)
+	}
+}
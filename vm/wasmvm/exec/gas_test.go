@@ -0,0 +1,144 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package exec
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"math"
+	"testing"
+
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/vm/wasmvm/exec/internal/compile"
+	"github.com/imZhuFei/zeepin/vm/wasmvm/wasm/operators"
+)
+
+type testGasMeter struct {
+	gas uint64
+}
+
+func (m *testGasMeter) CheckUseGas(gas uint64) bool {
+	if m.gas < gas {
+		return false
+	}
+	m.gas -= gas
+	return true
+}
+
+func addInput() []byte {
+	method := "add"
+	input := make([]byte, 9)
+	input[0] = byte(len(method))
+	copy(input[1:len(method)+1], []byte(method))
+	input[len(method)+1] = byte(2) //param count
+	input[len(method)+2] = byte(1) //param1 length
+	input[len(method)+3] = byte(1) //param2 length
+	input[len(method)+4] = byte(5) //param1
+	input[len(method)+5] = byte(9) //param2
+	return input
+}
+
+func TestGasMetering(t *testing.T) {
+	code, err := ioutil.ReadFile("./test_data2/math.wasm")
+	if err != nil {
+		t.Fatal("error in read file", err.Error())
+	}
+
+	meter := &testGasMeter{gas: 1000000}
+	engine := NewExecutionEngine(nil, nil, nil)
+	engine.SetGasMeter(meter, nil)
+	res, err := engine.Call(common.Address{}, code, "", addInput(), 0)
+	if err != nil {
+		t.Fatal("call error!", err.Error())
+	}
+	if binary.LittleEndian.Uint32(res) != uint32(14) {
+		t.Error("the result should be 14")
+	}
+	used := 1000000 - meter.gas
+	if used == 0 {
+		t.Fatal("execution should consume gas")
+	}
+
+	meter = &testGasMeter{gas: used - 1}
+	engine = NewExecutionEngine(nil, nil, nil)
+	engine.SetGasMeter(meter, nil)
+	_, err = engine.Call(common.Address{}, code, "", addInput(), 0)
+	if err != ErrOutOfGas {
+		t.Errorf("call should run out of gas, got %v", err)
+	}
+}
+
+func TestGasWithoutMeter(t *testing.T) {
+	engine := NewExecutionEngine(nil, nil, nil)
+	if err := engine.UseGas(math.MaxUint64); err != nil {
+		t.Error("engine without gas meter should not charge gas")
+	}
+}
+
+func TestOpGasTable(t *testing.T) {
+	table := &GasTable{Base: 1, Arith: 2, Mul: 3, Div: 4, Memory: 5, Branch: 6, Call: 7}
+	ops := table.opGasTable()
+	cases := map[byte]uint64{
+		operators.I32Add:   2,
+		operators.I64Mul:   3,
+		operators.I32DivU:  4,
+		operators.F64Div:   4,
+		operators.I32Load:  5,
+		operators.I64Store: 5,
+		operators.Call:     7,
+		operators.I32Const: 1,
+		compile.OpJmp:      6,
+		compile.OpJmpNz:    6,
+	}
+	for op, gas := range cases {
+		if ops[op] != gas {
+			t.Errorf("opcode 0x%x should cost %d, got %d", op, gas, ops[op])
+		}
+	}
+}
+
+func TestMaxMemoryPages(t *testing.T) {
+	// module with 1 page memory, exporting grow(n i32) i32 which grows memory by n pages
+	code := []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00,
+		0x01, 0x06, 0x01, 0x60, 0x01, 0x7f, 0x01, 0x7f,
+		0x03, 0x02, 0x01, 0x00,
+		0x05, 0x03, 0x01, 0x00, 0x01,
+		0x07, 0x08, 0x01, 0x04, 'g', 'r', 'o', 'w', 0x00, 0x00,
+		0x0a, 0x08, 0x01, 0x06, 0x00, 0x20, 0x00, 0x40, 0x00, 0x0b}
+	grow := func(pages byte, maxPages uint32) int32 {
+		engine := NewExecutionEngine(nil, nil, nil)
+		engine.SetMaxMemoryPages(maxPages)
+		input := []byte{4, 'g', 'r', 'o', 'w', 1, 1, pages}
+		res, err := engine.Call(common.Address{}, code, "", input, 0)
+		if err != nil {
+			t.Fatal("call error!", err.Error())
+		}
+		return int32(binary.LittleEndian.Uint32(res))
+	}
+
+	if n := grow(1, 2); n != 1 {
+		t.Errorf("grow within the limit should return the old pages 1, got %d", n)
+	}
+	if n := grow(2, 2); n != -1 {
+		t.Errorf("grow over the limit should return -1, got %d", n)
+	}
+	if n := grow(2, 0); n != 1 {
+		t.Errorf("grow without limit should return the old pages 1, got %d", n)
+	}
+}
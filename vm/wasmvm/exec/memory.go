@@ -243,6 +243,14 @@ func (vm *VM) growMemory() {
 	_ = vm.fetchInt8() // reserved (https://github.com/WebAssembly/design/blob/27ac254c854994103c24834a994be16f74f54186/BinaryEncoding.md#memory-related-operators-described-here)
 	curLen := len(vm.memory.Memory) / wasmPageSize
 	n := vm.popInt32()
+	if vm.Engine != nil && vm.Engine.maxMemoryPages > 0 &&
+		(n < 0 || int64(curLen)+int64(n) > int64(vm.Engine.maxMemoryPages)) {
+		vm.pushInt32(-1)
+		return
+	}
+	if vm.Engine != nil && n > 0 {
+		vm.useGas(uint64(n) * vm.Engine.gasTable.MemoryPage)
+	}
 	vm.memory.Memory = append(vm.memory.Memory, make([]byte, n*wasmPageSize)...)
 	vm.pushInt32(int32(curLen))
 }
@@ -0,0 +1,122 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package exec
+
+import (
+	"bytes"
+	"crypto/sha256"
+
+	"github.com/hashicorp/golang-lru"
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/errors"
+	"github.com/imZhuFei/zeepin/vm/wasmvm/wasm"
+)
+
+const (
+	MODULE_CACHE_SIZE = 256
+)
+
+//DefModuleCache is the compiled module cache shared by all wasm executions
+var DefModuleCache = newDefModuleCache()
+
+//CompiledModule is a parsed, verified and compiled wasm module.
+//it is shared between executions and must not be modified, every execution
+//instantiate its own memory and globals from it
+type CompiledModule struct {
+	module   *wasm.Module
+	funcs    []compiledFunction
+	codeHash [sha256.Size]byte
+}
+
+//CompileModule read, verify and compile the wasm code
+func CompileModule(code []byte) (*CompiledModule, error) {
+	m, err := wasm.ReadModule(bytes.NewBuffer(code), importer)
+	if err != nil {
+		return nil, errors.NewErr("[CompileModule]Verify wasm failed!" + err.Error())
+	}
+	//every wasm should have at least 1 export
+	if m.Export == nil {
+		return nil, errors.NewErr("[CompileModule]No export in wasm!")
+	}
+	funcs, err := compileFunctions(m)
+	if err != nil {
+		return nil, err
+	}
+	return &CompiledModule{
+		module:   m,
+		funcs:    funcs,
+		codeHash: sha256.Sum256(code),
+	}, nil
+}
+
+func (this *CompiledModule) Module() *wasm.Module {
+	return this.module
+}
+
+//ModuleCache cache the compiled modules keyed by contract address,
+//the code hash is checked on every lookup
+type ModuleCache struct {
+	cache *lru.ARCCache
+}
+
+func NewModuleCache(size int) (*ModuleCache, error) {
+	cache, err := lru.NewARC(size)
+	if err != nil {
+		return nil, err
+	}
+	return &ModuleCache{
+		cache: cache,
+	}, nil
+}
+
+func newDefModuleCache() *ModuleCache {
+	cache, err := NewModuleCache(MODULE_CACHE_SIZE)
+	if err != nil {
+		panic(err)
+	}
+	return cache
+}
+
+//GetModule return the compiled module of the contract, the code is compiled
+//and cached if it is not in cache or the cached one is compiled from other code
+func (this *ModuleCache) GetModule(address common.Address, code []byte) (*CompiledModule, error) {
+	hash := sha256.Sum256(code)
+	if value, ok := this.cache.Get(address); ok {
+		compiled := value.(*CompiledModule)
+		if compiled.codeHash == hash {
+			return compiled, nil
+		}
+	}
+	compiled, err := CompileModule(code)
+	if err != nil {
+		return nil, err
+	}
+	this.cache.Add(address, compiled)
+	return compiled, nil
+}
+
+//Invalidate remove the compiled module of the contract, used when the
+//contract is migrated or destroyed
+func (this *ModuleCache) Invalidate(address common.Address) {
+	this.cache.Remove(address)
+}
+
+func (this *ModuleCache) Len() int {
+	return this.cache.Len()
+}
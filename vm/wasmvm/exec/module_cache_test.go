@@ -0,0 +1,102 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package exec
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/imZhuFei/zeepin/common"
+)
+
+func TestModuleCache(t *testing.T) {
+	code, err := ioutil.ReadFile("./test_data2/math.wasm")
+	if err != nil {
+		t.Fatal("error in read file", err.Error())
+	}
+	other, err := ioutil.ReadFile("./test_data2/add.wasm")
+	if err != nil {
+		t.Fatal("error in read file", err.Error())
+	}
+	cache, err := NewModuleCache(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := common.Address{1}
+
+	compiled, err := cache.GetModule(addr, code)
+	if err != nil {
+		t.Fatal("compile error", err.Error())
+	}
+	cached, err := cache.GetModule(addr, code)
+	if err != nil {
+		t.Fatal("compile error", err.Error())
+	}
+	if cached != compiled {
+		t.Error("module should be compiled once")
+	}
+
+	changed, err := cache.GetModule(addr, other)
+	if err != nil {
+		t.Fatal("compile error", err.Error())
+	}
+	if changed == compiled {
+		t.Error("module should be recompiled when the code changed")
+	}
+
+	cache.Invalidate(addr)
+	if cache.Len() != 0 {
+		t.Error("module should be removed from cache")
+	}
+
+	if _, err := cache.GetModule(addr, []byte("invalid")); err == nil {
+		t.Error("invalid code should not be compiled")
+	}
+	if cache.Len() != 0 {
+		t.Error("invalid code should not be cached")
+	}
+}
+
+func TestNewVMFromCompiled(t *testing.T) {
+	code, err := ioutil.ReadFile("./test_data2/math.wasm")
+	if err != nil {
+		t.Fatal("error in read file", err.Error())
+	}
+	compiled, err := CompileModule(code)
+	if err != nil {
+		t.Fatal("compile error", err.Error())
+	}
+	entry, ok := compiled.Module().Export.Entries["add"]
+	if !ok {
+		t.Fatal("add should be exported")
+	}
+	for i := 0; i < 2; i++ {
+		vm, err := NewVMFromCompiled(compiled)
+		if err != nil {
+			t.Fatal("new vm error", err.Error())
+		}
+		res, err := vm.ExecCode(false, int64(entry.Index), 5, 9)
+		if err != nil {
+			t.Fatal("exec error", err.Error())
+		}
+		if res.(uint32) != 14 {
+			t.Errorf("the result should be 14, got %v", res)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package exec
+
+import (
+	"fmt"
+
+	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/vm/wasmvm/exec/internal/compile"
+	ops "github.com/imZhuFei/zeepin/vm/wasmvm/wasm/operators"
+)
+
+// MAX_TRACE_STACK is the max count of stack values from the top recorded in a step
+const MAX_TRACE_STACK = 16
+
+// Tracer receives the instructions and host calls executed by the engine,
+// it is set only when debugging contracts
+type Tracer interface {
+	CaptureStep(step *Step)
+	CaptureHostCall(contract common.Address, name string, gas uint64)
+}
+
+// MemoryAccess is the linear memory accessed by a load or store instruction
+type MemoryAccess struct {
+	Write bool
+	Addr  uint64
+	Size  int
+}
+
+// Step is the vm state before an instruction is executed
+type Step struct {
+	Contract common.Address
+	Func     int64
+	Pc       int64
+	Op       string
+	Gas      uint64
+	Stack    []uint64
+	Locals   []uint64
+	Memory   *MemoryAccess
+}
+
+// memory access size of the load and store instructions
+var memoryAccessSize = map[byte]int{
+	ops.I32Load: 4, ops.I64Load: 8, ops.F32Load: 4, ops.F64Load: 8,
+	ops.I32Load8s: 1, ops.I32Load8u: 1, ops.I32Load16s: 2, ops.I32Load16u: 2,
+	ops.I64Load8s: 1, ops.I64Load8u: 1, ops.I64Load16s: 2, ops.I64Load16u: 2,
+	ops.I64Load32s: 4, ops.I64Load32u: 4,
+	ops.I32Store: 4, ops.I64Store: 8, ops.F32Store: 4, ops.F64Store: 8,
+	ops.I32Store8: 1, ops.I32Store16: 2, ops.I64Store8: 1, ops.I64Store16: 2, ops.I64Store32: 4,
+}
+
+// OpName return the name of the compiled opcode
+func OpName(op byte) string {
+	switch op {
+	case compile.OpJmp:
+		return "jmp"
+	case compile.OpJmpZ:
+		return "jmpz"
+	case compile.OpJmpNz:
+		return "jmpnz"
+	case compile.OpDiscard:
+		return "discard"
+	case compile.OpDiscardPreserveTop:
+		return "discard_preserve_top"
+	}
+	if o, err := ops.New(op); err == nil {
+		return o.Name
+	}
+	return fmt.Sprintf("0x%02x", op)
+}
+
+// traceStep is called after op is read, the pc points to its immediates
+func (vm *VM) traceStep(tracer Tracer, op byte, gas uint64) {
+	stack := vm.ctx.stack
+	if len(stack) > MAX_TRACE_STACK {
+		stack = stack[len(stack)-MAX_TRACE_STACK:]
+	}
+	step := &Step{
+		Contract: vm.ContractAddress,
+		Func:     vm.ctx.curFunc,
+		Pc:       vm.ctx.pc - 1,
+		Op:       OpName(op),
+		Gas:      gas,
+		Stack:    append([]uint64{}, stack...),
+		Locals:   append([]uint64{}, vm.ctx.locals...),
+	}
+	if size, ok := memoryAccessSize[op]; ok {
+		//the base address is on the top of stack for load, under the value for store
+		write := op >= ops.I32Store
+		base := len(vm.ctx.stack) - 1
+		if write {
+			base--
+		}
+		if base >= 0 && int(vm.ctx.pc)+4 <= len(vm.ctx.code) {
+			offset := endianess.Uint32(vm.ctx.code[vm.ctx.pc:])
+			step.Memory = &MemoryAccess{
+				Write: write,
+				Addr:  uint64(offset + uint32(vm.ctx.stack[base])),
+				Size:  size,
+			}
+		}
+	}
+	tracer.CaptureStep(step)
+}
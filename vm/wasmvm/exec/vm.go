@@ -46,6 +46,7 @@ import (
 	"math"
 
 	"github.com/imZhuFei/zeepin/common"
+	"github.com/imZhuFei/zeepin/vm/wasmvm/abi"
 	"github.com/imZhuFei/zeepin/vm/wasmvm/disasm"
 	"github.com/imZhuFei/zeepin/vm/wasmvm/exec/internal/compile"
 	"github.com/imZhuFei/zeepin/vm/wasmvm/memory"
@@ -119,6 +120,9 @@ type VM struct {
 	Caller          common.Address
 	Engine          *ExecutionEngine
 	VMCode          []byte
+	//binary abi readers and writers
+	abiReaders map[uint64]*abi.Reader
+	abiWriters []*abi.Writer
 }
 
 // As per the WebAssembly spec: https://github.com/WebAssembly/design/blob/27ac254c854994103c24834a994be16f74f54186/Semantics.md#linear-memory
@@ -399,11 +403,30 @@ func (vm *VM) ExecCode(insideCall bool, fnIndex int64, args ...uint64) (interfac
 }
 
 func (vm *VM) execCode(isinside bool, compiled compiledFunction) uint64 {
+	var opGas *[256]uint64
+	var tracer Tracer
+	if vm.Engine != nil {
+		if vm.Engine.gasMeter != nil {
+			opGas = vm.Engine.opGas
+		}
+		tracer = vm.Engine.tracer
+	}
 outer:
 	for int(vm.ctx.pc) < len(vm.ctx.code) {
 		op := vm.ctx.code[vm.ctx.pc]
 		vm.ctx.pc++
 
+		if tracer != nil {
+			var gas uint64
+			if opGas != nil {
+				gas = opGas[op]
+			}
+			vm.traceStep(tracer, op, gas)
+		}
+		if opGas != nil {
+			vm.useGas(opGas[op])
+		}
+
 		switch op {
 		case ops.Return:
 			break outer
@@ -538,7 +561,26 @@ func (vm *VM) CallContract(caller common.Address, contractAddress common.Address
 	return uint64(idx), nil
 }
 
+// NewVMFromCompiled creates a new VM from a compiled module, the compiled
+// functions are shared with the module cache
+func NewVMFromCompiled(compiled *CompiledModule) (*VM, error) {
+	var vm VM
+	err := vm.instantiate(compiled.module, compiled.funcs)
+	if err != nil {
+		return nil, err
+	}
+	return &vm, nil
+}
+
 func (vm *VM) loadModule(module *wasm.Module) error {
+	funcs, err := compileFunctions(module)
+	if err != nil {
+		return err
+	}
+	return vm.instantiate(module, funcs)
+}
+
+func (vm *VM) instantiate(module *wasm.Module, funcs []compiledFunction) error {
 
 	vm.memory = &memory.VMmemory{}
 	if module.Memory != nil && len(module.Memory.Entries) != 0 {
@@ -548,8 +590,12 @@ func (vm *VM) loadModule(module *wasm.Module) error {
 		vm.memory.Memory = make([]byte, uint(module.Memory.Entries[0].Limits.Initial)*wasmPageSize)
 		copy(vm.memory.Memory, module.LinearMemoryIndexSpace[0])
 	} else if len(module.LinearMemoryIndexSpace) > 0 {
-		//add imported memory ,all mem access will be on the imported mem
-		vm.memory.Memory = module.LinearMemoryIndexSpace[0]
+		//add imported memory ,all mem access will be on the copy of imported mem
+		//as the module may be shared by other vms
+		if imported := module.LinearMemoryIndexSpace[0]; imported != nil {
+			vm.memory.Memory = make([]byte, len(imported))
+			copy(vm.memory.Memory, imported)
+		}
 	}
 
 	//give a default memory even if no memory section exist in wasm file
@@ -597,15 +643,45 @@ func (vm *VM) loadModule(module *wasm.Module) error {
 		vm.memory.PointedMemIndex = len(vm.memory.Memory) / 2 //the second half memory is reserved for the pointed objects,string,array,structs
 	}
 
-	vm.compiledFuncs = make([]compiledFunction, len(module.FunctionIndexSpace))
+	vm.compiledFuncs = funcs
 	vm.globals = make([]uint64, len(module.GlobalIndexSpace))
 	vm.newFuncTable()
 	vm.module = module
 
+	for i, global := range module.GlobalIndexSpace {
+		val, err := module.ExecInitExpr(global.Init)
+		if err != nil {
+			return err
+		}
+		switch v := val.(type) {
+		case int32:
+			vm.globals[i] = uint64(v)
+		case int64:
+			vm.globals[i] = uint64(v)
+		case float32:
+			vm.globals[i] = uint64(math.Float32bits(v))
+		case float64:
+			vm.globals[i] = uint64(math.Float64bits(v))
+		}
+	}
+
+	if module.Start != nil {
+		_, err := vm.ExecCode(false, int64(module.Start.Index))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+
+}
+
+// compileFunctions disassemble and compile all the functions of the module
+func compileFunctions(module *wasm.Module) ([]compiledFunction, error) {
+	funcs := make([]compiledFunction, len(module.FunctionIndexSpace))
 	for i, fn := range module.FunctionIndexSpace {
 		disassembly, err := disasm.Disassemble(fn, module)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		totalLocalVars := 0
@@ -616,7 +692,7 @@ func (vm *VM) loadModule(module *wasm.Module) error {
 		code, table := compile.Compile(disassembly.Code)
 
 		if fn.IsEnvFunc {
-			vm.compiledFuncs[i] = compiledFunction{
+			funcs[i] = compiledFunction{
 				code:           code,
 				branchTables:   table,
 				maxDepth:       disassembly.MaxDepth,
@@ -627,7 +703,7 @@ func (vm *VM) loadModule(module *wasm.Module) error {
 				name:           fn.Name,
 			}
 		} else {
-			vm.compiledFuncs[i] = compiledFunction{
+			funcs[i] = compiledFunction{
 				code:           code,
 				branchTables:   table,
 				maxDepth:       disassembly.MaxDepth,
@@ -637,30 +713,5 @@ func (vm *VM) loadModule(module *wasm.Module) error {
 			}
 		}
 	}
-
-	for i, global := range module.GlobalIndexSpace {
-		val, err := module.ExecInitExpr(global.Init)
-		if err != nil {
-			return err
-		}
-		switch v := val.(type) {
-		case int32:
-			vm.globals[i] = uint64(v)
-		case int64:
-			vm.globals[i] = uint64(v)
-		case float32:
-			vm.globals[i] = uint64(math.Float32bits(v))
-		case float64:
-			vm.globals[i] = uint64(math.Float64bits(v))
-		}
-	}
-
-	if module.Start != nil {
-		_, err := vm.ExecCode(false, int64(module.Start.Index))
-		if err != nil {
-			return err
-		}
-	}
-	return nil
-
+	return funcs, nil
 }
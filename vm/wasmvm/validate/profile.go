@@ -0,0 +1,366 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package validate
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/imZhuFei/zeepin/vm/wasmvm/disasm"
+	"github.com/imZhuFei/zeepin/vm/wasmvm/wasm"
+	"github.com/imZhuFei/zeepin/vm/wasmvm/wasm/leb128"
+	ops "github.com/imZhuFei/zeepin/vm/wasmvm/wasm/operators"
+)
+
+const (
+	ENV_MODULE     = "env"
+	WASM_PAGE_SIZE = 65536
+)
+
+// Profile restricts the modules accepted on chain, so that every node executes
+// them the same way and within bounded resources
+type Profile struct {
+	MaxCodeSize    int    // max size of the module binary
+	MaxFunctions   int    // max number of functions, imported ones included
+	MaxGlobals     int    // max number of globals, imported ones included
+	MaxLocals      uint64 // max number of params and locals of a function
+	MaxStackDepth  int    // max operand stack depth of a function
+	MaxMemoryPages uint32 // max pages of the linear memory
+	MaxTableSize   uint32 // max number of table elements
+	AllowFloat     bool   // whether floating point types and instructions are accepted
+}
+
+// ConsensusProfile is the profile applied when a wasm contract is deployed or migrated
+var ConsensusProfile = &Profile{
+	MaxCodeSize:    1024 * 1024,
+	MaxFunctions:   4096,
+	MaxGlobals:     1024,
+	MaxLocals:      1024,
+	MaxStackDepth:  1024,
+	MaxMemoryPages: 256,
+	MaxTableSize:   4096,
+	AllowFloat:     false,
+}
+
+// HostFunc report whether the function imported from env module is provided by the host
+type HostFunc func(name string) bool
+
+var ErrImportModule = errors.New("validate: only imports from env module are allowed")
+
+type LimitError struct {
+	Name  string
+	Value uint64
+	Limit uint64
+}
+
+func (e LimitError) Error() string {
+	return fmt.Sprintf("validate: %s %d exceeds the limit %d", e.Name, e.Value, e.Limit)
+}
+
+type FloatError struct {
+	Function int
+	Name     string
+}
+
+func (e FloatError) Error() string {
+	if e.Function < 0 {
+		return fmt.Sprintf("validate: floating point %s is not allowed", e.Name)
+	}
+	return fmt.Sprintf("validate: floating point %s in function %d is not allowed", e.Name, e.Function)
+}
+
+type ImportError struct {
+	Module string
+	Field  string
+}
+
+func (e ImportError) Error() string {
+	return fmt.Sprintf("validate: import %s.%s is not provided by the host", e.Module, e.Field)
+}
+
+// VerifyProfile checks the module code against the profile. The sections are
+// checked before the module is instantiated, so the memory and tables declared
+// by an invalid module are never allocated.
+func VerifyProfile(code []byte, profile *Profile, isHostFunc HostFunc) (err error) {
+	if len(code) > profile.MaxCodeSize {
+		return LimitError{"code size", uint64(len(code)), uint64(profile.MaxCodeSize)}
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("validate: invalid module: %v", r)
+		}
+	}()
+
+	m, err := wasm.DecodeModule(bytes.NewReader(code))
+	if err != nil {
+		return err
+	}
+	if err := profile.verifySections(m, isHostFunc); err != nil {
+		return err
+	}
+
+	m, err = wasm.ReadModule(bytes.NewReader(code), func(name string) (*wasm.Module, error) {
+		return nil, ErrImportModule
+	})
+	if err != nil {
+		return err
+	}
+	if err := VerifyModule(m); err != nil {
+		return err
+	}
+	return profile.verifyCode(m)
+}
+
+func (p *Profile) verifySections(m *wasm.Module, isHostFunc HostFunc) error {
+	var funcs, globals int
+	var envGlobals []int64
+	if m.Import != nil {
+		for _, entry := range m.Import.Entries {
+			if entry.ModuleName != ENV_MODULE {
+				return ErrImportModule
+			}
+			switch imp := entry.Type.(type) {
+			case wasm.FuncImport:
+				if !isHostFunc(entry.FieldName) {
+					return ImportError{entry.ModuleName, entry.FieldName}
+				}
+				if err := p.verifySig(m, imp.Type); err != nil {
+					return err
+				}
+				funcs++
+			case wasm.GlobalVarImport:
+				if entry.FieldName != "memoryBase" && entry.FieldName != "tableBase" {
+					return ImportError{entry.ModuleName, entry.FieldName}
+				}
+				envGlobals = append(envGlobals, wasm.EnvGlobalValue)
+				globals++
+			case wasm.MemoryImport:
+				if err := p.verifyLimits("memory pages", imp.Type.Limits, p.MaxMemoryPages); err != nil {
+					return err
+				}
+			case wasm.TableImport:
+				if err := p.verifyLimits("table size", imp.Type.Limits, p.MaxTableSize); err != nil {
+					return err
+				}
+			default:
+				return ImportError{entry.ModuleName, entry.FieldName}
+			}
+		}
+	}
+
+	if m.Function != nil {
+		funcs += len(m.Function.Types)
+		for _, t := range m.Function.Types {
+			if err := p.verifySig(m, t); err != nil {
+				return err
+			}
+		}
+	}
+	if funcs > p.MaxFunctions {
+		return LimitError{"function count", uint64(funcs), uint64(p.MaxFunctions)}
+	}
+
+	if m.Global != nil {
+		globals += len(m.Global.Globals)
+		for _, g := range m.Global.Globals {
+			if !p.AllowFloat && isFloat(g.Type.Type) {
+				return FloatError{-1, "global"}
+			}
+		}
+	}
+	if globals > p.MaxGlobals {
+		return LimitError{"global count", uint64(globals), uint64(p.MaxGlobals)}
+	}
+
+	if m.Memory != nil {
+		for _, entry := range m.Memory.Entries {
+			if err := p.verifyLimits("memory pages", entry.Limits, p.MaxMemoryPages); err != nil {
+				return err
+			}
+		}
+	}
+	if m.Table != nil {
+		for _, entry := range m.Table.Entries {
+			if err := p.verifyLimits("table size", entry.Limits, p.MaxTableSize); err != nil {
+				return err
+			}
+		}
+	}
+
+	if m.Code != nil {
+		for i, body := range m.Code.Bodies {
+			var locals uint64
+			if m.Function != nil && i < len(m.Function.Types) {
+				if t := int(m.Function.Types[i]); m.Types != nil && t < len(m.Types.Entries) {
+					locals = uint64(len(m.Types.Entries[t].ParamTypes))
+				}
+			}
+			for _, entry := range body.Locals {
+				if !p.AllowFloat && isFloat(entry.Type) {
+					return FloatError{funcs - len(m.Code.Bodies) + i, "local"}
+				}
+				locals += uint64(entry.Count)
+			}
+			if locals > p.MaxLocals {
+				return LimitError{"local count", locals, p.MaxLocals}
+			}
+		}
+	}
+
+	// the data and elements are copied to the offset at instantiation
+	memorySize := uint64(p.MaxMemoryPages) * WASM_PAGE_SIZE
+	if m.Data != nil {
+		for _, entry := range m.Data.Entries {
+			offset, err := initOffset(m, entry.Offset, envGlobals)
+			if err != nil {
+				return err
+			}
+			if end := offset + uint64(len(entry.Data)); end > memorySize {
+				return LimitError{"data segment end", end, memorySize}
+			}
+		}
+	}
+	if m.Elements != nil {
+		for _, entry := range m.Elements.Entries {
+			offset, err := initOffset(m, entry.Offset, envGlobals)
+			if err != nil {
+				return err
+			}
+			if end := offset + uint64(len(entry.Elems)); end > uint64(p.MaxTableSize) {
+				return LimitError{"element segment end", end, uint64(p.MaxTableSize)}
+			}
+		}
+	}
+	return nil
+}
+
+func (p *Profile) verifySig(m *wasm.Module, index uint32) error {
+	if m.Types == nil || int(index) >= len(m.Types.Entries) {
+		return fmt.Errorf("validate: invalid type index %d", index)
+	}
+	if p.AllowFloat {
+		return nil
+	}
+	sig := m.Types.Entries[index]
+	for _, t := range append(sig.ParamTypes, sig.ReturnTypes...) {
+		if isFloat(t) {
+			return FloatError{-1, "function signature"}
+		}
+	}
+	return nil
+}
+
+func (p *Profile) verifyLimits(name string, limits wasm.ResizableLimits, max uint32) error {
+	if limits.Initial > max {
+		return LimitError{name, uint64(limits.Initial), uint64(max)}
+	}
+	if limits.Flags&0x1 != 0 && limits.Maximum > max {
+		return LimitError{name, uint64(limits.Maximum), uint64(max)}
+	}
+	return nil
+}
+
+// verifyCode disassembles every function the same way the vm compiles it
+func (p *Profile) verifyCode(m *wasm.Module) error {
+	for i, fn := range m.FunctionIndexSpace {
+		if fn.IsEnvFunc {
+			continue
+		}
+		d, err := disasm.Disassemble(fn, m)
+		if err != nil {
+			return Error{0, i, err}
+		}
+		if d.MaxDepth > p.MaxStackDepth {
+			return LimitError{"stack depth", uint64(d.MaxDepth), uint64(p.MaxStackDepth)}
+		}
+		if p.AllowFloat {
+			continue
+		}
+		for _, instr := range d.Code {
+			if isFloatOp(instr.Op) {
+				return FloatError{i, "instruction " + instr.Op.Name}
+			}
+		}
+	}
+	return nil
+}
+
+// initOffset evaluates the offset of data and element segments, only the constant
+// and the global initialized by constant are accepted
+func initOffset(m *wasm.Module, expr []byte, envGlobals []int64) (uint64, error) {
+	r := bytes.NewReader(expr)
+	op, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	var val int64
+	switch op {
+	case ops.I32Const:
+		v, err := leb128.ReadVarint32(r)
+		if err != nil {
+			return 0, err
+		}
+		val = int64(v)
+	case ops.GetGlobal:
+		index, err := leb128.ReadVarUint32(r)
+		if err != nil {
+			return 0, err
+		}
+		if int(index) < len(envGlobals) {
+			val = envGlobals[index]
+			break
+		}
+		index -= uint32(len(envGlobals))
+		if m.Global == nil || int(index) >= len(m.Global.Globals) {
+			return 0, wasm.InvalidGlobalIndexError(index)
+		}
+		init := m.Global.Globals[index].Init
+		if len(init) == 0 || init[0] != ops.I32Const {
+			return 0, errors.New("validate: segment offset must be constant")
+		}
+		v, err := leb128.ReadVarint32(bytes.NewReader(init[1:]))
+		if err != nil {
+			return 0, err
+		}
+		val = int64(v)
+	default:
+		return 0, errors.New("validate: segment offset must be constant")
+	}
+	if val < 0 {
+		return 0, fmt.Errorf("validate: negative segment offset %d", val)
+	}
+	return uint64(val), nil
+}
+
+func isFloat(t wasm.ValueType) bool {
+	return t == wasm.ValueTypeF32 || t == wasm.ValueTypeF64
+}
+
+func isFloatOp(op ops.Op) bool {
+	if isFloat(op.Returns) {
+		return true
+	}
+	for _, t := range op.Args {
+		if isFloat(t) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+/*
+ * Copyright (C) 2018 The ZeepinChain Authors
+ * This file is part of The ZeepinChain library.
+ *
+ * The ZeepinChain is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ZeepinChain is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ZeepinChain.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package validate
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var header = []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+
+func module(sections ...byte) []byte {
+	return append(append([]byte{}, header...), sections...)
+}
+
+func allHostFunc(name string) bool {
+	return true
+}
+
+func TestVerifyProfile(t *testing.T) {
+	code, err := ioutil.ReadFile("../exec/test_data2/contract.wasm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Nil(t, VerifyProfile(code, ConsensusProfile, allHostFunc))
+
+	err = VerifyProfile(code, ConsensusProfile, func(name string) bool { return false })
+	assert.IsType(t, ImportError{}, err)
+
+	code, err = ioutil.ReadFile("../exec/test_data2/float.wasm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.IsType(t, FloatError{}, VerifyProfile(code, ConsensusProfile, allHostFunc))
+
+	code, err = ioutil.ReadFile("../exec/test_data/spec/traps_mem.wasm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = VerifyProfile(code, ConsensusProfile, allHostFunc)
+	assert.Equal(t, "validate: floating point instruction f32.load in function 12 is not allowed", err.Error())
+}
+
+func TestVerifyProfileLimits(t *testing.T) {
+	// memory section with 300 initial pages
+	code := module(0x05, 0x04, 0x01, 0x00, 0xac, 0x02)
+	assert.Equal(t, LimitError{"memory pages", 300, 256}, VerifyProfile(code, ConsensusProfile, allHostFunc))
+
+	// import section with ZPT.foo
+	code = module(0x01, 0x04, 0x01, 0x60, 0x00, 0x00,
+		0x02, 0x0b, 0x01, 0x03, 'Z', 'P', 'T', 0x03, 'f', 'o', 'o', 0x00, 0x00)
+	assert.Equal(t, ErrImportModule, VerifyProfile(code, ConsensusProfile, allHostFunc))
+
+	// type section claims 0xffffffff entries
+	code = module(0x01, 0x05, 0xff, 0xff, 0xff, 0xff, 0x0f)
+	assert.NotNil(t, VerifyProfile(code, ConsensusProfile, allHostFunc))
+
+	code = make([]byte, ConsensusProfile.MaxCodeSize+1)
+	copy(code, header)
+	assert.IsType(t, LimitError{}, VerifyProfile(code, ConsensusProfile, allHostFunc))
+}
@@ -85,6 +85,10 @@ type GlobalVarImport struct {
 
 func (GlobalVarImport) isImport() {}
 
+// EnvGlobalValue is the value of the env memoryBase and tableBase globals,
+// it is 16 for fiddle case, 0 is reserved for NULL
+const EnvGlobalValue = 16
+
 var (
 	ErrImportMutGlobal           = errors.New("wasm: cannot import global mutable variable")
 	ErrNoExportsInImportedModule = errors.New("wasm: imported module has no exports")
@@ -154,7 +158,7 @@ func (module *Module) resolveImports(resolve ResolveFunc) error {
 					glb := &GlobalEntry{Type: &GlobalVar{Type: ValueTypeI32, Mutable: false},
 						Init: []byte{getGlobal, byte(0), end}, //global 0 end
 						//InitVal: uint64(65536 / 4),               // pagesize/4
-						InitVal: 16, // reset to 16 for fiddle case,0 reserve for NULL
+						InitVal: EnvGlobalValue,
 						IsEnv:   true}
 					module.GlobalIndexSpace = append(module.GlobalIndexSpace, *glb)
 					module.imports.Globals++
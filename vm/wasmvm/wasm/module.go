@@ -95,13 +95,10 @@ type Module struct {
 	}
 }
 
-// ResolveFunc is a function that takes a module name and
-// returns a valid resolved module.
-type ResolveFunc func(name string) (*Module, error)
-
-// ReadModule reads a module from the reader r. resolvePath must take a string
-// and a return a reader to the module pointed to by the string.
-func ReadModule(r io.Reader, resolvePath ResolveFunc) (*Module, error) {
+// DecodeModule reads the sections of a module from the reader r, the imports are
+// not resolved and the index spaces are not populated. It is used to inspect a
+// module before any memory or table of it is allocated.
+func DecodeModule(r io.Reader) (*Module, error) {
 	reader := &readpos.ReadPos{
 		R:      r,
 		CurPos: 0,
@@ -126,6 +123,20 @@ func ReadModule(r io.Reader, resolvePath ResolveFunc) (*Module, error) {
 			break
 		}
 	}
+	return m, nil
+}
+
+// ResolveFunc is a function that takes a module name and
+// returns a valid resolved module.
+type ResolveFunc func(name string) (*Module, error)
+
+// ReadModule reads a module from the reader r. resolvePath must take a string
+// and a return a reader to the module pointed to by the string.
+func ReadModule(r io.Reader, resolvePath ResolveFunc) (*Module, error) {
+	m, err := DecodeModule(r)
+	if err != nil {
+		return nil, err
+	}
 
 	m.LinearMemoryIndexSpace = make([][]byte, 1)
 	if m.Table != nil {
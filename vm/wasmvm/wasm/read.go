@@ -41,9 +41,44 @@ package wasm
 import (
 	"encoding/binary"
 	"io"
+
+	"github.com/imZhuFei/zeepin/vm/wasmvm/wasm/internal/readpos"
+	"github.com/imZhuFei/zeepin/vm/wasmvm/wasm/leb128"
 )
 
+// remainingLen return the number of unread bytes of r if it is known. The
+// lengths and counts read from the module are checked against it before
+// allocating, so a malformed module can not request huge allocations.
+func remainingLen(r io.Reader) (int64, bool) {
+	switch r := r.(type) {
+	case *io.LimitedReader:
+		return r.N, true
+	case *readpos.ReadPos:
+		return remainingLen(r.R)
+	case interface {
+		Len() int
+	}:
+		return int64(r.Len()), true
+	}
+	return 0, false
+}
+
+// readCount read the number of entries that follow, every entry takes at least one byte
+func readCount(r io.Reader) (uint32, error) {
+	count, err := leb128.ReadVarUint32(r)
+	if err != nil {
+		return 0, err
+	}
+	if n, ok := remainingLen(r); ok && int64(count) > n {
+		return 0, io.ErrUnexpectedEOF
+	}
+	return count, nil
+}
+
 func readBytes(r io.Reader, n int) ([]byte, error) {
+	if max, ok := remainingLen(r); ok && int64(n) > max {
+		return nil, io.ErrUnexpectedEOF
+	}
 	bytes := make([]byte, n)
 	_, err := io.ReadFull(r, bytes)
 	if err != nil {
@@ -160,6 +160,9 @@ func (m *Module) readSection(r *readpos.ReadPos) (bool, error) {
 
 	s.Start = r.CurPos
 
+	if n, ok := remainingLen(r); ok && int64(payloadDataLen) > n {
+		return false, io.ErrUnexpectedEOF
+	}
 	sectionBytes := new(bytes.Buffer)
 	sectionBytes.Grow(int(payloadDataLen))
 	sectionReader := io.LimitReader(io.TeeReader(r, sectionBytes), int64(payloadDataLen))
@@ -270,7 +273,7 @@ type SectionTypes struct {
 
 func (m *Module) readSectionTypes(r io.Reader) error {
 	s := &SectionTypes{}
-	count, err := leb128.ReadVarUint32(r)
+	count, err := readCount(r)
 	if err != nil {
 		return err
 	}
@@ -297,7 +300,7 @@ type SectionImports struct {
 
 func (m *Module) readSectionImports(r io.Reader) error {
 	s := &SectionImports{}
-	count, err := leb128.ReadVarUint32(r)
+	count, err := readCount(r)
 	if err != nil {
 		return err
 	}
@@ -385,7 +388,7 @@ type SectionFunctions struct {
 
 func (m *Module) readSectionFunctions(r io.Reader) error {
 	s := &SectionFunctions{}
-	count, err := leb128.ReadVarUint32(r)
+	count, err := readCount(r)
 	if err != nil {
 		return err
 	}
@@ -412,7 +415,7 @@ type SectionTables struct {
 
 func (m *Module) readSectionTables(r io.Reader) error {
 	s := &SectionTables{}
-	count, err := leb128.ReadVarUint32(r)
+	count, err := readCount(r)
 	if err != nil {
 		return err
 	}
@@ -438,7 +441,7 @@ type SectionMemories struct {
 
 func (m *Module) readSectionMemories(r io.Reader) error {
 	s := &SectionMemories{}
-	count, err := leb128.ReadVarUint32(r)
+	count, err := readCount(r)
 	if err != nil {
 		return err
 	}
@@ -466,7 +469,7 @@ type SectionGlobals struct {
 func (m *Module) readSectionGlobals(r io.Reader) error {
 	s := &SectionGlobals{}
 
-	count, err := leb128.ReadVarUint32(r)
+	count, err := readCount(r)
 	if err != nil {
 		return err
 	}
@@ -521,7 +524,7 @@ func (e DuplicateExportError) Error() string {
 
 func (m *Module) readSectionExports(r io.Reader) error {
 	s := &SectionExports{}
-	count, err := leb128.ReadVarUint32(r)
+	count, err := readCount(r)
 	if err != nil {
 		return err
 	}
@@ -594,7 +597,7 @@ type SectionElements struct {
 
 func (m *Module) readSectionElements(r io.Reader) error {
 	s := &SectionElements{}
-	count, err := leb128.ReadVarUint32(r)
+	count, err := readCount(r)
 	if err != nil {
 		return err
 	}
@@ -629,7 +632,7 @@ func readElementSegment(r io.Reader) (ElementSegment, error) {
 		return s, err
 	}
 
-	numElems, err := leb128.ReadVarUint32(r)
+	numElems, err := readCount(r)
 	if err != nil {
 		return s, err
 	}
@@ -655,7 +658,7 @@ type SectionCode struct {
 func (m *Module) readSectionCode(r io.Reader) error {
 	s := &SectionCode{}
 
-	count, err := leb128.ReadVarUint32(r)
+	count, err := readCount(r)
 	if err != nil {
 		return err
 	}
@@ -701,15 +704,14 @@ func readFunctionBody(r io.Reader) (FunctionBody, error) {
 		return f, err
 	}
 
-	body := make([]byte, bodySize)
-
-	if _, err = io.ReadFull(r, body); err != nil {
+	body, err := readBytes(r, int(bodySize))
+	if err != nil {
 		return f, err
 	}
 
 	bytesReader := bytes.NewBuffer(body)
 
-	localCount, err := leb128.ReadVarUint32(bytesReader)
+	localCount, err := readCount(bytesReader)
 	if err != nil {
 		return f, err
 	}
@@ -765,7 +767,7 @@ type SectionData struct {
 
 func (m *Module) readSectionData(r io.Reader) error {
 	s := &SectionData{}
-	count, err := leb128.ReadVarUint32(r)
+	count, err := readCount(r)
 	if err != nil {
 		return err
 	}
@@ -144,7 +144,7 @@ func readFunction(r io.Reader) (FunctionSig, error) {
 
 	f.Form = int8(form)
 
-	paramCount, err := leb128.ReadVarUint32(r)
+	paramCount, err := readCount(r)
 	if err != nil {
 		return f, err
 	}
@@ -157,7 +157,7 @@ func readFunction(r io.Reader) (FunctionSig, error) {
 		}
 	}
 
-	returnCount, err := leb128.ReadVarUint32(r)
+	returnCount, err := readCount(r)
 	if err != nil {
 		return f, err
 	}